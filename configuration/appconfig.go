@@ -13,6 +13,13 @@ import (
 	"github.com/spf13/viper"
 )
 
+const (
+	defaultPinMaxAttempts      = 3
+	defaultPinLockoutSeconds   = 900
+	defaultLoginMaxAttempts    = 5
+	defaultLoginLockoutSeconds = 900
+)
+
 func newAppConfiguration() model.IAppConfiguration {
 	err := godotenv.Load()
 	if err != nil {
@@ -53,7 +60,7 @@ func parseConfig(v *viper.Viper) (*appConfig, error) {
 	var c appConfig
 	err := v.Unmarshal(&c)
 	if err != nil {
-		slog.Info("unable to decode into struct, %v", err) // nolint
+		slog.Info("unable to decode into struct", "error", err)
 		return nil, err
 	}
 	return &c, nil
@@ -74,6 +81,17 @@ type appConfig struct {
 	AppServerPort  string
 	ThirdPartyAPI  string
 	Secret         string
+	RefFormat      string
+	RefNodeID      string
+	RefPrefix      string
+	SchedulerSecs  string
+	RatesFile      string
+	CurrencyCode   string
+	PinAttempts    string
+	PinLockoutSecs string
+	LoginAttempts  string
+	LoginLockSecs  string
+	NotifyFile     string
 }
 
 func (a *appConfig) ReadTimeout() uint32 {
@@ -132,6 +150,67 @@ func (a *appConfig) JwtSecret() string {
 	return a.Secret
 }
 
+func (a *appConfig) ReferenceFormat() string {
+	return a.RefFormat
+}
+
+func (a *appConfig) ReferenceNodeID() int64 {
+	return int64(convertToInt(a.RefNodeID))
+}
+
+func (a *appConfig) ReferencePrefix() string {
+	return a.RefPrefix
+}
+
+func (a *appConfig) SchedulerInterval() int {
+	return convertToInt(a.SchedulerSecs)
+}
+
+func (a *appConfig) ExchangeRatesFile() string {
+	return a.RatesFile
+}
+
+func (a *appConfig) DefaultCurrency() string {
+	return a.CurrencyCode
+}
+
+// PinMaxAttempts is how many incorrect transaction PINs in a row lock PIN use, three unless configured
+func (a *appConfig) PinMaxAttempts() int {
+	if attempts := convertToInt(a.PinAttempts); attempts > 0 {
+		return attempts
+	}
+	return defaultPinMaxAttempts
+}
+
+// PinLockoutSeconds is how long PIN use stays locked, fifteen minutes unless configured
+func (a *appConfig) PinLockoutSeconds() int {
+	if seconds := convertToInt(a.PinLockoutSecs); seconds > 0 {
+		return seconds
+	}
+	return defaultPinLockoutSeconds
+}
+
+// LoginMaxAttempts is how many incorrect passwords in a row lock signing in, five unless configured
+func (a *appConfig) LoginMaxAttempts() int {
+	if attempts := convertToInt(a.LoginAttempts); attempts > 0 {
+		return attempts
+	}
+	return defaultLoginMaxAttempts
+}
+
+// LoginLockoutSeconds is how long signing in stays locked, fifteen minutes unless configured
+func (a *appConfig) LoginLockoutSeconds() int {
+	if seconds := convertToInt(a.LoginLockSecs); seconds > 0 {
+		return seconds
+	}
+	return defaultLoginLockoutSeconds
+}
+
+// NotificationsFile is the file notifications for users are appended to, they are logged when it is not set
+func (a *appConfig) NotificationsFile() string {
+	return a.NotifyFile
+}
+
 func convertToInt(valueToBeConverted string) int {
 	if val, err := strconv.Atoi(valueToBeConverted); err == nil {
 		return val
@@ -4,9 +4,16 @@ import (
 	"bankingApp/internal/api/bankservice"
 	"bankingApp/internal/api/handlers"
 	"bankingApp/internal/api/middleware"
+	"bankingApp/internal/currency"
+	"bankingApp/internal/ledger"
 	"bankingApp/internal/model"
 	"bankingApp/internal/nethttp"
+	"bankingApp/internal/notify"
+	"bankingApp/internal/reference"
 	"bankingApp/internal/repository"
+	"bankingApp/internal/token"
+	"bankingApp/internal/worker"
+	"context"
 	"fmt"
 	"log"
 	"time"
@@ -19,10 +26,29 @@ import (
 )
 
 type App struct {
-	DB                  *gorm.DB
-	bankTransferHandler *handler.BankTransferHandler
-	Configuration       model.IAppConfiguration
-	bankTransferService handler.IBankTransferService
+	DB                       *gorm.DB
+	idempotencyStore         middleware.IIdempotencyStore
+	authMiddleware           *middleware.AuthMiddleware
+	authHandler              *handler.AuthHandler
+	bankTransferHandler      *handler.BankTransferHandler
+	scheduledTransferHandler *handler.ScheduledTransferHandler
+	standingOrderHandler     *handler.StandingOrderHandler
+	bulkTransferHandler      *handler.BulkTransferHandler
+	exchangeRateHandler      *handler.ExchangeRateHandler
+	limitHandler             *handler.LimitHandler
+	feeHandler               *handler.FeeHandler
+	holdHandler              *handler.HoldHandler
+	overdraftHandler         *handler.OverdraftHandler
+	interestHandler          *handler.InterestHandler
+	statementHandler         *handler.StatementHandler
+	historyHandler           *handler.TransactionHistoryHandler
+	balanceHandler           *handler.BalanceHandler
+	accountStatusHandler     *handler.AccountStatusHandler
+	userHandler              *handler.UserHandler
+	pinHandler               *handler.PinHandler
+	Configuration            model.IAppConfiguration
+	bankTransferService      handler.IBankTransferService
+	worker                   *worker.Worker
 }
 
 // NewApp creates a new application instance
@@ -37,21 +63,157 @@ func NewApp() *App {
 		panic(dbErr)
 	}
 
+	if err := app.migrateDatabase(); err != nil {
+		panic(err)
+	}
+
 	transactionRepository := repository.NewTransactionRepository(app.DB)
-	userRepository := repository.NewUserRepository(app.DB)
+	userRepository := repository.NewUserRepository(app.DB, model.Lockout{
+		MaxAttempts: app.Configuration.PinMaxAttempts(),
+		Cooldown:    time.Duration(app.Configuration.PinLockoutSeconds()) * time.Second,
+	}, model.Lockout{
+		MaxAttempts: app.Configuration.LoginMaxAttempts(),
+		Cooldown:    time.Duration(app.Configuration.LoginLockoutSeconds()) * time.Second,
+	})
+	if err := userRepository.HashLegacyCredentials(); err != nil {
+		panic(err)
+	}
 	accountRepository := repository.NewAccountRepository(app.DB)
+	defaultCurrency, err := currency.Lookup(app.Configuration.DefaultCurrency())
+	if err != nil {
+		panic(err)
+	}
+	if err := accountRepository.AssignDefaultCurrency(defaultCurrency.Code); err != nil {
+		panic(err)
+	}
+	journalRepository := repository.NewJournalRepository(app.DB)
+	if err := journalRepository.EnsureChartOfAccounts(ledger.ChartOfAccounts); err != nil {
+		panic(err)
+	}
+	if err := journalRepository.PostOpeningBalances(); err != nil {
+		panic(err)
+	}
+	app.idempotencyStore = repository.NewIdempotencyRepository(app.DB)
+
+	tokenIssuer, err := token.NewIssuer(app.Configuration.JwtSecret(), token.AccessTokenTTL)
+	if err != nil {
+		panic(err)
+	}
+	app.authMiddleware = &middleware.AuthMiddleware{Tokens: tokenIssuer, Users: userRepository, Accounts: accountRepository}
+	app.authHandler = handler.NewAuthHandler(bankservice.NewAuthService(userRepository, tokenIssuer))
+
+	exchangeRateRepository := repository.NewExchangeRateRepository(app.DB)
+	exchangeRateService := bankservice.NewExchangeRateService(exchangeRateRepository)
+	if file := app.Configuration.ExchangeRatesFile(); file != "" {
+		rates, err := currency.LoadRatesFile(file)
+		if err != nil {
+			panic(err)
+		}
+		if err := exchangeRateService.Import(rates, bankservice.RateSourceFile); err != nil {
+			panic(err)
+		}
+	}
+	app.exchangeRateHandler = handler.NewExchangeRateHandler(exchangeRateService)
+
+	limitRepository := repository.NewLimitRepository(app.DB)
+	app.limitHandler = handler.NewLimitHandler(bankservice.NewLimitService(limitRepository, accountRepository))
+
+	feeRepository := repository.NewFeeRepository(app.DB)
+	app.feeHandler = handler.NewFeeHandler(bankservice.NewFeeService(feeRepository))
+
+	referenceGenerator, err := reference.NewGenerator(
+		app.Configuration.ReferenceFormat(),
+		app.Configuration.ReferenceNodeID(),
+		app.Configuration.ReferencePrefix())
+	if err != nil {
+		panic(err)
+	}
 
 	timeout := app.Configuration.ReadTimeout()
 	duration := time.Duration(time.Duration.Seconds(time.Duration(timeout)))
 	restClient := nethttp.NewRestHttpClient(duration)
 
-	app.bankTransferService = bankservice.NewBankService(
+	bankTransferService := bankservice.NewBankService(
 		app.Configuration,
 		transactionRepository,
 		userRepository,
 		accountRepository,
+		exchangeRateRepository,
+		limitRepository,
+		feeRepository,
+		repository.NewUnitOfWork(app.DB),
+		referenceGenerator,
 		restClient)
+	app.bankTransferService = bankTransferService
 	app.bankTransferHandler = handler.NewBankTransferHandler(app.bankTransferService)
+
+	scheduledTransferService := bankservice.NewScheduledTransferService(
+		repository.NewScheduledTransferRepository(app.DB),
+		bankTransferService)
+	app.scheduledTransferHandler = handler.NewScheduledTransferHandler(scheduledTransferService)
+
+	standingOrderService := bankservice.NewStandingOrderService(
+		repository.NewStandingOrderRepository(app.DB),
+		bankTransferService)
+	app.standingOrderHandler = handler.NewStandingOrderHandler(standingOrderService)
+
+	bulkTransferService := bankservice.NewBulkTransferService(
+		repository.NewBulkTransferRepository(app.DB),
+		bankTransferService)
+	app.bulkTransferHandler = handler.NewBulkTransferHandler(bulkTransferService)
+
+	holdService := bankservice.NewHoldService(repository.NewHoldRepository(app.DB), bankTransferService)
+	app.holdHandler = handler.NewHoldHandler(holdService)
+
+	overdraftService := bankservice.NewOverdraftService(
+		repository.NewOverdraftRepository(app.DB),
+		accountRepository,
+		repository.NewUnitOfWork(app.DB))
+	app.overdraftHandler = handler.NewOverdraftHandler(overdraftService)
+
+	app.accountStatusHandler = handler.NewAccountStatusHandler(bankservice.NewAccountStatusService(
+		accountRepository,
+		accountRepository,
+		repository.NewUnitOfWork(app.DB)))
+
+	interestService := bankservice.NewInterestService(
+		repository.NewInterestRepository(app.DB),
+		accountRepository,
+		repository.NewUnitOfWork(app.DB),
+		referenceGenerator)
+	app.interestHandler = handler.NewInterestHandler(interestService)
+
+	app.userHandler = handler.NewUserHandler(bankservice.NewUserService(
+		userRepository,
+		reference.NewAccountNumberGenerator(),
+		defaultCurrency.Code))
+
+	var notifier bankservice.INotifier = notify.LogNotifier{}
+	if file := app.Configuration.NotificationsFile(); file != "" {
+		notifier = notify.NewFileNotifier(file)
+	}
+	app.pinHandler = handler.NewPinHandler(
+		bankservice.NewPinService(repository.NewPinRepository(app.DB), userRepository, notifier))
+
+	app.statementHandler = handler.NewStatementHandler(
+		bankservice.NewStatementService(repository.NewStatementRepository(app.DB), accountRepository))
+	app.historyHandler = handler.NewTransactionHistoryHandler(
+		bankservice.NewTransactionHistoryService(transactionRepository, accountRepository))
+	app.balanceHandler = handler.NewBalanceHandler(
+		bankservice.NewBalanceService(transactionRepository, accountRepository))
+
+	app.worker = worker.NewWorker(
+		time.Duration(app.Configuration.SchedulerInterval())*time.Second,
+		worker.Job{Name: "scheduled-transfers", Run: scheduledTransferService.ExecuteDue},
+		worker.Job{Name: "standing-orders", Run: standingOrderService.ExecuteDue},
+		worker.Job{Name: "bulk-transfers", Run: bulkTransferService.ExecutePending},
+		worker.Job{Name: "expired-holds", Run: holdService.ExpireDue},
+		worker.Job{Name: "stale-transactions", Run: bankTransferService.ResolveStale},
+		worker.Job{Name: "overdraft-interest", Run: overdraftService.AccrueInterest},
+		worker.Job{Name: "interest-accrual", Run: interestService.AccrueDaily},
+		worker.Job{Name: "interest-capitalisation", Run: interestService.CapitaliseMonthly},
+		worker.Job{Name: "ledger-reconciliation", Run: ledger.NewLedger(journalRepository).Reconcile})
+	app.worker.Start(context.Background())
 	return app
 }
 
@@ -89,6 +251,36 @@ func (app *App) connectDatabase(config model.IAppConfiguration) (*gorm.DB, error
 	return db, nil
 }
 
+// migrateDatabase creates or updates the tables backing the application models
+func (app *App) migrateDatabase() error {
+	return app.DB.AutoMigrate(
+		&model.User{},
+		&model.Account{},
+		&model.Transaction{},
+		&model.TransactionStatusHistory{},
+		&model.IdempotencyRecord{},
+		&model.LedgerAccount{},
+		&model.JournalEntry{},
+		&model.Posting{},
+		&model.ScheduledTransfer{},
+		&model.StandingOrder{},
+		&model.StandingOrderExecution{},
+		&model.BulkTransferBatch{},
+		&model.BulkTransferRow{},
+		&model.ExchangeRate{},
+		&model.TransactionLimit{},
+		&model.FeeRule{},
+		&model.Hold{},
+		&model.OverdraftChange{},
+		&model.OverdraftInterestAccrual{},
+		&model.AccountProduct{},
+		&model.InterestAccrual{},
+		&model.AccountStatusChange{},
+		&model.PinAudit{},
+		&model.PinResetCode{},
+	)
+}
+
 // RouteHandler sets up the application routes and middleware
 func (app *App) RouteHandler(config model.IAppConfiguration) *gin.Engine {
 	route := gin.Default()
@@ -106,7 +298,91 @@ func (app *App) RouteHandler(config model.IAppConfiguration) *gin.Engine {
 	securityMiddleware := middleware.SecurityMiddleware{}
 	groupRoute.Use(securityMiddleware.RequestHeaders())
 
-	groupRoute.POST("/fund-transfer", app.bankTransferHandler.Transfer)
+	idempotencyMiddleware := middleware.IdempotencyMiddleware{Store: app.idempotencyStore}
+
+	groupRoute.POST("/auth/login", app.authHandler.Login)
+	groupRoute.POST("/users", app.userHandler.Register)
+	groupRoute.GET("/users/me", app.authMiddleware.Authenticate(), app.userHandler.Profile)
+	groupRoute.PUT("/users/me", app.authMiddleware.Authenticate(), app.userHandler.UpdateProfile)
+	groupRoute.PUT("/users/me/pin", app.authMiddleware.Authenticate(), app.pinHandler.Change)
+	groupRoute.POST("/users/me/pin/reset", app.authMiddleware.Authenticate(), app.pinHandler.RequestReset)
+	groupRoute.POST("/users/me/pin/reset/confirm", app.authMiddleware.Authenticate(), app.pinHandler.Reset)
+
 	groupRoute.GET("/status-query/:ref", app.bankTransferHandler.StatusQuery)
+	groupRoute.GET("/exchange-rates", app.exchangeRateHandler.List)
+	groupRoute.GET("/account-products", app.interestHandler.ListProducts)
+	groupRoute.GET("/fee-rules", app.feeHandler.List)
+
+	app.customerRoutes(groupRoute, idempotencyMiddleware)
+	app.staffRoutes(groupRoute, idempotencyMiddleware)
 	return route
 }
+
+// customerRoutes sets up the routes reached with an access token. Money only moves from accounts the
+// authenticated user owns, and what is set up on an account is only read by its owner and staff.
+func (app *App) customerRoutes(groupRoute *gin.RouterGroup, idempotencyMiddleware middleware.IdempotencyMiddleware) {
+	customerRoute := groupRoute.Group("", app.authMiddleware.Authenticate())
+
+	moneyRoute := customerRoute.Group("", app.authMiddleware.OwnsAccount(), idempotencyMiddleware.Idempotent())
+	moneyRoute.POST("/fund-transfer", app.bankTransferHandler.Transfer)
+	moneyRoute.POST("/internal-transfer", app.bankTransferHandler.InternalTransfer)
+	moneyRoute.POST("/scheduled-transfers", app.scheduledTransferHandler.Create)
+	moneyRoute.POST("/standing-orders", app.standingOrderHandler.Create)
+	moneyRoute.POST("/holds", app.holdHandler.Place)
+
+	customerRoute.GET("/scheduled-transfers", app.scheduledTransferHandler.List)
+	customerRoute.POST("/scheduled-transfers/:id/cancel", app.scheduledTransferHandler.Cancel)
+
+	customerRoute.GET("/standing-orders", app.standingOrderHandler.List)
+	customerRoute.GET("/standing-orders/:id/executions", app.standingOrderHandler.Executions)
+	customerRoute.POST("/standing-orders/:id/cancel", app.standingOrderHandler.Cancel)
+
+	customerRoute.POST("/bulk-transfers", idempotencyMiddleware.Idempotent(), app.bulkTransferHandler.Submit)
+	customerRoute.GET("/bulk-transfers/:id", app.bulkTransferHandler.Progress)
+	customerRoute.GET("/bulk-transfers/:id/results", app.bulkTransferHandler.Results)
+
+	customerRoute.GET("/holds/:id", app.holdHandler.Get)
+
+	customerRoute.GET("/accounts/:number/limits", app.limitHandler.Headroom)
+	customerRoute.GET("/accounts/:number/interest", app.interestHandler.Interest)
+	customerRoute.GET("/accounts/:number/statement", app.statementHandler.Statement)
+	customerRoute.GET("/accounts/:number/transactions", app.historyHandler.List)
+	customerRoute.GET("/accounts/:number/balance", app.balanceHandler.Balance)
+}
+
+// staffRoutes sets up the routes only bank staff reach, grouped by the permission their role must grant.
+// Requests from users whose role does not grant it are refused and logged.
+func (app *App) staffRoutes(groupRoute *gin.RouterGroup, idempotencyMiddleware middleware.IdempotencyMiddleware) {
+	staffRoute := groupRoute.Group("", app.authMiddleware.Authenticate())
+
+	viewRoute := staffRoute.Group("", app.authMiddleware.Authorize(model.ViewAccountsPermission))
+	viewRoute.GET("/admin/overdrafts/:number", app.overdraftHandler.Get)
+	viewRoute.GET("/admin/accounts/:number/status", app.accountStatusHandler.Get)
+
+	freezeRoute := staffRoute.Group("", app.authMiddleware.Authorize(model.FreezeAccountsPermission))
+	freezeRoute.PUT("/admin/accounts/:number/status", app.accountStatusHandler.Change)
+
+	reverseRoute := staffRoute.Group("", app.authMiddleware.Authorize(model.ReverseTransactionsPermission))
+	reverseRoute.POST("/transactions/:ref/reverse", idempotencyMiddleware.Idempotent(), app.bankTransferHandler.Reverse)
+
+	settleRoute := staffRoute.Group("", app.authMiddleware.Authorize(model.SettleHoldsPermission))
+	settleRoute.POST("/holds/:id/capture", idempotencyMiddleware.Idempotent(), app.holdHandler.Capture)
+	settleRoute.POST("/holds/:id/release", app.holdHandler.Release)
+
+	// Adjusting an account changes its terms. Balances are never set by hand, they only move through transactions,
+	// which are corrected by reversing them.
+	adjustRoute := staffRoute.Group("", app.authMiddleware.Authorize(model.AdjustAccountsPermission))
+	adjustRoute.PUT("/admin/limits/accounts/:number", app.limitHandler.SetAccountLimit)
+	adjustRoute.PUT("/admin/overdrafts/:number", app.overdraftHandler.Set)
+	adjustRoute.DELETE("/admin/overdrafts/:number", app.overdraftHandler.Remove)
+	adjustRoute.PUT("/admin/accounts/:number/product", app.interestHandler.AssignProduct)
+
+	configureRoute := staffRoute.Group("", app.authMiddleware.Authorize(model.ConfigureBankPermission))
+	configureRoute.PUT("/admin/exchange-rates", app.exchangeRateHandler.Update)
+	configureRoute.PUT("/admin/limits/tiers/:tier", app.limitHandler.SetTierLimit)
+	configureRoute.PUT("/admin/account-products/:code", app.interestHandler.SaveProduct)
+	configureRoute.PUT("/admin/fee-rules", app.feeHandler.Update)
+
+	manageRoute := staffRoute.Group("", app.authMiddleware.Authorize(model.ManageUsersPermission))
+	manageRoute.PUT("/admin/users/:username/role", app.userHandler.AssignRole)
+}
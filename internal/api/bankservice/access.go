@@ -0,0 +1,106 @@
+package bankservice
+
+import (
+	"bankingApp/internal/api/constants"
+	"bankingApp/internal/api/middleware"
+	"bankingApp/internal/model"
+	"bankingApp/internal/utility"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// accountOwnership is what a user who does not own the account of the request is denied
+const accountOwnership = "account ownership"
+
+// authoriseAccountAccess lets the owner of the account and staff whose role lets them view accounts read it.
+// The route must be authenticated by the auth middleware. Reads are authorised by the access token alone,
+// so they never count towards the transaction PIN lockout.
+func authoriseAccountAccess(c *gin.Context, account *model.Account) bool {
+	return authoriseOwnerOrStaff(c, account.UserID, constants.AccountAccessDenied)
+}
+
+// authoriseOwnerOrStaff lets the user with the ownerID and staff whose role lets them view accounts read what
+// belongs to the user, and refuses anyone else with the denied message
+func authoriseOwnerOrStaff(c *gin.Context, ownerID uint, denied string) bool {
+	claims, ok := middleware.AuthenticatedUser(c)
+	if !ok {
+		utility.HandleError(c, nil, http.StatusUnauthorized, constants.AccessTokenRequired)
+		return false
+	}
+	if claims.UserID != ownerID && !model.UserRole(claims.Role).Can(model.ViewAccountsPermission) {
+		middleware.LogAccessDenied(c, claims.Subject, claims.Role, string(model.ViewAccountsPermission))
+		utility.HandleError(c, nil, http.StatusForbidden, denied)
+		return false
+	}
+	return true
+}
+
+// tokenUser finds the user the access token of the request was issued to. The route must be authenticated
+// by the auth middleware.
+func tokenUser(c *gin.Context, users IUserRepository) (*model.User, bool) {
+	claims, ok := middleware.AuthenticatedUser(c)
+	if !ok {
+		utility.HandleError(c, nil, http.StatusUnauthorized, constants.AccessTokenRequired)
+		return nil, false
+	}
+
+	user, err := users.FindUserByUsername(claims.Subject)
+	if err != nil {
+		utility.HandleError(c, err, http.StatusInternalServerError, constants.ApplicationError)
+		return nil, false
+	}
+	if user.UserID == constants.Zero || user.UserID != claims.UserID {
+		utility.HandleError(c, nil, http.StatusUnauthorized, constants.InvalidAccessToken)
+		return nil, false
+	}
+	return &user, true
+}
+
+// findReadableAccount finds the account in the number path parameter, and lets only its owner and staff whose
+// role lets them view accounts read it
+func findReadableAccount(c *gin.Context, repository IAccountRepository) (*model.Account, bool) {
+	account, ok := findAccount(c, repository)
+	if !ok || !authoriseAccountAccess(c, account) {
+		return nil, false
+	}
+	return account, true
+}
+
+// authoriseAccountNumberAccess lets the owner of the account with the number and staff whose role lets them
+// view accounts read what is set up on it
+func authoriseAccountNumberAccess(c *gin.Context, repository IAccountRepository, number string) bool {
+	account, err := repository.GetAccountByAccountNumber(number)
+	if err != nil {
+		utility.HandleError(c, err, http.StatusInternalServerError, constants.ApplicationError)
+		return false
+	}
+	return authoriseAccountAccess(c, account)
+}
+
+// authoriseAccountOwner only lets the owner of an account change what is set up on it, like the transfers
+// scheduled from it. The route must be authenticated by the auth middleware.
+func authoriseAccountOwner(c *gin.Context, ownerID uint) bool {
+	claims, ok := middleware.AuthenticatedUser(c)
+	if !ok {
+		utility.HandleError(c, nil, http.StatusUnauthorized, constants.AccessTokenRequired)
+		return false
+	}
+	if claims.UserID != ownerID {
+		middleware.LogAccessDenied(c, claims.Subject, claims.Role, accountOwnership)
+		utility.HandleError(c, nil, http.StatusForbidden, constants.OwnerAccessDenied)
+		return false
+	}
+	return true
+}
+
+// staffUsername returns the username of the member of staff the access token of the request was issued to,
+// which the changes they make are recorded against. The route must be authenticated by the auth middleware.
+func staffUsername(c *gin.Context) (string, bool) {
+	claims, ok := middleware.AuthenticatedUser(c)
+	if !ok {
+		utility.HandleError(c, nil, http.StatusUnauthorized, constants.AccessTokenRequired)
+		return "", false
+	}
+	return claims.Subject, true
+}
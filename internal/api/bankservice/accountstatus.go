@@ -0,0 +1,134 @@
+package bankservice
+
+import (
+	"bankingApp/internal/api/constants"
+	"bankingApp/internal/model"
+	"bankingApp/internal/repository"
+	"bankingApp/internal/utility"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type IAccountStatusRepository interface {
+	FindAccountStatusChanges(accountID uint) ([]model.AccountStatusChange, error)
+}
+
+// accountStatusErrors are the status change failures reported to the client as business errors
+var accountStatusErrors = []error{
+	model.ErrAccountClosed,
+	model.ErrAccountStatusUnchanged,
+	model.ErrAccountNotEmpty,
+}
+
+// AccountStatusService lets bank staff move accounts through their lifecycle, freezing, restricting,
+// reactivating and closing them with a reason code recorded in the audit trail of the account
+type AccountStatusService struct {
+	Repository        IAccountStatusRepository
+	AccountRepository IAccountRepository
+	UnitOfWork        IUnitOfWork
+}
+
+// NewAccountStatusService creates a new instance of AccountStatusService
+func NewAccountStatusService(
+	repository IAccountStatusRepository,
+	accountRepository IAccountRepository,
+	unitOfWork IUnitOfWork) *AccountStatusService {
+	return &AccountStatusService{Repository: repository, AccountRepository: accountRepository, UnitOfWork: unitOfWork}
+}
+
+// Change handles the admin endpoint moving an account to another status. The change is recorded in the audit
+// trail of the account with its reason code and the member of staff the access token was issued to. A closed
+// account cannot be reopened, and an account can only be closed once its balance is zero and it has no held funds.
+func (s *AccountStatusService) Change(c *gin.Context) {
+	changedBy, ok := staffUsername(c)
+	if !ok {
+		return
+	}
+
+	account, ok := findAccount(c, s.AccountRepository)
+	if !ok {
+		return
+	}
+
+	r, ok := bindRequest[model.AccountStatusRequestDTO](c)
+	if !ok {
+		return
+	}
+
+	if !r.Status.IsValid() || !r.ReasonCode.IsValid() {
+		utility.HandleError(c, nil, http.StatusOK, constants.InvalidAccountStatus)
+		return
+	}
+
+	err := s.UnitOfWork.Execute(func(tx repository.ITx) error {
+		locked, err := tx.LockAccount(account.AccountID)
+		if err != nil {
+			return err
+		}
+		change, err := locked.ChangeStatus(r.Status, r.ReasonCode, changedBy, r.Reason, time.Now())
+		if err != nil {
+			return err
+		}
+		if err := tx.UpdateAccountStatus(locked); err != nil {
+			return err
+		}
+		account = locked
+		return tx.SaveAccountStatusChange(change)
+	})
+	for _, statusErr := range accountStatusErrors {
+		if errors.Is(err, statusErr) {
+			utility.HandleError(c, nil, http.StatusOK, statusErr.Error())
+			return
+		}
+	}
+	if err != nil {
+		utility.HandleError(c, err, http.StatusInternalServerError, constants.ApplicationError)
+		return
+	}
+
+	response := model.AccountStatusDTO{AccountNumber: account.AccountNumber, Status: account.Status}
+	c.JSON(http.StatusOK, utility.FormulateDataResponse(constants.AccountStatusSavedMsg, response))
+}
+
+// Get handles the admin endpoint retrieving the status of an account with its audit trail
+func (s *AccountStatusService) Get(c *gin.Context) {
+	account, ok := findAccount(c, s.AccountRepository)
+	if !ok {
+		return
+	}
+
+	changes, err := s.Repository.FindAccountStatusChanges(account.AccountID)
+	if err != nil {
+		utility.HandleError(c, err, http.StatusInternalServerError, constants.ApplicationError)
+		return
+	}
+
+	response := model.AccountStatusDTO{AccountNumber: account.AccountNumber, Status: account.Status}
+	for _, change := range changes {
+		response.Changes = append(response.Changes, model.AccountStatusChangeDTO{
+			PreviousStatus: change.PreviousStatus,
+			Status:         change.Status,
+			ReasonCode:     change.ReasonCode,
+			ChangedBy:      change.ChangedBy,
+			Reason:         change.Reason,
+			ChangedAt:      change.ChangedAt,
+		})
+	}
+	c.JSON(http.StatusOK, utility.FormulateDataResponse(constants.AccountStatusFoundMsg, response))
+}
+
+// checkAccountStatus checks that the status of the account allows it to be debited or credited
+// by a transaction of the given type
+func checkAccountStatus(account *model.Account, transactionType model.TransactionType) *transferError {
+	check := account.CheckCredit
+	if transactionType == model.DebitTransaction {
+		check = account.CheckDebit
+	}
+	if err := check(); err != nil {
+		return newTransferError(nil, http.StatusOK, err.Error())
+	}
+	return nil
+}
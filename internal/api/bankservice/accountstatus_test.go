@@ -0,0 +1,259 @@
+package bankservice
+
+import (
+	"bankingApp/internal/api/constants"
+	"bankingApp/internal/model"
+	"bankingApp/internal/utility"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+// FakeAccountStatusRepository reads the status audit trail saved through the fake unit of work
+type FakeAccountStatusRepository struct {
+	UnitOfWork *FakeUnitOfWork
+}
+
+func (f *FakeAccountStatusRepository) FindAccountStatusChanges(accountID uint) ([]model.AccountStatusChange, error) {
+	var changes []model.AccountStatusChange
+	for i := len(f.UnitOfWork.Lifecycle) - 1; i >= 0; i-- {
+		if f.UnitOfWork.Lifecycle[i].AccountID == accountID {
+			changes = append(changes, *f.UnitOfWork.Lifecycle[i])
+		}
+	}
+	return changes, nil
+}
+
+func Test_ChangeAccountStatus(t *testing.T) {
+	testCases := []struct {
+		name            string
+		accountErr      error
+		status          model.AccountStatus
+		balance         string
+		requestBody     string
+		expectedStatus  int
+		expectedMessage string
+		expectedAccount model.AccountStatus
+	}{
+		{
+			name:            "freeze",
+			status:          model.ActiveAccount,
+			balance:         "100000",
+			requestBody:     `{"status": "frozen", "reason_code": "account_compromised"}`,
+			expectedStatus:  http.StatusOK,
+			expectedMessage: constants.AccountStatusSavedMsg,
+			expectedAccount: model.FrozenAccount,
+		},
+		{
+			name:            "the member of staff is the one the access token was issued to",
+			status:          model.ActiveAccount,
+			balance:         "100000",
+			requestBody:     `{"status": "frozen", "reason_code": "account_compromised", "changed_by": "someone.else"}`,
+			expectedStatus:  http.StatusOK,
+			expectedMessage: constants.AccountStatusSavedMsg,
+			expectedAccount: model.FrozenAccount,
+		},
+		{
+			name:            "reactivate",
+			status:          model.DormantAccount,
+			balance:         "100000",
+			requestBody:     `{"status": "active", "reason_code": "resolved", "reason": "customer visited branch"}`,
+			expectedStatus:  http.StatusOK,
+			expectedMessage: constants.AccountStatusSavedMsg,
+			expectedAccount: model.ActiveAccount,
+		},
+		{
+			name:            "close an empty account",
+			status:          model.PostNoCreditAccount,
+			balance:         "0",
+			requestBody:     `{"status": "closed", "reason_code": "deceased"}`,
+			expectedStatus:  http.StatusOK,
+			expectedMessage: constants.AccountStatusSavedMsg,
+			expectedAccount: model.ClosedAccount,
+		},
+		{
+			name:            "close an account holding funds",
+			status:          model.ActiveAccount,
+			balance:         "100000",
+			requestBody:     `{"status": "closed", "reason_code": "customer_request"}`,
+			expectedStatus:  http.StatusOK,
+			expectedMessage: constants.AccountNotEmpty,
+			expectedAccount: model.ActiveAccount,
+		},
+		{
+			name:            "reopen a closed account",
+			status:          model.ClosedAccount,
+			balance:         "0",
+			requestBody:     `{"status": "active", "reason_code": "customer_request"}`,
+			expectedStatus:  http.StatusOK,
+			expectedMessage: constants.AccountAlreadyClosed,
+			expectedAccount: model.ClosedAccount,
+		},
+		{
+			name:            "same status",
+			status:          model.FrozenAccount,
+			balance:         "100000",
+			requestBody:     `{"status": "frozen", "reason_code": "legal_order"}`,
+			expectedStatus:  http.StatusOK,
+			expectedMessage: constants.AccountStatusUnchanged,
+			expectedAccount: model.FrozenAccount,
+		},
+		{
+			name:            "unknown status",
+			status:          model.ActiveAccount,
+			balance:         "100000",
+			requestBody:     `{"status": "suspended", "reason_code": "legal_order"}`,
+			expectedStatus:  http.StatusOK,
+			expectedMessage: constants.InvalidAccountStatus,
+			expectedAccount: model.ActiveAccount,
+		},
+		{
+			name:            "unknown reason code",
+			status:          model.ActiveAccount,
+			balance:         "100000",
+			requestBody:     `{"status": "frozen", "reason_code": "because"}`,
+			expectedStatus:  http.StatusOK,
+			expectedMessage: constants.InvalidAccountStatus,
+			expectedAccount: model.ActiveAccount,
+		},
+		{
+			name:            "missing reason code",
+			status:          model.ActiveAccount,
+			balance:         "100000",
+			requestBody:     `{"status": "frozen"}`,
+			expectedStatus:  http.StatusBadRequest,
+			expectedMessage: constants.BadRequestMessage,
+			expectedAccount: model.ActiveAccount,
+		},
+		{
+			name:            "unknown account",
+			accountErr:      gorm.ErrRecordNotFound,
+			status:          model.ActiveAccount,
+			balance:         "100000",
+			requestBody:     `{"status": "frozen", "reason_code": "legal_order"}`,
+			expectedStatus:  http.StatusOK,
+			expectedMessage: constants.AccountNotFound,
+			expectedAccount: model.ActiveAccount,
+		},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			// ------------ setups ------------
+			accountStatusService, unitOfWork, mockAccountRepo := createAccountStatusService()
+			account := accountWithStatus(unitOfWork.Accounts[0], tt.status)
+			account.SetBalance(amountOf(tt.balance))
+			gin.SetMode(gin.TestMode)
+
+			// ------------ expectations ------------
+			mockAccountRepo.On("GetAccountByAccountNumber", "1234567890").Return(account, tt.accountErr)
+
+			// ------------ executions -----------
+			context, recorder := newScheduledTransferContext(t, http.MethodPut,
+				"/api/v1/bank/admin/accounts/1234567890/status", []byte(tt.requestBody))
+			context.Params = gin.Params{{Key: "number", Value: "1234567890"}}
+			authenticate(t, context, "ops.user", 5, model.OpsRole)
+			accountStatusService.Change(context)
+
+			var returnedResponse struct {
+				utility.APIDataResponse
+				Data model.AccountStatusDTO `json:"data"`
+			}
+			require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &returnedResponse))
+
+			// ------------ assertions -----------
+			assert.Equal(t, tt.expectedStatus, recorder.Code)
+			assert.Equal(t, tt.expectedMessage, returnedResponse.Message)
+			assert.Equal(t, tt.expectedAccount, account.Status)
+			if tt.expectedMessage != constants.AccountStatusSavedMsg {
+				assert.Empty(t, unitOfWork.Lifecycle)
+				return
+			}
+
+			assert.Equal(t, tt.expectedAccount, returnedResponse.Data.Status)
+			require.Len(t, unitOfWork.Lifecycle, 1)
+			change := unitOfWork.Lifecycle[0]
+			assert.Equal(t, tt.status, change.PreviousStatus)
+			assert.Equal(t, tt.expectedAccount, change.Status)
+			assert.Equal(t, "ops.user", change.ChangedBy)
+		})
+	}
+}
+
+func Test_GetAccountStatus(t *testing.T) {
+	// ------------ setups ------------
+	accountStatusService, unitOfWork, mockAccountRepo := createAccountStatusService()
+	account := accountWithStatus(unitOfWork.Accounts[0], model.ActiveAccount)
+	now := time.Now()
+	for i, status := range []model.AccountStatus{model.FrozenAccount, model.PostNoDebitAccount} {
+		change, err := account.ChangeStatus(status, model.SuspectedFraudReason, "ops.user", "", now.Add(time.Duration(i)*time.Hour))
+		require.NoError(t, err)
+		require.NoError(t, unitOfWork.SaveAccountStatusChange(change))
+	}
+	gin.SetMode(gin.TestMode)
+
+	// ------------ expectations ------------
+	mockAccountRepo.On("GetAccountByAccountNumber", "1234567890").Return(account, nil)
+
+	// ------------ executions -----------
+	context, recorder := newScheduledTransferContext(t, http.MethodGet,
+		"/api/v1/bank/admin/accounts/1234567890/status", nil)
+	context.Params = gin.Params{{Key: "number", Value: "1234567890"}}
+	accountStatusService.Get(context)
+
+	var returnedResponse struct {
+		utility.APIDataResponse
+		Data model.AccountStatusDTO `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &returnedResponse))
+
+	// ------------ assertions -----------
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, constants.AccountStatusFoundMsg, returnedResponse.Message)
+	status := returnedResponse.Data
+	assert.Equal(t, model.PostNoDebitAccount, status.Status)
+	require.Len(t, status.Changes, 2)
+	assert.Equal(t, model.FrozenAccount, status.Changes[0].PreviousStatus)
+	assert.Equal(t, model.PostNoDebitAccount, status.Changes[0].Status)
+	assert.Equal(t, model.ActiveAccount, status.Changes[1].PreviousStatus)
+}
+
+func Test_AccountStatusesRestrictDebitsAndCredits(t *testing.T) {
+	testCases := []struct {
+		status        model.AccountStatus
+		allowsDebits  bool
+		allowsCredits bool
+	}{
+		{status: model.ActiveAccount, allowsDebits: true, allowsCredits: true},
+		{status: model.DormantAccount, allowsCredits: true},
+		{status: model.FrozenAccount},
+		{status: model.PostNoDebitAccount, allowsCredits: true},
+		{status: model.PostNoCreditAccount, allowsDebits: true},
+		{status: model.ClosedAccount},
+	}
+	for _, tt := range testCases {
+		t.Run(string(tt.status), func(t *testing.T) {
+			account := accountWithStatus(getMockAccount(), tt.status)
+			assert.Equal(t, tt.allowsDebits, checkAccountStatus(account, model.DebitTransaction) == nil)
+			assert.Equal(t, tt.allowsCredits, checkAccountStatus(account, model.CreditTransaction) == nil)
+		})
+	}
+}
+
+func createAccountStatusService() (*AccountStatusService, *FakeUnitOfWork, *MockAccountRepository) {
+	unitOfWork := &FakeUnitOfWork{Accounts: []*model.Account{getMockAccount()}}
+	mockAccountRepo := new(MockAccountRepository)
+	accountStatusService := NewAccountStatusService(
+		&FakeAccountStatusRepository{UnitOfWork: unitOfWork}, mockAccountRepo, unitOfWork)
+	return accountStatusService, unitOfWork, mockAccountRepo
+}
+
+func accountWithStatus(account *model.Account, status model.AccountStatus) *model.Account {
+	account.Status = status
+	return account
+}
@@ -0,0 +1,89 @@
+package bankservice
+
+import (
+	"bankingApp/internal/api/constants"
+	"bankingApp/internal/credential"
+	"bankingApp/internal/model"
+	"bankingApp/internal/utility"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// bearerTokenType is the type of every access token issued, sent back in the Authorization header
+const bearerTokenType = "Bearer"
+
+// unknownUserPasswordHash is verified against when the username is not registered, so that signing in as an
+// unknown user takes as long as signing in with an incorrect password
+var unknownUserPasswordHash, _ = credential.Hash("unknown user password")
+
+type ITokenIssuer interface {
+	Issue(username string, userID uint, role string) (string, time.Time, error)
+}
+
+// AuthService signs users in, exchanging their username and password for an access token
+type AuthService struct {
+	Repository IUserRepository
+	Tokens     ITokenIssuer
+}
+
+// NewAuthService creates a new instance of AuthService
+func NewAuthService(repository IUserRepository, tokens ITokenIssuer) *AuthService {
+	return &AuthService{Repository: repository, Tokens: tokens}
+}
+
+// Login handles the endpoint signing a user in. An unknown username and an incorrect password get the same
+// response in the same time, so the endpoint cannot be used to find out which usernames are registered.
+// Every incorrect password is counted against the user and too many in a row lock signing in for a cooldown,
+// during which even the correct password is refused.
+func (a *AuthService) Login(c *gin.Context) {
+	r, ok := bindRequest[model.LoginRequestDTO](c)
+	if !ok {
+		return
+	}
+
+	user, err := a.Repository.FindUserByUsername(r.Username)
+	if err != nil {
+		utility.HandleError(c, err, http.StatusInternalServerError, constants.ApplicationError)
+		return
+	}
+	storedPassword := unknownUserPasswordHash
+	if user.UserID != constants.Zero {
+		storedPassword = user.Password
+	}
+	verified := credential.Verify(storedPassword, r.Password)
+	if user.UserID == constants.Zero {
+		utility.HandleError(c, nil, http.StatusUnauthorized, constants.InvalidCredentials)
+		return
+	}
+
+	now := time.Now()
+	if user.LoginLocked(now) {
+		utility.HandleError(c, nil, http.StatusTooManyRequests, constants.LoginLocked)
+		return
+	}
+	if !verified {
+		if err := a.Repository.RecordFailedLogin(user.UserID, now); err != nil {
+			utility.HandleError(c, err, http.StatusInternalServerError, constants.ApplicationError)
+			return
+		}
+		utility.HandleError(c, nil, http.StatusUnauthorized, constants.InvalidCredentials)
+		return
+	}
+	if user.LoginAttempts != constants.Zero || user.LoginLockedUntil != nil {
+		if err := a.Repository.ResetLoginAttempts(user.UserID); err != nil {
+			utility.HandleError(c, err, http.StatusInternalServerError, constants.ApplicationError)
+			return
+		}
+	}
+
+	accessToken, expiresAt, err := a.Tokens.Issue(user.Username, user.UserID, string(user.Role))
+	if err != nil {
+		utility.HandleError(c, err, http.StatusInternalServerError, constants.ApplicationError)
+		return
+	}
+
+	response := model.LoginResponseDTO{AccessToken: accessToken, TokenType: bearerTokenType, ExpiresAt: expiresAt}
+	c.JSON(http.StatusOK, utility.FormulateDataResponse(constants.LoginSuccessMsg, response))
+}
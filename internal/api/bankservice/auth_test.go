@@ -0,0 +1,164 @@
+package bankservice
+
+import (
+	"bankingApp/internal/api/constants"
+	"bankingApp/internal/credential"
+	"bankingApp/internal/model"
+	"bankingApp/internal/token"
+	"bankingApp/internal/utility"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Login(t *testing.T) {
+	hashed, err := credential.Hash("correct horse")
+	require.NoError(t, err)
+	lockedUntil := time.Now().Add(time.Hour)
+	lockExpired := time.Now().Add(-time.Minute)
+
+	testCases := []struct {
+		name            string
+		storedPassword  string
+		found           bool
+		loginAttempts   int
+		lockedUntil     *time.Time
+		requestBody     string
+		expectedStatus  int
+		expectedMessage string
+		recordsFailure  bool
+		resetsAttempts  bool
+	}{
+		{
+			name:            "hashed password",
+			storedPassword:  hashed,
+			found:           true,
+			requestBody:     `{"username": "1234567890", "password": "correct horse"}`,
+			expectedStatus:  http.StatusOK,
+			expectedMessage: constants.LoginSuccessMsg,
+		},
+		{
+			name:            "password that is not hashed",
+			storedPassword:  "correct horse",
+			found:           true,
+			requestBody:     `{"username": "1234567890", "password": "correct horse"}`,
+			expectedStatus:  http.StatusUnauthorized,
+			expectedMessage: constants.InvalidCredentials,
+			recordsFailure:  true,
+		},
+		{
+			name:            "incorrect password",
+			storedPassword:  hashed,
+			found:           true,
+			requestBody:     `{"username": "1234567890", "password": "battery staple"}`,
+			expectedStatus:  http.StatusUnauthorized,
+			expectedMessage: constants.InvalidCredentials,
+			recordsFailure:  true,
+		},
+		{
+			name:            "correct password clears the incorrect attempts",
+			storedPassword:  hashed,
+			found:           true,
+			loginAttempts:   2,
+			requestBody:     `{"username": "1234567890", "password": "correct horse"}`,
+			expectedStatus:  http.StatusOK,
+			expectedMessage: constants.LoginSuccessMsg,
+			resetsAttempts:  true,
+		},
+		{
+			name:            "locked sign in refuses the correct password",
+			storedPassword:  hashed,
+			found:           true,
+			lockedUntil:     &lockedUntil,
+			requestBody:     `{"username": "1234567890", "password": "correct horse"}`,
+			expectedStatus:  http.StatusTooManyRequests,
+			expectedMessage: constants.LoginLocked,
+		},
+		{
+			name:            "expired lock is cleared by the correct password",
+			storedPassword:  hashed,
+			found:           true,
+			lockedUntil:     &lockExpired,
+			requestBody:     `{"username": "1234567890", "password": "correct horse"}`,
+			expectedStatus:  http.StatusOK,
+			expectedMessage: constants.LoginSuccessMsg,
+			resetsAttempts:  true,
+		},
+		{
+			name:            "unknown username",
+			requestBody:     `{"username": "1234567890", "password": "correct horse"}`,
+			expectedStatus:  http.StatusUnauthorized,
+			expectedMessage: constants.InvalidCredentials,
+		},
+		{
+			name:            "missing password",
+			requestBody:     `{"username": "1234567890"}`,
+			expectedStatus:  http.StatusBadRequest,
+			expectedMessage: constants.BadRequestMessage,
+		},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			// ------------ setups ------------
+			issuer, err := token.NewIssuer("secret", token.AccessTokenTTL)
+			require.NoError(t, err)
+			mockUserRepo := new(MockUserRepository)
+			authService := NewAuthService(mockUserRepo, issuer)
+			user := model.User{}
+			if tt.found {
+				user = *getMockUser()
+				user.Password = tt.storedPassword
+				user.LoginAttempts = tt.loginAttempts
+				user.LoginLockedUntil = tt.lockedUntil
+			}
+			gin.SetMode(gin.TestMode)
+
+			// ------------ expectations ------------
+			mockUserRepo.On("FindUserByUsername", "1234567890").Return(user, nil)
+			mockUserRepo.On("RecordFailedLogin", user.UserID, mock.Anything).Return(nil)
+			mockUserRepo.On("ResetLoginAttempts", user.UserID).Return(nil)
+
+			// ------------ executions -----------
+			context, recorder := newScheduledTransferContext(t, http.MethodPost, "/api/v1/bank/auth/login",
+				[]byte(tt.requestBody))
+			authService.Login(context)
+
+			var returnedResponse struct {
+				utility.APIDataResponse
+				Data model.LoginResponseDTO `json:"data"`
+			}
+			require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &returnedResponse))
+
+			// ------------ assertions -----------
+			assert.Equal(t, tt.expectedStatus, recorder.Code)
+			assert.Equal(t, tt.expectedMessage, returnedResponse.Message)
+			if !tt.recordsFailure {
+				mockUserRepo.AssertNotCalled(t, "RecordFailedLogin", mock.Anything, mock.Anything)
+			} else {
+				mockUserRepo.AssertCalled(t, "RecordFailedLogin", user.UserID, mock.Anything)
+			}
+			if !tt.resetsAttempts {
+				mockUserRepo.AssertNotCalled(t, "ResetLoginAttempts", mock.Anything)
+			} else {
+				mockUserRepo.AssertCalled(t, "ResetLoginAttempts", user.UserID)
+			}
+			if tt.expectedStatus != http.StatusOK {
+				assert.Empty(t, returnedResponse.Data.AccessToken)
+				return
+			}
+
+			assert.Equal(t, "Bearer", returnedResponse.Data.TokenType)
+			claims, err := issuer.Parse(returnedResponse.Data.AccessToken)
+			require.NoError(t, err)
+			assert.Equal(t, user.Username, claims.Subject)
+			assert.Equal(t, user.UserID, claims.UserID)
+			assert.Equal(t, claims.ExpiresAt, returnedResponse.Data.ExpiresAt.Unix())
+		})
+	}
+}
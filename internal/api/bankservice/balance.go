@@ -0,0 +1,57 @@
+package bankservice
+
+import (
+	"bankingApp/internal/api/constants"
+	"bankingApp/internal/model"
+	"bankingApp/internal/utility"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type IBalanceRepository interface {
+	SumUnsettledDebits(accountID uint) (model.BigDecimal, error)
+}
+
+// BalanceService reports the balances of an account to its owner and to staff
+type BalanceService struct {
+	Repository        IBalanceRepository
+	AccountRepository IAccountRepository
+}
+
+// NewBalanceService creates a new instance of BalanceService
+func NewBalanceService(repository IBalanceRepository, accountRepository IAccountRepository) *BalanceService {
+	return &BalanceService{Repository: repository, AccountRepository: accountRepository}
+}
+
+// Balance handles the endpoint reporting the balances of the account in the number path parameter.
+// The ledger balance is the balance of the postings made against the account. Debits awaiting the outcome
+// of the third-party provider are posted when they are made, so the ledger balance is already net of them
+// and they are reported alongside it. The available balance is what the account can spend: the ledger
+// balance less the funds held, plus the overdraft limit.
+func (b *BalanceService) Balance(c *gin.Context) {
+	account, ok := findReadableAccount(c, b.AccountRepository)
+	if !ok {
+		return
+	}
+
+	pending, err := b.Repository.SumUnsettledDebits(account.AccountID)
+	if err != nil {
+		utility.HandleError(c, err, http.StatusInternalServerError, constants.ApplicationError)
+		return
+	}
+
+	ledger := account.GetBalance()
+	available := account.AvailableBalance()
+	c.JSON(http.StatusOK, utility.FormulateDataResponse(constants.BalanceFoundMsg, model.BalanceDTO{
+		AccountNumber:    account.AccountNumber,
+		Currency:         account.Currency,
+		LedgerBalance:    &ledger,
+		AvailableBalance: &available,
+		HeldBalance:      setAmount(account.HeldBalance),
+		PendingDebits:    setAmount(pending),
+		OverdraftLimit:   setAmount(account.OverdraftLimit),
+		AsOf:             time.Now().UTC(),
+	}))
+}
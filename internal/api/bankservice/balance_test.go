@@ -0,0 +1,87 @@
+package bankservice
+
+import (
+	"bankingApp/internal/api/constants"
+	"bankingApp/internal/model"
+	"bankingApp/internal/utility"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/govalues/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// FakeBalanceRepository reports a fixed total of unsettled debits
+type FakeBalanceRepository struct {
+	Pending model.BigDecimal
+}
+
+func (f *FakeBalanceRepository) SumUnsettledDebits(accountID uint) (model.BigDecimal, error) {
+	return f.Pending, nil
+}
+
+func Test_Balance(t *testing.T) {
+	testCases := []struct {
+		name            string
+		username        string
+		userID          uint
+		role            model.UserRole
+		expectedStatus  int
+		expectedMessage string
+	}{
+		{name: "owner", username: "1234567890", userID: 1, role: model.CustomerRole, expectedStatus: http.StatusOK, expectedMessage: constants.BalanceFoundMsg},
+		{name: "staff", username: "teller", userID: 7, role: model.TellerRole, expectedStatus: http.StatusOK, expectedMessage: constants.BalanceFoundMsg},
+		{name: "another customer", username: "stranger", userID: 8, role: model.CustomerRole, expectedStatus: http.StatusForbidden, expectedMessage: constants.AccountAccessDenied},
+		{name: "not authenticated", expectedStatus: http.StatusUnauthorized, expectedMessage: constants.AccessTokenRequired},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			// ------------ setups ------------
+			account := getMockAccount()
+			account.Currency = "NGN"
+			account.HeldBalance = amountOf("250")
+			account.OverdraftLimit = amountOf("1000")
+			mockAccountRepo := new(MockAccountRepository)
+			balanceService := NewBalanceService(&FakeBalanceRepository{Pending: amountOf("75.5")}, mockAccountRepo)
+			gin.SetMode(gin.TestMode)
+
+			// ------------ expectations ------------
+			mockAccountRepo.On("GetAccountByAccountNumber", "1234567890").Return(account, nil)
+
+			// ------------ executions -----------
+			context, recorder := newScheduledTransferContext(t, http.MethodGet,
+				"/api/v1/bank/accounts/1234567890/balance", nil)
+			context.Params = gin.Params{{Key: "number", Value: "1234567890"}}
+			if tt.username != "" {
+				authenticate(t, context, tt.username, tt.userID, tt.role)
+			}
+			balanceService.Balance(context)
+
+			var returnedResponse struct {
+				utility.APIDataResponse
+				Data model.BalanceDTO `json:"data"`
+			}
+			require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &returnedResponse))
+
+			// ------------ assertions -----------
+			assert.Equal(t, tt.expectedStatus, recorder.Code)
+			assert.Equal(t, tt.expectedMessage, returnedResponse.Message)
+			if tt.expectedStatus != http.StatusOK {
+				return
+			}
+
+			balance := returnedResponse.Data
+			assert.Equal(t, "1234567890", balance.AccountNumber)
+			assert.Equal(t, "NGN", balance.Currency)
+			assert.Equal(t, 0, balance.LedgerBalance.Decimal.Cmp(decimal.MustParse("100000")))
+			assert.Equal(t, 0, balance.AvailableBalance.Decimal.Cmp(decimal.MustParse("100750")))
+			assert.Equal(t, 0, balance.HeldBalance.Decimal.Cmp(decimal.MustParse("250")))
+			assert.Equal(t, 0, balance.PendingDebits.Decimal.Cmp(decimal.MustParse("75.5")))
+			assert.Equal(t, 0, balance.OverdraftLimit.Decimal.Cmp(decimal.MustParse("1000")))
+			assert.False(t, balance.AsOf.IsZero())
+		})
+	}
+}
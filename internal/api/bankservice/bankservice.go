@@ -2,7 +2,10 @@ package bankservice // nolint:govet
 
 import (
 	"bankingApp/internal/api/constants"
+	"bankingApp/internal/api/middleware"
+	"bankingApp/internal/fee"
 	"bankingApp/internal/model"
+	"bankingApp/internal/repository"
 	"bankingApp/internal/utility"
 	"fmt"
 	"log/slog"
@@ -25,20 +28,31 @@ type IAccount interface {
 }
 
 type IAccountRepository interface {
-	UpdateAccount(account *model.Account) error
 	GetAccountByAccountNumber(number string) (*model.Account, error)
 }
 
 type IUserRepository interface {
 	FindUserByUsername(username string) (model.User, error)
 	GetUserAndAccountByAccountNumber(accountNumber string) (*model.User, *model.Account, error)
+	RecordFailedPinAttempt(userID uint, now time.Time) error
+	ResetPinAttempts(userID uint) error
+	RecordFailedLogin(userID uint, now time.Time) error
+	ResetLoginAttempts(userID uint) error
 }
 
 type ITransactionRepository interface {
 	FindTransaction(id uint) (*model.Transaction, error)
 	FindTransactionByReference(reference string) (*model.Transaction, error)
-	SaveTransaction(transaction *model.Transaction) error
-	GetLastInsertID() (uint, error)
+	FindStatusHistory(transactionID uint) ([]model.TransactionStatusHistory, error)
+	FindStaleTransactions(before time.Time, limit int) ([]model.Transaction, error)
+}
+
+type IReferenceGenerator interface {
+	NewReference() (string, error)
+}
+
+type IUnitOfWork interface {
+	Execute(fn func(tx repository.ITx) error) error
 }
 
 type IRestHttpClient interface {
@@ -47,20 +61,16 @@ type IRestHttpClient interface {
 }
 
 type BankTransferService struct {
-	Config                model.IAppConfiguration
-	TransactionRepository ITransactionRepository
-	UserRepository        IUserRepository
-	AccountRepository     IAccountRepository
-	RestHttpClient        IRestHttpClient
-}
-
-type transactionCreatedDTO struct {
-	context            *gin.Context
-	account            *model.Account
-	transactionRequest model.TransactionRequestDTO
-	statusCode         int
-	reference          string
-	err                error
+	Config                 model.IAppConfiguration
+	TransactionRepository  ITransactionRepository
+	UserRepository         IUserRepository
+	AccountRepository      IAccountRepository
+	ExchangeRateRepository IExchangeRateRepository
+	LimitRepository        ILimitRepository
+	FeeRepository          IFeeRepository
+	UnitOfWork             IUnitOfWork
+	ReferenceGenerator     IReferenceGenerator
+	RestHttpClient         IRestHttpClient
 }
 
 // NewBankService initializes a new BankTransferService with the provided dependencies.
@@ -70,18 +80,30 @@ func NewBankService(
 	transactionRepo ITransactionRepository,
 	userRepo IUserRepository,
 	accountRepo IAccountRepository,
+	exchangeRateRepo IExchangeRateRepository,
+	limitRepo ILimitRepository,
+	feeRepo IFeeRepository,
+	unitOfWork IUnitOfWork,
+	referenceGenerator IReferenceGenerator,
 	restClient IRestHttpClient) *BankTransferService {
 	return &BankTransferService{
-		Config:                config,
-		TransactionRepository: transactionRepo,
-		UserRepository:        userRepo,
-		AccountRepository:     accountRepo,
-		RestHttpClient:        restClient,
+		Config:                 config,
+		TransactionRepository:  transactionRepo,
+		UserRepository:         userRepo,
+		AccountRepository:      accountRepo,
+		ExchangeRateRepository: exchangeRateRepo,
+		LimitRepository:        limitRepo,
+		FeeRepository:          feeRepo,
+		UnitOfWork:             unitOfWork,
+		ReferenceGenerator:     referenceGenerator,
+		RestHttpClient:         restClient,
 	}
 }
 
 // StatusQuery handles the status query endpoint for checking transaction status.
-// It retrieves transaction details, makes a request to a third-party service, and returns the response.
+// It retrieves transaction details, confirms them with the third-party service when the transaction reached it,
+// and returns the lifecycle status of the transaction together with its status history.
+// It never changes the transaction; outcomes still awaited are resolved by ResolveStale.
 func (b *BankTransferService) StatusQuery(c *gin.Context) {
 	reference := c.Param("ref")
 
@@ -96,27 +118,43 @@ func (b *BankTransferService) StatusQuery(c *gin.Context) {
 		return
 	}
 
-	url := fmt.Sprintf("%s/api/v1/third-party/payments/%s/get", b.Config.ThirdPartyBaseUrl(), transaction.Reference)
+	accountID := strconv.Itoa(int(transaction.AccountID))
+	if transaction.Status != model.PendingStatus && transaction.Status != model.FailedStatus {
+		url := fmt.Sprintf("%s/api/v1/third-party/payments/%s/get", b.Config.ThirdPartyBaseUrl(), transaction.Reference)
 
-	response, statusCode, err := b.RestHttpClient.GetRequest(url, headers)
-	if err != nil {
-		utility.HandleError(c, err, http.StatusServiceUnavailable, constants.UnableToCompleteTransaction)
-		return
+		response, statusCode, err := b.RestHttpClient.GetRequest(url, headers)
+		if err != nil {
+			utility.HandleError(c, err, http.StatusServiceUnavailable, constants.UnableToCompleteTransaction)
+			return
+		}
+
+		if statusCode != http.StatusOK {
+			utility.HandleError(c, nil, http.StatusServiceUnavailable, constants.UnableToCompleteTransaction)
+			return
+		}
+
+		if id, ok := response["account_id"].(string); ok {
+			accountID = id
+		}
 	}
 
-	if statusCode != http.StatusOK {
-		utility.HandleError(c, nil, http.StatusServiceUnavailable, constants.UnableToCompleteTransaction)
+	history, err := b.TransactionRepository.FindStatusHistory(transaction.TransactionID)
+	if err != nil {
+		utility.HandleError(c, err, http.StatusInternalServerError, constants.ApplicationError)
 		return
 	}
 
 	amount := transaction.Amount.Decimal
 	apiResponse := model.ResponseDTO{
 		ThirdPartyTransactionDataDTO: model.ThirdPartyTransactionDataDTO{
-			AccountID: response["account_id"].(string),
+			AccountID: accountID,
 			Amount:    &model.BigDecimal{Decimal: amount},
+			Currency:  transaction.Currency,
 			Reference: transaction.Reference,
 		},
 		PaymentReference: transaction.PaymentReference,
+		Status:           transaction.Status,
+		StatusHistory:    statusHistoryDTOs(history),
 	}
 
 	c.JSON(http.StatusOK, utility.FormulateSuccessResponse(apiResponse))
@@ -135,30 +173,74 @@ func (b *BankTransferService) Transfer(c *gin.Context) {
 		return
 	}
 
-	err, account, complete := b.processValidation(c, t)
-	if complete {
+	apiResponse, tErr := b.transfer(t, true, model.APIChannel)
+	if tErr != nil {
+		if tErr.persisted {
+			middleware.MarkPersisted(c)
+		}
+		utility.HandleError(c, tErr.err, tErr.statusCode, tErr.message)
 		return
 	}
 
-	lastInsertID, done := b.getLastInsertID(c, err)
-	if done {
-		return
+	c.JSON(http.StatusOK, utility.FormulateSuccessResponse(*apiResponse))
+}
+
+// transferError is a failed transfer together with the HTTP status and message it is reported with.
+// persisted is set when the transfer failed after saving its transaction, so retrying it would be refused.
+type transferError struct {
+	err        error
+	statusCode int
+	message    string
+	persisted  bool
+}
+
+func newTransferError(err error, statusCode int, message string) *transferError {
+	return &transferError{err: err, statusCode: statusCode, message: message}
+}
+
+// afterSaving marks the failure as happening once the transaction of the transfer was saved
+func (t *transferError) afterSaving() *transferError {
+	t.persisted = true
+	return t
+}
+
+// transfer validates the transfer, charging it the fee of the channel it was made through, and executes it through
+// the third-party provider. The PIN is only checked when checkPIN is set; scheduled transfers had their PIN checked
+// when they were created.
+func (b *BankTransferService) transfer(
+	t model.TransactionRequestDTO,
+	checkPIN bool,
+	channel model.Channel) (*model.ResponseDTO, *transferError) {
+	account, converted, charged, tErr := b.processValidation(t, checkPIN, channel)
+	if tErr != nil {
+		return nil, tErr
+	}
+
+	reference, err := b.ReferenceGenerator.NewReference()
+	if err != nil {
+		return nil, newTransferError(err, http.StatusInternalServerError, constants.ApplicationError)
 	}
 
-	reference := fmt.Sprintf("ref%d", lastInsertID+1)
+	transaction := newPendingTransaction(t, account, converted, feeOf(charged), reference)
+	if tErr := b.createPendingTransaction(transaction, account); tErr != nil {
+		return nil, tErr
+	}
 
 	url := fmt.Sprintf("%s/api/v1/third-party/payments", b.Config.ThirdPartyBaseUrl())
-	accountID := strconv.Itoa(int(account.AccountID))
-	request := &model.ThirdPartyTransactionDataDTO{
-		AccountID: accountID,
-		Amount:    &t.Amount,
-		Reference: reference,
+	response, status, reason, err := b.submitTransaction(transaction, url)
+	if err != nil {
+		return nil, newTransferError(err, http.StatusInternalServerError, constants.ApplicationError).afterSaving()
 	}
 
-	response, statusCode, err := b.RestHttpClient.PostRequest(url, request, headers)
-	if err != nil || statusCode != http.StatusOK {
-		utility.HandleError(c, err, http.StatusInternalServerError, constants.ApplicationError)
-		return
+	if err := b.completeTransaction(transaction, status, reason); err != nil {
+		return nil, newTransferError(err, http.StatusInternalServerError, constants.ApplicationError).afterSaving()
+	}
+
+	switch status {
+	case model.UnknownStatus:
+		return nil, newTransferError(nil, http.StatusOK, constants.TransactionOutcomeUnknown).afterSaving()
+	case model.FailedStatus:
+		return nil, newTransferError(nil, http.StatusOK, constants.TransactionRejected).afterSaving()
 	}
 
 	var thirdPartyResponse model.ThirdPartyTransactionDataDTO
@@ -172,82 +254,118 @@ func (b *BankTransferService) Transfer(c *gin.Context) {
 
 	decoder, err := mapstructure.NewDecoder(config)
 	if err != nil {
-		slog.Error("map decode error", err)
+		slog.Error("map decode error", "error", err)
 	}
 
 	if err = decoder.Decode(response); err != nil {
-		utility.HandleError(c, err, http.StatusInternalServerError, constants.ApplicationError)
-		return
+		return nil, newTransferError(err, http.StatusInternalServerError, constants.ApplicationError).afterSaving()
 	}
 
-	apiResponse := model.ResponseDTO{
+	return &model.ResponseDTO{
 		ThirdPartyTransactionDataDTO: thirdPartyResponse,
 		PaymentReference:             t.Reference,
+		Status:                       transaction.Status,
+		Fee:                          feeBreakdownDTO(charged, transaction),
+	}, nil
+}
+
+// processValidation checks the transfer account, converts the amount to the account currency and works out the fee.
+// The status of the account must allow the debit or credit. A credit must cover its fee. A debit must be within
+// the limits of the account and the account must cover it together with its fee.
+func (b *BankTransferService) processValidation(
+	t model.TransactionRequestDTO,
+	checkPIN bool,
+	channel model.Channel) (*model.Account, conversion, *fee.Breakdown, *transferError) {
+	account, tErr := b.validateTransferAccount(t, checkPIN)
+	if tErr != nil {
+		return nil, conversion{}, nil, tErr
 	}
 
-	transactionCreatedDto := transactionCreatedDTO{
-		context:            c,
-		account:            account,
-		transactionRequest: t,
-		reference:          reference,
-		statusCode:         statusCode,
-		err:                err,
+	if tErr := checkAccountStatus(account, t.Type); tErr != nil {
+		return nil, conversion{}, nil, tErr
 	}
 
-	if !b.isTransactionCreated(transactionCreatedDto) {
-		return
+	converted, tErr := b.convert(t.Amount, transferCurrency(t.Currency, account), account.Currency)
+	if tErr != nil {
+		return nil, conversion{}, nil, tErr
 	}
 
-	c.JSON(http.StatusOK, utility.FormulateSuccessResponse(apiResponse))
+	charged, tErr := b.transferFee(t, account, converted.amount, channel)
+	if tErr != nil {
+		return nil, conversion{}, nil, tErr
+	}
+
+	if t.Type != model.DebitTransaction {
+		if feeOf(charged).Decimal.Cmp(converted.amount.Decimal) > 0 {
+			return nil, conversion{}, nil, newTransferError(nil, http.StatusOK, constants.FeeExceedsAmount)
+		}
+		return account, converted, charged, nil
+	}
+
+	if tErr := b.checkLimits(account, converted.amount); tErr != nil {
+		return nil, conversion{}, nil, tErr
+	}
+
+	total, err := converted.amount.Decimal.Add(feeOf(charged).Decimal)
+	if err != nil {
+		return nil, conversion{}, nil, newTransferError(err, http.StatusInternalServerError, constants.ApplicationError)
+	}
+
+	if account.IsInsufficientBalance(model.BigDecimal{Decimal: total}) {
+		return nil, conversion{}, nil, newTransferError(nil, http.StatusOK, constants.InsufficientFunds)
+	}
+
+	return account, converted, charged, nil
 }
 
-func (b *BankTransferService) processValidation(c *gin.Context, t model.TransactionRequestDTO) (error, *model.Account, bool) {
+// validateTransferAccount checks that the payment reference is unused and finds the account of the transfer,
+// checking the PIN of its owner when checkPIN is set and that the amount is valid in the transfer currency
+func (b *BankTransferService) validateTransferAccount(
+	t model.TransactionRequestDTO,
+	checkPIN bool) (*model.Account, *transferError) {
 	transaction, err := b.TransactionRepository.FindTransactionByReference(t.Reference)
 	if err != nil {
-		utility.HandleError(c, err, http.StatusInternalServerError, constants.ApplicationError)
-		return nil, nil, true
+		return nil, newTransferError(err, http.StatusInternalServerError, constants.ApplicationError)
 	}
 
 	if transaction.TransactionID != constants.Zero {
-		utility.HandleError(c, nil, http.StatusOK, constants.NotUniqueReferenceMsg)
-		return nil, nil, true
+		return nil, newTransferError(nil, http.StatusOK, constants.NotUniqueReferenceMsg)
 	}
 
 	user, account, err := b.UserRepository.GetUserAndAccountByAccountNumber(t.AccountNumber)
 	if err != nil {
-		utility.HandleError(c, err, http.StatusInternalServerError, constants.ApplicationError)
-		return nil, nil, true
+		return nil, newTransferError(err, http.StatusInternalServerError, constants.ApplicationError)
 	}
 
 	if user.UserID == constants.Zero || account.AccountID == constants.Zero {
-		utility.HandleError(c, nil, http.StatusOK, constants.UserOrAccountNotFound)
-		return nil, nil, true
+		return nil, newTransferError(nil, http.StatusOK, constants.UserOrAccountNotFound)
 	}
 
-	if t.TransactionPin != user.TransactionPin {
-		utility.HandleError(c, nil, http.StatusOK, constants.IncorrectTransactionPin)
-		return nil, nil, true
+	if checkPIN {
+		if tErr := verifyTransactionPin(b.UserRepository, user, t.TransactionPin); tErr != nil {
+			return nil, tErr
+		}
 	}
 
-	if t.Type == model.DebitTransaction && account.IsInsufficientBalance(t.Amount) {
-		utility.HandleError(c, nil, http.StatusOK, constants.InsufficientFunds)
-		return nil, nil, true
+	if tErr := checkTransferCurrency(transferCurrency(t.Currency, account), t.Amount); tErr != nil {
+		return nil, tErr
 	}
 
-	return err, account, false
+	return account, nil
 }
 
-func (b *BankTransferService) getLastInsertID(c *gin.Context, err error) (uint, bool) {
-	lastInsertID, err := b.TransactionRepository.GetLastInsertID()
+// newReference generates the internal reference of a transaction
+func (b *BankTransferService) newReference(c *gin.Context) (string, bool) {
+	reference, err := b.ReferenceGenerator.NewReference()
 	if err != nil {
 		utility.HandleError(c, err, http.StatusInternalServerError, constants.ApplicationError)
-		return 0, true
+		return "", true
 	}
-	return lastInsertID, false
+	return reference, false
 }
 
 // validateTransferRequest validates the transaction request data and handles any validation errors.
-func (b *BankTransferService) validateTransferRequest(c *gin.Context, t model.TransactionRequestDTO) error {
+func (b *BankTransferService) validateTransferRequest(c *gin.Context, t interface{}) error {
 	if errorMap, vErr := utility.ValidateRequest(t); len(errorMap) != constants.Zero || vErr != nil {
 		if vErr != nil {
 			utility.HandleError(c, vErr, http.StatusInternalServerError, constants.ApplicationError)
@@ -258,68 +376,3 @@ func (b *BankTransferService) validateTransferRequest(c *gin.Context, t model.Tr
 	}
 	return nil
 }
-
-// isTransactionCreated checks if a transaction was successfully created and updates account and transaction details accordingly.
-func (b *BankTransferService) isTransactionCreated(t transactionCreatedDTO) bool {
-	if b.isSuccessfulTransaction(t.transactionRequest, t.account, t.context) {
-		if err := b.AccountRepository.UpdateAccount(t.account); err != nil {
-			slog.Error("error in updating account balance", t.err)
-			utility.InternalServerError(t.context)
-			return false
-		}
-
-		transaction := &model.Transaction{
-			AccountID:        t.account.AccountID,
-			Amount:           t.transactionRequest.Amount,
-			Type:             t.transactionRequest.Type,
-			Success:          t.statusCode == http.StatusOK,
-			Reference:        t.reference,
-			PaymentReference: t.transactionRequest.Reference,
-			TransactionTime:  time.Now(),
-			TimestampData: model.TimestampData{
-				CreatedAt: time.Now(),
-			},
-		}
-
-		if err := b.TransactionRepository.SaveTransaction(transaction); err != nil {
-			slog.Error("error in save transaction", err)
-			utility.InternalServerError(t.context)
-			return false
-		}
-		return true
-	}
-	return false
-}
-
-// isSuccessfulTransaction processes the transaction based on its type (debit or credit) and updates the account balance.
-func (b *BankTransferService) isSuccessfulTransaction(t model.TransactionRequestDTO, a *model.Account, ctx *gin.Context) bool {
-	switch t.Type {
-	case model.DebitTransaction:
-		if err := b.handleDebit(t.Amount, a); err != nil {
-			slog.Error("debit transaction failed: ", err) //nolint:govet
-			utility.InternalServerError(ctx)
-			return false
-		}
-	case model.CreditTransaction:
-		if err := b.handleCredit(t.Amount, a); err != nil {
-			slog.Error("credit transaction failed: ", err) //nolint:govet
-			utility.InternalServerError(ctx)
-			return false
-		}
-	default:
-		slog.Error("invalid transaction type")
-		utility.InternalServerError(ctx)
-		return false
-	}
-	return true
-}
-
-// handleDebit updates the account balance by withdrawing the specified amount for a debit transaction.
-func (b *BankTransferService) handleDebit(amount model.BigDecimal, account *model.Account) error {
-	return account.Withdraw(amount)
-}
-
-// handleCredit updates the account balance by depositing the specified amount for a credit transaction.
-func (b *BankTransferService) handleCredit(amount model.BigDecimal, account *model.Account) error {
-	return account.Deposit(amount)
-}
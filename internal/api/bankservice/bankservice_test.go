@@ -2,19 +2,27 @@ package bankservice //nolint:typecheck
 
 import (
 	"bankingApp/internal/api/constants"
+	"bankingApp/internal/ledger"
 	"bankingApp/internal/model"
+	"bankingApp/internal/repository"
 	"bankingApp/internal/utility"
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/govalues/decimal"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
 )
 
 type (
@@ -24,6 +32,25 @@ type (
 	MockAccountRepository     struct{ mock.Mock }
 	MockRestHttpClient        struct{ mock.Mock }
 
+	// SequentialReferenceGenerator hands out ref1, ref2, ... like the old last-insert-ID references
+	SequentialReferenceGenerator struct{ next int }
+
+	// FakeUnitOfWork applies units of work to in-memory accounts, standing in for the database transaction
+	FakeUnitOfWork struct {
+		Accounts     []*model.Account
+		PostingError error
+		Entries      []*model.JournalEntry
+		Transactions []*model.Transaction
+		History      []model.TransactionStatusHistory
+		Holds        []*model.Hold
+		Changes      []*model.OverdraftChange
+		Accruals     []*model.OverdraftInterestAccrual
+		Interest     []*model.InterestAccrual
+		Lifecycle    []*model.AccountStatusChange
+		// Usage is the limit usage read within the unit of work, after the account is locked
+		Usage model.LimitUsage
+	}
+
 	MockAccount struct {
 		Balance model.BigDecimal
 		mock.Mock
@@ -49,6 +76,17 @@ func (a *MockConfig) MaximumIdleConnection() int { return a.Called().Get(0).(int
 func (a *MockConfig) MaximumIdleTime() int       { return a.Called().Get(0).(int) }
 func (a *MockConfig) MaximumTime() int           { return a.Called().Get(0).(int) }
 func (a *MockConfig) JwtSecret() string          { return a.Called().Get(0).(string) }
+func (a *MockConfig) ReferenceFormat() string    { return a.Called().Get(0).(string) }
+func (a *MockConfig) ReferenceNodeID() int64     { return a.Called().Get(0).(int64) }
+func (a *MockConfig) ReferencePrefix() string    { return a.Called().Get(0).(string) }
+func (a *MockConfig) SchedulerInterval() int     { return a.Called().Get(0).(int) }
+func (a *MockConfig) ExchangeRatesFile() string  { return a.Called().Get(0).(string) }
+func (a *MockConfig) DefaultCurrency() string    { return a.Called().Get(0).(string) }
+func (a *MockConfig) PinMaxAttempts() int        { return a.Called().Get(0).(int) }
+func (a *MockConfig) PinLockoutSeconds() int     { return a.Called().Get(0).(int) }
+func (a *MockConfig) LoginMaxAttempts() int      { return a.Called().Get(0).(int) }
+func (a *MockConfig) LoginLockoutSeconds() int   { return a.Called().Get(0).(int) }
+func (a *MockConfig) NotificationsFile() string  { return a.Called().Get(0).(string) }
 
 func (w *GinResponseWriter) Write(data []byte) (int, error) {
 	w.Body = append(w.Body, data...)
@@ -65,14 +103,30 @@ func (u *MockUserRepository) FindUserByUsername(username string) (model.User, er
 	return args.Get(0).(model.User), args.Error(1)
 }
 
+func (u *MockUserRepository) RecordFailedPinAttempt(userID uint, now time.Time) error {
+	return u.Called(userID, now).Error(0)
+}
+
+func (u *MockUserRepository) ResetPinAttempts(userID uint) error {
+	return u.Called(userID).Error(0)
+}
+
+func (u *MockUserRepository) RecordFailedLogin(userID uint, now time.Time) error {
+	return u.Called(userID, now).Error(0)
+}
+
+func (u *MockUserRepository) ResetLoginAttempts(userID uint) error {
+	return u.Called(userID).Error(0)
+}
+
 func (m *MockTransactionRepository) FindTransaction(id uint) (*model.Transaction, error) {
 	args := m.Called(id)
 	return args.Get(0).(*model.Transaction), args.Error(1)
 }
 
-func (m *MockTransactionRepository) SaveTransaction(transaction *model.Transaction) error {
-	args := m.Called(transaction)
-	return args.Error(0)
+func (m *MockTransactionRepository) FindStatusHistory(transactionID uint) ([]model.TransactionStatusHistory, error) {
+	args := m.Called(transactionID)
+	return args.Get(0).([]model.TransactionStatusHistory), args.Error(1)
 }
 
 func (m *MockTransactionRepository) FindTransactionByReference(reference string) (*model.Transaction, error) {
@@ -80,14 +134,9 @@ func (m *MockTransactionRepository) FindTransactionByReference(reference string)
 	return args.Get(0).(*model.Transaction), args.Error(1)
 }
 
-func (m *MockTransactionRepository) GetLastInsertID() (uint, error) {
-	args := m.Called()
-	return args.Get(0).(uint), args.Error(1)
-}
-
-func (a *MockAccountRepository) UpdateAccount(account *model.Account) error {
-	args := a.Called(account)
-	return args.Error(0)
+func (m *MockTransactionRepository) FindStaleTransactions(before time.Time, limit int) ([]model.Transaction, error) {
+	args := m.Called(before, limit)
+	return args.Get(0).([]model.Transaction), args.Error(1)
 }
 
 func (a *MockAccountRepository) GetAccountByAccountNumber(number string) (*model.Account, error) {
@@ -95,6 +144,183 @@ func (a *MockAccountRepository) GetAccountByAccountNumber(number string) (*model
 	return args.Get(0).(*model.Account), args.Error(1)
 }
 
+func (g *SequentialReferenceGenerator) NewReference() (string, error) {
+	g.next++
+	return fmt.Sprintf("ref%d", g.next), nil
+}
+
+func (f *FakeUnitOfWork) Execute(fn func(tx repository.ITx) error) error {
+	return fn(f)
+}
+
+func (f *FakeUnitOfWork) LockAccount(accountID uint) (*model.Account, error) {
+	for _, account := range f.Accounts {
+		if account != nil && account.AccountID == accountID {
+			return account, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (f *FakeUnitOfWork) PostJournalEntry(entry *model.JournalEntry) error {
+	return f.post(entry, ledger.Apply)
+}
+
+func (f *FakeUnitOfWork) PostCharge(entry *model.JournalEntry) error {
+	return f.post(entry, ledger.ApplyCharge)
+}
+
+func (f *FakeUnitOfWork) post(entry *model.JournalEntry, apply func(*model.Account, model.Posting) error) error {
+	if f.PostingError != nil {
+		return f.PostingError
+	}
+	if err := ledger.Validate(entry); err != nil {
+		return err
+	}
+	for _, posting := range entry.Postings {
+		if posting.AccountID == 0 {
+			continue
+		}
+		account, err := f.LockAccount(posting.AccountID)
+		if err != nil {
+			return err
+		}
+		if err := apply(account, posting); err != nil {
+			return err
+		}
+	}
+	f.Entries = append(f.Entries, entry)
+	return nil
+}
+
+func (f *FakeUnitOfWork) SaveTransaction(transaction *model.Transaction) error {
+	if transaction.TransactionID == 0 {
+		transaction.TransactionID = uint(len(f.Transactions) + 100)
+	}
+	f.Transactions = append(f.Transactions, transaction)
+	f.History = append(f.History, model.TransactionStatusHistory{ToStatus: transaction.Status})
+	return nil
+}
+
+func (f *FakeUnitOfWork) UpdateTransactionStatus(
+	transaction *model.Transaction,
+	status model.TransactionStatus,
+	reason string) error {
+	from := transaction.Status
+	if err := transaction.TransitionTo(status); err != nil {
+		return err
+	}
+	f.History = append(f.History, model.TransactionStatusHistory{FromStatus: from, ToStatus: status, Reason: reason})
+	return nil
+}
+
+func (f *FakeUnitOfWork) LockTransaction(transactionID uint) (*model.Transaction, error) {
+	for _, transaction := range f.Transactions {
+		if transaction.TransactionID == transactionID {
+			return transaction, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (f *FakeUnitOfWork) UpdateReversedAmount(*model.Transaction) error {
+	return nil
+}
+
+func (f *FakeUnitOfWork) UpdateHeldBalance(*model.Account) error {
+	return nil
+}
+
+func (f *FakeUnitOfWork) SaveHold(hold *model.Hold) error {
+	if hold.HoldID == 0 {
+		hold.HoldID = uint(len(f.Holds) + 1)
+	}
+	f.Holds = append(f.Holds, hold)
+	return nil
+}
+
+func (f *FakeUnitOfWork) LockHold(holdID uint) (*model.Hold, error) {
+	for _, hold := range f.Holds {
+		if hold.HoldID == holdID {
+			return hold, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (f *FakeUnitOfWork) UpdateHold(*model.Hold) error {
+	return nil
+}
+
+func (f *FakeUnitOfWork) FindLimitUsage(uint, time.Time, time.Time) (model.LimitUsage, error) {
+	return f.Usage, nil
+}
+
+func (f *FakeUnitOfWork) UpdateOverdraft(*model.Account) error {
+	return nil
+}
+
+func (f *FakeUnitOfWork) SaveOverdraftChange(change *model.OverdraftChange) error {
+	f.Changes = append(f.Changes, change)
+	return nil
+}
+
+func (f *FakeUnitOfWork) UpdateAccountStatus(*model.Account) error {
+	return nil
+}
+
+func (f *FakeUnitOfWork) SaveAccountStatusChange(change *model.AccountStatusChange) error {
+	f.Lifecycle = append(f.Lifecycle, change)
+	return nil
+}
+
+func (f *FakeUnitOfWork) SaveOverdraftAccrual(accrual *model.OverdraftInterestAccrual) error {
+	f.Accruals = append(f.Accruals, accrual)
+	return nil
+}
+
+func (f *FakeUnitOfWork) SaveInterestAccrual(accrual *model.InterestAccrual) error {
+	if accrual.InterestAccrualID == 0 {
+		accrual.InterestAccrualID = uint(len(f.Interest) + 1)
+	}
+	f.Interest = append(f.Interest, accrual)
+	return nil
+}
+
+func (f *FakeUnitOfWork) LockUncapitalisedAccruals(accountID uint, before time.Time) ([]model.InterestAccrual, error) {
+	var accruals []model.InterestAccrual
+	for _, accrual := range f.Interest {
+		if accrual.AccountID == accountID && accrual.CapitalisedAt == nil && accrual.AccrualDate.Before(before) {
+			accruals = append(accruals, *accrual)
+		}
+	}
+	return accruals, nil
+}
+
+func (f *FakeUnitOfWork) MarkAccrualsCapitalised(accruals []model.InterestAccrual, transactionID *uint, at time.Time) error {
+	for _, capitalised := range accruals {
+		for _, accrual := range f.Interest {
+			if accrual.InterestAccrualID == capitalised.InterestAccrualID {
+				accrual.TransactionID, accrual.CapitalisedAt = transactionID, &at
+			}
+		}
+	}
+	return nil
+}
+
+func (f *FakeUnitOfWork) UpdateInterestCarried(*model.Account) error {
+	return nil
+}
+
+// statuses returns the statuses the transactions went through, in order
+func (f *FakeUnitOfWork) statuses() []model.TransactionStatus {
+	var statuses []model.TransactionStatus
+	for _, h := range f.History {
+		statuses = append(statuses, h.ToStatus)
+	}
+	return statuses
+}
+
 func (m *MockRestHttpClient) GetRequest(
 	url string,
 	headers map[string]string) (map[string]interface{}, int, error) {
@@ -145,12 +371,23 @@ func (m *MockAccount) IsInsufficientBalance(amount model.BigDecimal) bool {
 
 func Test_NewBankService(t *testing.T) {
 	mockConfig, mockTransactionRepo, mockUserRepo, mockAccountRepo, mockRestClient, _ := setupMocks()
-	bankService := NewBankService(mockConfig, mockTransactionRepo, mockUserRepo, mockAccountRepo, mockRestClient)
+	unitOfWork := new(FakeUnitOfWork)
+	referenceGenerator := new(SequentialReferenceGenerator)
+	exchangeRateRepo := new(MockExchangeRateRepository)
+	limitRepo := new(FakeLimitRepository)
+	feeRepo := new(FakeFeeRepository)
+	bankService := NewBankService(mockConfig, mockTransactionRepo, mockUserRepo, mockAccountRepo,
+		exchangeRateRepo, limitRepo, feeRepo, unitOfWork, referenceGenerator, mockRestClient)
 	assert.NotNil(t, bankService)
 	assert.Equal(t, mockConfig, bankService.Config)
 	assert.Equal(t, mockTransactionRepo, bankService.TransactionRepository)
 	assert.Equal(t, mockUserRepo, bankService.UserRepository)
 	assert.Equal(t, mockAccountRepo, bankService.AccountRepository)
+	assert.Equal(t, exchangeRateRepo, bankService.ExchangeRateRepository)
+	assert.Equal(t, limitRepo, bankService.LimitRepository)
+	assert.Equal(t, feeRepo, bankService.FeeRepository)
+	assert.Equal(t, unitOfWork, bankService.UnitOfWork)
+	assert.Equal(t, referenceGenerator, bankService.ReferenceGenerator)
 	assert.Equal(t, mockRestClient, bankService.RestHttpClient)
 }
 
@@ -170,6 +407,7 @@ func Test_StatusQuery(t *testing.T) {
 		expectedResponse utility.APIResponse
 		expectedStatus   int
 		mockConfig       model.IAppConfiguration
+		expectedStatuses []model.TransactionStatus
 	}{
 		{
 			name:             "Happy case",
@@ -225,12 +463,43 @@ func Test_StatusQuery(t *testing.T) {
 			restError:        nil,
 			mockAccount:      getMockAccount(),
 		},
+		{
+			name:             "Unknown outcome is reported without being resolved",
+			reference:        "289192938929293",
+			mockTransaction:  getMockTransactionWithStatus(model.UnknownStatus),
+			restResponse:     getSuccessThirdPartyResponse(),
+			restStatusCode:   http.StatusOK,
+			expectedResponse: getExpectedResponseWithStatus(amount, model.UnknownStatus),
+			expectedStatus:   http.StatusOK,
+			config:           getMockConfig(),
+		},
+		{
+			name:             "Malformed third-party account is ignored",
+			reference:        "289192938929293",
+			mockTransaction:  getMockFoundTransaction(),
+			restResponse:     map[string]interface{}{"account_id": 1.0, "reference": "ref1"},
+			restStatusCode:   http.StatusOK,
+			expectedResponse: getExpectedResponse(amount),
+			expectedStatus:   http.StatusOK,
+			config:           getMockConfig(),
+		},
+		{
+			name:             "Pending transaction is reported without calling the third-party",
+			reference:        "289192938929293",
+			mockTransaction:  getMockTransactionWithStatus(model.PendingStatus),
+			restStatusCode:   http.StatusInternalServerError,
+			expectedResponse: getExpectedResponseWithStatus(amount, model.PendingStatus),
+			expectedStatus:   http.StatusOK,
+			config:           getMockConfig(),
+		},
 	}
 	for _, tt := range testCases {
 		t.Run(tt.name, func(t *testing.T) {
 			// ------------ setups ------------
 			mockConfig, mockTransactionRepo, mockUserRepo, mockAccountRepo, mockRestClient, _ := setupMocks()
 			bankService := createBankService(mockConfig, mockTransactionRepo, mockUserRepo, mockAccountRepo, mockRestClient)
+			unitOfWork := new(FakeUnitOfWork)
+			bankService.UnitOfWork = unitOfWork
 
 			gin.SetMode(gin.TestMode)
 
@@ -240,6 +509,9 @@ func Test_StatusQuery(t *testing.T) {
 			mockTransactionRepo.
 				On("FindTransactionByReference", tt.reference).Return(tt.mockTransaction, tt.dbError)
 
+			mockTransactionRepo.
+				On("FindStatusHistory", mock.Anything).Return([]model.TransactionStatusHistory{}, nil)
+
 			mockRestClient.
 				On("GetRequest", mock.Anything, mock.Anything).Return(tt.restResponse, tt.restStatusCode, tt.restError)
 
@@ -266,6 +538,90 @@ func Test_StatusQuery(t *testing.T) {
 			assert.Equal(t, tt.expectedResponse.Success, returnedResponse.Success)
 			assert.Equal(t, tt.expectedResponse.Data, returnedResponse.Data)
 			assert.Equal(t, tt.expectedResponse.Message, returnedResponse.Message)
+			assert.Equal(t, tt.expectedStatuses, unitOfWork.statuses())
+		})
+	}
+}
+
+func Test_ResolveStale(t *testing.T) {
+	testCases := []struct {
+		name            string
+		status          model.TransactionStatus
+		restStatusCode  int
+		restError       error
+		expectedStatus  model.TransactionStatus
+		expectedBalance string
+	}{
+		{
+			name:            "Pending transaction never sent is failed and its funds returned",
+			status:          model.PendingStatus,
+			expectedStatus:  model.FailedStatus,
+			expectedBalance: "100000",
+		},
+		{
+			name:            "Submitted transaction confirmed by the third-party succeeds",
+			status:          model.SubmittedStatus,
+			restStatusCode:  http.StatusOK,
+			expectedStatus:  model.SucceededStatus,
+			expectedBalance: "99900",
+		},
+		{
+			name:            "Unknown transaction the third-party never received is failed and its funds returned",
+			status:          model.UnknownStatus,
+			restStatusCode:  http.StatusNotFound,
+			expectedStatus:  model.FailedStatus,
+			expectedBalance: "100000",
+		},
+		{
+			name:            "Unreachable third-party leaves the transaction for the next run",
+			status:          model.SubmittedStatus,
+			restError:       errors.New("unable to reach server"),
+			expectedStatus:  model.SubmittedStatus,
+			expectedBalance: "99900",
+		},
+		{
+			name:            "Third-party server error leaves the transaction for the next run",
+			status:          model.UnknownStatus,
+			restStatusCode:  http.StatusServiceUnavailable,
+			expectedStatus:  model.UnknownStatus,
+			expectedBalance: "99900",
+		},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			// ------------ setups ------------
+			mockConfig, mockTransactionRepo, mockUserRepo, mockAccountRepo, mockRestClient, _ := setupMocks()
+			bankService := createBankService(mockConfig, mockTransactionRepo, mockUserRepo, mockAccountRepo, mockRestClient)
+			account := getMockAccount()
+			account.Balance = model.BigDecimal{Decimal: decimal.MustParse("99900")}
+			unitOfWork := &FakeUnitOfWork{Accounts: []*model.Account{account}}
+			bankService.UnitOfWork = unitOfWork
+			transaction := getMockTransactionWithStatus(tt.status)
+			transaction.Type = model.DebitTransaction
+			now := time.Now()
+
+			// ------------ expectations ------------
+			mockConfig.On("ThirdPartyBaseUrl").Return("http://third-party")
+			mockTransactionRepo.
+				On("FindStaleTransactions", now.Add(-staleTransactionAge), staleTransactionBatchSize).
+				Return([]model.Transaction{*transaction}, nil)
+			mockRestClient.
+				On("GetRequest", "http://third-party/api/v1/third-party/payments/ref1/get", mock.Anything).
+				Return(map[string]interface{}{}, tt.restStatusCode, tt.restError)
+
+			// ------------ executions -----------
+			require.NoError(t, bankService.ResolveStale(context.Background(), now))
+
+			// ------------ assertions -----------
+			if tt.expectedStatus != tt.status {
+				assert.Equal(t, []model.TransactionStatus{tt.expectedStatus}, unitOfWork.statuses())
+			} else {
+				assert.Empty(t, unitOfWork.History)
+			}
+			if tt.status == model.PendingStatus {
+				mockRestClient.AssertNotCalled(t, "GetRequest", mock.Anything, mock.Anything)
+			}
+			assert.Equal(t, 0, account.Balance.Decimal.Cmp(decimal.MustParse(tt.expectedBalance)))
 		})
 	}
 }
@@ -284,6 +640,7 @@ func Test_Transfer(t *testing.T) {
 		restStatusCode            int
 		restError                 error
 		dbError                   error
+		ledgerError               error
 		mockUser                  *model.User
 		config                    model.IAppConfiguration
 		expectedResponse          utility.APIResponse
@@ -294,6 +651,7 @@ func Test_Transfer(t *testing.T) {
 		transactionType           model.TransactionType
 		transactionTypeSuccessful bool
 		expectedBalance           model.BigDecimal
+		expectedStatuses          []model.TransactionStatus
 	}{
 		{
 			name:             "successful debit test case",
@@ -318,6 +676,9 @@ func Test_Transfer(t *testing.T) {
 			transactionTypeSuccessful: true,
 			transactionType:           model.DebitTransaction,
 			expectedBalance:           expectedBalance,
+			expectedStatuses: []model.TransactionStatus{
+				model.PendingStatus, model.SubmittedStatus, model.SucceededStatus,
+			},
 		},
 		{
 			name:             "check DB balance is updated",
@@ -430,6 +791,60 @@ func Test_Transfer(t *testing.T) {
 			transactionTypeSuccessful: false,
 			transactionType:           model.DebitTransaction,
 		},
+		{
+			name:             "post-no-debit account rejects debit test case",
+			mockTransaction:  getMockNotFoundTransaction(),
+			mockAccount:      accountWithStatus(getMockAccount(), model.PostNoDebitAccount),
+			expectedResponse: getErrorResponse(constants.AccountDebitsNotAllowed),
+			expectedStatus:   http.StatusOK,
+			config:           getMockConfig(),
+			requestBody: getTransactionRequest(
+				"1234567890",
+				"johndoe",
+				"1234",
+				"289192938929293",
+				model.DebitTransaction,
+				amount),
+			mockUser:        getMockUser(),
+			transactionType: model.DebitTransaction,
+		},
+		{
+			name:             "post-no-debit account accepts credit test case",
+			mockTransaction:  getMockNotFoundTransaction(),
+			restResponse:     getSuccessThirdPartyResponse(),
+			restStatusCode:   http.StatusOK,
+			mockAccount:      accountWithStatus(getMockAccount(), model.PostNoDebitAccount),
+			expectedResponse: getExpectedResponse(amount),
+			expectedStatus:   http.StatusOK,
+			config:           getMockConfig(),
+			expectedBalance:  getExpectedCreditBalance(),
+			requestBody: getTransactionRequest(
+				"1234567890",
+				"johndoe",
+				"1234",
+				"289192938929293",
+				model.CreditTransaction,
+				amount),
+			mockUser:        getMockUser(),
+			transactionType: model.CreditTransaction,
+		},
+		{
+			name:             "frozen account rejects credit test case",
+			mockTransaction:  getMockNotFoundTransaction(),
+			mockAccount:      accountWithStatus(getMockAccount(), model.FrozenAccount),
+			expectedResponse: getErrorResponse(constants.AccountCreditsNotAllowed),
+			expectedStatus:   http.StatusOK,
+			config:           getMockConfig(),
+			requestBody: getTransactionRequest(
+				"1234567890",
+				"johndoe",
+				"1234",
+				"289192938929293",
+				model.CreditTransaction,
+				amount),
+			mockUser:        getMockUser(),
+			transactionType: model.CreditTransaction,
+		},
 		{
 			name:             "find transaction throws DB error test case",
 			mockTransaction:  nil,
@@ -499,11 +914,31 @@ func Test_Transfer(t *testing.T) {
 			transactionType: model.DebitTransaction,
 		},
 		{
-			name:             "API call returns error",
+			name:             "insufficient funds when posting to the ledger test case",
+			mockTransaction:  getMockNotFoundTransaction(),
+			restResponse:     getSuccessThirdPartyResponse(),
+			restStatusCode:   http.StatusOK,
+			ledgerError:      model.ErrInsufficientFunds,
+			mockAccount:      getMockAccount(),
+			expectedResponse: getErrorResponse(constants.InsufficientFunds),
+			expectedStatus:   http.StatusOK,
+			config:           getMockConfig(),
+			requestBody: getTransactionRequest(
+				"1234567890",
+				"johndoe",
+				"1234",
+				"289192938929293",
+				model.DebitTransaction,
+				amount),
+			mockUser:        getMockUser(),
+			transactionType: model.DebitTransaction,
+		},
+		{
+			name:             "API call returns error leaves the outcome unknown",
 			mockTransaction:  getMockNotFoundTransaction(),
 			dbError:          nil,
-			expectedResponse: *utility.FormulateErrorResponse(constants.ApplicationError),
-			expectedStatus:   http.StatusInternalServerError,
+			expectedResponse: getErrorResponse(constants.TransactionOutcomeUnknown),
+			expectedStatus:   http.StatusOK,
 			config:           getMockConfig(),
 			restStatusCode:   0,
 			restError:        errors.New("unable to reach server"),
@@ -516,6 +951,32 @@ func Test_Transfer(t *testing.T) {
 				"289192938929293",
 				model.DebitTransaction,
 				amount),
+			expectedBalance: expectedBalance,
+			expectedStatuses: []model.TransactionStatus{
+				model.PendingStatus, model.SubmittedStatus, model.UnknownStatus,
+			},
+		},
+		{
+			name:             "third-party rejects debit and funds are returned",
+			mockTransaction:  getMockNotFoundTransaction(),
+			restResponse:     map[string]interface{}{},
+			restStatusCode:   http.StatusUnprocessableEntity,
+			expectedResponse: *utility.FormulateErrorResponse(constants.TransactionRejected),
+			expectedStatus:   http.StatusOK,
+			config:           getMockConfig(),
+			mockAccount:      getMockAccount(),
+			mockUser:         getMockUser(),
+			requestBody: getTransactionRequest(
+				"1234567890",
+				"johndoe",
+				"1234",
+				"289192938929293",
+				model.DebitTransaction,
+				amount),
+			expectedBalance: getMockAccount().Balance,
+			expectedStatuses: []model.TransactionStatus{
+				model.PendingStatus, model.SubmittedStatus, model.FailedStatus,
+			},
 		},
 	}
 	for _, tt := range testCases {
@@ -523,6 +984,8 @@ func Test_Transfer(t *testing.T) {
 			// ------------ setups ------------
 			mockConfig, mockTransactionRepo, mockUserRepo, mockAccountRepo, mockRestClient, mockAccount := setupMocks()
 			bankService := createBankService(mockConfig, mockTransactionRepo, mockUserRepo, mockAccountRepo, mockRestClient)
+			unitOfWork := &FakeUnitOfWork{Accounts: []*model.Account{tt.mockAccount}, PostingError: tt.ledgerError}
+			bankService.UnitOfWork = unitOfWork
 
 			gin.SetMode(gin.TestMode)
 
@@ -534,21 +997,13 @@ func Test_Transfer(t *testing.T) {
 			mockTransactionRepo.
 				On("FindTransactionByReference", mock.Anything).Return(tt.mockTransaction, tt.dbError)
 
-			mockTransactionRepo.
-				On("GetLastInsertID").Return(uint(1), tt.dbError)
-
-			mockTransactionRepo.
-				On("SaveTransaction", mock.Anything).Return(tt.dbError)
-
-			mockAccountRepo.
-				On("UpdateAccount", mock.Anything).Return(tt.dbError)
-
 			mockRestClient.
 				On("PostRequest", mock.Anything, mock.Anything, mock.Anything).
 				Return(tt.restResponse, tt.restStatusCode, tt.restError)
 
 			mockUserRepo.
 				On("GetUserAndAccountByAccountNumber", mock.Anything).Return(tt.mockUser, tt.mockAccount, nil)
+			mockUserRepo.On("RecordFailedPinAttempt", uint(1), mock.Anything).Return(nil)
 
 			mockAccount.On("IsInsufficientBalance", mock.Anything).Return(tt.insufficientBalance)
 
@@ -593,9 +1048,12 @@ func Test_Transfer(t *testing.T) {
 
 			// get balance after debit
 			var balance model.BigDecimal
-			if tt.mockAccount != nil && returnedResponse.Success {
+			if tt.mockAccount != nil && (returnedResponse.Success || tt.expectedStatuses != nil) {
 				balance = tt.mockAccount.GetBalance()
-				assert.Equal(t, tt.expectedBalance, balance)
+				assert.Equal(t, 0, tt.expectedBalance.Decimal.Cmp(balance.Decimal))
+			}
+			if tt.expectedStatuses != nil {
+				assert.Equal(t, tt.expectedStatuses, unitOfWork.statuses())
 			}
 
 			assert.Equal(t, tt.expectedResponse.Success, returnedResponse.Success)
@@ -606,6 +1064,7 @@ func Test_Transfer(t *testing.T) {
 }
 
 // helper functions
+
 func getExpectedBalance() model.BigDecimal {
 	expectedBalanceVal, _ := decimal.New(9990000, 2)
 	expectedBalance := model.BigDecimal{Decimal: expectedBalanceVal}
@@ -631,13 +1090,19 @@ func getMockConfig() model.IAppConfiguration {
 }
 
 func getMockFoundTransaction() *model.Transaction {
+	return getMockTransactionWithStatus(model.SucceededStatus)
+}
+
+func getMockTransactionWithStatus(status model.TransactionStatus) *model.Transaction {
 	val, _ := decimal.NewFromFloat64(100.00)
 	amount := model.BigDecimal{Decimal: val}
 	return &model.Transaction{
 		TransactionID:    1,
+		AccountID:        1,
 		Reference:        "ref1",
 		PaymentReference: "289192938929293",
 		Amount:           amount,
+		Status:           status,
 	}
 }
 
@@ -649,6 +1114,10 @@ func getMockNotFoundTransaction() *model.Transaction {
 }
 
 func getExpectedResponse(amount model.BigDecimal) utility.APIResponse {
+	return getExpectedResponseWithStatus(amount, model.SucceededStatus)
+}
+
+func getExpectedResponseWithStatus(amount model.BigDecimal, status model.TransactionStatus) utility.APIResponse {
 	return utility.APIResponse{
 		Data: &model.ResponseDTO{
 			ThirdPartyTransactionDataDTO: model.ThirdPartyTransactionDataDTO{
@@ -657,6 +1126,7 @@ func getExpectedResponse(amount model.BigDecimal) utility.APIResponse {
 				Reference: "ref1",
 			},
 			PaymentReference: "289192938929293",
+			Status:           status,
 		},
 		Success: true,
 		Message: constants.SuccessfulTransactionMsg,
@@ -693,10 +1163,16 @@ func getMockUser() *model.User {
 	return &model.User{
 		UserID:         1,
 		Username:       "1234567890",
-		TransactionPin: "1234",
+		TransactionPin: hashPin("1234"),
 	}
 }
 
+// hashPin hashes the PIN the way credentials are stored, at the lowest cost to keep tests fast
+func hashPin(pin string) string {
+	hashed, _ := bcrypt.GenerateFromPassword([]byte(pin), bcrypt.MinCost)
+	return string(hashed)
+}
+
 func getTransactionRequest(account, username, pin, reference string,
 	transactionType model.TransactionType, amount model.BigDecimal) []byte {
 	requestBody, _ := json.Marshal(model.TransactionRequestDTO{
@@ -731,5 +1207,8 @@ func createBankService(config *MockConfig, transactionRepo *MockTransactionRepos
 		RestHttpClient:        restClient,
 		UserRepository:        userRepo,
 		AccountRepository:     accountRepo,
+		LimitRepository:       new(FakeLimitRepository),
+		FeeRepository:         new(FakeFeeRepository),
+		ReferenceGenerator:    new(SequentialReferenceGenerator),
 	}
 }
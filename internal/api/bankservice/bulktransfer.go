@@ -0,0 +1,525 @@
+package bankservice
+
+import (
+	"bankingApp/internal/api/constants"
+	"bankingApp/internal/currency"
+	"bankingApp/internal/model"
+	"bankingApp/internal/utility"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/govalues/decimal"
+	"gorm.io/gorm"
+)
+
+const (
+	maxBulkTransferRows   = 1000
+	bulkTransferBatchSize = 5
+	bulkSourceJSON        = "json"
+	bulkSourceCSV         = "csv"
+	// bulkTransferStallTimeout is how long a batch can go without a row finishing before it is taken to have been
+	// left processing by a worker that stopped, and is resumed
+	bulkTransferStallTimeout = 15 * time.Minute
+)
+
+// bulkTransferColumns are the columns a bulk transfer CSV file must have, named after the JSON fields of a row
+var bulkTransferColumns = []string{"account_number", "payment_reference", "amount", "type"}
+
+// bulkTransferResultHeader is the header of the downloadable result file of a batch
+var bulkTransferResultHeader = []string{"row_number", "account_number", "payment_reference", "amount", "currency", "type", "status", "message"}
+
+var errInvalidBulkFile = errors.New(constants.InvalidCsvRequestErrorMsg)
+
+type IBulkTransferRepository interface {
+	CreateBulkTransferBatch(batch *model.BulkTransferBatch, rows []*model.BulkTransferRow) error
+	FindBulkTransferBatch(id uint) (*model.BulkTransferBatch, error)
+	FindBulkTransferRows(batchID uint) ([]model.BulkTransferRow, error)
+	ClaimPendingBulkTransferBatches(limit int) ([]model.BulkTransferBatch, error)
+	ClaimStalledBulkTransferBatches(stalledBefore time.Time, limit int) ([]model.BulkTransferBatch, error)
+	RecordBulkTransferRow(row *model.BulkTransferRow) error
+	CompleteBulkTransferBatch(batchID uint) error
+}
+
+// BulkTransferService accepts files of transfers and executes them in the background,
+// one row at a time through the same validation and posting path as BankTransferService.Transfer
+type BulkTransferService struct {
+	Repository      IBulkTransferRepository
+	TransferService *BankTransferService
+}
+
+// NewBulkTransferService creates a new instance of BulkTransferService
+func NewBulkTransferService(
+	repository IBulkTransferRepository,
+	transferService *BankTransferService) *BulkTransferService {
+	return &BulkTransferService{Repository: repository, TransferService: transferService}
+}
+
+// bulkTransferInput is a row of a submitted file. parseError is set when the row could not be read into a request.
+type bulkTransferInput struct {
+	request    model.BulkTransferRowRequestDTO
+	parseError string
+}
+
+// Submit handles the endpoint accepting a batch of transfers as a JSON array or as a CSV file, either as the request
+// body or as the "file" field of a multipart form. The rows are sent on behalf of the authenticated user, so each
+// must be on an account they own, and their transaction PIN is required once for the whole batch in the
+// Transaction-Pin header. Every row is validated straight away; rows that fail are rejected with the reason
+// and the others are executed by the background worker.
+func (s *BulkTransferService) Submit(c *gin.Context) {
+	user, ok := tokenUser(c, s.TransferService.UserRepository)
+	if !ok {
+		return
+	}
+
+	pin := c.GetHeader(constants.TransactionPinHeader)
+	if pin == "" {
+		utility.HandleError(c, nil, http.StatusBadRequest, constants.TransactionPinRequired)
+		return
+	}
+	if tErr := verifyTransactionPin(s.TransferService.UserRepository, user, pin); tErr != nil {
+		utility.HandleError(c, tErr.err, tErr.statusCode, tErr.message)
+		return
+	}
+
+	inputs, source, statusCode, message := readBulkTransferInputs(c)
+	if message != "" {
+		utility.HandleError(c, nil, statusCode, message)
+		return
+	}
+
+	if len(inputs) == constants.Zero {
+		utility.HandleError(c, nil, http.StatusBadRequest, constants.EmptyBulkTransfer)
+		return
+	}
+
+	if len(inputs) > maxBulkTransferRows {
+		utility.HandleError(c, nil, http.StatusBadRequest, constants.BulkTransferTooLarge)
+		return
+	}
+
+	now := time.Now()
+	batch := &model.BulkTransferBatch{
+		UserID:        user.UserID,
+		Source:        source,
+		Status:        model.BatchPending,
+		TotalRows:     len(inputs),
+		TimestampData: model.TimestampData{CreatedAt: now},
+	}
+
+	rows := make([]*model.BulkTransferRow, 0, len(inputs))
+	seen := make(map[string]bool, len(inputs))
+	for i, input := range inputs {
+		row := newBulkTransferRow(i+1, user.Username, input.request)
+		message, err := s.validateBulkTransferInput(input, user.UserID, seen)
+		if err != nil {
+			utility.HandleError(c, err, http.StatusInternalServerError, constants.ApplicationError)
+			return
+		}
+		if message != "" {
+			row.Status, row.Message, row.ProcessedAt = model.RowRejected, message, &now
+			batch.ProcessedRows++
+			batch.FailedRows++
+		}
+		rows = append(rows, row)
+	}
+
+	if batch.ProcessedRows == batch.TotalRows {
+		batch.Status, batch.CompletedAt = model.BatchCompleted, &now
+	}
+
+	if err := s.Repository.CreateBulkTransferBatch(batch, rows); err != nil {
+		utility.HandleError(c, err, http.StatusInternalServerError, constants.ApplicationError)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, utility.FormulateDataResponse(constants.BulkTransferAcceptedMsg, bulkTransferBatchDTO(batch, rows)))
+}
+
+// Progress handles the endpoint returning the progress of a batch together with the result of every row
+func (s *BulkTransferService) Progress(c *gin.Context) {
+	batch, rows, done := s.findBulkTransferBatch(c)
+	if done {
+		return
+	}
+
+	c.JSON(http.StatusOK, utility.FormulateDataResponse(constants.BulkTransferBatchFoundMsg, bulkTransferBatchDTO(batch, rows)))
+}
+
+// Results handles the endpoint downloading the per-row results of a completed batch as a CSV file
+func (s *BulkTransferService) Results(c *gin.Context) {
+	batch, rows, done := s.findBulkTransferBatch(c)
+	if done {
+		return
+	}
+
+	if batch.Status != model.BatchCompleted {
+		utility.HandleError(c, nil, http.StatusOK, constants.BulkTransferBatchNotReady)
+		return
+	}
+
+	var buffer bytes.Buffer
+	writer := csv.NewWriter(&buffer)
+	records := make([][]string, 0, len(rows)+1)
+	records = append(records, bulkTransferResultHeader)
+	for _, row := range rows {
+		records = append(records, []string{
+			strconv.Itoa(row.RowNumber),
+			row.AccountNumber,
+			row.PaymentReference,
+			row.Amount.String(),
+			row.Currency,
+			string(row.Type),
+			string(row.Status),
+			row.Message,
+		})
+	}
+	if err := writer.WriteAll(records); err != nil {
+		utility.HandleError(c, err, http.StatusInternalServerError, constants.ApplicationError)
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="bulk-transfer-%d-results.csv"`, batch.BatchID))
+	c.Data(http.StatusOK, constants.ContentTypeCSV, buffer.Bytes())
+}
+
+// findBulkTransferBatch loads the batch in the id path parameter with its rows, writing the response when there is none.
+// Only the user who submitted the batch and staff whose role lets them view accounts can read it.
+func (s *BulkTransferService) findBulkTransferBatch(c *gin.Context) (*model.BulkTransferBatch, []*model.BulkTransferRow, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		utility.HandleError(c, nil, http.StatusOK, constants.BulkTransferBatchNotFound)
+		return nil, nil, true
+	}
+
+	batch, err := s.Repository.FindBulkTransferBatch(uint(id))
+	if err != nil {
+		utility.HandleError(c, err, http.StatusInternalServerError, constants.ApplicationError)
+		return nil, nil, true
+	}
+
+	if batch.BatchID == constants.Zero {
+		utility.HandleError(c, nil, http.StatusOK, constants.BulkTransferBatchNotFound)
+		return nil, nil, true
+	}
+
+	if !authoriseOwnerOrStaff(c, batch.UserID, constants.BulkTransferAccessDenied) {
+		return nil, nil, true
+	}
+
+	found, err := s.Repository.FindBulkTransferRows(batch.BatchID)
+	if err != nil {
+		utility.HandleError(c, err, http.StatusInternalServerError, constants.ApplicationError)
+		return nil, nil, true
+	}
+
+	rows := make([]*model.BulkTransferRow, 0, len(found))
+	for i := range found {
+		rows = append(rows, &found[i])
+	}
+	return batch, rows, false
+}
+
+// ExecutePending processes the batches waiting for the background worker, recording the result of each row as it finishes.
+// Batches left processing by a worker that stopped, on a restart for instance, are resumed first.
+func (s *BulkTransferService) ExecutePending(ctx context.Context, now time.Time) error {
+	stalled, err := s.Repository.ClaimStalledBulkTransferBatches(now.Add(-bulkTransferStallTimeout), bulkTransferBatchSize)
+	if err != nil {
+		return err
+	}
+
+	for _, batch := range stalled {
+		slog.Warn("resuming stalled bulk transfer batch", "batch_id", batch.BatchID)
+		if err := s.process(batch.BatchID, true); err != nil {
+			slog.Error("unable to process bulk transfer batch", "batch_id", batch.BatchID, "error", err)
+		}
+	}
+
+	batches, err := s.Repository.ClaimPendingBulkTransferBatches(bulkTransferBatchSize)
+	if err != nil {
+		return err
+	}
+
+	for _, batch := range batches {
+		if err := s.process(batch.BatchID, false); err != nil {
+			slog.Error("unable to process bulk transfer batch", "batch_id", batch.BatchID, "error", err)
+		}
+	}
+	return nil
+}
+
+// process executes the pending rows of a claimed batch. A transfer whose outcome is unknown has been sent,
+// so its row is reported as unknown rather than failed. When a stalled batch is resumed, the worker may have
+// stopped between sending the transfer of a row and recording it, so such a row records the outcome of
+// the transaction it already made instead of being sent again.
+func (s *BulkTransferService) process(batchID uint, resumed bool) error {
+	rows, err := s.Repository.FindBulkTransferRows(batchID)
+	if err != nil {
+		return err
+	}
+
+	for i := range rows {
+		row := &rows[i]
+		if row.Status != model.RowPending {
+			continue
+		}
+
+		sent := false
+		if resumed {
+			if sent, err = s.recordSentTransfer(row); err != nil {
+				return err
+			}
+		}
+		if !sent {
+			s.send(row)
+		}
+		processedAt := time.Now()
+		row.ProcessedAt = &processedAt
+
+		if err := s.Repository.RecordBulkTransferRow(row); err != nil {
+			return err
+		}
+	}
+	return s.Repository.CompleteBulkTransferBatch(batchID)
+}
+
+// send executes the transfer of a row and sets the row status from its outcome
+func (s *BulkTransferService) send(row *model.BulkTransferRow) {
+	_, tErr := s.TransferService.transfer(row.TransferRequest(), false, model.BulkChannel)
+	row.Status, row.Message = model.RowSucceeded, constants.SuccessfulTransactionMsg
+	if tErr != nil {
+		row.Status, row.Message = model.RowFailed, tErr.message
+		if tErr.message == constants.TransactionOutcomeUnknown {
+			row.Status = model.RowUnknown
+		}
+	}
+}
+
+// recordSentTransfer looks for the transaction a row already made on its account, and sets the row status from
+// the status of the transaction. It reports false when the transfer of the row has not been sent.
+func (s *BulkTransferService) recordSentTransfer(row *model.BulkTransferRow) (bool, error) {
+	transaction, err := s.TransferService.TransactionRepository.FindTransactionByReference(row.PaymentReference)
+	if err != nil || transaction.TransactionID == constants.Zero {
+		return false, err
+	}
+
+	account, err := s.TransferService.AccountRepository.GetAccountByAccountNumber(row.AccountNumber)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return false, err
+	}
+	if account == nil || account.AccountID != transaction.AccountID {
+		return false, nil
+	}
+
+	switch transaction.Status {
+	case model.SucceededStatus, model.ReversedStatus:
+		row.Status, row.Message = model.RowSucceeded, constants.SuccessfulTransactionMsg
+	case model.FailedStatus:
+		row.Status, row.Message = model.RowFailed, constants.TransactionRejected
+	default:
+		row.Status, row.Message = model.RowUnknown, constants.TransactionOutcomeUnknown
+	}
+	return true, nil
+}
+
+// validateBulkTransferInput checks a row the way the transfer endpoint checks a request from the user who submitted
+// the batch, and returns the reason to reject it with, if any. Payment references must be unique within the batch
+// as well.
+func (s *BulkTransferService) validateBulkTransferInput(
+	input bulkTransferInput,
+	userID uint,
+	seen map[string]bool) (string, error) {
+	if input.parseError != "" {
+		return input.parseError, nil
+	}
+
+	errorMap, err := utility.ValidateRequest(input.request)
+	if err != nil {
+		return "", err
+	}
+	if len(errorMap) != constants.Zero {
+		messages := make([]string, 0, len(errorMap))
+		for _, message := range errorMap {
+			messages = append(messages, message)
+		}
+		sort.Strings(messages)
+		return strings.Join(messages, "; "), nil
+	}
+
+	if seen[input.request.Reference] {
+		return constants.DuplicateReferenceInBatch, nil
+	}
+	seen[input.request.Reference] = true
+
+	account, err := s.TransferService.AccountRepository.GetAccountByAccountNumber(input.request.AccountNumber)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return "", err
+	}
+	if account == nil || account.UserID != userID {
+		return constants.TransferAccessDenied, nil
+	}
+
+	request := model.TransactionRequestDTO{TransactionDataDTO: model.TransactionDataDTO{
+		AccountNumber: input.request.AccountNumber,
+		Reference:     input.request.Reference,
+		Amount:        input.request.Amount,
+		Currency:      input.request.Currency,
+		Type:          input.request.Type,
+	}}
+	_, tErr := s.TransferService.validateTransferAccount(request, false)
+	if tErr == nil {
+		return "", nil
+	}
+	if tErr.statusCode == http.StatusInternalServerError {
+		return "", fmt.Errorf("validating bulk transfer row: %s", tErr.message)
+	}
+	return tErr.message, nil
+}
+
+// readBulkTransferInputs reads the rows of a submitted batch according to the request content type.
+// It returns the status code and message to respond with when the request cannot be read.
+func readBulkTransferInputs(c *gin.Context) ([]bulkTransferInput, string, int, string) {
+	switch c.ContentType() {
+	case constants.ContentTypeValue:
+		var requests []model.BulkTransferRowRequestDTO
+		if err := c.BindJSON(&requests); err != nil {
+			return nil, "", http.StatusBadRequest, constants.InvalidJsonRequestErrorMsg
+		}
+		inputs := make([]bulkTransferInput, 0, len(requests))
+		for _, request := range requests {
+			inputs = append(inputs, bulkTransferInput{request: request})
+		}
+		return inputs, bulkSourceJSON, 0, ""
+	case constants.ContentTypeCSV:
+		inputs, err := parseBulkTransferCSV(c.Request.Body)
+		if err != nil {
+			slog.Info("invalid bulk transfer file", "error", err)
+			return nil, "", http.StatusBadRequest, constants.InvalidCsvRequestErrorMsg
+		}
+		return inputs, bulkSourceCSV, 0, ""
+	case gin.MIMEMultipartPOSTForm:
+		header, err := c.FormFile("file")
+		if err != nil {
+			return nil, "", http.StatusBadRequest, constants.InvalidCsvRequestErrorMsg
+		}
+		file, err := header.Open()
+		if err != nil {
+			return nil, "", http.StatusBadRequest, constants.InvalidCsvRequestErrorMsg
+		}
+		defer file.Close()
+
+		inputs, err := parseBulkTransferCSV(file)
+		if err != nil {
+			slog.Info("invalid bulk transfer file", "error", err)
+			return nil, "", http.StatusBadRequest, constants.InvalidCsvRequestErrorMsg
+		}
+		return inputs, bulkSourceCSV, 0, ""
+	default:
+		return nil, "", http.StatusUnsupportedMediaType, constants.UnsupportedBulkContentType
+	}
+}
+
+// parseBulkTransferCSV reads a CSV file with a header row naming the bulkTransferColumns in any order,
+// optionally followed by a currency column.
+// A row whose amount is not a number is kept with a parse error so it is reported with the other results.
+func parseBulkTransferCSV(file io.Reader) ([]bulkTransferInput, error) {
+	reader := csv.NewReader(file)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errInvalidBulkFile, err)
+	}
+
+	index := make(map[string]int, len(header))
+	for i, column := range header {
+		index[strings.ToLower(strings.TrimSpace(column))] = i
+	}
+	for _, column := range bulkTransferColumns {
+		if _, ok := index[column]; !ok {
+			return nil, fmt.Errorf("%w: missing column %s", errInvalidBulkFile, column)
+		}
+	}
+
+	var inputs []bulkTransferInput
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return inputs, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", errInvalidBulkFile, err)
+		}
+
+		input := bulkTransferInput{request: model.BulkTransferRowRequestDTO{
+			AccountNumber: record[index["account_number"]],
+			Reference:     record[index["payment_reference"]],
+			Type:          model.TransactionType(record[index["type"]]),
+		}}
+		if i, ok := index["currency"]; ok {
+			input.request.Currency = record[i]
+		}
+		amount, err := decimal.Parse(record[index["amount"]])
+		if err != nil {
+			input.parseError = constants.InvalidAmount
+		}
+		input.request.Amount = model.BigDecimal{Decimal: amount}
+		inputs = append(inputs, input)
+	}
+}
+
+// newBulkTransferRow creates the pending row of a batch for a transfer sent on behalf of the user
+func newBulkTransferRow(number int, username string, request model.BulkTransferRowRequestDTO) *model.BulkTransferRow {
+	return &model.BulkTransferRow{
+		RowNumber:        number,
+		AccountNumber:    request.AccountNumber,
+		Username:         username,
+		PaymentReference: request.Reference,
+		Amount:           request.Amount,
+		Currency:         currency.Normalize(request.Currency),
+		Type:             request.Type,
+		Status:           model.RowPending,
+	}
+}
+
+// bulkTransferBatchDTO converts a batch and its rows to their response representation
+func bulkTransferBatchDTO(batch *model.BulkTransferBatch, rows []*model.BulkTransferRow) model.BulkTransferBatchDTO {
+	dtos := make([]model.BulkTransferRowDTO, 0, len(rows))
+	for _, row := range rows {
+		amount := row.Amount
+		dtos = append(dtos, model.BulkTransferRowDTO{
+			RowNumber:        row.RowNumber,
+			AccountNumber:    row.AccountNumber,
+			PaymentReference: row.PaymentReference,
+			Amount:           &amount,
+			Currency:         row.Currency,
+			Type:             row.Type,
+			Status:           row.Status,
+			Message:          row.Message,
+		})
+	}
+
+	return model.BulkTransferBatchDTO{
+		BatchID:       batch.BatchID,
+		Source:        batch.Source,
+		Status:        batch.Status,
+		TotalRows:     batch.TotalRows,
+		ProcessedRows: batch.ProcessedRows,
+		SucceededRows: batch.SucceededRows,
+		FailedRows:    batch.FailedRows,
+		UnknownRows:   batch.UnknownRows,
+		SubmittedAt:   batch.CreatedAt,
+		CompletedAt:   batch.CompletedAt,
+		Rows:          dtos,
+	}
+}
@@ -0,0 +1,568 @@
+package bankservice //nolint:typecheck
+
+import (
+	"bankingApp/internal/api/constants"
+	"bankingApp/internal/model"
+	"bankingApp/internal/utility"
+	"bytes"
+	"context"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/govalues/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type MockBulkTransferRepository struct{ mock.Mock }
+
+func (m *MockBulkTransferRepository) CreateBulkTransferBatch(batch *model.BulkTransferBatch, rows []*model.BulkTransferRow) error {
+	return m.Called(batch, rows).Error(0)
+}
+
+func (m *MockBulkTransferRepository) FindBulkTransferBatch(id uint) (*model.BulkTransferBatch, error) {
+	args := m.Called(id)
+	return args.Get(0).(*model.BulkTransferBatch), args.Error(1)
+}
+
+func (m *MockBulkTransferRepository) FindBulkTransferRows(batchID uint) ([]model.BulkTransferRow, error) {
+	args := m.Called(batchID)
+	return args.Get(0).([]model.BulkTransferRow), args.Error(1)
+}
+
+func (m *MockBulkTransferRepository) ClaimPendingBulkTransferBatches(limit int) ([]model.BulkTransferBatch, error) {
+	args := m.Called(limit)
+	return args.Get(0).([]model.BulkTransferBatch), args.Error(1)
+}
+
+func (m *MockBulkTransferRepository) ClaimStalledBulkTransferBatches(
+	stalledBefore time.Time,
+	limit int) ([]model.BulkTransferBatch, error) {
+	args := m.Called(stalledBefore, limit)
+	return args.Get(0).([]model.BulkTransferBatch), args.Error(1)
+}
+
+func (m *MockBulkTransferRepository) RecordBulkTransferRow(row *model.BulkTransferRow) error {
+	return m.Called(row).Error(0)
+}
+
+func (m *MockBulkTransferRepository) CompleteBulkTransferBatch(batchID uint) error {
+	return m.Called(batchID).Error(0)
+}
+
+const bulkTransferCSV = `account_number,payment_reference,amount,type
+1234567890,payroll1,100,debit
+1234567890,payroll2,abc,debit
+1234567890,payroll1,100,debit
+0987654321,payroll3,100,debit
+1234567890,payroll4,-5,debit
+`
+
+func Test_SubmitBulkTransfer(t *testing.T) {
+	validRow := getBulkTransferRequest("payroll1", "1234567890", "100")
+	testCases := []struct {
+		name             string
+		contentType      string
+		body             []byte
+		anonymous        bool
+		withoutPin       bool
+		pin              string
+		expectedStatus   int
+		expectedSuccess  bool
+		expectedMessage  string
+		expectedSource   string
+		expectedRows     []model.BulkTransferRowStatus
+		expectedMessages []string
+		expectedBatch    model.BulkTransferBatchStatus
+	}{
+		{
+			name:            "JSON batch with a row on an account of another user",
+			contentType:     constants.ContentTypeValue,
+			body:            getBulkTransferJSON(validRow, getBulkTransferRequest("payroll2", "0987654321", "100")),
+			expectedStatus:  http.StatusAccepted,
+			expectedSuccess: true,
+			expectedMessage: constants.BulkTransferAcceptedMsg,
+			expectedSource:  bulkSourceJSON,
+			expectedRows:    []model.BulkTransferRowStatus{model.RowPending, model.RowRejected},
+			expectedMessages: []string{
+				"", constants.TransferAccessDenied,
+			},
+			expectedBatch: model.BatchPending,
+		},
+		{
+			name:            "CSV batch rejects invalid rows with the reason",
+			contentType:     constants.ContentTypeCSV,
+			body:            []byte(bulkTransferCSV),
+			expectedStatus:  http.StatusAccepted,
+			expectedSuccess: true,
+			expectedMessage: constants.BulkTransferAcceptedMsg,
+			expectedSource:  bulkSourceCSV,
+			expectedRows: []model.BulkTransferRowStatus{
+				model.RowPending, model.RowRejected, model.RowRejected, model.RowRejected, model.RowRejected,
+			},
+			expectedMessages: []string{
+				"",
+				constants.InvalidAmount,
+				constants.DuplicateReferenceInBatch,
+				constants.TransferAccessDenied,
+				"Amount must be a positive number",
+			},
+			expectedBatch: model.BatchPending,
+		},
+		{
+			name:             "batch of rejected rows is completed at once",
+			contentType:      constants.ContentTypeValue,
+			body:             getBulkTransferJSON(getBulkTransferRequest("payroll2", "0987654321", "100")),
+			expectedStatus:   http.StatusAccepted,
+			expectedSuccess:  true,
+			expectedMessage:  constants.BulkTransferAcceptedMsg,
+			expectedSource:   bulkSourceJSON,
+			expectedRows:     []model.BulkTransferRowStatus{model.RowRejected},
+			expectedMessages: []string{constants.TransferAccessDenied},
+			expectedBatch:    model.BatchCompleted,
+		},
+		{
+			name:            "CSV file without an amount column",
+			contentType:     constants.ContentTypeCSV,
+			body:            []byte("account_number,payment_reference,type\n"),
+			expectedStatus:  http.StatusBadRequest,
+			expectedMessage: constants.InvalidCsvRequestErrorMsg,
+		},
+		{
+			name:            "empty batch",
+			contentType:     constants.ContentTypeValue,
+			body:            []byte(`[]`),
+			expectedStatus:  http.StatusBadRequest,
+			expectedMessage: constants.EmptyBulkTransfer,
+		},
+		{
+			name:            "unsupported content type",
+			contentType:     "text/plain",
+			body:            []byte("payroll"),
+			expectedStatus:  http.StatusUnsupportedMediaType,
+			expectedMessage: constants.UnsupportedBulkContentType,
+		},
+		{
+			name:            "not authenticated",
+			contentType:     constants.ContentTypeValue,
+			body:            getBulkTransferJSON(validRow),
+			anonymous:       true,
+			expectedStatus:  http.StatusUnauthorized,
+			expectedMessage: constants.AccessTokenRequired,
+		},
+		{
+			name:            "batch without the transaction PIN",
+			contentType:     constants.ContentTypeValue,
+			body:            getBulkTransferJSON(validRow),
+			withoutPin:      true,
+			expectedStatus:  http.StatusBadRequest,
+			expectedMessage: constants.TransactionPinRequired,
+		},
+		{
+			name:            "batch with an incorrect transaction PIN",
+			contentType:     constants.ContentTypeValue,
+			body:            getBulkTransferJSON(validRow),
+			pin:             "9999",
+			expectedStatus:  http.StatusOK,
+			expectedMessage: constants.IncorrectTransactionPin,
+		},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			// ------------ setups ------------
+			service, mockRepository, mocks := createBulkTransferService()
+			gin.SetMode(gin.TestMode)
+
+			// ------------ expectations ------------
+			mockRepository.On("CreateBulkTransferBatch", mock.Anything, mock.Anything).Return(nil)
+			mocks.transactionRepository.
+				On("FindTransactionByReference", mock.Anything).Return(getMockNotFoundTransaction(), nil)
+			mocks.userRepository.
+				On("GetUserAndAccountByAccountNumber", mock.Anything).Return(getMockUser(), getMockAccount(), nil)
+			mocks.userRepository.On("FindUserByUsername", "1234567890").Return(*getMockUser(), nil)
+			mocks.userRepository.On("RecordFailedPinAttempt", uint(1), mock.Anything).Return(nil)
+			mocks.accountRepository.On("GetAccountByAccountNumber", "1234567890").Return(getMockAccount(), nil)
+			mocks.accountRepository.On("GetAccountByAccountNumber", "0987654321").Return(getMockOtherAccount(), nil)
+
+			// ------------ executions -----------
+			context, recorder := newScheduledTransferContext(t, "POST", "/api/v1/bank/bulk-transfers", tt.body)
+			context.Request.Header.Set(constants.ContentTypeHeader, tt.contentType)
+			pin := "1234"
+			if tt.pin != "" {
+				pin = tt.pin
+			}
+			if !tt.withoutPin {
+				context.Request.Header.Set(constants.TransactionPinHeader, pin)
+			}
+			if !tt.anonymous {
+				authenticate(t, context, "1234567890", 1, model.CustomerRole)
+			}
+			service.Submit(context)
+
+			var returnedResponse struct {
+				utility.APIDataResponse
+				Data model.BulkTransferBatchDTO `json:"data"`
+			}
+			if jsonErr := json.Unmarshal(recorder.Body.Bytes(), &returnedResponse); jsonErr != nil {
+				t.Fatalf("Error creating test context: %v", jsonErr)
+			}
+
+			// ------------ assertions -----------
+			assert.Equal(t, tt.expectedStatus, recorder.Code)
+			assert.Equal(t, tt.expectedSuccess, returnedResponse.Success)
+			assert.Equal(t, tt.expectedMessage, returnedResponse.Message)
+			if !tt.expectedSuccess {
+				mockRepository.AssertNotCalled(t, "CreateBulkTransferBatch", mock.Anything, mock.Anything)
+				return
+			}
+
+			saved := mockRepository.Calls[0].Arguments.Get(0).(*model.BulkTransferBatch)
+			assert.Equal(t, uint(1), saved.UserID)
+			for _, row := range mockRepository.Calls[0].Arguments.Get(1).([]*model.BulkTransferRow) {
+				assert.Equal(t, "1234567890", row.Username)
+			}
+
+			batch := returnedResponse.Data
+			assert.Equal(t, tt.expectedSource, batch.Source)
+			assert.Equal(t, tt.expectedBatch, batch.Status)
+			assert.Equal(t, len(tt.expectedRows), batch.TotalRows)
+			require.Len(t, batch.Rows, len(tt.expectedRows))
+			rejected := 0
+			for i, row := range batch.Rows {
+				assert.Equal(t, i+1, row.RowNumber)
+				assert.Equal(t, tt.expectedRows[i], row.Status)
+				assert.Equal(t, tt.expectedMessages[i], row.Message)
+				if row.Status == model.RowRejected {
+					rejected++
+				}
+			}
+			assert.Equal(t, rejected, batch.ProcessedRows)
+			assert.Equal(t, rejected, batch.FailedRows)
+		})
+	}
+}
+
+func Test_SubmitBulkTransferAsMultipartUpload(t *testing.T) {
+	// ------------ setups ------------
+	service, mockRepository, mocks := createBulkTransferService()
+	gin.SetMode(gin.TestMode)
+
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	part, err := form.CreateFormFile("file", "payroll.csv")
+	require.NoError(t, err)
+	_, err = part.Write([]byte(bulkTransferCSV))
+	require.NoError(t, err)
+	require.NoError(t, form.Close())
+
+	// ------------ expectations ------------
+	mockRepository.On("CreateBulkTransferBatch", mock.Anything, mock.Anything).Return(nil)
+	mocks.transactionRepository.
+		On("FindTransactionByReference", mock.Anything).Return(getMockNotFoundTransaction(), nil)
+	mocks.userRepository.
+		On("GetUserAndAccountByAccountNumber", mock.Anything).Return(getMockUser(), getMockAccount(), nil)
+	mocks.userRepository.On("FindUserByUsername", "1234567890").Return(*getMockUser(), nil)
+	mocks.accountRepository.On("GetAccountByAccountNumber", "1234567890").Return(getMockAccount(), nil)
+	mocks.accountRepository.On("GetAccountByAccountNumber", "0987654321").Return(getMockOtherAccount(), nil)
+
+	// ------------ executions -----------
+	context, recorder := newScheduledTransferContext(t, "POST", "/api/v1/bank/bulk-transfers", body.Bytes())
+	context.Request.Header.Set(constants.ContentTypeHeader, form.FormDataContentType())
+	context.Request.Header.Set(constants.TransactionPinHeader, "1234")
+	authenticate(t, context, "1234567890", 1, model.CustomerRole)
+	service.Submit(context)
+
+	// ------------ assertions -----------
+	assert.Equal(t, http.StatusAccepted, recorder.Code)
+	rows := mockRepository.Calls[0].Arguments.Get(1).([]*model.BulkTransferRow)
+	require.Len(t, rows, 5)
+	assert.Equal(t, "payroll1", rows[0].PaymentReference)
+	assert.Equal(t, 0, rows[0].Amount.Decimal.Cmp(decimal.MustParse("100")))
+}
+
+func Test_ExecutePendingBulkTransfers(t *testing.T) {
+	// ------------ setups ------------
+	service, mockRepository, mocks := createBulkTransferService()
+	account := getMockAccount()
+	service.TransferService.UnitOfWork = &FakeUnitOfWork{Accounts: []*model.Account{account}}
+	rows := []model.BulkTransferRow{
+		*getMockBulkTransferRow(1, "payroll1", "100", model.RowPending),
+		*getMockBulkTransferRow(2, "payroll2", "5", model.RowRejected),
+		*getMockBulkTransferRow(3, "payroll3", "1000000", model.RowPending),
+	}
+
+	// ------------ expectations ------------
+	mockRepository.On("ClaimStalledBulkTransferBatches", mock.Anything, bulkTransferBatchSize).
+		Return([]model.BulkTransferBatch{}, nil)
+	mockRepository.On("ClaimPendingBulkTransferBatches", bulkTransferBatchSize).
+		Return([]model.BulkTransferBatch{{BatchID: 7, Status: model.BatchProcessing}}, nil)
+	mockRepository.On("FindBulkTransferRows", uint(7)).Return(rows, nil)
+	mockRepository.On("RecordBulkTransferRow", mock.Anything).Return(nil)
+	mockRepository.On("CompleteBulkTransferBatch", uint(7)).Return(nil)
+	mocks.config.On("ThirdPartyBaseUrl").Return("http://provider")
+	mocks.transactionRepository.
+		On("FindTransactionByReference", mock.Anything).Return(getMockNotFoundTransaction(), nil)
+	mocks.userRepository.
+		On("GetUserAndAccountByAccountNumber", "1234567890").Return(getMockUser(), account, nil)
+	mocks.restClient.
+		On("PostRequest", mock.Anything, mock.Anything, mock.Anything).
+		Return(getSuccessThirdPartyResponse(), http.StatusOK, nil)
+
+	// ------------ executions -----------
+	err := service.ExecutePending(context.Background(), time.Now())
+
+	// ------------ assertions -----------
+	require.NoError(t, err)
+	mockRepository.AssertNumberOfCalls(t, "RecordBulkTransferRow", 2)
+	mockRepository.AssertCalled(t, "CompleteBulkTransferBatch", uint(7))
+
+	succeeded := mockRepository.Calls[3].Arguments.Get(0).(*model.BulkTransferRow)
+	assert.Equal(t, "payroll1", succeeded.PaymentReference)
+	assert.Equal(t, model.RowSucceeded, succeeded.Status)
+	assert.NotNil(t, succeeded.ProcessedAt)
+
+	failed := mockRepository.Calls[4].Arguments.Get(0).(*model.BulkTransferRow)
+	assert.Equal(t, "payroll3", failed.PaymentReference)
+	assert.Equal(t, model.RowFailed, failed.Status)
+	assert.Equal(t, constants.InsufficientFunds, failed.Message)
+
+	assert.Equal(t, 0, account.GetBalance().Decimal.Cmp(decimal.MustParse("99900")))
+}
+
+func Test_ExecutePendingResumesStalledBulkTransfers(t *testing.T) {
+	// ------------ setups ------------
+	service, mockRepository, mocks := createBulkTransferService()
+	account := getMockAccount()
+	service.TransferService.UnitOfWork = &FakeUnitOfWork{Accounts: []*model.Account{account}}
+	now := time.Now()
+	rows := []model.BulkTransferRow{
+		*getMockBulkTransferRow(1, "payroll1", "100", model.RowSucceeded),
+		*getMockBulkTransferRow(2, "payroll2", "50", model.RowPending),
+		*getMockBulkTransferRow(3, "payroll3", "5", model.RowPending),
+	}
+
+	// ------------ expectations ------------
+	mockRepository.On("ClaimStalledBulkTransferBatches", now.Add(-bulkTransferStallTimeout), bulkTransferBatchSize).
+		Return([]model.BulkTransferBatch{{BatchID: 7, Status: model.BatchProcessing}}, nil)
+	mockRepository.On("ClaimPendingBulkTransferBatches", bulkTransferBatchSize).Return([]model.BulkTransferBatch{}, nil)
+	mockRepository.On("FindBulkTransferRows", uint(7)).Return(rows, nil)
+	mockRepository.On("RecordBulkTransferRow", mock.Anything).Return(nil)
+	mockRepository.On("CompleteBulkTransferBatch", uint(7)).Return(nil)
+	mocks.config.On("ThirdPartyBaseUrl").Return("http://provider")
+	mocks.transactionRepository.On("FindTransactionByReference", "payroll2").Return(&model.Transaction{
+		TransactionID: 3, AccountID: 1, PaymentReference: "payroll2", Status: model.SucceededStatus,
+	}, nil)
+	mocks.transactionRepository.
+		On("FindTransactionByReference", "payroll3").Return(getMockNotFoundTransaction(), nil)
+	mocks.accountRepository.On("GetAccountByAccountNumber", "1234567890").Return(account, nil)
+	mocks.userRepository.
+		On("GetUserAndAccountByAccountNumber", "1234567890").Return(getMockUser(), account, nil)
+	mocks.restClient.
+		On("PostRequest", mock.Anything, mock.Anything, mock.Anything).
+		Return(getSuccessThirdPartyResponse(), http.StatusOK, nil)
+
+	// ------------ executions -----------
+	err := service.ExecutePending(context.Background(), now)
+
+	// ------------ assertions -----------
+	require.NoError(t, err)
+	mockRepository.AssertNumberOfCalls(t, "RecordBulkTransferRow", 2)
+	mockRepository.AssertCalled(t, "CompleteBulkTransferBatch", uint(7))
+	mocks.restClient.AssertNumberOfCalls(t, "PostRequest", 1)
+
+	alreadySent := mockRepository.Calls[2].Arguments.Get(0).(*model.BulkTransferRow)
+	assert.Equal(t, "payroll2", alreadySent.PaymentReference)
+	assert.Equal(t, model.RowSucceeded, alreadySent.Status)
+	assert.NotNil(t, alreadySent.ProcessedAt)
+
+	sent := mockRepository.Calls[3].Arguments.Get(0).(*model.BulkTransferRow)
+	assert.Equal(t, "payroll3", sent.PaymentReference)
+	assert.Equal(t, model.RowSucceeded, sent.Status)
+
+	assert.Equal(t, 0, account.GetBalance().Decimal.Cmp(decimal.MustParse("99995")))
+}
+
+func Test_BulkTransferResults(t *testing.T) {
+	testCases := []struct {
+		name            string
+		id              string
+		batch           *model.BulkTransferBatch
+		userID          uint
+		expectedStatus  int
+		expectedType    string
+		expectedBody    string
+		expectedMessage string
+	}{
+		{
+			name:           "results of a completed batch",
+			id:             "7",
+			batch:          &model.BulkTransferBatch{BatchID: 7, UserID: 1, Status: model.BatchCompleted},
+			userID:         1,
+			expectedStatus: http.StatusOK,
+			expectedType:   constants.ContentTypeCSV,
+			expectedBody: "row_number,account_number,payment_reference,amount,currency,type,status,message\n" +
+				"1,1234567890,payroll1,100,,debit,succeeded,transaction is successful\n" +
+				"2,1234567890,payroll2,5,,debit,failed,\"insufficient funds, try later\"\n",
+		},
+		{
+			name:            "batch still being processed",
+			id:              "7",
+			batch:           &model.BulkTransferBatch{BatchID: 7, UserID: 1, Status: model.BatchProcessing},
+			userID:          1,
+			expectedStatus:  http.StatusOK,
+			expectedMessage: constants.BulkTransferBatchNotReady,
+		},
+		{
+			name:            "batch of another user",
+			id:              "7",
+			batch:           &model.BulkTransferBatch{BatchID: 7, UserID: 1, Status: model.BatchCompleted},
+			userID:          2,
+			expectedStatus:  http.StatusForbidden,
+			expectedMessage: constants.BulkTransferAccessDenied,
+		},
+		{
+			name:            "batch not found",
+			id:              "7",
+			batch:           &model.BulkTransferBatch{},
+			userID:          1,
+			expectedStatus:  http.StatusOK,
+			expectedMessage: constants.BulkTransferBatchNotFound,
+		},
+		{
+			name:            "invalid batch ID",
+			id:              "abc",
+			userID:          1,
+			expectedStatus:  http.StatusOK,
+			expectedMessage: constants.BulkTransferBatchNotFound,
+		},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			// ------------ setups ------------
+			service, mockRepository, _ := createBulkTransferService()
+			gin.SetMode(gin.TestMode)
+			succeeded := getMockBulkTransferRow(1, "payroll1", "100", model.RowSucceeded)
+			succeeded.Message = constants.SuccessfulTransactionMsg
+			failed := getMockBulkTransferRow(2, "payroll2", "5", model.RowFailed)
+			failed.Message = "insufficient funds, try later"
+
+			// ------------ expectations ------------
+			mockRepository.On("FindBulkTransferBatch", uint(7)).Return(tt.batch, nil)
+			mockRepository.On("FindBulkTransferRows", uint(7)).Return([]model.BulkTransferRow{*succeeded, *failed}, nil)
+
+			// ------------ executions -----------
+			context, recorder := newScheduledTransferContext(t, "GET", "/api/v1/bank/bulk-transfers/"+tt.id+"/results", nil)
+			context.Params = append(context.Params, gin.Param{Key: "id", Value: tt.id})
+			authenticate(t, context, "1234567890", tt.userID, model.CustomerRole)
+			service.Results(context)
+
+			// ------------ assertions -----------
+			assert.Equal(t, tt.expectedStatus, recorder.Code)
+			if tt.expectedBody != "" {
+				assert.Equal(t, tt.expectedType, recorder.Header().Get(constants.ContentTypeHeader))
+				assert.Contains(t, recorder.Header().Get("Content-Disposition"), "bulk-transfer-7-results.csv")
+				assert.Equal(t, tt.expectedBody, recorder.Body.String())
+				return
+			}
+
+			var returnedResponse utility.APIDataResponse
+			if jsonErr := json.Unmarshal(recorder.Body.Bytes(), &returnedResponse); jsonErr != nil {
+				t.Fatalf("Error creating test context: %v", jsonErr)
+			}
+			assert.False(t, returnedResponse.Success)
+			assert.Equal(t, tt.expectedMessage, returnedResponse.Message)
+		})
+	}
+}
+
+func Test_BulkTransferProgress(t *testing.T) {
+	// ------------ setups ------------
+	service, mockRepository, _ := createBulkTransferService()
+	gin.SetMode(gin.TestMode)
+	batch := &model.BulkTransferBatch{
+		BatchID: 7, UserID: 1, Status: model.BatchProcessing, TotalRows: 2, ProcessedRows: 1, SucceededRows: 1,
+	}
+
+	// ------------ expectations ------------
+	mockRepository.On("FindBulkTransferBatch", uint(7)).Return(batch, nil)
+	mockRepository.On("FindBulkTransferRows", uint(7)).Return([]model.BulkTransferRow{
+		*getMockBulkTransferRow(1, "payroll1", "100", model.RowSucceeded),
+		*getMockBulkTransferRow(2, "payroll2", "5", model.RowPending),
+	}, nil)
+
+	// ------------ executions -----------
+	recorder := httptest.NewRecorder()
+	context, _ := gin.CreateTestContext(recorder)
+	context.Request, _ = http.NewRequest("GET", "/api/v1/bank/bulk-transfers/7", nil)
+	context.Params = append(context.Params, gin.Param{Key: "id", Value: "7"})
+	authenticate(t, context, "teller", 9, model.TellerRole)
+	service.Progress(context)
+
+	var returnedResponse struct {
+		utility.APIDataResponse
+		Data model.BulkTransferBatchDTO `json:"data"`
+	}
+	if jsonErr := json.Unmarshal(recorder.Body.Bytes(), &returnedResponse); jsonErr != nil {
+		t.Fatalf("Error creating test context: %v", jsonErr)
+	}
+
+	// ------------ assertions -----------
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, constants.BulkTransferBatchFoundMsg, returnedResponse.Message)
+	assert.Equal(t, model.BatchProcessing, returnedResponse.Data.Status)
+	assert.Equal(t, 1, returnedResponse.Data.ProcessedRows)
+	require.Len(t, returnedResponse.Data.Rows, 2)
+	assert.Equal(t, model.RowPending, returnedResponse.Data.Rows[1].Status)
+}
+
+func createBulkTransferService() (*BulkTransferService, *MockBulkTransferRepository, scheduledTransferMocks) {
+	mockConfig, mockTransactionRepo, mockUserRepo, mockAccountRepo, mockRestClient, _ := setupMocks()
+	mockRepository := new(MockBulkTransferRepository)
+	transferService := createBankService(mockConfig, mockTransactionRepo, mockUserRepo, mockAccountRepo, mockRestClient)
+	return NewBulkTransferService(mockRepository, transferService), mockRepository, scheduledTransferMocks{
+		config:                mockConfig,
+		transactionRepository: mockTransactionRepo,
+		userRepository:        mockUserRepo,
+		accountRepository:     mockAccountRepo,
+		restClient:            mockRestClient,
+	}
+}
+
+func getMockBulkTransferRow(number int, reference, amount string, status model.BulkTransferRowStatus) *model.BulkTransferRow {
+	return &model.BulkTransferRow{
+		RowID:            uint(number),
+		BatchID:          7,
+		RowNumber:        number,
+		AccountNumber:    "1234567890",
+		Username:         "johndoe",
+		PaymentReference: reference,
+		Amount:           model.BigDecimal{Decimal: decimal.MustParse(amount)},
+		Type:             model.DebitTransaction,
+		Status:           status,
+	}
+}
+
+func getBulkTransferRequest(reference, accountNumber, amount string) model.BulkTransferRowRequestDTO {
+	return model.BulkTransferRowRequestDTO{
+		AccountNumber: accountNumber,
+		Reference:     reference,
+		Amount:        model.BigDecimal{Decimal: decimal.MustParse(amount)},
+		Type:          model.DebitTransaction,
+	}
+}
+
+// getMockOtherAccount is an account of another user than the one of getMockAccount
+func getMockOtherAccount() *model.Account {
+	return &model.Account{AccountID: 2, AccountNumber: "0987654321", UserID: 2}
+}
+
+func getBulkTransferJSON(requests ...model.BulkTransferRowRequestDTO) []byte {
+	body, _ := json.Marshal(requests)
+	return body
+}
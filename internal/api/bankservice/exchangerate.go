@@ -0,0 +1,192 @@
+package bankservice
+
+import (
+	"bankingApp/internal/api/constants"
+	"bankingApp/internal/currency"
+	"bankingApp/internal/model"
+	"bankingApp/internal/utility"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/govalues/decimal"
+)
+
+type IExchangeRateRepository interface {
+	FindExchangeRate(base, quote string) (*model.ExchangeRate, error)
+	FindExchangeRates() ([]model.ExchangeRate, error)
+	SaveExchangeRates(rates []model.ExchangeRate) error
+}
+
+// Sources of exchange rates
+const (
+	RateSourceFile  = "file"
+	RateSourceAdmin = "admin"
+)
+
+// ExchangeRateService maintains the table of exchange rates transfers are converted with
+type ExchangeRateService struct {
+	Repository IExchangeRateRepository
+}
+
+// NewExchangeRateService creates a new instance of ExchangeRateService
+func NewExchangeRateService(repository IExchangeRateRepository) *ExchangeRateService {
+	return &ExchangeRateService{Repository: repository}
+}
+
+// Import validates the rates and stores them, replacing the rates of the pairs that already have one
+func (e *ExchangeRateService) Import(rates []currency.Rate, source string) error {
+	records := make([]model.ExchangeRate, 0, len(rates))
+	for _, rate := range rates {
+		if err := rate.Validate(); err != nil {
+			return err
+		}
+		records = append(records, model.ExchangeRate{
+			BaseCurrency:  rate.Base,
+			QuoteCurrency: rate.Quote,
+			Rate:          model.BigDecimal{Decimal: rate.Rate},
+			Source:        source,
+		})
+	}
+	return e.Repository.SaveExchangeRates(records)
+}
+
+// Update handles the admin endpoint for loading exchange rates. Either every rate in the request is stored or none is.
+func (e *ExchangeRateService) Update(c *gin.Context) {
+	var r model.UpdateExchangeRatesRequestDTO
+	if err := c.BindJSON(&r); err != nil {
+		utility.HandleError(c, err, http.StatusBadRequest, constants.InvalidJsonRequestErrorMsg)
+		return
+	}
+
+	if errorMap, vErr := utility.ValidateRequest(r); len(errorMap) != constants.Zero || vErr != nil {
+		if vErr != nil {
+			utility.HandleError(c, vErr, http.StatusInternalServerError, constants.ApplicationError)
+			return
+		}
+		utility.HandleValidationErrors(c, errorMap)
+		return
+	}
+
+	rates := make([]currency.Rate, 0, len(r.Rates))
+	for _, rate := range r.Rates {
+		if rate.Rate == nil {
+			utility.HandleError(c, nil, http.StatusOK, constants.InvalidExchangeRate)
+			return
+		}
+		rates = append(rates, currency.Rate{Base: rate.BaseCurrency, Quote: rate.QuoteCurrency, Rate: rate.Rate.Decimal})
+	}
+
+	err := e.Import(rates, RateSourceAdmin)
+	if errors.Is(err, currency.ErrUnsupportedCurrency) || errors.Is(err, currency.ErrInvalidRate) {
+		utility.HandleError(c, nil, http.StatusOK, constants.InvalidExchangeRate)
+		return
+	}
+	if err != nil {
+		utility.HandleError(c, err, http.StatusInternalServerError, constants.ApplicationError)
+		return
+	}
+
+	e.respondRates(c, constants.ExchangeRatesUpdatedMsg)
+}
+
+// List handles the endpoint listing the current exchange rates
+func (e *ExchangeRateService) List(c *gin.Context) {
+	e.respondRates(c, constants.ExchangeRatesFoundMsg)
+}
+
+// respondRates writes every exchange rate with the given message
+func (e *ExchangeRateService) respondRates(c *gin.Context, message string) {
+	rates, err := e.Repository.FindExchangeRates()
+	if err != nil {
+		utility.HandleError(c, err, http.StatusInternalServerError, constants.ApplicationError)
+		return
+	}
+
+	dtos := make([]model.ExchangeRateDTO, 0, len(rates))
+	for _, rate := range rates {
+		value, updatedAt := rate.Rate, rate.UpdatedAt
+		dtos = append(dtos, model.ExchangeRateDTO{
+			BaseCurrency:  rate.BaseCurrency,
+			QuoteCurrency: rate.QuoteCurrency,
+			Rate:          &value,
+			Source:        rate.Source,
+			UpdatedAt:     &updatedAt,
+		})
+	}
+	c.JSON(http.StatusOK, utility.FormulateDataResponse(message, dtos))
+}
+
+// conversion is an amount converted to another currency together with the rate it was converted at
+type conversion struct {
+	amount model.BigDecimal
+	rate   model.BigDecimal
+}
+
+// convert converts an amount from one currency to another at the rate of the pair, or at the inverse of the rate
+// quoted the other way round when the pair only has that one. An amount in the same currency is kept at a rate of one.
+func (b *BankTransferService) convert(amount model.BigDecimal, from, to string) (conversion, *transferError) {
+	if from == to {
+		return conversion{amount: amount, rate: model.BigDecimal{Decimal: decimal.One}}, nil
+	}
+
+	rate, tErr := b.exchangeRate(from, to)
+	if tErr != nil {
+		return conversion{}, tErr
+	}
+
+	converted, err := rate.Convert(amount.Decimal)
+	if err != nil {
+		return conversion{}, newTransferError(err, http.StatusInternalServerError, constants.ApplicationError)
+	}
+	return conversion{amount: model.BigDecimal{Decimal: converted}, rate: model.BigDecimal{Decimal: rate.Rate}}, nil
+}
+
+// exchangeRate finds the rate converting the from currency to the to currency
+func (b *BankTransferService) exchangeRate(from, to string) (currency.Rate, *transferError) {
+	rate, err := b.ExchangeRateRepository.FindExchangeRate(from, to)
+	if err != nil {
+		return currency.Rate{}, newTransferError(err, http.StatusInternalServerError, constants.ApplicationError)
+	}
+	if rate.ExchangeRateID != constants.Zero {
+		return currency.Rate{Base: from, Quote: to, Rate: rate.Rate.Decimal}, nil
+	}
+
+	inverse, err := b.ExchangeRateRepository.FindExchangeRate(to, from)
+	if err != nil {
+		return currency.Rate{}, newTransferError(err, http.StatusInternalServerError, constants.ApplicationError)
+	}
+	if inverse.ExchangeRateID == constants.Zero {
+		return currency.Rate{}, newTransferError(nil, http.StatusOK, constants.ExchangeRateNotFound)
+	}
+
+	quoted, err := currency.Rate{Base: to, Quote: from, Rate: inverse.Rate.Decimal}.Inverse()
+	if err != nil {
+		return currency.Rate{}, newTransferError(err, http.StatusInternalServerError, constants.ApplicationError)
+	}
+	return quoted, nil
+}
+
+// transferCurrency returns the currency a transfer is requested in, which is the account currency unless one is given
+func transferCurrency(requested string, account *model.Account) string {
+	if code := currency.Normalize(requested); code != "" {
+		return code
+	}
+	return account.Currency
+}
+
+// checkTransferCurrency checks that the currency of the transfer is supported and that the amount fits its minor unit
+func checkTransferCurrency(code string, amount model.BigDecimal) *transferError {
+	if code == "" {
+		return nil
+	}
+
+	c, err := currency.Lookup(code)
+	if err != nil {
+		return newTransferError(nil, http.StatusOK, constants.UnsupportedCurrency)
+	}
+	if err := c.CheckAmount(amount.Decimal); err != nil {
+		return newTransferError(nil, http.StatusOK, constants.AmountPrecisionExceeded)
+	}
+	return nil
+}
@@ -0,0 +1,321 @@
+package bankservice
+
+import (
+	"bankingApp/internal/api/constants"
+	"bankingApp/internal/ledger"
+	"bankingApp/internal/model"
+	"bankingApp/internal/utility"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/govalues/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockExchangeRateRepository struct{ mock.Mock }
+
+func (m *MockExchangeRateRepository) FindExchangeRate(base, quote string) (*model.ExchangeRate, error) {
+	args := m.Called(base, quote)
+	return args.Get(0).(*model.ExchangeRate), args.Error(1)
+}
+
+func (m *MockExchangeRateRepository) FindExchangeRates() ([]model.ExchangeRate, error) {
+	args := m.Called()
+	return args.Get(0).([]model.ExchangeRate), args.Error(1)
+}
+
+func (m *MockExchangeRateRepository) SaveExchangeRates(rates []model.ExchangeRate) error {
+	return m.Called(rates).Error(0)
+}
+
+func Test_ConvertedTransfer(t *testing.T) {
+	testCases := []struct {
+		name            string
+		currency        string
+		amount          string
+		rates           map[string]*model.ExchangeRate
+		expectedMessage string
+		expectedAmount  string
+		expectedRate    string
+	}{
+		{
+			name:            "debit in a foreign currency",
+			currency:        "usd",
+			amount:          "100",
+			rates:           map[string]*model.ExchangeRate{"USD/NGN": getMockExchangeRate("USD", "NGN", "1550.25")},
+			expectedMessage: constants.SuccessfulTransactionMsg,
+			expectedAmount:  "155025",
+			expectedRate:    "1550.25",
+		},
+		{
+			name:            "inverse of the rate quoted the other way round",
+			currency:        "USD",
+			amount:          "100",
+			rates:           map[string]*model.ExchangeRate{"NGN/USD": getMockExchangeRate("NGN", "USD", "0.0008")},
+			expectedMessage: constants.SuccessfulTransactionMsg,
+			expectedAmount:  "125000",
+			expectedRate:    "1250",
+		},
+		{
+			name:            "no rate for the pair",
+			currency:        "EUR",
+			amount:          "100",
+			expectedMessage: constants.ExchangeRateNotFound,
+		},
+		{
+			name:            "unsupported currency",
+			currency:        "XYZ",
+			amount:          "100",
+			expectedMessage: constants.UnsupportedCurrency,
+		},
+		{
+			name:            "amount finer than the minor unit",
+			currency:        "JPY",
+			amount:          "100.5",
+			expectedMessage: constants.AmountPrecisionExceeded,
+		},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			// ------------ setups ------------
+			mockConfig, mockTransactionRepo, mockUserRepo, mockAccountRepo, mockRestClient, _ := setupMocks()
+			bankService := createBankService(mockConfig, mockTransactionRepo, mockUserRepo, mockAccountRepo, mockRestClient)
+			exchangeRateRepo := new(MockExchangeRateRepository)
+			bankService.ExchangeRateRepository = exchangeRateRepo
+			account := getMockAccount()
+			account.Currency = "NGN"
+			account.Balance = model.BigDecimal{Decimal: decimal.MustParse("200000.00")}
+			unitOfWork := &FakeUnitOfWork{Accounts: []*model.Account{account}}
+			bankService.UnitOfWork = unitOfWork
+
+			gin.SetMode(gin.TestMode)
+
+			// ------------ expectations ------------
+			mockConfig.On("ThirdPartyBaseUrl").Return("http://provider")
+			mockTransactionRepo.On("FindTransactionByReference", mock.Anything).Return(getMockNotFoundTransaction(), nil)
+			mockUserRepo.On("GetUserAndAccountByAccountNumber", mock.Anything).Return(getMockUser(), account, nil)
+			for _, pair := range [][2]string{{"USD", "NGN"}, {"NGN", "USD"}, {"EUR", "NGN"}, {"NGN", "EUR"}} {
+				rate, ok := tt.rates[pair[0]+"/"+pair[1]]
+				if !ok {
+					rate = &model.ExchangeRate{}
+				}
+				exchangeRateRepo.On("FindExchangeRate", pair[0], pair[1]).Return(rate, nil)
+			}
+			mockRestClient.
+				On("PostRequest", mock.Anything, mock.Anything, mock.Anything).
+				Return(getSuccessThirdPartyResponse(), http.StatusOK, nil)
+
+			// ------------ executions -----------
+			request := model.TransactionRequestDTO{TransactionDataDTO: model.TransactionDataDTO{
+				AccountNumber:  "1234567890",
+				Username:       "johndoe",
+				TransactionPin: "1234",
+				Reference:      "289192938929293",
+				Amount:         model.BigDecimal{Decimal: decimal.MustParse(tt.amount)},
+				Currency:       tt.currency,
+				Type:           model.DebitTransaction,
+			}}
+			body, _ := json.Marshal(request)
+			context, recorder := newScheduledTransferContext(t, http.MethodPost, "/api/v1/bank/fund-transfer", body)
+			bankService.Transfer(context)
+
+			var returnedResponse utility.APIResponse
+			if jsonErr := json.Unmarshal(recorder.Body.Bytes(), &returnedResponse); jsonErr != nil {
+				t.Fatalf("Error creating test context: %v", jsonErr)
+			}
+
+			// ------------ assertions -----------
+			assert.Equal(t, http.StatusOK, recorder.Code)
+			assert.Equal(t, tt.expectedMessage, returnedResponse.Message)
+			if tt.expectedAmount == "" {
+				assert.Empty(t, unitOfWork.Transactions)
+				mockRestClient.AssertNotCalled(t, "PostRequest", mock.Anything, mock.Anything, mock.Anything)
+				return
+			}
+
+			transaction := unitOfWork.Transactions[0]
+			assert.Equal(t, 0, transaction.Amount.Decimal.Cmp(decimal.MustParse(tt.expectedAmount)))
+			assert.Equal(t, "NGN", transaction.Currency)
+			assert.Equal(t, 0, transaction.TransferAmount.Decimal.Cmp(decimal.MustParse(tt.amount)))
+			assert.Equal(t, "USD", transaction.TransferCurrency)
+			assert.Equal(t, 0, transaction.ExchangeRate.Decimal.Cmp(decimal.MustParse(tt.expectedRate)))
+
+			balance, _ := decimal.MustParse("200000").Sub(decimal.MustParse(tt.expectedAmount))
+			assert.Equal(t, 0, account.GetBalance().Decimal.Cmp(balance))
+			assert.NoError(t, ledger.Validate(unitOfWork.Entries[0]))
+			assert.Len(t, unitOfWork.Entries[0].Postings, 4)
+
+			sent := mockRestClient.Calls[0].Arguments.Get(1).(*model.ThirdPartyTransactionDataDTO)
+			assert.Equal(t, "USD", sent.Currency)
+			assert.Equal(t, 0, sent.Amount.Decimal.Cmp(decimal.MustParse(tt.amount)))
+		})
+	}
+}
+
+func Test_InternalTransferBetweenCurrencies(t *testing.T) {
+	// ------------ setups ------------
+	mockConfig, mockTransactionRepo, mockUserRepo, mockAccountRepo, mockRestClient, _ := setupMocks()
+	bankService := createBankService(mockConfig, mockTransactionRepo, mockUserRepo, mockAccountRepo, mockRestClient)
+	exchangeRateRepo := new(MockExchangeRateRepository)
+	bankService.ExchangeRateRepository = exchangeRateRepo
+	source, destination := getMockAccount(), getMockDestinationAccount()
+	source.Currency, destination.Currency = "NGN", "USD"
+	unitOfWork := &FakeUnitOfWork{Accounts: []*model.Account{source, destination}}
+	bankService.UnitOfWork = unitOfWork
+
+	gin.SetMode(gin.TestMode)
+
+	// ------------ expectations ------------
+	mockTransactionRepo.On("FindTransactionByReference", mock.Anything).Return(getMockNotFoundTransaction(), nil)
+	mockUserRepo.On("GetUserAndAccountByAccountNumber", mock.Anything).Return(getMockUser(), source, nil)
+	mockAccountRepo.On("GetAccountByAccountNumber", mock.Anything).Return(destination, nil)
+	exchangeRateRepo.On("FindExchangeRate", "NGN", "USD").Return(&model.ExchangeRate{}, nil)
+	exchangeRateRepo.On("FindExchangeRate", "USD", "NGN").Return(getMockExchangeRate("USD", "NGN", "1600"), nil)
+
+	// ------------ executions -----------
+	body := getInternalTransferRequest("1234567890", "0987654321", "1234", model.BigDecimal{Decimal: decimal.MustParse("8000")})
+	context, recorder := newScheduledTransferContext(t, http.MethodPost, "/api/v1/bank/internal-transfer", body)
+	bankService.InternalTransfer(context)
+
+	// ------------ assertions -----------
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, 0, source.GetBalance().Decimal.Cmp(decimal.MustParse("92000")))
+	assert.Equal(t, 0, destination.GetBalance().Decimal.Cmp(decimal.MustParse("505")))
+
+	debit, credit := unitOfWork.Transactions[0], unitOfWork.Transactions[1]
+	assert.Equal(t, "NGN", debit.Currency)
+	assert.Equal(t, "USD", credit.Currency)
+	assert.Equal(t, 0, credit.Amount.Decimal.Cmp(decimal.MustParse("5")))
+	assert.Equal(t, "NGN", credit.TransferCurrency)
+	assert.Equal(t, 0, credit.ExchangeRate.Decimal.Cmp(decimal.MustParse("0.000625")))
+
+	// ------------ reversing part of the credit leg reverses the same share of the debit leg ------------
+	mockTransactionRepo.ExpectedCalls = nil
+	mockTransactionRepo.On("FindTransactionByReference", "289192938929293").Return(debit, nil)
+	mockTransactionRepo.On("FindTransactionByReference", "289192938929293"+creditLegSuffix).Return(credit, nil)
+	mockTransactionRepo.On("FindTransaction", debit.TransactionID).Return(debit, nil)
+
+	context, recorder = newReversalContext(t, getReversalRequest(decimalPointer("4000")))
+	bankService.Reverse(context)
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, 0, source.GetBalance().Decimal.Cmp(decimal.MustParse("96000")))
+	assert.Equal(t, 0, destination.GetBalance().Decimal.Cmp(decimal.MustParse("502.5")))
+	assert.Equal(t, 0, credit.ReversedAmount.Decimal.Cmp(decimal.MustParse("2.5")))
+}
+
+func Test_UpdateExchangeRates(t *testing.T) {
+	testCases := []struct {
+		name            string
+		requestBody     string
+		expectSave      bool
+		expectedStatus  int
+		expectedMessage string
+	}{
+		{
+			name:            "rates are stored",
+			requestBody:     `{"rates": [{"base_currency": "usd", "quote_currency": "NGN", "rate": "1550.25"}]}`,
+			expectSave:      true,
+			expectedStatus:  http.StatusOK,
+			expectedMessage: constants.ExchangeRatesUpdatedMsg,
+		},
+		{
+			name:            "rate between the same currency",
+			requestBody:     `{"rates": [{"base_currency": "USD", "quote_currency": "USD", "rate": "1"}]}`,
+			expectedStatus:  http.StatusOK,
+			expectedMessage: constants.InvalidExchangeRate,
+		},
+		{
+			name:            "unsupported currency",
+			requestBody:     `{"rates": [{"base_currency": "USD", "quote_currency": "XYZ", "rate": "2"}]}`,
+			expectedStatus:  http.StatusOK,
+			expectedMessage: constants.InvalidExchangeRate,
+		},
+		{
+			name:            "no rates",
+			requestBody:     `{"rates": []}`,
+			expectedStatus:  http.StatusBadRequest,
+			expectedMessage: constants.BadRequestMessage,
+		},
+		{
+			name:            "invalid json",
+			requestBody:     `{"rates": `,
+			expectedStatus:  http.StatusBadRequest,
+			expectedMessage: constants.InvalidJsonRequestErrorMsg,
+		},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			// ------------ setups ------------
+			repository := new(MockExchangeRateRepository)
+			service := NewExchangeRateService(repository)
+			gin.SetMode(gin.TestMode)
+
+			// ------------ expectations ------------
+			repository.On("SaveExchangeRates", mock.Anything).Return(nil)
+			repository.On("FindExchangeRates").Return([]model.ExchangeRate{*getMockExchangeRate("USD", "NGN", "1550.25")}, nil)
+
+			// ------------ executions -----------
+			context, recorder := newScheduledTransferContext(t, http.MethodPut, "/api/v1/bank/admin/exchange-rates", []byte(tt.requestBody))
+			service.Update(context)
+
+			var returnedResponse utility.APIDataResponse
+			if jsonErr := json.Unmarshal(recorder.Body.Bytes(), &returnedResponse); jsonErr != nil {
+				t.Fatalf("Error creating test context: %v", jsonErr)
+			}
+
+			// ------------ assertions -----------
+			assert.Equal(t, tt.expectedStatus, recorder.Code)
+			assert.Equal(t, tt.expectedMessage, returnedResponse.Message)
+			if !tt.expectSave {
+				repository.AssertNotCalled(t, "SaveExchangeRates", mock.Anything)
+				return
+			}
+
+			saved := repository.Calls[0].Arguments.Get(0).([]model.ExchangeRate)
+			assert.Equal(t, "USD", saved[0].BaseCurrency)
+			assert.Equal(t, "NGN", saved[0].QuoteCurrency)
+			assert.Equal(t, RateSourceAdmin, saved[0].Source)
+			assert.Len(t, returnedResponse.Data, 1)
+		})
+	}
+}
+
+func Test_ListExchangeRates(t *testing.T) {
+	repository := new(MockExchangeRateRepository)
+	service := NewExchangeRateService(repository)
+	gin.SetMode(gin.TestMode)
+
+	repository.On("FindExchangeRates").Return([]model.ExchangeRate{}, errors.New("connection refused")).Once()
+	context, recorder := newScheduledTransferContext(t, http.MethodGet, "/api/v1/bank/exchange-rates", nil)
+	service.List(context)
+	assert.Equal(t, http.StatusInternalServerError, recorder.Code)
+
+	repository.On("FindExchangeRates").Return([]model.ExchangeRate{*getMockExchangeRate("EUR", "USD", "1.085")}, nil)
+	context, recorder = newScheduledTransferContext(t, http.MethodGet, "/api/v1/bank/exchange-rates", nil)
+	service.List(context)
+
+	var returnedResponse struct {
+		Data []model.ExchangeRateDTO `json:"data"`
+	}
+	if jsonErr := json.Unmarshal(recorder.Body.Bytes(), &returnedResponse); jsonErr != nil {
+		t.Fatalf("Error creating test context: %v", jsonErr)
+	}
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, "EUR", returnedResponse.Data[0].BaseCurrency)
+	assert.Equal(t, 0, returnedResponse.Data[0].Rate.Decimal.Cmp(decimal.MustParse("1.085")))
+}
+
+func getMockExchangeRate(base, quote, rate string) *model.ExchangeRate {
+	return &model.ExchangeRate{
+		ExchangeRateID: 1,
+		BaseCurrency:   base,
+		QuoteCurrency:  quote,
+		Rate:           model.BigDecimal{Decimal: decimal.MustParse(rate)},
+		Source:         RateSourceFile,
+	}
+}
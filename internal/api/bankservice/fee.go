@@ -0,0 +1,189 @@
+package bankservice
+
+import (
+	"bankingApp/internal/api/constants"
+	"bankingApp/internal/currency"
+	"bankingApp/internal/fee"
+	"bankingApp/internal/model"
+	"bankingApp/internal/utility"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type IFeeRepository interface {
+	FindFeeRules() ([]model.FeeRule, error)
+	ReplaceFeeRules(rules []model.FeeRule) error
+}
+
+// FeeService maintains the fee schedule transfers are charged by
+type FeeService struct {
+	Repository IFeeRepository
+}
+
+// NewFeeService creates a new instance of FeeService
+func NewFeeService(repository IFeeRepository) *FeeService {
+	return &FeeService{Repository: repository}
+}
+
+// Update handles the admin endpoint replacing the fee schedule. Either every rule in the request is stored or none is,
+// and an empty list of rules stops transfers from being charged.
+func (f *FeeService) Update(c *gin.Context) {
+	var r model.UpdateFeeRulesRequestDTO
+	if err := c.BindJSON(&r); err != nil {
+		utility.HandleError(c, err, http.StatusBadRequest, constants.InvalidJsonRequestErrorMsg)
+		return
+	}
+
+	if errorMap, vErr := utility.ValidateRequest(r); len(errorMap) != constants.Zero || vErr != nil {
+		if vErr != nil {
+			utility.HandleError(c, vErr, http.StatusInternalServerError, constants.ApplicationError)
+			return
+		}
+		utility.HandleValidationErrors(c, errorMap)
+		return
+	}
+
+	rules := make([]model.FeeRule, 0, len(r.Rules))
+	for _, rule := range r.Rules {
+		rules = append(rules, feeRule(rule))
+	}
+
+	err := fee.Validate(rules)
+	if errors.Is(err, fee.ErrInvalidRule) {
+		utility.HandleError(c, nil, http.StatusOK, constants.InvalidFeeRule)
+		return
+	}
+	if err != nil {
+		utility.HandleError(c, err, http.StatusInternalServerError, constants.ApplicationError)
+		return
+	}
+
+	if err := f.Repository.ReplaceFeeRules(rules); err != nil {
+		utility.HandleError(c, err, http.StatusInternalServerError, constants.ApplicationError)
+		return
+	}
+
+	f.respondRules(c, constants.FeeRulesUpdatedMsg)
+}
+
+// List handles the endpoint listing the fee schedule
+func (f *FeeService) List(c *gin.Context) {
+	f.respondRules(c, constants.FeeRulesFoundMsg)
+}
+
+// respondRules writes every rule of the fee schedule with the given message
+func (f *FeeService) respondRules(c *gin.Context, message string) {
+	rules, err := f.Repository.FindFeeRules()
+	if err != nil {
+		utility.HandleError(c, err, http.StatusInternalServerError, constants.ApplicationError)
+		return
+	}
+
+	dtos := make([]model.FeeRuleDTO, 0, len(rules))
+	for _, rule := range rules {
+		dtos = append(dtos, model.FeeRuleDTO{
+			FeeRuleID:       rule.FeeRuleID,
+			TransactionType: rule.TransactionType,
+			Channel:         rule.Channel,
+			Currency:        rule.Currency,
+			MinAmount:       setAmount(rule.MinAmount),
+			MaxAmount:       setAmount(rule.MaxAmount),
+			FlatFee:         setAmount(rule.FlatFee),
+			Percentage:      setAmount(rule.Percentage),
+			MinFee:          setAmount(rule.MinFee),
+			MaxFee:          setAmount(rule.MaxFee),
+		})
+	}
+	c.JSON(http.StatusOK, utility.FormulateDataResponse(message, dtos))
+}
+
+// feeRule converts a rule of the request to the rule stored in the fee schedule, leaving omitted amounts at zero
+func feeRule(r model.FeeRuleDTO) model.FeeRule {
+	rule := model.FeeRule{
+		TransactionType: r.TransactionType,
+		Channel:         r.Channel,
+		Currency:        r.Currency,
+		TimestampData:   model.TimestampData{CreatedAt: time.Now(), UpdatedAt: time.Now()},
+	}
+	for value, amount := range map[*model.BigDecimal]*model.BigDecimal{
+		&rule.MinAmount:  r.MinAmount,
+		&rule.MaxAmount:  r.MaxAmount,
+		&rule.FlatFee:    r.FlatFee,
+		&rule.Percentage: r.Percentage,
+		&rule.MinFee:     r.MinFee,
+		&rule.MaxFee:     r.MaxFee,
+	} {
+		if amount != nil {
+			*value = *amount
+		}
+	}
+	return rule
+}
+
+// transferFee works out the fee charged on a transfer of the amount, in the currency of the account,
+// through the given channel. It returns nil when no rule of the fee schedule applies to the transfer.
+func (b *BankTransferService) transferFee(
+	t model.TransactionRequestDTO,
+	account *model.Account,
+	amount model.BigDecimal,
+	channel model.Channel) (*fee.Breakdown, *transferError) {
+	rules, err := b.FeeRepository.FindFeeRules()
+	if err != nil {
+		return nil, newTransferError(err, http.StatusInternalServerError, constants.ApplicationError)
+	}
+
+	rule, ok := fee.Select(rules, t.Type, channel, account.Currency, amount.Decimal)
+	if !ok {
+		return nil, nil
+	}
+
+	breakdown, err := fee.Compute(rule, amount.Decimal, currency.MinorUnits(account.Currency))
+	if err != nil {
+		return nil, newTransferError(err, http.StatusInternalServerError, constants.ApplicationError)
+	}
+	return &breakdown, nil
+}
+
+// feeOf returns the total of the fee breakdown, which is zero when the transfer is not charged
+func feeOf(breakdown *fee.Breakdown) model.BigDecimal {
+	if breakdown == nil {
+		return model.BigDecimal{}
+	}
+	return model.BigDecimal{Decimal: breakdown.Total}
+}
+
+// feeBreakdownDTO converts the fee charged on the transaction to its response representation,
+// returning nil when the transaction was not charged
+func feeBreakdownDTO(breakdown *fee.Breakdown, transaction *model.Transaction) *model.FeeBreakdownDTO {
+	if breakdown == nil {
+		return nil
+	}
+
+	accountAmount, err := transaction.Amount.Decimal.Add(breakdown.Total)
+	if transaction.Type == model.CreditTransaction {
+		accountAmount, err = transaction.Amount.Decimal.Sub(breakdown.Total)
+	}
+	if err != nil {
+		accountAmount = transaction.Amount.Decimal
+	}
+
+	dto := &model.FeeBreakdownDTO{
+		FeeRuleID:     breakdown.RuleID,
+		Currency:      transaction.Currency,
+		FlatFee:       &model.BigDecimal{Decimal: breakdown.Flat},
+		Percentage:    &model.BigDecimal{Decimal: breakdown.Percentage},
+		PercentageFee: &model.BigDecimal{Decimal: breakdown.PercentageFee},
+		Total:         &model.BigDecimal{Decimal: breakdown.Total},
+		AccountAmount: &model.BigDecimal{Decimal: accountAmount},
+	}
+	switch {
+	case breakdown.Adjustment.IsPos():
+		dto.MinFeeTopUp = &model.BigDecimal{Decimal: breakdown.Adjustment}
+	case breakdown.Adjustment.IsNeg():
+		dto.MaxFeeReduction = &model.BigDecimal{Decimal: breakdown.Adjustment.Neg()}
+	}
+	return dto
+}
@@ -0,0 +1,375 @@
+package bankservice
+
+import (
+	"bankingApp/internal/api/constants"
+	"bankingApp/internal/ledger"
+	"bankingApp/internal/model"
+	"bankingApp/internal/utility"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/govalues/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// FakeFeeRepository keeps the fee schedule in memory
+type FakeFeeRepository struct {
+	Rules    []model.FeeRule
+	Replaced bool
+}
+
+func (f *FakeFeeRepository) FindFeeRules() ([]model.FeeRule, error) {
+	return f.Rules, nil
+}
+
+func (f *FakeFeeRepository) ReplaceFeeRules(rules []model.FeeRule) error {
+	f.Rules, f.Replaced = rules, true
+	return nil
+}
+
+func Test_TransferFees(t *testing.T) {
+	testCases := []struct {
+		name            string
+		transactionType model.TransactionType
+		amount          string
+		rules           []model.FeeRule
+		providerStatus  int
+		expectedStatus  int
+		expectedMessage string
+		expectedFee     string
+		expectedBalance string
+	}{
+		{
+			name:            "debit charged flat and percentage fee",
+			transactionType: model.DebitTransaction,
+			amount:          "1000",
+			rules:           []model.FeeRule{getMockFeeRule(model.DebitTransaction, "", "10.75", "0.5", "", "")},
+			providerStatus:  http.StatusOK,
+			expectedStatus:  http.StatusOK,
+			expectedMessage: constants.SuccessfulTransactionMsg,
+			expectedFee:     "15.75",
+			expectedBalance: "98984.25",
+		},
+		{
+			name:            "debit fee lowered to the maximum fee",
+			transactionType: model.DebitTransaction,
+			amount:          "50000",
+			rules:           []model.FeeRule{getMockFeeRule("", "", "", "1", "", "100")},
+			providerStatus:  http.StatusOK,
+			expectedStatus:  http.StatusOK,
+			expectedMessage: constants.SuccessfulTransactionMsg,
+			expectedFee:     "100",
+			expectedBalance: "49900",
+		},
+		{
+			name:            "balance must cover the fee",
+			transactionType: model.DebitTransaction,
+			amount:          "100000",
+			rules:           []model.FeeRule{getMockFeeRule(model.DebitTransaction, "", "10", "", "", "")},
+			expectedStatus:  http.StatusOK,
+			expectedMessage: constants.InsufficientFunds,
+			expectedBalance: "100000",
+		},
+		{
+			name:            "failed debit refunds the fee",
+			transactionType: model.DebitTransaction,
+			amount:          "1000",
+			rules:           []model.FeeRule{getMockFeeRule(model.DebitTransaction, "", "10", "", "", "")},
+			providerStatus:  http.StatusBadRequest,
+			expectedStatus:  http.StatusOK,
+			expectedMessage: constants.TransactionRejected,
+			expectedBalance: "100000",
+		},
+		{
+			name:            "rule of another channel does not apply",
+			transactionType: model.DebitTransaction,
+			amount:          "1000",
+			rules:           []model.FeeRule{getMockFeeRule(model.DebitTransaction, model.BulkChannel, "10", "", "", "")},
+			providerStatus:  http.StatusOK,
+			expectedStatus:  http.StatusOK,
+			expectedMessage: constants.SuccessfulTransactionMsg,
+			expectedBalance: "99000",
+		},
+		{
+			name:            "credit charged out of the amount",
+			transactionType: model.CreditTransaction,
+			amount:          "1000",
+			rules:           []model.FeeRule{getMockFeeRule(model.CreditTransaction, model.APIChannel, "", "1", "25", "")},
+			providerStatus:  http.StatusOK,
+			expectedStatus:  http.StatusOK,
+			expectedMessage: constants.SuccessfulTransactionMsg,
+			expectedFee:     "25",
+			expectedBalance: "100975",
+		},
+		{
+			name:            "credit smaller than its fee",
+			transactionType: model.CreditTransaction,
+			amount:          "20",
+			rules:           []model.FeeRule{getMockFeeRule(model.CreditTransaction, "", "25", "", "", "")},
+			expectedStatus:  http.StatusOK,
+			expectedMessage: constants.FeeExceedsAmount,
+			expectedBalance: "100000",
+		},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			// ------------ setups ------------
+			mockConfig, mockTransactionRepo, mockUserRepo, mockAccountRepo, mockRestClient, _ := setupMocks()
+			bankService := createBankService(mockConfig, mockTransactionRepo, mockUserRepo, mockAccountRepo, mockRestClient)
+			account := getMockAccount()
+			unitOfWork := &FakeUnitOfWork{Accounts: []*model.Account{account}}
+			bankService.UnitOfWork = unitOfWork
+			bankService.FeeRepository = &FakeFeeRepository{Rules: tt.rules}
+
+			gin.SetMode(gin.TestMode)
+
+			// ------------ expectations ------------
+			mockConfig.On("ThirdPartyBaseUrl").Return("http://provider")
+			mockTransactionRepo.On("FindTransactionByReference", mock.Anything).Return(getMockNotFoundTransaction(), nil)
+			mockUserRepo.On("GetUserAndAccountByAccountNumber", mock.Anything).Return(getMockUser(), account, nil)
+			mockRestClient.
+				On("PostRequest", mock.Anything, mock.Anything, mock.Anything).
+				Return(getSuccessThirdPartyResponse(), tt.providerStatus, nil)
+
+			// ------------ executions -----------
+			amount := model.BigDecimal{Decimal: decimal.MustParse(tt.amount)}
+			body := getTransactionRequest("1234567890", "johndoe", "1234", "289192938929293", tt.transactionType, amount)
+			context, recorder := newScheduledTransferContext(t, http.MethodPost, "/api/v1/bank/fund-transfer", body)
+			bankService.Transfer(context)
+
+			var returnedResponse utility.APIResponse
+			if jsonErr := json.Unmarshal(recorder.Body.Bytes(), &returnedResponse); jsonErr != nil {
+				t.Fatalf("Error creating test context: %v", jsonErr)
+			}
+
+			// ------------ assertions -----------
+			assert.Equal(t, tt.expectedStatus, recorder.Code)
+			assert.Equal(t, tt.expectedMessage, returnedResponse.Message)
+			assert.Equal(t, 0, account.GetBalance().Decimal.Cmp(decimal.MustParse(tt.expectedBalance)))
+			if tt.expectedMessage != constants.SuccessfulTransactionMsg {
+				return
+			}
+
+			require.Len(t, unitOfWork.Transactions, 1)
+			if tt.expectedFee == "" {
+				assert.Nil(t, returnedResponse.Data.Fee)
+				assert.True(t, unitOfWork.Transactions[0].Fee.Decimal.IsZero())
+				return
+			}
+
+			charged := returnedResponse.Data.Fee
+			require.NotNil(t, charged)
+			assert.Equal(t, 0, charged.Total.Decimal.Cmp(decimal.MustParse(tt.expectedFee)))
+			assert.Equal(t, 0, unitOfWork.Transactions[0].Fee.Decimal.Cmp(decimal.MustParse(tt.expectedFee)))
+			assert.Equal(t, tt.expectedFee, feeIncome(unitOfWork.Entries).Decimal.Trim(0).String())
+		})
+	}
+}
+
+func Test_TransferFeeBreakdown(t *testing.T) {
+	// ------------ setups ------------
+	mockConfig, mockTransactionRepo, mockUserRepo, mockAccountRepo, mockRestClient, _ := setupMocks()
+	bankService := createBankService(mockConfig, mockTransactionRepo, mockUserRepo, mockAccountRepo, mockRestClient)
+	account := getMockAccount()
+	account.Currency = "NGN"
+	bankService.UnitOfWork = &FakeUnitOfWork{Accounts: []*model.Account{account}}
+	rule := getMockFeeRule(model.DebitTransaction, model.APIChannel, "10", "0.5", "50", "")
+	rule.FeeRuleID = 7
+	bankService.FeeRepository = &FakeFeeRepository{Rules: []model.FeeRule{rule}}
+
+	gin.SetMode(gin.TestMode)
+
+	// ------------ expectations ------------
+	mockConfig.On("ThirdPartyBaseUrl").Return("http://provider")
+	mockTransactionRepo.On("FindTransactionByReference", mock.Anything).Return(getMockNotFoundTransaction(), nil)
+	mockUserRepo.On("GetUserAndAccountByAccountNumber", mock.Anything).Return(getMockUser(), account, nil)
+	mockRestClient.
+		On("PostRequest", mock.Anything, mock.Anything, mock.Anything).
+		Return(getSuccessThirdPartyResponse(), http.StatusOK, nil)
+
+	// ------------ executions -----------
+	amount := model.BigDecimal{Decimal: decimal.MustParse("1000")}
+	body := getTransactionRequest("1234567890", "johndoe", "1234", "289192938929293", model.DebitTransaction, amount)
+	context, recorder := newScheduledTransferContext(t, http.MethodPost, "/api/v1/bank/fund-transfer", body)
+	bankService.Transfer(context)
+
+	var returnedResponse utility.APIResponse
+	if jsonErr := json.Unmarshal(recorder.Body.Bytes(), &returnedResponse); jsonErr != nil {
+		t.Fatalf("Error creating test context: %v", jsonErr)
+	}
+
+	// ------------ assertions -----------
+	require.NotNil(t, returnedResponse.Data)
+	charged := returnedResponse.Data.Fee
+	require.NotNil(t, charged)
+	assert.Equal(t, uint(7), charged.FeeRuleID)
+	assert.Equal(t, "NGN", charged.Currency)
+	assert.Equal(t, 0, charged.FlatFee.Decimal.Cmp(decimal.MustParse("10")))
+	assert.Equal(t, 0, charged.Percentage.Decimal.Cmp(decimal.MustParse("0.5")))
+	assert.Equal(t, 0, charged.PercentageFee.Decimal.Cmp(decimal.MustParse("5")))
+	assert.Equal(t, 0, charged.MinFeeTopUp.Decimal.Cmp(decimal.MustParse("35")))
+	assert.Nil(t, charged.MaxFeeReduction)
+	assert.Equal(t, 0, charged.Total.Decimal.Cmp(decimal.MustParse("50")))
+	assert.Equal(t, 0, charged.AccountAmount.Decimal.Cmp(decimal.MustParse("1050")))
+}
+
+func Test_UpdateFeeRules(t *testing.T) {
+	testCases := []struct {
+		name            string
+		requestBody     string
+		expectedStatus  int
+		expectedMessage string
+		expectedRules   int
+	}{
+		{
+			name: "valid schedule",
+			requestBody: `{"rules": [
+				{"transaction_type": "debit", "max_amount": "5000", "flat_fee": "10.75"},
+				{"transaction_type": "debit", "min_amount": "5000", "percentage": "0.5", "max_fee": "2000"},
+				{"channel": "bulk", "currency": "ngn", "flat_fee": 5}
+			]}`,
+			expectedStatus:  http.StatusOK,
+			expectedMessage: constants.FeeRulesUpdatedMsg,
+			expectedRules:   3,
+		},
+		{
+			name:            "empty schedule",
+			requestBody:     `{"rules": []}`,
+			expectedStatus:  http.StatusOK,
+			expectedMessage: constants.FeeRulesUpdatedMsg,
+		},
+		{
+			name: "overlapping bands",
+			requestBody: `{"rules": [
+				{"transaction_type": "debit", "max_amount": "5000", "flat_fee": "10"},
+				{"transaction_type": "debit", "min_amount": "4000", "flat_fee": "20"}
+			]}`,
+			expectedStatus:  http.StatusOK,
+			expectedMessage: constants.InvalidFeeRule,
+		},
+		{
+			name:            "unknown channel",
+			requestBody:     `{"rules": [{"channel": "branch", "flat_fee": "10"}]}`,
+			expectedStatus:  http.StatusOK,
+			expectedMessage: constants.InvalidFeeRule,
+		},
+		{
+			name:            "negative fee",
+			requestBody:     `{"rules": [{"flat_fee": "-10"}]}`,
+			expectedStatus:  http.StatusOK,
+			expectedMessage: constants.InvalidFeeRule,
+		},
+		{
+			name:            "missing rules",
+			requestBody:     `{}`,
+			expectedStatus:  http.StatusBadRequest,
+			expectedMessage: constants.BadRequestMessage,
+		},
+		{
+			name:            "invalid json",
+			requestBody:     `{"rules": `,
+			expectedStatus:  http.StatusBadRequest,
+			expectedMessage: constants.InvalidJsonRequestErrorMsg,
+		},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			// ------------ setups ------------
+			repository := new(FakeFeeRepository)
+			service := NewFeeService(repository)
+			gin.SetMode(gin.TestMode)
+
+			// ------------ executions -----------
+			context, recorder := newScheduledTransferContext(t, http.MethodPut, "/api/v1/bank/admin/fee-rules", []byte(tt.requestBody))
+			service.Update(context)
+
+			var returnedResponse struct {
+				utility.APIDataResponse
+				Data []model.FeeRuleDTO `json:"data"`
+			}
+			if jsonErr := json.Unmarshal(recorder.Body.Bytes(), &returnedResponse); jsonErr != nil {
+				t.Fatalf("Error creating test context: %v", jsonErr)
+			}
+
+			// ------------ assertions -----------
+			assert.Equal(t, tt.expectedStatus, recorder.Code)
+			assert.Equal(t, tt.expectedMessage, returnedResponse.Message)
+			if tt.expectedMessage != constants.FeeRulesUpdatedMsg {
+				assert.False(t, repository.Replaced)
+				return
+			}
+
+			assert.True(t, repository.Replaced)
+			assert.Len(t, returnedResponse.Data, tt.expectedRules)
+			if tt.expectedRules > 0 {
+				assert.Equal(t, "NGN", repository.Rules[2].Currency)
+				assert.Equal(t, 0, repository.Rules[1].MaxFee.Decimal.Cmp(decimal.MustParse("2000")))
+			}
+		})
+	}
+}
+
+func Test_ListFeeRules(t *testing.T) {
+	// ------------ setups ------------
+	repository := &FakeFeeRepository{Rules: []model.FeeRule{
+		getMockFeeRule(model.DebitTransaction, model.APIChannel, "10.75", "", "", ""),
+	}}
+	service := NewFeeService(repository)
+	gin.SetMode(gin.TestMode)
+
+	// ------------ executions -----------
+	context, recorder := newScheduledTransferContext(t, http.MethodGet, "/api/v1/bank/fee-rules", nil)
+	service.List(context)
+
+	var returnedResponse struct {
+		utility.APIDataResponse
+		Data []model.FeeRuleDTO `json:"data"`
+	}
+	if jsonErr := json.Unmarshal(recorder.Body.Bytes(), &returnedResponse); jsonErr != nil {
+		t.Fatalf("Error creating test context: %v", jsonErr)
+	}
+
+	// ------------ assertions -----------
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, constants.FeeRulesFoundMsg, returnedResponse.Message)
+	require.Len(t, returnedResponse.Data, 1)
+	assert.Equal(t, model.DebitTransaction, returnedResponse.Data[0].TransactionType)
+	assert.Equal(t, model.APIChannel, returnedResponse.Data[0].Channel)
+	assert.Equal(t, 0, returnedResponse.Data[0].FlatFee.Decimal.Cmp(decimal.MustParse("10.75")))
+	assert.Nil(t, returnedResponse.Data[0].Percentage)
+}
+
+func getMockFeeRule(
+	transactionType model.TransactionType,
+	channel model.Channel,
+	flatFee, percentage, minFee, maxFee string) model.FeeRule {
+	rule := model.FeeRule{TransactionType: transactionType, Channel: channel}
+	for value, amount := range map[*model.BigDecimal]string{
+		&rule.FlatFee:    flatFee,
+		&rule.Percentage: percentage,
+		&rule.MinFee:     minFee,
+		&rule.MaxFee:     maxFee,
+	} {
+		if amount != "" {
+			*value = model.BigDecimal{Decimal: decimal.MustParse(amount)}
+		}
+	}
+	return rule
+}
+
+// feeIncome totals what the journal entries credited to fee income
+func feeIncome(entries []*model.JournalEntry) model.BigDecimal {
+	total := decimal.Zero
+	for _, entry := range entries {
+		for _, posting := range entry.Postings {
+			if posting.LedgerCode == ledger.FeeIncomeGL && posting.Direction == model.CreditEntry {
+				total, _ = total.Add(posting.Amount.Decimal)
+			}
+		}
+	}
+	return model.BigDecimal{Decimal: total}
+}
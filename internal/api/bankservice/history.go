@@ -0,0 +1,159 @@
+package bankservice
+
+import (
+	"bankingApp/internal/api/constants"
+	"bankingApp/internal/model"
+	"bankingApp/internal/utility"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/govalues/decimal"
+)
+
+const (
+	defaultHistoryPageSize = 20
+	maxHistoryPageSize     = 100
+)
+
+type ITransactionHistoryRepository interface {
+	FindAccountTransactions(filter model.TransactionFilter) ([]model.Transaction, error)
+}
+
+// TransactionHistoryService lists the transactions of an account a page at a time
+type TransactionHistoryService struct {
+	Repository        ITransactionHistoryRepository
+	AccountRepository IAccountRepository
+}
+
+// NewTransactionHistoryService creates a new instance of TransactionHistoryService
+func NewTransactionHistoryService(
+	repository ITransactionHistoryRepository,
+	accountRepository IAccountRepository) *TransactionHistoryService {
+	return &TransactionHistoryService{
+		Repository:        repository,
+		AccountRepository: accountRepository,
+	}
+}
+
+// List handles the endpoint listing the transactions of the account in the number path parameter, newest first.
+// The query parameters filter the transactions:
+//   - from and to: the dates of the transaction time as YYYY-MM-DD, both inclusive
+//   - type: debit or credit
+//   - status: one of the lifecycle statuses of a transaction
+//   - min_amount and max_amount: the amount in the account currency, both inclusive
+//   - reference: the start of the payment reference
+//
+// A page holds up to limit transactions, 20 by default and at most 100. The next_cursor of a page is passed
+// as the cursor parameter, together with the same filters, to read the page after it.
+func (h *TransactionHistoryService) List(c *gin.Context) {
+	filter, ok := transactionFilter(c)
+	if !ok {
+		return
+	}
+
+	account, ok := findReadableAccount(c, h.AccountRepository)
+	if !ok {
+		return
+	}
+	filter.AccountID = account.AccountID
+
+	// read one transaction more than the page holds to tell whether there is a next page
+	pageSize := filter.Limit
+	filter.Limit++
+	transactions, err := h.Repository.FindAccountTransactions(filter)
+	if err != nil {
+		utility.HandleError(c, err, http.StatusInternalServerError, constants.ApplicationError)
+		return
+	}
+
+	history := model.TransactionHistoryDTO{
+		AccountNumber: account.AccountNumber,
+		Transactions:  make([]model.TransactionDTO, 0, min(len(transactions), pageSize)),
+	}
+	if len(transactions) > pageSize {
+		transactions = transactions[:pageSize]
+		history.NextCursor = model.CursorOf(&transactions[pageSize-1]).Encode()
+	}
+	for i := range transactions {
+		history.Transactions = append(history.Transactions, transactionDTO(&transactions[i]))
+	}
+	c.JSON(http.StatusOK, utility.FormulateDataResponse(constants.TransactionsFoundMsg, history))
+}
+
+// transactionFilter reads the filters, the page size and the cursor of the history from the query parameters
+func transactionFilter(c *gin.Context) (model.TransactionFilter, bool) {
+	filter := model.TransactionFilter{
+		Type:            model.TransactionType(c.Query("type")),
+		Status:          model.TransactionStatus(c.Query("status")),
+		ReferencePrefix: c.Query("reference"),
+		Limit:           defaultHistoryPageSize,
+	}
+
+	valid := filter.Type == "" || filter.Type == model.DebitTransaction || filter.Type == model.CreditTransaction
+	valid = valid && (filter.Status == "" || filter.Status.IsValid())
+
+	var err error
+	if from := c.Query("from"); from != "" && valid {
+		filter.From, err = time.Parse(time.DateOnly, from)
+		valid = err == nil
+	}
+	if to := c.Query("to"); to != "" && valid {
+		filter.To, err = time.Parse(time.DateOnly, to)
+		filter.To = filter.To.AddDate(0, 0, 1)
+		valid = err == nil && (filter.From.IsZero() || filter.From.Before(filter.To))
+	}
+	if valid {
+		filter.MinAmount, valid = queryAmount(c, "min_amount")
+	}
+	if valid {
+		filter.MaxAmount, valid = queryAmount(c, "max_amount")
+	}
+	if valid && filter.MinAmount != nil && filter.MaxAmount != nil {
+		valid = filter.MinAmount.Decimal.Cmp(filter.MaxAmount.Decimal) <= 0
+	}
+	if limit := c.Query("limit"); limit != "" && valid {
+		filter.Limit, err = strconv.Atoi(limit)
+		valid = err == nil && filter.Limit > 0 && filter.Limit <= maxHistoryPageSize
+	}
+	if cursor := c.Query("cursor"); cursor != "" && valid {
+		after, err := model.ParseTransactionCursor(cursor)
+		filter.After, valid = &after, err == nil
+	}
+
+	if !valid {
+		utility.HandleError(c, nil, http.StatusBadRequest, constants.InvalidTransactionFilter)
+		return model.TransactionFilter{}, false
+	}
+	return filter, true
+}
+
+// queryAmount reads an optional non-negative amount from the query parameter
+func queryAmount(c *gin.Context, key string) (*model.BigDecimal, bool) {
+	value := c.Query(key)
+	if value == "" {
+		return nil, true
+	}
+	amount, err := decimal.Parse(value)
+	if err != nil || amount.IsNeg() {
+		return nil, false
+	}
+	return &model.BigDecimal{Decimal: amount}, true
+}
+
+func transactionDTO(transaction *model.Transaction) model.TransactionDTO {
+	return model.TransactionDTO{
+		Reference:        transaction.Reference,
+		PaymentReference: transaction.PaymentReference,
+		Type:             transaction.Type,
+		Status:           transaction.Status,
+		Amount:           setAmount(transaction.Amount),
+		Currency:         transaction.Currency,
+		TransferAmount:   setAmount(transaction.TransferAmount),
+		TransferCurrency: transaction.TransferCurrency,
+		Fee:              setAmount(transaction.Fee),
+		ReversedAmount:   setAmount(transaction.ReversedAmount),
+		TransactionTime:  transaction.TransactionTime,
+	}
+}
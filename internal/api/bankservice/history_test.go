@@ -0,0 +1,173 @@
+package bankservice
+
+import (
+	"bankingApp/internal/api/constants"
+	"bankingApp/internal/model"
+	"bankingApp/internal/utility"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// FakeTransactionHistoryRepository pages through the transactions it holds, newest first, and records the filter
+type FakeTransactionHistoryRepository struct {
+	Transactions []model.Transaction
+	Filter       model.TransactionFilter
+}
+
+func (f *FakeTransactionHistoryRepository) FindAccountTransactions(
+	filter model.TransactionFilter) ([]model.Transaction, error) {
+	f.Filter = filter
+	var page []model.Transaction
+	for _, transaction := range f.Transactions {
+		if filter.After != nil && transaction.TransactionID >= filter.After.TransactionID {
+			continue
+		}
+		if len(page) < filter.Limit {
+			page = append(page, transaction)
+		}
+	}
+	return page, nil
+}
+
+func Test_ListTransactions(t *testing.T) {
+	testCases := []struct {
+		name               string
+		query              string
+		expectedStatus     int
+		expectedMessage    string
+		userID             uint
+		expectedReferences []string
+		expectedNextPage   bool
+	}{
+		{
+			name:               "first page",
+			query:              "limit=2",
+			expectedStatus:     http.StatusOK,
+			expectedMessage:    constants.TransactionsFoundMsg,
+			expectedReferences: []string{"payment5", "payment4"},
+			expectedNextPage:   true,
+		},
+		{
+			name:               "last page",
+			query:              "limit=2&cursor=" + model.TransactionCursor{TransactionID: 2, TransactionTime: mockTransactionTime(2)}.Encode(),
+			expectedStatus:     http.StatusOK,
+			expectedMessage:    constants.TransactionsFoundMsg,
+			expectedReferences: []string{"payment1"},
+		},
+		{
+			name:               "default page size",
+			query:              "type=debit&status=succeeded&from=2024-03-01&to=2024-03-31&min_amount=10&max_amount=10.5&reference=pay",
+			expectedStatus:     http.StatusOK,
+			expectedMessage:    constants.TransactionsFoundMsg,
+			expectedReferences: []string{"payment5", "payment4", "payment3", "payment2", "payment1"},
+		},
+		{name: "unknown type", query: "type=refund", expectedStatus: http.StatusBadRequest, expectedMessage: constants.InvalidTransactionFilter},
+		{name: "unknown status", query: "status=settled", expectedStatus: http.StatusBadRequest, expectedMessage: constants.InvalidTransactionFilter},
+		{name: "invalid date", query: "from=01-03-2024", expectedStatus: http.StatusBadRequest, expectedMessage: constants.InvalidTransactionFilter},
+		{name: "to before from", query: "from=2024-03-02&to=2024-03-01", expectedStatus: http.StatusBadRequest, expectedMessage: constants.InvalidTransactionFilter},
+		{name: "negative amount", query: "min_amount=-1", expectedStatus: http.StatusBadRequest, expectedMessage: constants.InvalidTransactionFilter},
+		{name: "min above max", query: "min_amount=20&max_amount=10", expectedStatus: http.StatusBadRequest, expectedMessage: constants.InvalidTransactionFilter},
+		{name: "limit too large", query: "limit=101", expectedStatus: http.StatusBadRequest, expectedMessage: constants.InvalidTransactionFilter},
+		{name: "tampered cursor", query: "cursor=not-a-cursor", expectedStatus: http.StatusBadRequest, expectedMessage: constants.InvalidTransactionFilter},
+		{name: "another customer's account", query: "limit=2", userID: 2, expectedStatus: http.StatusForbidden, expectedMessage: constants.AccountAccessDenied},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			// ------------ setups ------------
+			repository := &FakeTransactionHistoryRepository{}
+			for id := uint(5); id >= 1; id-- {
+				repository.Transactions = append(repository.Transactions, model.Transaction{
+					TransactionID:    id,
+					AccountID:        1,
+					Reference:        fmt.Sprintf("TRF%04d", id),
+					PaymentReference: fmt.Sprintf("payment%d", id),
+					Amount:           amountOf("10.00"),
+					Currency:         "NGN",
+					Type:             model.DebitTransaction,
+					Status:           model.SucceededStatus,
+					TransactionTime:  mockTransactionTime(id),
+				})
+			}
+			mockAccountRepo := new(MockAccountRepository)
+			historyService := NewTransactionHistoryService(repository, mockAccountRepo)
+			gin.SetMode(gin.TestMode)
+
+			// ------------ expectations ------------
+			mockAccountRepo.On("GetAccountByAccountNumber", "1234567890").Return(getMockAccount(), nil)
+
+			// ------------ executions -----------
+			context, recorder := newScheduledTransferContext(t, http.MethodGet,
+				"/api/v1/bank/accounts/1234567890/transactions?"+tt.query, nil)
+			context.Params = gin.Params{{Key: "number", Value: "1234567890"}}
+			userID := tt.userID
+			if userID == 0 {
+				userID = getMockAccount().UserID
+			}
+			authenticate(t, context, "1234567890", userID, model.CustomerRole)
+			historyService.List(context)
+
+			var returnedResponse struct {
+				utility.APIDataResponse
+				Data model.TransactionHistoryDTO `json:"data"`
+			}
+			require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &returnedResponse))
+
+			// ------------ assertions -----------
+			assert.Equal(t, tt.expectedStatus, recorder.Code)
+			assert.Equal(t, tt.expectedMessage, returnedResponse.Message)
+			if tt.expectedStatus != http.StatusOK {
+				return
+			}
+
+			var references []string
+			for _, transaction := range returnedResponse.Data.Transactions {
+				references = append(references, transaction.PaymentReference)
+			}
+			assert.Equal(t, tt.expectedReferences, references)
+			assert.Equal(t, tt.expectedNextPage, returnedResponse.Data.NextCursor != "")
+			assert.Equal(t, uint(1), repository.Filter.AccountID)
+		})
+	}
+}
+
+func Test_ListTransactionsPassesTheFiltersToTheRepository(t *testing.T) {
+	// ------------ setups ------------
+	repository := &FakeTransactionHistoryRepository{}
+	mockAccountRepo := new(MockAccountRepository)
+	historyService := NewTransactionHistoryService(repository, mockAccountRepo)
+	gin.SetMode(gin.TestMode)
+
+	// ------------ expectations ------------
+	mockAccountRepo.On("GetAccountByAccountNumber", "1234567890").Return(getMockAccount(), nil)
+
+	// ------------ executions -----------
+	context, _ := newScheduledTransferContext(t, http.MethodGet,
+		"/api/v1/bank/accounts/1234567890/transactions?type=credit&status=reversed&from=2024-03-01&to=2024-03-31"+
+			"&min_amount=10&max_amount=99.99&reference=INV-&limit=50", nil)
+	context.Params = gin.Params{{Key: "number", Value: "1234567890"}}
+	authenticate(t, context, "1234567890", 1, model.CustomerRole)
+	historyService.List(context)
+
+	// ------------ assertions -----------
+	filter := repository.Filter
+	assert.Equal(t, model.CreditTransaction, filter.Type)
+	assert.Equal(t, model.ReversedStatus, filter.Status)
+	assert.Equal(t, time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), filter.From)
+	assert.Equal(t, time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC), filter.To)
+	assert.Equal(t, "10", filter.MinAmount.Decimal.String())
+	assert.Equal(t, "99.99", filter.MaxAmount.Decimal.String())
+	assert.Equal(t, "INV-", filter.ReferencePrefix)
+	assert.Equal(t, 51, filter.Limit)
+	assert.Nil(t, filter.After)
+}
+
+func mockTransactionTime(id uint) time.Time {
+	return time.Date(2024, 3, int(id), 12, 0, 0, 0, time.UTC)
+}
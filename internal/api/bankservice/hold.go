@@ -0,0 +1,384 @@
+package bankservice
+
+import (
+	"bankingApp/internal/api/constants"
+	"bankingApp/internal/api/middleware"
+	"bankingApp/internal/model"
+	"bankingApp/internal/repository"
+	"bankingApp/internal/utility"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/govalues/decimal"
+)
+
+const expiredHoldBatchSize = 50
+
+type IHoldRepository interface {
+	FindHold(id uint) (*model.Hold, error)
+	FindHoldByReference(reference string) (*model.Hold, error)
+	FindExpiredHolds(now time.Time, limit int) ([]model.Hold, error)
+}
+
+// holdErrors are the hold failures reported to the client as business errors
+var holdErrors = append([]error{
+	model.ErrHoldNotActive,
+	model.ErrHoldExpired,
+	model.ErrCaptureAmountExceeded,
+	model.ErrInsufficientFunds,
+	model.ErrDebitsNotAllowed,
+}, limitErrors...)
+
+// HoldService reserves funds on accounts without moving them, and later captures them into a debit
+// through the same lifecycle as BankTransferService.Transfer or gives them back
+type HoldService struct {
+	Repository      IHoldRepository
+	TransferService *BankTransferService
+}
+
+// NewHoldService creates a new instance of HoldService
+func NewHoldService(repository IHoldRepository, transferService *BankTransferService) *HoldService {
+	return &HoldService{Repository: repository, TransferService: transferService}
+}
+
+// Place handles the endpoint placing a hold on an account. The PIN of the account owner is required, the status
+// of the account must allow debits, and the amount must be within the limits of the account and covered by its
+// available balance.
+func (h *HoldService) Place(c *gin.Context) {
+	var r model.HoldRequestDTO
+	if err := c.BindJSON(&r); err != nil {
+		utility.HandleError(c, err, http.StatusBadRequest, constants.InvalidJsonRequestErrorMsg)
+		return
+	}
+
+	if err := h.TransferService.validateTransferRequest(c, r); err != nil {
+		return
+	}
+
+	expiresAt := time.Now().Add(model.DefaultHoldExpiry)
+	if r.ExpiresAt != nil {
+		if !r.ExpiresAt.After(time.Now()) {
+			utility.HandleError(c, nil, http.StatusOK, constants.HoldExpiryNotInFuture)
+			return
+		}
+		expiresAt = *r.ExpiresAt
+	}
+
+	existing, err := h.Repository.FindHoldByReference(r.Reference)
+	if err != nil {
+		utility.HandleError(c, err, http.StatusInternalServerError, constants.ApplicationError)
+		return
+	}
+
+	if existing.HoldID != constants.Zero {
+		utility.HandleError(c, nil, http.StatusOK, constants.NotUniqueReferenceMsg)
+		return
+	}
+
+	account, tErr := h.TransferService.validateTransferAccount(r.TransferRequest(), true)
+	if tErr != nil {
+		utility.HandleError(c, tErr.err, tErr.statusCode, tErr.message)
+		return
+	}
+
+	if tErr := checkAccountStatus(account, model.DebitTransaction); tErr != nil {
+		utility.HandleError(c, tErr.err, tErr.statusCode, tErr.message)
+		return
+	}
+
+	if tErr := h.TransferService.checkLimits(account, r.Amount); tErr != nil {
+		utility.HandleError(c, tErr.err, tErr.statusCode, tErr.message)
+		return
+	}
+
+	hold := &model.Hold{
+		AccountID:        account.AccountID,
+		AccountNumber:    account.AccountNumber,
+		PaymentReference: r.Reference,
+		Amount:           r.Amount,
+		Currency:         account.Currency,
+		Status:           model.HoldActive,
+		ExpiresAt:        expiresAt,
+	}
+	err = h.TransferService.UnitOfWork.Execute(func(tx repository.ITx) error {
+		if err := h.TransferService.checkLockedLimits(tx, account, hold.Amount); err != nil {
+			return err
+		}
+		locked, err := tx.LockAccount(account.AccountID)
+		if err != nil {
+			return err
+		}
+		if err := locked.PlaceHold(hold.Amount); err != nil {
+			return err
+		}
+		if err := tx.UpdateHeldBalance(locked); err != nil {
+			return err
+		}
+		account = locked
+		return tx.SaveHold(hold)
+	})
+	if err != nil {
+		handleHoldError(c, err)
+		return
+	}
+
+	response := holdDTO(hold, nil)
+	available := account.AvailableBalance()
+	response.AvailableBalance = &available
+	c.JSON(http.StatusOK, utility.FormulateDataResponse(constants.HoldPlacedMsg, response))
+}
+
+// Get handles the endpoint retrieving a hold. Only the owner of the account it is placed on and staff whose
+// role lets them view accounts can read it.
+func (h *HoldService) Get(c *gin.Context) {
+	hold, ok := h.findHold(c)
+	if !ok {
+		return
+	}
+
+	if !authoriseAccountNumberAccess(c, h.TransferService.AccountRepository, hold.AccountNumber) {
+		return
+	}
+	c.JSON(http.StatusOK, utility.FormulateDataResponse(constants.HoldFoundMsg, holdDTO(hold, nil)))
+}
+
+// Capture handles the endpoint capturing all or part of an active hold. The hold is released and the captured
+// amount debited in one commit, so the debit can use the funds the hold reserved, and the debit is then sent to
+// the third-party provider like a transfer. A debit the provider rejects is refunded; the hold stays captured.
+// A hold on an account whose status no longer allows debits cannot be captured until the account is reactivated.
+// Only staff whose role lets them settle holds reach it.
+func (h *HoldService) Capture(c *gin.Context) {
+	var r model.CaptureHoldRequestDTO
+	if err := c.BindJSON(&r); err != nil {
+		utility.HandleError(c, err, http.StatusBadRequest, constants.InvalidJsonRequestErrorMsg)
+		return
+	}
+
+	if err := h.TransferService.validateTransferRequest(c, r); err != nil {
+		return
+	}
+
+	hold, ok := h.findHold(c)
+	if !ok {
+		return
+	}
+
+	amount := hold.Amount
+	if r.Amount != nil {
+		amount = *r.Amount
+	}
+
+	if tErr := checkTransferCurrency(hold.Currency, amount); tErr != nil {
+		utility.HandleError(c, tErr.err, tErr.statusCode, tErr.message)
+		return
+	}
+
+	reference, done := h.TransferService.newReference(c)
+	if done {
+		return
+	}
+
+	var transaction *model.Transaction
+	err := h.TransferService.UnitOfWork.Execute(func(tx repository.ITx) error {
+		locked, err := tx.LockHold(hold.HoldID)
+		if err != nil {
+			return err
+		}
+		account, err := tx.LockAccount(locked.AccountID)
+		if err != nil {
+			return err
+		}
+		if err := account.CheckDebit(); err != nil {
+			return err
+		}
+		if err := locked.Capture(amount, time.Now()); err != nil {
+			return err
+		}
+		if err := releaseHeldFunds(tx, locked); err != nil {
+			return err
+		}
+
+		transaction = newCaptureTransaction(locked, reference)
+		if err := savePendingTransaction(tx, transaction); err != nil {
+			return err
+		}
+		locked.TransactionID = &transaction.TransactionID
+		hold = locked
+		return tx.UpdateHold(locked)
+	})
+	if err != nil {
+		handleHoldError(c, err)
+		return
+	}
+	middleware.MarkPersisted(c)
+
+	b := h.TransferService
+	url := fmt.Sprintf("%s/api/v1/third-party/payments", b.Config.ThirdPartyBaseUrl())
+	_, status, reason, err := b.submitTransaction(transaction, url)
+	if err != nil {
+		utility.HandleError(c, err, http.StatusInternalServerError, constants.ApplicationError)
+		return
+	}
+
+	if err := b.completeTransaction(transaction, status, reason); err != nil {
+		utility.HandleError(c, err, http.StatusInternalServerError, constants.ApplicationError)
+		return
+	}
+
+	switch status {
+	case model.UnknownStatus:
+		utility.HandleError(c, nil, http.StatusOK, constants.TransactionOutcomeUnknown)
+		return
+	case model.FailedStatus:
+		utility.HandleError(c, nil, http.StatusOK, constants.TransactionRejected)
+		return
+	}
+
+	c.JSON(http.StatusOK, utility.FormulateDataResponse(constants.HoldCapturedMsg, holdDTO(hold, transaction)))
+}
+
+// Release handles the endpoint releasing an active hold, giving the funds it reserves back to the available balance.
+// Only staff whose role lets them settle holds reach it.
+func (h *HoldService) Release(c *gin.Context) {
+	hold, ok := h.findHold(c)
+	if !ok {
+		return
+	}
+
+	released, err := h.closeHold(hold.HoldID, model.HoldReleased)
+	if err != nil {
+		handleHoldError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, utility.FormulateDataResponse(constants.HoldReleasedMsg, holdDTO(released, nil)))
+}
+
+// ExpireDue expires the active holds that reached their expiry at now, giving their funds back
+func (h *HoldService) ExpireDue(ctx context.Context, now time.Time) error {
+	expired, err := h.Repository.FindExpiredHolds(now, expiredHoldBatchSize)
+	if err != nil {
+		return err
+	}
+
+	for _, hold := range expired {
+		_, err := h.closeHold(hold.HoldID, model.HoldExpired)
+		if err != nil && !errors.Is(err, model.ErrHoldNotActive) {
+			slog.Error("unable to expire hold", "hold_id", hold.HoldID, "error", err)
+		}
+	}
+	return nil
+}
+
+// findHold finds the hold in the id path parameter
+func (h *HoldService) findHold(c *gin.Context) (*model.Hold, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		utility.HandleError(c, nil, http.StatusOK, constants.HoldNotFound)
+		return nil, false
+	}
+
+	hold, err := h.Repository.FindHold(uint(id))
+	if err != nil {
+		utility.HandleError(c, err, http.StatusInternalServerError, constants.ApplicationError)
+		return nil, false
+	}
+
+	if hold.HoldID == constants.Zero {
+		utility.HandleError(c, nil, http.StatusOK, constants.HoldNotFound)
+		return nil, false
+	}
+	return hold, true
+}
+
+// closeHold ends an active hold as released or expired and gives its funds back to the account in one commit
+func (h *HoldService) closeHold(holdID uint, status model.HoldStatus) (*model.Hold, error) {
+	var closed *model.Hold
+	err := h.TransferService.UnitOfWork.Execute(func(tx repository.ITx) error {
+		locked, err := tx.LockHold(holdID)
+		if err != nil {
+			return err
+		}
+		if err := locked.Close(status); err != nil {
+			return err
+		}
+		if err := releaseHeldFunds(tx, locked); err != nil {
+			return err
+		}
+		closed = locked
+		return tx.UpdateHold(locked)
+	})
+	return closed, err
+}
+
+// releaseHeldFunds gives the whole amount of the hold back to the available balance of its account
+func releaseHeldFunds(tx repository.ITx, hold *model.Hold) error {
+	account, err := tx.LockAccount(hold.AccountID)
+	if err != nil {
+		return err
+	}
+	if err := account.ReleaseHold(hold.Amount); err != nil {
+		return err
+	}
+	return tx.UpdateHeldBalance(account)
+}
+
+// newCaptureTransaction creates the pending debit the captured amount of the hold is sent to the third-party
+// provider with, under the payment reference of the hold
+func newCaptureTransaction(hold *model.Hold, reference string) *model.Transaction {
+	now := time.Now()
+	return &model.Transaction{
+		AccountID:        hold.AccountID,
+		Reference:        reference,
+		PaymentReference: hold.PaymentReference,
+		Amount:           hold.CapturedAmount,
+		Currency:         hold.Currency,
+		TransferAmount:   hold.CapturedAmount,
+		TransferCurrency: hold.Currency,
+		ExchangeRate:     model.BigDecimal{Decimal: decimal.One},
+		Type:             model.DebitTransaction,
+		Status:           model.PendingStatus,
+		TransactionTime:  now,
+		TimestampData:    model.TimestampData{CreatedAt: now},
+	}
+}
+
+// holdDTO converts a hold to its response representation, with the transaction it was captured into if any
+func holdDTO(hold *model.Hold, transaction *model.Transaction) model.HoldDTO {
+	amount := hold.Amount
+	dto := model.HoldDTO{
+		HoldID:           hold.HoldID,
+		AccountNumber:    hold.AccountNumber,
+		PaymentReference: hold.PaymentReference,
+		Amount:           &amount,
+		Currency:         hold.Currency,
+		Status:           hold.Status,
+		ExpiresAt:        hold.ExpiresAt,
+	}
+	if hold.Status == model.HoldCaptured {
+		captured := hold.CapturedAmount
+		dto.CapturedAmount = &captured
+	}
+	if transaction != nil {
+		dto.TransactionReference = transaction.Reference
+		dto.TransactionStatus = transaction.Status
+	}
+	return dto
+}
+
+// handleHoldError writes the response of a hold that could not be placed, captured or released
+func handleHoldError(c *gin.Context, err error) {
+	for _, holdErr := range holdErrors {
+		if errors.Is(err, holdErr) {
+			utility.HandleError(c, nil, http.StatusOK, holdErr.Error())
+			return
+		}
+	}
+	slog.Error("error in processing hold", "error", err)
+	utility.InternalServerError(c)
+}
@@ -0,0 +1,543 @@
+package bankservice
+
+import (
+	"bankingApp/internal/api/constants"
+	"bankingApp/internal/model"
+	"bankingApp/internal/utility"
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/govalues/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// FakeHoldRepository reads the holds saved through the fake unit of work
+type FakeHoldRepository struct {
+	UnitOfWork *FakeUnitOfWork
+}
+
+func (f *FakeHoldRepository) FindHold(id uint) (*model.Hold, error) {
+	for _, hold := range f.UnitOfWork.Holds {
+		if hold.HoldID == id {
+			found := *hold
+			return &found, nil
+		}
+	}
+	return &model.Hold{}, nil
+}
+
+func (f *FakeHoldRepository) FindHoldByReference(reference string) (*model.Hold, error) {
+	for _, hold := range f.UnitOfWork.Holds {
+		if hold.PaymentReference == reference {
+			found := *hold
+			return &found, nil
+		}
+	}
+	return &model.Hold{}, nil
+}
+
+func (f *FakeHoldRepository) FindExpiredHolds(now time.Time, limit int) ([]model.Hold, error) {
+	var expired []model.Hold
+	for _, hold := range f.UnitOfWork.Holds {
+		if hold.Status == model.HoldActive && hold.IsExpired(now) && len(expired) < limit {
+			expired = append(expired, *hold)
+		}
+	}
+	return expired, nil
+}
+
+func Test_PlaceHold(t *testing.T) {
+	testCases := []struct {
+		name              string
+		pin               string
+		reference         string
+		amount            string
+		expiresAt         *time.Time
+		tierLimit         *model.TransactionLimit
+		expectedMessage   string
+		expectedHeld      string
+		expectedAvailable string
+	}{
+		{
+			name:              "funds are held",
+			pin:               "1234",
+			reference:         "hold-2",
+			amount:            "600",
+			expectedMessage:   constants.HoldPlacedMsg,
+			expectedHeld:      "1600",
+			expectedAvailable: "98400",
+		},
+		{
+			name:            "amount above the available balance",
+			pin:             "1234",
+			reference:       "hold-2",
+			amount:          "99500",
+			expectedMessage: constants.InsufficientFunds,
+			expectedHeld:    "1000",
+		},
+		{
+			name:            "amount above the transaction limit",
+			pin:             "1234",
+			reference:       "hold-2",
+			amount:          "600",
+			tierLimit:       getMockLimit("500", "", "", 0, 0),
+			expectedMessage: constants.TransactionLimitExceeded,
+			expectedHeld:    "1000",
+		},
+		{
+			name:            "incorrect PIN",
+			pin:             "9999",
+			reference:       "hold-2",
+			amount:          "600",
+			expectedMessage: constants.IncorrectTransactionPin,
+			expectedHeld:    "1000",
+		},
+		{
+			name:            "payment reference of another hold",
+			pin:             "1234",
+			reference:       "hold-1",
+			amount:          "600",
+			expectedMessage: constants.NotUniqueReferenceMsg,
+			expectedHeld:    "1000",
+		},
+		{
+			name:            "expiry in the past",
+			pin:             "1234",
+			reference:       "hold-2",
+			amount:          "600",
+			expiresAt:       timePointer(time.Now().Add(-time.Minute)),
+			expectedMessage: constants.HoldExpiryNotInFuture,
+			expectedHeld:    "1000",
+		},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			// ------------ setups ------------
+			holdService, unitOfWork, mocks := createHoldService()
+			account := unitOfWork.Accounts[0]
+			addMockHold(t, unitOfWork, "hold-1", "1000", time.Now().Add(time.Hour))
+			holdService.TransferService.LimitRepository = getMockLimitRepository(tt.tierLimit, nil, model.LimitUsage{})
+			gin.SetMode(gin.TestMode)
+
+			// ------------ expectations ------------
+			mocks.transactionRepo.On("FindTransactionByReference", mock.Anything).Return(getMockNotFoundTransaction(), nil)
+			mocks.userRepo.On("GetUserAndAccountByAccountNumber", mock.Anything).Return(getMockUser(), account, nil)
+			mocks.userRepo.On("RecordFailedPinAttempt", uint(1), mock.Anything).Return(nil)
+
+			// ------------ executions -----------
+			body := getHoldRequest(tt.pin, tt.reference, tt.amount, tt.expiresAt)
+			context, recorder := newScheduledTransferContext(t, http.MethodPost, "/api/v1/bank/holds", body)
+			holdService.Place(context)
+
+			var returnedResponse struct {
+				utility.APIDataResponse
+				Data model.HoldDTO `json:"data"`
+			}
+			if jsonErr := json.Unmarshal(recorder.Body.Bytes(), &returnedResponse); jsonErr != nil {
+				t.Fatalf("Error creating test context: %v", jsonErr)
+			}
+
+			// ------------ assertions -----------
+			assert.Equal(t, http.StatusOK, recorder.Code)
+			assert.Equal(t, tt.expectedMessage, returnedResponse.Message)
+			assert.Equal(t, 0, account.HeldBalance.Decimal.Cmp(decimal.MustParse(tt.expectedHeld)))
+			assert.Equal(t, 0, account.GetBalance().Decimal.Cmp(decimal.MustParse("100000")))
+			if tt.expectedMessage != constants.HoldPlacedMsg {
+				assert.Len(t, unitOfWork.Holds, 1)
+				return
+			}
+
+			require.Len(t, unitOfWork.Holds, 2)
+			hold := returnedResponse.Data
+			assert.Equal(t, model.HoldActive, hold.Status)
+			assert.Equal(t, tt.reference, hold.PaymentReference)
+			assert.Equal(t, 0, hold.Amount.Decimal.Cmp(decimal.MustParse(tt.amount)))
+			assert.Equal(t, 0, hold.AvailableBalance.Decimal.Cmp(decimal.MustParse(tt.expectedAvailable)))
+			assert.WithinDuration(t, time.Now().Add(model.DefaultHoldExpiry), hold.ExpiresAt, time.Minute)
+			assert.Empty(t, unitOfWork.Transactions)
+		})
+	}
+}
+
+func Test_HoldReducesTheBalanceAvailableForTransfers(t *testing.T) {
+	// ------------ setups ------------
+	holdService, unitOfWork, mocks := createHoldService()
+	bankService := holdService.TransferService
+	account := unitOfWork.Accounts[0]
+	addMockHold(t, unitOfWork, "hold-1", "99950", time.Now().Add(time.Hour))
+	gin.SetMode(gin.TestMode)
+
+	// ------------ expectations ------------
+	mocks.transactionRepo.On("FindTransactionByReference", mock.Anything).Return(getMockNotFoundTransaction(), nil)
+	mocks.userRepo.On("GetUserAndAccountByAccountNumber", mock.Anything).Return(getMockUser(), account, nil)
+
+	// ------------ executions -----------
+	amount := model.BigDecimal{Decimal: decimal.MustParse("100")}
+	body := getTransactionRequest("1234567890", "johndoe", "1234", "289192938929293", model.DebitTransaction, amount)
+	context, recorder := newScheduledTransferContext(t, http.MethodPost, "/api/v1/bank/fund-transfer", body)
+	bankService.Transfer(context)
+
+	var returnedResponse utility.APIResponse
+	if jsonErr := json.Unmarshal(recorder.Body.Bytes(), &returnedResponse); jsonErr != nil {
+		t.Fatalf("Error creating test context: %v", jsonErr)
+	}
+
+	// ------------ assertions -----------
+	assert.Equal(t, constants.InsufficientFunds, returnedResponse.Message)
+	assert.Empty(t, unitOfWork.Transactions)
+	mocks.restClient.AssertNotCalled(t, "PostRequest", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func Test_CaptureHold(t *testing.T) {
+	testCases := []struct {
+		name              string
+		requestBody       string
+		expiresIn         time.Duration
+		status            model.HoldStatus
+		accountStatus     model.AccountStatus
+		providerStatus    int
+		expectedStatus    int
+		expectedMessage   string
+		expectedHold      model.HoldStatus
+		expectedCaptured  string
+		expectedBalance   string
+		expectedHeld      string
+		expectedPostCalls int
+	}{
+		{
+			name:              "whole hold",
+			requestBody:       `{}`,
+			expiresIn:         time.Hour,
+			status:            model.HoldActive,
+			providerStatus:    http.StatusOK,
+			expectedStatus:    http.StatusOK,
+			expectedMessage:   constants.HoldCapturedMsg,
+			expectedHold:      model.HoldCaptured,
+			expectedCaptured:  "600",
+			expectedBalance:   "99400",
+			expectedHeld:      "0",
+			expectedPostCalls: 1,
+		},
+		{
+			name:              "part of the hold releases the rest",
+			requestBody:       `{"amount": "250.50"}`,
+			expiresIn:         time.Hour,
+			status:            model.HoldActive,
+			providerStatus:    http.StatusOK,
+			expectedStatus:    http.StatusOK,
+			expectedMessage:   constants.HoldCapturedMsg,
+			expectedHold:      model.HoldCaptured,
+			expectedCaptured:  "250.50",
+			expectedBalance:   "99749.50",
+			expectedHeld:      "0",
+			expectedPostCalls: 1,
+		},
+		{
+			name:            "more than the hold",
+			requestBody:     `{"amount": "600.01"}`,
+			expiresIn:       time.Hour,
+			status:          model.HoldActive,
+			expectedStatus:  http.StatusOK,
+			expectedMessage: constants.CaptureAmountExceeded,
+			expectedHold:    model.HoldActive,
+			expectedBalance: "100000",
+			expectedHeld:    "600",
+		},
+		{
+			name:            "expired hold",
+			requestBody:     `{}`,
+			expiresIn:       -time.Minute,
+			status:          model.HoldActive,
+			expectedStatus:  http.StatusOK,
+			expectedMessage: constants.HoldExpired,
+			expectedHold:    model.HoldActive,
+			expectedBalance: "100000",
+			expectedHeld:    "600",
+		},
+		{
+			name:            "released hold",
+			requestBody:     `{}`,
+			expiresIn:       time.Hour,
+			status:          model.HoldReleased,
+			expectedStatus:  http.StatusOK,
+			expectedMessage: constants.HoldNotActive,
+			expectedHold:    model.HoldReleased,
+			expectedBalance: "100000",
+			expectedHeld:    "600",
+		},
+		{
+			name:            "frozen account",
+			requestBody:     `{}`,
+			expiresIn:       time.Hour,
+			status:          model.HoldActive,
+			accountStatus:   model.FrozenAccount,
+			expectedStatus:  http.StatusOK,
+			expectedMessage: constants.AccountDebitsNotAllowed,
+			expectedHold:    model.HoldActive,
+			expectedBalance: "100000",
+			expectedHeld:    "600",
+		},
+		{
+			name:              "provider rejects the debit",
+			requestBody:       `{}`,
+			expiresIn:         time.Hour,
+			status:            model.HoldActive,
+			providerStatus:    http.StatusBadRequest,
+			expectedStatus:    http.StatusOK,
+			expectedMessage:   constants.TransactionRejected,
+			expectedHold:      model.HoldCaptured,
+			expectedCaptured:  "600",
+			expectedBalance:   "100000",
+			expectedHeld:      "0",
+			expectedPostCalls: 1,
+		},
+		{
+			name:            "invalid amount",
+			requestBody:     `{"amount": "-5"}`,
+			expiresIn:       time.Hour,
+			status:          model.HoldActive,
+			expectedStatus:  http.StatusBadRequest,
+			expectedMessage: constants.BadRequestMessage,
+			expectedHold:    model.HoldActive,
+			expectedBalance: "100000",
+			expectedHeld:    "600",
+		},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			// ------------ setups ------------
+			holdService, unitOfWork, mocks := createHoldService()
+			account := unitOfWork.Accounts[0]
+			hold := addMockHold(t, unitOfWork, "hold-1", "600", time.Now().Add(tt.expiresIn))
+			hold.Status = tt.status
+			account.Status = tt.accountStatus
+			gin.SetMode(gin.TestMode)
+
+			// ------------ expectations ------------
+			mocks.config.On("ThirdPartyBaseUrl").Return("http://provider")
+			mocks.restClient.
+				On("PostRequest", "http://provider/api/v1/third-party/payments", mock.Anything, mock.Anything).
+				Return(getSuccessThirdPartyResponse(), tt.providerStatus, nil)
+
+			// ------------ executions -----------
+			context, recorder := newScheduledTransferContext(t, http.MethodPost, "/api/v1/bank/holds/1/capture",
+				[]byte(tt.requestBody))
+			context.Params = gin.Params{{Key: "id", Value: "1"}}
+			holdService.Capture(context)
+
+			var returnedResponse struct {
+				utility.APIDataResponse
+				Data model.HoldDTO `json:"data"`
+			}
+			if jsonErr := json.Unmarshal(recorder.Body.Bytes(), &returnedResponse); jsonErr != nil {
+				t.Fatalf("Error creating test context: %v", jsonErr)
+			}
+
+			// ------------ assertions -----------
+			assert.Equal(t, tt.expectedStatus, recorder.Code)
+			assert.Equal(t, tt.expectedMessage, returnedResponse.Message)
+			assert.Equal(t, tt.expectedHold, hold.Status)
+			assert.Equal(t, 0, account.GetBalance().Decimal.Cmp(decimal.MustParse(tt.expectedBalance)))
+			assert.Equal(t, 0, account.HeldBalance.Decimal.Cmp(decimal.MustParse(tt.expectedHeld)))
+			mocks.restClient.AssertNumberOfCalls(t, "PostRequest", tt.expectedPostCalls)
+			if tt.expectedCaptured == "" {
+				assert.Empty(t, unitOfWork.Transactions)
+				return
+			}
+
+			require.Len(t, unitOfWork.Transactions, 1)
+			transaction := unitOfWork.Transactions[0]
+			assert.Equal(t, model.DebitTransaction, transaction.Type)
+			assert.Equal(t, "hold-1", transaction.PaymentReference)
+			assert.Equal(t, 0, transaction.Amount.Decimal.Cmp(decimal.MustParse(tt.expectedCaptured)))
+			assert.Equal(t, 0, hold.CapturedAmount.Decimal.Cmp(decimal.MustParse(tt.expectedCaptured)))
+			require.NotNil(t, hold.TransactionID)
+			assert.Equal(t, transaction.TransactionID, *hold.TransactionID)
+			if tt.expectedMessage == constants.HoldCapturedMsg {
+				assert.Equal(t, transaction.Reference, returnedResponse.Data.TransactionReference)
+				assert.Equal(t, model.SucceededStatus, returnedResponse.Data.TransactionStatus)
+			}
+		})
+	}
+}
+
+func Test_ReleaseHold(t *testing.T) {
+	testCases := []struct {
+		name            string
+		id              string
+		status          model.HoldStatus
+		expectedMessage string
+		expectedHeld    string
+	}{
+		{name: "active hold", id: "1", status: model.HoldActive, expectedMessage: constants.HoldReleasedMsg, expectedHeld: "0"},
+		{name: "captured hold", id: "1", status: model.HoldCaptured, expectedMessage: constants.HoldNotActive, expectedHeld: "600"},
+		{name: "unknown hold", id: "2", status: model.HoldActive, expectedMessage: constants.HoldNotFound, expectedHeld: "600"},
+		{name: "invalid id", id: "abc", status: model.HoldActive, expectedMessage: constants.HoldNotFound, expectedHeld: "600"},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			// ------------ setups ------------
+			holdService, unitOfWork, _ := createHoldService()
+			account := unitOfWork.Accounts[0]
+			hold := addMockHold(t, unitOfWork, "hold-1", "600", time.Now().Add(time.Hour))
+			hold.Status = tt.status
+			gin.SetMode(gin.TestMode)
+
+			// ------------ executions -----------
+			context, recorder := newScheduledTransferContext(t, http.MethodPost, "/api/v1/bank/holds/"+tt.id+"/release", nil)
+			context.Params = gin.Params{{Key: "id", Value: tt.id}}
+			holdService.Release(context)
+
+			var returnedResponse struct {
+				utility.APIDataResponse
+				Data model.HoldDTO `json:"data"`
+			}
+			if jsonErr := json.Unmarshal(recorder.Body.Bytes(), &returnedResponse); jsonErr != nil {
+				t.Fatalf("Error creating test context: %v", jsonErr)
+			}
+
+			// ------------ assertions -----------
+			assert.Equal(t, http.StatusOK, recorder.Code)
+			assert.Equal(t, tt.expectedMessage, returnedResponse.Message)
+			assert.Equal(t, 0, account.HeldBalance.Decimal.Cmp(decimal.MustParse(tt.expectedHeld)))
+			if tt.expectedMessage == constants.HoldReleasedMsg {
+				assert.Equal(t, model.HoldReleased, hold.Status)
+				assert.Equal(t, model.HoldReleased, returnedResponse.Data.Status)
+			}
+		})
+	}
+}
+
+func Test_GetHold(t *testing.T) {
+	testCases := []struct {
+		name            string
+		username        string
+		userID          uint
+		role            model.UserRole
+		expectedStatus  int
+		expectedMessage string
+	}{
+		{name: "owner", username: "1234567890", userID: 1, role: model.CustomerRole,
+			expectedStatus: http.StatusOK, expectedMessage: constants.HoldFoundMsg},
+		{name: "staff", username: "teller", userID: 7, role: model.TellerRole,
+			expectedStatus: http.StatusOK, expectedMessage: constants.HoldFoundMsg},
+		{name: "another customer", username: "stranger", userID: 8, role: model.CustomerRole,
+			expectedStatus: http.StatusForbidden, expectedMessage: constants.AccountAccessDenied},
+		{name: "not authenticated", expectedStatus: http.StatusUnauthorized, expectedMessage: constants.AccessTokenRequired},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			// ------------ setups ------------
+			holdService, unitOfWork, mocks := createHoldService()
+			addMockHold(t, unitOfWork, "hold-1", "600", time.Now().Add(time.Hour))
+			gin.SetMode(gin.TestMode)
+
+			// ------------ expectations ------------
+			mocks.accountRepo.On("GetAccountByAccountNumber", "1234567890").Return(getMockAccount(), nil)
+
+			// ------------ executions -----------
+			context, recorder := newScheduledTransferContext(t, http.MethodGet, "/api/v1/bank/holds/1", nil)
+			context.Params = gin.Params{{Key: "id", Value: "1"}}
+			if tt.username != "" {
+				authenticate(t, context, tt.username, tt.userID, tt.role)
+			}
+			holdService.Get(context)
+
+			var returnedResponse struct {
+				utility.APIDataResponse
+				Data model.HoldDTO `json:"data"`
+			}
+			if jsonErr := json.Unmarshal(recorder.Body.Bytes(), &returnedResponse); jsonErr != nil {
+				t.Fatalf("Error creating test context: %v", jsonErr)
+			}
+
+			// ------------ assertions -----------
+			assert.Equal(t, tt.expectedStatus, recorder.Code)
+			assert.Equal(t, tt.expectedMessage, returnedResponse.Message)
+			if tt.expectedStatus != http.StatusOK {
+				return
+			}
+			assert.Equal(t, "hold-1", returnedResponse.Data.PaymentReference)
+			assert.Equal(t, "1234567890", returnedResponse.Data.AccountNumber)
+			assert.Equal(t, model.HoldActive, returnedResponse.Data.Status)
+			assert.Nil(t, returnedResponse.Data.CapturedAmount)
+		})
+	}
+}
+
+func Test_ExpireDueHolds(t *testing.T) {
+	// ------------ setups ------------
+	holdService, unitOfWork, _ := createHoldService()
+	account := unitOfWork.Accounts[0]
+	now := time.Now()
+	stale := addMockHold(t, unitOfWork, "hold-1", "600", now.Add(-time.Minute))
+	current := addMockHold(t, unitOfWork, "hold-2", "400", now.Add(time.Hour))
+
+	// ------------ executions -----------
+	require.NoError(t, holdService.ExpireDue(context.Background(), now))
+
+	// ------------ assertions -----------
+	assert.Equal(t, model.HoldExpired, stale.Status)
+	assert.Equal(t, model.HoldActive, current.Status)
+	assert.Equal(t, 0, account.HeldBalance.Decimal.Cmp(decimal.MustParse("400")))
+	assert.Equal(t, 0, account.AvailableBalance().Decimal.Cmp(decimal.MustParse("99600")))
+}
+
+// holdMocks are the mocks behind the transfer service of a hold service
+type holdMocks struct {
+	config          *MockConfig
+	transactionRepo *MockTransactionRepository
+	userRepo        *MockUserRepository
+	accountRepo     *MockAccountRepository
+	restClient      *MockRestHttpClient
+}
+
+func createHoldService() (*HoldService, *FakeUnitOfWork, holdMocks) {
+	mockConfig, mockTransactionRepo, mockUserRepo, mockAccountRepo, mockRestClient, _ := setupMocks()
+	bankService := createBankService(mockConfig, mockTransactionRepo, mockUserRepo, mockAccountRepo, mockRestClient)
+	unitOfWork := &FakeUnitOfWork{Accounts: []*model.Account{getMockAccount()}}
+	bankService.UnitOfWork = unitOfWork
+	mocks := holdMocks{
+		config:          mockConfig,
+		transactionRepo: mockTransactionRepo,
+		userRepo:        mockUserRepo,
+		accountRepo:     mockAccountRepo,
+		restClient:      mockRestClient,
+	}
+	return NewHoldService(&FakeHoldRepository{UnitOfWork: unitOfWork}, bankService), unitOfWork, mocks
+}
+
+// addMockHold places an active hold on the mock account
+func addMockHold(t *testing.T, unitOfWork *FakeUnitOfWork, reference, amount string, expiresAt time.Time) *model.Hold {
+	account := unitOfWork.Accounts[0]
+	value := model.BigDecimal{Decimal: decimal.MustParse(amount)}
+	require.NoError(t, account.PlaceHold(value))
+	hold := &model.Hold{
+		AccountID:        account.AccountID,
+		AccountNumber:    account.AccountNumber,
+		PaymentReference: reference,
+		Amount:           value,
+		Status:           model.HoldActive,
+		ExpiresAt:        expiresAt,
+	}
+	require.NoError(t, unitOfWork.SaveHold(hold))
+	return hold
+}
+
+func getHoldRequest(pin, reference, amount string, expiresAt *time.Time) []byte {
+	requestBody, _ := json.Marshal(model.HoldRequestDTO{
+		AccountNumber:  "1234567890",
+		Username:       "johndoe",
+		TransactionPin: pin,
+		Reference:      reference,
+		Amount:         model.BigDecimal{Decimal: decimal.MustParse(amount)},
+		ExpiresAt:      expiresAt,
+	})
+	return requestBody
+}
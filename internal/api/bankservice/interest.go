@@ -0,0 +1,389 @@
+package bankservice
+
+import (
+	"bankingApp/internal/api/constants"
+	"bankingApp/internal/currency"
+	"bankingApp/internal/interest"
+	"bankingApp/internal/ledger"
+	"bankingApp/internal/model"
+	"bankingApp/internal/repository"
+	"bankingApp/internal/utility"
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/govalues/decimal"
+)
+
+const (
+	interestBatchSize = 50
+	maxProductLength  = 30
+)
+
+type IInterestRepository interface {
+	FindProducts() ([]model.AccountProduct, error)
+	FindProduct(code string) (*model.AccountProduct, error)
+	SaveProduct(product *model.AccountProduct) error
+	AssignProduct(accountID uint, code string) error
+	FindAccountsToAccrueInterest(day time.Time, limit int) ([]model.Account, error)
+	FindAccountsToCapitalise(before time.Time, limit int) ([]model.Account, error)
+	FindUncapitalisedAccruals(accountID uint) ([]model.InterestAccrual, error)
+}
+
+// InterestService maintains the savings products accounts are opened under, accrues their interest daily
+// and capitalises it monthly into a credit to the account
+type InterestService struct {
+	Repository         IInterestRepository
+	AccountRepository  IAccountRepository
+	UnitOfWork         IUnitOfWork
+	ReferenceGenerator IReferenceGenerator
+}
+
+// NewInterestService creates a new instance of InterestService
+func NewInterestService(
+	repository IInterestRepository,
+	accountRepository IAccountRepository,
+	unitOfWork IUnitOfWork,
+	referenceGenerator IReferenceGenerator) *InterestService {
+	return &InterestService{
+		Repository:         repository,
+		AccountRepository:  accountRepository,
+		UnitOfWork:         unitOfWork,
+		ReferenceGenerator: referenceGenerator,
+	}
+}
+
+// SaveProduct handles the admin endpoint creating or replacing the account product in the code path parameter.
+// A changed rate applies to the interest accounts of the product accrue from then on.
+func (i *InterestService) SaveProduct(c *gin.Context) {
+	code := c.Param("code")
+	if code == "" || len(code) > maxProductLength {
+		utility.HandleError(c, nil, http.StatusBadRequest, constants.InvalidAccountProduct)
+		return
+	}
+
+	r, ok := bindRequest[model.AccountProductDTO](c)
+	if !ok {
+		return
+	}
+
+	product := &model.AccountProduct{
+		Code:          code,
+		Name:          r.Name,
+		AnnualRate:    *r.AnnualRate,
+		DayCount:      r.DayCount,
+		TimestampData: model.TimestampData{CreatedAt: time.Now(), UpdatedAt: time.Now()},
+	}
+	if r.WithholdingTaxRate != nil {
+		product.WithholdingTaxRate = *r.WithholdingTaxRate
+	}
+
+	if err := interest.Validate(product); err != nil {
+		utility.HandleError(c, nil, http.StatusOK, constants.InvalidAccountProduct)
+		return
+	}
+
+	if err := i.Repository.SaveProduct(product); err != nil {
+		utility.HandleError(c, err, http.StatusInternalServerError, constants.ApplicationError)
+		return
+	}
+	c.JSON(http.StatusOK, utility.FormulateDataResponse(constants.AccountProductSavedMsg, productDTO(product)))
+}
+
+// ListProducts handles the endpoint listing the account products
+func (i *InterestService) ListProducts(c *gin.Context) {
+	products, err := i.Repository.FindProducts()
+	if err != nil {
+		utility.HandleError(c, err, http.StatusInternalServerError, constants.ApplicationError)
+		return
+	}
+
+	response := make([]model.AccountProductDTO, 0, len(products))
+	for index := range products {
+		response = append(response, productDTO(&products[index]))
+	}
+	c.JSON(http.StatusOK, utility.FormulateDataResponse(constants.AccountProductsFoundMsg, response))
+}
+
+// AssignProduct handles the admin endpoint opening an account under an account product. Interest the account
+// accrued under its previous product is capitalised with the rest at the end of the month.
+func (i *InterestService) AssignProduct(c *gin.Context) {
+	account, ok := findAccount(c, i.AccountRepository)
+	if !ok {
+		return
+	}
+
+	r, ok := bindRequest[model.AssignProductRequestDTO](c)
+	if !ok {
+		return
+	}
+
+	product, err := i.Repository.FindProduct(r.Product)
+	if err != nil {
+		utility.HandleError(c, err, http.StatusInternalServerError, constants.ApplicationError)
+		return
+	}
+
+	if product.AccountProductID == constants.Zero {
+		utility.HandleError(c, nil, http.StatusOK, constants.AccountProductNotFound)
+		return
+	}
+
+	if err := i.Repository.AssignProduct(account.AccountID, product.Code); err != nil {
+		utility.HandleError(c, err, http.StatusInternalServerError, constants.ApplicationError)
+		return
+	}
+	account.Product = product.Code
+	i.respondWithInterest(c, account, product, constants.AccountProductAssignedMsg)
+}
+
+// Interest handles the endpoint reporting the interest an account accrued since its last capitalisation
+func (i *InterestService) Interest(c *gin.Context) {
+	account, ok := findReadableAccount(c, i.AccountRepository)
+	if !ok {
+		return
+	}
+
+	product, err := i.Repository.FindProduct(account.Product)
+	if err != nil {
+		utility.HandleError(c, err, http.StatusInternalServerError, constants.ApplicationError)
+		return
+	}
+	i.respondWithInterest(c, account, product, constants.InterestFoundMsg)
+}
+
+// AccrueDaily accrues a day of interest for the day of now on the balance of every account in credit that is
+// opened under a product. Each account accrues once a day however often the job runs.
+func (i *InterestService) AccrueDaily(ctx context.Context, now time.Time) error {
+	day, _ := model.LimitPeriods(now)
+	accounts, err := i.Repository.FindAccountsToAccrueInterest(day, interestBatchSize)
+	if err != nil {
+		return err
+	}
+
+	products, err := i.productsByCode()
+	if err != nil {
+		return err
+	}
+
+	for index := range accounts {
+		accountID := accounts[index].AccountID
+		if err := i.accrue(accountID, products, day); err != nil {
+			slog.Error("unable to accrue interest", "account_id", accountID, "error", err)
+		}
+	}
+	return nil
+}
+
+// CapitaliseMonthly capitalises the interest every account accrued before the month of now. The accrued interest
+// is credited to the account in the minor unit of its currency less withholding tax, and what is below the
+// minor unit is carried into the next capitalisation.
+func (i *InterestService) CapitaliseMonthly(ctx context.Context, now time.Time) error {
+	_, month := model.LimitPeriods(now)
+	accounts, err := i.Repository.FindAccountsToCapitalise(month, interestBatchSize)
+	if err != nil {
+		return err
+	}
+
+	products, err := i.productsByCode()
+	if err != nil {
+		return err
+	}
+
+	for index := range accounts {
+		accountID := accounts[index].AccountID
+		if err := i.capitalise(accountID, products, month, now); err != nil {
+			slog.Error("unable to capitalise interest", "account_id", accountID, "error", err)
+		}
+	}
+	return nil
+}
+
+// accrue records a day of interest on the balance of the account at the rate of its product.
+// Days that accrue nothing, like the 30th of a 31-day month under 30/360, are recorded too so they are not accrued again.
+func (i *InterestService) accrue(accountID uint, products map[string]model.AccountProduct, day time.Time) error {
+	return i.UnitOfWork.Execute(func(tx repository.ITx) error {
+		account, err := tx.LockAccount(accountID)
+		if err != nil {
+			return err
+		}
+
+		product, ok := products[account.Product]
+		if !ok {
+			return fmt.Errorf("%w: account product %q does not exist", interest.ErrInvalidProduct, account.Product)
+		}
+
+		balance := account.GetBalance()
+		accrued, err := interest.DailyAccrual(balance.Decimal, product.AnnualRate.Decimal, product.DayCount, day)
+		if err != nil {
+			return err
+		}
+
+		return tx.SaveInterestAccrual(&model.InterestAccrual{
+			AccountID:   account.AccountID,
+			AccrualDate: day,
+			Balance:     balance,
+			Rate:        product.AnnualRate,
+			DayCount:    product.DayCount,
+			Amount:      model.BigDecimal{Decimal: accrued},
+		})
+	})
+}
+
+// capitalise credits the account with the interest accrued before the given month, withholding tax at the rate
+// of its product, and marks the accruals capitalised in one commit
+func (i *InterestService) capitalise(
+	accountID uint,
+	products map[string]model.AccountProduct,
+	month, now time.Time) error {
+	return i.UnitOfWork.Execute(func(tx repository.ITx) error {
+		account, err := tx.LockAccount(accountID)
+		if err != nil {
+			return err
+		}
+
+		accruals, err := tx.LockUncapitalisedAccruals(account.AccountID, month)
+		if err != nil || len(accruals) == 0 {
+			return err
+		}
+
+		accrued, err := accruedInterest(account.InterestCarried, accruals)
+		if err != nil {
+			return err
+		}
+
+		taxRate := products[account.Product].WithholdingTaxRate
+		capitalisation, err := interest.Capitalise(accrued, taxRate.Decimal, currency.MinorUnits(account.Currency))
+		if err != nil {
+			return err
+		}
+
+		var transactionID *uint
+		if capitalisation.Gross.IsPos() {
+			reference, err := i.ReferenceGenerator.NewReference()
+			if err != nil {
+				return err
+			}
+
+			credit := newCapitalisationTransaction(account, reference, month, capitalisation, now)
+			entry, err := ledger.InterestCapitalisationEntry(credit)
+			if err != nil {
+				return err
+			}
+			if err := tx.PostCharge(entry); err != nil {
+				return err
+			}
+			if err := tx.SaveTransaction(credit); err != nil {
+				return err
+			}
+			transactionID = &credit.TransactionID
+		}
+
+		account.InterestCarried = model.BigDecimal{Decimal: capitalisation.Remainder}
+		if err := tx.UpdateInterestCarried(account); err != nil {
+			return err
+		}
+		return tx.MarkAccrualsCapitalised(accruals, transactionID, now)
+	})
+}
+
+// productsByCode returns the account products by their code
+func (i *InterestService) productsByCode() (map[string]model.AccountProduct, error) {
+	products, err := i.Repository.FindProducts()
+	if err != nil {
+		return nil, err
+	}
+
+	byCode := make(map[string]model.AccountProduct, len(products))
+	for _, product := range products {
+		byCode[product.Code] = product
+	}
+	return byCode, nil
+}
+
+// respondWithInterest responds with the product of the account and the interest it accrued since it was last
+// capitalised, including what was carried over from the last capitalisation
+func (i *InterestService) respondWithInterest(
+	c *gin.Context,
+	account *model.Account,
+	product *model.AccountProduct,
+	message string) {
+	accruals, err := i.Repository.FindUncapitalisedAccruals(account.AccountID)
+	if err != nil {
+		utility.HandleError(c, err, http.StatusInternalServerError, constants.ApplicationError)
+		return
+	}
+
+	accrued, err := accruedInterest(account.InterestCarried, accruals)
+	if err != nil {
+		utility.HandleError(c, err, http.StatusInternalServerError, constants.ApplicationError)
+		return
+	}
+
+	response := model.InterestDTO{
+		AccountNumber:   account.AccountNumber,
+		Currency:        account.Currency,
+		Product:         account.Product,
+		AccruedInterest: model.BigDecimal{Decimal: accrued},
+		AccruedDays:     len(accruals),
+	}
+	if product.AccountProductID != constants.Zero {
+		response.AnnualRate = &product.AnnualRate
+		response.DayCount = product.DayCount
+		response.WithholdingTaxRate = setAmount(product.WithholdingTaxRate)
+	}
+	c.JSON(http.StatusOK, utility.FormulateDataResponse(message, response))
+}
+
+// accruedInterest adds up the accruals and the interest carried over from the last capitalisation
+func accruedInterest(carried model.BigDecimal, accruals []model.InterestAccrual) (decimal.Decimal, error) {
+	total := carried.Decimal
+	for _, accrual := range accruals {
+		var err error
+		if total, err = total.Add(accrual.Amount.Decimal); err != nil {
+			return decimal.Decimal{}, err
+		}
+	}
+	return total, nil
+}
+
+// newCapitalisationTransaction creates the credit of the gross interest accrued before the month, with the
+// withholding tax on it as its fee. The payment reference names the account and the month the interest is for.
+func newCapitalisationTransaction(
+	account *model.Account,
+	reference string,
+	month time.Time,
+	capitalisation interest.Capitalisation,
+	now time.Time) *model.Transaction {
+	gross := model.BigDecimal{Decimal: capitalisation.Gross}
+	return &model.Transaction{
+		AccountID:        account.AccountID,
+		Reference:        reference,
+		PaymentReference: fmt.Sprintf("INT-%s-%s", account.AccountNumber, month.AddDate(0, -1, 0).Format("200601")),
+		Amount:           gross,
+		Currency:         account.Currency,
+		TransferAmount:   gross,
+		TransferCurrency: account.Currency,
+		ExchangeRate:     model.BigDecimal{Decimal: decimal.One},
+		Fee:              model.BigDecimal{Decimal: capitalisation.Tax},
+		Type:             model.CreditTransaction,
+		Status:           model.SucceededStatus,
+		TransactionTime:  now,
+		TimestampData:    model.TimestampData{CreatedAt: now},
+	}
+}
+
+// productDTO converts an account product to its response representation
+func productDTO(product *model.AccountProduct) model.AccountProductDTO {
+	annualRate := product.AnnualRate
+	return model.AccountProductDTO{
+		Code:               product.Code,
+		Name:               product.Name,
+		AnnualRate:         &annualRate,
+		DayCount:           product.DayCount,
+		WithholdingTaxRate: setAmount(product.WithholdingTaxRate),
+	}
+}
@@ -0,0 +1,359 @@
+package bankservice
+
+import (
+	"bankingApp/internal/api/constants"
+	"bankingApp/internal/ledger"
+	"bankingApp/internal/model"
+	"bankingApp/internal/utility"
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/govalues/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// FakeInterestRepository keeps account products in memory and reads accruals saved through the fake unit of work
+type FakeInterestRepository struct {
+	UnitOfWork *FakeUnitOfWork
+	Products   []model.AccountProduct
+	Assigned   map[uint]string
+}
+
+func (f *FakeInterestRepository) FindProducts() ([]model.AccountProduct, error) {
+	return f.Products, nil
+}
+
+func (f *FakeInterestRepository) FindProduct(code string) (*model.AccountProduct, error) {
+	for _, product := range f.Products {
+		if product.Code == code {
+			found := product
+			return &found, nil
+		}
+	}
+	return &model.AccountProduct{}, nil
+}
+
+func (f *FakeInterestRepository) SaveProduct(product *model.AccountProduct) error {
+	product.AccountProductID = uint(len(f.Products) + 1)
+	f.Products = append(f.Products, *product)
+	return nil
+}
+
+func (f *FakeInterestRepository) AssignProduct(accountID uint, code string) error {
+	if f.Assigned == nil {
+		f.Assigned = make(map[uint]string)
+	}
+	f.Assigned[accountID] = code
+	return nil
+}
+
+func (f *FakeInterestRepository) FindAccountsToAccrueInterest(day time.Time, limit int) ([]model.Account, error) {
+	accrued := make(map[uint]bool)
+	for _, accrual := range f.UnitOfWork.Interest {
+		if accrual.AccrualDate.Equal(day) {
+			accrued[accrual.AccountID] = true
+		}
+	}
+
+	var accounts []model.Account
+	for _, account := range f.UnitOfWork.Accounts {
+		if account.Product != "" && account.Balance.Decimal.IsPos() && !accrued[account.AccountID] &&
+			len(accounts) < limit {
+			accounts = append(accounts, model.Account{AccountID: account.AccountID})
+		}
+	}
+	return accounts, nil
+}
+
+func (f *FakeInterestRepository) FindAccountsToCapitalise(before time.Time, limit int) ([]model.Account, error) {
+	var accounts []model.Account
+	for _, account := range f.UnitOfWork.Accounts {
+		accruals, _ := f.UnitOfWork.LockUncapitalisedAccruals(account.AccountID, before)
+		if len(accruals) > 0 && len(accounts) < limit {
+			accounts = append(accounts, model.Account{AccountID: account.AccountID})
+		}
+	}
+	return accounts, nil
+}
+
+func (f *FakeInterestRepository) FindUncapitalisedAccruals(accountID uint) ([]model.InterestAccrual, error) {
+	return f.UnitOfWork.LockUncapitalisedAccruals(accountID, time.Date(9999, 1, 1, 0, 0, 0, 0, time.UTC))
+}
+
+func Test_SaveAccountProduct(t *testing.T) {
+	testCases := []struct {
+		name            string
+		code            string
+		requestBody     string
+		expectedStatus  int
+		expectedMessage string
+	}{
+		{
+			name:            "product with withholding tax",
+			code:            "SAVER",
+			requestBody:     `{"name": "Easy saver", "annual_rate": "3.5", "day_count": "ACT/365", "withholding_tax_rate": "10"}`,
+			expectedStatus:  http.StatusOK,
+			expectedMessage: constants.AccountProductSavedMsg,
+		},
+		{
+			name:            "product without withholding tax",
+			code:            "BONUS",
+			requestBody:     `{"name": "Bonus saver", "annual_rate": 5, "day_count": "30/360"}`,
+			expectedStatus:  http.StatusOK,
+			expectedMessage: constants.AccountProductSavedMsg,
+		},
+		{
+			name:            "unknown day-count convention",
+			code:            "SAVER",
+			requestBody:     `{"name": "Easy saver", "annual_rate": "3.5", "day_count": "ACT/ACT"}`,
+			expectedStatus:  http.StatusOK,
+			expectedMessage: constants.InvalidAccountProduct,
+		},
+		{
+			name:            "rate over 100",
+			code:            "SAVER",
+			requestBody:     `{"name": "Easy saver", "annual_rate": "101", "day_count": "ACT/365"}`,
+			expectedStatus:  http.StatusOK,
+			expectedMessage: constants.InvalidAccountProduct,
+		},
+		{
+			name:            "missing rate",
+			code:            "SAVER",
+			requestBody:     `{"name": "Easy saver", "day_count": "ACT/365"}`,
+			expectedStatus:  http.StatusBadRequest,
+			expectedMessage: constants.BadRequestMessage,
+		},
+		{
+			name:            "code too long",
+			code:            "A-VERY-LONG-PRODUCT-CODE-INDEED",
+			requestBody:     `{"name": "Easy saver", "annual_rate": "3.5", "day_count": "ACT/365"}`,
+			expectedStatus:  http.StatusBadRequest,
+			expectedMessage: constants.InvalidAccountProduct,
+		},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			// ------------ setups ------------
+			interestService, _, repository, _ := createInterestService()
+			repository.Products = nil
+			gin.SetMode(gin.TestMode)
+
+			// ------------ executions -----------
+			context, recorder := newScheduledTransferContext(t, http.MethodPut,
+				"/api/v1/bank/admin/account-products/"+tt.code, []byte(tt.requestBody))
+			context.Params = gin.Params{{Key: "code", Value: tt.code}}
+			interestService.SaveProduct(context)
+
+			var returnedResponse struct {
+				utility.APIDataResponse
+				Data model.AccountProductDTO `json:"data"`
+			}
+			if jsonErr := json.Unmarshal(recorder.Body.Bytes(), &returnedResponse); jsonErr != nil {
+				t.Fatalf("Error creating test context: %v", jsonErr)
+			}
+
+			// ------------ assertions -----------
+			assert.Equal(t, tt.expectedStatus, recorder.Code)
+			assert.Equal(t, tt.expectedMessage, returnedResponse.Message)
+			if tt.expectedMessage != constants.AccountProductSavedMsg {
+				assert.Empty(t, repository.Products)
+				return
+			}
+
+			require.Len(t, repository.Products, 1)
+			assert.Equal(t, tt.code, repository.Products[0].Code)
+			assert.Equal(t, tt.code, returnedResponse.Data.Code)
+		})
+	}
+}
+
+func Test_AssignAccountProduct(t *testing.T) {
+	testCases := []struct {
+		name            string
+		product         string
+		expectedMessage string
+	}{
+		{name: "existing product", product: "SAVER", expectedMessage: constants.AccountProductAssignedMsg},
+		{name: "unknown product", product: "GOLD", expectedMessage: constants.AccountProductNotFound},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			// ------------ setups ------------
+			interestService, unitOfWork, repository, mockAccountRepo := createInterestService()
+			account := unitOfWork.Accounts[0]
+			gin.SetMode(gin.TestMode)
+
+			// ------------ expectations ------------
+			mockAccountRepo.On("GetAccountByAccountNumber", "1234567890").Return(account, nil)
+
+			// ------------ executions -----------
+			context, recorder := newScheduledTransferContext(t, http.MethodPut,
+				"/api/v1/bank/admin/accounts/1234567890/product", []byte(`{"product": "`+tt.product+`"}`))
+			context.Params = gin.Params{{Key: "number", Value: "1234567890"}}
+			interestService.AssignProduct(context)
+
+			var returnedResponse struct {
+				utility.APIDataResponse
+				Data model.InterestDTO `json:"data"`
+			}
+			if jsonErr := json.Unmarshal(recorder.Body.Bytes(), &returnedResponse); jsonErr != nil {
+				t.Fatalf("Error creating test context: %v", jsonErr)
+			}
+
+			// ------------ assertions -----------
+			assert.Equal(t, http.StatusOK, recorder.Code)
+			assert.Equal(t, tt.expectedMessage, returnedResponse.Message)
+			if tt.expectedMessage != constants.AccountProductAssignedMsg {
+				assert.Empty(t, repository.Assigned)
+				return
+			}
+
+			assert.Equal(t, "SAVER", repository.Assigned[account.AccountID])
+			assert.Equal(t, "SAVER", returnedResponse.Data.Product)
+			assert.Equal(t, model.Actual365, returnedResponse.Data.DayCount)
+			assert.Equal(t, 0, returnedResponse.Data.AnnualRate.Decimal.Cmp(decimal.MustParse("3.65")))
+		})
+	}
+}
+
+func Test_AccrueDailyInterest(t *testing.T) {
+	// ------------ setups ------------
+	interestService, unitOfWork, _, _ := createInterestService()
+	saver := unitOfWork.Accounts[0]
+	saver.Product = "SAVER"
+	overdrawn := &model.Account{AccountID: 2, Product: "SAVER", Balance: amountOf("-50")}
+	current := &model.Account{AccountID: 3, Balance: amountOf("5000")}
+	unitOfWork.Accounts = append(unitOfWork.Accounts, overdrawn, current)
+	now := time.Date(2024, 2, 10, 15, 30, 0, 0, time.UTC)
+
+	// ------------ executions -----------
+	require.NoError(t, interestService.AccrueDaily(context.Background(), now))
+	require.NoError(t, interestService.AccrueDaily(context.Background(), now.Add(time.Hour)))
+
+	// ------------ assertions -----------
+	require.Len(t, unitOfWork.Interest, 1)
+	accrual := unitOfWork.Interest[0]
+	assert.Equal(t, saver.AccountID, accrual.AccountID)
+	assert.Equal(t, time.Date(2024, 2, 10, 0, 0, 0, 0, time.UTC), accrual.AccrualDate)
+	assert.Equal(t, model.Actual365, accrual.DayCount)
+	assert.Equal(t, 0, accrual.Amount.Decimal.Cmp(decimal.MustParse("10")))
+	assert.Equal(t, 0, saver.GetBalance().Decimal.Cmp(decimal.MustParse("100000")))
+	assert.Empty(t, unitOfWork.Entries)
+
+	require.NoError(t, interestService.AccrueDaily(context.Background(), now.AddDate(0, 0, 1)))
+	assert.Len(t, unitOfWork.Interest, 2)
+}
+
+func Test_CapitaliseMonthlyInterest(t *testing.T) {
+	testCases := []struct {
+		name            string
+		taxRate         string
+		carried         string
+		expectedGross   string
+		expectedTax     string
+		expectedBalance string
+		expectedCarried string
+	}{
+		{
+			name:            "interest without withholding tax",
+			taxRate:         "0",
+			expectedGross:   "278.08",
+			expectedTax:     "0",
+			expectedBalance: "100278.08",
+			expectedCarried: "0.0021917808219186",
+		},
+		{
+			name:            "interest less withholding tax",
+			taxRate:         "10",
+			expectedGross:   "278.08",
+			expectedTax:     "27.81",
+			expectedBalance: "100250.27",
+			expectedCarried: "0.0021917808219186",
+		},
+		{
+			name:            "carried interest makes up another minor unit",
+			taxRate:         "0",
+			carried:         "0.0090821917808219",
+			expectedGross:   "278.09",
+			expectedTax:     "0",
+			expectedBalance: "100278.09",
+			expectedCarried: "0.0012739726027405",
+		},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			// ------------ setups ------------
+			interestService, unitOfWork, repository, _ := createInterestService()
+			repository.Products[0].WithholdingTaxRate = amountOf(tt.taxRate)
+			account := unitOfWork.Accounts[0]
+			account.Product = "SAVER"
+			account.Currency = "NGN"
+			if tt.carried != "" {
+				account.InterestCarried = amountOf(tt.carried)
+			}
+			for day := 1; day <= 29; day++ {
+				addMockAccrual(unitOfWork, account.AccountID, time.Date(2024, 2, day, 0, 0, 0, 0, time.UTC),
+					"9.589041095890410959")
+			}
+			addMockAccrual(unitOfWork, account.AccountID, time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+				"9.589041095890410959")
+			now := time.Date(2024, 3, 1, 0, 5, 0, 0, time.UTC)
+
+			// ------------ executions -----------
+			require.NoError(t, interestService.CapitaliseMonthly(context.Background(), now))
+			require.NoError(t, interestService.CapitaliseMonthly(context.Background(), now.Add(time.Hour)))
+
+			// ------------ assertions -----------
+			require.Len(t, unitOfWork.Transactions, 1)
+			credit := unitOfWork.Transactions[0]
+			assert.Equal(t, model.CreditTransaction, credit.Type)
+			assert.Equal(t, model.SucceededStatus, credit.Status)
+			assert.Equal(t, "INT-1234567890-202402", credit.PaymentReference)
+			assert.Equal(t, 0, credit.Amount.Decimal.Cmp(decimal.MustParse(tt.expectedGross)))
+			assert.Equal(t, 0, credit.Fee.Decimal.Cmp(decimal.MustParse(tt.expectedTax)))
+			assert.Equal(t, 0, account.GetBalance().Decimal.Cmp(decimal.MustParse(tt.expectedBalance)))
+			assert.Equal(t, 0, account.InterestCarried.Decimal.Cmp(decimal.MustParse(tt.expectedCarried)),
+				account.InterestCarried.Decimal.String())
+
+			require.Len(t, unitOfWork.Entries, 1)
+			assert.Equal(t, ledger.InterestExpenseGL, unitOfWork.Entries[0].Postings[0].LedgerCode)
+
+			for _, accrual := range unitOfWork.Interest[:29] {
+				require.NotNil(t, accrual.CapitalisedAt)
+				assert.Equal(t, credit.TransactionID, *accrual.TransactionID)
+			}
+			assert.Nil(t, unitOfWork.Interest[29].CapitalisedAt)
+		})
+	}
+}
+
+func createInterestService() (*InterestService, *FakeUnitOfWork, *FakeInterestRepository, *MockAccountRepository) {
+	unitOfWork := &FakeUnitOfWork{Accounts: []*model.Account{getMockAccount()}}
+	repository := &FakeInterestRepository{
+		UnitOfWork: unitOfWork,
+		Products: []model.AccountProduct{{
+			AccountProductID: 1,
+			Code:             "SAVER",
+			Name:             "Easy saver",
+			AnnualRate:       amountOf("3.65"),
+			DayCount:         model.Actual365,
+		}},
+	}
+	mockAccountRepo := new(MockAccountRepository)
+	interestService := NewInterestService(repository, mockAccountRepo, unitOfWork, new(SequentialReferenceGenerator))
+	return interestService, unitOfWork, repository, mockAccountRepo
+}
+
+func addMockAccrual(unitOfWork *FakeUnitOfWork, accountID uint, day time.Time, amount string) {
+	_ = unitOfWork.SaveInterestAccrual(&model.InterestAccrual{
+		AccountID:   accountID,
+		AccrualDate: day,
+		DayCount:    model.Actual365,
+		Amount:      amountOf(amount),
+	})
+}
@@ -0,0 +1,227 @@
+package bankservice
+
+import (
+	"bankingApp/internal/api/constants"
+	"bankingApp/internal/ledger"
+	"bankingApp/internal/model"
+	"bankingApp/internal/repository"
+	"bankingApp/internal/utility"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// creditLegSuffix keeps the credit leg's payment reference unique while the debit leg carries the client's reference
+const creditLegSuffix = "-CR"
+
+// InternalTransfer handles the internal transfer endpoint for moving funds between two accounts held by the bank.
+// The debit and credit legs are posted to the ledger in one database transaction and share a correlation reference.
+// Each leg is in the currency of its account, converted from the currency of the transfer.
+func (b *BankTransferService) InternalTransfer(c *gin.Context) {
+	var t model.InternalTransferRequestDTO
+	if err := c.BindJSON(&t); err != nil {
+		utility.HandleError(c, err, http.StatusBadRequest, constants.InvalidJsonRequestErrorMsg)
+		return
+	}
+
+	if err := b.validateTransferRequest(c, t); err != nil {
+		return
+	}
+
+	source, destination, complete := b.processInternalTransferValidation(c, t)
+	if complete {
+		return
+	}
+
+	code := transferCurrency(t.Currency, source)
+	debited, credited, tErr := b.convertInternalTransfer(t.Amount, code, source, destination)
+	if tErr != nil {
+		utility.HandleError(c, tErr.err, tErr.statusCode, tErr.message)
+		return
+	}
+
+	debitReference, done := b.newReference(c)
+	if done {
+		return
+	}
+
+	creditReference, done := b.newReference(c)
+	if done {
+		return
+	}
+
+	now := time.Now()
+	debit := &model.Transaction{
+		AccountID:            source.AccountID,
+		Reference:            debitReference,
+		PaymentReference:     t.Reference,
+		CorrelationReference: t.Reference,
+		Amount:               debited.amount,
+		Currency:             source.Currency,
+		TransferAmount:       t.Amount,
+		TransferCurrency:     code,
+		ExchangeRate:         debited.rate,
+		Type:                 model.DebitTransaction,
+		Status:               model.SucceededStatus,
+		TransactionTime:      now,
+		TimestampData:        model.TimestampData{CreatedAt: now},
+	}
+	credit := &model.Transaction{
+		AccountID:            destination.AccountID,
+		Reference:            creditReference,
+		PaymentReference:     t.Reference + creditLegSuffix,
+		CorrelationReference: t.Reference,
+		Amount:               credited.amount,
+		Currency:             destination.Currency,
+		TransferAmount:       t.Amount,
+		TransferCurrency:     code,
+		ExchangeRate:         credited.rate,
+		Type:                 model.CreditTransaction,
+		Status:               model.SucceededStatus,
+		TransactionTime:      now,
+		TimestampData:        model.TimestampData{CreatedAt: now},
+	}
+
+	entry, err := ledger.InternalTransferEntry(t.Reference, debit, credit)
+	if err != nil {
+		utility.HandleError(c, err, http.StatusInternalServerError, constants.ApplicationError)
+		return
+	}
+
+	err = b.UnitOfWork.Execute(func(tx repository.ITx) error {
+		if err := b.checkLockedLimits(tx, source, debit.Amount); err != nil {
+			return err
+		}
+		if err := tx.PostJournalEntry(entry); err != nil {
+			return err
+		}
+		if err := tx.SaveTransaction(debit); err != nil {
+			return err
+		}
+		return tx.SaveTransaction(credit)
+	})
+	if errors.Is(err, model.ErrInsufficientFunds) {
+		utility.HandleError(c, nil, http.StatusOK, constants.InsufficientFunds)
+		return
+	}
+	if limitErr := limitError(err); limitErr != nil {
+		utility.HandleError(c, nil, http.StatusOK, limitErr.Error())
+		return
+	}
+	if err != nil {
+		utility.HandleError(c, err, http.StatusInternalServerError, constants.ApplicationError)
+		return
+	}
+
+	amount := t.Amount
+	response := model.InternalTransferResponseDTO{
+		SourceAccountNumber:      t.SourceAccountNumber,
+		DestinationAccountNumber: t.DestinationAccountNumber,
+		Amount:                   &amount,
+		Currency:                 code,
+		DebitedAmount:            &debit.Amount,
+		DebitedCurrency:          debit.Currency,
+		CreditedAmount:           &credit.Amount,
+		CreditedCurrency:         credit.Currency,
+		PaymentReference:         t.Reference,
+		DebitReference:           debit.Reference,
+		CreditReference:          credit.Reference,
+	}
+	c.JSON(http.StatusOK, utility.FormulateDataResponse(constants.SuccessfulTransactionMsg, response))
+}
+
+// processInternalTransferValidation checks the reference, both accounts, the PIN of the source account owner,
+// that the statuses of the accounts allow the source to be debited and the destination credited
+// and the currency of the transfer.
+func (b *BankTransferService) processInternalTransferValidation(
+	c *gin.Context,
+	t model.InternalTransferRequestDTO) (*model.Account, *model.Account, bool) {
+	if t.SourceAccountNumber == t.DestinationAccountNumber {
+		utility.HandleError(c, nil, http.StatusOK, constants.SameAccountTransfer)
+		return nil, nil, true
+	}
+
+	transaction, err := b.TransactionRepository.FindTransactionByReference(t.Reference)
+	if err != nil {
+		utility.HandleError(c, err, http.StatusInternalServerError, constants.ApplicationError)
+		return nil, nil, true
+	}
+
+	if transaction.TransactionID != constants.Zero {
+		utility.HandleError(c, nil, http.StatusOK, constants.NotUniqueReferenceMsg)
+		return nil, nil, true
+	}
+
+	user, source, err := b.UserRepository.GetUserAndAccountByAccountNumber(t.SourceAccountNumber)
+	if err != nil {
+		utility.HandleError(c, err, http.StatusInternalServerError, constants.ApplicationError)
+		return nil, nil, true
+	}
+
+	if user.UserID == constants.Zero || source.AccountID == constants.Zero {
+		utility.HandleError(c, nil, http.StatusOK, constants.UserOrAccountNotFound)
+		return nil, nil, true
+	}
+
+	destination, err := b.AccountRepository.GetAccountByAccountNumber(t.DestinationAccountNumber)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		utility.HandleError(c, err, http.StatusInternalServerError, constants.ApplicationError)
+		return nil, nil, true
+	}
+
+	if err != nil || destination.AccountID == constants.Zero {
+		utility.HandleError(c, nil, http.StatusOK, constants.DestinationAccountNotFound)
+		return nil, nil, true
+	}
+
+	if tErr := verifyTransactionPin(b.UserRepository, user, t.TransactionPin); tErr != nil {
+		utility.HandleError(c, tErr.err, tErr.statusCode, tErr.message)
+		return nil, nil, true
+	}
+
+	if tErr := checkAccountStatus(source, model.DebitTransaction); tErr != nil {
+		utility.HandleError(c, tErr.err, tErr.statusCode, tErr.message)
+		return nil, nil, true
+	}
+
+	if tErr := checkAccountStatus(destination, model.CreditTransaction); tErr != nil {
+		utility.HandleError(c, tErr.err, tErr.statusCode, tErr.message)
+		return nil, nil, true
+	}
+
+	if tErr := checkTransferCurrency(transferCurrency(t.Currency, source), t.Amount); tErr != nil {
+		utility.HandleError(c, tErr.err, tErr.statusCode, tErr.message)
+		return nil, nil, true
+	}
+
+	return source, destination, false
+}
+
+// convertInternalTransfer converts the amount of an internal transfer to the currencies of both accounts
+// and checks that the amount the source account is debited is within its limits and that the account can cover it
+func (b *BankTransferService) convertInternalTransfer(
+	amount model.BigDecimal,
+	code string,
+	source, destination *model.Account) (conversion, conversion, *transferError) {
+	debited, tErr := b.convert(amount, code, source.Currency)
+	if tErr != nil {
+		return conversion{}, conversion{}, tErr
+	}
+
+	credited, tErr := b.convert(amount, code, destination.Currency)
+	if tErr != nil {
+		return conversion{}, conversion{}, tErr
+	}
+
+	if tErr := b.checkLimits(source, debited.amount); tErr != nil {
+		return conversion{}, conversion{}, tErr
+	}
+
+	if source.IsInsufficientBalance(debited.amount) {
+		return conversion{}, conversion{}, newTransferError(nil, http.StatusOK, constants.InsufficientFunds)
+	}
+	return debited, credited, nil
+}
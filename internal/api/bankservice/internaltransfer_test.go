@@ -0,0 +1,212 @@
+package bankservice //nolint:typecheck
+
+import (
+	"bankingApp/internal/api/constants"
+	"bankingApp/internal/ledger"
+	"bankingApp/internal/model"
+	"bankingApp/internal/utility"
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/govalues/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+func Test_InternalTransfer(t *testing.T) {
+	val, _ := decimal.NewFromFloat64(100.00)
+	amount := model.BigDecimal{Decimal: val}
+	val, _ = decimal.NewFromFloat64(1_000_000_000.00)
+	amountInsufficientFunds := model.BigDecimal{Decimal: val}
+	testCases := []struct {
+		name             string
+		requestBody      []byte
+		mockUser         *model.User
+		mockSource       *model.Account
+		mockDestination  *model.Account
+		destinationError error
+		ledgerError      error
+		expectedStatus   int
+		expectedSuccess  bool
+		expectedMessage  string
+		expectPosting    bool
+	}{
+		{
+			name:            "successful internal transfer",
+			requestBody:     getInternalTransferRequest("1234567890", "0987654321", "1234", amount),
+			mockUser:        getMockUser(),
+			mockSource:      getMockAccount(),
+			mockDestination: getMockDestinationAccount(),
+			expectedStatus:  http.StatusOK,
+			expectedSuccess: true,
+			expectedMessage: constants.SuccessfulTransactionMsg,
+			expectPosting:   true,
+		},
+		{
+			name:            "same source and destination account",
+			requestBody:     getInternalTransferRequest("1234567890", "1234567890", "1234", amount),
+			expectedStatus:  http.StatusOK,
+			expectedMessage: constants.SameAccountTransfer,
+		},
+		{
+			name:             "destination account not found",
+			requestBody:      getInternalTransferRequest("1234567890", "0987654321", "1234", amount),
+			mockUser:         getMockUser(),
+			mockSource:       getMockAccount(),
+			mockDestination:  &model.Account{},
+			destinationError: gorm.ErrRecordNotFound,
+			expectedStatus:   http.StatusOK,
+			expectedMessage:  constants.DestinationAccountNotFound,
+		},
+		{
+			name:            "incorrect PIN",
+			requestBody:     getInternalTransferRequest("1234567890", "0987654321", "4321", amount),
+			mockUser:        getMockUser(),
+			mockSource:      getMockAccount(),
+			mockDestination: getMockDestinationAccount(),
+			expectedStatus:  http.StatusOK,
+			expectedMessage: constants.IncorrectTransactionPin,
+		},
+		{
+			name:            "frozen source account",
+			requestBody:     getInternalTransferRequest("1234567890", "0987654321", "1234", amount),
+			mockUser:        getMockUser(),
+			mockSource:      accountWithStatus(getMockAccount(), model.FrozenAccount),
+			mockDestination: getMockDestinationAccount(),
+			expectedStatus:  http.StatusOK,
+			expectedMessage: constants.AccountDebitsNotAllowed,
+		},
+		{
+			name:            "closed destination account",
+			requestBody:     getInternalTransferRequest("1234567890", "0987654321", "1234", amount),
+			mockUser:        getMockUser(),
+			mockSource:      getMockAccount(),
+			mockDestination: accountWithStatus(getMockDestinationAccount(), model.ClosedAccount),
+			expectedStatus:  http.StatusOK,
+			expectedMessage: constants.AccountCreditsNotAllowed,
+		},
+		{
+			name:            "insufficient funds before transfer",
+			requestBody:     getInternalTransferRequest("1234567890", "0987654321", "1234", amountInsufficientFunds),
+			mockUser:        getMockUser(),
+			mockSource:      getMockAccount(),
+			mockDestination: getMockDestinationAccount(),
+			expectedStatus:  http.StatusOK,
+			expectedMessage: constants.InsufficientFunds,
+		},
+		{
+			name:            "insufficient funds inside database transaction",
+			requestBody:     getInternalTransferRequest("1234567890", "0987654321", "1234", amount),
+			mockUser:        getMockUser(),
+			mockSource:      getMockAccount(),
+			mockDestination: getMockDestinationAccount(),
+			ledgerError:     model.ErrInsufficientFunds,
+			expectedStatus:  http.StatusOK,
+			expectedMessage: constants.InsufficientFunds,
+			expectPosting:   true,
+		},
+		{
+			name:            "bad request missing destination",
+			requestBody:     getInternalTransferRequest("1234567890", "", "1234", amount),
+			expectedStatus:  http.StatusBadRequest,
+			expectedMessage: constants.BadRequestMessage,
+		},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			// ------------ setups ------------
+			mockConfig, mockTransactionRepo, mockUserRepo, mockAccountRepo, mockRestClient, _ := setupMocks()
+			bankService := createBankService(mockConfig, mockTransactionRepo, mockUserRepo, mockAccountRepo, mockRestClient)
+			unitOfWork := &FakeUnitOfWork{
+				Accounts:     []*model.Account{tt.mockSource, tt.mockDestination},
+				PostingError: tt.ledgerError,
+			}
+			bankService.UnitOfWork = unitOfWork
+
+			gin.SetMode(gin.TestMode)
+
+			// ------------ expectations ------------
+			mockTransactionRepo.
+				On("FindTransactionByReference", mock.Anything).Return(getMockNotFoundTransaction(), nil)
+
+			mockUserRepo.
+				On("GetUserAndAccountByAccountNumber", mock.Anything).Return(tt.mockUser, tt.mockSource, nil)
+			mockUserRepo.On("RecordFailedPinAttempt", uint(1), mock.Anything).Return(nil)
+
+			mockAccountRepo.
+				On("GetAccountByAccountNumber", mock.Anything).Return(tt.mockDestination, tt.destinationError)
+
+			// ------------ executions -----------
+			req, err := http.NewRequest("POST", "/api/v1/bank/internal-transfer", bytes.NewBuffer(tt.requestBody))
+			if err != nil {
+				t.Fatalf("Error creating request context: %v", err)
+			}
+
+			recorder := httptest.NewRecorder()
+			context, _ := gin.CreateTestContext(recorder)
+			context.Request = req
+
+			bankService.InternalTransfer(context)
+
+			var returnedResponse utility.APIDataResponse
+			if jsonErr := json.Unmarshal(recorder.Body.Bytes(), &returnedResponse); jsonErr != nil {
+				t.Fatalf("Error creating test context: %v", jsonErr)
+			}
+
+			// ------------ assertions -----------
+			assert.Equal(t, tt.expectedStatus, recorder.Code)
+			assert.Equal(t, tt.expectedSuccess, returnedResponse.Success)
+			assert.Equal(t, tt.expectedMessage, returnedResponse.Message)
+
+			if !tt.expectPosting || tt.ledgerError != nil {
+				assert.Empty(t, unitOfWork.Entries)
+				assert.Empty(t, unitOfWork.Transactions)
+				return
+			}
+
+			entry := unitOfWork.Entries[0]
+			debit, credit := unitOfWork.Transactions[0], unitOfWork.Transactions[1]
+			assert.NoError(t, ledger.Validate(entry))
+			assert.Equal(t, uint(1), entry.Postings[0].AccountID)
+			assert.Equal(t, model.DebitEntry, entry.Postings[0].Direction)
+			assert.Equal(t, uint(2), entry.Postings[1].AccountID)
+			assert.Equal(t, model.CreditEntry, entry.Postings[1].Direction)
+			assert.Equal(t, model.DebitTransaction, debit.Type)
+			assert.Equal(t, model.CreditTransaction, credit.Type)
+			assert.Equal(t, debit.CorrelationReference, credit.CorrelationReference)
+			assert.NotEqual(t, debit.PaymentReference, credit.PaymentReference)
+			assert.NotEqual(t, debit.Reference, credit.Reference)
+			assert.Equal(t, model.SucceededStatus, debit.Status)
+			assert.Equal(t, model.SucceededStatus, credit.Status)
+		})
+	}
+}
+
+func getMockDestinationAccount() *model.Account {
+	balance, _ := decimal.NewFromFloat64(500)
+	return &model.Account{
+		AccountID:     2,
+		AccountNumber: "0987654321",
+		UserID:        2,
+		Balance:       model.BigDecimal{Decimal: balance},
+	}
+}
+
+func getInternalTransferRequest(source, destination, pin string, amount model.BigDecimal) []byte {
+	requestBody, _ := json.Marshal(model.InternalTransferRequestDTO{
+		InternalTransferDataDTO: model.InternalTransferDataDTO{
+			SourceAccountNumber:      source,
+			DestinationAccountNumber: destination,
+			Username:                 "johndoe",
+			TransactionPin:           pin,
+			Reference:                "289192938929293",
+			Amount:                   amount,
+		},
+	})
+	return requestBody
+}
@@ -0,0 +1,240 @@
+package bankservice
+
+import (
+	"bankingApp/internal/api/constants"
+	"bankingApp/internal/ledger"
+	"bankingApp/internal/model"
+	"bankingApp/internal/repository"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const reversalSuffix = "-REV"
+
+const (
+	// staleTransactionAge is how long a transaction may wait for its outcome before it is resolved by ResolveStale
+	staleTransactionAge       = 10 * time.Minute
+	staleTransactionBatchSize = 50
+)
+
+// newPendingTransaction creates the transaction of a transfer request before it is sent to the third-party provider.
+// It records the requested amount and currency together with the amount in the account currency, the rate and the fee.
+func newPendingTransaction(
+	t model.TransactionRequestDTO,
+	account *model.Account,
+	converted conversion,
+	charge model.BigDecimal,
+	reference string) *model.Transaction {
+	return &model.Transaction{
+		AccountID:        account.AccountID,
+		Amount:           converted.amount,
+		Currency:         account.Currency,
+		TransferAmount:   t.Amount,
+		TransferCurrency: transferCurrency(t.Currency, account),
+		ExchangeRate:     converted.rate,
+		Fee:              charge,
+		Type:             t.Type,
+		Status:           model.PendingStatus,
+		Reference:        reference,
+		PaymentReference: t.Reference,
+		TransactionTime:  time.Now(),
+		TimestampData: model.TimestampData{
+			CreatedAt: time.Now(),
+		},
+	}
+}
+
+// createPendingTransaction saves the transaction as pending before the third-party provider is called.
+// Debits have the limits of the account checked again and reserve the funds in the same commit, so concurrent
+// debits cannot spend the same balance or exceed the limits together.
+func (b *BankTransferService) createPendingTransaction(
+	transaction *model.Transaction,
+	account *model.Account) *transferError {
+	err := b.UnitOfWork.Execute(func(tx repository.ITx) error {
+		if transaction.Type == model.DebitTransaction {
+			if err := b.checkLockedLimits(tx, account, transaction.Amount); err != nil {
+				return err
+			}
+		}
+		return savePendingTransaction(tx, transaction)
+	})
+
+	if errors.Is(err, model.ErrInsufficientFunds) {
+		return newTransferError(nil, http.StatusOK, constants.InsufficientFunds)
+	}
+	if limitErr := limitError(err); limitErr != nil {
+		return newTransferError(nil, http.StatusOK, limitErr.Error())
+	}
+	if err != nil {
+		slog.Error("error in saving pending transaction", "error", err)
+		return newTransferError(nil, http.StatusInternalServerError, constants.ApplicationError)
+	}
+	return nil
+}
+
+// savePendingTransaction reserves the funds of a pending debit and saves the transaction
+func savePendingTransaction(tx repository.ITx, transaction *model.Transaction) error {
+	if transaction.Type == model.DebitTransaction {
+		entry, err := ledger.TransactionEntry(transaction)
+		if err != nil {
+			return err
+		}
+		if err := tx.PostJournalEntry(entry); err != nil {
+			return err
+		}
+	}
+	return tx.SaveTransaction(transaction)
+}
+
+// submitTransaction marks the transaction as submitted and posts it to the given third-party provider URL.
+// It returns the provider response together with the status and reason the outcome maps to.
+func (b *BankTransferService) submitTransaction(
+	transaction *model.Transaction,
+	url string) (map[string]interface{}, model.TransactionStatus, string, error) {
+	err := b.UnitOfWork.Execute(func(tx repository.ITx) error {
+		return tx.UpdateTransactionStatus(transaction, model.SubmittedStatus, "sent to third-party provider")
+	})
+	if err != nil {
+		return nil, "", "", err
+	}
+
+	amount, code := transaction.SettlementAmount()
+	request := &model.ThirdPartyTransactionDataDTO{
+		AccountID: strconv.Itoa(int(transaction.AccountID)),
+		Amount:    &amount,
+		Currency:  code,
+		Reference: transaction.Reference,
+	}
+
+	response, statusCode, err := b.RestHttpClient.PostRequest(url, request, headers)
+	if err != nil {
+		slog.Error("third-party payment request failed", "reference", transaction.Reference, "error", err)
+	}
+
+	status := outcomeStatus(statusCode, err)
+	reason := fmt.Sprintf("third-party provider responded with HTTP %d", statusCode)
+	if statusCode == constants.Zero {
+		reason = "no response from third-party provider"
+	}
+	return response, status, reason, nil
+}
+
+// outcomeStatus maps the third-party response to a transaction status. Rejections are final failures,
+// while timeouts and server errors leave the outcome unknown until the transaction is queried.
+func outcomeStatus(statusCode int, err error) model.TransactionStatus {
+	switch {
+	case statusCode == http.StatusOK && err == nil:
+		return model.SucceededStatus
+	case statusCode >= http.StatusBadRequest && statusCode < http.StatusInternalServerError:
+		return model.FailedStatus
+	default:
+		return model.UnknownStatus
+	}
+}
+
+// completeTransaction moves the transaction to its outcome status and settles it in the same commit:
+// a succeeded credit is paid into the account and a failed debit gives the reserved funds back.
+// When the transaction is a reversal, the transaction it reverses is updated as well.
+func (b *BankTransferService) completeTransaction(
+	transaction *model.Transaction,
+	status model.TransactionStatus,
+	reason string) error {
+	return b.UnitOfWork.Execute(func(tx repository.ITx) error {
+		entry, err := settlementEntry(transaction, status)
+		if err != nil {
+			return err
+		}
+		if entry != nil {
+			if err := tx.PostJournalEntry(entry); err != nil {
+				return err
+			}
+		}
+		if err := tx.UpdateTransactionStatus(transaction, status, reason); err != nil {
+			return err
+		}
+		if transaction.OriginalTransactionID != nil {
+			return settleReversedTransaction(tx, transaction, status)
+		}
+		return nil
+	})
+}
+
+// settlementEntry returns the journal entry posted when the transaction reaches the given status, if any
+func settlementEntry(transaction *model.Transaction, status model.TransactionStatus) (*model.JournalEntry, error) {
+	switch {
+	case status == model.SucceededStatus && transaction.Type == model.CreditTransaction:
+		return ledger.TransactionEntry(transaction)
+	case status == model.FailedStatus && transaction.Type == model.DebitTransaction:
+		original, err := ledger.TransactionEntry(transaction)
+		if err != nil {
+			return nil, err
+		}
+		return ledger.ReversalEntry(transaction.Reference+reversalSuffix, original)
+	default:
+		return nil, nil
+	}
+}
+
+// ResolveStale resolves the transactions that have been waiting for their outcome since before now less
+// staleTransactionAge. Pending transactions never reached the third-party provider and are failed, giving reserved
+// funds back; submitted and unknown ones are settled with the outcome the provider reports for them.
+func (b *BankTransferService) ResolveStale(ctx context.Context, now time.Time) error {
+	stale, err := b.TransactionRepository.FindStaleTransactions(now.Add(-staleTransactionAge), staleTransactionBatchSize)
+	if err != nil {
+		return err
+	}
+
+	for i := range stale {
+		transaction := &stale[i]
+		err := b.resolveStaleTransaction(transaction)
+		if err != nil && !errors.Is(err, repository.ErrStaleTransactionStatus) {
+			slog.Error("unable to resolve stale transaction", "reference", transaction.Reference, "error", err)
+		}
+	}
+	return nil
+}
+
+// resolveStaleTransaction settles a transaction still waiting for its outcome. Transactions the provider cannot
+// be asked about, or does not answer for, are left for the next run.
+func (b *BankTransferService) resolveStaleTransaction(transaction *model.Transaction) error {
+	if !b.isAwaitingOutcome(transaction) {
+		return b.completeTransaction(transaction, model.FailedStatus, "never sent to third-party provider")
+	}
+
+	url := fmt.Sprintf("%s/api/v1/third-party/payments/%s/get", b.Config.ThirdPartyBaseUrl(), transaction.Reference)
+	_, statusCode, err := b.RestHttpClient.GetRequest(url, headers)
+	switch {
+	case err != nil:
+		return err
+	case statusCode == http.StatusOK:
+		return b.completeTransaction(transaction, model.SucceededStatus, "confirmed by third-party status query")
+	case statusCode == http.StatusNotFound:
+		return b.completeTransaction(transaction, model.FailedStatus, "unknown to third-party provider")
+	default:
+		return nil
+	}
+}
+
+// isAwaitingOutcome reports whether the transaction was sent to the third-party provider without a final outcome
+func (b *BankTransferService) isAwaitingOutcome(transaction *model.Transaction) bool {
+	return transaction.Status == model.SubmittedStatus || transaction.Status == model.UnknownStatus
+}
+
+// statusHistoryDTOs converts the status history of a transaction to its response representation
+func statusHistoryDTOs(history []model.TransactionStatusHistory) []model.StatusHistoryDTO {
+	dtos := make([]model.StatusHistoryDTO, 0, len(history))
+	for _, h := range history {
+		dtos = append(dtos, model.StatusHistoryDTO{
+			FromStatus: h.FromStatus,
+			ToStatus:   h.ToStatus,
+			Reason:     h.Reason,
+			ChangedAt:  h.ChangedAt,
+		})
+	}
+	return dtos
+}
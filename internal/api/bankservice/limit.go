@@ -0,0 +1,268 @@
+package bankservice
+
+import (
+	"bankingApp/internal/api/constants"
+	"bankingApp/internal/model"
+	"bankingApp/internal/repository"
+	"bankingApp/internal/utility"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/govalues/decimal"
+	"gorm.io/gorm"
+)
+
+type ILimitRepository interface {
+	FindTierLimit(tier string) (*model.TransactionLimit, error)
+	FindAccountLimit(accountID uint) (*model.TransactionLimit, error)
+	SaveLimit(limit *model.TransactionLimit) error
+	FindLimitUsage(accountID uint, day, month time.Time) (model.LimitUsage, error)
+}
+
+const maxTierLength = 20
+
+// limitErrors are the limits a debit can exceed, reported to the client as business errors
+var limitErrors = []error{
+	model.ErrTransactionLimitExceeded,
+	model.ErrDailyLimitExceeded,
+	model.ErrMonthlyLimitExceeded,
+	model.ErrDailyCountLimitExceeded,
+	model.ErrMonthlyCountLimitExceeded,
+}
+
+// LimitService maintains the transaction limits of account tiers and accounts and reports the headroom left under them
+type LimitService struct {
+	Repository        ILimitRepository
+	AccountRepository IAccountRepository
+}
+
+// NewLimitService creates a new instance of LimitService
+func NewLimitService(repository ILimitRepository, accountRepository IAccountRepository) *LimitService {
+	return &LimitService{Repository: repository, AccountRepository: accountRepository}
+}
+
+// SetTierLimit handles the admin endpoint setting the limits of every account in a tier
+func (l *LimitService) SetTierLimit(c *gin.Context) {
+	tier := c.Param("tier")
+	if tier == "" || len(tier) > maxTierLength {
+		utility.HandleError(c, nil, http.StatusBadRequest, constants.InvalidAccountTier)
+		return
+	}
+
+	limit, ok := bindLimit(c)
+	if !ok {
+		return
+	}
+
+	limit.Tier = tier
+	l.saveLimit(c, limit, model.TransactionLimitDTO{Tier: tier})
+}
+
+// SetAccountLimit handles the admin endpoint setting the limits of a single account, which override those of its tier
+func (l *LimitService) SetAccountLimit(c *gin.Context) {
+	account, ok := findAccount(c, l.AccountRepository)
+	if !ok {
+		return
+	}
+
+	limit, ok := bindLimit(c)
+	if !ok {
+		return
+	}
+
+	limit.AccountID = account.AccountID
+	l.saveLimit(c, limit, model.TransactionLimitDTO{AccountNumber: account.AccountNumber})
+}
+
+// Headroom handles the endpoint reporting how much an account can still debit today and this month
+func (l *LimitService) Headroom(c *gin.Context) {
+	account, ok := findReadableAccount(c, l.AccountRepository)
+	if !ok {
+		return
+	}
+
+	limit, err := effectiveLimit(l.Repository, account)
+	if err != nil {
+		utility.HandleError(c, err, http.StatusInternalServerError, constants.ApplicationError)
+		return
+	}
+
+	usage, err := limitUsage(l.Repository, account.AccountID, time.Now())
+	if err != nil {
+		utility.HandleError(c, err, http.StatusInternalServerError, constants.ApplicationError)
+		return
+	}
+
+	response := model.LimitHeadroomDTO{
+		AccountNumber: account.AccountNumber,
+		Tier:          model.TierOf(account),
+		Currency:      account.Currency,
+		MaxAmount:     setAmount(limit.MaxAmount),
+		Daily:         headroomPeriod(limit.DailyAmount, usage.DailyAmount, limit.DailyCount, usage.DailyCount),
+		Monthly:       headroomPeriod(limit.MonthlyAmount, usage.MonthlyAmount, limit.MonthlyCount, usage.MonthlyCount),
+	}
+	c.JSON(http.StatusOK, utility.FormulateDataResponse(constants.LimitHeadroomFoundMsg, response))
+}
+
+// findAccount finds the account in the number path parameter
+func findAccount(c *gin.Context, repository IAccountRepository) (*model.Account, bool) {
+	account, err := repository.GetAccountByAccountNumber(c.Param("number"))
+	if errors.Is(err, gorm.ErrRecordNotFound) || (err == nil && account.AccountID == constants.Zero) {
+		utility.HandleError(c, nil, http.StatusOK, constants.AccountNotFound)
+		return nil, false
+	}
+	if err != nil {
+		utility.HandleError(c, err, http.StatusInternalServerError, constants.ApplicationError)
+		return nil, false
+	}
+	return account, true
+}
+
+// bindLimit reads the limits from the request body, rejecting negative limits
+func bindLimit(c *gin.Context) (*model.TransactionLimit, bool) {
+	var r model.TransactionLimitDTO
+	if err := c.BindJSON(&r); err != nil {
+		utility.HandleError(c, err, http.StatusBadRequest, constants.InvalidJsonRequestErrorMsg)
+		return nil, false
+	}
+
+	if errorMap, vErr := utility.ValidateRequest(r); len(errorMap) != constants.Zero || vErr != nil {
+		if vErr != nil {
+			utility.HandleError(c, vErr, http.StatusInternalServerError, constants.ApplicationError)
+			return nil, false
+		}
+		utility.HandleValidationErrors(c, errorMap)
+		return nil, false
+	}
+
+	limit := &model.TransactionLimit{DailyCount: r.DailyCount, MonthlyCount: r.MonthlyCount}
+	for value, amount := range map[*model.BigDecimal]*model.BigDecimal{
+		&limit.MaxAmount:     r.MaxAmount,
+		&limit.DailyAmount:   r.DailyAmount,
+		&limit.MonthlyAmount: r.MonthlyAmount,
+	} {
+		if amount == nil {
+			continue
+		}
+		if amount.Decimal.IsNeg() {
+			utility.HandleError(c, nil, http.StatusOK, constants.InvalidTransactionLimit)
+			return nil, false
+		}
+		*value = *amount
+	}
+	return limit, true
+}
+
+// saveLimit stores the limits and responds with them
+func (l *LimitService) saveLimit(c *gin.Context, limit *model.TransactionLimit, response model.TransactionLimitDTO) {
+	limit.UpdatedAt = time.Now()
+	if err := l.Repository.SaveLimit(limit); err != nil {
+		utility.HandleError(c, err, http.StatusInternalServerError, constants.ApplicationError)
+		return
+	}
+
+	response.MaxAmount = setAmount(limit.MaxAmount)
+	response.DailyAmount = setAmount(limit.DailyAmount)
+	response.MonthlyAmount = setAmount(limit.MonthlyAmount)
+	response.DailyCount = limit.DailyCount
+	response.MonthlyCount = limit.MonthlyCount
+	c.JSON(http.StatusOK, utility.FormulateDataResponse(constants.TransactionLimitsSavedMsg, response))
+}
+
+// checkLimits checks that debiting the amount keeps the account within the limits of its tier and its own limits
+func (b *BankTransferService) checkLimits(account *model.Account, amount model.BigDecimal) *transferError {
+	limit, err := effectiveLimit(b.LimitRepository, account)
+	if err != nil {
+		return newTransferError(err, http.StatusInternalServerError, constants.ApplicationError)
+	}
+
+	usage, err := limitUsage(b.LimitRepository, account.AccountID, time.Now())
+	if err != nil {
+		return newTransferError(err, http.StatusInternalServerError, constants.ApplicationError)
+	}
+
+	if err := limit.Check(usage, amount); err != nil {
+		return newTransferError(nil, http.StatusOK, err.Error())
+	}
+	return nil
+}
+
+// checkLockedLimits checks the limits of the account again within the unit of work debiting it, once the account
+// is locked. Concurrent debits are serialised by the lock, so debits that each passed checkLimits on their own
+// cannot exceed the limits together. It returns the limit error of the model when a limit is exceeded.
+func (b *BankTransferService) checkLockedLimits(tx repository.ITx, account *model.Account, amount model.BigDecimal) error {
+	limit, err := effectiveLimit(b.LimitRepository, account)
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.LockAccount(account.AccountID); err != nil {
+		return err
+	}
+
+	day, month := model.LimitPeriods(time.Now())
+	usage, err := tx.FindLimitUsage(account.AccountID, day, month)
+	if err != nil {
+		return err
+	}
+	return limit.Check(usage, amount)
+}
+
+// limitError returns the limit error the error is, or nil when it is not one
+func limitError(err error) error {
+	for _, limitErr := range limitErrors {
+		if errors.Is(err, limitErr) {
+			return limitErr
+		}
+	}
+	return nil
+}
+
+// effectiveLimit returns the limits of the tier of the account overridden by the limits set on the account
+func effectiveLimit(repository ILimitRepository, account *model.Account) (model.TransactionLimit, error) {
+	tierLimit, err := repository.FindTierLimit(model.TierOf(account))
+	if err != nil {
+		return model.TransactionLimit{}, err
+	}
+
+	accountLimit, err := repository.FindAccountLimit(account.AccountID)
+	if err != nil {
+		return model.TransactionLimit{}, err
+	}
+	return tierLimit.Override(*accountLimit), nil
+}
+
+// limitUsage returns what the account has debited in the day and the month of the given time
+func limitUsage(repository ILimitRepository, accountID uint, now time.Time) (model.LimitUsage, error) {
+	day, month := model.LimitPeriods(now)
+	return repository.FindLimitUsage(accountID, day, month)
+}
+
+// headroomPeriod reports the limits of a period with what is used and what remains of those that are set
+func headroomPeriod(amountLimit, amountUsed model.BigDecimal, countLimit, countUsed int) model.LimitPeriodDTO {
+	period := model.LimitPeriodDTO{AmountUsed: amountUsed, CountUsed: countUsed}
+	if amountLimit.Decimal.IsPos() {
+		remaining, err := amountLimit.Decimal.Sub(amountUsed.Decimal)
+		if err != nil || remaining.IsNeg() {
+			remaining = decimal.Zero
+		}
+		period.AmountLimit = &amountLimit
+		period.AmountRemaining = &model.BigDecimal{Decimal: remaining}
+	}
+	if countLimit > 0 {
+		remaining := max(countLimit-countUsed, 0)
+		period.CountLimit = &countLimit
+		period.CountRemaining = &remaining
+	}
+	return period
+}
+
+// setAmount returns the amount of a limit, or nil when the limit is not set
+func setAmount(amount model.BigDecimal) *model.BigDecimal {
+	if !amount.Decimal.IsPos() {
+		return nil
+	}
+	return &amount
+}
@@ -0,0 +1,465 @@
+package bankservice
+
+import (
+	"bankingApp/internal/api/constants"
+	"bankingApp/internal/model"
+	"bankingApp/internal/utility"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/govalues/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+// FakeLimitRepository keeps limits in memory and reports the same usage for every account
+type FakeLimitRepository struct {
+	TierLimits    map[string]*model.TransactionLimit
+	AccountLimits map[uint]*model.TransactionLimit
+	Usage         model.LimitUsage
+	Saved         []*model.TransactionLimit
+}
+
+func (f *FakeLimitRepository) FindTierLimit(tier string) (*model.TransactionLimit, error) {
+	if limit, ok := f.TierLimits[tier]; ok {
+		return limit, nil
+	}
+	return &model.TransactionLimit{}, nil
+}
+
+func (f *FakeLimitRepository) FindAccountLimit(accountID uint) (*model.TransactionLimit, error) {
+	if limit, ok := f.AccountLimits[accountID]; ok {
+		return limit, nil
+	}
+	return &model.TransactionLimit{}, nil
+}
+
+func (f *FakeLimitRepository) SaveLimit(limit *model.TransactionLimit) error {
+	f.Saved = append(f.Saved, limit)
+	return nil
+}
+
+func (f *FakeLimitRepository) FindLimitUsage(uint, time.Time, time.Time) (model.LimitUsage, error) {
+	return f.Usage, nil
+}
+
+func Test_TransferLimits(t *testing.T) {
+	testCases := []struct {
+		name            string
+		transactionType model.TransactionType
+		amount          string
+		tierLimit       *model.TransactionLimit
+		accountLimit    *model.TransactionLimit
+		usage           model.LimitUsage
+		lockedUsage     model.LimitUsage
+		expectedMessage string
+	}{
+		{
+			name:            "debit within every limit",
+			transactionType: model.DebitTransaction,
+			amount:          "100",
+			tierLimit:       getMockLimit("500", "1000", "5000", 5, 20),
+			usage:           getMockLimitUsage("400", 1, "400", 1),
+			expectedMessage: constants.SuccessfulTransactionMsg,
+		},
+		{
+			name:            "debit above the single transaction limit",
+			transactionType: model.DebitTransaction,
+			amount:          "600",
+			tierLimit:       getMockLimit("500", "", "", 0, 0),
+			expectedMessage: constants.TransactionLimitExceeded,
+		},
+		{
+			name:            "debit going over the daily limit",
+			transactionType: model.DebitTransaction,
+			amount:          "300",
+			tierLimit:       getMockLimit("", "1000", "", 0, 0),
+			usage:           getMockLimitUsage("800", 2, "800", 2),
+			expectedMessage: constants.DailyLimitExceeded,
+		},
+		{
+			name:            "debits committed while the debit was validated go over the daily limit",
+			transactionType: model.DebitTransaction,
+			amount:          "300",
+			tierLimit:       getMockLimit("", "1000", "", 0, 0),
+			usage:           getMockLimitUsage("400", 1, "400", 1),
+			lockedUsage:     getMockLimitUsage("800", 2, "800", 2),
+			expectedMessage: constants.DailyLimitExceeded,
+		},
+		{
+			name:            "debit going over the monthly limit",
+			transactionType: model.DebitTransaction,
+			amount:          "300",
+			tierLimit:       getMockLimit("", "1000", "2000", 0, 0),
+			usage:           getMockLimitUsage("0", 0, "1800", 2),
+			expectedMessage: constants.MonthlyLimitExceeded,
+		},
+		{
+			name:            "daily number of debits reached",
+			transactionType: model.DebitTransaction,
+			amount:          "10",
+			tierLimit:       getMockLimit("", "", "", 2, 0),
+			usage:           getMockLimitUsage("20", 2, "20", 2),
+			expectedMessage: constants.DailyCountLimitExceeded,
+		},
+		{
+			name:            "monthly number of debits reached",
+			transactionType: model.DebitTransaction,
+			amount:          "10",
+			tierLimit:       getMockLimit("", "", "", 5, 3),
+			usage:           getMockLimitUsage("0", 0, "30", 3),
+			expectedMessage: constants.MonthlyCountLimitExceeded,
+		},
+		{
+			name:            "account limit overrides the limit of its tier",
+			transactionType: model.DebitTransaction,
+			amount:          "800",
+			tierLimit:       getMockLimit("500", "1000", "", 0, 0),
+			accountLimit:    getMockLimit("1000", "", "", 0, 0),
+			expectedMessage: constants.SuccessfulTransactionMsg,
+		},
+		{
+			name:            "credits are not limited",
+			transactionType: model.CreditTransaction,
+			amount:          "600",
+			tierLimit:       getMockLimit("500", "", "", 0, 0),
+			expectedMessage: constants.SuccessfulTransactionMsg,
+		},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			// ------------ setups ------------
+			mockConfig, mockTransactionRepo, mockUserRepo, mockAccountRepo, mockRestClient, _ := setupMocks()
+			bankService := createBankService(mockConfig, mockTransactionRepo, mockUserRepo, mockAccountRepo, mockRestClient)
+			account := getMockAccount()
+			unitOfWork := &FakeUnitOfWork{Accounts: []*model.Account{account}, Usage: tt.lockedUsage}
+			bankService.UnitOfWork = unitOfWork
+			bankService.LimitRepository = getMockLimitRepository(tt.tierLimit, tt.accountLimit, tt.usage)
+
+			gin.SetMode(gin.TestMode)
+
+			// ------------ expectations ------------
+			mockConfig.On("ThirdPartyBaseUrl").Return("http://provider")
+			mockTransactionRepo.On("FindTransactionByReference", mock.Anything).Return(getMockNotFoundTransaction(), nil)
+			mockUserRepo.On("GetUserAndAccountByAccountNumber", mock.Anything).Return(getMockUser(), account, nil)
+			mockRestClient.
+				On("PostRequest", mock.Anything, mock.Anything, mock.Anything).
+				Return(getSuccessThirdPartyResponse(), http.StatusOK, nil)
+
+			// ------------ executions -----------
+			amount := model.BigDecimal{Decimal: decimal.MustParse(tt.amount)}
+			body := getTransactionRequest("1234567890", "johndoe", "1234", "289192938929293", tt.transactionType, amount)
+			context, recorder := newScheduledTransferContext(t, http.MethodPost, "/api/v1/bank/fund-transfer", body)
+			bankService.Transfer(context)
+
+			var returnedResponse utility.APIResponse
+			if jsonErr := json.Unmarshal(recorder.Body.Bytes(), &returnedResponse); jsonErr != nil {
+				t.Fatalf("Error creating test context: %v", jsonErr)
+			}
+
+			// ------------ assertions -----------
+			assert.Equal(t, http.StatusOK, recorder.Code)
+			assert.Equal(t, tt.expectedMessage, returnedResponse.Message)
+			if tt.expectedMessage != constants.SuccessfulTransactionMsg {
+				assert.False(t, returnedResponse.Success)
+				assert.Empty(t, unitOfWork.Transactions)
+				mockRestClient.AssertNotCalled(t, "PostRequest", mock.Anything, mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
+
+func Test_InternalTransferLimits(t *testing.T) {
+	// ------------ setups ------------
+	mockConfig, mockTransactionRepo, mockUserRepo, mockAccountRepo, mockRestClient, _ := setupMocks()
+	bankService := createBankService(mockConfig, mockTransactionRepo, mockUserRepo, mockAccountRepo, mockRestClient)
+	source, destination := getMockAccount(), getMockDestinationAccount()
+	unitOfWork := &FakeUnitOfWork{Accounts: []*model.Account{source, destination}}
+	bankService.UnitOfWork = unitOfWork
+	bankService.LimitRepository = getMockLimitRepository(
+		getMockLimit("", "1000", "", 0, 0), nil, getMockLimitUsage("900", 1, "900", 1))
+
+	gin.SetMode(gin.TestMode)
+
+	// ------------ expectations ------------
+	mockTransactionRepo.On("FindTransactionByReference", mock.Anything).Return(getMockNotFoundTransaction(), nil)
+	mockUserRepo.On("GetUserAndAccountByAccountNumber", mock.Anything).Return(getMockUser(), source, nil)
+	mockAccountRepo.On("GetAccountByAccountNumber", mock.Anything).Return(destination, nil)
+
+	// ------------ executions -----------
+	body := getInternalTransferRequest("1234567890", "0987654321", "1234", model.BigDecimal{Decimal: decimal.MustParse("200")})
+	context, recorder := newScheduledTransferContext(t, http.MethodPost, "/api/v1/bank/internal-transfer", body)
+	bankService.InternalTransfer(context)
+
+	var returnedResponse utility.APIResponse
+	if jsonErr := json.Unmarshal(recorder.Body.Bytes(), &returnedResponse); jsonErr != nil {
+		t.Fatalf("Error creating test context: %v", jsonErr)
+	}
+
+	// ------------ assertions -----------
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, constants.DailyLimitExceeded, returnedResponse.Message)
+	assert.Empty(t, unitOfWork.Transactions)
+	assert.Equal(t, 0, source.GetBalance().Decimal.Cmp(decimal.MustParse("100000")))
+}
+
+func Test_InternalTransferLimitsAreCheckedAgainOnceTheAccountIsLocked(t *testing.T) {
+	// ------------ setups ------------
+	mockConfig, mockTransactionRepo, mockUserRepo, mockAccountRepo, mockRestClient, _ := setupMocks()
+	bankService := createBankService(mockConfig, mockTransactionRepo, mockUserRepo, mockAccountRepo, mockRestClient)
+	source, destination := getMockAccount(), getMockDestinationAccount()
+	unitOfWork := &FakeUnitOfWork{
+		Accounts: []*model.Account{source, destination},
+		Usage:    getMockLimitUsage("900", 1, "900", 1),
+	}
+	bankService.UnitOfWork = unitOfWork
+	bankService.LimitRepository = getMockLimitRepository(
+		getMockLimit("", "1000", "", 0, 0), nil, getMockLimitUsage("500", 1, "500", 1))
+
+	gin.SetMode(gin.TestMode)
+
+	// ------------ expectations ------------
+	mockTransactionRepo.On("FindTransactionByReference", mock.Anything).Return(getMockNotFoundTransaction(), nil)
+	mockUserRepo.On("GetUserAndAccountByAccountNumber", mock.Anything).Return(getMockUser(), source, nil)
+	mockAccountRepo.On("GetAccountByAccountNumber", mock.Anything).Return(destination, nil)
+
+	// ------------ executions -----------
+	body := getInternalTransferRequest("1234567890", "0987654321", "1234", model.BigDecimal{Decimal: decimal.MustParse("200")})
+	context, recorder := newScheduledTransferContext(t, http.MethodPost, "/api/v1/bank/internal-transfer", body)
+	bankService.InternalTransfer(context)
+
+	var returnedResponse utility.APIResponse
+	if jsonErr := json.Unmarshal(recorder.Body.Bytes(), &returnedResponse); jsonErr != nil {
+		t.Fatalf("Error creating test context: %v", jsonErr)
+	}
+
+	// ------------ assertions -----------
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, constants.DailyLimitExceeded, returnedResponse.Message)
+	assert.Empty(t, unitOfWork.Transactions)
+	assert.Equal(t, 0, source.GetBalance().Decimal.Cmp(decimal.MustParse("100000")))
+}
+
+func Test_SetLimits(t *testing.T) {
+	testCases := []struct {
+		name            string
+		path            string
+		tier            string
+		accountNumber   string
+		account         *model.Account
+		accountErr      error
+		requestBody     string
+		expectedStatus  int
+		expectedMessage string
+		expectedLimit   *model.TransactionLimit
+	}{
+		{
+			name:            "limits of a tier",
+			path:            "/api/v1/bank/admin/limits/tiers/premium",
+			tier:            "premium",
+			requestBody:     `{"max_amount": "500", "daily_amount": "2000", "daily_count": 10}`,
+			expectedStatus:  http.StatusOK,
+			expectedMessage: constants.TransactionLimitsSavedMsg,
+			expectedLimit: &model.TransactionLimit{
+				Tier:        "premium",
+				MaxAmount:   model.BigDecimal{Decimal: decimal.MustParse("500")},
+				DailyAmount: model.BigDecimal{Decimal: decimal.MustParse("2000")},
+				DailyCount:  10,
+			},
+		},
+		{
+			name:            "limits of an account",
+			path:            "/api/v1/bank/admin/limits/accounts/1234567890",
+			accountNumber:   "1234567890",
+			account:         getMockAccount(),
+			requestBody:     `{"monthly_amount": 50000, "monthly_count": 100}`,
+			expectedStatus:  http.StatusOK,
+			expectedMessage: constants.TransactionLimitsSavedMsg,
+			expectedLimit: &model.TransactionLimit{
+				AccountID:     1,
+				MonthlyAmount: model.BigDecimal{Decimal: decimal.MustParse("50000")},
+				MonthlyCount:  100,
+			},
+		},
+		{
+			name:            "unknown account",
+			path:            "/api/v1/bank/admin/limits/accounts/1111111111",
+			accountNumber:   "1111111111",
+			account:         &model.Account{},
+			accountErr:      gorm.ErrRecordNotFound,
+			requestBody:     `{"max_amount": "500"}`,
+			expectedStatus:  http.StatusOK,
+			expectedMessage: constants.AccountNotFound,
+		},
+		{
+			name:            "negative limit",
+			path:            "/api/v1/bank/admin/limits/tiers/premium",
+			tier:            "premium",
+			requestBody:     `{"daily_amount": "-1"}`,
+			expectedStatus:  http.StatusOK,
+			expectedMessage: constants.InvalidTransactionLimit,
+		},
+		{
+			name:            "negative count",
+			path:            "/api/v1/bank/admin/limits/tiers/premium",
+			tier:            "premium",
+			requestBody:     `{"daily_count": -1}`,
+			expectedStatus:  http.StatusBadRequest,
+			expectedMessage: constants.BadRequestMessage,
+		},
+		{
+			name:            "tier name too long",
+			path:            "/api/v1/bank/admin/limits/tiers/a-very-long-tier-name-indeed",
+			tier:            "a-very-long-tier-name-indeed",
+			requestBody:     `{"max_amount": "500"}`,
+			expectedStatus:  http.StatusBadRequest,
+			expectedMessage: constants.InvalidAccountTier,
+		},
+		{
+			name:            "invalid json",
+			path:            "/api/v1/bank/admin/limits/tiers/premium",
+			tier:            "premium",
+			requestBody:     `{"max_amount": `,
+			expectedStatus:  http.StatusBadRequest,
+			expectedMessage: constants.InvalidJsonRequestErrorMsg,
+		},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			// ------------ setups ------------
+			repository := new(FakeLimitRepository)
+			accountRepository := new(MockAccountRepository)
+			service := NewLimitService(repository, accountRepository)
+			gin.SetMode(gin.TestMode)
+
+			// ------------ expectations ------------
+			accountRepository.On("GetAccountByAccountNumber", tt.accountNumber).Return(tt.account, tt.accountErr)
+
+			// ------------ executions -----------
+			context, recorder := newScheduledTransferContext(t, http.MethodPut, tt.path, []byte(tt.requestBody))
+			if tt.tier != "" {
+				context.Params = gin.Params{{Key: "tier", Value: tt.tier}}
+				service.SetTierLimit(context)
+			} else {
+				context.Params = gin.Params{{Key: "number", Value: tt.accountNumber}}
+				service.SetAccountLimit(context)
+			}
+
+			var returnedResponse utility.APIDataResponse
+			if jsonErr := json.Unmarshal(recorder.Body.Bytes(), &returnedResponse); jsonErr != nil {
+				t.Fatalf("Error creating test context: %v", jsonErr)
+			}
+
+			// ------------ assertions -----------
+			assert.Equal(t, tt.expectedStatus, recorder.Code)
+			assert.Equal(t, tt.expectedMessage, returnedResponse.Message)
+			if tt.expectedLimit == nil {
+				assert.Empty(t, repository.Saved)
+				return
+			}
+
+			require.Len(t, repository.Saved, 1)
+			saved := repository.Saved[0]
+			assert.Equal(t, tt.expectedLimit.Tier, saved.Tier)
+			assert.Equal(t, tt.expectedLimit.AccountID, saved.AccountID)
+			assert.Equal(t, 0, saved.MaxAmount.Decimal.Cmp(tt.expectedLimit.MaxAmount.Decimal))
+			assert.Equal(t, 0, saved.DailyAmount.Decimal.Cmp(tt.expectedLimit.DailyAmount.Decimal))
+			assert.Equal(t, 0, saved.MonthlyAmount.Decimal.Cmp(tt.expectedLimit.MonthlyAmount.Decimal))
+			assert.Equal(t, tt.expectedLimit.DailyCount, saved.DailyCount)
+			assert.Equal(t, tt.expectedLimit.MonthlyCount, saved.MonthlyCount)
+		})
+	}
+}
+
+func Test_LimitHeadroom(t *testing.T) {
+	// ------------ setups ------------
+	account := getMockAccount()
+	account.Currency = "NGN"
+	repository := getMockLimitRepository(
+		getMockLimit("500", "1000", "", 3, 0), getMockLimit("", "", "", 0, 0), getMockLimitUsage("1100", 2, "1150", 3))
+	accountRepository := new(MockAccountRepository)
+	service := NewLimitService(repository, accountRepository)
+	gin.SetMode(gin.TestMode)
+
+	// ------------ expectations ------------
+	accountRepository.On("GetAccountByAccountNumber", "1234567890").Return(account, nil)
+
+	// ------------ executions -----------
+	context, recorder := newScheduledTransferContext(t, http.MethodGet, "/api/v1/bank/accounts/1234567890/limits", nil)
+	context.Params = gin.Params{{Key: "number", Value: "1234567890"}}
+	authenticate(t, context, "1234567890", 1, model.CustomerRole)
+	service.Headroom(context)
+
+	var returnedResponse struct {
+		utility.APIDataResponse
+		Data model.LimitHeadroomDTO `json:"data"`
+	}
+	if jsonErr := json.Unmarshal(recorder.Body.Bytes(), &returnedResponse); jsonErr != nil {
+		t.Fatalf("Error creating test context: %v", jsonErr)
+	}
+
+	// ------------ assertions -----------
+	headroom := returnedResponse.Data
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, constants.LimitHeadroomFoundMsg, returnedResponse.Message)
+	assert.Equal(t, model.DefaultAccountTier, headroom.Tier)
+	assert.Equal(t, "NGN", headroom.Currency)
+	assert.Equal(t, 0, headroom.MaxAmount.Decimal.Cmp(decimal.MustParse("500")))
+
+	assert.Equal(t, 0, headroom.Daily.AmountUsed.Decimal.Cmp(decimal.MustParse("1100")))
+	assert.True(t, headroom.Daily.AmountRemaining.Decimal.IsZero())
+	assert.Equal(t, 2, headroom.Daily.CountUsed)
+	assert.Equal(t, 1, *headroom.Daily.CountRemaining)
+
+	assert.Nil(t, headroom.Monthly.AmountLimit)
+	assert.Nil(t, headroom.Monthly.AmountRemaining)
+	assert.Nil(t, headroom.Monthly.CountLimit)
+	assert.Equal(t, 0, headroom.Monthly.AmountUsed.Decimal.Cmp(decimal.MustParse("1150")))
+	assert.Equal(t, 3, headroom.Monthly.CountUsed)
+}
+
+func getMockLimit(maxAmount, dailyAmount, monthlyAmount string, dailyCount, monthlyCount int) *model.TransactionLimit {
+	limit := &model.TransactionLimit{DailyCount: dailyCount, MonthlyCount: monthlyCount}
+	for value, amount := range map[*model.BigDecimal]string{
+		&limit.MaxAmount:     maxAmount,
+		&limit.DailyAmount:   dailyAmount,
+		&limit.MonthlyAmount: monthlyAmount,
+	} {
+		if amount != "" {
+			*value = model.BigDecimal{Decimal: decimal.MustParse(amount)}
+		}
+	}
+	return limit
+}
+
+// getMockLimitRepository holds limits for the default tier and the mock account
+func getMockLimitRepository(
+	tierLimit, accountLimit *model.TransactionLimit,
+	usage model.LimitUsage) *FakeLimitRepository {
+	repository := &FakeLimitRepository{
+		TierLimits:    map[string]*model.TransactionLimit{},
+		AccountLimits: map[uint]*model.TransactionLimit{},
+		Usage:         usage,
+	}
+	if tierLimit != nil {
+		repository.TierLimits[model.DefaultAccountTier] = tierLimit
+	}
+	if accountLimit != nil {
+		repository.AccountLimits[1] = accountLimit
+	}
+	return repository
+}
+
+func getMockLimitUsage(dailyAmount string, dailyCount int, monthlyAmount string, monthlyCount int) model.LimitUsage {
+	return model.LimitUsage{
+		DailyAmount:   model.BigDecimal{Decimal: decimal.MustParse(dailyAmount)},
+		DailyCount:    dailyCount,
+		MonthlyAmount: model.BigDecimal{Decimal: decimal.MustParse(monthlyAmount)},
+		MonthlyCount:  monthlyCount,
+	}
+}
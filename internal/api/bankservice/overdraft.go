@@ -0,0 +1,259 @@
+package bankservice
+
+import (
+	"bankingApp/internal/api/constants"
+	"bankingApp/internal/ledger"
+	"bankingApp/internal/model"
+	"bankingApp/internal/repository"
+	"bankingApp/internal/utility"
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/govalues/decimal"
+)
+
+const overdraftAccrualBatchSize = 50
+
+// maxOverdraftRate is the highest annual percentage an overdraft can be charged at
+var maxOverdraftRate = decimal.MustNew(100, 0)
+
+type IOverdraftRepository interface {
+	FindOverdraftChanges(accountID uint) ([]model.OverdraftChange, error)
+	FindAccountsToAccrue(day, end time.Time, limit int) ([]model.Account, error)
+	BalanceBefore(accountID uint, before time.Time) (model.BigDecimal, error)
+}
+
+// OverdraftService lets bank staff set the overdraft facility of accounts, and charges the accounts that close
+// a day overdrawn a day of interest at the rate of their facility
+type OverdraftService struct {
+	Repository        IOverdraftRepository
+	AccountRepository IAccountRepository
+	UnitOfWork        IUnitOfWork
+}
+
+// NewOverdraftService creates a new instance of OverdraftService
+func NewOverdraftService(
+	repository IOverdraftRepository,
+	accountRepository IAccountRepository,
+	unitOfWork IUnitOfWork) *OverdraftService {
+	return &OverdraftService{Repository: repository, AccountRepository: accountRepository, UnitOfWork: unitOfWork}
+}
+
+// Set handles the admin endpoint setting or changing the overdraft limit and interest rate of an account.
+// Every change is recorded in the audit trail of the account with the member of staff the access token was issued to.
+func (o *OverdraftService) Set(c *gin.Context) {
+	changedBy, ok := staffUsername(c)
+	if !ok {
+		return
+	}
+
+	account, ok := findAccount(c, o.AccountRepository)
+	if !ok {
+		return
+	}
+
+	r, ok := bindRequest[model.OverdraftRequestDTO](c)
+	if !ok {
+		return
+	}
+
+	rate := account.OverdraftRate
+	if r.InterestRate != nil {
+		rate = *r.InterestRate
+	}
+
+	if r.Limit.Decimal.IsNeg() || rate.Decimal.IsNeg() || rate.Decimal.Cmp(maxOverdraftRate) > 0 {
+		utility.HandleError(c, nil, http.StatusOK, constants.InvalidOverdraft)
+		return
+	}
+
+	if tErr := checkTransferCurrency(account.Currency, *r.Limit); tErr != nil {
+		utility.HandleError(c, tErr.err, tErr.statusCode, tErr.message)
+		return
+	}
+
+	o.saveOverdraft(c, account.AccountID, *r.Limit, rate, changedBy, r.Reason, constants.OverdraftSavedMsg)
+}
+
+// Remove handles the admin endpoint removing the overdraft facility of an account. An account that is overdrawn
+// when its facility is removed keeps its balance, but cannot be debited until it is back in credit.
+func (o *OverdraftService) Remove(c *gin.Context) {
+	changedBy, ok := staffUsername(c)
+	if !ok {
+		return
+	}
+
+	account, ok := findAccount(c, o.AccountRepository)
+	if !ok {
+		return
+	}
+
+	r, ok := bindRequest[model.OverdraftRemovalDTO](c)
+	if !ok {
+		return
+	}
+
+	if !account.OverdraftLimit.Decimal.IsPos() && !account.OverdraftRate.Decimal.IsPos() {
+		utility.HandleError(c, nil, http.StatusOK, constants.OverdraftNotSet)
+		return
+	}
+
+	o.saveOverdraft(c, account.AccountID, model.BigDecimal{}, model.BigDecimal{}, changedBy, r.Reason,
+		constants.OverdraftRemovedMsg)
+}
+
+// Get handles the admin endpoint retrieving the overdraft facility of an account with its audit trail
+func (o *OverdraftService) Get(c *gin.Context) {
+	account, ok := findAccount(c, o.AccountRepository)
+	if !ok {
+		return
+	}
+
+	changes, err := o.Repository.FindOverdraftChanges(account.AccountID)
+	if err != nil {
+		utility.HandleError(c, err, http.StatusInternalServerError, constants.ApplicationError)
+		return
+	}
+
+	response := overdraftDTO(account)
+	for _, change := range changes {
+		response.Changes = append(response.Changes, model.OverdraftChangeDTO{
+			PreviousLimit: change.PreviousLimit,
+			Limit:         change.Limit,
+			PreviousRate:  change.PreviousRate,
+			Rate:          change.Rate,
+			ChangedBy:     change.ChangedBy,
+			Reason:        change.Reason,
+			ChangedAt:     change.ChangedAt,
+		})
+	}
+	c.JSON(http.StatusOK, utility.FormulateDataResponse(constants.OverdraftFoundMsg, response))
+}
+
+// AccrueInterest charges every account that closed the day before now overdrawn a day of interest on its closing
+// balance, the balance of the postings made before midnight. An account overdrawn during the day but back in credit
+// by midnight is not charged. Each account accrues once a day however often the job runs, including days when
+// the interest rounds to zero.
+func (o *OverdraftService) AccrueInterest(ctx context.Context, now time.Time) error {
+	midnight, _ := model.LimitPeriods(now)
+	day := midnight.AddDate(0, 0, -1)
+	accounts, err := o.Repository.FindAccountsToAccrue(day, midnight, overdraftAccrualBatchSize)
+	if err != nil {
+		return err
+	}
+
+	for i := range accounts {
+		if err := o.accrue(accounts[i].AccountID, day, midnight); err != nil {
+			slog.Error("unable to accrue overdraft interest", "account_id", accounts[i].AccountID, "error", err)
+		}
+	}
+	return nil
+}
+
+// accrue charges the account a day of overdraft interest on the balance it closed the day on at midnight,
+// and records the accrual in one commit. The postings before midnight no longer change, so the closing balance
+// is read before the account is locked. The interest is charged even when it takes the account beyond its
+// overdraft limit.
+func (o *OverdraftService) accrue(accountID uint, day, midnight time.Time) error {
+	closing, err := o.Repository.BalanceBefore(accountID, midnight)
+	if err != nil {
+		return err
+	}
+
+	return o.UnitOfWork.Execute(func(tx repository.ITx) error {
+		account, err := tx.LockAccount(accountID)
+		if err != nil {
+			return err
+		}
+
+		interest, err := account.DailyOverdraftInterest(closing)
+		if err != nil {
+			return err
+		}
+
+		accrual := &model.OverdraftInterestAccrual{
+			AccountID:   account.AccountID,
+			AccrualDate: day,
+			Reference:   fmt.Sprintf("ODI-%d-%s", account.AccountID, day.Format("20060102")),
+			Balance:     closing,
+			Rate:        account.OverdraftRate,
+			Amount:      interest,
+		}
+		if interest.Decimal.IsPos() {
+			entry, err := ledger.OverdraftInterestEntry(accrual.Reference, account.AccountID, account.Currency, interest)
+			if err != nil {
+				return err
+			}
+			if err := tx.PostCharge(entry); err != nil {
+				return err
+			}
+		}
+		return tx.SaveOverdraftAccrual(accrual)
+	})
+}
+
+// saveOverdraft sets the overdraft of the account and records the change in one commit, and responds with it
+func (o *OverdraftService) saveOverdraft(
+	c *gin.Context,
+	accountID uint,
+	limit, rate model.BigDecimal,
+	changedBy, reason, message string) {
+	var account *model.Account
+	err := o.UnitOfWork.Execute(func(tx repository.ITx) error {
+		locked, err := tx.LockAccount(accountID)
+		if err != nil {
+			return err
+		}
+		change := locked.SetOverdraft(limit, rate, changedBy, reason, time.Now())
+		if err := tx.UpdateOverdraft(locked); err != nil {
+			return err
+		}
+		account = locked
+		return tx.SaveOverdraftChange(change)
+	})
+	if err != nil {
+		utility.HandleError(c, err, http.StatusInternalServerError, constants.ApplicationError)
+		return
+	}
+	c.JSON(http.StatusOK, utility.FormulateDataResponse(message, overdraftDTO(account)))
+}
+
+// bindRequest reads and validates the request body
+func bindRequest[T any](c *gin.Context) (T, bool) {
+	var r T
+	if err := c.BindJSON(&r); err != nil {
+		utility.HandleError(c, err, http.StatusBadRequest, constants.InvalidJsonRequestErrorMsg)
+		return r, false
+	}
+
+	if errorMap, vErr := utility.ValidateRequest(r); len(errorMap) != constants.Zero || vErr != nil {
+		if vErr != nil {
+			utility.HandleError(c, vErr, http.StatusInternalServerError, constants.ApplicationError)
+			return r, false
+		}
+		utility.HandleValidationErrors(c, errorMap)
+		return r, false
+	}
+	return r, true
+}
+
+// overdraftDTO converts the overdraft facility of an account to its response representation.
+// The available balance is reported as zero when the account is overdrawn beyond its limit.
+func overdraftDTO(account *model.Account) model.OverdraftDTO {
+	available := account.AvailableBalance()
+	if available.Decimal.IsNeg() {
+		available = model.BigDecimal{}
+	}
+	return model.OverdraftDTO{
+		AccountNumber:    account.AccountNumber,
+		Currency:         account.Currency,
+		Limit:            setAmount(account.OverdraftLimit),
+		InterestRate:     setAmount(account.OverdraftRate),
+		OverdrawnAmount:  setAmount(account.OverdrawnAmount()),
+		AvailableBalance: &available,
+	}
+}
@@ -0,0 +1,405 @@
+package bankservice
+
+import (
+	"bankingApp/internal/api/constants"
+	"bankingApp/internal/ledger"
+	"bankingApp/internal/model"
+	"bankingApp/internal/utility"
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/govalues/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+// FakeOverdraftRepository reads the overdraft audit trail and accruals saved through the fake unit of work
+type FakeOverdraftRepository struct {
+	UnitOfWork *FakeUnitOfWork
+	// Closing are the balances the accounts closed the day on, accounts that are not in it closed on their balance
+	Closing map[uint]model.BigDecimal
+}
+
+func (f *FakeOverdraftRepository) FindOverdraftChanges(accountID uint) ([]model.OverdraftChange, error) {
+	var changes []model.OverdraftChange
+	for i := len(f.UnitOfWork.Changes) - 1; i >= 0; i-- {
+		if f.UnitOfWork.Changes[i].AccountID == accountID {
+			changes = append(changes, *f.UnitOfWork.Changes[i])
+		}
+	}
+	return changes, nil
+}
+
+func (f *FakeOverdraftRepository) FindAccountsToAccrue(day, end time.Time, limit int) ([]model.Account, error) {
+	accrued := make(map[uint]bool)
+	for _, accrual := range f.UnitOfWork.Accruals {
+		if accrual.AccrualDate.Equal(day) {
+			accrued[accrual.AccountID] = true
+		}
+	}
+
+	var accounts []model.Account
+	for _, account := range f.UnitOfWork.Accounts {
+		closing, _ := f.BalanceBefore(account.AccountID, end)
+		if closing.Decimal.IsNeg() && account.OverdraftRate.Decimal.IsPos() &&
+			!accrued[account.AccountID] && len(accounts) < limit {
+			accounts = append(accounts, model.Account{AccountID: account.AccountID})
+		}
+	}
+	return accounts, nil
+}
+
+func (f *FakeOverdraftRepository) BalanceBefore(accountID uint, before time.Time) (model.BigDecimal, error) {
+	if closing, ok := f.Closing[accountID]; ok {
+		return closing, nil
+	}
+	for _, account := range f.UnitOfWork.Accounts {
+		if account.AccountID == accountID {
+			return account.GetBalance(), nil
+		}
+	}
+	return model.BigDecimal{}, nil
+}
+
+func Test_SetOverdraft(t *testing.T) {
+	testCases := []struct {
+		name            string
+		accountErr      error
+		anonymous       bool
+		rate            string
+		requestBody     string
+		expectedStatus  int
+		expectedMessage string
+		expectedLimit   string
+		expectedRate    string
+	}{
+		{
+			name:            "new overdraft",
+			requestBody:     `{"limit": "5000", "interest_rate": "18.5", "reason": "agreed facility"}`,
+			expectedStatus:  http.StatusOK,
+			expectedMessage: constants.OverdraftSavedMsg,
+			expectedLimit:   "5000",
+			expectedRate:    "18.5",
+		},
+		{
+			name:            "limit change keeps the interest rate",
+			rate:            "12",
+			requestBody:     `{"limit": "2500"}`,
+			expectedStatus:  http.StatusOK,
+			expectedMessage: constants.OverdraftSavedMsg,
+			expectedLimit:   "2500",
+			expectedRate:    "12",
+		},
+		{
+			name:            "negative limit",
+			requestBody:     `{"limit": "-10"}`,
+			expectedStatus:  http.StatusOK,
+			expectedMessage: constants.InvalidOverdraft,
+			expectedLimit:   "0",
+			expectedRate:    "0",
+		},
+		{
+			name:            "interest rate over 100",
+			requestBody:     `{"limit": "100", "interest_rate": "101"}`,
+			expectedStatus:  http.StatusOK,
+			expectedMessage: constants.InvalidOverdraft,
+			expectedLimit:   "0",
+			expectedRate:    "0",
+		},
+		{
+			name:            "not authenticated",
+			anonymous:       true,
+			requestBody:     `{"limit": "100"}`,
+			expectedStatus:  http.StatusUnauthorized,
+			expectedMessage: constants.AccessTokenRequired,
+			expectedLimit:   "0",
+			expectedRate:    "0",
+		},
+		{
+			name:            "unknown account",
+			accountErr:      gorm.ErrRecordNotFound,
+			requestBody:     `{"limit": "100"}`,
+			expectedStatus:  http.StatusOK,
+			expectedMessage: constants.AccountNotFound,
+			expectedLimit:   "0",
+			expectedRate:    "0",
+		},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			// ------------ setups ------------
+			overdraftService, unitOfWork, mockAccountRepo := createOverdraftService()
+			account := unitOfWork.Accounts[0]
+			if tt.rate != "" {
+				account.OverdraftRate = model.BigDecimal{Decimal: decimal.MustParse(tt.rate)}
+			}
+			gin.SetMode(gin.TestMode)
+
+			// ------------ expectations ------------
+			mockAccountRepo.On("GetAccountByAccountNumber", "1234567890").Return(account, tt.accountErr)
+
+			// ------------ executions -----------
+			context, recorder := newScheduledTransferContext(t, http.MethodPut, "/api/v1/bank/admin/overdrafts/1234567890",
+				[]byte(tt.requestBody))
+			context.Params = gin.Params{{Key: "number", Value: "1234567890"}}
+			if !tt.anonymous {
+				authenticate(t, context, "ops.user", 5, model.OpsRole)
+			}
+			overdraftService.Set(context)
+
+			var returnedResponse struct {
+				utility.APIDataResponse
+				Data model.OverdraftDTO `json:"data"`
+			}
+			if jsonErr := json.Unmarshal(recorder.Body.Bytes(), &returnedResponse); jsonErr != nil {
+				t.Fatalf("Error creating test context: %v", jsonErr)
+			}
+
+			// ------------ assertions -----------
+			assert.Equal(t, tt.expectedStatus, recorder.Code)
+			assert.Equal(t, tt.expectedMessage, returnedResponse.Message)
+			assert.Equal(t, 0, account.OverdraftLimit.Decimal.Cmp(decimal.MustParse(tt.expectedLimit)))
+			assert.Equal(t, 0, account.OverdraftRate.Decimal.Cmp(decimal.MustParse(tt.expectedRate)))
+			if tt.expectedMessage != constants.OverdraftSavedMsg {
+				assert.Empty(t, unitOfWork.Changes)
+				return
+			}
+
+			require.Len(t, unitOfWork.Changes, 1)
+			change := unitOfWork.Changes[0]
+			assert.Equal(t, "ops.user", change.ChangedBy)
+			assert.True(t, change.PreviousLimit.Decimal.IsZero())
+			assert.Equal(t, 0, change.Limit.Decimal.Cmp(decimal.MustParse(tt.expectedLimit)))
+			assert.Equal(t, 0, change.Rate.Decimal.Cmp(decimal.MustParse(tt.expectedRate)))
+
+			overdraft := returnedResponse.Data
+			assert.Equal(t, 0, overdraft.Limit.Decimal.Cmp(decimal.MustParse(tt.expectedLimit)))
+			available, _ := decimal.MustParse("100000").Add(decimal.MustParse(tt.expectedLimit))
+			assert.Equal(t, 0, overdraft.AvailableBalance.Decimal.Cmp(available))
+			assert.Nil(t, overdraft.OverdrawnAmount)
+		})
+	}
+}
+
+func Test_RemoveOverdraft(t *testing.T) {
+	testCases := []struct {
+		name            string
+		limit           string
+		expectedMessage string
+		expectedChanges int
+	}{
+		{name: "overdraft is removed", limit: "5000", expectedMessage: constants.OverdraftRemovedMsg, expectedChanges: 1},
+		{name: "account without overdraft", limit: "0", expectedMessage: constants.OverdraftNotSet},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			// ------------ setups ------------
+			overdraftService, unitOfWork, mockAccountRepo := createOverdraftService()
+			account := unitOfWork.Accounts[0]
+			account.SetBalance(model.BigDecimal{Decimal: decimal.MustParse("-200")})
+			account.OverdraftLimit = model.BigDecimal{Decimal: decimal.MustParse(tt.limit)}
+			gin.SetMode(gin.TestMode)
+
+			// ------------ expectations ------------
+			mockAccountRepo.On("GetAccountByAccountNumber", "1234567890").Return(account, nil)
+
+			// ------------ executions -----------
+			context, recorder := newScheduledTransferContext(t, http.MethodDelete, "/api/v1/bank/admin/overdrafts/1234567890",
+				[]byte(`{"reason": "facility withdrawn"}`))
+			context.Params = gin.Params{{Key: "number", Value: "1234567890"}}
+			authenticate(t, context, "ops.user", 5, model.OpsRole)
+			overdraftService.Remove(context)
+
+			var returnedResponse struct {
+				utility.APIDataResponse
+				Data model.OverdraftDTO `json:"data"`
+			}
+			if jsonErr := json.Unmarshal(recorder.Body.Bytes(), &returnedResponse); jsonErr != nil {
+				t.Fatalf("Error creating test context: %v", jsonErr)
+			}
+
+			// ------------ assertions -----------
+			assert.Equal(t, http.StatusOK, recorder.Code)
+			assert.Equal(t, tt.expectedMessage, returnedResponse.Message)
+			assert.True(t, account.OverdraftLimit.Decimal.IsZero())
+			assert.Len(t, unitOfWork.Changes, tt.expectedChanges)
+			if tt.expectedChanges == 0 {
+				return
+			}
+
+			assert.Equal(t, "facility withdrawn", unitOfWork.Changes[0].Reason)
+			assert.Equal(t, 0, unitOfWork.Changes[0].PreviousLimit.Decimal.Cmp(decimal.MustParse("5000")))
+			assert.Nil(t, returnedResponse.Data.Limit)
+			assert.Equal(t, 0, returnedResponse.Data.OverdrawnAmount.Decimal.Cmp(decimal.MustParse("200")))
+			assert.True(t, returnedResponse.Data.AvailableBalance.Decimal.IsZero())
+			assert.True(t, account.IsInsufficientBalance(model.BigDecimal{Decimal: decimal.MustParse("0.01")}))
+		})
+	}
+}
+
+func Test_GetOverdraftListsItsAuditTrail(t *testing.T) {
+	// ------------ setups ------------
+	overdraftService, unitOfWork, mockAccountRepo := createOverdraftService()
+	account := unitOfWork.Accounts[0]
+	now := time.Now()
+	unitOfWork.Changes = []*model.OverdraftChange{
+		account.SetOverdraft(amountOf("5000"), amountOf("18"), "ops.user", "agreed facility", now.Add(-time.Hour)),
+		account.SetOverdraft(amountOf("2500"), amountOf("18"), "risk.user", "reduced", now),
+	}
+	gin.SetMode(gin.TestMode)
+
+	// ------------ expectations ------------
+	mockAccountRepo.On("GetAccountByAccountNumber", "1234567890").Return(account, nil)
+
+	// ------------ executions -----------
+	context, recorder := newScheduledTransferContext(t, http.MethodGet, "/api/v1/bank/admin/overdrafts/1234567890", nil)
+	context.Params = gin.Params{{Key: "number", Value: "1234567890"}}
+	overdraftService.Get(context)
+
+	var returnedResponse struct {
+		utility.APIDataResponse
+		Data model.OverdraftDTO `json:"data"`
+	}
+	if jsonErr := json.Unmarshal(recorder.Body.Bytes(), &returnedResponse); jsonErr != nil {
+		t.Fatalf("Error creating test context: %v", jsonErr)
+	}
+
+	// ------------ assertions -----------
+	assert.Equal(t, constants.OverdraftFoundMsg, returnedResponse.Message)
+	overdraft := returnedResponse.Data
+	assert.Equal(t, 0, overdraft.Limit.Decimal.Cmp(decimal.MustParse("2500")))
+	assert.Equal(t, 0, overdraft.InterestRate.Decimal.Cmp(decimal.MustParse("18")))
+	require.Len(t, overdraft.Changes, 2)
+	assert.Equal(t, "risk.user", overdraft.Changes[0].ChangedBy)
+	assert.Equal(t, 0, overdraft.Changes[0].PreviousLimit.Decimal.Cmp(decimal.MustParse("5000")))
+	assert.Equal(t, "ops.user", overdraft.Changes[1].ChangedBy)
+}
+
+func Test_TransferUsesTheOverdraft(t *testing.T) {
+	testCases := []struct {
+		name            string
+		amount          string
+		expectedMessage string
+		expectedBalance string
+	}{
+		{name: "debit into the overdraft", amount: "100500", expectedMessage: constants.SuccessfulTransactionMsg, expectedBalance: "-500"},
+		{name: "debit beyond the overdraft", amount: "101000.01", expectedMessage: constants.InsufficientFunds, expectedBalance: "100000"},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			// ------------ setups ------------
+			mockConfig, mockTransactionRepo, mockUserRepo, mockAccountRepo, mockRestClient, _ := setupMocks()
+			bankService := createBankService(mockConfig, mockTransactionRepo, mockUserRepo, mockAccountRepo, mockRestClient)
+			account := getMockAccount()
+			account.OverdraftLimit = amountOf("1000")
+			unitOfWork := &FakeUnitOfWork{Accounts: []*model.Account{account}}
+			bankService.UnitOfWork = unitOfWork
+			gin.SetMode(gin.TestMode)
+
+			// ------------ expectations ------------
+			mockConfig.On("ThirdPartyBaseUrl").Return("http://provider")
+			mockTransactionRepo.On("FindTransactionByReference", mock.Anything).Return(getMockNotFoundTransaction(), nil)
+			mockUserRepo.On("GetUserAndAccountByAccountNumber", mock.Anything).Return(getMockUser(), account, nil)
+			mockRestClient.
+				On("PostRequest", mock.Anything, mock.Anything, mock.Anything).
+				Return(getSuccessThirdPartyResponse(), http.StatusOK, nil)
+
+			// ------------ executions -----------
+			body := getTransactionRequest("1234567890", "johndoe", "1234", "289192938929293", model.DebitTransaction,
+				amountOf(tt.amount))
+			context, recorder := newScheduledTransferContext(t, http.MethodPost, "/api/v1/bank/fund-transfer", body)
+			bankService.Transfer(context)
+
+			var returnedResponse utility.APIResponse
+			if jsonErr := json.Unmarshal(recorder.Body.Bytes(), &returnedResponse); jsonErr != nil {
+				t.Fatalf("Error creating test context: %v", jsonErr)
+			}
+
+			// ------------ assertions -----------
+			assert.Equal(t, tt.expectedMessage, returnedResponse.Message)
+			assert.Equal(t, 0, account.GetBalance().Decimal.Cmp(decimal.MustParse(tt.expectedBalance)))
+		})
+	}
+}
+
+func Test_AccrueOverdraftInterest(t *testing.T) {
+	// ------------ setups ------------
+	overdraftService, unitOfWork, _ := createOverdraftService()
+	overdrawn := unitOfWork.Accounts[0]
+	overdrawn.SetBalance(amountOf("-1000"))
+	overdrawn.OverdraftLimit = amountOf("1000")
+	overdrawn.OverdraftRate = amountOf("18.25")
+	inCredit := &model.Account{AccountID: 2, Balance: amountOf("50"), OverdraftLimit: amountOf("1000"),
+		OverdraftRate: amountOf("18.25")}
+	withoutRate := &model.Account{AccountID: 3, Balance: amountOf("-50"), OverdraftLimit: amountOf("100")}
+	unitOfWork.Accounts = append(unitOfWork.Accounts, inCredit, withoutRate)
+	now := time.Date(2024, 3, 2, 15, 30, 0, 0, time.UTC)
+
+	// ------------ executions -----------
+	require.NoError(t, overdraftService.AccrueInterest(context.Background(), now))
+	require.NoError(t, overdraftService.AccrueInterest(context.Background(), now.Add(time.Hour)))
+
+	// ------------ assertions -----------
+	require.Len(t, unitOfWork.Accruals, 1)
+	accrual := unitOfWork.Accruals[0]
+	assert.Equal(t, overdrawn.AccountID, accrual.AccountID)
+	assert.Equal(t, time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), accrual.AccrualDate)
+	assert.Equal(t, "ODI-1-20240301", accrual.Reference)
+	assert.Equal(t, 0, accrual.Amount.Decimal.Cmp(decimal.MustParse("0.50")))
+	assert.Equal(t, 0, accrual.Balance.Decimal.Cmp(decimal.MustParse("-1000")))
+	assert.Equal(t, 0, overdrawn.GetBalance().Decimal.Cmp(decimal.MustParse("-1000.50")))
+	assert.Equal(t, 0, inCredit.GetBalance().Decimal.Cmp(decimal.MustParse("50")))
+	assert.Equal(t, 0, withoutRate.GetBalance().Decimal.Cmp(decimal.MustParse("-50")))
+
+	require.Len(t, unitOfWork.Entries, 1)
+	assert.Equal(t, accrual.Reference, unitOfWork.Entries[0].Reference)
+	assert.Equal(t, ledger.OverdraftInterestGL, unitOfWork.Entries[0].Postings[1].LedgerCode)
+
+	require.NoError(t, overdraftService.AccrueInterest(context.Background(), now.AddDate(0, 0, 1)))
+	assert.Len(t, unitOfWork.Accruals, 2)
+	assert.Equal(t, 0, overdrawn.GetBalance().Decimal.Cmp(decimal.MustParse("-1001.00")))
+}
+
+func Test_AccrueOverdraftInterestOnTheBalanceTheDayClosedOn(t *testing.T) {
+	// ------------ setups ------------
+	unitOfWork := &FakeUnitOfWork{Accounts: []*model.Account{getMockAccount()}}
+	repository := &FakeOverdraftRepository{UnitOfWork: unitOfWork, Closing: map[uint]model.BigDecimal{}}
+	overdraftService := NewOverdraftService(repository, new(MockAccountRepository), unitOfWork)
+	repaidAfterMidnight := unitOfWork.Accounts[0]
+	repaidAfterMidnight.SetBalance(amountOf("500"))
+	repaidAfterMidnight.OverdraftRate = amountOf("18.25")
+	repository.Closing[repaidAfterMidnight.AccountID] = amountOf("-1000")
+	overdrawnDuringTheDay := &model.Account{AccountID: 2, Balance: amountOf("-30"), OverdraftLimit: amountOf("1000"),
+		OverdraftRate: amountOf("18.25")}
+	repository.Closing[overdrawnDuringTheDay.AccountID] = amountOf("20")
+	unitOfWork.Accounts = append(unitOfWork.Accounts, overdrawnDuringTheDay)
+
+	// ------------ executions -----------
+	require.NoError(t, overdraftService.AccrueInterest(context.Background(), time.Date(2024, 3, 2, 0, 5, 0, 0, time.UTC)))
+
+	// ------------ assertions -----------
+	require.Len(t, unitOfWork.Accruals, 1)
+	accrual := unitOfWork.Accruals[0]
+	assert.Equal(t, repaidAfterMidnight.AccountID, accrual.AccountID)
+	assert.Equal(t, 0, accrual.Balance.Decimal.Cmp(decimal.MustParse("-1000")))
+	assert.Equal(t, 0, accrual.Amount.Decimal.Cmp(decimal.MustParse("0.50")))
+	assert.Equal(t, 0, repaidAfterMidnight.GetBalance().Decimal.Cmp(decimal.MustParse("499.50")))
+	assert.Equal(t, 0, overdrawnDuringTheDay.GetBalance().Decimal.Cmp(decimal.MustParse("-30")))
+}
+
+func createOverdraftService() (*OverdraftService, *FakeUnitOfWork, *MockAccountRepository) {
+	unitOfWork := &FakeUnitOfWork{Accounts: []*model.Account{getMockAccount()}}
+	mockAccountRepo := new(MockAccountRepository)
+	overdraftService := NewOverdraftService(&FakeOverdraftRepository{UnitOfWork: unitOfWork}, mockAccountRepo, unitOfWork)
+	return overdraftService, unitOfWork, mockAccountRepo
+}
+
+func amountOf(value string) model.BigDecimal {
+	return model.BigDecimal{Decimal: decimal.MustParse(value)}
+}
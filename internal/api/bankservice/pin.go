@@ -0,0 +1,266 @@
+package bankservice
+
+import (
+	"bankingApp/internal/api/constants"
+	"bankingApp/internal/credential"
+	"bankingApp/internal/model"
+	"bankingApp/internal/notify"
+	"bankingApp/internal/utility"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	pinResetCodeDigits = 6
+	pinResetCodeTTL    = 15 * time.Minute
+)
+
+type IPinRepository interface {
+	SavePinAudit(audit *model.PinAudit) error
+	SavePinResetCode(code *model.PinResetCode) error
+	FindPinResetCode(userID uint) (*model.PinResetCode, error)
+	RecordPinResetCodeAttempt(codeID uint) error
+	UpdateTransactionPin(userID uint, pinHash string) error
+	ResetTransactionPin(codeID, userID uint, pinHash string, now time.Time) error
+}
+
+type INotifier interface {
+	Send(notification notify.Notification) error
+}
+
+// pinErrors are the PIN change and reset failures reported to the client as business errors
+var pinErrors = []error{
+	model.ErrIncorrectPin,
+	model.ErrPinLocked,
+	model.ErrTrivialPin,
+	model.ErrPinUnchanged,
+	model.ErrInvalidPinResetCode,
+}
+
+// PinService lets users change their transaction PIN, or reset it with a one-time code sent to them when they
+// have forgotten it. Every attempt is recorded in the PIN audit trail, whether it succeeded or not.
+type PinService struct {
+	Repository     IPinRepository
+	UserRepository IUserRepository
+	Notifier       INotifier
+}
+
+// NewPinService creates a new instance of PinService sending PIN reset codes through the notifier
+func NewPinService(repository IPinRepository, userRepository IUserRepository, notifier INotifier) *PinService {
+	return &PinService{Repository: repository, UserRepository: userRepository, Notifier: notifier}
+}
+
+// Change handles the endpoint replacing the transaction PIN of the authenticated user. The current PIN is
+// checked like any other, so incorrect ones count towards locking PIN use.
+func (p *PinService) Change(c *gin.Context) {
+	user, ok := tokenUser(c, p.UserRepository)
+	if !ok {
+		return
+	}
+
+	r, ok := bindRequest[model.PinChangeRequestDTO](c)
+	if !ok {
+		return
+	}
+
+	err := checkTransactionPin(p.UserRepository, user, r.OldPin)
+	if err == nil {
+		err = checkNewPin(user, r.NewPin)
+	}
+	if err == nil {
+		err = p.updateTransactionPin(user, r.NewPin)
+	}
+	if !p.audit(c, user.UserID, model.PinChangeAction, err) {
+		return
+	}
+	c.JSON(http.StatusOK, utility.FormulateDataResponse(constants.PinChangedMsg, nil))
+}
+
+// RequestReset handles the endpoint sending the authenticated user a one-time code to reset their transaction
+// PIN with. Requesting another code expires the codes sent before it.
+func (p *PinService) RequestReset(c *gin.Context) {
+	user, ok := tokenUser(c, p.UserRepository)
+	if !ok {
+		return
+	}
+
+	expiresAt, err := p.sendResetCode(user)
+	if !p.audit(c, user.UserID, model.PinResetRequestAction, err) {
+		return
+	}
+	response := model.PinResetCodeDTO{ExpiresAt: expiresAt}
+	c.JSON(http.StatusOK, utility.FormulateDataResponse(constants.PinResetCodeSentMsg, response))
+}
+
+// Reset handles the endpoint setting a new transaction PIN for the authenticated user with the code sent to them.
+// The code can be used once, and too many incorrect codes burn it.
+func (p *PinService) Reset(c *gin.Context) {
+	user, ok := tokenUser(c, p.UserRepository)
+	if !ok {
+		return
+	}
+
+	r, ok := bindRequest[model.PinResetRequestDTO](c)
+	if !ok {
+		return
+	}
+
+	err := p.reset(user, r)
+	if !p.audit(c, user.UserID, model.PinResetAction, err) {
+		return
+	}
+	c.JSON(http.StatusOK, utility.FormulateDataResponse(constants.PinChangedMsg, nil))
+}
+
+func (p *PinService) updateTransactionPin(user *model.User, pin string) error {
+	hashed, err := credential.Hash(pin)
+	if err != nil {
+		return err
+	}
+	return p.Repository.UpdateTransactionPin(user.UserID, hashed)
+}
+
+// sendResetCode stores a new PIN reset code for the user and sends it to them, returning when it expires
+func (p *PinService) sendResetCode(user *model.User) (time.Time, error) {
+	code, err := credential.NewOneTimeCode(pinResetCodeDigits)
+	if err != nil {
+		return time.Time{}, err
+	}
+	hashed, err := credential.Hash(code)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	now := time.Now()
+	resetCode := &model.PinResetCode{
+		UserID:        user.UserID,
+		CodeHash:      hashed,
+		ExpiresAt:     now.Add(pinResetCodeTTL),
+		TimestampData: model.TimestampData{CreatedAt: now, UpdatedAt: now},
+	}
+	if err := p.Repository.SavePinResetCode(resetCode); err != nil {
+		return time.Time{}, err
+	}
+
+	return resetCode.ExpiresAt, p.Notifier.Send(notify.Notification{
+		Username:    user.Username,
+		Email:       user.Email,
+		PhoneNumber: user.PhoneNumber,
+		Subject:     "Transaction PIN reset",
+		Body: fmt.Sprintf("Your code to reset your transaction PIN is %s. It expires at %s.",
+			code, resetCode.ExpiresAt.Format(time.RFC1123)),
+		SentAt: now,
+	})
+}
+
+func (p *PinService) reset(user *model.User, r model.PinResetRequestDTO) error {
+	if err := model.CheckPinStrength(r.NewPin); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	code, err := p.Repository.FindPinResetCode(user.UserID)
+	if err != nil {
+		return err
+	}
+	if code.PinResetCodeID == constants.Zero || !code.Usable(now) {
+		return model.ErrInvalidPinResetCode
+	}
+	if !credential.Verify(code.CodeHash, r.Code) {
+		if err := p.Repository.RecordPinResetCodeAttempt(code.PinResetCodeID); err != nil {
+			return err
+		}
+		return model.ErrInvalidPinResetCode
+	}
+
+	hashed, err := credential.Hash(r.NewPin)
+	if err != nil {
+		return err
+	}
+	return p.Repository.ResetTransactionPin(code.PinResetCodeID, user.UserID, hashed, now)
+}
+
+// audit records the attempt in the PIN audit trail and responds with its failure, if any. It reports whether
+// the attempt succeeded.
+func (p *PinService) audit(c *gin.Context, userID uint, action model.PinAction, err error) bool {
+	audit := &model.PinAudit{
+		UserID:    userID,
+		Action:    action,
+		Succeeded: err == nil,
+		ClientIP:  c.ClientIP(),
+		CreatedAt: time.Now(),
+	}
+	reported := constants.ApplicationError
+	for _, pinErr := range pinErrors {
+		if errors.Is(err, pinErr) {
+			reported = pinErr.Error()
+		}
+	}
+	if err != nil {
+		audit.Reason = reported
+	}
+	if auditErr := p.Repository.SavePinAudit(audit); auditErr != nil {
+		utility.HandleError(c, auditErr, http.StatusInternalServerError, constants.ApplicationError)
+		return false
+	}
+
+	if err == nil {
+		return true
+	}
+	if reported == constants.ApplicationError {
+		utility.HandleError(c, err, http.StatusInternalServerError, constants.ApplicationError)
+	} else {
+		utility.HandleError(c, nil, http.StatusOK, reported)
+	}
+	return false
+}
+
+// checkNewPin rejects a new transaction PIN that is easy to guess or the same as the current one
+func checkNewPin(user *model.User, pin string) error {
+	if err := model.CheckPinStrength(pin); err != nil {
+		return err
+	}
+	if user.CheckTransactionPin(pin) {
+		return model.ErrPinUnchanged
+	}
+	return nil
+}
+
+// checkTransactionPin verifies the transaction PIN of the user. Every incorrect PIN is counted against the user
+// and too many in a row lock PIN use for a cooldown, during which even the correct PIN is refused. A correct PIN
+// clears the incorrect ones counted so far.
+func checkTransactionPin(users IUserRepository, user *model.User, pin string) error {
+	now := time.Now()
+	if user.PinLocked(now) {
+		return model.ErrPinLocked
+	}
+
+	if !user.CheckTransactionPin(pin) {
+		if err := users.RecordFailedPinAttempt(user.UserID, now); err != nil {
+			return err
+		}
+		return model.ErrIncorrectPin
+	}
+
+	if user.PinAttempts == constants.Zero && user.PinLockedUntil == nil {
+		return nil
+	}
+	return users.ResetPinAttempts(user.UserID)
+}
+
+// verifyTransactionPin checks the transaction PIN given with a request, reporting an incorrect or locked PIN
+// to the client as a business error
+func verifyTransactionPin(users IUserRepository, user *model.User, pin string) *transferError {
+	err := checkTransactionPin(users, user, pin)
+	if errors.Is(err, model.ErrIncorrectPin) || errors.Is(err, model.ErrPinLocked) {
+		return newTransferError(nil, http.StatusOK, err.Error())
+	}
+	if err != nil {
+		return newTransferError(err, http.StatusInternalServerError, constants.ApplicationError)
+	}
+	return nil
+}
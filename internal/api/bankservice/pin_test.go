@@ -0,0 +1,513 @@
+package bankservice
+
+import (
+	"bankingApp/internal/api/constants"
+	"bankingApp/internal/api/middleware"
+	"bankingApp/internal/credential"
+	"bankingApp/internal/model"
+	"bankingApp/internal/notify"
+	"bankingApp/internal/token"
+	"bankingApp/internal/utility"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_CheckTransactionPin(t *testing.T) {
+	lockedUntil := time.Now().Add(10 * time.Minute)
+	expiredLock := time.Now().Add(-time.Minute)
+
+	testCases := []struct {
+		name            string
+		pin             string
+		attempts        int
+		lockedUntil     *time.Time
+		expectedErr     error
+		expectedRecords bool
+		expectedResets  bool
+	}{
+		{
+			name: "correct PIN",
+			pin:  "1234",
+		},
+		{
+			name:            "incorrect PIN",
+			pin:             "4321",
+			expectedErr:     model.ErrIncorrectPin,
+			expectedRecords: true,
+		},
+		{
+			name:           "correct PIN after incorrect ones",
+			pin:            "1234",
+			attempts:       2,
+			expectedResets: true,
+		},
+		{
+			name:        "correct PIN while locked",
+			pin:         "1234",
+			lockedUntil: &lockedUntil,
+			expectedErr: model.ErrPinLocked,
+		},
+		{
+			name:        "incorrect PIN while locked",
+			pin:         "4321",
+			lockedUntil: &lockedUntil,
+			expectedErr: model.ErrPinLocked,
+		},
+		{
+			name:           "correct PIN once the lock has expired",
+			pin:            "1234",
+			lockedUntil:    &expiredLock,
+			expectedResets: true,
+		},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			// ------------ setups ------------
+			mockUserRepo := new(MockUserRepository)
+			user := getMockUser()
+			user.PinAttempts = tt.attempts
+			user.PinLockedUntil = tt.lockedUntil
+
+			// ------------ expectations ------------
+			mockUserRepo.On("RecordFailedPinAttempt", uint(1), mock.Anything).Return(nil)
+			mockUserRepo.On("ResetPinAttempts", uint(1)).Return(nil)
+
+			// ------------ executions -----------
+			err := checkTransactionPin(mockUserRepo, user, tt.pin)
+
+			// ------------ assertions -----------
+			assert.ErrorIs(t, err, tt.expectedErr)
+			if tt.expectedRecords {
+				mockUserRepo.AssertCalled(t, "RecordFailedPinAttempt", uint(1), mock.Anything)
+			} else {
+				mockUserRepo.AssertNotCalled(t, "RecordFailedPinAttempt", uint(1), mock.Anything)
+			}
+			if tt.expectedResets {
+				mockUserRepo.AssertCalled(t, "ResetPinAttempts", uint(1))
+			} else {
+				mockUserRepo.AssertNotCalled(t, "ResetPinAttempts", uint(1))
+			}
+		})
+	}
+}
+
+func Test_VerifyTransactionPinReportsLockedPins(t *testing.T) {
+	lockedUntil := time.Now().Add(10 * time.Minute)
+	user := getMockUser()
+	user.PinLockedUntil = &lockedUntil
+
+	tErr := verifyTransactionPin(new(MockUserRepository), user, "1234")
+
+	assert.NotNil(t, tErr)
+	assert.Equal(t, http.StatusOK, tErr.statusCode)
+	assert.Equal(t, constants.TransactionPinLocked, tErr.message)
+}
+
+// FakePinRepository keeps the PIN audit trail, reset codes and updated PIN hashes in memory
+type FakePinRepository struct {
+	Audits []*model.PinAudit
+	Codes  []*model.PinResetCode
+	Pins   map[uint]string
+}
+
+func (f *FakePinRepository) SavePinAudit(audit *model.PinAudit) error {
+	f.Audits = append(f.Audits, audit)
+	return nil
+}
+
+func (f *FakePinRepository) SavePinResetCode(code *model.PinResetCode) error {
+	for _, earlier := range f.Codes {
+		if earlier.UserID == code.UserID && earlier.UsedAt == nil {
+			earlier.ExpiresAt = code.CreatedAt
+		}
+	}
+	code.PinResetCodeID = uint(len(f.Codes) + 1)
+	f.Codes = append(f.Codes, code)
+	return nil
+}
+
+func (f *FakePinRepository) FindPinResetCode(userID uint) (*model.PinResetCode, error) {
+	for i := len(f.Codes) - 1; i >= 0; i-- {
+		if f.Codes[i].UserID == userID {
+			return f.Codes[i], nil
+		}
+	}
+	return &model.PinResetCode{}, nil
+}
+
+func (f *FakePinRepository) RecordPinResetCodeAttempt(codeID uint) error {
+	f.Codes[codeID-1].Attempts++
+	return nil
+}
+
+func (f *FakePinRepository) UpdateTransactionPin(userID uint, pinHash string) error {
+	f.Pins[userID] = pinHash
+	return nil
+}
+
+func (f *FakePinRepository) ResetTransactionPin(codeID, userID uint, pinHash string, now time.Time) error {
+	code := f.Codes[codeID-1]
+	if code.UsedAt != nil {
+		return model.ErrInvalidPinResetCode
+	}
+	code.UsedAt = &now
+	f.Pins[userID] = pinHash
+	return nil
+}
+
+// FakeNotifier keeps the notifications sent instead of delivering them
+type FakeNotifier struct {
+	Sent []notify.Notification
+}
+
+func (f *FakeNotifier) Send(notification notify.Notification) error {
+	f.Sent = append(f.Sent, notification)
+	return nil
+}
+
+// sentCode reads the PIN reset code out of the last notification sent
+func (f *FakeNotifier) sentCode(t *testing.T) string {
+	require.NotEmpty(t, f.Sent)
+	code := regexp.MustCompile(`[0-9]{6}`).FindString(f.Sent[len(f.Sent)-1].Body)
+	require.NotEmpty(t, code)
+	return code
+}
+
+func Test_ChangePin(t *testing.T) {
+	lockedUntil := time.Now().Add(10 * time.Minute)
+
+	testCases := []struct {
+		name            string
+		authenticated   bool
+		lockedUntil     *time.Time
+		requestBody     string
+		expectedStatus  int
+		expectedMessage string
+		expectedAudit   bool
+	}{
+		{
+			name:            "change",
+			authenticated:   true,
+			requestBody:     `{"old_pin": "2580", "new_pin": "7391"}`,
+			expectedStatus:  http.StatusOK,
+			expectedMessage: constants.PinChangedMsg,
+			expectedAudit:   true,
+		},
+		{
+			name:            "incorrect current PIN",
+			authenticated:   true,
+			requestBody:     `{"old_pin": "2581", "new_pin": "7391"}`,
+			expectedStatus:  http.StatusOK,
+			expectedMessage: constants.IncorrectTransactionPin,
+			expectedAudit:   true,
+		},
+		{
+			name:            "PIN use locked",
+			authenticated:   true,
+			lockedUntil:     &lockedUntil,
+			requestBody:     `{"old_pin": "2580", "new_pin": "7391"}`,
+			expectedStatus:  http.StatusOK,
+			expectedMessage: constants.TransactionPinLocked,
+			expectedAudit:   true,
+		},
+		{
+			name:            "repeated digit",
+			authenticated:   true,
+			requestBody:     `{"old_pin": "2580", "new_pin": "0000"}`,
+			expectedStatus:  http.StatusOK,
+			expectedMessage: constants.TrivialPin,
+			expectedAudit:   true,
+		},
+		{
+			name:            "ascending digits",
+			authenticated:   true,
+			requestBody:     `{"old_pin": "2580", "new_pin": "1234"}`,
+			expectedStatus:  http.StatusOK,
+			expectedMessage: constants.TrivialPin,
+			expectedAudit:   true,
+		},
+		{
+			name:            "descending digits",
+			authenticated:   true,
+			requestBody:     `{"old_pin": "2580", "new_pin": "9876"}`,
+			expectedStatus:  http.StatusOK,
+			expectedMessage: constants.TrivialPin,
+			expectedAudit:   true,
+		},
+		{
+			name:            "same PIN",
+			authenticated:   true,
+			requestBody:     `{"old_pin": "2580", "new_pin": "2580"}`,
+			expectedStatus:  http.StatusOK,
+			expectedMessage: constants.PinUnchanged,
+			expectedAudit:   true,
+		},
+		{
+			name:            "new PIN is not four digits",
+			authenticated:   true,
+			requestBody:     `{"old_pin": "2580", "new_pin": "73915"}`,
+			expectedStatus:  http.StatusBadRequest,
+			expectedMessage: constants.BadRequestMessage,
+		},
+		{
+			name:            "no access token",
+			requestBody:     `{"old_pin": "2580", "new_pin": "7391"}`,
+			expectedStatus:  http.StatusUnauthorized,
+			expectedMessage: constants.AccessTokenRequired,
+		},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			// ------------ setups ------------
+			pinService, pinRepository, _, mockUserRepo := createPinService()
+			user := getMockUser()
+			user.TransactionPin = hashPin("2580")
+			user.PinLockedUntil = tt.lockedUntil
+			gin.SetMode(gin.TestMode)
+
+			// ------------ expectations ------------
+			mockUserRepo.On("FindUserByUsername", "1234567890").Return(*user, nil)
+			mockUserRepo.On("RecordFailedPinAttempt", uint(1), mock.Anything).Return(nil)
+
+			// ------------ executions -----------
+			context, recorder := newPinContext(t, http.MethodPut, "/api/v1/bank/users/me/pin", tt.requestBody,
+				tt.authenticated)
+			if !context.IsAborted() {
+				pinService.Change(context)
+			}
+
+			var returnedResponse utility.APIDataResponse
+			require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &returnedResponse))
+
+			// ------------ assertions -----------
+			assert.Equal(t, tt.expectedStatus, recorder.Code)
+			assert.Equal(t, tt.expectedMessage, returnedResponse.Message)
+			if !tt.expectedAudit {
+				assert.Empty(t, pinRepository.Audits)
+				return
+			}
+
+			require.Len(t, pinRepository.Audits, 1)
+			audit := pinRepository.Audits[0]
+			assert.Equal(t, model.PinChangeAction, audit.Action)
+			assert.Equal(t, uint(1), audit.UserID)
+			if tt.expectedMessage != constants.PinChangedMsg {
+				assert.False(t, audit.Succeeded)
+				assert.Equal(t, tt.expectedMessage, audit.Reason)
+				assert.Empty(t, pinRepository.Pins)
+				return
+			}
+
+			assert.True(t, audit.Succeeded)
+			assert.True(t, credential.Verify(pinRepository.Pins[1], "7391"))
+		})
+	}
+}
+
+func Test_ResetPin(t *testing.T) {
+	testCases := []struct {
+		name            string
+		prepare         func(pinRepository *FakePinRepository, code string) string
+		newPin          string
+		expectedMessage string
+	}{
+		{
+			name:            "reset",
+			newPin:          "7391",
+			expectedMessage: constants.PinChangedMsg,
+		},
+		{
+			name: "incorrect code",
+			prepare: func(pinRepository *FakePinRepository, code string) string {
+				return wrongCode(code)
+			},
+			newPin:          "7391",
+			expectedMessage: constants.InvalidPinResetCode,
+		},
+		{
+			name: "expired code",
+			prepare: func(pinRepository *FakePinRepository, code string) string {
+				pinRepository.Codes[0].ExpiresAt = time.Now().Add(-time.Minute)
+				return code
+			},
+			newPin:          "7391",
+			expectedMessage: constants.InvalidPinResetCode,
+		},
+		{
+			name: "code burnt by incorrect codes",
+			prepare: func(pinRepository *FakePinRepository, code string) string {
+				pinRepository.Codes[0].Attempts = model.MaxPinResetCodeAttempts
+				return code
+			},
+			newPin:          "7391",
+			expectedMessage: constants.InvalidPinResetCode,
+		},
+		{
+			name: "code already used",
+			prepare: func(pinRepository *FakePinRepository, code string) string {
+				usedAt := time.Now()
+				pinRepository.Codes[0].UsedAt = &usedAt
+				return code
+			},
+			newPin:          "7391",
+			expectedMessage: constants.InvalidPinResetCode,
+		},
+		{
+			name:            "trivial PIN",
+			newPin:          "5555",
+			expectedMessage: constants.TrivialPin,
+		},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			// ------------ setups ------------
+			pinService, pinRepository, notifier, mockUserRepo := createPinService()
+			gin.SetMode(gin.TestMode)
+
+			// ------------ expectations ------------
+			mockUserRepo.On("FindUserByUsername", "1234567890").Return(*getMockUser(), nil)
+
+			// ------------ executions -----------
+			context, recorder := newPinContext(t, http.MethodPost, "/api/v1/bank/users/me/pin/reset", "", true)
+			pinService.RequestReset(context)
+
+			var requested struct {
+				utility.APIDataResponse
+				Data model.PinResetCodeDTO `json:"data"`
+			}
+			require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &requested))
+			assert.Equal(t, constants.PinResetCodeSentMsg, requested.Message)
+			assert.WithinDuration(t, time.Now().Add(15*time.Minute), requested.Data.ExpiresAt, time.Minute)
+
+			code := notifier.sentCode(t)
+			assert.True(t, credential.IsHashed(pinRepository.Codes[0].CodeHash))
+			if tt.prepare != nil {
+				code = tt.prepare(pinRepository, code)
+			}
+
+			body, _ := json.Marshal(model.PinResetRequestDTO{Code: code, NewPin: tt.newPin})
+			context, recorder = newPinContext(t, http.MethodPost, "/api/v1/bank/users/me/pin/reset/confirm",
+				string(body), true)
+			pinService.Reset(context)
+
+			var returnedResponse utility.APIDataResponse
+			require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &returnedResponse))
+
+			// ------------ assertions -----------
+			assert.Equal(t, http.StatusOK, recorder.Code)
+			assert.Equal(t, tt.expectedMessage, returnedResponse.Message)
+			require.Len(t, pinRepository.Audits, 2)
+			assert.Equal(t, model.PinResetRequestAction, pinRepository.Audits[0].Action)
+			assert.True(t, pinRepository.Audits[0].Succeeded)
+			audit := pinRepository.Audits[1]
+			assert.Equal(t, model.PinResetAction, audit.Action)
+			if tt.expectedMessage != constants.PinChangedMsg {
+				assert.False(t, audit.Succeeded)
+				assert.Equal(t, tt.expectedMessage, audit.Reason)
+				assert.Empty(t, pinRepository.Pins)
+				return
+			}
+
+			assert.True(t, audit.Succeeded)
+			assert.True(t, credential.Verify(pinRepository.Pins[1], "7391"))
+			assert.NotNil(t, pinRepository.Codes[0].UsedAt)
+		})
+	}
+}
+
+func Test_IncorrectResetCodesBurnTheCode(t *testing.T) {
+	// ------------ setups ------------
+	pinService, pinRepository, notifier, mockUserRepo := createPinService()
+	gin.SetMode(gin.TestMode)
+	mockUserRepo.On("FindUserByUsername", "1234567890").Return(*getMockUser(), nil)
+	context, _ := newPinContext(t, http.MethodPost, "/api/v1/bank/users/me/pin/reset", "", true)
+	pinService.RequestReset(context)
+	code := notifier.sentCode(t)
+
+	// ------------ executions -----------
+	reset := func(code string) string {
+		body, _ := json.Marshal(model.PinResetRequestDTO{Code: code, NewPin: "7391"})
+		context, recorder := newPinContext(t, http.MethodPost, "/api/v1/bank/users/me/pin/reset/confirm",
+			string(body), true)
+		pinService.Reset(context)
+		var returnedResponse utility.APIDataResponse
+		require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &returnedResponse))
+		return returnedResponse.Message
+	}
+	for attempt := 0; attempt < model.MaxPinResetCodeAttempts; attempt++ {
+		assert.Equal(t, constants.InvalidPinResetCode, reset(wrongCode(code)))
+	}
+
+	// ------------ assertions -----------
+	assert.Equal(t, constants.InvalidPinResetCode, reset(code))
+	assert.Empty(t, pinRepository.Pins)
+	assert.Len(t, pinRepository.Audits, model.MaxPinResetCodeAttempts+2)
+}
+
+func Test_CheckPinStrength(t *testing.T) {
+	for _, pin := range []string{"0000", "7777", "1234", "6789", "4321", "3210"} {
+		assert.ErrorIs(t, model.CheckPinStrength(pin), model.ErrTrivialPin, pin)
+	}
+	for _, pin := range []string{"2580", "1235", "9870", "1212", "0901"} {
+		assert.NoError(t, model.CheckPinStrength(pin), pin)
+	}
+}
+
+func createPinService() (*PinService, *FakePinRepository, *FakeNotifier, *MockUserRepository) {
+	pinRepository := &FakePinRepository{Pins: map[uint]string{}}
+	notifier := &FakeNotifier{}
+	mockUserRepo := new(MockUserRepository)
+	return NewPinService(pinRepository, mockUserRepo, notifier), pinRepository, notifier, mockUserRepo
+}
+
+// newPinContext creates a request context that has been through the auth middleware, with an access token
+// issued to the mock user when authenticated
+func newPinContext(t *testing.T, method, url, body string, authenticated bool) (*gin.Context, *httptest.ResponseRecorder) {
+	var requestBody []byte
+	if body != "" {
+		requestBody = []byte(body)
+	}
+	context, recorder := newScheduledTransferContext(t, method, url, requestBody)
+	if authenticated {
+		authenticate(t, context, "1234567890", 1, model.CustomerRole)
+	}
+	return context, recorder
+}
+
+// authenticate runs the request through the auth middleware with an access token issued to the user
+func authenticate(t *testing.T, context *gin.Context, username string, userID uint, role model.UserRole) {
+	issuer, err := token.NewIssuer("secret", token.AccessTokenTTL)
+	require.NoError(t, err)
+	accessToken, _, err := issuer.Issue(username, userID, string(role))
+	require.NoError(t, err)
+	context.Request.Header.Set(constants.AuthorizationHeader, "Bearer "+accessToken)
+
+	users := tokenUserRepository{UserID: userID, Username: username, Role: role}
+	authMiddleware := middleware.AuthMiddleware{Tokens: issuer, Users: users}
+	authMiddleware.Authenticate()(context)
+}
+
+// tokenUserRepository only knows the user an access token is issued to in a test
+type tokenUserRepository model.User
+
+func (u tokenUserRepository) FindUserByUsername(username string) (model.User, error) {
+	if username != u.Username {
+		return model.User{}, nil
+	}
+	return model.User(u), nil
+}
+
+// wrongCode returns a code of the same length that differs from the code in its last digit
+func wrongCode(code string) string {
+	last := (code[len(code)-1]-'0'+1)%10 + '0'
+	return code[:len(code)-1] + string(last)
+}
@@ -0,0 +1,371 @@
+package bankservice
+
+import (
+	"bankingApp/internal/api/constants"
+	"bankingApp/internal/api/middleware"
+	"bankingApp/internal/currency"
+	"bankingApp/internal/ledger"
+	"bankingApp/internal/model"
+	"bankingApp/internal/repository"
+	"bankingApp/internal/utility"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// reversalErrors are the reversal failures reported to the client as business errors
+var reversalErrors = []error{
+	model.ErrTransactionNotReversible,
+	model.ErrTransactionAlreadyReversed,
+	model.ErrReversalAmountExceeded,
+	model.ErrInsufficientFunds,
+}
+
+// Reverse handles the reversal endpoint for undoing a completed transaction in full or in part.
+// A compensating transaction linked to the original moves the funds back. Transactions that went out
+// through the third-party provider are reversed with the provider, internal transfers are reversed at once.
+func (b *BankTransferService) Reverse(c *gin.Context) {
+	var r model.ReversalRequestDTO
+	if err := c.BindJSON(&r); err != nil {
+		utility.HandleError(c, err, http.StatusBadRequest, constants.InvalidJsonRequestErrorMsg)
+		return
+	}
+
+	if err := b.validateTransferRequest(c, r); err != nil {
+		return
+	}
+
+	original, err := b.TransactionRepository.FindTransactionByReference(c.Param("ref"))
+	if err != nil {
+		utility.HandleError(c, err, http.StatusInternalServerError, constants.ApplicationError)
+		return
+	}
+
+	if original.TransactionID == constants.Zero {
+		utility.HandleError(c, nil, http.StatusOK, constants.TransactionNotFound)
+		return
+	}
+
+	amount, err := original.ReversibleAmount()
+	if err != nil {
+		utility.HandleError(c, err, http.StatusInternalServerError, constants.ApplicationError)
+		return
+	}
+	if r.Amount != nil {
+		amount = *r.Amount
+	}
+
+	if err := original.CheckReversal(amount); err != nil {
+		handleReversalError(c, err)
+		return
+	}
+
+	if original.CorrelationReference != "" {
+		b.reverseInternalTransfer(c, original, amount, r.Reason)
+		return
+	}
+	b.reverseThirdPartyTransaction(c, original, amount, r.Reason)
+}
+
+// reverseThirdPartyTransaction reserves the amount on the original, then sends the compensating transaction
+// through the same lifecycle as a transfer. A failed reversal gives the reserved amount back to the original.
+func (b *BankTransferService) reverseThirdPartyTransaction(
+	c *gin.Context,
+	original *model.Transaction,
+	amount model.BigDecimal,
+	reason string) {
+	reference, done := b.newReference(c)
+	if done {
+		return
+	}
+
+	reversal, err := newReversalTransaction(original, reference, amount, model.PendingStatus)
+	if err != nil {
+		utility.HandleError(c, err, http.StatusInternalServerError, constants.ApplicationError)
+		return
+	}
+
+	err = b.UnitOfWork.Execute(func(tx repository.ITx) error {
+		if _, err := reserveReversal(tx, original.TransactionID, amount); err != nil {
+			return err
+		}
+		return savePendingTransaction(tx, reversal)
+	})
+	if err != nil {
+		handleReversalError(c, err)
+		return
+	}
+	middleware.MarkPersisted(c)
+
+	url := fmt.Sprintf("%s/api/v1/third-party/payments/%s/reverse", b.Config.ThirdPartyBaseUrl(), original.Reference)
+	_, status, outcome, err := b.submitTransaction(reversal, url)
+	if err != nil {
+		utility.HandleError(c, err, http.StatusInternalServerError, constants.ApplicationError)
+		return
+	}
+
+	if err := b.completeTransaction(reversal, status, fmt.Sprintf("%s: %s", reason, outcome)); err != nil {
+		utility.HandleError(c, err, http.StatusInternalServerError, constants.ApplicationError)
+		return
+	}
+
+	switch status {
+	case model.UnknownStatus:
+		utility.HandleError(c, nil, http.StatusOK, constants.TransactionOutcomeUnknown)
+		return
+	case model.FailedStatus:
+		utility.HandleError(c, nil, http.StatusOK, constants.TransactionRejected)
+		return
+	}
+
+	b.respondReversal(c, original.TransactionID, reversal)
+}
+
+// reverseInternalTransfer reverses both legs of an internal transfer in one commit,
+// moving the amount from the destination account back to the source account.
+// The amount is in the currency of the original transaction; the other leg reverses the matching part of its amount.
+func (b *BankTransferService) reverseInternalTransfer(
+	c *gin.Context,
+	original *model.Transaction,
+	amount model.BigDecimal,
+	reason string) {
+	debitLeg, creditLeg, done := b.findInternalTransferLegs(c, original.CorrelationReference)
+	if done {
+		return
+	}
+
+	debitReference, done := b.newReference(c)
+	if done {
+		return
+	}
+
+	creditReference, done := b.newReference(c)
+	if done {
+		return
+	}
+
+	amounts, err := internalReversalAmounts(original, debitLeg, creditLeg, amount)
+	if err != nil {
+		utility.HandleError(c, err, http.StatusInternalServerError, constants.ApplicationError)
+		return
+	}
+
+	reversalDebit, err := newReversalTransaction(creditLeg, debitReference, amounts[creditLeg], model.SucceededStatus)
+	if err != nil {
+		utility.HandleError(c, err, http.StatusInternalServerError, constants.ApplicationError)
+		return
+	}
+
+	reversalCredit, err := newReversalTransaction(debitLeg, creditReference, amounts[debitLeg], model.SucceededStatus)
+	if err != nil {
+		utility.HandleError(c, err, http.StatusInternalServerError, constants.ApplicationError)
+		return
+	}
+	reversalDebit.CorrelationReference = debitReference
+	reversalCredit.CorrelationReference = debitReference
+
+	entry, err := ledger.InternalTransferEntry(debitReference, reversalDebit, reversalCredit)
+	if err != nil {
+		utility.HandleError(c, err, http.StatusInternalServerError, constants.ApplicationError)
+		return
+	}
+
+	err = b.UnitOfWork.Execute(func(tx repository.ITx) error {
+		for _, leg := range []*model.Transaction{debitLeg, creditLeg} {
+			locked, err := reserveReversal(tx, leg.TransactionID, amounts[leg])
+			if err != nil {
+				return err
+			}
+			if locked.IsFullyReversed() {
+				if err := tx.UpdateTransactionStatus(locked, model.ReversedStatus, reason); err != nil {
+					return err
+				}
+			}
+		}
+		if err := tx.PostJournalEntry(entry); err != nil {
+			return err
+		}
+		if err := tx.SaveTransaction(reversalDebit); err != nil {
+			return err
+		}
+		return tx.SaveTransaction(reversalCredit)
+	})
+	if err != nil {
+		handleReversalError(c, err)
+		return
+	}
+
+	reversal := reversalCredit
+	if original.TransactionID == creditLeg.TransactionID {
+		reversal = reversalDebit
+	}
+	b.respondReversal(c, original.TransactionID, reversal)
+}
+
+// internalReversalAmounts returns the amount to reverse from each leg of an internal transfer when amount is
+// reversed from the original leg. Legs in the same currency reverse the same amount. Otherwise the other leg reverses
+// the same share of its amount, and reversing the rest of the original reverses the rest of the other leg.
+func internalReversalAmounts(
+	original, debitLeg, creditLeg *model.Transaction,
+	amount model.BigDecimal) (map[*model.Transaction]model.BigDecimal, error) {
+	leg, other := debitLeg, creditLeg
+	if original.TransactionID == creditLeg.TransactionID {
+		leg, other = creditLeg, debitLeg
+	}
+	amounts := map[*model.Transaction]model.BigDecimal{leg: amount, other: amount}
+	if leg.Currency == other.Currency {
+		return amounts, nil
+	}
+
+	remaining, err := leg.ReversibleAmount()
+	if err != nil {
+		return nil, err
+	}
+	if amount.Decimal.Cmp(remaining.Decimal) == 0 {
+		amounts[other], err = other.ReversibleAmount()
+		return amounts, err
+	}
+
+	share, err := other.Amount.Decimal.Mul(amount.Decimal)
+	if err != nil {
+		return nil, err
+	}
+	share, err = share.Quo(leg.Amount.Decimal)
+	if err != nil {
+		return nil, err
+	}
+	amounts[other] = model.BigDecimal{Decimal: share.Round(currency.MinorUnits(other.Currency))}
+	return amounts, nil
+}
+
+// findInternalTransferLegs loads the debit and credit legs of the internal transfer with the given correlation reference
+func (b *BankTransferService) findInternalTransferLegs(
+	c *gin.Context,
+	correlationReference string) (*model.Transaction, *model.Transaction, bool) {
+	debitLeg, err := b.TransactionRepository.FindTransactionByReference(correlationReference)
+	if err != nil {
+		utility.HandleError(c, err, http.StatusInternalServerError, constants.ApplicationError)
+		return nil, nil, true
+	}
+
+	creditLeg, err := b.TransactionRepository.FindTransactionByReference(correlationReference + creditLegSuffix)
+	if err != nil {
+		utility.HandleError(c, err, http.StatusInternalServerError, constants.ApplicationError)
+		return nil, nil, true
+	}
+
+	if debitLeg.TransactionID == constants.Zero || creditLeg.TransactionID == constants.Zero {
+		slog.Error("internal transfer leg is missing", "correlation_reference", correlationReference)
+		utility.InternalServerError(c)
+		return nil, nil, true
+	}
+	return debitLeg, creditLeg, false
+}
+
+// respondReversal writes the reversal together with the current state of the transaction it reverses
+func (b *BankTransferService) respondReversal(c *gin.Context, originalID uint, reversal *model.Transaction) {
+	original, err := b.TransactionRepository.FindTransaction(originalID)
+	if err != nil {
+		utility.HandleError(c, err, http.StatusInternalServerError, constants.ApplicationError)
+		return
+	}
+
+	amount, reversed := reversal.Amount, original.ReversedAmount
+	response := model.ReversalResponseDTO{
+		PaymentReference:  original.PaymentReference,
+		ReversalReference: reversal.Reference,
+		Amount:            &amount,
+		ReversedAmount:    &reversed,
+		Currency:          original.Currency,
+		Status:            reversal.Status,
+		OriginalStatus:    original.Status,
+	}
+	c.JSON(http.StatusOK, utility.FormulateDataResponse(constants.SuccessfulReversalMsg, response))
+}
+
+// newReversalTransaction creates the compensating transaction of the original, moving the amount the other way.
+// A converted transaction is reversed at its original rate.
+func newReversalTransaction(
+	original *model.Transaction,
+	reference string,
+	amount model.BigDecimal,
+	status model.TransactionStatus) (*model.Transaction, error) {
+	transferAmount, err := original.TransferAmountOf(amount)
+	if err != nil {
+		return nil, err
+	}
+
+	transactionType := model.CreditTransaction
+	if original.Type == model.CreditTransaction {
+		transactionType = model.DebitTransaction
+	}
+
+	originalID := original.TransactionID
+	now := time.Now()
+	return &model.Transaction{
+		AccountID:             original.AccountID,
+		Reference:             reference,
+		PaymentReference:      reference,
+		Amount:                amount,
+		Currency:              original.Currency,
+		TransferAmount:        transferAmount,
+		TransferCurrency:      original.TransferCurrency,
+		ExchangeRate:          original.ExchangeRate,
+		Type:                  transactionType,
+		Status:                status,
+		OriginalTransactionID: &originalID,
+		TransactionTime:       now,
+		TimestampData:         model.TimestampData{CreatedAt: now},
+	}, nil
+}
+
+// reserveReversal locks the transaction and adds the amount to its reversed amount,
+// so concurrent reversals cannot together reverse more than the transaction amount
+func reserveReversal(tx repository.ITx, transactionID uint, amount model.BigDecimal) (*model.Transaction, error) {
+	locked, err := tx.LockTransaction(transactionID)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := locked.AddReversal(amount); err != nil {
+		return nil, err
+	}
+	return locked, tx.UpdateReversedAmount(locked)
+}
+
+// settleReversedTransaction updates the transaction a reversal compensates once the reversal has an outcome:
+// a succeeded reversal may complete the reversal of the original, a failed one gives its amount back.
+func settleReversedTransaction(tx repository.ITx, reversal *model.Transaction, status model.TransactionStatus) error {
+	original, err := tx.LockTransaction(*reversal.OriginalTransactionID)
+	if err != nil {
+		return err
+	}
+
+	switch status {
+	case model.SucceededStatus:
+		if original.Status == model.SucceededStatus && original.IsFullyReversed() {
+			return tx.UpdateTransactionStatus(original, model.ReversedStatus, "reversed by "+reversal.Reference)
+		}
+	case model.FailedStatus:
+		if err := original.ReleaseReversal(reversal.Amount); err != nil {
+			return err
+		}
+		return tx.UpdateReversedAmount(original)
+	}
+	return nil
+}
+
+// handleReversalError writes the response of a failed reversal
+func handleReversalError(c *gin.Context, err error) {
+	for _, reversalErr := range reversalErrors {
+		if errors.Is(err, reversalErr) {
+			utility.HandleError(c, nil, http.StatusOK, reversalErr.Error())
+			return
+		}
+	}
+	slog.Error("error in reversing transaction", "error", err)
+	utility.InternalServerError(c)
+}
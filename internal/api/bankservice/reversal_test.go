@@ -0,0 +1,275 @@
+package bankservice //nolint:typecheck
+
+import (
+	"bankingApp/internal/api/constants"
+	"bankingApp/internal/model"
+	"bankingApp/internal/utility"
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/govalues/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func Test_Reverse(t *testing.T) {
+	testCases := []struct {
+		name                   string
+		requestBody            []byte
+		original               *model.Transaction
+		restStatusCode         int
+		expectedStatus         int
+		expectedSuccess        bool
+		expectedMessage        string
+		expectedBalance        string
+		expectedReversed       string
+		expectedOriginalStatus model.TransactionStatus
+		expectProviderCall     bool
+	}{
+		{
+			name:                   "full reversal of a third-party debit",
+			requestBody:            getReversalRequest(nil),
+			original:               getMockReversibleTransaction(model.DebitTransaction, model.SucceededStatus),
+			restStatusCode:         http.StatusOK,
+			expectedStatus:         http.StatusOK,
+			expectedSuccess:        true,
+			expectedMessage:        constants.SuccessfulReversalMsg,
+			expectedBalance:        "100100",
+			expectedReversed:       "100",
+			expectedOriginalStatus: model.ReversedStatus,
+			expectProviderCall:     true,
+		},
+		{
+			name:                   "partial reversal of a third-party debit",
+			requestBody:            getReversalRequest(decimalPointer("40")),
+			original:               getMockReversibleTransaction(model.DebitTransaction, model.SucceededStatus),
+			restStatusCode:         http.StatusOK,
+			expectedStatus:         http.StatusOK,
+			expectedSuccess:        true,
+			expectedMessage:        constants.SuccessfulReversalMsg,
+			expectedBalance:        "100040",
+			expectedReversed:       "40",
+			expectedOriginalStatus: model.SucceededStatus,
+			expectProviderCall:     true,
+		},
+		{
+			name:                   "full reversal of a third-party credit",
+			requestBody:            getReversalRequest(nil),
+			original:               getMockReversibleTransaction(model.CreditTransaction, model.SucceededStatus),
+			restStatusCode:         http.StatusOK,
+			expectedStatus:         http.StatusOK,
+			expectedSuccess:        true,
+			expectedMessage:        constants.SuccessfulReversalMsg,
+			expectedBalance:        "99900",
+			expectedReversed:       "100",
+			expectedOriginalStatus: model.ReversedStatus,
+			expectProviderCall:     true,
+		},
+		{
+			name:                   "third-party rejects the reversal",
+			requestBody:            getReversalRequest(nil),
+			original:               getMockReversibleTransaction(model.CreditTransaction, model.SucceededStatus),
+			restStatusCode:         http.StatusUnprocessableEntity,
+			expectedStatus:         http.StatusOK,
+			expectedMessage:        constants.TransactionRejected,
+			expectedBalance:        "100000",
+			expectedReversed:       "0",
+			expectedOriginalStatus: model.SucceededStatus,
+			expectProviderCall:     true,
+		},
+		{
+			name:                   "reversal amount exceeds the transaction amount",
+			requestBody:            getReversalRequest(decimalPointer("150")),
+			original:               getMockReversibleTransaction(model.DebitTransaction, model.SucceededStatus),
+			expectedStatus:         http.StatusOK,
+			expectedMessage:        constants.ReversalAmountExceeded,
+			expectedBalance:        "100000",
+			expectedReversed:       "0",
+			expectedOriginalStatus: model.SucceededStatus,
+		},
+		{
+			name:                   "transaction already reversed",
+			requestBody:            getReversalRequest(nil),
+			original:               getMockReversibleTransaction(model.DebitTransaction, model.ReversedStatus),
+			expectedStatus:         http.StatusOK,
+			expectedMessage:        constants.TransactionAlreadyReversed,
+			expectedBalance:        "100000",
+			expectedReversed:       "0",
+			expectedOriginalStatus: model.ReversedStatus,
+		},
+		{
+			name:                   "failed transaction cannot be reversed",
+			requestBody:            getReversalRequest(nil),
+			original:               getMockReversibleTransaction(model.DebitTransaction, model.FailedStatus),
+			expectedStatus:         http.StatusOK,
+			expectedMessage:        constants.TransactionNotReversible,
+			expectedBalance:        "100000",
+			expectedReversed:       "0",
+			expectedOriginalStatus: model.FailedStatus,
+		},
+		{
+			name:            "transaction not found",
+			requestBody:     getReversalRequest(nil),
+			original:        getMockNotFoundTransaction(),
+			expectedStatus:  http.StatusOK,
+			expectedMessage: constants.TransactionNotFound,
+		},
+		{
+			name:            "bad request missing reason",
+			requestBody:     []byte(`{"amount": 10}`),
+			expectedStatus:  http.StatusBadRequest,
+			expectedMessage: constants.BadRequestMessage,
+		},
+		{
+			name:            "bad request negative amount",
+			requestBody:     getReversalRequest(decimalPointer("-10")),
+			expectedStatus:  http.StatusBadRequest,
+			expectedMessage: constants.BadRequestMessage,
+		},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			// ------------ setups ------------
+			mockConfig, mockTransactionRepo, mockUserRepo, mockAccountRepo, mockRestClient, _ := setupMocks()
+			bankService := createBankService(mockConfig, mockTransactionRepo, mockUserRepo, mockAccountRepo, mockRestClient)
+			account := getMockAccount()
+			unitOfWork := &FakeUnitOfWork{Accounts: []*model.Account{account}}
+			if tt.original != nil && tt.original.TransactionID != constants.Zero {
+				unitOfWork.Transactions = append(unitOfWork.Transactions, tt.original)
+			}
+			bankService.UnitOfWork = unitOfWork
+
+			gin.SetMode(gin.TestMode)
+
+			// ------------ expectations ------------
+			mockConfig.On("ThirdPartyBaseUrl").Return("http://provider")
+
+			mockTransactionRepo.
+				On("FindTransactionByReference", "289192938929293").Return(tt.original, nil)
+
+			mockTransactionRepo.
+				On("FindTransaction", uint(1)).Return(tt.original, nil)
+
+			mockRestClient.
+				On("PostRequest", "http://provider/api/v1/third-party/payments/ref0/reverse", mock.Anything, mock.Anything).
+				Return(map[string]interface{}{}, tt.restStatusCode, nil)
+
+			// ------------ executions -----------
+			context, recorder := newReversalContext(t, tt.requestBody)
+			bankService.Reverse(context)
+
+			var returnedResponse utility.APIDataResponse
+			if jsonErr := json.Unmarshal(recorder.Body.Bytes(), &returnedResponse); jsonErr != nil {
+				t.Fatalf("Error creating test context: %v", jsonErr)
+			}
+
+			// ------------ assertions -----------
+			assert.Equal(t, tt.expectedStatus, recorder.Code)
+			assert.Equal(t, tt.expectedSuccess, returnedResponse.Success)
+			assert.Equal(t, tt.expectedMessage, returnedResponse.Message)
+
+			if !tt.expectProviderCall {
+				mockRestClient.AssertNotCalled(t, "PostRequest", mock.Anything, mock.Anything, mock.Anything)
+			}
+
+			if tt.expectedBalance == "" {
+				return
+			}
+			assert.Equal(t, 0, account.GetBalance().Decimal.Cmp(decimal.MustParse(tt.expectedBalance)))
+			assert.Equal(t, 0, tt.original.ReversedAmount.Decimal.Cmp(decimal.MustParse(tt.expectedReversed)))
+			assert.Equal(t, tt.expectedOriginalStatus, tt.original.Status)
+
+			if tt.expectedSuccess {
+				reversal := unitOfWork.Transactions[len(unitOfWork.Transactions)-1]
+				assert.Equal(t, tt.original.TransactionID, *reversal.OriginalTransactionID)
+				assert.NotEqual(t, tt.original.Type, reversal.Type)
+				assert.Equal(t, model.SucceededStatus, reversal.Status)
+			}
+		})
+	}
+}
+
+func Test_ReverseInternalTransfer(t *testing.T) {
+	// ------------ setups ------------
+	mockConfig, mockTransactionRepo, mockUserRepo, mockAccountRepo, mockRestClient, _ := setupMocks()
+	bankService := createBankService(mockConfig, mockTransactionRepo, mockUserRepo, mockAccountRepo, mockRestClient)
+	source, destination := getMockAccount(), getMockDestinationAccount()
+	debitLeg := getMockReversibleTransaction(model.DebitTransaction, model.SucceededStatus)
+	debitLeg.CorrelationReference = debitLeg.PaymentReference
+	creditLeg := getMockReversibleTransaction(model.CreditTransaction, model.SucceededStatus)
+	creditLeg.TransactionID, creditLeg.AccountID = 2, destination.AccountID
+	creditLeg.PaymentReference = debitLeg.PaymentReference + creditLegSuffix
+	creditLeg.CorrelationReference = debitLeg.PaymentReference
+	unitOfWork := &FakeUnitOfWork{
+		Accounts:     []*model.Account{source, destination},
+		Transactions: []*model.Transaction{debitLeg, creditLeg},
+	}
+	bankService.UnitOfWork = unitOfWork
+
+	gin.SetMode(gin.TestMode)
+
+	// ------------ expectations ------------
+	mockTransactionRepo.On("FindTransactionByReference", debitLeg.PaymentReference).Return(debitLeg, nil)
+	mockTransactionRepo.On("FindTransactionByReference", creditLeg.PaymentReference).Return(creditLeg, nil)
+	mockTransactionRepo.On("FindTransaction", debitLeg.TransactionID).Return(debitLeg, nil)
+
+	// ------------ executions -----------
+	context, recorder := newReversalContext(t, getReversalRequest(decimalPointer("60")))
+	bankService.Reverse(context)
+
+	var returnedResponse utility.APIDataResponse
+	if jsonErr := json.Unmarshal(recorder.Body.Bytes(), &returnedResponse); jsonErr != nil {
+		t.Fatalf("Error creating test context: %v", jsonErr)
+	}
+
+	// ------------ assertions -----------
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.True(t, returnedResponse.Success)
+	assert.Equal(t, 0, source.GetBalance().Decimal.Cmp(decimal.MustParse("100060")))
+	assert.Equal(t, 0, destination.GetBalance().Decimal.Cmp(decimal.MustParse("440")))
+	for _, leg := range []*model.Transaction{debitLeg, creditLeg} {
+		assert.Equal(t, 0, leg.ReversedAmount.Decimal.Cmp(decimal.MustParse("60")))
+		assert.Equal(t, model.SucceededStatus, leg.Status)
+	}
+	assert.Len(t, unitOfWork.Transactions, 4)
+	mockRestClient.AssertNotCalled(t, "PostRequest", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func newReversalContext(t *testing.T, body []byte) (*gin.Context, *httptest.ResponseRecorder) {
+	req, err := http.NewRequest("POST", "/api/v1/bank/transactions/289192938929293/reverse", bytes.NewBuffer(body))
+	if err != nil {
+		t.Fatalf("Error creating request context: %v", err)
+	}
+
+	recorder := httptest.NewRecorder()
+	context, _ := gin.CreateTestContext(recorder)
+	context.Request = req
+	context.Params = append(context.Params, gin.Param{Key: "ref", Value: "289192938929293"})
+	return context, recorder
+}
+
+func getMockReversibleTransaction(transactionType model.TransactionType, status model.TransactionStatus) *model.Transaction {
+	return &model.Transaction{
+		TransactionID:    1,
+		AccountID:        1,
+		Reference:        "ref0",
+		PaymentReference: "289192938929293",
+		Amount:           model.BigDecimal{Decimal: decimal.MustParse("100")},
+		Type:             transactionType,
+		Status:           status,
+		Success:          status == model.SucceededStatus,
+	}
+}
+
+func getReversalRequest(amount *model.BigDecimal) []byte {
+	requestBody, _ := json.Marshal(model.ReversalRequestDTO{Amount: amount, Reason: "customer dispute"})
+	return requestBody
+}
+
+func decimalPointer(value string) *model.BigDecimal {
+	return &model.BigDecimal{Decimal: decimal.MustParse(value)}
+}
@@ -0,0 +1,299 @@
+package bankservice
+
+import (
+	"bankingApp/internal/api/constants"
+	"bankingApp/internal/currency"
+	"bankingApp/internal/model"
+	"bankingApp/internal/utility"
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+const (
+	scheduledTransferBatchSize = 50
+	// scheduledTransferStallTimeout is how long a transfer can stay executing before it is taken to have been
+	// left by a worker that stopped, and is resumed
+	scheduledTransferStallTimeout = 15 * time.Minute
+	// scheduledTransferMaxAttempts is how many times a transfer that failed on an error of our own is tried
+	// before it is failed for good
+	scheduledTransferMaxAttempts = 3
+)
+
+type IScheduledTransferRepository interface {
+	SaveScheduledTransfer(scheduled *model.ScheduledTransfer) error
+	FindScheduledTransfer(id uint) (*model.ScheduledTransfer, error)
+	FindScheduledTransferByReference(reference string) (*model.ScheduledTransfer, error)
+	FindScheduledTransfersByAccount(accountID uint) ([]model.ScheduledTransfer, error)
+	ClaimDueScheduledTransfers(now time.Time, limit int) ([]model.ScheduledTransfer, error)
+	ClaimStalledScheduledTransfers(stalledBefore time.Time, limit int) ([]model.ScheduledTransfer, error)
+	UpdateScheduledTransfer(scheduled *model.ScheduledTransfer) error
+	CancelScheduledTransfer(id uint) (bool, error)
+}
+
+// ScheduledTransferService stores future-dated transfers and executes them once they are due
+// through the same validation and posting path as BankTransferService.Transfer
+type ScheduledTransferService struct {
+	Repository      IScheduledTransferRepository
+	TransferService *BankTransferService
+}
+
+// NewScheduledTransferService creates a new instance of ScheduledTransferService
+func NewScheduledTransferService(
+	repository IScheduledTransferRepository,
+	transferService *BankTransferService) *ScheduledTransferService {
+	return &ScheduledTransferService{Repository: repository, TransferService: transferService}
+}
+
+// Create handles the endpoint for scheduling a transfer. The account and PIN are checked now,
+// the balance is checked when the transfer runs.
+func (s *ScheduledTransferService) Create(c *gin.Context) {
+	var r model.ScheduledTransferRequestDTO
+	if err := c.BindJSON(&r); err != nil {
+		utility.HandleError(c, err, http.StatusBadRequest, constants.InvalidJsonRequestErrorMsg)
+		return
+	}
+
+	if err := s.TransferService.validateTransferRequest(c, r); err != nil {
+		return
+	}
+
+	if !r.ExecuteAt.After(time.Now()) {
+		utility.HandleError(c, nil, http.StatusOK, constants.ScheduleDateNotInFuture)
+		return
+	}
+
+	existing, err := s.Repository.FindScheduledTransferByReference(r.Reference)
+	if err != nil {
+		utility.HandleError(c, err, http.StatusInternalServerError, constants.ApplicationError)
+		return
+	}
+
+	if existing.ScheduledTransferID != constants.Zero {
+		utility.HandleError(c, nil, http.StatusOK, constants.NotUniqueReferenceMsg)
+		return
+	}
+
+	account, tErr := s.TransferService.validateTransferAccount(model.TransactionRequestDTO{TransactionDataDTO: r.TransactionDataDTO}, true)
+	if tErr != nil {
+		utility.HandleError(c, tErr.err, tErr.statusCode, tErr.message)
+		return
+	}
+
+	scheduled := &model.ScheduledTransfer{
+		AccountID:        account.AccountID,
+		AccountNumber:    r.AccountNumber,
+		Username:         r.Username,
+		PaymentReference: r.Reference,
+		Amount:           r.Amount,
+		Currency:         currency.Normalize(r.Currency),
+		Type:             r.Type,
+		ExecuteAt:        r.ExecuteAt,
+		Status:           model.ScheduleActive,
+	}
+	if err := s.Repository.SaveScheduledTransfer(scheduled); err != nil {
+		utility.HandleError(c, err, http.StatusInternalServerError, constants.ApplicationError)
+		return
+	}
+
+	c.JSON(http.StatusOK, utility.FormulateDataResponse(constants.TransferScheduledMsg, scheduledTransferDTO(scheduled)))
+}
+
+// List handles the endpoint listing the scheduled transfers of the account in the account_number query parameter
+func (s *ScheduledTransferService) List(c *gin.Context) {
+	account, err := s.TransferService.AccountRepository.GetAccountByAccountNumber(c.Query("account_number"))
+	if errors.Is(err, gorm.ErrRecordNotFound) || (err == nil && account.AccountID == constants.Zero) {
+		utility.HandleError(c, nil, http.StatusOK, constants.AccountNotFound)
+		return
+	}
+	if err != nil {
+		utility.HandleError(c, err, http.StatusInternalServerError, constants.ApplicationError)
+		return
+	}
+	if !authoriseAccountAccess(c, account) {
+		return
+	}
+
+	scheduled, err := s.Repository.FindScheduledTransfersByAccount(account.AccountID)
+	if err != nil {
+		utility.HandleError(c, err, http.StatusInternalServerError, constants.ApplicationError)
+		return
+	}
+
+	dtos := make([]model.ScheduledTransferDTO, 0, len(scheduled))
+	for i := range scheduled {
+		dtos = append(dtos, scheduledTransferDTO(&scheduled[i]))
+	}
+	c.JSON(http.StatusOK, utility.FormulateDataResponse(constants.ScheduledTransfersFoundMsg, dtos))
+}
+
+// Cancel handles the endpoint cancelling a scheduled transfer that has not run yet.
+// Only the account owner can cancel it, and their PIN is required.
+func (s *ScheduledTransferService) Cancel(c *gin.Context) {
+	var r model.CancelScheduledTransferRequestDTO
+	if err := c.BindJSON(&r); err != nil {
+		utility.HandleError(c, err, http.StatusBadRequest, constants.InvalidJsonRequestErrorMsg)
+		return
+	}
+
+	if err := s.TransferService.validateTransferRequest(c, r); err != nil {
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		utility.HandleError(c, nil, http.StatusOK, constants.ScheduledTransferNotFound)
+		return
+	}
+
+	scheduled, err := s.Repository.FindScheduledTransfer(uint(id))
+	if err != nil {
+		utility.HandleError(c, err, http.StatusInternalServerError, constants.ApplicationError)
+		return
+	}
+
+	if scheduled.ScheduledTransferID == constants.Zero {
+		utility.HandleError(c, nil, http.StatusOK, constants.ScheduledTransferNotFound)
+		return
+	}
+
+	user, _, err := s.TransferService.UserRepository.GetUserAndAccountByAccountNumber(scheduled.AccountNumber)
+	if err != nil {
+		utility.HandleError(c, err, http.StatusInternalServerError, constants.ApplicationError)
+		return
+	}
+	if !authoriseAccountOwner(c, user.UserID) {
+		return
+	}
+
+	if tErr := verifyTransactionPin(s.TransferService.UserRepository, user, r.TransactionPin); tErr != nil {
+		utility.HandleError(c, tErr.err, tErr.statusCode, tErr.message)
+		return
+	}
+
+	cancelled, err := s.Repository.CancelScheduledTransfer(scheduled.ScheduledTransferID)
+	if err != nil {
+		utility.HandleError(c, err, http.StatusInternalServerError, constants.ApplicationError)
+		return
+	}
+
+	if !cancelled {
+		utility.HandleError(c, nil, http.StatusOK, constants.ScheduleNotCancellable)
+		return
+	}
+
+	scheduled.Status = model.ScheduleCancelled
+	c.JSON(http.StatusOK, utility.FormulateDataResponse(constants.ScheduledTransferCancelled, scheduledTransferDTO(scheduled)))
+}
+
+// ExecuteDue executes the scheduled transfers that are due at now and records the outcome of each against its schedule.
+// Transfers left executing by a worker that stopped, on a restart for instance, are resumed first.
+func (s *ScheduledTransferService) ExecuteDue(ctx context.Context, now time.Time) error {
+	stalled, err := s.Repository.ClaimStalledScheduledTransfers(now.Add(-scheduledTransferStallTimeout), scheduledTransferBatchSize)
+	if err != nil {
+		return err
+	}
+
+	for i := range stalled {
+		slog.Warn("resuming stalled scheduled transfer", "scheduled_transfer_id", stalled[i].ScheduledTransferID)
+		s.execute(&stalled[i], true)
+	}
+
+	due, err := s.Repository.ClaimDueScheduledTransfers(now, scheduledTransferBatchSize)
+	if err != nil {
+		return err
+	}
+
+	for i := range due {
+		s.execute(&due[i], false)
+	}
+	return nil
+}
+
+// execute runs one claimed scheduled transfer. When it is resumed or tried again, an earlier attempt may have
+// made its transaction before the outcome was recorded, so the outcome of that transaction is recorded instead
+// of sending the transfer twice.
+func (s *ScheduledTransferService) execute(scheduled *model.ScheduledTransfer, resumed bool) {
+	sent := false
+	if resumed || scheduled.Attempts > 0 {
+		var err error
+		if sent, err = s.recordSentTransfer(scheduled); err != nil {
+			slog.Error("unable to look up scheduled transfer transaction",
+				"scheduled_transfer_id", scheduled.ScheduledTransferID, "error", err)
+			return
+		}
+	}
+	if !sent {
+		s.send(scheduled)
+	}
+
+	if err := s.Repository.UpdateScheduledTransfer(scheduled); err != nil {
+		slog.Error("unable to record scheduled transfer outcome",
+			"scheduled_transfer_id", scheduled.ScheduledTransferID, "error", err)
+	}
+}
+
+// send executes the transfer and sets the schedule status from its outcome. A transfer whose outcome is unknown
+// has been sent, so it counts as executed and its transaction status tells how it ended. A transfer that failed
+// on an error of our own stays active to be tried again on the next run, up to scheduledTransferMaxAttempts.
+func (s *ScheduledTransferService) send(scheduled *model.ScheduledTransfer) {
+	_, tErr := s.TransferService.transfer(scheduled.TransferRequest(), false, model.ScheduledChannel)
+
+	attemptedAt := time.Now()
+	scheduled.Attempts++
+	scheduled.LastAttemptAt = &attemptedAt
+	scheduled.Status = model.ScheduleExecuted
+	scheduled.FailureReason = ""
+	switch {
+	case tErr == nil:
+	case tErr.message == constants.TransactionOutcomeUnknown:
+		scheduled.FailureReason = tErr.message
+	case tErr.statusCode >= http.StatusInternalServerError && scheduled.Attempts < scheduledTransferMaxAttempts:
+		scheduled.Status, scheduled.FailureReason = model.ScheduleActive, tErr.message
+	default:
+		scheduled.Status, scheduled.FailureReason = model.ScheduleFailed, tErr.message
+	}
+}
+
+// recordSentTransfer looks for the transaction the scheduled transfer already made on its account, and sets the
+// schedule status from the status of the transaction. It reports false when the transfer has not been sent.
+func (s *ScheduledTransferService) recordSentTransfer(scheduled *model.ScheduledTransfer) (bool, error) {
+	transaction, err := s.TransferService.TransactionRepository.FindTransactionByReference(scheduled.PaymentReference)
+	if err != nil || transaction.TransactionID == constants.Zero || transaction.AccountID != scheduled.AccountID {
+		return false, err
+	}
+
+	switch transaction.Status {
+	case model.SucceededStatus, model.ReversedStatus:
+		scheduled.Status, scheduled.FailureReason = model.ScheduleExecuted, ""
+	case model.FailedStatus:
+		scheduled.Status, scheduled.FailureReason = model.ScheduleFailed, constants.TransactionRejected
+	default:
+		scheduled.Status, scheduled.FailureReason = model.ScheduleExecuted, constants.TransactionOutcomeUnknown
+	}
+	return true, nil
+}
+
+// scheduledTransferDTO converts a scheduled transfer to its response representation
+func scheduledTransferDTO(scheduled *model.ScheduledTransfer) model.ScheduledTransferDTO {
+	amount := scheduled.Amount
+	return model.ScheduledTransferDTO{
+		ScheduledTransferID: scheduled.ScheduledTransferID,
+		AccountNumber:       scheduled.AccountNumber,
+		PaymentReference:    scheduled.PaymentReference,
+		Amount:              &amount,
+		Currency:            scheduled.Currency,
+		Type:                scheduled.Type,
+		ExecuteAt:           scheduled.ExecuteAt,
+		Status:              scheduled.Status,
+		Attempts:            scheduled.Attempts,
+		FailureReason:       scheduled.FailureReason,
+		LastAttemptAt:       scheduled.LastAttemptAt,
+	}
+}
@@ -0,0 +1,560 @@
+package bankservice //nolint:typecheck
+
+import (
+	"bankingApp/internal/api/constants"
+	"bankingApp/internal/model"
+	"bankingApp/internal/utility"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/govalues/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+type MockScheduledTransferRepository struct{ mock.Mock }
+
+func (m *MockScheduledTransferRepository) SaveScheduledTransfer(scheduled *model.ScheduledTransfer) error {
+	return m.Called(scheduled).Error(0)
+}
+
+func (m *MockScheduledTransferRepository) FindScheduledTransfer(id uint) (*model.ScheduledTransfer, error) {
+	args := m.Called(id)
+	return args.Get(0).(*model.ScheduledTransfer), args.Error(1)
+}
+
+func (m *MockScheduledTransferRepository) FindScheduledTransferByReference(reference string) (*model.ScheduledTransfer, error) {
+	args := m.Called(reference)
+	return args.Get(0).(*model.ScheduledTransfer), args.Error(1)
+}
+
+func (m *MockScheduledTransferRepository) FindScheduledTransfersByAccount(accountID uint) ([]model.ScheduledTransfer, error) {
+	args := m.Called(accountID)
+	return args.Get(0).([]model.ScheduledTransfer), args.Error(1)
+}
+
+func (m *MockScheduledTransferRepository) ClaimDueScheduledTransfers(now time.Time, limit int) ([]model.ScheduledTransfer, error) {
+	args := m.Called(now, limit)
+	return args.Get(0).([]model.ScheduledTransfer), args.Error(1)
+}
+
+func (m *MockScheduledTransferRepository) ClaimStalledScheduledTransfers(
+	stalledBefore time.Time,
+	limit int) ([]model.ScheduledTransfer, error) {
+	args := m.Called(stalledBefore, limit)
+	return args.Get(0).([]model.ScheduledTransfer), args.Error(1)
+}
+
+func (m *MockScheduledTransferRepository) UpdateScheduledTransfer(scheduled *model.ScheduledTransfer) error {
+	return m.Called(scheduled).Error(0)
+}
+
+func (m *MockScheduledTransferRepository) CancelScheduledTransfer(id uint) (bool, error) {
+	args := m.Called(id)
+	return args.Bool(0), args.Error(1)
+}
+
+func Test_CreateScheduledTransfer(t *testing.T) {
+	amount := model.BigDecimal{Decimal: decimal.MustParse("100")}
+	testCases := []struct {
+		name            string
+		requestBody     []byte
+		existing        *model.ScheduledTransfer
+		expectedStatus  int
+		expectedSuccess bool
+		expectedMessage string
+		expectSave      bool
+	}{
+		{
+			name:            "transfer is scheduled",
+			requestBody:     getScheduledTransferRequest("1234", time.Now().Add(time.Hour), amount),
+			existing:        &model.ScheduledTransfer{},
+			expectedStatus:  http.StatusOK,
+			expectedSuccess: true,
+			expectedMessage: constants.TransferScheduledMsg,
+			expectSave:      true,
+		},
+		{
+			name:            "execution date in the past",
+			requestBody:     getScheduledTransferRequest("1234", time.Now().Add(-time.Hour), amount),
+			existing:        &model.ScheduledTransfer{},
+			expectedStatus:  http.StatusOK,
+			expectedMessage: constants.ScheduleDateNotInFuture,
+		},
+		{
+			name:            "payment reference already scheduled",
+			requestBody:     getScheduledTransferRequest("1234", time.Now().Add(time.Hour), amount),
+			existing:        &model.ScheduledTransfer{ScheduledTransferID: 1},
+			expectedStatus:  http.StatusOK,
+			expectedMessage: constants.NotUniqueReferenceMsg,
+		},
+		{
+			name:            "incorrect PIN",
+			requestBody:     getScheduledTransferRequest("4321", time.Now().Add(time.Hour), amount),
+			existing:        &model.ScheduledTransfer{},
+			expectedStatus:  http.StatusOK,
+			expectedMessage: constants.IncorrectTransactionPin,
+		},
+		{
+			name:            "bad request missing execution date",
+			requestBody:     getScheduledTransferRequest("1234", time.Time{}, amount),
+			expectedStatus:  http.StatusBadRequest,
+			expectedMessage: constants.BadRequestMessage,
+		},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			// ------------ setups ------------
+			service, mockRepository, mocks := createScheduledTransferService()
+			gin.SetMode(gin.TestMode)
+
+			// ------------ expectations ------------
+			mockRepository.On("FindScheduledTransferByReference", "289192938929293").Return(tt.existing, nil)
+			mockRepository.On("SaveScheduledTransfer", mock.Anything).Return(nil)
+			mocks.transactionRepository.
+				On("FindTransactionByReference", mock.Anything).Return(getMockNotFoundTransaction(), nil)
+			mocks.userRepository.
+				On("GetUserAndAccountByAccountNumber", mock.Anything).Return(getMockUser(), getMockAccount(), nil)
+			mocks.userRepository.On("RecordFailedPinAttempt", uint(1), mock.Anything).Return(nil)
+
+			// ------------ executions -----------
+			context, recorder := newScheduledTransferContext(t, "POST", "/api/v1/bank/scheduled-transfers", tt.requestBody)
+			service.Create(context)
+
+			var returnedResponse utility.APIDataResponse
+			if jsonErr := json.Unmarshal(recorder.Body.Bytes(), &returnedResponse); jsonErr != nil {
+				t.Fatalf("Error creating test context: %v", jsonErr)
+			}
+
+			// ------------ assertions -----------
+			assert.Equal(t, tt.expectedStatus, recorder.Code)
+			assert.Equal(t, tt.expectedSuccess, returnedResponse.Success)
+			assert.Equal(t, tt.expectedMessage, returnedResponse.Message)
+			if !tt.expectSave {
+				mockRepository.AssertNotCalled(t, "SaveScheduledTransfer", mock.Anything)
+				return
+			}
+
+			saved := mockRepository.Calls[len(mockRepository.Calls)-1].Arguments.Get(0).(*model.ScheduledTransfer)
+			assert.Equal(t, model.ScheduleActive, saved.Status)
+			assert.Equal(t, uint(1), saved.AccountID)
+			assert.Equal(t, "289192938929293", saved.PaymentReference)
+		})
+	}
+}
+
+func Test_ListScheduledTransfers(t *testing.T) {
+	testCases := []struct {
+		name            string
+		account         *model.Account
+		accountError    error
+		userID          uint
+		expectedStatus  int
+		expectedSuccess bool
+		expectedMessage string
+		expectedCount   int
+	}{
+		{
+			name:            "scheduled transfers of the account",
+			account:         getMockAccount(),
+			expectedSuccess: true,
+			expectedStatus:  http.StatusOK,
+			expectedMessage: constants.ScheduledTransfersFoundMsg,
+			expectedCount:   2,
+		},
+		{
+			name:            "account not found",
+			account:         &model.Account{},
+			accountError:    gorm.ErrRecordNotFound,
+			expectedStatus:  http.StatusOK,
+			expectedMessage: constants.AccountNotFound,
+		},
+		{
+			name:            "another customer's account",
+			account:         getMockAccount(),
+			userID:          2,
+			expectedStatus:  http.StatusForbidden,
+			expectedMessage: constants.AccountAccessDenied,
+		},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			// ------------ setups ------------
+			service, mockRepository, mocks := createScheduledTransferService()
+			gin.SetMode(gin.TestMode)
+
+			// ------------ expectations ------------
+			mocks.accountRepository.On("GetAccountByAccountNumber", "1234567890").Return(tt.account, tt.accountError)
+			mockRepository.On("FindScheduledTransfersByAccount", uint(1)).Return([]model.ScheduledTransfer{
+				*getMockScheduledTransfer(1), *getMockScheduledTransfer(2),
+			}, nil)
+
+			// ------------ executions -----------
+			context, recorder := newScheduledTransferContext(t, "GET",
+				"/api/v1/bank/scheduled-transfers?account_number=1234567890", nil)
+			userID := tt.userID
+			if userID == 0 {
+				userID = getMockUser().UserID
+			}
+			authenticate(t, context, "1234567890", userID, model.CustomerRole)
+			service.List(context)
+
+			var returnedResponse struct {
+				utility.APIDataResponse
+				Data []model.ScheduledTransferDTO `json:"data"`
+			}
+			if jsonErr := json.Unmarshal(recorder.Body.Bytes(), &returnedResponse); jsonErr != nil {
+				t.Fatalf("Error creating test context: %v", jsonErr)
+			}
+
+			// ------------ assertions -----------
+			assert.Equal(t, tt.expectedStatus, recorder.Code)
+			assert.Equal(t, tt.expectedSuccess, returnedResponse.Success)
+			assert.Equal(t, tt.expectedMessage, returnedResponse.Message)
+			assert.Len(t, returnedResponse.Data, tt.expectedCount)
+		})
+	}
+}
+
+func Test_CancelScheduledTransfer(t *testing.T) {
+	testCases := []struct {
+		name            string
+		id              string
+		pin             string
+		scheduled       *model.ScheduledTransfer
+		cancelled       bool
+		userID          uint
+		expectedStatus  int
+		expectedSuccess bool
+		expectedMessage string
+	}{
+		{
+			name:            "scheduled transfer is cancelled",
+			id:              "1",
+			pin:             "1234",
+			scheduled:       getMockScheduledTransfer(1),
+			cancelled:       true,
+			expectedSuccess: true,
+			expectedStatus:  http.StatusOK,
+			expectedMessage: constants.ScheduledTransferCancelled,
+		},
+		{
+			name:            "scheduled transfer already ran",
+			id:              "1",
+			pin:             "1234",
+			scheduled:       getMockScheduledTransfer(1),
+			expectedStatus:  http.StatusOK,
+			expectedMessage: constants.ScheduleNotCancellable,
+		},
+		{
+			name:            "incorrect PIN",
+			id:              "1",
+			pin:             "4321",
+			scheduled:       getMockScheduledTransfer(1),
+			cancelled:       true,
+			expectedStatus:  http.StatusOK,
+			expectedMessage: constants.IncorrectTransactionPin,
+		},
+		{
+			name:            "scheduled transfer not found",
+			id:              "1",
+			pin:             "1234",
+			scheduled:       &model.ScheduledTransfer{},
+			expectedStatus:  http.StatusOK,
+			expectedMessage: constants.ScheduledTransferNotFound,
+		},
+		{
+			name:            "invalid scheduled transfer ID",
+			id:              "abc",
+			pin:             "1234",
+			expectedStatus:  http.StatusOK,
+			expectedMessage: constants.ScheduledTransferNotFound,
+		},
+		{
+			name:            "another customer's scheduled transfer",
+			id:              "1",
+			pin:             "1234",
+			scheduled:       getMockScheduledTransfer(1),
+			cancelled:       true,
+			userID:          2,
+			expectedStatus:  http.StatusForbidden,
+			expectedMessage: constants.OwnerAccessDenied,
+		},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			// ------------ setups ------------
+			service, mockRepository, mocks := createScheduledTransferService()
+			gin.SetMode(gin.TestMode)
+
+			// ------------ expectations ------------
+			mockRepository.On("FindScheduledTransfer", uint(1)).Return(tt.scheduled, nil)
+			mockRepository.On("CancelScheduledTransfer", uint(1)).Return(tt.cancelled, nil)
+			mocks.userRepository.
+				On("GetUserAndAccountByAccountNumber", "1234567890").Return(getMockUser(), getMockAccount(), nil)
+			mocks.userRepository.On("RecordFailedPinAttempt", uint(1), mock.Anything).Return(nil)
+
+			// ------------ executions -----------
+			body, _ := json.Marshal(model.CancelScheduledTransferRequestDTO{Username: "johndoe", TransactionPin: tt.pin})
+			context, recorder := newScheduledTransferContext(t, "POST",
+				"/api/v1/bank/scheduled-transfers/"+tt.id+"/cancel", body)
+			context.Params = append(context.Params, gin.Param{Key: "id", Value: tt.id})
+			userID := tt.userID
+			if userID == 0 {
+				userID = getMockUser().UserID
+			}
+			authenticate(t, context, "1234567890", userID, model.CustomerRole)
+			service.Cancel(context)
+
+			var returnedResponse utility.APIDataResponse
+			if jsonErr := json.Unmarshal(recorder.Body.Bytes(), &returnedResponse); jsonErr != nil {
+				t.Fatalf("Error creating test context: %v", jsonErr)
+			}
+
+			// ------------ assertions -----------
+			assert.Equal(t, tt.expectedStatus, recorder.Code)
+			assert.Equal(t, tt.expectedSuccess, returnedResponse.Success)
+			assert.Equal(t, tt.expectedMessage, returnedResponse.Message)
+		})
+	}
+}
+
+func Test_ExecuteDueScheduledTransfers(t *testing.T) {
+	// ------------ setups ------------
+	service, mockRepository, mocks := createScheduledTransferService()
+	account := getMockAccount()
+	service.TransferService.UnitOfWork = &FakeUnitOfWork{Accounts: []*model.Account{account}}
+	now := time.Now()
+
+	affordable := getMockScheduledTransfer(1)
+	unaffordable := getMockScheduledTransfer(2)
+	unaffordable.Amount = model.BigDecimal{Decimal: decimal.MustParse("1000000")}
+
+	// ------------ expectations ------------
+	mockRepository.On("ClaimStalledScheduledTransfers", now.Add(-scheduledTransferStallTimeout), scheduledTransferBatchSize).
+		Return([]model.ScheduledTransfer{}, nil)
+	mockRepository.On("ClaimDueScheduledTransfers", now, scheduledTransferBatchSize).
+		Return([]model.ScheduledTransfer{*affordable, *unaffordable}, nil)
+	mockRepository.On("UpdateScheduledTransfer", mock.Anything).Return(nil)
+	mocks.config.On("ThirdPartyBaseUrl").Return("http://provider")
+	mocks.transactionRepository.
+		On("FindTransactionByReference", mock.Anything).Return(getMockNotFoundTransaction(), nil)
+	mocks.userRepository.
+		On("GetUserAndAccountByAccountNumber", "1234567890").Return(getMockUser(), account, nil)
+	mocks.restClient.
+		On("PostRequest", mock.Anything, mock.Anything, mock.Anything).
+		Return(getSuccessThirdPartyResponse(), http.StatusOK, nil)
+
+	// ------------ executions -----------
+	err := service.ExecuteDue(context.Background(), now)
+
+	// ------------ assertions -----------
+	assert.NoError(t, err)
+	mockRepository.AssertNumberOfCalls(t, "UpdateScheduledTransfer", 2)
+
+	executed := mockRepository.Calls[2].Arguments.Get(0).(*model.ScheduledTransfer)
+	assert.Equal(t, model.ScheduleExecuted, executed.Status)
+	assert.Equal(t, 1, executed.Attempts)
+	assert.Empty(t, executed.FailureReason)
+
+	failed := mockRepository.Calls[3].Arguments.Get(0).(*model.ScheduledTransfer)
+	assert.Equal(t, model.ScheduleFailed, failed.Status)
+	assert.Equal(t, constants.InsufficientFunds, failed.FailureReason)
+	assert.NotNil(t, failed.LastAttemptAt)
+
+	assert.Equal(t, 0, account.GetBalance().Decimal.Cmp(decimal.MustParse("99900")))
+	mocks.restClient.AssertNumberOfCalls(t, "PostRequest", 1)
+}
+
+func Test_ExecuteDueResumesStalledScheduledTransfers(t *testing.T) {
+	testCases := []struct {
+		name             string
+		transaction      *model.Transaction
+		expectedStatus   model.ScheduledTransferStatus
+		expectedReason   string
+		expectedPostings int
+		expectedAttempts int
+		expectedBalance  string
+	}{
+		{
+			name:            "Transfer sent before the worker stopped is recorded without sending it again",
+			transaction:     getMockTransactionWithStatus(model.SucceededStatus),
+			expectedStatus:  model.ScheduleExecuted,
+			expectedBalance: "100000",
+		},
+		{
+			name:            "Transfer rejected before the worker stopped is recorded as failed",
+			transaction:     getMockTransactionWithStatus(model.FailedStatus),
+			expectedStatus:  model.ScheduleFailed,
+			expectedReason:  constants.TransactionRejected,
+			expectedBalance: "100000",
+		},
+		{
+			name:             "Transfer not sent before the worker stopped is sent",
+			transaction:      getMockNotFoundTransaction(),
+			expectedStatus:   model.ScheduleExecuted,
+			expectedPostings: 1,
+			expectedAttempts: 1,
+			expectedBalance:  "99900",
+		},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			// ------------ setups ------------
+			service, mockRepository, mocks := createScheduledTransferService()
+			account := getMockAccount()
+			service.TransferService.UnitOfWork = &FakeUnitOfWork{Accounts: []*model.Account{account}}
+			now := time.Now()
+			stalled := getMockScheduledTransfer(1)
+
+			// ------------ expectations ------------
+			mockRepository.On("ClaimStalledScheduledTransfers", now.Add(-scheduledTransferStallTimeout), scheduledTransferBatchSize).
+				Return([]model.ScheduledTransfer{*stalled}, nil)
+			mockRepository.On("ClaimDueScheduledTransfers", now, scheduledTransferBatchSize).
+				Return([]model.ScheduledTransfer{}, nil)
+			mockRepository.On("UpdateScheduledTransfer", mock.Anything).Return(nil)
+			mocks.config.On("ThirdPartyBaseUrl").Return("http://provider")
+			mocks.transactionRepository.
+				On("FindTransactionByReference", stalled.PaymentReference).Return(tt.transaction, nil)
+			mocks.userRepository.
+				On("GetUserAndAccountByAccountNumber", "1234567890").Return(getMockUser(), account, nil)
+			mocks.restClient.
+				On("PostRequest", mock.Anything, mock.Anything, mock.Anything).
+				Return(getSuccessThirdPartyResponse(), http.StatusOK, nil)
+
+			// ------------ executions -----------
+			require.NoError(t, service.ExecuteDue(context.Background(), now))
+
+			// ------------ assertions -----------
+			mockRepository.AssertNumberOfCalls(t, "UpdateScheduledTransfer", 1)
+			recorded := mockRepository.Calls[1].Arguments.Get(0).(*model.ScheduledTransfer)
+			assert.Equal(t, tt.expectedStatus, recorded.Status)
+			assert.Equal(t, tt.expectedReason, recorded.FailureReason)
+			assert.Equal(t, tt.expectedAttempts, recorded.Attempts)
+			mocks.restClient.AssertNumberOfCalls(t, "PostRequest", tt.expectedPostings)
+			assert.Equal(t, 0, account.GetBalance().Decimal.Cmp(decimal.MustParse(tt.expectedBalance)))
+		})
+	}
+}
+
+func Test_ExecuteDueRetriesScheduledTransfersThatFailedOnOurSide(t *testing.T) {
+	testCases := []struct {
+		name           string
+		attempts       int
+		expectedStatus model.ScheduledTransferStatus
+	}{
+		{name: "First failure leaves the transfer to be tried again", attempts: 0, expectedStatus: model.ScheduleActive},
+		{
+			name:           "Last allowed attempt fails the transfer",
+			attempts:       scheduledTransferMaxAttempts - 1,
+			expectedStatus: model.ScheduleFailed,
+		},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			// ------------ setups ------------
+			service, mockRepository, mocks := createScheduledTransferService()
+			account := getMockAccount()
+			service.TransferService.UnitOfWork = &FakeUnitOfWork{
+				Accounts:     []*model.Account{account},
+				PostingError: errors.New("database unavailable"),
+			}
+			now := time.Now()
+			due := getMockScheduledTransfer(1)
+			due.Attempts = tt.attempts
+
+			// ------------ expectations ------------
+			mockRepository.On("ClaimStalledScheduledTransfers", mock.Anything, scheduledTransferBatchSize).
+				Return([]model.ScheduledTransfer{}, nil)
+			mockRepository.On("ClaimDueScheduledTransfers", now, scheduledTransferBatchSize).
+				Return([]model.ScheduledTransfer{*due}, nil)
+			mockRepository.On("UpdateScheduledTransfer", mock.Anything).Return(nil)
+			mocks.config.On("ThirdPartyBaseUrl").Return("http://provider")
+			mocks.transactionRepository.
+				On("FindTransactionByReference", mock.Anything).Return(getMockNotFoundTransaction(), nil)
+			mocks.userRepository.
+				On("GetUserAndAccountByAccountNumber", "1234567890").Return(getMockUser(), account, nil)
+
+			// ------------ executions -----------
+			require.NoError(t, service.ExecuteDue(context.Background(), now))
+
+			// ------------ assertions -----------
+			recorded := mockRepository.Calls[2].Arguments.Get(0).(*model.ScheduledTransfer)
+			assert.Equal(t, tt.expectedStatus, recorded.Status)
+			assert.Equal(t, tt.attempts+1, recorded.Attempts)
+			assert.Equal(t, constants.ApplicationError, recorded.FailureReason)
+			mocks.restClient.AssertNotCalled(t, "PostRequest", mock.Anything, mock.Anything, mock.Anything)
+		})
+	}
+}
+
+type scheduledTransferMocks struct {
+	config                *MockConfig
+	transactionRepository *MockTransactionRepository
+	userRepository        *MockUserRepository
+	accountRepository     *MockAccountRepository
+	restClient            *MockRestHttpClient
+}
+
+func createScheduledTransferService() (*ScheduledTransferService, *MockScheduledTransferRepository, scheduledTransferMocks) {
+	mockConfig, mockTransactionRepo, mockUserRepo, mockAccountRepo, mockRestClient, _ := setupMocks()
+	mockRepository := new(MockScheduledTransferRepository)
+	transferService := createBankService(mockConfig, mockTransactionRepo, mockUserRepo, mockAccountRepo, mockRestClient)
+	return NewScheduledTransferService(mockRepository, transferService), mockRepository, scheduledTransferMocks{
+		config:                mockConfig,
+		transactionRepository: mockTransactionRepo,
+		userRepository:        mockUserRepo,
+		accountRepository:     mockAccountRepo,
+		restClient:            mockRestClient,
+	}
+}
+
+func newScheduledTransferContext(t *testing.T, method, url string, body []byte) (*gin.Context, *httptest.ResponseRecorder) {
+	req, err := http.NewRequest(method, url, bytes.NewBuffer(body))
+	if err != nil {
+		t.Fatalf("Error creating request context: %v", err)
+	}
+
+	recorder := httptest.NewRecorder()
+	context, _ := gin.CreateTestContext(recorder)
+	context.Request = req
+	return context, recorder
+}
+
+func getMockScheduledTransfer(id uint) *model.ScheduledTransfer {
+	return &model.ScheduledTransfer{
+		ScheduledTransferID: id,
+		AccountID:           1,
+		AccountNumber:       "1234567890",
+		Username:            "johndoe",
+		PaymentReference:    "scheduled" + string(rune('0'+id)),
+		Amount:              model.BigDecimal{Decimal: decimal.MustParse("100")},
+		Type:                model.DebitTransaction,
+		ExecuteAt:           time.Now().Add(-time.Minute),
+		Status:              model.ScheduleExecuting,
+	}
+}
+
+func getScheduledTransferRequest(pin string, executeAt time.Time, amount model.BigDecimal) []byte {
+	requestBody, _ := json.Marshal(model.ScheduledTransferRequestDTO{
+		TransactionDataDTO: model.TransactionDataDTO{
+			AccountNumber:  "1234567890",
+			Username:       "johndoe",
+			TransactionPin: pin,
+			Reference:      "289192938929293",
+			Amount:         amount,
+			Type:           model.DebitTransaction,
+		},
+		ExecuteAt: executeAt,
+	})
+	return requestBody
+}
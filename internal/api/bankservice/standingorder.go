@@ -0,0 +1,484 @@
+package bankservice
+
+import (
+	"bankingApp/internal/api/constants"
+	"bankingApp/internal/currency"
+	"bankingApp/internal/model"
+	"bankingApp/internal/recurrence"
+	"bankingApp/internal/utility"
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+const (
+	standingOrderBatchSize  = 50
+	maxStandingOrderRetries = 10
+	// standingOrderStallTimeout is how long an order can stay executing before it is taken to have been
+	// left by a worker that stopped, and is resumed
+	standingOrderStallTimeout = 15 * time.Minute
+)
+
+type IStandingOrderRepository interface {
+	SaveStandingOrder(order *model.StandingOrder) error
+	FindStandingOrder(id uint) (*model.StandingOrder, error)
+	FindStandingOrderByReference(reference string) (*model.StandingOrder, error)
+	FindStandingOrdersByAccount(accountID uint) ([]model.StandingOrder, error)
+	FindStandingOrderExecutions(id uint) ([]model.StandingOrderExecution, error)
+	ClaimDueStandingOrders(now time.Time, limit int) ([]model.StandingOrder, error)
+	ClaimStalledStandingOrders(stalledBefore time.Time, limit int) ([]model.StandingOrder, error)
+	RecordStandingOrderExecution(order *model.StandingOrder, execution *model.StandingOrderExecution) error
+	CancelStandingOrder(id uint) (bool, error)
+}
+
+// StandingOrderService manages recurring transfers. Each occurrence runs through the same validation and
+// posting path as BankTransferService.Transfer. Recurrence rules are evaluated in UTC.
+type StandingOrderService struct {
+	Repository      IStandingOrderRepository
+	TransferService *BankTransferService
+}
+
+// NewStandingOrderService creates a new instance of StandingOrderService
+func NewStandingOrderService(
+	repository IStandingOrderRepository,
+	transferService *BankTransferService) *StandingOrderService {
+	return &StandingOrderService{Repository: repository, TransferService: transferService}
+}
+
+// Create handles the endpoint for setting up a standing order. The recurrence rule, the account and the PIN
+// are checked now, the balance is checked on every run.
+func (s *StandingOrderService) Create(c *gin.Context) {
+	var r model.StandingOrderRequestDTO
+	if err := c.BindJSON(&r); err != nil {
+		utility.HandleError(c, err, http.StatusBadRequest, constants.InvalidJsonRequestErrorMsg)
+		return
+	}
+
+	if err := s.TransferService.validateTransferRequest(c, r); err != nil {
+		return
+	}
+
+	order := newStandingOrder(r)
+	first, message := validateStandingOrder(order)
+	if message != "" {
+		utility.HandleError(c, nil, http.StatusOK, message)
+		return
+	}
+
+	existing, err := s.Repository.FindStandingOrderByReference(r.Reference)
+	if err != nil {
+		utility.HandleError(c, err, http.StatusInternalServerError, constants.ApplicationError)
+		return
+	}
+
+	if existing.StandingOrderID != constants.Zero {
+		utility.HandleError(c, nil, http.StatusOK, constants.NotUniqueReferenceMsg)
+		return
+	}
+
+	account, tErr := s.TransferService.validateTransferAccount(model.TransactionRequestDTO{TransactionDataDTO: r.TransactionDataDTO}, true)
+	if tErr != nil {
+		utility.HandleError(c, tErr.err, tErr.statusCode, tErr.message)
+		return
+	}
+
+	order.AccountID = account.AccountID
+	order.NextOccurrenceAt, order.NextRunAt = &first, &first
+	if err := s.Repository.SaveStandingOrder(order); err != nil {
+		utility.HandleError(c, err, http.StatusInternalServerError, constants.ApplicationError)
+		return
+	}
+
+	c.JSON(http.StatusOK, utility.FormulateDataResponse(constants.StandingOrderCreatedMsg, standingOrderDTO(order)))
+}
+
+// List handles the endpoint listing the standing orders of the account in the account_number query parameter
+func (s *StandingOrderService) List(c *gin.Context) {
+	account, err := s.TransferService.AccountRepository.GetAccountByAccountNumber(c.Query("account_number"))
+	if errors.Is(err, gorm.ErrRecordNotFound) || (err == nil && account.AccountID == constants.Zero) {
+		utility.HandleError(c, nil, http.StatusOK, constants.AccountNotFound)
+		return
+	}
+	if err != nil {
+		utility.HandleError(c, err, http.StatusInternalServerError, constants.ApplicationError)
+		return
+	}
+	if !authoriseAccountAccess(c, account) {
+		return
+	}
+
+	orders, err := s.Repository.FindStandingOrdersByAccount(account.AccountID)
+	if err != nil {
+		utility.HandleError(c, err, http.StatusInternalServerError, constants.ApplicationError)
+		return
+	}
+
+	dtos := make([]model.StandingOrderDTO, 0, len(orders))
+	for i := range orders {
+		dtos = append(dtos, standingOrderDTO(&orders[i]))
+	}
+	c.JSON(http.StatusOK, utility.FormulateDataResponse(constants.StandingOrdersFoundMsg, dtos))
+}
+
+// Executions handles the endpoint listing the execution history of a standing order, oldest first
+func (s *StandingOrderService) Executions(c *gin.Context) {
+	order, done := s.findStandingOrder(c)
+	if done {
+		return
+	}
+	if !authoriseAccountNumberAccess(c, s.TransferService.AccountRepository, order.AccountNumber) {
+		return
+	}
+
+	executions, err := s.Repository.FindStandingOrderExecutions(order.StandingOrderID)
+	if err != nil {
+		utility.HandleError(c, err, http.StatusInternalServerError, constants.ApplicationError)
+		return
+	}
+
+	dtos := make([]model.StandingOrderExecutionDTO, 0, len(executions))
+	for _, e := range executions {
+		dtos = append(dtos, model.StandingOrderExecutionDTO{
+			Occurrence:       e.Occurrence,
+			Attempt:          e.Attempt,
+			ScheduledFor:     e.ScheduledFor,
+			PaymentReference: e.PaymentReference,
+			Outcome:          e.Outcome,
+			Reason:           e.Reason,
+			ExecutedAt:       e.ExecutedAt,
+		})
+	}
+	c.JSON(http.StatusOK, utility.FormulateDataResponse(constants.StandingOrderRunsFoundMsg, dtos))
+}
+
+// Cancel handles the endpoint cancelling an active standing order. Only the account owner can cancel it,
+// and their PIN is required.
+func (s *StandingOrderService) Cancel(c *gin.Context) {
+	var r model.CancelStandingOrderRequestDTO
+	if err := c.BindJSON(&r); err != nil {
+		utility.HandleError(c, err, http.StatusBadRequest, constants.InvalidJsonRequestErrorMsg)
+		return
+	}
+
+	if err := s.TransferService.validateTransferRequest(c, r); err != nil {
+		return
+	}
+
+	order, done := s.findStandingOrder(c)
+	if done {
+		return
+	}
+
+	user, _, err := s.TransferService.UserRepository.GetUserAndAccountByAccountNumber(order.AccountNumber)
+	if err != nil {
+		utility.HandleError(c, err, http.StatusInternalServerError, constants.ApplicationError)
+		return
+	}
+	if !authoriseAccountOwner(c, user.UserID) {
+		return
+	}
+
+	if tErr := verifyTransactionPin(s.TransferService.UserRepository, user, r.TransactionPin); tErr != nil {
+		utility.HandleError(c, tErr.err, tErr.statusCode, tErr.message)
+		return
+	}
+
+	cancelled, err := s.Repository.CancelStandingOrder(order.StandingOrderID)
+	if err != nil {
+		utility.HandleError(c, err, http.StatusInternalServerError, constants.ApplicationError)
+		return
+	}
+
+	if !cancelled {
+		utility.HandleError(c, nil, http.StatusOK, constants.StandingOrderNotCancellable)
+		return
+	}
+
+	order.Status, order.NextRunAt = model.StandingOrderCancelled, nil
+	c.JSON(http.StatusOK, utility.FormulateDataResponse(constants.StandingOrderCancelled, standingOrderDTO(order)))
+}
+
+// findStandingOrder loads the standing order in the id path parameter, writing the response when there is none
+func (s *StandingOrderService) findStandingOrder(c *gin.Context) (*model.StandingOrder, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		utility.HandleError(c, nil, http.StatusOK, constants.StandingOrderNotFound)
+		return nil, true
+	}
+
+	order, err := s.Repository.FindStandingOrder(uint(id))
+	if err != nil {
+		utility.HandleError(c, err, http.StatusInternalServerError, constants.ApplicationError)
+		return nil, true
+	}
+
+	if order.StandingOrderID == constants.Zero {
+		utility.HandleError(c, nil, http.StatusOK, constants.StandingOrderNotFound)
+		return nil, true
+	}
+	return order, false
+}
+
+// ExecuteDue runs the standing orders that are due at now and records every attempt in their execution history.
+// Orders left executing by a worker that stopped, on a restart for instance, are resumed first.
+func (s *StandingOrderService) ExecuteDue(ctx context.Context, now time.Time) error {
+	stalled, err := s.Repository.ClaimStalledStandingOrders(now.Add(-standingOrderStallTimeout), standingOrderBatchSize)
+	if err != nil {
+		return err
+	}
+
+	for i := range stalled {
+		slog.Warn("resuming stalled standing order", "standing_order_id", stalled[i].StandingOrderID)
+		s.run(&stalled[i], now, true)
+	}
+
+	due, err := s.Repository.ClaimDueStandingOrders(now, standingOrderBatchSize)
+	if err != nil {
+		return err
+	}
+
+	for i := range due {
+		s.run(&due[i], now, false)
+	}
+	return nil
+}
+
+// run executes the current occurrence of a claimed standing order. When the order is resumed, the worker may have
+// stopped between sending the transfer of the occurrence and recording it, so the outcome of the transaction it
+// already made is recorded instead of sending the transfer twice. An order whose next run cannot be calculated
+// is failed rather than completed, and its occurrence is not sent.
+func (s *StandingOrderService) run(order *model.StandingOrder, now time.Time, resumed bool) {
+	occurrence := order.Occurrences + 1
+	execution := &model.StandingOrderExecution{
+		StandingOrderID:  order.StandingOrderID,
+		Occurrence:       occurrence,
+		Attempt:          order.RetryCount + 1,
+		ScheduledFor:     *order.NextOccurrenceAt,
+		PaymentReference: order.OccurrenceReference(occurrence),
+		Outcome:          model.StandingOrderRunExecuted,
+	}
+
+	sent := false
+	if resumed {
+		var err error
+		if sent, err = s.recordSentTransfer(order, execution); err != nil {
+			slog.Error("unable to look up standing order transaction",
+				"standing_order_id", order.StandingOrderID, "error", err)
+			return
+		}
+	}
+
+	following, err := standingOrderRule(order).Next(order.NextOccurrenceAt.UTC())
+	switch {
+	case err != nil:
+		slog.Error("unable to calculate next standing order run",
+			"standing_order_id", order.StandingOrderID, "error", err)
+		if !sent {
+			execution.Outcome, execution.Reason = model.StandingOrderRunFailed, constants.StandingOrderNextRunFailed
+		}
+		failStandingOrder(order, constants.StandingOrderNextRunFailed)
+	case !sent:
+		s.send(order, execution, now, following)
+	}
+	execution.ExecutedAt = time.Now()
+
+	switch {
+	case order.Status == model.StandingOrderFailed:
+	case execution.Outcome == model.StandingOrderRunRetryPlanned:
+		retryAt := now.Add(order.RetryWait())
+		order.RetryCount++
+		order.NextRunAt = &retryAt
+		order.Status = model.StandingOrderActive
+	default:
+		advanceStandingOrder(order, following)
+	}
+
+	if err := s.Repository.RecordStandingOrderExecution(order, execution); err != nil {
+		slog.Error("unable to record standing order execution",
+			"standing_order_id", order.StandingOrderID, "error", err)
+	}
+}
+
+// send executes the transfer of the current occurrence and sets the outcome of the execution. An occurrence the
+// account cannot pay for is retried if the order allows it and the retry comes before the next occurrence,
+// otherwise it is skipped. A transfer whose outcome is unknown has been sent, so it counts as executed.
+func (s *StandingOrderService) send(
+	order *model.StandingOrder,
+	execution *model.StandingOrderExecution,
+	now time.Time,
+	following time.Time) {
+	_, tErr := s.TransferService.transfer(order.TransferRequest(execution.Occurrence), false, model.StandingOrderChannel)
+	if tErr == nil {
+		return
+	}
+
+	execution.Reason = tErr.message
+	switch {
+	case tErr.message == constants.TransactionOutcomeUnknown:
+	case tErr.message == constants.InsufficientFunds && canRetry(order, now, following):
+		execution.Outcome = model.StandingOrderRunRetryPlanned
+	case tErr.message == constants.InsufficientFunds:
+		execution.Outcome = model.StandingOrderRunSkipped
+	default:
+		execution.Outcome = model.StandingOrderRunFailed
+	}
+}
+
+// recordSentTransfer looks for the transaction the current occurrence already made on the account of the order,
+// and sets the outcome of the execution from its status. It reports false when the occurrence has not been sent.
+func (s *StandingOrderService) recordSentTransfer(
+	order *model.StandingOrder,
+	execution *model.StandingOrderExecution) (bool, error) {
+	transaction, err := s.TransferService.TransactionRepository.FindTransactionByReference(execution.PaymentReference)
+	if err != nil || transaction.TransactionID == constants.Zero || transaction.AccountID != order.AccountID {
+		return false, err
+	}
+
+	switch transaction.Status {
+	case model.SucceededStatus, model.ReversedStatus:
+	case model.FailedStatus:
+		execution.Outcome, execution.Reason = model.StandingOrderRunFailed, constants.TransactionRejected
+	default:
+		execution.Reason = constants.TransactionOutcomeUnknown
+	}
+	return true, nil
+}
+
+// canRetry reports whether an occurrence that failed for insufficient funds gets another attempt
+func canRetry(order *model.StandingOrder, now time.Time, following time.Time) bool {
+	if order.OnInsufficientFunds != model.RetryOnInsufficientFunds || order.RetryCount >= order.MaxRetries {
+		return false
+	}
+	return following.IsZero() || now.Add(order.RetryWait()).Before(following)
+}
+
+// advanceStandingOrder finishes the current occurrence and moves the order on to the following one,
+// completing the order once its end date or number of occurrences is reached
+func advanceStandingOrder(order *model.StandingOrder, following time.Time) {
+	order.Occurrences++
+	order.RetryCount = 0
+
+	finished := following.IsZero() ||
+		(order.MaxOccurrences > 0 && order.Occurrences >= order.MaxOccurrences) ||
+		(order.EndAt != nil && following.After(*order.EndAt))
+	if finished {
+		order.Status = model.StandingOrderCompleted
+		order.NextOccurrenceAt, order.NextRunAt = nil, nil
+		return
+	}
+
+	order.Status = model.StandingOrderActive
+	order.NextOccurrenceAt, order.NextRunAt = &following, &following
+}
+
+// failStandingOrder stops the order from running again, keeping the reason it failed for
+func failStandingOrder(order *model.StandingOrder, reason string) {
+	order.Occurrences++
+	order.RetryCount = 0
+	order.Status = model.StandingOrderFailed
+	order.FailureReason = reason
+	order.NextOccurrenceAt, order.NextRunAt = nil, nil
+}
+
+// newStandingOrder creates the standing order of a request, filling in the defaults of the optional fields
+func newStandingOrder(r model.StandingOrderRequestDTO) *model.StandingOrder {
+	order := &model.StandingOrder{
+		AccountNumber:        r.AccountNumber,
+		Username:             r.Username,
+		Reference:            r.Reference,
+		Amount:               r.Amount,
+		Currency:             currency.Normalize(r.Currency),
+		Type:                 r.Type,
+		Frequency:            r.Frequency,
+		Interval:             r.Interval,
+		CronExpression:       r.CronExpression,
+		StartAt:              r.StartAt.UTC(),
+		EndAt:                r.EndAt,
+		MaxOccurrences:       r.MaxOccurrences,
+		OnInsufficientFunds:  r.OnInsufficientFunds,
+		MaxRetries:           r.MaxRetries,
+		RetryIntervalMinutes: r.RetryIntervalMinutes,
+		Status:               model.StandingOrderActive,
+	}
+	if order.Interval == constants.Zero && recurrence.Frequency(order.Frequency) != recurrence.Cron {
+		order.Interval = 1
+	}
+	if order.OnInsufficientFunds == "" {
+		order.OnInsufficientFunds = model.SkipOnInsufficientFunds
+	}
+	return order
+}
+
+// validateStandingOrder checks the recurrence and retry settings of a new standing order. It returns the first run
+// time, or the message to reject the order with.
+func validateStandingOrder(order *model.StandingOrder) (time.Time, string) {
+	if !order.StartAt.After(time.Now()) {
+		return time.Time{}, constants.StartDateNotInFuture
+	}
+
+	if order.MaxOccurrences < constants.Zero {
+		return time.Time{}, constants.InvalidMaxOccurrences
+	}
+
+	switch order.OnInsufficientFunds {
+	case model.SkipOnInsufficientFunds, model.RetryOnInsufficientFunds:
+	default:
+		return time.Time{}, constants.InvalidFundsPolicy
+	}
+
+	if order.MaxRetries < constants.Zero || order.MaxRetries > maxStandingOrderRetries || order.RetryIntervalMinutes < constants.Zero {
+		return time.Time{}, constants.InvalidRetryPolicy
+	}
+
+	first, err := standingOrderRule(order).First()
+	if err != nil {
+		return time.Time{}, err.Error()
+	}
+
+	if order.EndAt != nil && order.EndAt.Before(first) {
+		return time.Time{}, constants.EndDateBeforeFirstRun
+	}
+	return first, ""
+}
+
+// standingOrderRule returns the recurrence rule of a standing order
+func standingOrderRule(order *model.StandingOrder) recurrence.Rule {
+	return recurrence.Rule{
+		Frequency:  recurrence.Frequency(order.Frequency),
+		Interval:   order.Interval,
+		Expression: order.CronExpression,
+		Start:      order.StartAt.UTC(),
+	}
+}
+
+// standingOrderDTO converts a standing order to its response representation
+func standingOrderDTO(order *model.StandingOrder) model.StandingOrderDTO {
+	amount := order.Amount
+	return model.StandingOrderDTO{
+		StandingOrderID:      order.StandingOrderID,
+		AccountNumber:        order.AccountNumber,
+		Reference:            order.Reference,
+		Amount:               &amount,
+		Currency:             order.Currency,
+		Type:                 order.Type,
+		Frequency:            order.Frequency,
+		Interval:             order.Interval,
+		CronExpression:       order.CronExpression,
+		StartAt:              order.StartAt,
+		EndAt:                order.EndAt,
+		MaxOccurrences:       order.MaxOccurrences,
+		OnInsufficientFunds:  order.OnInsufficientFunds,
+		MaxRetries:           order.MaxRetries,
+		RetryIntervalMinutes: order.RetryIntervalMinutes,
+		Occurrences:          order.Occurrences,
+		NextRunAt:            order.NextRunAt,
+		Status:               order.Status,
+		FailureReason:        order.FailureReason,
+	}
+}
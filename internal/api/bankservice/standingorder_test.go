@@ -0,0 +1,549 @@
+package bankservice //nolint:typecheck
+
+import (
+	"bankingApp/internal/api/constants"
+	"bankingApp/internal/model"
+	"bankingApp/internal/utility"
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/govalues/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type MockStandingOrderRepository struct{ mock.Mock }
+
+func (m *MockStandingOrderRepository) SaveStandingOrder(order *model.StandingOrder) error {
+	return m.Called(order).Error(0)
+}
+
+func (m *MockStandingOrderRepository) FindStandingOrder(id uint) (*model.StandingOrder, error) {
+	args := m.Called(id)
+	return args.Get(0).(*model.StandingOrder), args.Error(1)
+}
+
+func (m *MockStandingOrderRepository) FindStandingOrderByReference(reference string) (*model.StandingOrder, error) {
+	args := m.Called(reference)
+	return args.Get(0).(*model.StandingOrder), args.Error(1)
+}
+
+func (m *MockStandingOrderRepository) FindStandingOrdersByAccount(accountID uint) ([]model.StandingOrder, error) {
+	args := m.Called(accountID)
+	return args.Get(0).([]model.StandingOrder), args.Error(1)
+}
+
+func (m *MockStandingOrderRepository) FindStandingOrderExecutions(id uint) ([]model.StandingOrderExecution, error) {
+	args := m.Called(id)
+	return args.Get(0).([]model.StandingOrderExecution), args.Error(1)
+}
+
+func (m *MockStandingOrderRepository) ClaimDueStandingOrders(now time.Time, limit int) ([]model.StandingOrder, error) {
+	args := m.Called(now, limit)
+	return args.Get(0).([]model.StandingOrder), args.Error(1)
+}
+
+func (m *MockStandingOrderRepository) ClaimStalledStandingOrders(
+	stalledBefore time.Time,
+	limit int) ([]model.StandingOrder, error) {
+	args := m.Called(stalledBefore, limit)
+	return args.Get(0).([]model.StandingOrder), args.Error(1)
+}
+
+func (m *MockStandingOrderRepository) RecordStandingOrderExecution(
+	order *model.StandingOrder,
+	execution *model.StandingOrderExecution) error {
+	return m.Called(order, execution).Error(0)
+}
+
+func (m *MockStandingOrderRepository) CancelStandingOrder(id uint) (bool, error) {
+	args := m.Called(id)
+	return args.Bool(0), args.Error(1)
+}
+
+func Test_CreateStandingOrder(t *testing.T) {
+	start := time.Now().Add(24 * time.Hour).UTC().Truncate(time.Minute)
+	testCases := []struct {
+		name            string
+		request         model.StandingOrderRequestDTO
+		existing        *model.StandingOrder
+		expectedStatus  int
+		expectedSuccess bool
+		expectedMessage string
+		expectedNextRun time.Time
+	}{
+		{
+			name:            "monthly standing order",
+			request:         getStandingOrderRequest(start, "monthly", ""),
+			existing:        &model.StandingOrder{},
+			expectedStatus:  http.StatusOK,
+			expectedSuccess: true,
+			expectedMessage: constants.StandingOrderCreatedMsg,
+			expectedNextRun: start,
+		},
+		{
+			name:            "cron standing order runs at the first match after the start",
+			request:         getStandingOrderRequest(start, "cron", "0 0 1 * *"),
+			existing:        &model.StandingOrder{},
+			expectedStatus:  http.StatusOK,
+			expectedSuccess: true,
+			expectedMessage: constants.StandingOrderCreatedMsg,
+			expectedNextRun: time.Date(start.Year(), start.Month()+1, 1, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:            "invalid cron expression",
+			request:         getStandingOrderRequest(start, "cron", "0 25 * * *"),
+			existing:        &model.StandingOrder{},
+			expectedStatus:  http.StatusOK,
+			expectedMessage: `cron expression is invalid: hour "25" is out of range 0-23`,
+		},
+		{
+			name:            "unknown frequency",
+			request:         getStandingOrderRequest(start, "yearly", ""),
+			existing:        &model.StandingOrder{},
+			expectedStatus:  http.StatusOK,
+			expectedMessage: "frequency must be one of daily, weekly, monthly or cron",
+		},
+		{
+			name:            "start date in the past",
+			request:         getStandingOrderRequest(time.Now().Add(-time.Hour), "daily", ""),
+			existing:        &model.StandingOrder{},
+			expectedStatus:  http.StatusOK,
+			expectedMessage: constants.StartDateNotInFuture,
+		},
+		{
+			name: "end date before the first run",
+			request: func() model.StandingOrderRequestDTO {
+				r := getStandingOrderRequest(start, "daily", "")
+				end := start.Add(-time.Minute)
+				r.EndAt = &end
+				return r
+			}(),
+			existing:        &model.StandingOrder{},
+			expectedStatus:  http.StatusOK,
+			expectedMessage: constants.EndDateBeforeFirstRun,
+		},
+		{
+			name: "too many retries",
+			request: func() model.StandingOrderRequestDTO {
+				r := getStandingOrderRequest(start, "daily", "")
+				r.OnInsufficientFunds, r.MaxRetries = model.RetryOnInsufficientFunds, 11
+				return r
+			}(),
+			existing:        &model.StandingOrder{},
+			expectedStatus:  http.StatusOK,
+			expectedMessage: constants.InvalidRetryPolicy,
+		},
+		{
+			name: "unknown insufficient funds policy",
+			request: func() model.StandingOrderRequestDTO {
+				r := getStandingOrderRequest(start, "daily", "")
+				r.OnInsufficientFunds = "wait"
+				return r
+			}(),
+			existing:        &model.StandingOrder{},
+			expectedStatus:  http.StatusOK,
+			expectedMessage: constants.InvalidFundsPolicy,
+		},
+		{
+			name:            "payment reference already used by a standing order",
+			request:         getStandingOrderRequest(start, "monthly", ""),
+			existing:        &model.StandingOrder{StandingOrderID: 1},
+			expectedStatus:  http.StatusOK,
+			expectedMessage: constants.NotUniqueReferenceMsg,
+		},
+		{
+			name: "incorrect PIN",
+			request: func() model.StandingOrderRequestDTO {
+				r := getStandingOrderRequest(start, "monthly", "")
+				r.TransactionPin = "4321"
+				return r
+			}(),
+			existing:        &model.StandingOrder{},
+			expectedStatus:  http.StatusOK,
+			expectedMessage: constants.IncorrectTransactionPin,
+		},
+		{
+			name: "bad request missing start date",
+			request: func() model.StandingOrderRequestDTO {
+				r := getStandingOrderRequest(start, "monthly", "")
+				r.StartAt = time.Time{}
+				return r
+			}(),
+			expectedStatus:  http.StatusBadRequest,
+			expectedMessage: constants.BadRequestMessage,
+		},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			// ------------ setups ------------
+			service, mockRepository, mocks := createStandingOrderService()
+			gin.SetMode(gin.TestMode)
+
+			// ------------ expectations ------------
+			mockRepository.On("FindStandingOrderByReference", "rent").Return(tt.existing, nil)
+			mockRepository.On("SaveStandingOrder", mock.Anything).Return(nil)
+			mocks.transactionRepository.
+				On("FindTransactionByReference", mock.Anything).Return(getMockNotFoundTransaction(), nil)
+			mocks.userRepository.
+				On("GetUserAndAccountByAccountNumber", mock.Anything).Return(getMockUser(), getMockAccount(), nil)
+			mocks.userRepository.On("RecordFailedPinAttempt", uint(1), mock.Anything).Return(nil)
+
+			// ------------ executions -----------
+			body, _ := json.Marshal(tt.request)
+			context, recorder := newScheduledTransferContext(t, "POST", "/api/v1/bank/standing-orders", body)
+			service.Create(context)
+
+			var returnedResponse utility.APIDataResponse
+			if jsonErr := json.Unmarshal(recorder.Body.Bytes(), &returnedResponse); jsonErr != nil {
+				t.Fatalf("Error creating test context: %v", jsonErr)
+			}
+
+			// ------------ assertions -----------
+			assert.Equal(t, tt.expectedStatus, recorder.Code)
+			assert.Equal(t, tt.expectedSuccess, returnedResponse.Success)
+			assert.Equal(t, tt.expectedMessage, returnedResponse.Message)
+			if !tt.expectedSuccess {
+				mockRepository.AssertNotCalled(t, "SaveStandingOrder", mock.Anything)
+				return
+			}
+
+			saved := mockRepository.Calls[len(mockRepository.Calls)-1].Arguments.Get(0).(*model.StandingOrder)
+			assert.Equal(t, model.StandingOrderActive, saved.Status)
+			assert.Equal(t, model.SkipOnInsufficientFunds, saved.OnInsufficientFunds)
+			assert.Equal(t, tt.expectedNextRun, *saved.NextRunAt)
+			assert.Equal(t, tt.expectedNextRun, *saved.NextOccurrenceAt)
+		})
+	}
+}
+
+func Test_CancelStandingOrder(t *testing.T) {
+	testCases := []struct {
+		name            string
+		id              string
+		order           *model.StandingOrder
+		cancelled       bool
+		userID          uint
+		expectedStatus  int
+		expectedSuccess bool
+		expectedMessage string
+	}{
+		{
+			name:            "standing order is cancelled",
+			id:              "1",
+			order:           getMockStandingOrder(model.SkipOnInsufficientFunds),
+			cancelled:       true,
+			expectedSuccess: true,
+			expectedStatus:  http.StatusOK,
+			expectedMessage: constants.StandingOrderCancelled,
+		},
+		{
+			name:            "standing order is not active",
+			id:              "1",
+			order:           getMockStandingOrder(model.SkipOnInsufficientFunds),
+			expectedStatus:  http.StatusOK,
+			expectedMessage: constants.StandingOrderNotCancellable,
+		},
+		{
+			name:            "standing order not found",
+			id:              "1",
+			order:           &model.StandingOrder{},
+			expectedStatus:  http.StatusOK,
+			expectedMessage: constants.StandingOrderNotFound,
+		},
+		{
+			name:            "invalid standing order ID",
+			id:              "abc",
+			expectedStatus:  http.StatusOK,
+			expectedMessage: constants.StandingOrderNotFound,
+		},
+		{
+			name:            "another customer's standing order",
+			id:              "1",
+			order:           getMockStandingOrder(model.SkipOnInsufficientFunds),
+			cancelled:       true,
+			userID:          2,
+			expectedStatus:  http.StatusForbidden,
+			expectedMessage: constants.OwnerAccessDenied,
+		},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			// ------------ setups ------------
+			service, mockRepository, mocks := createStandingOrderService()
+			gin.SetMode(gin.TestMode)
+
+			// ------------ expectations ------------
+			mockRepository.On("FindStandingOrder", uint(1)).Return(tt.order, nil)
+			mockRepository.On("CancelStandingOrder", uint(1)).Return(tt.cancelled, nil)
+			mocks.userRepository.
+				On("GetUserAndAccountByAccountNumber", "1234567890").Return(getMockUser(), getMockAccount(), nil)
+			mocks.userRepository.On("RecordFailedPinAttempt", uint(1), mock.Anything).Return(nil)
+
+			// ------------ executions -----------
+			body, _ := json.Marshal(model.CancelStandingOrderRequestDTO{Username: "johndoe", TransactionPin: "1234"})
+			context, recorder := newScheduledTransferContext(t, "POST",
+				"/api/v1/bank/standing-orders/"+tt.id+"/cancel", body)
+			context.Params = append(context.Params, gin.Param{Key: "id", Value: tt.id})
+			userID := tt.userID
+			if userID == 0 {
+				userID = getMockUser().UserID
+			}
+			authenticate(t, context, "1234567890", userID, model.CustomerRole)
+			service.Cancel(context)
+
+			var returnedResponse utility.APIDataResponse
+			if jsonErr := json.Unmarshal(recorder.Body.Bytes(), &returnedResponse); jsonErr != nil {
+				t.Fatalf("Error creating test context: %v", jsonErr)
+			}
+
+			// ------------ assertions -----------
+			assert.Equal(t, tt.expectedStatus, recorder.Code)
+			assert.Equal(t, tt.expectedSuccess, returnedResponse.Success)
+			assert.Equal(t, tt.expectedMessage, returnedResponse.Message)
+		})
+	}
+}
+
+func Test_ExecuteDueStandingOrders(t *testing.T) {
+	occurrenceAt := time.Date(2024, 3, 1, 6, 0, 0, 0, time.UTC)
+	now := occurrenceAt.Add(time.Minute)
+	testCases := []struct {
+		name               string
+		order              *model.StandingOrder
+		amount             string
+		expectedOutcome    model.StandingOrderOutcome
+		expectedStatus     model.StandingOrderStatus
+		expectedOccurrence int
+		expectedRetries    int
+		expectedNextRun    *time.Time
+		expectedBalance    string
+		// stalled orders are resumed after a worker stopped, with the transaction their occurrence already made
+		stalled     bool
+		transaction *model.Transaction
+	}{
+		{
+			name:               "occurrence is paid and the order moves to the next month",
+			order:              getMockStandingOrder(model.SkipOnInsufficientFunds),
+			amount:             "100",
+			expectedOutcome:    model.StandingOrderRunExecuted,
+			expectedStatus:     model.StandingOrderActive,
+			expectedOccurrence: 1,
+			expectedNextRun:    timePointer(occurrenceAt.AddDate(0, 1, 0)),
+			expectedBalance:    "99900",
+		},
+		{
+			name:               "occurrence the account cannot pay for is skipped",
+			order:              getMockStandingOrder(model.SkipOnInsufficientFunds),
+			amount:             "1000000",
+			expectedOutcome:    model.StandingOrderRunSkipped,
+			expectedStatus:     model.StandingOrderActive,
+			expectedOccurrence: 1,
+			expectedNextRun:    timePointer(occurrenceAt.AddDate(0, 1, 0)),
+			expectedBalance:    "100000",
+		},
+		{
+			name:            "occurrence the account cannot pay for is retried",
+			order:           getMockStandingOrder(model.RetryOnInsufficientFunds),
+			amount:          "1000000",
+			expectedOutcome: model.StandingOrderRunRetryPlanned,
+			expectedStatus:  model.StandingOrderActive,
+			expectedRetries: 1,
+			expectedNextRun: timePointer(now.Add(2 * time.Hour)),
+			expectedBalance: "100000",
+		},
+		{
+			name: "occurrence is skipped once the retries are used up",
+			order: func() *model.StandingOrder {
+				order := getMockStandingOrder(model.RetryOnInsufficientFunds)
+				order.RetryCount = 3
+				return order
+			}(),
+			amount:             "1000000",
+			expectedOutcome:    model.StandingOrderRunSkipped,
+			expectedStatus:     model.StandingOrderActive,
+			expectedOccurrence: 1,
+			expectedNextRun:    timePointer(occurrenceAt.AddDate(0, 1, 0)),
+			expectedBalance:    "100000",
+		},
+		{
+			name: "last occurrence completes the order",
+			order: func() *model.StandingOrder {
+				order := getMockStandingOrder(model.SkipOnInsufficientFunds)
+				order.Occurrences, order.MaxOccurrences = 11, 12
+				return order
+			}(),
+			amount:             "100",
+			expectedOutcome:    model.StandingOrderRunExecuted,
+			expectedStatus:     model.StandingOrderCompleted,
+			expectedOccurrence: 12,
+			expectedBalance:    "99900",
+		},
+		{
+			name: "order completes when the next run is after the end date",
+			order: func() *model.StandingOrder {
+				order := getMockStandingOrder(model.SkipOnInsufficientFunds)
+				order.EndAt = timePointer(occurrenceAt.AddDate(0, 0, 15))
+				return order
+			}(),
+			amount:             "100",
+			expectedOutcome:    model.StandingOrderRunExecuted,
+			expectedStatus:     model.StandingOrderCompleted,
+			expectedOccurrence: 1,
+			expectedBalance:    "99900",
+		},
+		{
+			name: "order whose next run cannot be calculated is failed without paying the occurrence",
+			order: func() *model.StandingOrder {
+				order := getMockStandingOrder(model.SkipOnInsufficientFunds)
+				order.Frequency = "yearly"
+				return order
+			}(),
+			amount:             "100",
+			expectedOutcome:    model.StandingOrderRunFailed,
+			expectedStatus:     model.StandingOrderFailed,
+			expectedOccurrence: 1,
+			expectedBalance:    "100000",
+		},
+		{
+			name:               "stalled occurrence that was paid is recorded without paying it again",
+			order:              getMockStandingOrder(model.SkipOnInsufficientFunds),
+			amount:             "100",
+			stalled:            true,
+			transaction:        getMockTransactionWithStatus(model.SucceededStatus),
+			expectedOutcome:    model.StandingOrderRunExecuted,
+			expectedStatus:     model.StandingOrderActive,
+			expectedOccurrence: 1,
+			expectedNextRun:    timePointer(occurrenceAt.AddDate(0, 1, 0)),
+			expectedBalance:    "100000",
+		},
+		{
+			name:               "stalled occurrence that was not sent is paid",
+			order:              getMockStandingOrder(model.SkipOnInsufficientFunds),
+			amount:             "100",
+			stalled:            true,
+			expectedOutcome:    model.StandingOrderRunExecuted,
+			expectedStatus:     model.StandingOrderActive,
+			expectedOccurrence: 1,
+			expectedNextRun:    timePointer(occurrenceAt.AddDate(0, 1, 0)),
+			expectedBalance:    "99900",
+		},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			// ------------ setups ------------
+			service, mockRepository, mocks := createStandingOrderService()
+			account := getMockAccount()
+			service.TransferService.UnitOfWork = &FakeUnitOfWork{Accounts: []*model.Account{account}}
+			tt.order.Amount = model.BigDecimal{Decimal: decimal.MustParse(tt.amount)}
+			tt.order.NextOccurrenceAt, tt.order.NextRunAt = &occurrenceAt, &occurrenceAt
+			stalled, due := []model.StandingOrder{}, []model.StandingOrder{*tt.order}
+			if tt.stalled {
+				stalled, due = due, stalled
+			}
+			transaction := tt.transaction
+			if transaction == nil {
+				transaction = getMockNotFoundTransaction()
+			}
+
+			// ------------ expectations ------------
+			mockRepository.On("ClaimStalledStandingOrders", now.Add(-standingOrderStallTimeout), standingOrderBatchSize).
+				Return(stalled, nil)
+			mockRepository.On("ClaimDueStandingOrders", now, standingOrderBatchSize).
+				Return(due, nil)
+			mockRepository.On("RecordStandingOrderExecution", mock.Anything, mock.Anything).Return(nil)
+			mocks.config.On("ThirdPartyBaseUrl").Return("http://provider")
+			mocks.transactionRepository.
+				On("FindTransactionByReference", mock.Anything).Return(transaction, nil)
+			mocks.userRepository.
+				On("GetUserAndAccountByAccountNumber", "1234567890").Return(getMockUser(), account, nil)
+			mocks.restClient.
+				On("PostRequest", mock.Anything, mock.Anything, mock.Anything).
+				Return(getSuccessThirdPartyResponse(), http.StatusOK, nil)
+
+			// ------------ executions -----------
+			err := service.ExecuteDue(context.Background(), now)
+
+			// ------------ assertions -----------
+			require.NoError(t, err)
+			mockRepository.AssertNumberOfCalls(t, "RecordStandingOrderExecution", 1)
+			var call mock.Call
+			for _, c := range mockRepository.Calls {
+				if c.Method == "RecordStandingOrderExecution" {
+					call = c
+				}
+			}
+			order := call.Arguments.Get(0).(*model.StandingOrder)
+			execution := call.Arguments.Get(1).(*model.StandingOrderExecution)
+
+			assert.Equal(t, tt.expectedOutcome, execution.Outcome)
+			assert.Equal(t, occurrenceAt, execution.ScheduledFor)
+			assert.Equal(t, tt.order.OccurrenceReference(tt.order.Occurrences+1), execution.PaymentReference)
+			assert.Equal(t, tt.expectedStatus, order.Status)
+			assert.Equal(t, tt.expectedOccurrence, order.Occurrences)
+			assert.Equal(t, tt.expectedRetries, order.RetryCount)
+			assert.Equal(t, tt.expectedNextRun, order.NextRunAt)
+			if tt.expectedStatus == model.StandingOrderFailed {
+				assert.Equal(t, constants.StandingOrderNextRunFailed, order.FailureReason)
+			}
+			assert.Equal(t, 0, account.GetBalance().Decimal.Cmp(decimal.MustParse(tt.expectedBalance)))
+		})
+	}
+}
+
+func createStandingOrderService() (*StandingOrderService, *MockStandingOrderRepository, scheduledTransferMocks) {
+	mockConfig, mockTransactionRepo, mockUserRepo, mockAccountRepo, mockRestClient, _ := setupMocks()
+	mockRepository := new(MockStandingOrderRepository)
+	transferService := createBankService(mockConfig, mockTransactionRepo, mockUserRepo, mockAccountRepo, mockRestClient)
+	return NewStandingOrderService(mockRepository, transferService), mockRepository, scheduledTransferMocks{
+		config:                mockConfig,
+		transactionRepository: mockTransactionRepo,
+		userRepository:        mockUserRepo,
+		accountRepository:     mockAccountRepo,
+		restClient:            mockRestClient,
+	}
+}
+
+func getMockStandingOrder(policy model.InsufficientFundsPolicy) *model.StandingOrder {
+	return &model.StandingOrder{
+		StandingOrderID:      1,
+		AccountID:            1,
+		AccountNumber:        "1234567890",
+		Username:             "johndoe",
+		Reference:            "rent",
+		Amount:               model.BigDecimal{Decimal: decimal.MustParse("100")},
+		Type:                 model.DebitTransaction,
+		Frequency:            "monthly",
+		Interval:             1,
+		StartAt:              time.Date(2024, 1, 1, 6, 0, 0, 0, time.UTC),
+		OnInsufficientFunds:  policy,
+		MaxRetries:           3,
+		RetryIntervalMinutes: 120,
+		Status:               model.StandingOrderExecuting,
+	}
+}
+
+func getStandingOrderRequest(start time.Time, frequency, expression string) model.StandingOrderRequestDTO {
+	return model.StandingOrderRequestDTO{
+		TransactionDataDTO: model.TransactionDataDTO{
+			AccountNumber:  "1234567890",
+			Username:       "johndoe",
+			TransactionPin: "1234",
+			Reference:      "rent",
+			Amount:         model.BigDecimal{Decimal: decimal.MustParse("100")},
+			Type:           model.DebitTransaction,
+		},
+		Frequency:      frequency,
+		CronExpression: expression,
+		StartAt:        start,
+	}
+}
+
+func timePointer(t time.Time) *time.Time {
+	return &t
+}
@@ -0,0 +1,92 @@
+package bankservice
+
+import (
+	"bankingApp/internal/api/constants"
+	"bankingApp/internal/model"
+	"bankingApp/internal/statement"
+	"bankingApp/internal/utility"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type IStatementRepository interface {
+	BalanceBefore(accountID uint, before time.Time) (model.BigDecimal, error)
+	EachStatementEntry(accountID uint, from, to time.Time, fn func(entry *model.StatementEntry) error) error
+}
+
+// StatementService renders account statements from the postings made against the account
+type StatementService struct {
+	Repository        IStatementRepository
+	AccountRepository IAccountRepository
+}
+
+// NewStatementService creates a new instance of StatementService
+func NewStatementService(repository IStatementRepository, accountRepository IAccountRepository) *StatementService {
+	return &StatementService{
+		Repository:        repository,
+		AccountRepository: accountRepository,
+	}
+}
+
+// Statement handles the endpoint downloading the statement of the account in the number path parameter from the
+// from date up to and including the to date, in the csv, json or mt940 format of the format query parameter.
+// The opening balance is the balance at the start of the from date. Lines are streamed to the response as they
+// are read, so once the statement has started an error can only cut it short.
+func (s *StatementService) Statement(c *gin.Context) {
+	from, to, format, ok := statementRequest(c)
+	if !ok {
+		return
+	}
+
+	account, ok := findReadableAccount(c, s.AccountRepository)
+	if !ok {
+		return
+	}
+
+	opening, err := s.Repository.BalanceBefore(account.AccountID, from)
+	if err != nil {
+		utility.HandleError(c, err, http.StatusInternalServerError, constants.ApplicationError)
+		return
+	}
+
+	header := statement.Header{
+		AccountNumber:  account.AccountNumber,
+		Currency:       account.Currency,
+		From:           from,
+		To:             to,
+		OpeningBalance: opening.Decimal,
+	}
+	writer, err := statement.NewWriter(format, c.Writer)
+	if err != nil {
+		utility.HandleError(c, err, http.StatusInternalServerError, constants.ApplicationError)
+		return
+	}
+
+	c.Header("Content-Type", format.ContentType())
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, format.FileName(header)))
+	c.Status(http.StatusOK)
+	_, err = statement.Render(writer, header, func(fn func(entry *model.StatementEntry) error) error {
+		return s.Repository.EachStatementEntry(account.AccountID, from, to.AddDate(0, 0, 1), fn)
+	})
+	if err != nil {
+		slog.Error("error in rendering statement", "account", account.AccountNumber, "error", err)
+		c.Abort()
+	}
+}
+
+// statementRequest reads the period and the format of the statement from the query parameters.
+// The format defaults to json.
+func statementRequest(c *gin.Context) (time.Time, time.Time, statement.Format, bool) {
+	from, fromErr := time.Parse(time.DateOnly, c.Query("from"))
+	to, toErr := time.Parse(time.DateOnly, c.Query("to"))
+	format := statement.Format(c.DefaultQuery("format", string(statement.JSON)))
+	if fromErr != nil || toErr != nil || to.Before(from) || !format.IsValid() {
+		utility.HandleError(c, nil, http.StatusBadRequest, constants.InvalidStatementRequest)
+		return time.Time{}, time.Time{}, "", false
+	}
+	return from, to, format, true
+}
@@ -0,0 +1,178 @@
+package bankservice
+
+import (
+	"bankingApp/internal/api/constants"
+	"bankingApp/internal/model"
+	"bankingApp/internal/utility"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// FakeStatementRepository serves the statement entries it holds that fall within the requested period
+type FakeStatementRepository struct {
+	Opening model.BigDecimal
+	Entries []model.StatementEntry
+	Before  time.Time
+	From    time.Time
+	To      time.Time
+}
+
+func (f *FakeStatementRepository) BalanceBefore(accountID uint, before time.Time) (model.BigDecimal, error) {
+	f.Before = before
+	return f.Opening, nil
+}
+
+func (f *FakeStatementRepository) EachStatementEntry(
+	accountID uint,
+	from, to time.Time,
+	fn func(entry *model.StatementEntry) error) error {
+	f.From, f.To = from, to
+	for i := range f.Entries {
+		if f.Entries[i].PostedAt.Before(from) || !f.Entries[i].PostedAt.Before(to) {
+			continue
+		}
+		if err := fn(&f.Entries[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func Test_Statement(t *testing.T) {
+	testCases := []struct {
+		name                string
+		query               string
+		expectedStatus      int
+		expectedContentType string
+		userID              uint
+		role                model.UserRole
+		expectedBody        string
+		expectedMessage     string
+	}{
+		{
+			name:                "csv statement",
+			query:               "from=2024-03-01&to=2024-03-31&format=csv",
+			expectedStatus:      http.StatusOK,
+			expectedContentType: "text/csv; charset=utf-8",
+			expectedBody:        "2024-03-31T23:59:00Z,TRF0001,TRF0001,rent,inbound transfer,credit,succeeded,250.00,350.00",
+		},
+		{
+			name:                "json statement by default",
+			query:               "from=2024-03-01&to=2024-03-31",
+			expectedStatus:      http.StatusOK,
+			expectedContentType: "application/json; charset=utf-8",
+			expectedBody:        `"closing_balance":"350.00"`,
+		},
+		{
+			name:                "mt940 statement",
+			query:               "from=2024-03-01&to=2024-03-31&format=mt940",
+			expectedStatus:      http.StatusOK,
+			expectedContentType: "text/plain; charset=utf-8",
+			expectedBody:        ":62F:C240331NGN350,00",
+		},
+		{
+			name:            "unknown format",
+			query:           "from=2024-03-01&to=2024-03-31&format=pdf",
+			expectedStatus:  http.StatusBadRequest,
+			expectedMessage: constants.InvalidStatementRequest,
+		},
+		{
+			name:            "to before from",
+			query:           "from=2024-03-31&to=2024-03-01",
+			expectedStatus:  http.StatusBadRequest,
+			expectedMessage: constants.InvalidStatementRequest,
+		},
+		{
+			name:            "missing period",
+			query:           "format=csv",
+			expectedStatus:  http.StatusBadRequest,
+			expectedMessage: constants.InvalidStatementRequest,
+		},
+		{
+			name:                "statement read by a teller",
+			query:               "from=2024-03-01&to=2024-03-31",
+			userID:              5,
+			role:                model.TellerRole,
+			expectedStatus:      http.StatusOK,
+			expectedContentType: "application/json; charset=utf-8",
+			expectedBody:        `"closing_balance":"350.00"`,
+		},
+		{
+			name:            "another customer's account",
+			query:           "from=2024-03-01&to=2024-03-31&format=csv",
+			userID:          2,
+			role:            model.CustomerRole,
+			expectedStatus:  http.StatusForbidden,
+			expectedMessage: constants.AccountAccessDenied,
+		},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			// ------------ setups ------------
+			repository := &FakeStatementRepository{
+				Opening: amountOf("100"),
+				Entries: []model.StatementEntry{
+					{
+						JournalEntryID:       1,
+						Reference:            "TRF0001",
+						Description:          "inbound transfer",
+						PostedAt:             time.Date(2024, 3, 31, 23, 59, 0, 0, time.UTC),
+						Amount:               amountOf("250"),
+						TransactionReference: "TRF0001",
+						PaymentReference:     "rent",
+						Type:                 model.CreditTransaction,
+						Status:               model.SucceededStatus,
+					},
+					{
+						JournalEntryID: 2,
+						Reference:      "ODI-1-20240401",
+						Description:    "overdraft interest",
+						PostedAt:       time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC),
+						Amount:         amountOf("-1"),
+					},
+				},
+			}
+			account := getMockAccount()
+			account.Currency = "NGN"
+			mockAccountRepo := new(MockAccountRepository)
+			statementService := NewStatementService(repository, mockAccountRepo)
+			gin.SetMode(gin.TestMode)
+
+			// ------------ expectations ------------
+			mockAccountRepo.On("GetAccountByAccountNumber", "1234567890").Return(account, nil)
+
+			// ------------ executions -----------
+			context, recorder := newScheduledTransferContext(t, http.MethodGet,
+				"/api/v1/bank/accounts/1234567890/statement?"+tt.query, nil)
+			context.Params = gin.Params{{Key: "number", Value: "1234567890"}}
+			if tt.userID == 0 {
+				tt.userID, tt.role = account.UserID, model.CustomerRole
+			}
+			authenticate(t, context, "1234567890", tt.userID, tt.role)
+			statementService.Statement(context)
+
+			// ------------ assertions -----------
+			assert.Equal(t, tt.expectedStatus, recorder.Code)
+			if tt.expectedMessage != "" {
+				var returnedResponse utility.APIDataResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &returnedResponse))
+				assert.Equal(t, tt.expectedMessage, returnedResponse.Message)
+				return
+			}
+
+			assert.Equal(t, tt.expectedContentType, recorder.Header().Get("Content-Type"))
+			assert.True(t, strings.HasPrefix(recorder.Header().Get("Content-Disposition"), "attachment;"))
+			assert.Contains(t, recorder.Body.String(), tt.expectedBody)
+			assert.NotContains(t, recorder.Body.String(), "ODI-1-20240401")
+			assert.Equal(t, time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), repository.Before)
+			assert.Equal(t, time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC), repository.To)
+		})
+	}
+}
@@ -0,0 +1,251 @@
+package bankservice
+
+import (
+	"bankingApp/internal/api/constants"
+	"bankingApp/internal/api/middleware"
+	"bankingApp/internal/credential"
+	"bankingApp/internal/currency"
+	"bankingApp/internal/model"
+	"bankingApp/internal/reference"
+	"bankingApp/internal/utility"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxAccountNumberDraws is how many account numbers are drawn for a new account before registration gives up
+const maxAccountNumberDraws = 5
+
+type IUserProfileRepository interface {
+	IUserRepository
+	CreateUser(user *model.User, account *model.Account) error
+	UpdateProfile(user *model.User) error
+	FindUserAccounts(userID uint) ([]model.Account, error)
+	UpdateRole(userID uint, role model.UserRole) error
+}
+
+// UserService registers customers, opening their first account, and lets them read and update their profile
+type UserService struct {
+	Repository      IUserProfileRepository
+	AccountNumbers  reference.IAccountNumberGenerator
+	DefaultCurrency string
+}
+
+// NewUserService creates a new instance of UserService opening accounts in the default currency
+// unless the customer asks for another one
+func NewUserService(
+	repository IUserProfileRepository,
+	accountNumbers reference.IAccountNumberGenerator,
+	defaultCurrency string) *UserService {
+	return &UserService{Repository: repository, AccountNumbers: accountNumbers, DefaultCurrency: defaultCurrency}
+}
+
+// Register handles the endpoint registering a customer. A transaction PIN that is easy to guess is refused, the
+// password and transaction PIN are stored as bcrypt hashes, and the first account of the customer is opened
+// with a generated account number in the same commit.
+func (u *UserService) Register(c *gin.Context) {
+	r, ok := bindRequest[model.RegistrationRequestDTO](c)
+	if !ok {
+		return
+	}
+
+	if err := model.CheckPinStrength(r.TransactionPin); err != nil {
+		utility.HandleError(c, nil, http.StatusOK, err.Error())
+		return
+	}
+
+	code := u.DefaultCurrency
+	if r.Currency != "" {
+		requested, err := currency.Lookup(r.Currency)
+		if err != nil {
+			utility.HandleError(c, nil, http.StatusOK, constants.UnsupportedCurrency)
+			return
+		}
+		code = requested.Code
+	}
+
+	existing, err := u.Repository.FindUserByUsername(r.Username)
+	if err != nil {
+		utility.HandleError(c, err, http.StatusInternalServerError, constants.ApplicationError)
+		return
+	}
+	if existing.UserID != constants.Zero {
+		utility.HandleError(c, nil, http.StatusOK, constants.UsernameTaken)
+		return
+	}
+
+	password, err := credential.Hash(r.Password)
+	if err != nil {
+		utility.HandleError(c, err, http.StatusInternalServerError, constants.ApplicationError)
+		return
+	}
+	pin, err := credential.Hash(r.TransactionPin)
+	if err != nil {
+		utility.HandleError(c, err, http.StatusInternalServerError, constants.ApplicationError)
+		return
+	}
+
+	now := time.Now()
+	user := &model.User{
+		Username:       r.Username,
+		Password:       password,
+		TransactionPin: pin,
+		FullName:       r.FullName,
+		Email:          r.Email,
+		PhoneNumber:    r.PhoneNumber,
+		Role:           model.CustomerRole,
+		TimestampData:  model.TimestampData{CreatedAt: now, UpdatedAt: now},
+	}
+	account, err := u.createUser(user, code)
+	if errors.Is(err, model.ErrUsernameTaken) {
+		utility.HandleError(c, nil, http.StatusOK, constants.UsernameTaken)
+		return
+	}
+	if err != nil {
+		utility.HandleError(c, err, http.StatusInternalServerError, constants.ApplicationError)
+		return
+	}
+
+	profile := profileDTO(user)
+	profile.Accounts = append(profile.Accounts, profileAccountDTO(account))
+	c.JSON(http.StatusOK, utility.FormulateDataResponse(constants.UserRegisteredMsg, profile))
+}
+
+// Profile handles the endpoint retrieving the profile of the user the access token was issued to,
+// with the accounts they hold
+func (u *UserService) Profile(c *gin.Context) {
+	user, ok := tokenUser(c, u.Repository)
+	if !ok {
+		return
+	}
+	u.respondProfile(c, user, constants.ProfileFoundMsg)
+}
+
+// UpdateProfile handles the endpoint changing the contact details of the user the access token was issued to
+func (u *UserService) UpdateProfile(c *gin.Context) {
+	user, ok := tokenUser(c, u.Repository)
+	if !ok {
+		return
+	}
+
+	r, ok := bindRequest[model.ProfileUpdateDTO](c)
+	if !ok {
+		return
+	}
+
+	if r.FullName != nil {
+		user.FullName = *r.FullName
+	}
+	if r.Email != nil {
+		user.Email = *r.Email
+	}
+	if r.PhoneNumber != nil {
+		user.PhoneNumber = *r.PhoneNumber
+	}
+
+	if err := u.Repository.UpdateProfile(user); err != nil {
+		utility.HandleError(c, err, http.StatusInternalServerError, constants.ApplicationError)
+		return
+	}
+	u.respondProfile(c, user, constants.ProfileUpdatedMsg)
+}
+
+// AssignRole handles the endpoint giving the user named in the path another role. Only administrators
+// reach it, the change is logged with the administrator who made it.
+func (u *UserService) AssignRole(c *gin.Context) {
+	r, ok := bindRequest[model.RoleAssignmentDTO](c)
+	if !ok {
+		return
+	}
+	if !r.Role.IsValid() {
+		utility.HandleError(c, nil, http.StatusOK, constants.InvalidRole)
+		return
+	}
+
+	user, err := u.Repository.FindUserByUsername(c.Param("username"))
+	if err != nil {
+		utility.HandleError(c, err, http.StatusInternalServerError, constants.ApplicationError)
+		return
+	}
+	if user.UserID == constants.Zero {
+		utility.HandleError(c, nil, http.StatusOK, constants.UserNotFound)
+		return
+	}
+
+	if err := u.Repository.UpdateRole(user.UserID, r.Role); err != nil {
+		utility.HandleError(c, err, http.StatusInternalServerError, constants.ApplicationError)
+		return
+	}
+	assignedBy := ""
+	if claims, ok := middleware.AuthenticatedUser(c); ok {
+		assignedBy = claims.Subject
+	}
+	slog.Info("role assigned", "username", user.Username, "previous_role", user.Role, "role", r.Role,
+		"assigned_by", assignedBy)
+
+	user.Role = r.Role
+	u.respondProfile(c, &user, constants.RoleAssignedMsg)
+}
+
+// createUser stores the user with their first account in the currency, drawing another account number
+// when the one drawn is already taken
+func (u *UserService) createUser(user *model.User, code string) (*model.Account, error) {
+	for draw := 1; ; draw++ {
+		number, err := u.AccountNumbers.NewAccountNumber()
+		if err != nil {
+			return nil, err
+		}
+
+		account := &model.Account{
+			AccountNumber: number,
+			Currency:      code,
+			Tier:          model.DefaultAccountTier,
+			Status:        model.ActiveAccount,
+			TimestampData: user.TimestampData,
+		}
+		user.UserID = constants.Zero
+		err = u.Repository.CreateUser(user, account)
+		if !errors.Is(err, model.ErrAccountNumberTaken) || draw == maxAccountNumberDraws {
+			return account, err
+		}
+	}
+}
+
+// respondProfile responds with the profile of the user and the accounts they hold
+func (u *UserService) respondProfile(c *gin.Context, user *model.User, message string) {
+	accounts, err := u.Repository.FindUserAccounts(user.UserID)
+	if err != nil {
+		utility.HandleError(c, err, http.StatusInternalServerError, constants.ApplicationError)
+		return
+	}
+
+	profile := profileDTO(user)
+	for i := range accounts {
+		profile.Accounts = append(profile.Accounts, profileAccountDTO(&accounts[i]))
+	}
+	c.JSON(http.StatusOK, utility.FormulateDataResponse(message, profile))
+}
+
+// profileDTO converts the user to their profile, never exposing their password or transaction PIN
+func profileDTO(user *model.User) model.ProfileDTO {
+	return model.ProfileDTO{
+		Username:    user.Username,
+		FullName:    user.FullName,
+		Email:       user.Email,
+		PhoneNumber: user.PhoneNumber,
+		Role:        user.Role,
+		Accounts:    []model.ProfileAccountDTO{},
+		CreatedAt:   user.CreatedAt,
+	}
+}
+
+func profileAccountDTO(account *model.Account) model.ProfileAccountDTO {
+	return model.ProfileAccountDTO{
+		AccountNumber: account.AccountNumber,
+		Currency:      account.Currency,
+		Status:        account.Status,
+	}
+}
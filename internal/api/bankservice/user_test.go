@@ -0,0 +1,426 @@
+package bankservice
+
+import (
+	"bankingApp/internal/api/constants"
+	"bankingApp/internal/credential"
+	"bankingApp/internal/model"
+	"bankingApp/internal/utility"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// FakeUserRepository keeps users and their accounts in memory, enforcing unique usernames and account numbers
+type FakeUserRepository struct {
+	Users    []*model.User
+	Accounts []*model.Account
+	// Racing is a username another registration takes between the check and the commit
+	Racing string
+}
+
+func (f *FakeUserRepository) FindUserByUsername(username string) (model.User, error) {
+	for _, user := range f.Users {
+		if user.Username == username {
+			return *user, nil
+		}
+	}
+	return model.User{}, nil
+}
+
+func (f *FakeUserRepository) GetUserAndAccountByAccountNumber(accountNumber string) (*model.User, *model.Account, error) {
+	return nil, nil, nil
+}
+
+func (f *FakeUserRepository) RecordFailedPinAttempt(userID uint, now time.Time) error {
+	return nil
+}
+
+func (f *FakeUserRepository) ResetPinAttempts(userID uint) error {
+	return nil
+}
+
+func (f *FakeUserRepository) RecordFailedLogin(userID uint, now time.Time) error {
+	return nil
+}
+
+func (f *FakeUserRepository) ResetLoginAttempts(userID uint) error {
+	return nil
+}
+
+func (f *FakeUserRepository) CreateUser(user *model.User, account *model.Account) error {
+	if existing, _ := f.FindUserByUsername(user.Username); existing.UserID != 0 || user.Username == f.Racing {
+		return model.ErrUsernameTaken
+	}
+	for _, existing := range f.Accounts {
+		if existing.AccountNumber == account.AccountNumber {
+			return model.ErrAccountNumberTaken
+		}
+	}
+	user.UserID = uint(len(f.Users) + 1)
+	account.AccountID, account.UserID = uint(len(f.Accounts)+1), user.UserID
+	f.Users = append(f.Users, user)
+	f.Accounts = append(f.Accounts, account)
+	return nil
+}
+
+func (f *FakeUserRepository) UpdateProfile(user *model.User) error {
+	for _, existing := range f.Users {
+		if existing.UserID == user.UserID {
+			existing.FullName, existing.Email, existing.PhoneNumber = user.FullName, user.Email, user.PhoneNumber
+		}
+	}
+	return nil
+}
+
+func (f *FakeUserRepository) UpdateRole(userID uint, role model.UserRole) error {
+	for _, existing := range f.Users {
+		if existing.UserID == userID {
+			existing.Role = role
+		}
+	}
+	return nil
+}
+
+func (f *FakeUserRepository) FindUserAccounts(userID uint) ([]model.Account, error) {
+	var accounts []model.Account
+	for _, account := range f.Accounts {
+		if account.UserID == userID {
+			accounts = append(accounts, model.Account{
+				AccountID:     account.AccountID,
+				UserID:        account.UserID,
+				AccountNumber: account.AccountNumber,
+				Currency:      account.Currency,
+				Status:        account.Status,
+			})
+		}
+	}
+	return accounts, nil
+}
+
+// FakeAccountNumberGenerator hands out the account numbers it holds in order
+type FakeAccountNumberGenerator struct {
+	Numbers []string
+}
+
+func (f *FakeAccountNumberGenerator) NewAccountNumber() (string, error) {
+	number := f.Numbers[0]
+	f.Numbers = f.Numbers[1:]
+	return number, nil
+}
+
+func Test_Register(t *testing.T) {
+	testCases := []struct {
+		name            string
+		requestBody     string
+		racing          string
+		expectedStatus  int
+		expectedMessage string
+		expectedNumber  string
+		expectedCcy     string
+	}{
+		{
+			name:            "new customer",
+			requestBody:     registrationRequest("janedoe", ""),
+			expectedStatus:  http.StatusOK,
+			expectedMessage: constants.UserRegisteredMsg,
+			expectedNumber:  "2000000002",
+			expectedCcy:     "NGN",
+		},
+		{
+			name:            "account in another currency",
+			requestBody:     registrationRequest("janedoe", "usd"),
+			expectedStatus:  http.StatusOK,
+			expectedMessage: constants.UserRegisteredMsg,
+			expectedNumber:  "2000000002",
+			expectedCcy:     "USD",
+		},
+		{
+			name:            "username taken",
+			requestBody:     registrationRequest("johndoe", ""),
+			expectedStatus:  http.StatusOK,
+			expectedMessage: constants.UsernameTaken,
+		},
+		{
+			name:            "username taken by a concurrent registration",
+			requestBody:     registrationRequest("janedoe", ""),
+			racing:          "janedoe",
+			expectedStatus:  http.StatusOK,
+			expectedMessage: constants.UsernameTaken,
+		},
+		{
+			name:            "unsupported currency",
+			requestBody:     registrationRequest("janedoe", "XYZ"),
+			expectedStatus:  http.StatusOK,
+			expectedMessage: constants.UnsupportedCurrency,
+		},
+		{
+			name: "PIN is not four digits",
+			requestBody: `{"username": "janedoe", "password": "correct horse", "transaction_pin": "12a4",
+				"full_name": "Jane Doe", "email": "jane@example.com"}`,
+			expectedStatus:  http.StatusBadRequest,
+			expectedMessage: constants.BadRequestMessage,
+		},
+		{
+			name: "PIN is easy to guess",
+			requestBody: `{"username": "janedoe", "password": "correct horse", "transaction_pin": "1234",
+				"full_name": "Jane Doe", "email": "jane@example.com"}`,
+			expectedStatus:  http.StatusOK,
+			expectedMessage: constants.TrivialPin,
+		},
+		{
+			name: "password too short",
+			requestBody: `{"username": "janedoe", "password": "short", "transaction_pin": "5829",
+				"full_name": "Jane Doe", "email": "jane@example.com"}`,
+			expectedStatus:  http.StatusBadRequest,
+			expectedMessage: constants.BadRequestMessage,
+		},
+		{
+			name: "invalid email",
+			requestBody: `{"username": "janedoe", "password": "correct horse", "transaction_pin": "5829",
+				"full_name": "Jane Doe", "email": "jane"}`,
+			expectedStatus:  http.StatusBadRequest,
+			expectedMessage: constants.BadRequestMessage,
+		},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			// ------------ setups ------------
+			userService, repository := createUserService()
+			repository.Racing = tt.racing
+			gin.SetMode(gin.TestMode)
+
+			// ------------ executions -----------
+			context, recorder := newScheduledTransferContext(t, http.MethodPost, "/api/v1/bank/users",
+				[]byte(tt.requestBody))
+			userService.Register(context)
+
+			var returnedResponse struct {
+				utility.APIDataResponse
+				Data model.ProfileDTO `json:"data"`
+			}
+			require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &returnedResponse))
+
+			// ------------ assertions -----------
+			assert.Equal(t, tt.expectedStatus, recorder.Code)
+			assert.Equal(t, tt.expectedMessage, returnedResponse.Message)
+			assert.NotContains(t, recorder.Body.String(), "correct horse")
+			if tt.expectedMessage != constants.UserRegisteredMsg {
+				assert.Len(t, repository.Users, 1)
+				return
+			}
+
+			profile := returnedResponse.Data
+			assert.Equal(t, "janedoe", profile.Username)
+			assert.Equal(t, model.CustomerRole, profile.Role)
+			require.Len(t, profile.Accounts, 1)
+			assert.Equal(t, tt.expectedNumber, profile.Accounts[0].AccountNumber)
+			assert.Equal(t, tt.expectedCcy, profile.Accounts[0].Currency)
+			assert.Equal(t, model.ActiveAccount, profile.Accounts[0].Status)
+
+			require.Len(t, repository.Users, 2)
+			user := repository.Users[1]
+			assert.True(t, credential.IsHashed(user.Password))
+			assert.True(t, credential.IsHashed(user.TransactionPin))
+			assert.True(t, credential.Verify(user.Password, "correct horse"))
+			assert.True(t, user.CheckTransactionPin("5829"))
+		})
+	}
+}
+
+func Test_Profile(t *testing.T) {
+	testCases := []struct {
+		name            string
+		method          string
+		username        string
+		userID          uint
+		requestBody     string
+		expectedStatus  int
+		expectedMessage string
+		expectedEmail   string
+		expectedName    string
+	}{
+		{
+			name:            "read",
+			method:          http.MethodGet,
+			username:        "janedoe",
+			userID:          2,
+			expectedStatus:  http.StatusOK,
+			expectedMessage: constants.ProfileFoundMsg,
+			expectedEmail:   "jane@example.com",
+			expectedName:    "Jane Doe",
+		},
+		{
+			name:            "update the email only",
+			method:          http.MethodPut,
+			username:        "janedoe",
+			userID:          2,
+			requestBody:     `{"email": "jane.doe@example.com"}`,
+			expectedStatus:  http.StatusOK,
+			expectedMessage: constants.ProfileUpdatedMsg,
+			expectedEmail:   "jane.doe@example.com",
+			expectedName:    "Jane Doe",
+		},
+		{
+			name:            "invalid email",
+			method:          http.MethodPut,
+			username:        "janedoe",
+			userID:          2,
+			requestBody:     `{"email": "jane"}`,
+			expectedStatus:  http.StatusBadRequest,
+			expectedMessage: constants.BadRequestMessage,
+			expectedEmail:   "jane@example.com",
+		},
+		{
+			name:            "token of a user that no longer exists",
+			method:          http.MethodGet,
+			username:        "nobody",
+			userID:          9,
+			expectedStatus:  http.StatusUnauthorized,
+			expectedMessage: constants.InvalidAccessToken,
+			expectedEmail:   "jane@example.com",
+		},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			// ------------ setups ------------
+			userService, repository := createUserService()
+			gin.SetMode(gin.TestMode)
+			context, _ := newScheduledTransferContext(t, http.MethodPost, "/api/v1/bank/users",
+				[]byte(registrationRequest("janedoe", "")))
+			userService.Register(context)
+			require.Len(t, repository.Users, 2)
+
+			// ------------ executions -----------
+			var body []byte
+			if tt.requestBody != "" {
+				body = []byte(tt.requestBody)
+			}
+			context, recorder := newScheduledTransferContext(t, tt.method, "/api/v1/bank/users/me", body)
+			authenticate(t, context, tt.username, tt.userID, model.CustomerRole)
+			if tt.method == http.MethodGet {
+				userService.Profile(context)
+			} else {
+				userService.UpdateProfile(context)
+			}
+
+			var returnedResponse struct {
+				utility.APIDataResponse
+				Data model.ProfileDTO `json:"data"`
+			}
+			require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &returnedResponse))
+
+			// ------------ assertions -----------
+			assert.Equal(t, tt.expectedStatus, recorder.Code)
+			assert.Equal(t, tt.expectedMessage, returnedResponse.Message)
+			assert.Equal(t, tt.expectedEmail, repository.Users[1].Email)
+			if tt.expectedStatus != http.StatusOK {
+				return
+			}
+
+			profile := returnedResponse.Data
+			assert.Equal(t, tt.expectedEmail, profile.Email)
+			assert.Equal(t, tt.expectedName, profile.FullName)
+			require.Len(t, profile.Accounts, 1)
+			assert.Equal(t, "2000000002", profile.Accounts[0].AccountNumber)
+		})
+	}
+}
+
+func Test_AssignRole(t *testing.T) {
+	testCases := []struct {
+		name            string
+		username        string
+		requestBody     string
+		expectedStatus  int
+		expectedMessage string
+		expectedRole    model.UserRole
+	}{
+		{
+			name:            "promote to ops",
+			username:        "johndoe",
+			requestBody:     `{"role": "ops"}`,
+			expectedStatus:  http.StatusOK,
+			expectedMessage: constants.RoleAssignedMsg,
+			expectedRole:    model.OpsRole,
+		},
+		{
+			name:            "unknown role",
+			username:        "johndoe",
+			requestBody:     `{"role": "superuser"}`,
+			expectedStatus:  http.StatusOK,
+			expectedMessage: constants.InvalidRole,
+			expectedRole:    model.CustomerRole,
+		},
+		{
+			name:            "missing role",
+			username:        "johndoe",
+			requestBody:     `{}`,
+			expectedStatus:  http.StatusBadRequest,
+			expectedMessage: constants.BadRequestMessage,
+			expectedRole:    model.CustomerRole,
+		},
+		{
+			name:            "unknown user",
+			username:        "nobody",
+			requestBody:     `{"role": "teller"}`,
+			expectedStatus:  http.StatusOK,
+			expectedMessage: constants.UserNotFound,
+			expectedRole:    model.CustomerRole,
+		},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			// ------------ setups ------------
+			userService, repository := createUserService()
+			repository.Users[0].Role = model.CustomerRole
+			gin.SetMode(gin.TestMode)
+
+			// ------------ executions -----------
+			context, recorder := newScheduledTransferContext(t, http.MethodPut,
+				"/api/v1/bank/admin/users/"+tt.username+"/role", []byte(tt.requestBody))
+			context.Params = gin.Params{{Key: "username", Value: tt.username}}
+			userService.AssignRole(context)
+
+			var returnedResponse struct {
+				utility.APIDataResponse
+				Data model.ProfileDTO `json:"data"`
+			}
+			require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &returnedResponse))
+
+			// ------------ assertions -----------
+			assert.Equal(t, tt.expectedStatus, recorder.Code)
+			assert.Equal(t, tt.expectedMessage, returnedResponse.Message)
+			assert.Equal(t, tt.expectedRole, repository.Users[0].Role)
+			if tt.expectedMessage == constants.RoleAssignedMsg {
+				assert.Equal(t, "johndoe", returnedResponse.Data.Username)
+				assert.Equal(t, tt.expectedRole, returnedResponse.Data.Role)
+			}
+		})
+	}
+}
+
+// createUserService creates the service with johndoe already registered, holding account 1000000001,
+// and an account number generator that draws that taken number before a free one
+func createUserService() (*UserService, *FakeUserRepository) {
+	repository := &FakeUserRepository{}
+	_ = repository.CreateUser(&model.User{Username: "johndoe"}, &model.Account{AccountNumber: "1000000001"})
+	generator := &FakeAccountNumberGenerator{Numbers: []string{"1000000001", "2000000002"}}
+	return NewUserService(repository, generator, "NGN"), repository
+}
+
+func registrationRequest(username, currency string) string {
+	request, _ := json.Marshal(model.RegistrationRequestDTO{
+		Username:       username,
+		Password:       "correct horse",
+		TransactionPin: "5829",
+		FullName:       "Jane Doe",
+		Email:          "jane@example.com",
+		Currency:       currency,
+	})
+	return string(request)
+}
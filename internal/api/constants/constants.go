@@ -15,5 +15,125 @@ const (
 	BadRequestMessage           = "bad request"
 	UserOrAccountNotFound       = "user or account not found"
 	IncorrectTransactionPin     = "incorrect user transaction PIN"
+	TransactionPinLocked        = "transaction PIN is locked after too many incorrect attempts, try again later"
+	TransactionPinHeader        = "Transaction-Pin"
+	TransactionPinRequired      = "Transaction-Pin header is required"
 	InsufficientFunds           = "insufficient funds"
+	SameAccountTransfer         = "source and destination accounts must be different"
+	DestinationAccountNotFound  = "destination account not found"
+	TransactionOutcomeUnknown   = "transaction outcome is unknown, check the transaction status"
+	TransactionRejected         = "transaction was rejected by the payment provider"
+	IdempotencyKeyHeader        = "Idempotency-Key"
+	IdempotencyReplayedHeader   = "Idempotent-Replayed"
+	InvalidIdempotencyKey       = "invalid Idempotency-Key header"
+	IdempotencyKeyConflict      = "Idempotency-Key has already been used with a different request"
+	IdempotencyKeyInProgress    = "a request with this Idempotency-Key is still being processed"
+	SuccessfulReversalMsg       = "transaction reversal is successful"
+	TransactionNotReversible    = "only successful transactions can be reversed"
+	TransactionAlreadyReversed  = "transaction has already been reversed"
+	ReversalAmountExceeded      = "reversal amount exceeds the amount left to reverse"
+	TransferScheduledMsg        = "transfer is scheduled"
+	ScheduledTransfersFoundMsg  = "scheduled transfers retrieved"
+	ScheduledTransferCancelled  = "scheduled transfer is cancelled"
+	ScheduleDateNotInFuture     = "execute_at must be in the future"
+	ScheduledTransferNotFound   = "scheduled transfer not found"
+	ScheduleNotCancellable      = "only scheduled transfers that have not run can be cancelled"
+	AccountNotFound             = "account not found"
+	StandingOrderCreatedMsg     = "standing order is created"
+	StandingOrdersFoundMsg      = "standing orders retrieved"
+	StandingOrderRunsFoundMsg   = "standing order executions retrieved"
+	StandingOrderCancelled      = "standing order is cancelled"
+	StandingOrderNotFound       = "standing order not found"
+	StandingOrderNotCancellable = "only active standing orders can be cancelled"
+	StandingOrderNextRunFailed  = "unable to calculate the next run of the standing order"
+	StartDateNotInFuture        = "start_at must be in the future"
+	EndDateBeforeFirstRun       = "end_at must not be before the first run"
+	InvalidFundsPolicy          = "on_insufficient_funds must be either 'skip' or 'retry'"
+	InvalidRetryPolicy          = "max_retries must be between 0 and 10 and retry_interval_minutes must not be negative"
+	InvalidMaxOccurrences       = "max_occurrences must not be negative"
+	ContentTypeCSV              = "text/csv"
+	BulkTransferAcceptedMsg     = "bulk transfer batch is accepted"
+	BulkTransferBatchFoundMsg   = "bulk transfer batch retrieved"
+	BulkTransferBatchNotFound   = "bulk transfer batch not found"
+	BulkTransferBatchNotReady   = "bulk transfer batch is still being processed"
+	EmptyBulkTransfer           = "bulk transfer must contain at least one row"
+	BulkTransferTooLarge        = "bulk transfer contains too many rows"
+	InvalidCsvRequestErrorMsg   = "invalid csv file passed"
+	UnsupportedBulkContentType  = "bulk transfers must be sent as JSON or CSV"
+	InvalidAmount               = "amount must be a number"
+	DuplicateReferenceInBatch   = "payment reference is repeated in the batch"
+	UnsupportedCurrency         = "currency is not supported"
+	AmountPrecisionExceeded     = "amount has more decimal places than the currency allows"
+	ExchangeRateNotFound        = "no exchange rate is available for the currency pair"
+	InvalidExchangeRate         = "exchange rates must be positive and between two different supported currencies"
+	ExchangeRatesUpdatedMsg     = "exchange rates are updated"
+	ExchangeRatesFoundMsg       = "exchange rates retrieved"
+	TransactionLimitExceeded    = "amount exceeds the single transaction limit of the account"
+	DailyLimitExceeded          = "transfer exceeds the daily debit limit of the account"
+	MonthlyLimitExceeded        = "transfer exceeds the monthly debit limit of the account"
+	DailyCountLimitExceeded     = "account has reached its daily number of debits"
+	MonthlyCountLimitExceeded   = "account has reached its monthly number of debits"
+	InvalidTransactionLimit     = "limits must not be negative"
+	InvalidAccountTier          = "account tier must be between 1 and 20 characters"
+	TransactionLimitsSavedMsg   = "transaction limits are saved"
+	LimitHeadroomFoundMsg       = "transaction limit headroom retrieved"
+	InvalidFeeRule              = "fee rules must have valid criteria, non-negative amounts and bands that do not overlap"
+	FeeExceedsAmount            = "fee exceeds the amount of the transfer"
+	FeeRulesUpdatedMsg          = "fee rules are updated"
+	FeeRulesFoundMsg            = "fee rules retrieved"
+	HoldPlacedMsg               = "funds are held"
+	HoldFoundMsg                = "hold retrieved"
+	HoldCapturedMsg             = "hold is captured"
+	HoldReleasedMsg             = "hold is released"
+	HoldNotFound                = "hold not found"
+	HoldNotActive               = "hold is no longer active"
+	HoldExpired                 = "hold has expired"
+	CaptureAmountExceeded       = "capture amount exceeds the amount held"
+	HoldExpiryNotInFuture       = "expires_at must be in the future"
+	InvalidOverdraft            = "overdraft limit must not be negative and interest rate must be between 0 and 100"
+	OverdraftNotSet             = "account has no overdraft"
+	OverdraftSavedMsg           = "overdraft is saved"
+	OverdraftRemovedMsg         = "overdraft is removed"
+	OverdraftFoundMsg           = "overdraft retrieved"
+	InvalidAccountProduct       = "account products must have a day-count convention of ACT/365 or 30/360 and rates between 0 and 100"
+	AccountProductNotFound      = "account product not found"
+	AccountProductSavedMsg      = "account product is saved"
+	AccountProductsFoundMsg     = "account products retrieved"
+	AccountProductAssignedMsg   = "account product is assigned"
+	InterestFoundMsg            = "interest retrieved"
+	TransactionsFoundMsg        = "transactions retrieved"
+	InvalidTransactionFilter    = "filters need dates as YYYY-MM-DD, a type of debit or credit, a known status, amounts that are not negative, a limit of 1 to 100 and a cursor from a previous page"
+	AccountAccessDenied         = "only the account owner or staff can access this account"
+	BalanceFoundMsg             = "balance retrieved"
+	InvalidStatementRequest     = "statements need from and to dates as YYYY-MM-DD with from not after to, and a format of csv, json or mt940"
+	AccountDebitsNotAllowed     = "account status does not allow debits"
+	AccountCreditsNotAllowed    = "account status does not allow credits"
+	InvalidAccountStatus        = "status must be active, dormant, frozen, post_no_debit, post_no_credit or closed, with a known reason_code"
+	AccountAlreadyClosed        = "account is closed and its status cannot change"
+	AccountStatusUnchanged      = "account already has this status"
+	AccountNotEmpty             = "account must have a zero balance and no held funds to be closed"
+	AccountStatusSavedMsg       = "account status is saved"
+	AccountStatusFoundMsg       = "account status retrieved"
+	UsernameTaken               = "username is already taken"
+	UserRegisteredMsg           = "user is registered"
+	ProfileFoundMsg             = "profile retrieved"
+	ProfileUpdatedMsg           = "profile is updated"
+	AuthorizationHeader         = "Authorization"
+	InvalidCredentials          = "incorrect username or password"
+	LoginLocked                 = "signing in is locked after too many incorrect attempts, try again later"
+	LoginSuccessMsg             = "login is successful"
+	AccessTokenRequired         = "a bearer access token is required in the Authorization header"
+	InvalidAccessToken          = "access token is invalid or has expired"
+	TransferAccessDenied        = "only the account owner can transfer from this account"
+	TrivialPin                  = "new PIN must not be a repeated digit or a run of consecutive digits such as 0000 or 1234"
+	PinUnchanged                = "new PIN must differ from the current PIN"
+	InvalidPinResetCode         = "PIN reset code is incorrect, used or expired, request a new one"
+	PinChangedMsg               = "transaction PIN is changed"
+	PinResetCodeSentMsg         = "PIN reset code is sent"
+	PermissionDenied            = "your role does not allow this action"
+	OwnerAccessDenied           = "only the account owner can do this"
+	BulkTransferAccessDenied    = "only the user who submitted the batch or staff can access it"
+	InvalidRole                 = "role must be customer, teller, ops, admin or auditor"
+	UserNotFound                = "user not found"
+	RoleAssignedMsg             = "role is assigned"
 )
@@ -0,0 +1,26 @@
+package handler
+
+import "github.com/gin-gonic/gin"
+
+type IAccountStatusService interface {
+	Change(context *gin.Context)
+	Get(context *gin.Context)
+}
+
+type AccountStatusHandler struct {
+	AccountStatusService IAccountStatusService
+}
+
+func NewAccountStatusHandler(service IAccountStatusService) *AccountStatusHandler {
+	return &AccountStatusHandler{
+		AccountStatusService: service,
+	}
+}
+
+func (a *AccountStatusHandler) Change(context *gin.Context) {
+	a.AccountStatusService.Change(context)
+}
+
+func (a *AccountStatusHandler) Get(context *gin.Context) {
+	a.AccountStatusService.Get(context)
+}
@@ -0,0 +1,50 @@
+package handler // nolint:typecheck
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockAccountStatusService struct{ mock.Mock }
+
+func (m *MockAccountStatusService) Change(context *gin.Context) {
+	m.Called(context).Get(0)
+}
+
+func (m *MockAccountStatusService) Get(context *gin.Context) {
+	m.Called(context).Get(0)
+}
+
+func Test_NewAccountStatusHandler(t *testing.T) {
+	mockService := new(MockAccountStatusService)
+	accountStatusHandler := NewAccountStatusHandler(mockService)
+	assert.NotNil(t, accountStatusHandler)
+	assert.Equal(t, mockService, accountStatusHandler.AccountStatusService)
+}
+
+func Test_AccountStatusHandler(t *testing.T) {
+	mockService := new(MockAccountStatusService)
+	accountStatusHandler := NewAccountStatusHandler(mockService)
+	testCases := []struct {
+		name        string
+		method      string
+		handlerFunc func(*gin.Context)
+	}{
+		{name: "Change test case", method: "Change", handlerFunc: accountStatusHandler.Change},
+		{name: "Get test case", method: "Get", handlerFunc: accountStatusHandler.Get},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			ctx, _ := gin.CreateTestContext(recorder)
+			gin.SetMode(gin.TestMode)
+			mockService.On(tt.method, ctx).Return(mock.Anything)
+			tt.handlerFunc(ctx)
+			mockService.AssertCalled(t, tt.method, ctx)
+		})
+	}
+}
@@ -0,0 +1,21 @@
+package handler
+
+import "github.com/gin-gonic/gin"
+
+type IAuthService interface {
+	Login(context *gin.Context)
+}
+
+type AuthHandler struct {
+	AuthService IAuthService
+}
+
+func NewAuthHandler(service IAuthService) *AuthHandler {
+	return &AuthHandler{
+		AuthService: service,
+	}
+}
+
+func (a *AuthHandler) Login(context *gin.Context) {
+	a.AuthService.Login(context)
+}
@@ -0,0 +1,33 @@
+package handler // nolint:typecheck
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockAuthService struct{ mock.Mock }
+
+func (m *MockAuthService) Login(context *gin.Context) {
+	m.Called(context).Get(0)
+}
+
+func Test_NewAuthHandler(t *testing.T) {
+	mockService := new(MockAuthService)
+	authHandler := NewAuthHandler(mockService)
+	assert.NotNil(t, authHandler)
+	assert.Equal(t, mockService, authHandler.AuthService)
+}
+
+func Test_AuthHandler(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(recorder)
+	gin.SetMode(gin.TestMode)
+	mockService := new(MockAuthService)
+	mockService.On("Login", ctx).Return(mock.Anything)
+	NewAuthHandler(mockService).Login(ctx)
+	mockService.AssertCalled(t, "Login", ctx)
+}
@@ -0,0 +1,21 @@
+package handler
+
+import "github.com/gin-gonic/gin"
+
+type IBalanceService interface {
+	Balance(context *gin.Context)
+}
+
+type BalanceHandler struct {
+	BalanceService IBalanceService
+}
+
+func NewBalanceHandler(service IBalanceService) *BalanceHandler {
+	return &BalanceHandler{
+		BalanceService: service,
+	}
+}
+
+func (b *BalanceHandler) Balance(context *gin.Context) {
+	b.BalanceService.Balance(context)
+}
@@ -0,0 +1,45 @@
+package handler // nolint:typecheck
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockBalanceService struct{ mock.Mock }
+
+func (m *MockBalanceService) Balance(context *gin.Context) {
+	m.Called(context).Get(0)
+}
+
+func Test_NewBalanceHandler(t *testing.T) {
+	mockService := new(MockBalanceService)
+	balanceHandler := NewBalanceHandler(mockService)
+	assert.NotNil(t, balanceHandler)
+	assert.Equal(t, mockService, balanceHandler.BalanceService)
+}
+
+func Test_BalanceHandler(t *testing.T) {
+	mockService := new(MockBalanceService)
+	balanceHandler := NewBalanceHandler(mockService)
+	testCases := []struct {
+		name        string
+		method      string
+		handlerFunc func(*gin.Context)
+	}{
+		{name: "Balance test case", method: "Balance", handlerFunc: balanceHandler.Balance},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			ctx, _ := gin.CreateTestContext(recorder)
+			gin.SetMode(gin.TestMode)
+			mockService.On(tt.method, ctx).Return(mock.Anything)
+			tt.handlerFunc(ctx)
+			mockService.AssertCalled(t, tt.method, ctx)
+		})
+	}
+}
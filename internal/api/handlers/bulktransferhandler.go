@@ -0,0 +1,31 @@
+package handler
+
+import "github.com/gin-gonic/gin"
+
+type IBulkTransferService interface {
+	Submit(context *gin.Context)
+	Progress(context *gin.Context)
+	Results(context *gin.Context)
+}
+
+type BulkTransferHandler struct {
+	BulkTransferService IBulkTransferService
+}
+
+func NewBulkTransferHandler(service IBulkTransferService) *BulkTransferHandler {
+	return &BulkTransferHandler{
+		BulkTransferService: service,
+	}
+}
+
+func (b *BulkTransferHandler) Submit(context *gin.Context) {
+	b.BulkTransferService.Submit(context)
+}
+
+func (b *BulkTransferHandler) Progress(context *gin.Context) {
+	b.BulkTransferService.Progress(context)
+}
+
+func (b *BulkTransferHandler) Results(context *gin.Context) {
+	b.BulkTransferService.Results(context)
+}
@@ -0,0 +1,55 @@
+package handler // nolint:typecheck
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockBulkTransferService struct{ mock.Mock }
+
+func (m *MockBulkTransferService) Submit(context *gin.Context) {
+	m.Called(context).Get(0)
+}
+
+func (m *MockBulkTransferService) Progress(context *gin.Context) {
+	m.Called(context).Get(0)
+}
+
+func (m *MockBulkTransferService) Results(context *gin.Context) {
+	m.Called(context).Get(0)
+}
+
+func Test_NewBulkTransferHandler(t *testing.T) {
+	mockService := new(MockBulkTransferService)
+	bulkTransferHandler := NewBulkTransferHandler(mockService)
+	assert.NotNil(t, bulkTransferHandler)
+	assert.Equal(t, mockService, bulkTransferHandler.BulkTransferService)
+}
+
+func Test_BulkTransferHandler(t *testing.T) {
+	mockService := new(MockBulkTransferService)
+	bulkTransferHandler := NewBulkTransferHandler(mockService)
+	testCases := []struct {
+		name        string
+		method      string
+		handlerFunc func(*gin.Context)
+	}{
+		{name: "Submit test case", method: "Submit", handlerFunc: bulkTransferHandler.Submit},
+		{name: "Progress test case", method: "Progress", handlerFunc: bulkTransferHandler.Progress},
+		{name: "Results test case", method: "Results", handlerFunc: bulkTransferHandler.Results},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			ctx, _ := gin.CreateTestContext(recorder)
+			gin.SetMode(gin.TestMode)
+			mockService.On(tt.method, ctx).Return(mock.Anything)
+			tt.handlerFunc(ctx)
+			mockService.AssertCalled(t, tt.method, ctx)
+		})
+	}
+}
@@ -0,0 +1,26 @@
+package handler
+
+import "github.com/gin-gonic/gin"
+
+type IExchangeRateService interface {
+	Update(context *gin.Context)
+	List(context *gin.Context)
+}
+
+type ExchangeRateHandler struct {
+	ExchangeRateService IExchangeRateService
+}
+
+func NewExchangeRateHandler(service IExchangeRateService) *ExchangeRateHandler {
+	return &ExchangeRateHandler{
+		ExchangeRateService: service,
+	}
+}
+
+func (e *ExchangeRateHandler) Update(context *gin.Context) {
+	e.ExchangeRateService.Update(context)
+}
+
+func (e *ExchangeRateHandler) List(context *gin.Context) {
+	e.ExchangeRateService.List(context)
+}
@@ -0,0 +1,50 @@
+package handler // nolint:typecheck
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockExchangeRateService struct{ mock.Mock }
+
+func (m *MockExchangeRateService) Update(context *gin.Context) {
+	m.Called(context).Get(0)
+}
+
+func (m *MockExchangeRateService) List(context *gin.Context) {
+	m.Called(context).Get(0)
+}
+
+func Test_NewExchangeRateHandler(t *testing.T) {
+	mockService := new(MockExchangeRateService)
+	exchangeRateHandler := NewExchangeRateHandler(mockService)
+	assert.NotNil(t, exchangeRateHandler)
+	assert.Equal(t, mockService, exchangeRateHandler.ExchangeRateService)
+}
+
+func Test_ExchangeRateHandler(t *testing.T) {
+	mockService := new(MockExchangeRateService)
+	exchangeRateHandler := NewExchangeRateHandler(mockService)
+	testCases := []struct {
+		name        string
+		method      string
+		handlerFunc func(*gin.Context)
+	}{
+		{name: "Update test case", method: "Update", handlerFunc: exchangeRateHandler.Update},
+		{name: "List test case", method: "List", handlerFunc: exchangeRateHandler.List},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			ctx, _ := gin.CreateTestContext(recorder)
+			gin.SetMode(gin.TestMode)
+			mockService.On(tt.method, ctx).Return(mock.Anything)
+			tt.handlerFunc(ctx)
+			mockService.AssertCalled(t, tt.method, ctx)
+		})
+	}
+}
@@ -0,0 +1,26 @@
+package handler
+
+import "github.com/gin-gonic/gin"
+
+type IFeeService interface {
+	Update(context *gin.Context)
+	List(context *gin.Context)
+}
+
+type FeeHandler struct {
+	FeeService IFeeService
+}
+
+func NewFeeHandler(service IFeeService) *FeeHandler {
+	return &FeeHandler{
+		FeeService: service,
+	}
+}
+
+func (f *FeeHandler) Update(context *gin.Context) {
+	f.FeeService.Update(context)
+}
+
+func (f *FeeHandler) List(context *gin.Context) {
+	f.FeeService.List(context)
+}
@@ -0,0 +1,50 @@
+package handler // nolint:typecheck
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockFeeService struct{ mock.Mock }
+
+func (m *MockFeeService) Update(context *gin.Context) {
+	m.Called(context).Get(0)
+}
+
+func (m *MockFeeService) List(context *gin.Context) {
+	m.Called(context).Get(0)
+}
+
+func Test_NewFeeHandler(t *testing.T) {
+	mockService := new(MockFeeService)
+	feeHandler := NewFeeHandler(mockService)
+	assert.NotNil(t, feeHandler)
+	assert.Equal(t, mockService, feeHandler.FeeService)
+}
+
+func Test_FeeHandler(t *testing.T) {
+	mockService := new(MockFeeService)
+	feeHandler := NewFeeHandler(mockService)
+	testCases := []struct {
+		name        string
+		method      string
+		handlerFunc func(*gin.Context)
+	}{
+		{name: "Update test case", method: "Update", handlerFunc: feeHandler.Update},
+		{name: "List test case", method: "List", handlerFunc: feeHandler.List},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			ctx, _ := gin.CreateTestContext(recorder)
+			gin.SetMode(gin.TestMode)
+			mockService.On(tt.method, ctx).Return(mock.Anything)
+			tt.handlerFunc(ctx)
+			mockService.AssertCalled(t, tt.method, ctx)
+		})
+	}
+}
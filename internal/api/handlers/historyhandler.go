@@ -0,0 +1,21 @@
+package handler
+
+import "github.com/gin-gonic/gin"
+
+type ITransactionHistoryService interface {
+	List(context *gin.Context)
+}
+
+type TransactionHistoryHandler struct {
+	TransactionHistoryService ITransactionHistoryService
+}
+
+func NewTransactionHistoryHandler(service ITransactionHistoryService) *TransactionHistoryHandler {
+	return &TransactionHistoryHandler{
+		TransactionHistoryService: service,
+	}
+}
+
+func (h *TransactionHistoryHandler) List(context *gin.Context) {
+	h.TransactionHistoryService.List(context)
+}
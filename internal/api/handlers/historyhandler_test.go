@@ -0,0 +1,45 @@
+package handler // nolint:typecheck
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockTransactionHistoryService struct{ mock.Mock }
+
+func (m *MockTransactionHistoryService) List(context *gin.Context) {
+	m.Called(context).Get(0)
+}
+
+func Test_NewTransactionHistoryHandler(t *testing.T) {
+	mockService := new(MockTransactionHistoryService)
+	historyHandler := NewTransactionHistoryHandler(mockService)
+	assert.NotNil(t, historyHandler)
+	assert.Equal(t, mockService, historyHandler.TransactionHistoryService)
+}
+
+func Test_TransactionHistoryHandler(t *testing.T) {
+	mockService := new(MockTransactionHistoryService)
+	historyHandler := NewTransactionHistoryHandler(mockService)
+	testCases := []struct {
+		name        string
+		method      string
+		handlerFunc func(*gin.Context)
+	}{
+		{name: "List test case", method: "List", handlerFunc: historyHandler.List},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			ctx, _ := gin.CreateTestContext(recorder)
+			gin.SetMode(gin.TestMode)
+			mockService.On(tt.method, ctx).Return(mock.Anything)
+			tt.handlerFunc(ctx)
+			mockService.AssertCalled(t, tt.method, ctx)
+		})
+	}
+}
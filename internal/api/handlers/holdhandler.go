@@ -0,0 +1,36 @@
+package handler
+
+import "github.com/gin-gonic/gin"
+
+type IHoldService interface {
+	Place(context *gin.Context)
+	Get(context *gin.Context)
+	Capture(context *gin.Context)
+	Release(context *gin.Context)
+}
+
+type HoldHandler struct {
+	HoldService IHoldService
+}
+
+func NewHoldHandler(service IHoldService) *HoldHandler {
+	return &HoldHandler{
+		HoldService: service,
+	}
+}
+
+func (h *HoldHandler) Place(context *gin.Context) {
+	h.HoldService.Place(context)
+}
+
+func (h *HoldHandler) Get(context *gin.Context) {
+	h.HoldService.Get(context)
+}
+
+func (h *HoldHandler) Capture(context *gin.Context) {
+	h.HoldService.Capture(context)
+}
+
+func (h *HoldHandler) Release(context *gin.Context) {
+	h.HoldService.Release(context)
+}
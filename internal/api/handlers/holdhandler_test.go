@@ -0,0 +1,60 @@
+package handler // nolint:typecheck
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockHoldService struct{ mock.Mock }
+
+func (m *MockHoldService) Place(context *gin.Context) {
+	m.Called(context).Get(0)
+}
+
+func (m *MockHoldService) Get(context *gin.Context) {
+	m.Called(context).Get(0)
+}
+
+func (m *MockHoldService) Capture(context *gin.Context) {
+	m.Called(context).Get(0)
+}
+
+func (m *MockHoldService) Release(context *gin.Context) {
+	m.Called(context).Get(0)
+}
+
+func Test_NewHoldHandler(t *testing.T) {
+	mockService := new(MockHoldService)
+	holdHandler := NewHoldHandler(mockService)
+	assert.NotNil(t, holdHandler)
+	assert.Equal(t, mockService, holdHandler.HoldService)
+}
+
+func Test_HoldHandler(t *testing.T) {
+	mockService := new(MockHoldService)
+	holdHandler := NewHoldHandler(mockService)
+	testCases := []struct {
+		name        string
+		method      string
+		handlerFunc func(*gin.Context)
+	}{
+		{name: "Place test case", method: "Place", handlerFunc: holdHandler.Place},
+		{name: "Get test case", method: "Get", handlerFunc: holdHandler.Get},
+		{name: "Capture test case", method: "Capture", handlerFunc: holdHandler.Capture},
+		{name: "Release test case", method: "Release", handlerFunc: holdHandler.Release},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			ctx, _ := gin.CreateTestContext(recorder)
+			gin.SetMode(gin.TestMode)
+			mockService.On(tt.method, ctx).Return(mock.Anything)
+			tt.handlerFunc(ctx)
+			mockService.AssertCalled(t, tt.method, ctx)
+		})
+	}
+}
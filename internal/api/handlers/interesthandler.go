@@ -0,0 +1,36 @@
+package handler
+
+import "github.com/gin-gonic/gin"
+
+type IInterestService interface {
+	SaveProduct(context *gin.Context)
+	ListProducts(context *gin.Context)
+	AssignProduct(context *gin.Context)
+	Interest(context *gin.Context)
+}
+
+type InterestHandler struct {
+	InterestService IInterestService
+}
+
+func NewInterestHandler(service IInterestService) *InterestHandler {
+	return &InterestHandler{
+		InterestService: service,
+	}
+}
+
+func (i *InterestHandler) SaveProduct(context *gin.Context) {
+	i.InterestService.SaveProduct(context)
+}
+
+func (i *InterestHandler) ListProducts(context *gin.Context) {
+	i.InterestService.ListProducts(context)
+}
+
+func (i *InterestHandler) AssignProduct(context *gin.Context) {
+	i.InterestService.AssignProduct(context)
+}
+
+func (i *InterestHandler) Interest(context *gin.Context) {
+	i.InterestService.Interest(context)
+}
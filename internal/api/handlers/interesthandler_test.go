@@ -0,0 +1,60 @@
+package handler // nolint:typecheck
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockInterestService struct{ mock.Mock }
+
+func (m *MockInterestService) SaveProduct(context *gin.Context) {
+	m.Called(context).Get(0)
+}
+
+func (m *MockInterestService) ListProducts(context *gin.Context) {
+	m.Called(context).Get(0)
+}
+
+func (m *MockInterestService) AssignProduct(context *gin.Context) {
+	m.Called(context).Get(0)
+}
+
+func (m *MockInterestService) Interest(context *gin.Context) {
+	m.Called(context).Get(0)
+}
+
+func Test_NewInterestHandler(t *testing.T) {
+	mockService := new(MockInterestService)
+	interestHandler := NewInterestHandler(mockService)
+	assert.NotNil(t, interestHandler)
+	assert.Equal(t, mockService, interestHandler.InterestService)
+}
+
+func Test_InterestHandler(t *testing.T) {
+	mockService := new(MockInterestService)
+	interestHandler := NewInterestHandler(mockService)
+	testCases := []struct {
+		name        string
+		method      string
+		handlerFunc func(*gin.Context)
+	}{
+		{name: "SaveProduct test case", method: "SaveProduct", handlerFunc: interestHandler.SaveProduct},
+		{name: "ListProducts test case", method: "ListProducts", handlerFunc: interestHandler.ListProducts},
+		{name: "AssignProduct test case", method: "AssignProduct", handlerFunc: interestHandler.AssignProduct},
+		{name: "Interest test case", method: "Interest", handlerFunc: interestHandler.Interest},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			ctx, _ := gin.CreateTestContext(recorder)
+			gin.SetMode(gin.TestMode)
+			mockService.On(tt.method, ctx).Return(mock.Anything)
+			tt.handlerFunc(ctx)
+			mockService.AssertCalled(t, tt.method, ctx)
+		})
+	}
+}
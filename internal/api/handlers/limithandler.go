@@ -0,0 +1,31 @@
+package handler
+
+import "github.com/gin-gonic/gin"
+
+type ILimitService interface {
+	SetTierLimit(context *gin.Context)
+	SetAccountLimit(context *gin.Context)
+	Headroom(context *gin.Context)
+}
+
+type LimitHandler struct {
+	LimitService ILimitService
+}
+
+func NewLimitHandler(service ILimitService) *LimitHandler {
+	return &LimitHandler{
+		LimitService: service,
+	}
+}
+
+func (l *LimitHandler) SetTierLimit(context *gin.Context) {
+	l.LimitService.SetTierLimit(context)
+}
+
+func (l *LimitHandler) SetAccountLimit(context *gin.Context) {
+	l.LimitService.SetAccountLimit(context)
+}
+
+func (l *LimitHandler) Headroom(context *gin.Context) {
+	l.LimitService.Headroom(context)
+}
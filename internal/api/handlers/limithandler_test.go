@@ -0,0 +1,55 @@
+package handler // nolint:typecheck
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockLimitService struct{ mock.Mock }
+
+func (m *MockLimitService) SetTierLimit(context *gin.Context) {
+	m.Called(context).Get(0)
+}
+
+func (m *MockLimitService) SetAccountLimit(context *gin.Context) {
+	m.Called(context).Get(0)
+}
+
+func (m *MockLimitService) Headroom(context *gin.Context) {
+	m.Called(context).Get(0)
+}
+
+func Test_NewLimitHandler(t *testing.T) {
+	mockService := new(MockLimitService)
+	limitHandler := NewLimitHandler(mockService)
+	assert.NotNil(t, limitHandler)
+	assert.Equal(t, mockService, limitHandler.LimitService)
+}
+
+func Test_LimitHandler(t *testing.T) {
+	mockService := new(MockLimitService)
+	limitHandler := NewLimitHandler(mockService)
+	testCases := []struct {
+		name        string
+		method      string
+		handlerFunc func(*gin.Context)
+	}{
+		{name: "SetTierLimit test case", method: "SetTierLimit", handlerFunc: limitHandler.SetTierLimit},
+		{name: "SetAccountLimit test case", method: "SetAccountLimit", handlerFunc: limitHandler.SetAccountLimit},
+		{name: "Headroom test case", method: "Headroom", handlerFunc: limitHandler.Headroom},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			ctx, _ := gin.CreateTestContext(recorder)
+			gin.SetMode(gin.TestMode)
+			mockService.On(tt.method, ctx).Return(mock.Anything)
+			tt.handlerFunc(ctx)
+			mockService.AssertCalled(t, tt.method, ctx)
+		})
+	}
+}
@@ -0,0 +1,31 @@
+package handler
+
+import "github.com/gin-gonic/gin"
+
+type IOverdraftService interface {
+	Set(context *gin.Context)
+	Remove(context *gin.Context)
+	Get(context *gin.Context)
+}
+
+type OverdraftHandler struct {
+	OverdraftService IOverdraftService
+}
+
+func NewOverdraftHandler(service IOverdraftService) *OverdraftHandler {
+	return &OverdraftHandler{
+		OverdraftService: service,
+	}
+}
+
+func (o *OverdraftHandler) Set(context *gin.Context) {
+	o.OverdraftService.Set(context)
+}
+
+func (o *OverdraftHandler) Remove(context *gin.Context) {
+	o.OverdraftService.Remove(context)
+}
+
+func (o *OverdraftHandler) Get(context *gin.Context) {
+	o.OverdraftService.Get(context)
+}
@@ -0,0 +1,55 @@
+package handler // nolint:typecheck
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockOverdraftService struct{ mock.Mock }
+
+func (m *MockOverdraftService) Set(context *gin.Context) {
+	m.Called(context).Get(0)
+}
+
+func (m *MockOverdraftService) Remove(context *gin.Context) {
+	m.Called(context).Get(0)
+}
+
+func (m *MockOverdraftService) Get(context *gin.Context) {
+	m.Called(context).Get(0)
+}
+
+func Test_NewOverdraftHandler(t *testing.T) {
+	mockService := new(MockOverdraftService)
+	overdraftHandler := NewOverdraftHandler(mockService)
+	assert.NotNil(t, overdraftHandler)
+	assert.Equal(t, mockService, overdraftHandler.OverdraftService)
+}
+
+func Test_OverdraftHandler(t *testing.T) {
+	mockService := new(MockOverdraftService)
+	overdraftHandler := NewOverdraftHandler(mockService)
+	testCases := []struct {
+		name        string
+		method      string
+		handlerFunc func(*gin.Context)
+	}{
+		{name: "Set test case", method: "Set", handlerFunc: overdraftHandler.Set},
+		{name: "Remove test case", method: "Remove", handlerFunc: overdraftHandler.Remove},
+		{name: "Get test case", method: "Get", handlerFunc: overdraftHandler.Get},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			ctx, _ := gin.CreateTestContext(recorder)
+			gin.SetMode(gin.TestMode)
+			mockService.On(tt.method, ctx).Return(mock.Anything)
+			tt.handlerFunc(ctx)
+			mockService.AssertCalled(t, tt.method, ctx)
+		})
+	}
+}
@@ -0,0 +1,31 @@
+package handler
+
+import "github.com/gin-gonic/gin"
+
+type IPinService interface {
+	Change(context *gin.Context)
+	RequestReset(context *gin.Context)
+	Reset(context *gin.Context)
+}
+
+type PinHandler struct {
+	PinService IPinService
+}
+
+func NewPinHandler(service IPinService) *PinHandler {
+	return &PinHandler{
+		PinService: service,
+	}
+}
+
+func (p *PinHandler) Change(context *gin.Context) {
+	p.PinService.Change(context)
+}
+
+func (p *PinHandler) RequestReset(context *gin.Context) {
+	p.PinService.RequestReset(context)
+}
+
+func (p *PinHandler) Reset(context *gin.Context) {
+	p.PinService.Reset(context)
+}
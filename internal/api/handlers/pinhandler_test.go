@@ -0,0 +1,55 @@
+package handler // nolint:typecheck
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockPinService struct{ mock.Mock }
+
+func (m *MockPinService) Change(context *gin.Context) {
+	m.Called(context).Get(0)
+}
+
+func (m *MockPinService) RequestReset(context *gin.Context) {
+	m.Called(context).Get(0)
+}
+
+func (m *MockPinService) Reset(context *gin.Context) {
+	m.Called(context).Get(0)
+}
+
+func Test_NewPinHandler(t *testing.T) {
+	mockService := new(MockPinService)
+	pinHandler := NewPinHandler(mockService)
+	assert.NotNil(t, pinHandler)
+	assert.Equal(t, mockService, pinHandler.PinService)
+}
+
+func Test_PinHandler(t *testing.T) {
+	mockService := new(MockPinService)
+	pinHandler := NewPinHandler(mockService)
+	testCases := []struct {
+		name        string
+		method      string
+		handlerFunc func(*gin.Context)
+	}{
+		{name: "Change test case", method: "Change", handlerFunc: pinHandler.Change},
+		{name: "RequestReset test case", method: "RequestReset", handlerFunc: pinHandler.RequestReset},
+		{name: "Reset test case", method: "Reset", handlerFunc: pinHandler.Reset},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			ctx, _ := gin.CreateTestContext(recorder)
+			gin.SetMode(gin.TestMode)
+			mockService.On(tt.method, ctx).Return(mock.Anything)
+			tt.handlerFunc(ctx)
+			mockService.AssertCalled(t, tt.method, ctx)
+		})
+	}
+}
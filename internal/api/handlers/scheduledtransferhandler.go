@@ -0,0 +1,31 @@
+package handler
+
+import "github.com/gin-gonic/gin"
+
+type IScheduledTransferService interface {
+	Create(context *gin.Context)
+	List(context *gin.Context)
+	Cancel(context *gin.Context)
+}
+
+type ScheduledTransferHandler struct {
+	ScheduledTransferService IScheduledTransferService
+}
+
+func NewScheduledTransferHandler(service IScheduledTransferService) *ScheduledTransferHandler {
+	return &ScheduledTransferHandler{
+		ScheduledTransferService: service,
+	}
+}
+
+func (s *ScheduledTransferHandler) Create(context *gin.Context) {
+	s.ScheduledTransferService.Create(context)
+}
+
+func (s *ScheduledTransferHandler) List(context *gin.Context) {
+	s.ScheduledTransferService.List(context)
+}
+
+func (s *ScheduledTransferHandler) Cancel(context *gin.Context) {
+	s.ScheduledTransferService.Cancel(context)
+}
@@ -0,0 +1,55 @@
+package handler // nolint:typecheck
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockScheduledTransferService struct{ mock.Mock }
+
+func (m *MockScheduledTransferService) Create(context *gin.Context) {
+	m.Called(context).Get(0)
+}
+
+func (m *MockScheduledTransferService) List(context *gin.Context) {
+	m.Called(context).Get(0)
+}
+
+func (m *MockScheduledTransferService) Cancel(context *gin.Context) {
+	m.Called(context).Get(0)
+}
+
+func Test_NewScheduledTransferHandler(t *testing.T) {
+	mockService := new(MockScheduledTransferService)
+	scheduledTransferHandler := NewScheduledTransferHandler(mockService)
+	assert.NotNil(t, scheduledTransferHandler)
+	assert.Equal(t, mockService, scheduledTransferHandler.ScheduledTransferService)
+}
+
+func Test_ScheduledTransferHandler(t *testing.T) {
+	mockService := new(MockScheduledTransferService)
+	scheduledTransferHandler := NewScheduledTransferHandler(mockService)
+	testCases := []struct {
+		name        string
+		method      string
+		handlerFunc func(*gin.Context)
+	}{
+		{name: "Create test case", method: "Create", handlerFunc: scheduledTransferHandler.Create},
+		{name: "List test case", method: "List", handlerFunc: scheduledTransferHandler.List},
+		{name: "Cancel test case", method: "Cancel", handlerFunc: scheduledTransferHandler.Cancel},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			ctx, _ := gin.CreateTestContext(recorder)
+			gin.SetMode(gin.TestMode)
+			mockService.On(tt.method, ctx).Return(mock.Anything)
+			tt.handlerFunc(ctx)
+			mockService.AssertCalled(t, tt.method, ctx)
+		})
+	}
+}
@@ -0,0 +1,36 @@
+package handler
+
+import "github.com/gin-gonic/gin"
+
+type IStandingOrderService interface {
+	Create(context *gin.Context)
+	List(context *gin.Context)
+	Executions(context *gin.Context)
+	Cancel(context *gin.Context)
+}
+
+type StandingOrderHandler struct {
+	StandingOrderService IStandingOrderService
+}
+
+func NewStandingOrderHandler(service IStandingOrderService) *StandingOrderHandler {
+	return &StandingOrderHandler{
+		StandingOrderService: service,
+	}
+}
+
+func (s *StandingOrderHandler) Create(context *gin.Context) {
+	s.StandingOrderService.Create(context)
+}
+
+func (s *StandingOrderHandler) List(context *gin.Context) {
+	s.StandingOrderService.List(context)
+}
+
+func (s *StandingOrderHandler) Executions(context *gin.Context) {
+	s.StandingOrderService.Executions(context)
+}
+
+func (s *StandingOrderHandler) Cancel(context *gin.Context) {
+	s.StandingOrderService.Cancel(context)
+}
@@ -0,0 +1,60 @@
+package handler // nolint:typecheck
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockStandingOrderService struct{ mock.Mock }
+
+func (m *MockStandingOrderService) Create(context *gin.Context) {
+	m.Called(context).Get(0)
+}
+
+func (m *MockStandingOrderService) List(context *gin.Context) {
+	m.Called(context).Get(0)
+}
+
+func (m *MockStandingOrderService) Executions(context *gin.Context) {
+	m.Called(context).Get(0)
+}
+
+func (m *MockStandingOrderService) Cancel(context *gin.Context) {
+	m.Called(context).Get(0)
+}
+
+func Test_NewStandingOrderHandler(t *testing.T) {
+	mockService := new(MockStandingOrderService)
+	standingOrderHandler := NewStandingOrderHandler(mockService)
+	assert.NotNil(t, standingOrderHandler)
+	assert.Equal(t, mockService, standingOrderHandler.StandingOrderService)
+}
+
+func Test_StandingOrderHandler(t *testing.T) {
+	mockService := new(MockStandingOrderService)
+	standingOrderHandler := NewStandingOrderHandler(mockService)
+	testCases := []struct {
+		name        string
+		method      string
+		handlerFunc func(*gin.Context)
+	}{
+		{name: "Create test case", method: "Create", handlerFunc: standingOrderHandler.Create},
+		{name: "List test case", method: "List", handlerFunc: standingOrderHandler.List},
+		{name: "Executions test case", method: "Executions", handlerFunc: standingOrderHandler.Executions},
+		{name: "Cancel test case", method: "Cancel", handlerFunc: standingOrderHandler.Cancel},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			ctx, _ := gin.CreateTestContext(recorder)
+			gin.SetMode(gin.TestMode)
+			mockService.On(tt.method, ctx).Return(mock.Anything)
+			tt.handlerFunc(ctx)
+			mockService.AssertCalled(t, tt.method, ctx)
+		})
+	}
+}
@@ -0,0 +1,21 @@
+package handler
+
+import "github.com/gin-gonic/gin"
+
+type IStatementService interface {
+	Statement(context *gin.Context)
+}
+
+type StatementHandler struct {
+	StatementService IStatementService
+}
+
+func NewStatementHandler(service IStatementService) *StatementHandler {
+	return &StatementHandler{
+		StatementService: service,
+	}
+}
+
+func (s *StatementHandler) Statement(context *gin.Context) {
+	s.StatementService.Statement(context)
+}
@@ -0,0 +1,45 @@
+package handler // nolint:typecheck
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockStatementService struct{ mock.Mock }
+
+func (m *MockStatementService) Statement(context *gin.Context) {
+	m.Called(context).Get(0)
+}
+
+func Test_NewStatementHandler(t *testing.T) {
+	mockService := new(MockStatementService)
+	statementHandler := NewStatementHandler(mockService)
+	assert.NotNil(t, statementHandler)
+	assert.Equal(t, mockService, statementHandler.StatementService)
+}
+
+func Test_StatementHandler(t *testing.T) {
+	mockService := new(MockStatementService)
+	statementHandler := NewStatementHandler(mockService)
+	testCases := []struct {
+		name        string
+		method      string
+		handlerFunc func(*gin.Context)
+	}{
+		{name: "Statement test case", method: "Statement", handlerFunc: statementHandler.Statement},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			ctx, _ := gin.CreateTestContext(recorder)
+			gin.SetMode(gin.TestMode)
+			mockService.On(tt.method, ctx).Return(mock.Anything)
+			tt.handlerFunc(ctx)
+			mockService.AssertCalled(t, tt.method, ctx)
+		})
+	}
+}
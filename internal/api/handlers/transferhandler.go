@@ -5,6 +5,8 @@ import "github.com/gin-gonic/gin"
 type IBankTransferService interface {
 	StatusQuery(context *gin.Context)
 	Transfer(context *gin.Context)
+	InternalTransfer(context *gin.Context)
+	Reverse(context *gin.Context)
 }
 
 type BankTransferHandler struct {
@@ -24,3 +26,11 @@ func (b *BankTransferHandler) Transfer(context *gin.Context) {
 func (b *BankTransferHandler) StatusQuery(context *gin.Context) {
 	b.BankTransferService.StatusQuery(context)
 }
+
+func (b *BankTransferHandler) InternalTransfer(context *gin.Context) {
+	b.BankTransferService.InternalTransfer(context)
+}
+
+func (b *BankTransferHandler) Reverse(context *gin.Context) {
+	b.BankTransferService.Reverse(context)
+}
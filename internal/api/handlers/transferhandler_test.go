@@ -31,6 +31,14 @@ func (m *MockBankTransferService) Transfer(context *gin.Context) {
 	m.Called(context).Get(0)
 }
 
+func (m *MockBankTransferService) InternalTransfer(context *gin.Context) {
+	m.Called(context).Get(0)
+}
+
+func (m *MockBankTransferService) Reverse(context *gin.Context) {
+	m.Called(context).Get(0)
+}
+
 func Test_NewBankTransferHandler(t *testing.T) {
 	mockBankTransferService := new(MockBankTransferService)
 	transferHandler := NewBankTransferHandler(mockBankTransferService)
@@ -89,3 +97,25 @@ func Test_Transfer(t *testing.T) {
 		})
 	}
 }
+
+func Test_InternalTransfer(t *testing.T) {
+	mockBankTransferService := new(MockBankTransferService)
+	transferHandler := NewBankTransferHandler(mockBankTransferService)
+	recorder := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(recorder)
+	gin.SetMode(gin.TestMode)
+	mockBankTransferService.On("InternalTransfer", ctx).Return(mock.Anything)
+	transferHandler.InternalTransfer(ctx)
+	mockBankTransferService.AssertCalled(t, "InternalTransfer", ctx)
+}
+
+func Test_Reverse(t *testing.T) {
+	mockBankTransferService := new(MockBankTransferService)
+	transferHandler := NewBankTransferHandler(mockBankTransferService)
+	recorder := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(recorder)
+	gin.SetMode(gin.TestMode)
+	mockBankTransferService.On("Reverse", ctx).Return(mock.Anything)
+	transferHandler.Reverse(ctx)
+	mockBankTransferService.AssertCalled(t, "Reverse", ctx)
+}
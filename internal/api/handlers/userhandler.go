@@ -0,0 +1,36 @@
+package handler
+
+import "github.com/gin-gonic/gin"
+
+type IUserService interface {
+	Register(context *gin.Context)
+	Profile(context *gin.Context)
+	UpdateProfile(context *gin.Context)
+	AssignRole(context *gin.Context)
+}
+
+type UserHandler struct {
+	UserService IUserService
+}
+
+func NewUserHandler(service IUserService) *UserHandler {
+	return &UserHandler{
+		UserService: service,
+	}
+}
+
+func (u *UserHandler) Register(context *gin.Context) {
+	u.UserService.Register(context)
+}
+
+func (u *UserHandler) Profile(context *gin.Context) {
+	u.UserService.Profile(context)
+}
+
+func (u *UserHandler) UpdateProfile(context *gin.Context) {
+	u.UserService.UpdateProfile(context)
+}
+
+func (u *UserHandler) AssignRole(context *gin.Context) {
+	u.UserService.AssignRole(context)
+}
@@ -0,0 +1,60 @@
+package handler // nolint:typecheck
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockUserService struct{ mock.Mock }
+
+func (m *MockUserService) Register(context *gin.Context) {
+	m.Called(context).Get(0)
+}
+
+func (m *MockUserService) Profile(context *gin.Context) {
+	m.Called(context).Get(0)
+}
+
+func (m *MockUserService) UpdateProfile(context *gin.Context) {
+	m.Called(context).Get(0)
+}
+
+func (m *MockUserService) AssignRole(context *gin.Context) {
+	m.Called(context).Get(0)
+}
+
+func Test_NewUserHandler(t *testing.T) {
+	mockService := new(MockUserService)
+	userHandler := NewUserHandler(mockService)
+	assert.NotNil(t, userHandler)
+	assert.Equal(t, mockService, userHandler.UserService)
+}
+
+func Test_UserHandler(t *testing.T) {
+	mockService := new(MockUserService)
+	userHandler := NewUserHandler(mockService)
+	testCases := []struct {
+		name        string
+		method      string
+		handlerFunc func(*gin.Context)
+	}{
+		{name: "Register test case", method: "Register", handlerFunc: userHandler.Register},
+		{name: "Profile test case", method: "Profile", handlerFunc: userHandler.Profile},
+		{name: "UpdateProfile test case", method: "UpdateProfile", handlerFunc: userHandler.UpdateProfile},
+		{name: "AssignRole test case", method: "AssignRole", handlerFunc: userHandler.AssignRole},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			ctx, _ := gin.CreateTestContext(recorder)
+			gin.SetMode(gin.TestMode)
+			mockService.On(tt.method, ctx).Return(mock.Anything)
+			tt.handlerFunc(ctx)
+			mockService.AssertCalled(t, tt.method, ctx)
+		})
+	}
+}
@@ -0,0 +1,179 @@
+package middleware
+
+import (
+	"bankingApp/internal/api/constants"
+	"bankingApp/internal/model"
+	"bankingApp/internal/token"
+	"bankingApp/internal/utility"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+const (
+	bearerPrefix = "Bearer "
+	claimsKey    = "claims"
+	// accountOwnership is what a user who does not own the account of the request is denied
+	accountOwnership = "account ownership"
+)
+
+// moneyMovement holds the fields of a request naming the user who makes it and the accounts it moves money on
+type moneyMovement struct {
+	Username            string `json:"username"`
+	AccountNumber       string `json:"account_number"`
+	SourceAccountNumber string `json:"source_account_number"`
+}
+
+// accountNumbers returns the numbers of the accounts the request moves money on
+func (m moneyMovement) accountNumbers() []string {
+	var numbers []string
+	for _, number := range []string{m.AccountNumber, m.SourceAccountNumber} {
+		if number != "" {
+			numbers = append(numbers, number)
+		}
+	}
+	return numbers
+}
+
+type ITokenParser interface {
+	Parse(accessToken string) (*token.Claims, error)
+}
+
+type IUserRoleRepository interface {
+	FindUserByUsername(username string) (model.User, error)
+}
+
+type IAccountOwnerRepository interface {
+	GetAccountByAccountNumber(accountNumber string) (*model.Account, error)
+}
+
+// AuthMiddleware authenticates requests with the access token in their Authorization header
+type AuthMiddleware struct {
+	Tokens   ITokenParser
+	Users    IUserRoleRepository
+	Accounts IAccountOwnerRepository
+}
+
+// Authenticate rejects requests without a valid bearer access token and keeps the claims of the token
+// on the context for the handlers that follow. The role in the claims is replaced with the current role of
+// the user, so a role change applies from the next request rather than when the token expires, and tokens
+// of users that no longer exist are refused.
+func (a *AuthMiddleware) Authenticate() gin.HandlerFunc {
+	return func(context *gin.Context) {
+		header := context.GetHeader(constants.AuthorizationHeader)
+		if !strings.HasPrefix(header, bearerPrefix) {
+			context.AbortWithStatusJSON(http.StatusUnauthorized, utility.FormulateErrorResponse(constants.AccessTokenRequired))
+			return
+		}
+
+		claims, err := a.Tokens.Parse(strings.TrimPrefix(header, bearerPrefix))
+		if err != nil {
+			context.AbortWithStatusJSON(http.StatusUnauthorized, utility.FormulateErrorResponse(constants.InvalidAccessToken))
+			return
+		}
+
+		user, err := a.Users.FindUserByUsername(claims.Subject)
+		if err != nil {
+			utility.HandleError(context, err, http.StatusInternalServerError, constants.ApplicationError)
+			context.Abort()
+			return
+		}
+		if user.UserID == constants.Zero || user.UserID != claims.UserID {
+			context.AbortWithStatusJSON(http.StatusUnauthorized, utility.FormulateErrorResponse(constants.InvalidAccessToken))
+			return
+		}
+		claims.Role = string(user.Role)
+		context.Set(claimsKey, claims)
+		context.Next()
+	}
+}
+
+// OwnsAccount only lets a request moving money through when the authenticated user is the one named in it
+// and owns every account it moves money on: the account_number of transfers, holds, scheduled transfers
+// and standing orders, and the source_account_number of internal transfers. It must follow Authenticate.
+func (a *AuthMiddleware) OwnsAccount() gin.HandlerFunc {
+	return func(context *gin.Context) {
+		claims, ok := AuthenticatedUser(context)
+		if !ok {
+			context.AbortWithStatusJSON(http.StatusUnauthorized, utility.FormulateErrorResponse(constants.AccessTokenRequired))
+			return
+		}
+
+		body, err := io.ReadAll(context.Request.Body)
+		if err != nil {
+			utility.HandleError(context, err, http.StatusInternalServerError, constants.ApplicationError)
+			context.Abort()
+			return
+		}
+		context.Request.Body = io.NopCloser(bytes.NewBuffer(body))
+
+		var request moneyMovement
+		if err := json.Unmarshal(body, &request); err != nil {
+			context.AbortWithStatusJSON(http.StatusBadRequest, utility.FormulateErrorResponse(constants.BadRequestMessage))
+			return
+		}
+		numbers := request.accountNumbers()
+		if request.Username != claims.Subject || len(numbers) == 0 {
+			LogAccessDenied(context, claims.Subject, claims.Role, accountOwnership)
+			context.AbortWithStatusJSON(http.StatusForbidden, utility.FormulateErrorResponse(constants.TransferAccessDenied))
+			return
+		}
+
+		for _, number := range numbers {
+			account, err := a.Accounts.GetAccountByAccountNumber(number)
+			if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+				utility.HandleError(context, err, http.StatusInternalServerError, constants.ApplicationError)
+				context.Abort()
+				return
+			}
+			if account == nil || account.UserID != claims.UserID {
+				LogAccessDenied(context, claims.Subject, claims.Role, accountOwnership)
+				context.AbortWithStatusJSON(http.StatusForbidden, utility.FormulateErrorResponse(constants.TransferAccessDenied))
+				return
+			}
+		}
+		context.Next()
+	}
+}
+
+// Authorize only lets the request through when the current role of the authenticated user grants the permission.
+// It must follow Authenticate. Denied requests are logged with the user, their role and the permission.
+func (a *AuthMiddleware) Authorize(permission model.Permission) gin.HandlerFunc {
+	return func(context *gin.Context) {
+		claims, ok := AuthenticatedUser(context)
+		if !ok {
+			context.AbortWithStatusJSON(http.StatusUnauthorized, utility.FormulateErrorResponse(constants.AccessTokenRequired))
+			return
+		}
+
+		if !model.UserRole(claims.Role).Can(permission) {
+			LogAccessDenied(context, claims.Subject, claims.Role, string(permission))
+			context.AbortWithStatusJSON(http.StatusForbidden, utility.FormulateErrorResponse(constants.PermissionDenied))
+			return
+		}
+		context.Next()
+	}
+}
+
+// LogAccessDenied logs a request refused to the user, saying what the user was denied
+func LogAccessDenied(context *gin.Context, username, role, denied string) {
+	slog.Warn("access denied", "username", username, "role", role, "denied", denied,
+		"method", context.Request.Method, "path", context.Request.URL.Path, "client_ip", context.ClientIP())
+}
+
+// AuthenticatedUser returns the claims of the access token the request was authenticated with
+func AuthenticatedUser(context *gin.Context) (*token.Claims, bool) {
+	value, ok := context.Get(claimsKey)
+	if !ok {
+		return nil, false
+	}
+	claims, ok := value.(*token.Claims)
+	return claims, ok
+}
@@ -0,0 +1,149 @@
+package middleware
+
+import (
+	"bankingApp/internal/api/constants"
+	"bankingApp/internal/model"
+	"bankingApp/internal/utility"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	maxIdempotencyKeyLength = 255
+	// persistedKey marks a request that persisted a change, so its response is stored even when it is a server error
+	persistedKey = "idempotency_persisted"
+)
+
+type IIdempotencyStore interface {
+	Claim(key, requestHash string) (*model.IdempotencyRecord, bool, error)
+	Complete(key string, statusCode int, responseBody string) error
+	Release(key string) error
+}
+
+// IdempotencyMiddleware replays the stored response for requests retried with the same Idempotency-Key header
+type IdempotencyMiddleware struct {
+	Store IIdempotencyStore
+}
+
+// Idempotent claims the Idempotency-Key of the request before it is handled and persists the response afterwards.
+// A repeated key with an identical payload replays the stored response; a different payload is rejected with a conflict.
+// Keys are scoped to the authenticated user, so one user never sees the responses stored for another.
+// Responses with a server error status release the key so the client can safely retry, unless the handler
+// persisted a change with MarkPersisted, as a retry would then be refused as a duplicate instead.
+func (i *IdempotencyMiddleware) Idempotent() gin.HandlerFunc {
+	return func(context *gin.Context) {
+		key := context.GetHeader(constants.IdempotencyKeyHeader)
+		if key == "" {
+			context.Next()
+			return
+		}
+
+		if len(key) > maxIdempotencyKeyLength {
+			context.AbortWithStatusJSON(http.StatusBadRequest, utility.FormulateErrorResponse(constants.InvalidIdempotencyKey))
+			return
+		}
+
+		body, err := io.ReadAll(context.Request.Body)
+		if err != nil {
+			utility.HandleError(context, err, http.StatusInternalServerError, constants.ApplicationError)
+			context.Abort()
+			return
+		}
+		context.Request.Body = io.NopCloser(bytes.NewBuffer(body))
+
+		key = scopedKey(context, key)
+		hash := requestHash(context.Request.Method, context.FullPath(), body)
+		record, claimed, err := i.Store.Claim(key, hash)
+		if err != nil {
+			utility.HandleError(context, err, http.StatusInternalServerError, constants.ApplicationError)
+			context.Abort()
+			return
+		}
+
+		if !claimed {
+			i.replay(context, record, hash)
+			return
+		}
+
+		recorder := &ResponseWriterType{ResponseWriter: context.Writer, body: &bytes.Buffer{}}
+		context.Writer = recorder
+
+		completed := false
+		defer func() {
+			if !completed {
+				i.release(key)
+			}
+		}()
+
+		context.Next()
+
+		statusCode := recorder.Status()
+		if statusCode >= http.StatusInternalServerError && !context.GetBool(persistedKey) {
+			return
+		}
+
+		if err := i.Store.Complete(key, statusCode, recorder.body.String()); err != nil {
+			slog.Error("unable to store idempotent response", "error", err)
+			return
+		}
+		completed = true
+	}
+}
+
+// MarkPersisted records that the request persisted a change, like a pending transaction, so the idempotency key
+// keeps the response even when the request goes on to fail with a server error
+func MarkPersisted(context *gin.Context) {
+	context.Set(persistedKey, true)
+}
+
+// scopedKey prefixes the Idempotency-Key with the ID of the authenticated user, if any
+func scopedKey(context *gin.Context, key string) string {
+	if claims, ok := AuthenticatedUser(context); ok {
+		return strconv.FormatUint(uint64(claims.UserID), 10) + ":" + key
+	}
+	return key
+}
+
+// replay writes the stored response of a previously completed request
+func (i *IdempotencyMiddleware) replay(context *gin.Context, record *model.IdempotencyRecord, hash string) {
+	if record.RequestHash != hash {
+		context.AbortWithStatusJSON(http.StatusConflict, utility.FormulateErrorResponse(constants.IdempotencyKeyConflict))
+		return
+	}
+
+	if !record.Completed {
+		context.AbortWithStatusJSON(http.StatusConflict, utility.FormulateErrorResponse(constants.IdempotencyKeyInProgress))
+		return
+	}
+
+	context.Header(constants.IdempotencyReplayedHeader, "true")
+	context.Data(record.StatusCode, constants.ContentTypeValue, []byte(record.ResponseBody))
+	context.Abort()
+}
+
+func (i *IdempotencyMiddleware) release(key string) {
+	if err := i.Store.Release(key); err != nil {
+		slog.Error("unable to release idempotency key", "error", err)
+	}
+}
+
+// requestHash fingerprints the request so a reused key can be matched against the original payload
+func requestHash(method, path string, body []byte) string {
+	compacted := &bytes.Buffer{}
+	if err := json.Compact(compacted, body); err != nil {
+		compacted.Reset()
+		compacted.Write(body)
+	}
+	hash := sha256.New()
+	hash.Write([]byte(method + " " + path + "\n"))
+	hash.Write(compacted.Bytes())
+	return hex.EncodeToString(hash.Sum(nil))
+}
@@ -0,0 +1,199 @@
+package middleware_test
+
+import (
+	"bankingApp/internal/api/constants"
+	"bankingApp/internal/api/middleware"
+	"bankingApp/internal/model"
+	"bankingApp/internal/token"
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeIdempotencyStore struct {
+	mu      sync.Mutex
+	records map[string]*model.IdempotencyRecord
+}
+
+func newFakeIdempotencyStore() *fakeIdempotencyStore {
+	return &fakeIdempotencyStore{records: make(map[string]*model.IdempotencyRecord)}
+}
+
+func (f *fakeIdempotencyStore) Claim(key, requestHash string) (*model.IdempotencyRecord, bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if record, ok := f.records[key]; ok {
+		return record, false, nil
+	}
+	record := &model.IdempotencyRecord{IdempotencyKey: key, RequestHash: requestHash}
+	f.records[key] = record
+	return record, true, nil
+}
+
+func (f *fakeIdempotencyStore) Complete(key string, statusCode int, responseBody string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	record := f.records[key]
+	record.StatusCode = statusCode
+	record.ResponseBody = responseBody
+	record.Completed = true
+	return nil
+}
+
+func (f *fakeIdempotencyStore) Release(key string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delete(f.records, key)
+	return nil
+}
+
+func setupIdempotentRouter(store middleware.IIdempotencyStore, statusCode int, calls *int) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	route := gin.New()
+	idempotencyMiddleware := middleware.IdempotencyMiddleware{Store: store}
+	route.POST("/fund-transfer", idempotencyMiddleware.Idempotent(), func(c *gin.Context) {
+		*calls++
+		c.JSON(statusCode, gin.H{"success": statusCode == http.StatusOK, "call": *calls})
+	})
+	return route
+}
+
+func sendIdempotentRequest(route *gin.Engine, key, body string) *httptest.ResponseRecorder {
+	req, _ := http.NewRequest(http.MethodPost, "/fund-transfer", bytes.NewBufferString(body))
+	if key != "" {
+		req.Header.Set(constants.IdempotencyKeyHeader, key)
+	}
+	recorder := httptest.NewRecorder()
+	route.ServeHTTP(recorder, req)
+	return recorder
+}
+
+func Test_IdempotentWithoutKeyPassesThrough(t *testing.T) {
+	calls := 0
+	route := setupIdempotentRouter(newFakeIdempotencyStore(), http.StatusOK, &calls)
+	sendIdempotentRequest(route, "", `{"amount": 100}`)
+	sendIdempotentRequest(route, "", `{"amount": 100}`)
+	assert.Equal(t, 2, calls)
+}
+
+func Test_IdempotentReplaysStoredResponse(t *testing.T) {
+	calls := 0
+	route := setupIdempotentRouter(newFakeIdempotencyStore(), http.StatusOK, &calls)
+
+	first := sendIdempotentRequest(route, "key-1", `{"amount": 100}`)
+	second := sendIdempotentRequest(route, "key-1", `{ "amount":100 }`)
+
+	assert.Equal(t, 1, calls)
+	assert.Equal(t, http.StatusOK, second.Code)
+	assert.Equal(t, first.Body.String(), second.Body.String())
+	assert.Equal(t, "true", second.Header().Get(constants.IdempotencyReplayedHeader))
+}
+
+func Test_IdempotentRejectsDifferentPayload(t *testing.T) {
+	calls := 0
+	route := setupIdempotentRouter(newFakeIdempotencyStore(), http.StatusOK, &calls)
+
+	sendIdempotentRequest(route, "key-1", `{"amount": 100}`)
+	second := sendIdempotentRequest(route, "key-1", `{"amount": 200}`)
+
+	assert.Equal(t, 1, calls)
+	assert.Equal(t, http.StatusConflict, second.Code)
+	assert.Contains(t, second.Body.String(), constants.IdempotencyKeyConflict)
+}
+
+func Test_IdempotentRejectsInProgressRequest(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	route := gin.New()
+	idempotencyMiddleware := middleware.IdempotencyMiddleware{Store: newFakeIdempotencyStore()}
+
+	var retry *httptest.ResponseRecorder
+	route.POST("/fund-transfer", idempotencyMiddleware.Idempotent(), func(c *gin.Context) {
+		// a client retry arriving while the first request is still being handled
+		if retry == nil {
+			retry = httptest.NewRecorder()
+			retry = sendIdempotentRequest(route, "key-1", `{"amount": 100}`)
+		}
+		c.JSON(http.StatusOK, gin.H{"success": true})
+	})
+
+	first := sendIdempotentRequest(route, "key-1", `{"amount": 100}`)
+
+	assert.Equal(t, http.StatusOK, first.Code)
+	assert.Equal(t, http.StatusConflict, retry.Code)
+	assert.Contains(t, retry.Body.String(), constants.IdempotencyKeyInProgress)
+}
+
+func Test_IdempotentReleasesKeyOnServerError(t *testing.T) {
+	calls := 0
+	store := newFakeIdempotencyStore()
+	route := setupIdempotentRouter(store, http.StatusInternalServerError, &calls)
+
+	sendIdempotentRequest(route, "key-1", `{"amount": 100}`)
+	sendIdempotentRequest(route, "key-1", `{"amount": 100}`)
+
+	assert.Equal(t, 2, calls)
+	assert.Empty(t, store.records)
+}
+
+func Test_IdempotentKeepsKeyOnServerErrorAfterPersisting(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	store := newFakeIdempotencyStore()
+	route := gin.New()
+	idempotencyMiddleware := middleware.IdempotencyMiddleware{Store: store}
+	calls := 0
+	route.POST("/fund-transfer", idempotencyMiddleware.Idempotent(), func(c *gin.Context) {
+		calls++
+		middleware.MarkPersisted(c)
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false})
+	})
+
+	first := sendIdempotentRequest(route, "key-1", `{"amount": 100}`)
+	second := sendIdempotentRequest(route, "key-1", `{"amount": 100}`)
+
+	assert.Equal(t, 1, calls)
+	assert.Equal(t, http.StatusInternalServerError, second.Code)
+	assert.Equal(t, first.Body.String(), second.Body.String())
+	assert.Equal(t, "true", second.Header().Get(constants.IdempotencyReplayedHeader))
+}
+
+func Test_IdempotentScopesKeysToTheAuthenticatedUser(t *testing.T) {
+	issuer, err := token.NewIssuer("secret", token.AccessTokenTTL)
+	require.NoError(t, err)
+	gin.SetMode(gin.TestMode)
+	route := gin.New()
+	users := newFakeUserRepository(
+		model.User{UserID: 1, Username: "johndoe", Role: model.CustomerRole},
+		model.User{UserID: 2, Username: "janedoe", Role: model.CustomerRole})
+	authMiddleware := middleware.AuthMiddleware{Tokens: issuer, Users: users}
+	idempotencyMiddleware := middleware.IdempotencyMiddleware{Store: newFakeIdempotencyStore()}
+	calls := 0
+	route.POST("/fund-transfer", authMiddleware.Authenticate(), idempotencyMiddleware.Idempotent(), func(c *gin.Context) {
+		calls++
+		c.JSON(http.StatusOK, gin.H{"call": calls})
+	})
+
+	send := func(username string, userID uint) *httptest.ResponseRecorder {
+		accessToken, _, err := issuer.Issue(username, userID, "customer")
+		require.NoError(t, err)
+		req, _ := http.NewRequest(http.MethodPost, "/fund-transfer", bytes.NewBufferString(`{"amount": 100}`))
+		req.Header.Set(constants.IdempotencyKeyHeader, "key-1")
+		req.Header.Set(constants.AuthorizationHeader, "Bearer "+accessToken)
+		recorder := httptest.NewRecorder()
+		route.ServeHTTP(recorder, req)
+		return recorder
+	}
+
+	send("johndoe", 1)
+	other := send("janedoe", 2)
+	replayed := send("johndoe", 1)
+
+	assert.Equal(t, 2, calls)
+	assert.Empty(t, other.Header().Get(constants.IdempotencyReplayedHeader))
+	assert.Equal(t, "true", replayed.Header().Get(constants.IdempotencyReplayedHeader))
+}
@@ -12,6 +12,9 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
+// sensitiveFields are the fields whose values are masked before requests and responses are logged
+var sensitiveFields = []string{"password", "transaction_pin", "old_pin", "new_pin", "reset_code", "access_token"}
+
 // ResponseWriterType defines a custom response recorder to capture the status code and response body
 type ResponseWriterType struct {
 	gin.ResponseWriter
@@ -34,7 +37,7 @@ func (l *LoggingMiddleware) RequestLogger() gin.HandlerFunc {
 	return func(context *gin.Context) {
 		body, err := io.ReadAll(context.Request.Body)
 		if err != nil {
-			slog.Error("Error reading request body: %v", err)
+			slog.Error("Error reading request body", "error", err)
 			context.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Internal Server Error"})
 			return
 		}
@@ -46,7 +49,7 @@ func (l *LoggingMiddleware) RequestLogger() gin.HandlerFunc {
 			slog.Info(fmt.Sprintf("URI: %s | Method: %s", uri, method))
 		default:
 			format := "URI: %s | Method: %s | Request to Bank Transfer API => %s"
-			slog.Info(fmt.Sprintf(format, uri, strings.ToLower(method), body))
+			slog.Info(fmt.Sprintf(format, uri, strings.ToLower(method), redact(body)))
 		}
 		context.Next()
 	}
@@ -61,9 +64,71 @@ func (l *LoggingMiddleware) ResponseLogger() gin.HandlerFunc {
 		responseBody := recorder.body.String()
 		var responseMap map[string]interface{}
 		if err := json.Unmarshal([]byte(responseBody), &responseMap); err != nil {
-			slog.Error("Error decoding response body: %v", err)
+			slog.Error("Error decoding response body", "error", err)
 		} else {
-			slog.Info(fmt.Sprintf("Response from Bank Transfer API => %s", responseBody))
+			slog.Info(fmt.Sprintf("Response from Bank Transfer API => %s", redact(recorder.body.Bytes())))
+		}
+	}
+}
+
+// redact masks the sensitive fields of a JSON body wherever they are nested in its objects and arrays.
+// Bodies that are not JSON, like CSV uploads, could hold credentials anywhere, so only their length is kept.
+func redact(body []byte) []byte {
+	if !json.Valid(body) {
+		return lengthOf(body)
+	}
+
+	var value interface{}
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	if err := decoder.Decode(&value); err != nil {
+		return lengthOf(body)
+	}
+	if !mask(value) {
+		return body
+	}
+	redacted, err := json.Marshal(value)
+	if err != nil {
+		return lengthOf(body)
+	}
+	return redacted
+}
+
+// lengthOf describes a body by its length alone
+func lengthOf(body []byte) []byte {
+	return []byte(fmt.Sprintf("<%d bytes>", len(body)))
+}
+
+// mask replaces the values of the sensitive fields in the decoded JSON value and in the objects and arrays
+// nested in it, reporting whether any was masked
+func mask(value interface{}) bool {
+	masked := false
+	switch value := value.(type) {
+	case map[string]interface{}:
+		for key, field := range value {
+			if isSensitive(key) {
+				value[key] = "****"
+				masked = true
+			} else if mask(field) {
+				masked = true
+			}
+		}
+	case []interface{}:
+		for _, element := range value {
+			if mask(element) {
+				masked = true
+			}
+		}
+	}
+	return masked
+}
+
+// isSensitive reports whether the values of the field are masked before they are logged
+func isSensitive(field string) bool {
+	for _, sensitive := range sensitiveFields {
+		if field == sensitive {
+			return true
 		}
 	}
+	return false
 }
@@ -0,0 +1,77 @@
+package middleware
+
+import (
+	"bytes"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Redact(t *testing.T) {
+	testCases := []struct {
+		name     string
+		body     string
+		expected string
+	}{
+		{
+			name:     "masks credentials",
+			body:     `{"username": "janedoe", "password": "correct horse", "transaction_pin": "5678"}`,
+			expected: `{"password":"****","transaction_pin":"****","username":"janedoe"}`,
+		},
+		{
+			name:     "leaves other requests as they are",
+			body:     `{"amount": "100.00"}`,
+			expected: `{"amount": "100.00"}`,
+		},
+		{
+			name:     "masks credentials nested in objects",
+			body:     `{"user": {"username": "janedoe", "password": "correct horse"}}`,
+			expected: `{"user":{"password":"****","username":"janedoe"}}`,
+		},
+		{
+			name: "masks credentials in every element of an array",
+			body: `[{"account_number": "1234567890", "transaction_pin": "1234", "amount": 100.50},` +
+				`{"account_number": "0987654321", "transaction_pin": "5678", "amount": 20}]`,
+			expected: `[{"account_number":"1234567890","amount":100.50,"transaction_pin":"****"},` +
+				`{"account_number":"0987654321","amount":20,"transaction_pin":"****"}]`,
+		},
+		{
+			name:     "logs only the length of bodies that are not JSON",
+			body:     "account_number,username,transaction_pin\n1234567890,janedoe,5678",
+			expected: `<63 bytes>`,
+		},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, string(redact([]byte(tt.body))))
+		})
+	}
+}
+
+func Test_ResponseLoggerMasksAccessToken(t *testing.T) {
+	// ------------ setups ------------
+	var logs bytes.Buffer
+	defaultLogger := slog.Default()
+	slog.SetDefault(slog.New(slog.NewTextHandler(&logs, nil)))
+	defer slog.SetDefault(defaultLogger)
+
+	gin.SetMode(gin.TestMode)
+	loggingMiddleware := LoggingMiddleware{}
+	router := gin.New()
+	router.POST("/auth/login", loggingMiddleware.ResponseLogger(), func(context *gin.Context) {
+		context.JSON(http.StatusOK, gin.H{"data": gin.H{"access_token": "eyJhbGciOiJIUzI1NiJ9", "token_type": "Bearer"}})
+	})
+
+	// ------------ executions -----------
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/auth/login", nil))
+
+	// ------------ assertions -----------
+	assert.Contains(t, recorder.Body.String(), "eyJhbGciOiJIUzI1NiJ9")
+	assert.NotContains(t, logs.String(), "eyJhbGciOiJIUzI1NiJ9")
+	assert.Contains(t, logs.String(), `\"access_token\":\"****\"`)
+}
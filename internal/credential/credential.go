@@ -0,0 +1,43 @@
+package credential
+
+import (
+	"crypto/rand"
+	"math/big"
+	"strings"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Hash hashes the password or transaction PIN of a user with bcrypt, which salts every hash
+func Hash(secret string) (string, error) {
+	hashed, err := bcrypt.GenerateFromPassword([]byte(secret), bcrypt.DefaultCost)
+	if err != nil {
+		return "", err
+	}
+	return string(hashed), nil
+}
+
+// Verify reports whether the secret matches the stored hash, comparing them in constant time. A value that is
+// not a hash never matches, credentials stored before they were hashed are hashed when the app starts.
+func Verify(stored, secret string) bool {
+	return bcrypt.CompareHashAndPassword([]byte(stored), []byte(secret)) == nil
+}
+
+// IsHashed reports whether the stored value is a bcrypt hash
+func IsHashed(stored string) bool {
+	_, err := bcrypt.Cost([]byte(stored))
+	return err == nil
+}
+
+// NewOneTimeCode draws a random code of the given number of decimal digits to be sent to a user
+func NewOneTimeCode(digits int) (string, error) {
+	var code strings.Builder
+	for i := 0; i < digits; i++ {
+		digit, err := rand.Int(rand.Reader, big.NewInt(10))
+		if err != nil {
+			return "", err
+		}
+		code.WriteString(digit.String())
+	}
+	return code.String(), nil
+}
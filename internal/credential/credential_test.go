@@ -0,0 +1,34 @@
+package credential
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_HashIsSaltedAndVerifies(t *testing.T) {
+	first, err := Hash("1234")
+	require.NoError(t, err)
+	second, err := Hash("1234")
+	require.NoError(t, err)
+
+	assert.NotEqual(t, "1234", first)
+	assert.NotEqual(t, first, second)
+	assert.True(t, IsHashed(first))
+	assert.True(t, Verify(first, "1234"))
+	assert.True(t, Verify(second, "1234"))
+	assert.False(t, Verify(first, "4321"))
+}
+
+func Test_VerifyNeverMatchesValuesThatAreNotHashed(t *testing.T) {
+	assert.False(t, IsHashed("1234"))
+	assert.False(t, Verify("1234", "1234"))
+	assert.False(t, Verify("", ""))
+}
+
+func Test_NewOneTimeCode(t *testing.T) {
+	code, err := NewOneTimeCode(6)
+	require.NoError(t, err)
+	assert.Regexp(t, `^[0-9]{6}$`, code)
+}
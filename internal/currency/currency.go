@@ -0,0 +1,105 @@
+package currency
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/govalues/decimal"
+)
+
+// DefaultMinorUnits is used for amounts recorded before accounts and transactions carried a currency
+const DefaultMinorUnits = 2
+
+var (
+	ErrUnsupportedCurrency = errors.New("currency is not supported")
+	ErrTooManyDecimals     = errors.New("amount has more decimal places than the currency allows")
+)
+
+// Currency is an ISO 4217 currency with the number of digits after the decimal point of its minor unit
+type Currency struct {
+	Code       string
+	MinorUnits int
+}
+
+// currencies lists the supported ISO 4217 currencies
+var currencies = map[string]Currency{}
+
+func init() {
+	for _, c := range []Currency{
+		{Code: "AED", MinorUnits: 2},
+		{Code: "AUD", MinorUnits: 2},
+		{Code: "BHD", MinorUnits: 3},
+		{Code: "BRL", MinorUnits: 2},
+		{Code: "CAD", MinorUnits: 2},
+		{Code: "CHF", MinorUnits: 2},
+		{Code: "CNY", MinorUnits: 2},
+		{Code: "CZK", MinorUnits: 2},
+		{Code: "DKK", MinorUnits: 2},
+		{Code: "EGP", MinorUnits: 2},
+		{Code: "EUR", MinorUnits: 2},
+		{Code: "GBP", MinorUnits: 2},
+		{Code: "GHS", MinorUnits: 2},
+		{Code: "HKD", MinorUnits: 2},
+		{Code: "INR", MinorUnits: 2},
+		{Code: "JOD", MinorUnits: 3},
+		{Code: "JPY", MinorUnits: 0},
+		{Code: "KES", MinorUnits: 2},
+		{Code: "KRW", MinorUnits: 0},
+		{Code: "KWD", MinorUnits: 3},
+		{Code: "MXN", MinorUnits: 2},
+		{Code: "NGN", MinorUnits: 2},
+		{Code: "NOK", MinorUnits: 2},
+		{Code: "NZD", MinorUnits: 2},
+		{Code: "OMR", MinorUnits: 3},
+		{Code: "PLN", MinorUnits: 2},
+		{Code: "SAR", MinorUnits: 2},
+		{Code: "SEK", MinorUnits: 2},
+		{Code: "SGD", MinorUnits: 2},
+		{Code: "TND", MinorUnits: 3},
+		{Code: "TRY", MinorUnits: 2},
+		{Code: "UGX", MinorUnits: 0},
+		{Code: "USD", MinorUnits: 2},
+		{Code: "XAF", MinorUnits: 0},
+		{Code: "XOF", MinorUnits: 0},
+		{Code: "ZAR", MinorUnits: 2},
+	} {
+		currencies[c.Code] = c
+	}
+}
+
+// Normalize returns the currency code in the upper case form it is stored in
+func Normalize(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+// Lookup returns the supported currency with the given code, which is case-insensitive
+func Lookup(code string) (Currency, error) {
+	c, ok := currencies[Normalize(code)]
+	if !ok {
+		return Currency{}, fmt.Errorf("%w: %q", ErrUnsupportedCurrency, code)
+	}
+	return c, nil
+}
+
+// MinorUnits returns the number of decimal places of the currency,
+// falling back to DefaultMinorUnits for an empty or unknown code
+func MinorUnits(code string) int {
+	if c, err := Lookup(code); err == nil {
+		return c.MinorUnits
+	}
+	return DefaultMinorUnits
+}
+
+// CheckAmount returns ErrTooManyDecimals when the amount cannot be expressed in the minor unit of the currency
+func (c Currency) CheckAmount(amount decimal.Decimal) error {
+	if amount.MinScale() > c.MinorUnits {
+		return fmt.Errorf("%w: %s takes %d", ErrTooManyDecimals, c.Code, c.MinorUnits)
+	}
+	return nil
+}
+
+// Round rounds the amount to the minor unit of the currency using banker's rounding
+func (c Currency) Round(amount decimal.Decimal) (decimal.Decimal, error) {
+	return amount.Rescale(c.MinorUnits)
+}
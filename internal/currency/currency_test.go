@@ -0,0 +1,145 @@
+package currency
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/govalues/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Lookup(t *testing.T) {
+	testCases := []struct {
+		name       string
+		code       string
+		minorUnits int
+		err        error
+	}{
+		{name: "two minor units", code: "USD", minorUnits: 2},
+		{name: "no minor units", code: "JPY", minorUnits: 0},
+		{name: "three minor units", code: "KWD", minorUnits: 3},
+		{name: "lower case code", code: "eur", minorUnits: 2},
+		{name: "unknown code", code: "XYZ", err: ErrUnsupportedCurrency},
+		{name: "empty code", code: "", err: ErrUnsupportedCurrency},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			c, err := Lookup(tc.code)
+			if tc.err != nil {
+				assert.ErrorIs(t, err, tc.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.minorUnits, c.MinorUnits)
+		})
+	}
+}
+
+func Test_MinorUnitsDefaultsForAccountsWithoutCurrency(t *testing.T) {
+	assert.Equal(t, DefaultMinorUnits, MinorUnits(""))
+	assert.Equal(t, 0, MinorUnits("JPY"))
+}
+
+func Test_CheckAmount(t *testing.T) {
+	testCases := []struct {
+		name   string
+		code   string
+		amount string
+		err    error
+	}{
+		{name: "cents in USD", code: "USD", amount: "10.25"},
+		{name: "trailing zeros are ignored", code: "JPY", amount: "100.00"},
+		{name: "fractional yen", code: "JPY", amount: "100.5", err: ErrTooManyDecimals},
+		{name: "fils in KWD", code: "KWD", amount: "1.125"},
+		{name: "sub-cent USD", code: "USD", amount: "1.005", err: ErrTooManyDecimals},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			c, err := Lookup(tc.code)
+			require.NoError(t, err)
+			err = c.CheckAmount(decimal.MustParse(tc.amount))
+			if tc.err != nil {
+				assert.ErrorIs(t, err, tc.err)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func Test_RateConvertRoundsToTheQuoteCurrency(t *testing.T) {
+	testCases := []struct {
+		name     string
+		rate     Rate
+		amount   string
+		expected string
+	}{
+		{name: "to two minor units", rate: Rate{Base: "USD", Quote: "EUR", Rate: decimal.MustParse("0.9215")},
+			amount: "100.50", expected: "92.61"},
+		{name: "half to even", rate: Rate{Base: "USD", Quote: "EUR", Rate: decimal.MustParse("0.5")},
+			amount: "0.05", expected: "0.02"},
+		{name: "to no minor units", rate: Rate{Base: "USD", Quote: "JPY", Rate: decimal.MustParse("149.3")},
+			amount: "10.00", expected: "1493"},
+		{name: "to three minor units", rate: Rate{Base: "USD", Quote: "KWD", Rate: decimal.MustParse("0.30712")},
+			amount: "10.00", expected: "3.071"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			converted, err := tc.rate.Convert(decimal.MustParse(tc.amount))
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, converted.String())
+		})
+	}
+}
+
+func Test_RateInverse(t *testing.T) {
+	inverse, err := Rate{Base: "USD", Quote: "EUR", Rate: decimal.MustParse("0.8")}.Inverse()
+	require.NoError(t, err)
+	assert.Equal(t, "EUR", inverse.Base)
+	assert.Equal(t, "USD", inverse.Quote)
+	assert.Equal(t, "1.25", inverse.Rate.String())
+
+	inverse, err = Rate{Base: "USD", Quote: "EUR", Rate: decimal.MustParse("3")}.Inverse()
+	require.NoError(t, err)
+	assert.Equal(t, "0.3333333333", inverse.Rate.String())
+}
+
+func Test_RateValidate(t *testing.T) {
+	rate := Rate{Base: "usd", Quote: "eur", Rate: decimal.MustParse("0.92")}
+	require.NoError(t, rate.Validate())
+	assert.Equal(t, "USD", rate.Base)
+	assert.Equal(t, "EUR", rate.Quote)
+
+	assert.ErrorIs(t, (&Rate{Base: "USD", Quote: "USD", Rate: decimal.One}).Validate(), ErrInvalidRate)
+	assert.ErrorIs(t, (&Rate{Base: "USD", Quote: "EUR", Rate: decimal.Zero}).Validate(), ErrInvalidRate)
+	assert.ErrorIs(t, (&Rate{Base: "USD", Quote: "ABC", Rate: decimal.One}).Validate(), ErrUnsupportedCurrency)
+}
+
+func Test_LoadRatesFile(t *testing.T) {
+	dir := t.TempDir()
+	valid := filepath.Join(dir, "rates.json")
+	require.NoError(t, os.WriteFile(valid, []byte(`[
+		{"base_currency": "USD", "quote_currency": "NGN", "rate": "1550.25"},
+		{"base_currency": "eur", "quote_currency": "usd", "rate": "1.0850"}
+	]`), 0o600))
+
+	rates, err := LoadRatesFile(valid)
+	require.NoError(t, err)
+	require.Len(t, rates, 2)
+	assert.Equal(t, Rate{Base: "USD", Quote: "NGN", Rate: decimal.MustParse("1550.25")}, rates[0])
+	assert.Equal(t, "EUR", rates[1].Base)
+	assert.Equal(t, "USD", rates[1].Quote)
+
+	invalid := filepath.Join(dir, "invalid.json")
+	require.NoError(t, os.WriteFile(invalid, []byte(`[{"base_currency": "USD", "quote_currency": "XYZ", "rate": "2"}]`), 0o600))
+	_, err = LoadRatesFile(invalid)
+	assert.ErrorIs(t, err, ErrUnsupportedCurrency)
+
+	_, err = LoadRatesFile(filepath.Join(dir, "missing.json"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,86 @@
+package currency
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/govalues/decimal"
+)
+
+// RateScale is the number of decimal places exchange rates are kept to
+const RateScale = 10
+
+var ErrInvalidRate = errors.New("exchange rate is invalid")
+
+// Rate is the price of one unit of the base currency in the quote currency
+type Rate struct {
+	Base  string          `json:"base_currency"`
+	Quote string          `json:"quote_currency"`
+	Rate  decimal.Decimal `json:"rate"`
+}
+
+// Validate checks that both currencies are supported and distinct and that the rate is positive.
+// The currency codes are normalised to upper case.
+func (r *Rate) Validate() error {
+	base, err := Lookup(r.Base)
+	if err != nil {
+		return err
+	}
+	quote, err := Lookup(r.Quote)
+	if err != nil {
+		return err
+	}
+	if base.Code == quote.Code || !r.Rate.IsPos() {
+		return fmt.Errorf("%w: %s/%s %s", ErrInvalidRate, r.Base, r.Quote, r.Rate)
+	}
+	r.Base, r.Quote = base.Code, quote.Code
+	return nil
+}
+
+// Convert converts an amount in the base currency to the quote currency, rounded to the minor unit of the quote currency
+func (r Rate) Convert(amount decimal.Decimal) (decimal.Decimal, error) {
+	quote, err := Lookup(r.Quote)
+	if err != nil {
+		return decimal.Decimal{}, err
+	}
+	converted, err := amount.Mul(r.Rate)
+	if err != nil {
+		return decimal.Decimal{}, err
+	}
+	return quote.Round(converted)
+}
+
+// Inverse returns the rate quoting the other way round, kept to RateScale decimal places
+func (r Rate) Inverse() (Rate, error) {
+	inverse, err := decimal.One.Quo(r.Rate)
+	if err != nil {
+		return Rate{}, err
+	}
+	inverse, err = inverse.Rescale(RateScale)
+	if err != nil {
+		return Rate{}, err
+	}
+	return Rate{Base: r.Quote, Quote: r.Base, Rate: inverse.Trim(0)}, nil
+}
+
+// LoadRatesFile reads a JSON array of rates from a local file and validates every rate in it
+func LoadRatesFile(path string) ([]Rate, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var rates []Rate
+	if err := json.Unmarshal(data, &rates); err != nil {
+		return nil, fmt.Errorf("parsing exchange rates file %s: %w", path, err)
+	}
+
+	for i := range rates {
+		if err := rates[i].Validate(); err != nil {
+			return nil, fmt.Errorf("exchange rates file %s, entry %d: %w", path, i+1, err)
+		}
+	}
+	return rates, nil
+}
@@ -0,0 +1,203 @@
+package fee
+
+import (
+	"bankingApp/internal/currency"
+	"bankingApp/internal/model"
+	"errors"
+	"fmt"
+
+	"github.com/govalues/decimal"
+)
+
+var ErrInvalidRule = errors.New("fee rule is invalid")
+
+var hundred = decimal.MustNew(100, 0)
+
+// Breakdown is how the fee charged on a transfer is made up. Adjustment is what the minimum or maximum fee
+// of the rule added to or took off the flat and percentage fees, and Total is the fee that is charged.
+type Breakdown struct {
+	RuleID        uint
+	Flat          decimal.Decimal
+	Percentage    decimal.Decimal
+	PercentageFee decimal.Decimal
+	Adjustment    decimal.Decimal
+	Total         decimal.Decimal
+}
+
+// Select returns the rule charging a transfer of the amount: of the rules matching its transaction type, channel
+// and currency whose band contains the amount, the one that sets the most of them, where the transaction type
+// outweighs the channel and the channel outweighs the currency. It reports false when no rule applies.
+func Select(
+	rules []model.FeeRule,
+	transactionType model.TransactionType,
+	channel model.Channel,
+	code string,
+	amount decimal.Decimal) (model.FeeRule, bool) {
+	var selected model.FeeRule
+	best := -1
+	for _, rule := range rules {
+		score, ok := specificity(rule, transactionType, channel, code)
+		if !ok || score <= best || !inBand(rule, amount) {
+			continue
+		}
+		selected, best = rule, score
+	}
+	return selected, best >= 0
+}
+
+// specificity scores how specific the rule is for the transfer, reporting false when the rule does not match it
+func specificity(rule model.FeeRule, transactionType model.TransactionType, channel model.Channel, code string) (int, bool) {
+	score := 0
+	for _, criterion := range []struct {
+		set, transfer string
+		weight        int
+	}{
+		{set: string(rule.TransactionType), transfer: string(transactionType), weight: 4},
+		{set: string(rule.Channel), transfer: string(channel), weight: 2},
+		{set: rule.Currency, transfer: code, weight: 1},
+	} {
+		if criterion.set == "" {
+			continue
+		}
+		if criterion.set != criterion.transfer {
+			return 0, false
+		}
+		score += criterion.weight
+	}
+	return score, true
+}
+
+// inBand reports whether the amount is above the lower bound of the band of the rule and within its upper bound
+func inBand(rule model.FeeRule, amount decimal.Decimal) bool {
+	if amount.Cmp(rule.MinAmount.Decimal) <= 0 {
+		return false
+	}
+	return !rule.MaxAmount.Decimal.IsPos() || amount.Cmp(rule.MaxAmount.Decimal) <= 0
+}
+
+// Compute works out the fee the rule charges on the amount: the flat fee plus the percentage of the amount,
+// raised to the minimum fee and lowered to the maximum fee when those are set, in the given minor units
+func Compute(rule model.FeeRule, amount decimal.Decimal, minorUnits int) (Breakdown, error) {
+	percentageFee, err := amount.Mul(rule.Percentage.Decimal)
+	if err != nil {
+		return Breakdown{}, err
+	}
+	percentageFee, err = percentageFee.Quo(hundred)
+	if err != nil {
+		return Breakdown{}, err
+	}
+	percentageFee, err = percentageFee.Rescale(minorUnits)
+	if err != nil {
+		return Breakdown{}, err
+	}
+
+	flat, err := rule.FlatFee.Decimal.Rescale(minorUnits)
+	if err != nil {
+		return Breakdown{}, err
+	}
+	uncapped, err := flat.Add(percentageFee)
+	if err != nil {
+		return Breakdown{}, err
+	}
+
+	total := uncapped
+	if minFee := rule.MinFee.Decimal; minFee.IsPos() && total.Cmp(minFee) < 0 {
+		total = minFee
+	}
+	if maxFee := rule.MaxFee.Decimal; maxFee.IsPos() && total.Cmp(maxFee) > 0 {
+		total = maxFee
+	}
+	total, err = total.Rescale(minorUnits)
+	if err != nil {
+		return Breakdown{}, err
+	}
+	adjustment, err := total.Sub(uncapped)
+	if err != nil {
+		return Breakdown{}, err
+	}
+
+	return Breakdown{
+		RuleID:        rule.FeeRuleID,
+		Flat:          flat,
+		Percentage:    rule.Percentage.Decimal,
+		PercentageFee: percentageFee,
+		Adjustment:    adjustment,
+		Total:         total,
+	}, nil
+}
+
+// Validate checks the rules of a fee schedule: the criteria must be known, amounts and fees must not be negative,
+// the percentage must not exceed 100, bands and fee caps must not be inverted, and rules for the same transaction
+// type, channel and currency must not have overlapping bands. Currency codes are normalised to upper case.
+func Validate(rules []model.FeeRule) error {
+	for i := range rules {
+		if err := validateRule(&rules[i]); err != nil {
+			return err
+		}
+	}
+
+	for i, rule := range rules {
+		for _, other := range rules[i+1:] {
+			if sameCriteria(rule, other) && overlap(rule, other) {
+				return fmt.Errorf("%w: bands %s-%s and %s-%s overlap", ErrInvalidRule,
+					rule.MinAmount.Decimal, rule.MaxAmount.Decimal, other.MinAmount.Decimal, other.MaxAmount.Decimal)
+			}
+		}
+	}
+	return nil
+}
+
+// validateRule checks the criteria and the amounts of a single rule
+func validateRule(rule *model.FeeRule) error {
+	switch rule.TransactionType {
+	case "", model.DebitTransaction, model.CreditTransaction:
+	default:
+		return fmt.Errorf("%w: unknown transaction type %q", ErrInvalidRule, rule.TransactionType)
+	}
+
+	if rule.Channel != "" && !rule.Channel.IsValid() {
+		return fmt.Errorf("%w: unknown channel %q", ErrInvalidRule, rule.Channel)
+	}
+
+	if rule.Currency != "" {
+		c, err := currency.Lookup(rule.Currency)
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrInvalidRule, err)
+		}
+		rule.Currency = c.Code
+	}
+
+	for _, amount := range []model.BigDecimal{
+		rule.MinAmount, rule.MaxAmount, rule.FlatFee, rule.Percentage, rule.MinFee, rule.MaxFee,
+	} {
+		if amount.Decimal.IsNeg() {
+			return fmt.Errorf("%w: negative amount %s", ErrInvalidRule, amount.Decimal)
+		}
+	}
+
+	switch {
+	case rule.Percentage.Decimal.Cmp(hundred) > 0:
+		return fmt.Errorf("%w: percentage %s is over 100", ErrInvalidRule, rule.Percentage.Decimal)
+	case rule.MaxAmount.Decimal.IsPos() && rule.MaxAmount.Decimal.Cmp(rule.MinAmount.Decimal) <= 0:
+		return fmt.Errorf("%w: band %s-%s is empty", ErrInvalidRule, rule.MinAmount.Decimal, rule.MaxAmount.Decimal)
+	case rule.MaxFee.Decimal.IsPos() && rule.MaxFee.Decimal.Cmp(rule.MinFee.Decimal) < 0:
+		return fmt.Errorf("%w: maximum fee %s is below minimum fee %s", ErrInvalidRule,
+			rule.MaxFee.Decimal, rule.MinFee.Decimal)
+	}
+	return nil
+}
+
+// sameCriteria reports whether both rules apply to the same transaction type, channel and currency
+func sameCriteria(a, b model.FeeRule) bool {
+	return a.TransactionType == b.TransactionType && a.Channel == b.Channel && a.Currency == b.Currency
+}
+
+// overlap reports whether the bands of both rules share an amount, a band with no upper bound running on forever
+func overlap(a, b model.FeeRule) bool {
+	return below(a.MinAmount, b.MaxAmount) && below(b.MinAmount, a.MaxAmount)
+}
+
+// below reports whether the lower bound of one band is below the upper bound of another
+func below(lower, upper model.BigDecimal) bool {
+	return !upper.Decimal.IsPos() || lower.Decimal.Cmp(upper.Decimal) < 0
+}
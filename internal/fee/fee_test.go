@@ -0,0 +1,163 @@
+package fee
+
+import (
+	"bankingApp/internal/model"
+	"testing"
+
+	"github.com/govalues/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func amountOf(value string) model.BigDecimal {
+	return model.BigDecimal{Decimal: decimal.MustParse(value)}
+}
+
+func Test_Select(t *testing.T) {
+	rules := []model.FeeRule{
+		{FeeRuleID: 1},
+		{FeeRuleID: 2, TransactionType: model.DebitTransaction, MaxAmount: amountOf("5000")},
+		{FeeRuleID: 3, TransactionType: model.DebitTransaction, MinAmount: amountOf("5000")},
+		{FeeRuleID: 4, TransactionType: model.DebitTransaction, Channel: model.BulkChannel},
+		{FeeRuleID: 5, Channel: model.BulkChannel, Currency: "USD"},
+		{FeeRuleID: 6, Currency: "USD"},
+	}
+
+	testCases := []struct {
+		name            string
+		transactionType model.TransactionType
+		channel         model.Channel
+		code            string
+		amount          string
+		ruleID          uint
+	}{
+		{name: "lower band", transactionType: model.DebitTransaction, channel: model.APIChannel, amount: "5000", ruleID: 2},
+		{name: "upper band", transactionType: model.DebitTransaction, channel: model.APIChannel, amount: "5000.01", ruleID: 3},
+		{name: "channel over band", transactionType: model.DebitTransaction, channel: model.BulkChannel, amount: "10", ruleID: 4},
+		{name: "type over channel and currency", transactionType: model.DebitTransaction, channel: model.BulkChannel, code: "USD", amount: "10", ruleID: 4},
+		{name: "channel over currency", transactionType: model.CreditTransaction, channel: model.BulkChannel, code: "USD", amount: "10", ruleID: 5},
+		{name: "currency", transactionType: model.CreditTransaction, channel: model.APIChannel, code: "USD", amount: "10", ruleID: 6},
+		{name: "catch-all", transactionType: model.CreditTransaction, channel: model.APIChannel, code: "NGN", amount: "10", ruleID: 1},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rule, ok := Select(rules, tc.transactionType, tc.channel, tc.code, decimal.MustParse(tc.amount))
+			assert.True(t, ok)
+			assert.Equal(t, tc.ruleID, rule.FeeRuleID)
+		})
+	}
+
+	_, ok := Select(rules[1:2], model.DebitTransaction, model.APIChannel, "", decimal.MustParse("5000.01"))
+	assert.False(t, ok)
+}
+
+func Test_Compute(t *testing.T) {
+	testCases := []struct {
+		name          string
+		rule          model.FeeRule
+		amount        string
+		minorUnits    int
+		percentageFee string
+		adjustment    string
+		total         string
+	}{
+		{
+			name:   "flat",
+			rule:   model.FeeRule{FlatFee: amountOf("10.75")},
+			amount: "1000", minorUnits: 2, percentageFee: "0", adjustment: "0", total: "10.75",
+		},
+		{
+			name:   "flat and percentage",
+			rule:   model.FeeRule{FlatFee: amountOf("10"), Percentage: amountOf("1.5")},
+			amount: "1000", minorUnits: 2, percentageFee: "15", adjustment: "0", total: "25",
+		},
+		{
+			name:   "rounded to the currency",
+			rule:   model.FeeRule{Percentage: amountOf("0.75")},
+			amount: "1234.56", minorUnits: 2, percentageFee: "9.26", adjustment: "0", total: "9.26",
+		},
+		{
+			name:   "no minor units",
+			rule:   model.FeeRule{Percentage: amountOf("0.75")},
+			amount: "1234", minorUnits: 0, percentageFee: "9", adjustment: "0", total: "9",
+		},
+		{
+			name:   "raised to minimum fee",
+			rule:   model.FeeRule{Percentage: amountOf("1"), MinFee: amountOf("50")},
+			amount: "1000", minorUnits: 2, percentageFee: "10", adjustment: "40", total: "50",
+		},
+		{
+			name:   "lowered to maximum fee",
+			rule:   model.FeeRule{FlatFee: amountOf("25"), Percentage: amountOf("1"), MaxFee: amountOf("2000")},
+			amount: "1000000", minorUnits: 2, percentageFee: "10000", adjustment: "-8025", total: "2000",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			breakdown, err := Compute(tc.rule, decimal.MustParse(tc.amount), tc.minorUnits)
+			require.NoError(t, err)
+			assert.Equal(t, 0, breakdown.PercentageFee.Cmp(decimal.MustParse(tc.percentageFee)))
+			assert.Equal(t, 0, breakdown.Adjustment.Cmp(decimal.MustParse(tc.adjustment)))
+			assert.Equal(t, 0, breakdown.Total.Cmp(decimal.MustParse(tc.total)))
+			assert.Equal(t, tc.minorUnits, breakdown.Total.Scale())
+		})
+	}
+}
+
+func Test_Validate(t *testing.T) {
+	testCases := []struct {
+		name  string
+		rules []model.FeeRule
+		valid bool
+	}{
+		{
+			name: "adjacent bands",
+			rules: []model.FeeRule{
+				{TransactionType: model.DebitTransaction, MaxAmount: amountOf("5000"), FlatFee: amountOf("10")},
+				{TransactionType: model.DebitTransaction, MinAmount: amountOf("5000"), Percentage: amountOf("0.5")},
+			},
+			valid: true,
+		},
+		{
+			name: "same band for different channels",
+			rules: []model.FeeRule{
+				{Channel: model.APIChannel, FlatFee: amountOf("10")},
+				{Channel: model.BulkChannel, FlatFee: amountOf("5")},
+			},
+			valid: true,
+		},
+		{
+			name: "overlapping bands",
+			rules: []model.FeeRule{
+				{TransactionType: model.DebitTransaction, MaxAmount: amountOf("5000")},
+				{TransactionType: model.DebitTransaction, MinAmount: amountOf("4000")},
+			},
+		},
+		{name: "unknown transaction type", rules: []model.FeeRule{{TransactionType: "flier"}}},
+		{name: "unknown channel", rules: []model.FeeRule{{Channel: "branch"}}},
+		{name: "unknown currency", rules: []model.FeeRule{{Currency: "XYZ"}}},
+		{name: "negative fee", rules: []model.FeeRule{{FlatFee: amountOf("-1")}}},
+		{name: "percentage over 100", rules: []model.FeeRule{{Percentage: amountOf("100.5")}}},
+		{name: "empty band", rules: []model.FeeRule{{MinAmount: amountOf("100"), MaxAmount: amountOf("100")}}},
+		{name: "maximum below minimum fee", rules: []model.FeeRule{{MinFee: amountOf("50"), MaxFee: amountOf("10")}}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := Validate(tc.rules)
+			if tc.valid {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorIs(t, err, ErrInvalidRule)
+		})
+	}
+}
+
+func Test_ValidateNormalisesCurrency(t *testing.T) {
+	rules := []model.FeeRule{{Currency: "ngn"}}
+	require.NoError(t, Validate(rules))
+	assert.Equal(t, "NGN", rules[0].Currency)
+}
@@ -0,0 +1,118 @@
+package interest
+
+import (
+	"bankingApp/internal/model"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/govalues/decimal"
+)
+
+var hundred = decimal.MustNew(100, 0)
+
+// ErrInvalidProduct is returned for account products that cannot be used to calculate interest
+var ErrInvalidProduct = errors.New("account product is invalid")
+
+// Capitalisation splits the interest accrued over a period into what is credited to the account and what is left
+type Capitalisation struct {
+	// Gross is the accrued interest truncated to the minor unit of the currency
+	Gross decimal.Decimal
+	// Tax is the withholding tax deducted from Gross, rounded to the minor unit of the currency
+	Tax decimal.Decimal
+	// Net is what the account is left with, Gross less Tax
+	Net decimal.Decimal
+	// Remainder is the accrued interest below the minor unit, carried into the next capitalisation
+	Remainder decimal.Decimal
+}
+
+// Days counts the days from one date to the next under the day-count convention, and returns the number
+// of days in the year of the convention. Only the dates of from and to are used.
+func Days(convention model.DayCountConvention, from, to time.Time) (int, int, error) {
+	switch convention {
+	case model.Actual365:
+		start := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, time.UTC)
+		end := time.Date(to.Year(), to.Month(), to.Day(), 0, 0, 0, 0, time.UTC)
+		return int(end.Sub(start).Hours() / 24), 365, nil
+	case model.Thirty360:
+		// 30E/360: the 31st of a month counts as the 30th
+		d1, d2 := min(from.Day(), 30), min(to.Day(), 30)
+		days := 360*(to.Year()-from.Year()) + 30*(int(to.Month())-int(from.Month())) + d2 - d1
+		return days, 360, nil
+	default:
+		return 0, 0, fmt.Errorf("%w: unknown day-count convention %q", ErrInvalidProduct, convention)
+	}
+}
+
+// DailyAccrual returns the interest the balance accrues for the given day at the annual percentage rate,
+// at the full precision of decimal.Decimal. Balances that are not positive accrue nothing.
+func DailyAccrual(
+	balance, annualRate decimal.Decimal,
+	convention model.DayCountConvention,
+	day time.Time) (decimal.Decimal, error) {
+	days, basis, err := Days(convention, day, day.AddDate(0, 0, 1))
+	if err != nil {
+		return decimal.Decimal{}, err
+	}
+	if !balance.IsPos() || !annualRate.IsPos() || days == 0 {
+		return decimal.Zero, nil
+	}
+
+	yearly, err := balance.Mul(annualRate)
+	if err != nil {
+		return decimal.Decimal{}, err
+	}
+	accrued, err := yearly.Mul(decimal.MustNew(int64(days), 0))
+	if err != nil {
+		return decimal.Decimal{}, err
+	}
+	divisor, err := hundred.Mul(decimal.MustNew(int64(basis), 0))
+	if err != nil {
+		return decimal.Decimal{}, err
+	}
+	return accrued.Quo(divisor)
+}
+
+// Capitalise splits the accrued interest into the gross interest in minor units of the currency, the withholding
+// tax deducted from it at the percentage tax rate, and the remainder below the minor unit
+func Capitalise(accrued, taxRate decimal.Decimal, minorUnits int) (Capitalisation, error) {
+	gross := accrued.Trunc(minorUnits)
+	remainder, err := accrued.Sub(gross)
+	if err != nil {
+		return Capitalisation{}, err
+	}
+
+	tax := decimal.Zero
+	if taxRate.IsPos() && gross.IsPos() {
+		withheld, err := gross.Mul(taxRate)
+		if err != nil {
+			return Capitalisation{}, err
+		}
+		if withheld, err = withheld.Quo(hundred); err != nil {
+			return Capitalisation{}, err
+		}
+		tax = withheld.Round(minorUnits)
+	}
+
+	net, err := gross.Sub(tax)
+	if err != nil {
+		return Capitalisation{}, err
+	}
+	return Capitalisation{Gross: gross, Tax: tax, Net: net, Remainder: remainder}, nil
+}
+
+// Validate checks that the product has a known day-count convention and that its rates are between 0 and 100
+func Validate(product *model.AccountProduct) error {
+	if product.Code == "" {
+		return fmt.Errorf("%w: code is required", ErrInvalidProduct)
+	}
+	if !product.DayCount.IsValid() {
+		return fmt.Errorf("%w: unknown day-count convention %q", ErrInvalidProduct, product.DayCount)
+	}
+	for _, rate := range []decimal.Decimal{product.AnnualRate.Decimal, product.WithholdingTaxRate.Decimal} {
+		if rate.IsNeg() || rate.Cmp(hundred) > 0 {
+			return fmt.Errorf("%w: rate %s is not between 0 and 100", ErrInvalidProduct, rate)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,130 @@
+package interest
+
+import (
+	"bankingApp/internal/model"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/govalues/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+func Test_Days(t *testing.T) {
+	testCases := []struct {
+		name          string
+		convention    model.DayCountConvention
+		from, to      time.Time
+		expectedDays  int
+		expectedBasis int
+	}{
+		{name: "actual day", convention: model.Actual365, from: date(2024, 1, 30), to: date(2024, 1, 31), expectedDays: 1, expectedBasis: 365},
+		{name: "actual month", convention: model.Actual365, from: date(2024, 2, 1), to: date(2024, 3, 1), expectedDays: 29, expectedBasis: 365},
+		{name: "30/360 day", convention: model.Thirty360, from: date(2024, 1, 29), to: date(2024, 1, 30), expectedDays: 1, expectedBasis: 360},
+		{name: "30/360 31st", convention: model.Thirty360, from: date(2024, 1, 30), to: date(2024, 1, 31), expectedDays: 0, expectedBasis: 360},
+		{name: "30/360 into the next month", convention: model.Thirty360, from: date(2024, 1, 31), to: date(2024, 2, 1), expectedDays: 1, expectedBasis: 360},
+		{name: "30/360 end of february", convention: model.Thirty360, from: date(2023, 2, 28), to: date(2023, 3, 1), expectedDays: 3, expectedBasis: 360},
+		{name: "30/360 month", convention: model.Thirty360, from: date(2024, 2, 1), to: date(2024, 3, 1), expectedDays: 30, expectedBasis: 360},
+		{name: "30/360 year", convention: model.Thirty360, from: date(2023, 12, 31), to: date(2024, 12, 31), expectedDays: 360, expectedBasis: 360},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			days, basis, err := Days(tt.convention, tt.from, tt.to)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedDays, days)
+			assert.Equal(t, tt.expectedBasis, basis)
+		})
+	}
+
+	_, _, err := Days("ACT/ACT", date(2024, 1, 1), date(2024, 1, 2))
+	assert.True(t, errors.Is(err, ErrInvalidProduct))
+}
+
+func Test_DailyAccrual(t *testing.T) {
+	testCases := []struct {
+		name       string
+		balance    string
+		rate       string
+		convention model.DayCountConvention
+		day        time.Time
+		expected   string
+	}{
+		{name: "actual/365", balance: "100000", rate: "3.65", convention: model.Actual365, day: date(2024, 1, 10), expected: "10"},
+		{name: "full precision", balance: "100000", rate: "3.5", convention: model.Actual365, day: date(2024, 1, 10), expected: "9.589041095890410959"},
+		{name: "30/360", balance: "36000", rate: "5", convention: model.Thirty360, day: date(2024, 1, 10), expected: "5"},
+		{name: "30/360 31st", balance: "36000", rate: "5", convention: model.Thirty360, day: date(2024, 1, 31), expected: "5"},
+		{name: "30/360 30th", balance: "36000", rate: "5", convention: model.Thirty360, day: date(2024, 1, 30), expected: "0"},
+		{name: "30/360 end of february", balance: "36000", rate: "5", convention: model.Thirty360, day: date(2023, 2, 28), expected: "15"},
+		{name: "overdrawn balance", balance: "-100", rate: "5", convention: model.Actual365, day: date(2024, 1, 10), expected: "0"},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			accrued, err := DailyAccrual(decimal.MustParse(tt.balance), decimal.MustParse(tt.rate), tt.convention, tt.day)
+			require.NoError(t, err)
+			assert.Equal(t, 0, accrued.Cmp(decimal.MustParse(tt.expected)), accrued.String())
+		})
+	}
+}
+
+func Test_Capitalise(t *testing.T) {
+	testCases := []struct {
+		name              string
+		accrued           string
+		taxRate           string
+		minorUnits        int
+		expectedGross     string
+		expectedTax       string
+		expectedNet       string
+		expectedRemainder string
+	}{
+		{name: "without tax", accrued: "297.2602739726027397", taxRate: "0", minorUnits: 2,
+			expectedGross: "297.26", expectedTax: "0", expectedNet: "297.26", expectedRemainder: "0.0002739726027397"},
+		{name: "with tax", accrued: "297.2602739726027397", taxRate: "10", minorUnits: 2,
+			expectedGross: "297.26", expectedTax: "29.73", expectedNet: "267.53", expectedRemainder: "0.0002739726027397"},
+		{name: "no minor units", accrued: "1250.75", taxRate: "7.5", minorUnits: 0,
+			expectedGross: "1250", expectedTax: "94", expectedNet: "1156", expectedRemainder: "0.75"},
+		{name: "less than a minor unit", accrued: "0.004", taxRate: "10", minorUnits: 2,
+			expectedGross: "0", expectedTax: "0", expectedNet: "0", expectedRemainder: "0.004"},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			capitalisation, err := Capitalise(decimal.MustParse(tt.accrued), decimal.MustParse(tt.taxRate), tt.minorUnits)
+			require.NoError(t, err)
+			assert.Equal(t, 0, capitalisation.Gross.Cmp(decimal.MustParse(tt.expectedGross)))
+			assert.Equal(t, 0, capitalisation.Tax.Cmp(decimal.MustParse(tt.expectedTax)))
+			assert.Equal(t, 0, capitalisation.Net.Cmp(decimal.MustParse(tt.expectedNet)))
+			assert.Equal(t, 0, capitalisation.Remainder.Cmp(decimal.MustParse(tt.expectedRemainder)))
+		})
+	}
+}
+
+func Test_Validate(t *testing.T) {
+	valid := model.AccountProduct{Code: "SAVER", DayCount: model.Actual365, AnnualRate: amountOf("3.5")}
+	assert.NoError(t, Validate(&valid))
+
+	testCases := []struct {
+		name   string
+		modify func(p *model.AccountProduct)
+	}{
+		{name: "missing code", modify: func(p *model.AccountProduct) { p.Code = "" }},
+		{name: "unknown day count", modify: func(p *model.AccountProduct) { p.DayCount = "ACT/ACT" }},
+		{name: "negative rate", modify: func(p *model.AccountProduct) { p.AnnualRate = amountOf("-1") }},
+		{name: "tax over 100", modify: func(p *model.AccountProduct) { p.WithholdingTaxRate = amountOf("100.5") }},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			product := valid
+			tt.modify(&product)
+			assert.True(t, errors.Is(Validate(&product), ErrInvalidProduct))
+		})
+	}
+}
+
+func amountOf(value string) model.BigDecimal {
+	return model.BigDecimal{Decimal: decimal.MustParse(value)}
+}
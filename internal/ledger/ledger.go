@@ -0,0 +1,389 @@
+package ledger
+
+import (
+	"bankingApp/internal/model"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/govalues/decimal"
+)
+
+// General ledger account codes. Customer accounts form the sub-ledger of CustomerDepositsGL.
+const (
+	CustomerDepositsGL   = "CUSTOMER_DEPOSITS"
+	SettlementSuspenseGL = "SETTLEMENT_SUSPENSE"
+	FeeIncomeGL          = "FEE_INCOME"
+	OpeningBalanceGL     = "OPENING_BALANCE_EQUITY"
+	FXPositionGL         = "FX_POSITION"
+	OverdraftInterestGL  = "OVERDRAFT_INTEREST_INCOME"
+	InterestExpenseGL    = "INTEREST_EXPENSE"
+	WithholdingTaxGL     = "WITHHOLDING_TAX_PAYABLE"
+)
+
+// ChartOfAccounts lists the general ledger accounts every installation must have
+var ChartOfAccounts = []model.LedgerAccount{
+	{Code: CustomerDepositsGL, Name: "Customer deposits", Type: model.LiabilityAccount},
+	{Code: SettlementSuspenseGL, Name: "Third-party settlement suspense", Type: model.AssetAccount},
+	{Code: FeeIncomeGL, Name: "Fee income", Type: model.IncomeAccount},
+	{Code: OpeningBalanceGL, Name: "Opening balance equity", Type: model.EquityAccount},
+	{Code: FXPositionGL, Name: "Foreign exchange position", Type: model.AssetAccount},
+	{Code: OverdraftInterestGL, Name: "Overdraft interest income", Type: model.IncomeAccount},
+	{Code: InterestExpenseGL, Name: "Interest expense", Type: model.ExpenseAccount},
+	{Code: WithholdingTaxGL, Name: "Withholding tax payable", Type: model.LiabilityAccount},
+}
+
+var (
+	ErrEmptyEntry      = errors.New("journal entry must have at least two postings")
+	ErrUnbalancedEntry = errors.New("journal entry debits and credits do not balance")
+	ErrInvalidPosting  = errors.New("journal entry posting is invalid")
+	ErrBalanceMismatch = errors.New("account balance does not match its postings")
+)
+
+// reconcileBatchSize is how many accounts are read at a time when balances are reconciled
+const reconcileBatchSize = 100
+
+type IJournalRepository interface {
+	AccountPostingTotals(accountID uint) (model.BigDecimal, model.BigDecimal, error)
+	FindAccounts(afterID uint, limit int) ([]model.Account, error)
+	FindAccount(accountID uint) (*model.Account, error)
+}
+
+// Ledger checks the balances of customer accounts against their postings
+type Ledger struct {
+	Repository IJournalRepository
+	// reconciledOn is the day balances were last reconciled on
+	reconciledOn string
+}
+
+// NewLedger creates a new instance of Ledger
+func NewLedger(repository IJournalRepository) *Ledger {
+	return &Ledger{Repository: repository}
+}
+
+// Reconcile checks the balance of every customer account against its postings once a day, logging every account
+// whose balance does not match. An account that does not match is read again before it is reported, so a posting
+// made between reading the account and totalling its postings is not reported as a mismatch.
+func (l *Ledger) Reconcile(ctx context.Context, now time.Time) error {
+	day := now.Format("2006-01-02")
+	if l.reconciledOn == day {
+		return nil
+	}
+
+	mismatched := 0
+	for afterID := uint(0); ; {
+		accounts, err := l.Repository.FindAccounts(afterID, reconcileBatchSize)
+		if err != nil {
+			return err
+		}
+		if len(accounts) == 0 {
+			break
+		}
+
+		for i := range accounts {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			afterID = accounts[i].AccountID
+			matched, err := l.reconcileAccount(&accounts[i])
+			if err != nil {
+				return err
+			}
+			if !matched {
+				mismatched++
+			}
+		}
+	}
+
+	l.reconciledOn = day
+	slog.Info("account balances reconciled against postings", "day", day, "mismatched", mismatched)
+	return nil
+}
+
+// reconcileAccount reports whether the balance of the account matches its postings, reading the account again
+// when it does not and logging it when it still does not
+func (l *Ledger) reconcileAccount(account *model.Account) (bool, error) {
+	_, err := l.CheckAccountBalance(account)
+	if !errors.Is(err, ErrBalanceMismatch) {
+		return err == nil, err
+	}
+
+	account, err = l.Repository.FindAccount(account.AccountID)
+	if err != nil {
+		return false, err
+	}
+	derived, err := l.CheckAccountBalance(account)
+	if !errors.Is(err, ErrBalanceMismatch) {
+		return err == nil, err
+	}
+	slog.Error("account balance does not match its postings", "account_number", account.AccountNumber,
+		"balance", account.GetBalance().Decimal, "postings", derived.Decimal)
+	return false, nil
+}
+
+// CheckAccountBalance derives the balance of the account from its postings and
+// returns ErrBalanceMismatch when it differs from the stored balance
+func (l *Ledger) CheckAccountBalance(account *model.Account) (model.BigDecimal, error) {
+	debits, credits, err := l.Repository.AccountPostingTotals(account.AccountID)
+	if err != nil {
+		return model.BigDecimal{}, err
+	}
+
+	derived, err := credits.Decimal.Sub(debits.Decimal)
+	if err != nil {
+		return model.BigDecimal{}, err
+	}
+
+	if derived.Cmp(account.GetBalance().Decimal) != 0 {
+		return model.BigDecimal{Decimal: derived}, fmt.Errorf("%w: account %s has balance %s, postings give %s",
+			ErrBalanceMismatch, account.AccountNumber, account.GetBalance().Decimal, derived)
+	}
+	return model.BigDecimal{Decimal: derived}, nil
+}
+
+// Entry builds a journal entry one posting at a time
+type Entry struct {
+	entry    *model.JournalEntry
+	currency string
+}
+
+// NewEntry starts a journal entry for the given reference
+func NewEntry(reference, description string) *Entry {
+	return &Entry{entry: &model.JournalEntry{Reference: reference, Description: description}}
+}
+
+// In sets the currency of the postings added after it
+func (e *Entry) In(currency string) *Entry {
+	e.currency = currency
+	return e
+}
+
+// Exchange exchanges an amount in one currency for an amount in another through the FX position:
+// the position is credited in the currency given away and debited in the currency received.
+// It adds nothing when both currencies are the same.
+func (e *Entry) Exchange(from model.BigDecimal, fromCurrency string, to model.BigDecimal, toCurrency string) *Entry {
+	if fromCurrency == toCurrency {
+		return e
+	}
+	return e.In(fromCurrency).CreditGL(FXPositionGL, from).In(toCurrency).DebitGL(FXPositionGL, to)
+}
+
+// DebitAccount debits a customer account, reducing its balance
+func (e *Entry) DebitAccount(accountID uint, amount model.BigDecimal) *Entry {
+	return e.add(CustomerDepositsGL, accountID, model.DebitEntry, amount)
+}
+
+// CreditAccount credits a customer account, increasing its balance
+func (e *Entry) CreditAccount(accountID uint, amount model.BigDecimal) *Entry {
+	return e.add(CustomerDepositsGL, accountID, model.CreditEntry, amount)
+}
+
+// DebitGL debits a general ledger account
+func (e *Entry) DebitGL(code string, amount model.BigDecimal) *Entry {
+	return e.add(code, 0, model.DebitEntry, amount)
+}
+
+// CreditGL credits a general ledger account
+func (e *Entry) CreditGL(code string, amount model.BigDecimal) *Entry {
+	return e.add(code, 0, model.CreditEntry, amount)
+}
+
+// ChargeFee debits a fee from a customer account to fee income. It adds nothing when there is no fee.
+func (e *Entry) ChargeFee(accountID uint, fee model.BigDecimal) *Entry {
+	if !fee.Decimal.IsPos() {
+		return e
+	}
+	return e.DebitAccount(accountID, fee).CreditGL(FeeIncomeGL, fee)
+}
+
+// Build validates and returns the journal entry
+func (e *Entry) Build() (*model.JournalEntry, error) {
+	if err := Validate(e.entry); err != nil {
+		return nil, err
+	}
+	return e.entry, nil
+}
+
+func (e *Entry) add(code string, accountID uint, direction model.EntryDirection, amount model.BigDecimal) *Entry {
+	e.entry.Postings = append(e.entry.Postings, model.Posting{
+		LedgerCode: code,
+		AccountID:  accountID,
+		Direction:  direction,
+		Amount:     amount,
+		Currency:   e.currency,
+	})
+	return e
+}
+
+// TransactionEntry builds the journal entry of a transaction settled through the third-party provider.
+// When the transfer was requested in another currency, settlement suspense carries the transfer amount
+// and the account amount is exchanged for it through the FX position. The fee of the transaction is charged
+// to the account in the same entry, so reversing a failed debit refunds its fee as well.
+func TransactionEntry(transaction *model.Transaction) (*model.JournalEntry, error) {
+	settled, settledCurrency := transaction.SettlementAmount()
+
+	switch transaction.Type {
+	case model.DebitTransaction:
+		return NewEntry(transaction.Reference, "outbound transfer").
+			In(transaction.Currency).
+			DebitAccount(transaction.AccountID, transaction.Amount).
+			Exchange(transaction.Amount, transaction.Currency, settled, settledCurrency).
+			CreditGL(SettlementSuspenseGL, settled).
+			In(transaction.Currency).
+			ChargeFee(transaction.AccountID, transaction.Fee).
+			Build()
+	case model.CreditTransaction:
+		return NewEntry(transaction.Reference, "inbound transfer").
+			In(settledCurrency).
+			DebitGL(SettlementSuspenseGL, settled).
+			Exchange(settled, settledCurrency, transaction.Amount, transaction.Currency).
+			CreditAccount(transaction.AccountID, transaction.Amount).
+			ChargeFee(transaction.AccountID, transaction.Fee).
+			Build()
+	default:
+		return nil, fmt.Errorf("%w: unknown transaction type %q", ErrInvalidPosting, transaction.Type)
+	}
+}
+
+// InternalTransferEntry builds the journal entry moving funds between two customer accounts from the debit
+// and credit legs of the transfer. Legs in different currencies are exchanged through the FX position.
+func InternalTransferEntry(reference string, debitLeg, creditLeg *model.Transaction) (*model.JournalEntry, error) {
+	return NewEntry(reference, "internal transfer").
+		In(debitLeg.Currency).
+		DebitAccount(debitLeg.AccountID, debitLeg.Amount).
+		Exchange(debitLeg.Amount, debitLeg.Currency, creditLeg.Amount, creditLeg.Currency).
+		CreditAccount(creditLeg.AccountID, creditLeg.Amount).
+		Build()
+}
+
+// OverdraftInterestEntry builds the journal entry charging a day of overdraft interest to a customer account
+func OverdraftInterestEntry(
+	reference string,
+	accountID uint,
+	currency string,
+	interest model.BigDecimal) (*model.JournalEntry, error) {
+	return NewEntry(reference, "overdraft interest").
+		In(currency).
+		DebitAccount(accountID, interest).
+		CreditGL(OverdraftInterestGL, interest).
+		Build()
+}
+
+// InterestCapitalisationEntry builds the journal entry of a capitalisation credit, crediting the customer account
+// with the gross interest it earned and debiting the fee of the credit, the withholding tax on the interest,
+// to the tax payable. It adds no tax postings when nothing is withheld.
+func InterestCapitalisationEntry(transaction *model.Transaction) (*model.JournalEntry, error) {
+	entry := NewEntry(transaction.Reference, "interest capitalisation").
+		In(transaction.Currency).
+		DebitGL(InterestExpenseGL, transaction.Amount).
+		CreditAccount(transaction.AccountID, transaction.Amount)
+	if transaction.Fee.Decimal.IsPos() {
+		entry.DebitAccount(transaction.AccountID, transaction.Fee).CreditGL(WithholdingTaxGL, transaction.Fee)
+	}
+	return entry.Build()
+}
+
+// ReversalEntry builds the entry that undoes the original entry by swapping the direction of every posting
+func ReversalEntry(reference string, original *model.JournalEntry) (*model.JournalEntry, error) {
+	if original == nil {
+		return nil, ErrEmptyEntry
+	}
+
+	entry := NewEntry(reference, "reversal of "+original.Reference)
+	for _, posting := range original.Postings {
+		direction := model.DebitEntry
+		if posting.Direction == model.DebitEntry {
+			direction = model.CreditEntry
+		}
+		entry.In(posting.Currency).add(posting.LedgerCode, posting.AccountID, direction, posting.Amount)
+	}
+	return entry.Build()
+}
+
+// Validate checks that every posting is well formed and that total debits equal total credits in every currency
+func Validate(entry *model.JournalEntry) error {
+	if entry == nil || len(entry.Postings) < 2 {
+		return ErrEmptyEntry
+	}
+
+	debits, credits := map[string]decimal.Decimal{}, map[string]decimal.Decimal{}
+	for _, posting := range entry.Postings {
+		if posting.LedgerCode == "" || !posting.Amount.Decimal.IsPos() {
+			return ErrInvalidPosting
+		}
+		if (posting.AccountID != 0) != (posting.LedgerCode == CustomerDepositsGL) {
+			return ErrInvalidPosting
+		}
+
+		var err error
+		switch posting.Direction {
+		case model.DebitEntry:
+			debits[posting.Currency], err = debits[posting.Currency].Add(posting.Amount.Decimal)
+		case model.CreditEntry:
+			credits[posting.Currency], err = credits[posting.Currency].Add(posting.Amount.Decimal)
+		default:
+			return ErrInvalidPosting
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	for currency, total := range debits {
+		if total.Cmp(credits[currency]) != 0 {
+			return ErrUnbalancedEntry
+		}
+	}
+	for currency, total := range credits {
+		if total.Cmp(debits[currency]) != 0 {
+			return ErrUnbalancedEntry
+		}
+	}
+	return nil
+}
+
+// Apply applies a posting to the balance of the customer account it belongs to.
+// Customer accounts are liabilities of the bank, so a debit withdraws and a credit deposits.
+// A debit the available balance of the account cannot cover is rejected.
+func Apply(account *model.Account, posting model.Posting) error {
+	return apply(account, posting, true)
+}
+
+// ApplyCharge applies a posting like Apply, except that a debit is made even when it takes the account beyond
+// its overdraft limit. It is used for charges the bank levies, such as overdraft interest.
+func ApplyCharge(account *model.Account, posting model.Posting) error {
+	return apply(account, posting, false)
+}
+
+func apply(account *model.Account, posting model.Posting, checkFunds bool) error {
+	if posting.AccountID != account.AccountID {
+		return ErrInvalidPosting
+	}
+	if posting.Currency != "" && account.Currency != "" && posting.Currency != account.Currency {
+		return fmt.Errorf("%w: %s posting to %s account %s", ErrInvalidPosting,
+			posting.Currency, account.Currency, account.AccountNumber)
+	}
+
+	switch posting.Direction {
+	case model.DebitEntry:
+		if checkFunds && account.IsInsufficientBalance(posting.Amount) {
+			return model.ErrInsufficientFunds
+		}
+		return account.Withdraw(posting.Amount)
+	case model.CreditEntry:
+		return account.Deposit(posting.Amount)
+	default:
+		return ErrInvalidPosting
+	}
+}
+
+// Stamp sets the posting time on the entry and its postings
+func Stamp(entry *model.JournalEntry, now time.Time) {
+	entry.PostedAt = now
+	entry.CreatedAt = now
+	for i := range entry.Postings {
+		entry.Postings[i].CreatedAt = now
+	}
+}
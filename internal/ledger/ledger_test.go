@@ -0,0 +1,340 @@
+package ledger
+
+import (
+	"bankingApp/internal/model"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/govalues/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeJournalRepository struct {
+	debits   model.BigDecimal
+	credits  model.BigDecimal
+	accounts []model.Account
+	// reloaded is the account FindAccount returns, as it is when it is read again
+	reloaded *model.Account
+}
+
+func (f *fakeJournalRepository) AccountPostingTotals(uint) (model.BigDecimal, model.BigDecimal, error) {
+	return f.debits, f.credits, nil
+}
+
+func (f *fakeJournalRepository) FindAccounts(afterID uint, limit int) ([]model.Account, error) {
+	start := 0
+	for start < len(f.accounts) && f.accounts[start].AccountID <= afterID {
+		start++
+	}
+	return f.accounts[start:min(start+limit, len(f.accounts))], nil
+}
+
+func (f *fakeJournalRepository) FindAccount(uint) (*model.Account, error) {
+	return f.reloaded, nil
+}
+
+func amountOf(value string) model.BigDecimal {
+	return model.BigDecimal{Decimal: decimal.MustParse(value)}
+}
+
+// transferEntry builds the journal entry of a transfer of the amount in the currency of the account
+func transferEntry(reference string, transactionType model.TransactionType, accountID uint, amount model.BigDecimal) (*model.JournalEntry, error) {
+	return TransactionEntry(&model.Transaction{
+		Reference: reference,
+		Type:      transactionType,
+		AccountID: accountID,
+		Amount:    amount,
+	})
+}
+
+func Test_TransactionEntryIsBalanced(t *testing.T) {
+	for _, transactionType := range []model.TransactionType{model.DebitTransaction, model.CreditTransaction} {
+		entry, err := transferEntry("ref1", transactionType, 1, amountOf("100.00"))
+		assert.NoError(t, err)
+		assert.Len(t, entry.Postings, 2)
+		assert.NoError(t, Validate(entry))
+	}
+}
+
+func Test_TransactionEntryRejectsUnknownType(t *testing.T) {
+	_, err := transferEntry("ref1", "flier", 1, amountOf("100.00"))
+	assert.True(t, errors.Is(err, ErrInvalidPosting))
+}
+
+func Test_TransactionEntryExchangesConvertedTransfers(t *testing.T) {
+	transaction := &model.Transaction{
+		Reference:        "ref1",
+		AccountID:        1,
+		Amount:           amountOf("155025.00"),
+		Currency:         "NGN",
+		TransferAmount:   amountOf("100.00"),
+		TransferCurrency: "USD",
+	}
+
+	for _, transactionType := range []model.TransactionType{model.DebitTransaction, model.CreditTransaction} {
+		transaction.Type = transactionType
+		entry, err := TransactionEntry(transaction)
+		assert.NoError(t, err)
+		assert.Len(t, entry.Postings, 4)
+
+		for _, posting := range entry.Postings {
+			switch posting.LedgerCode {
+			case CustomerDepositsGL:
+				assert.Equal(t, "NGN", posting.Currency)
+				assert.Equal(t, 0, posting.Amount.Decimal.Cmp(transaction.Amount.Decimal))
+			case SettlementSuspenseGL:
+				assert.Equal(t, "USD", posting.Currency)
+				assert.Equal(t, 0, posting.Amount.Decimal.Cmp(transaction.TransferAmount.Decimal))
+			default:
+				assert.Equal(t, FXPositionGL, posting.LedgerCode)
+			}
+		}
+	}
+}
+
+func Test_TransactionEntryChargesFee(t *testing.T) {
+	transaction := &model.Transaction{
+		Reference: "ref1",
+		AccountID: 1,
+		Amount:    amountOf("100.00"),
+		Currency:  "NGN",
+		Fee:       amountOf("10.75"),
+	}
+
+	for _, transactionType := range []model.TransactionType{model.DebitTransaction, model.CreditTransaction} {
+		transaction.Type = transactionType
+		entry, err := TransactionEntry(transaction)
+		assert.NoError(t, err)
+		assert.Len(t, entry.Postings, 4)
+
+		feePosting := entry.Postings[3]
+		assert.Equal(t, FeeIncomeGL, feePosting.LedgerCode)
+		assert.Equal(t, model.CreditEntry, feePosting.Direction)
+		assert.Equal(t, "NGN", feePosting.Currency)
+		assert.Equal(t, 0, feePosting.Amount.Decimal.Cmp(decimal.MustParse("10.75")))
+
+		account := &model.Account{AccountID: 1, Currency: "NGN", Balance: amountOf("200.00")}
+		for _, posting := range entry.Postings {
+			if posting.LedgerCode == CustomerDepositsGL {
+				assert.NoError(t, Apply(account, posting))
+			}
+		}
+		expected := map[model.TransactionType]string{
+			model.DebitTransaction:  "89.25",
+			model.CreditTransaction: "289.25",
+		}[transactionType]
+		assert.Equal(t, 0, account.GetBalance().Decimal.Cmp(decimal.MustParse(expected)))
+	}
+}
+
+func Test_InternalTransferEntryBetweenCurrencies(t *testing.T) {
+	entry, err := InternalTransferEntry("ref1",
+		&model.Transaction{AccountID: 1, Amount: amountOf("100.00"), Currency: "USD"},
+		&model.Transaction{AccountID: 2, Amount: amountOf("92.15"), Currency: "EUR"})
+	assert.NoError(t, err)
+	assert.Len(t, entry.Postings, 4)
+
+	_, err = InternalTransferEntry("ref1",
+		&model.Transaction{AccountID: 1, Amount: amountOf("100.00"), Currency: "USD"},
+		&model.Transaction{AccountID: 2, Amount: amountOf("92.15"), Currency: "USD"})
+	assert.Equal(t, ErrUnbalancedEntry, err)
+}
+
+func Test_InterestCapitalisationEntryWithholdsTax(t *testing.T) {
+	credit := &model.Transaction{Reference: "ref1", AccountID: 1, Amount: amountOf("100.00"), Currency: "NGN",
+		Fee: amountOf("10.00")}
+
+	entry, err := InterestCapitalisationEntry(credit)
+	assert.NoError(t, err)
+	assert.Len(t, entry.Postings, 4)
+	assert.Equal(t, InterestExpenseGL, entry.Postings[0].LedgerCode)
+	assert.Equal(t, WithholdingTaxGL, entry.Postings[3].LedgerCode)
+
+	account := &model.Account{AccountID: 1, Currency: "NGN"}
+	for _, posting := range entry.Postings[1:3] {
+		assert.NoError(t, Apply(account, posting))
+	}
+	assert.Equal(t, 0, account.GetBalance().Decimal.Cmp(decimal.MustParse("90.00")))
+
+	credit.Fee = model.BigDecimal{}
+	entry, err = InterestCapitalisationEntry(credit)
+	assert.NoError(t, err)
+	assert.Len(t, entry.Postings, 2)
+}
+
+func Test_ValidateBalancesEachCurrency(t *testing.T) {
+	_, err := NewEntry("ref1", "cross-currency").
+		In("USD").DebitAccount(1, amountOf("100.00")).
+		In("EUR").CreditGL(SettlementSuspenseGL, amountOf("100.00")).
+		Build()
+	assert.Equal(t, ErrUnbalancedEntry, err)
+}
+
+func Test_ValidateRejectsUnbalancedEntry(t *testing.T) {
+	_, err := NewEntry("ref1", "unbalanced").
+		DebitAccount(1, amountOf("100.00")).
+		CreditGL(SettlementSuspenseGL, amountOf("99.99")).
+		Build()
+	assert.Equal(t, ErrUnbalancedEntry, err)
+}
+
+func Test_ValidateRejectsInvalidPostings(t *testing.T) {
+	testCases := []struct {
+		name  string
+		entry *Entry
+		err   error
+	}{
+		{
+			name:  "single posting",
+			entry: NewEntry("ref1", "").DebitAccount(1, amountOf("1")),
+			err:   ErrEmptyEntry,
+		},
+		{
+			name:  "zero amount",
+			entry: NewEntry("ref1", "").DebitAccount(1, amountOf("0")).CreditGL(FeeIncomeGL, amountOf("0")),
+			err:   ErrInvalidPosting,
+		},
+		{
+			name:  "customer posting without account",
+			entry: NewEntry("ref1", "").DebitAccount(0, amountOf("1")).CreditGL(FeeIncomeGL, amountOf("1")),
+			err:   ErrInvalidPosting,
+		},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.entry.Build()
+			assert.Equal(t, tt.err, err)
+		})
+	}
+}
+
+func Test_ApplyDebitAndCredit(t *testing.T) {
+	account := &model.Account{AccountID: 1, Balance: amountOf("100.00")}
+	entry, _ := InternalTransferEntry("ref1",
+		&model.Transaction{AccountID: 1, Amount: amountOf("40.00")},
+		&model.Transaction{AccountID: 2, Amount: amountOf("40.00")})
+
+	assert.NoError(t, Apply(account, entry.Postings[0]))
+	assert.Equal(t, 0, account.GetBalance().Decimal.Cmp(decimal.MustParse("60.00")))
+
+	assert.Equal(t, ErrInvalidPosting, Apply(account, entry.Postings[1]))
+
+	credit := entry.Postings[1]
+	credit.AccountID = 1
+	assert.NoError(t, Apply(account, credit))
+	assert.Equal(t, 0, account.GetBalance().Decimal.Cmp(decimal.MustParse("100.00")))
+}
+
+func Test_ApplyRejectsPostingInAnotherCurrency(t *testing.T) {
+	account := &model.Account{AccountID: 1, Currency: "NGN", Balance: amountOf("100.00")}
+	entry, _ := NewEntry("ref1", "").In("USD").
+		DebitAccount(1, amountOf("10.00")).
+		CreditGL(SettlementSuspenseGL, amountOf("10.00")).
+		Build()
+	assert.True(t, errors.Is(Apply(account, entry.Postings[0]), ErrInvalidPosting))
+	assert.Equal(t, 0, account.GetBalance().Decimal.Cmp(decimal.MustParse("100.00")))
+}
+
+func Test_ApplyRejectsInsufficientFunds(t *testing.T) {
+	account := &model.Account{AccountID: 1, Balance: amountOf("10.00")}
+	entry, _ := transferEntry("ref1", model.DebitTransaction, 1, amountOf("40.00"))
+	assert.Equal(t, model.ErrInsufficientFunds, Apply(account, entry.Postings[0]))
+	assert.Equal(t, 0, account.GetBalance().Decimal.Cmp(decimal.MustParse("10.00")))
+}
+
+func Test_ApplyDebitsDownToTheOverdraftLimit(t *testing.T) {
+	account := &model.Account{AccountID: 1, Balance: amountOf("10.00"), OverdraftLimit: amountOf("50.00")}
+	entry, _ := transferEntry("ref1", model.DebitTransaction, 1, amountOf("40.00"))
+	assert.NoError(t, Apply(account, entry.Postings[0]))
+	assert.Equal(t, 0, account.GetBalance().Decimal.Cmp(decimal.MustParse("-30.00")))
+
+	assert.Equal(t, model.ErrInsufficientFunds, Apply(account, entry.Postings[0]))
+	assert.Equal(t, 0, account.GetBalance().Decimal.Cmp(decimal.MustParse("-30.00")))
+}
+
+func Test_ApplyChargeDebitsBeyondTheOverdraftLimit(t *testing.T) {
+	account := &model.Account{AccountID: 1, Balance: amountOf("-50.00"), OverdraftLimit: amountOf("50.00")}
+	entry, err := OverdraftInterestEntry("ref1", 1, "", amountOf("0.25"))
+	assert.NoError(t, err)
+	assert.Equal(t, OverdraftInterestGL, entry.Postings[1].LedgerCode)
+
+	assert.Equal(t, model.ErrInsufficientFunds, Apply(account, entry.Postings[0]))
+	assert.NoError(t, ApplyCharge(account, entry.Postings[0]))
+	assert.Equal(t, 0, account.GetBalance().Decimal.Cmp(decimal.MustParse("-50.25")))
+}
+
+func Test_CheckAccountBalance(t *testing.T) {
+	repository := &fakeJournalRepository{debits: amountOf("40.00"), credits: amountOf("140.00")}
+	l := NewLedger(repository)
+
+	derived, err := l.CheckAccountBalance(&model.Account{AccountID: 1, Balance: amountOf("100.00")})
+	assert.NoError(t, err)
+	assert.Equal(t, 0, derived.Decimal.Cmp(decimal.MustParse("100.00")))
+
+	_, err = l.CheckAccountBalance(&model.Account{AccountID: 1, Balance: amountOf("90.00")})
+	assert.True(t, errors.Is(err, ErrBalanceMismatch))
+}
+
+func Test_ReconcileReportsAccountsThatStillDoNotMatch(t *testing.T) {
+	now := time.Date(2024, time.March, 1, 9, 0, 0, 0, time.UTC)
+	testCases := []struct {
+		name     string
+		balance  string
+		reloaded string
+	}{
+		{name: "balance matches its postings", balance: "100.00", reloaded: "100.00"},
+		{name: "posting made while the account was read", balance: "90.00", reloaded: "100.00"},
+		{name: "balance does not match its postings", balance: "90.00", reloaded: "90.00"},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			repository := &fakeJournalRepository{
+				debits:   amountOf("40.00"),
+				credits:  amountOf("140.00"),
+				accounts: []model.Account{{AccountID: 1, Balance: amountOf(tt.balance)}},
+				reloaded: &model.Account{AccountID: 1, Balance: amountOf(tt.reloaded)},
+			}
+			l := NewLedger(repository)
+
+			matched, err := l.reconcileAccount(&repository.accounts[0])
+			assert.NoError(t, err)
+			assert.Equal(t, tt.reloaded == "100.00", matched)
+
+			assert.NoError(t, l.Reconcile(context.Background(), now))
+			assert.Equal(t, "2024-03-01", l.reconciledOn)
+		})
+	}
+}
+
+func Test_ReconcileRunsOnceADay(t *testing.T) {
+	now := time.Date(2024, time.March, 1, 9, 0, 0, 0, time.UTC)
+	repository := &fakeJournalRepository{}
+	l := NewLedger(repository)
+
+	assert.NoError(t, l.Reconcile(context.Background(), now))
+	repository.accounts = []model.Account{{AccountID: 1, Balance: amountOf("10.00")}}
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.NoError(t, l.Reconcile(cancelled, now.Add(time.Hour)))
+	assert.Equal(t, context.Canceled, l.Reconcile(cancelled, now.AddDate(0, 0, 1)))
+}
+
+func Test_ReversalEntrySwapsDirections(t *testing.T) {
+	original, _ := transferEntry("ref1", model.DebitTransaction, 1, amountOf("25.00"))
+	reversal, err := ReversalEntry("ref1-rev", original)
+	assert.NoError(t, err)
+	assert.Len(t, reversal.Postings, 2)
+	for i, posting := range reversal.Postings {
+		assert.Equal(t, original.Postings[i].LedgerCode, posting.LedgerCode)
+		assert.Equal(t, original.Postings[i].AccountID, posting.AccountID)
+		assert.Equal(t, original.Postings[i].Currency, posting.Currency)
+		assert.NotEqual(t, original.Postings[i].Direction, posting.Direction)
+	}
+
+	account := &model.Account{AccountID: 1, Balance: amountOf("100.00")}
+	assert.NoError(t, Apply(account, original.Postings[0]))
+	assert.NoError(t, Apply(account, reversal.Postings[0]))
+	assert.Equal(t, 0, account.GetBalance().Decimal.Cmp(decimal.MustParse("100.00")))
+}
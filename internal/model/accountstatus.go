@@ -0,0 +1,138 @@
+package model
+
+import (
+	"bankingApp/internal/api/constants"
+	"errors"
+	"time"
+)
+
+// AccountStatus is the lifecycle status of an account, which decides whether it can be debited or credited
+type AccountStatus string
+
+const (
+	ActiveAccount       AccountStatus = "active"
+	DormantAccount      AccountStatus = "dormant"
+	FrozenAccount       AccountStatus = "frozen"
+	PostNoDebitAccount  AccountStatus = "post_no_debit"
+	PostNoCreditAccount AccountStatus = "post_no_credit"
+	ClosedAccount       AccountStatus = "closed"
+)
+
+// StatusReasonCode is the reason bank staff give for changing the status of an account
+type StatusReasonCode string
+
+const (
+	CustomerRequestReason StatusReasonCode = "customer_request"
+	SuspectedFraudReason  StatusReasonCode = "suspected_fraud"
+	CompromisedReason     StatusReasonCode = "account_compromised"
+	DeceasedReason        StatusReasonCode = "deceased"
+	LegalOrderReason      StatusReasonCode = "legal_order"
+	KYCReviewReason       StatusReasonCode = "kyc_review"
+	InactivityReason      StatusReasonCode = "inactivity"
+	ResolvedReason        StatusReasonCode = "resolved"
+)
+
+var (
+	ErrDebitsNotAllowed       = errors.New(constants.AccountDebitsNotAllowed)
+	ErrCreditsNotAllowed      = errors.New(constants.AccountCreditsNotAllowed)
+	ErrAccountClosed          = errors.New(constants.AccountAlreadyClosed)
+	ErrAccountStatusUnchanged = errors.New(constants.AccountStatusUnchanged)
+	ErrAccountNotEmpty        = errors.New(constants.AccountNotEmpty)
+)
+
+func (s AccountStatus) IsValid() bool {
+	switch s {
+	case ActiveAccount, DormantAccount, FrozenAccount, PostNoDebitAccount, PostNoCreditAccount, ClosedAccount:
+		return true
+	}
+	return false
+}
+
+// AllowsDebits reports whether an account in the status can be debited
+func (s AccountStatus) AllowsDebits() bool {
+	switch s {
+	case DormantAccount, FrozenAccount, PostNoDebitAccount, ClosedAccount:
+		return false
+	}
+	return true
+}
+
+// AllowsCredits reports whether an account in the status can be credited
+func (s AccountStatus) AllowsCredits() bool {
+	switch s {
+	case FrozenAccount, PostNoCreditAccount, ClosedAccount:
+		return false
+	}
+	return true
+}
+
+func (r StatusReasonCode) IsValid() bool {
+	switch r {
+	case CustomerRequestReason, SuspectedFraudReason, CompromisedReason, DeceasedReason,
+		LegalOrderReason, KYCReviewReason, InactivityReason, ResolvedReason:
+		return true
+	}
+	return false
+}
+
+// AccountStatusChange is the audit trail of the status of an account. A change is recorded every time
+// bank staff move the account to another status, with the reason code and the member of staff who did it.
+type AccountStatusChange struct {
+	AccountStatusChangeID uint             `gorm:"primaryKey"`
+	AccountID             uint             `gorm:"index"`
+	PreviousStatus        AccountStatus    `gorm:"type:varchar(20)"`
+	Status                AccountStatus    `gorm:"type:varchar(20)"`
+	ReasonCode            StatusReasonCode `gorm:"type:varchar(30)"`
+	ChangedBy             string           `gorm:"type:varchar(100)"`
+	Reason                string
+	ChangedAt             time.Time
+	TimestampData
+}
+
+// CheckDebit returns an error when the status of the account does not allow it to be debited
+func (acc *Account) CheckDebit() error {
+	if !acc.Status.AllowsDebits() {
+		return ErrDebitsNotAllowed
+	}
+	return nil
+}
+
+// CheckCredit returns an error when the status of the account does not allow it to be credited
+func (acc *Account) CheckCredit() error {
+	if !acc.Status.AllowsCredits() {
+		return ErrCreditsNotAllowed
+	}
+	return nil
+}
+
+// ChangeStatus moves the account to the status and returns the change made, which changedBy is recorded against.
+// A closed account cannot change status, and an account can only be closed once it holds no funds.
+func (acc *Account) ChangeStatus(
+	status AccountStatus,
+	reasonCode StatusReasonCode,
+	changedBy, reason string,
+	now time.Time) (*AccountStatusChange, error) {
+	acc.mu.Lock()
+	defer acc.mu.Unlock()
+	switch {
+	case acc.Status == ClosedAccount:
+		return nil, ErrAccountClosed
+	case acc.Status == status:
+		return nil, ErrAccountStatusUnchanged
+	case status == ClosedAccount && (!acc.Balance.Decimal.IsZero() || !acc.HeldBalance.Decimal.IsZero()):
+		return nil, ErrAccountNotEmpty
+	}
+
+	change := &AccountStatusChange{
+		AccountID:      acc.AccountID,
+		PreviousStatus: acc.Status,
+		Status:         status,
+		ReasonCode:     reasonCode,
+		ChangedBy:      changedBy,
+		Reason:         reason,
+		ChangedAt:      now,
+		TimestampData:  TimestampData{CreatedAt: now},
+	}
+	acc.Status = status
+	return change, nil
+}
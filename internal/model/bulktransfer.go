@@ -0,0 +1,70 @@
+package model
+
+import "time"
+
+type BulkTransferBatchStatus string
+
+const (
+	BatchPending    BulkTransferBatchStatus = "pending"
+	BatchProcessing BulkTransferBatchStatus = "processing"
+	BatchCompleted  BulkTransferBatchStatus = "completed"
+)
+
+type BulkTransferRowStatus string
+
+const (
+	RowPending   BulkTransferRowStatus = "pending"
+	RowSucceeded BulkTransferRowStatus = "succeeded"
+	RowFailed    BulkTransferRowStatus = "failed"
+	RowUnknown   BulkTransferRowStatus = "unknown"
+	// RowRejected rows failed validation when the batch was submitted and are never executed
+	RowRejected BulkTransferRowStatus = "rejected"
+)
+
+// BulkTransferBatch is a file of transfers submitted together and executed in the background.
+// The counters are updated as rows finish, so they show the progress of the batch.
+type BulkTransferBatch struct {
+	BatchID uint `gorm:"primaryKey"`
+	// UserID is the user who submitted the batch. Its rows only move money on accounts they own.
+	UserID        uint                    `gorm:"index"`
+	Source        string                  `gorm:"type:varchar(10)"`
+	Status        BulkTransferBatchStatus `gorm:"type:varchar(20);index"`
+	TotalRows     int
+	ProcessedRows int
+	SucceededRows int
+	FailedRows    int
+	UnknownRows   int
+	CompletedAt   *time.Time
+	TimestampData
+}
+
+// BulkTransferRow is one transfer of a batch together with its result. Rows are sent on behalf of
+// the user who submitted the batch, who is authenticated once by their access token.
+type BulkTransferRow struct {
+	RowID            uint   `gorm:"primaryKey"`
+	BatchID          uint   `gorm:"index"`
+	RowNumber        int    `gorm:"column:line_number"`
+	AccountNumber    string `gorm:"type:varchar(10)"`
+	Username         string
+	PaymentReference string
+	Amount           BigDecimal            `gorm:"type:decimal(20,4)"`
+	Currency         string                `gorm:"type:varchar(3)"`
+	Type             TransactionType       `gorm:"type:varchar(10)"`
+	Status           BulkTransferRowStatus `gorm:"type:varchar(20);index"`
+	Message          string
+	ProcessedAt      *time.Time
+}
+
+// TransferRequest builds the transfer request the row is executed with
+func (r *BulkTransferRow) TransferRequest() TransactionRequestDTO {
+	return TransactionRequestDTO{
+		TransactionDataDTO: TransactionDataDTO{
+			AccountNumber: r.AccountNumber,
+			Username:      r.Username,
+			Reference:     r.PaymentReference,
+			Amount:        r.Amount,
+			Currency:      r.Currency,
+			Type:          r.Type,
+		},
+	}
+}
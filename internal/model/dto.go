@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"errors"
 	"strconv"
+	"time"
 
 	"github.com/govalues/decimal"
 )
@@ -24,12 +25,24 @@ type IAppConfiguration interface {
 	MaximumIdleTime() int
 	MaximumTime() int
 	JwtSecret() string
+	ReferenceFormat() string
+	ReferenceNodeID() int64
+	ReferencePrefix() string
+	SchedulerInterval() int
+	ExchangeRatesFile() string
+	DefaultCurrency() string
+	PinMaxAttempts() int
+	PinLockoutSeconds() int
+	LoginMaxAttempts() int
+	LoginLockoutSeconds() int
+	NotificationsFile() string
 }
 
 type ThirdPartyTransactionDataDTO struct {
 	AccountID string      `json:"account_id,omitempty" mapstructure:"account_id"`
 	Reference string      `json:"reference,omitempty"`
 	Amount    *BigDecimal `json:"amount,omitempty"`
+	Currency  string      `json:"currency,omitempty"`
 }
 
 type DebitRequestDTO struct {
@@ -42,7 +55,17 @@ type CreditRequestDTO struct {
 
 type ResponseDTO struct {
 	ThirdPartyTransactionDataDTO
-	PaymentReference string `json:"payment_reference,omitempty"`
+	PaymentReference string             `json:"payment_reference,omitempty"`
+	Status           TransactionStatus  `json:"status,omitempty"`
+	StatusHistory    []StatusHistoryDTO `json:"status_history,omitempty"`
+	Fee              *FeeBreakdownDTO   `json:"fee,omitempty"`
+}
+
+type StatusHistoryDTO struct {
+	FromStatus TransactionStatus `json:"from_status,omitempty"`
+	ToStatus   TransactionStatus `json:"to_status"`
+	Reason     string            `json:"reason,omitempty"`
+	ChangedAt  time.Time         `json:"changed_at"`
 }
 
 type TransactionType string
@@ -102,6 +125,7 @@ type TransactionDataDTO struct {
 	TransactionPin string          `json:"transaction_pin" validate:"required,min=4,max=4"`
 	Reference      string          `json:"payment_reference" validate:"required,min=1,max=255"`
 	Amount         BigDecimal      `json:"amount" validate:"required,isPositive"`
+	Currency       string          `json:"currency,omitempty"`
 	Type           TransactionType `json:"type" validate:"required,oneof=credit debit"`
 }
 
@@ -113,3 +137,455 @@ type TransactionResponseDTO struct {
 	TransactionDataDTO
 	Success bool `json:"success"`
 }
+
+type InternalTransferDataDTO struct {
+	SourceAccountNumber      string     `json:"source_account_number" validate:"required,min=10,max=10"`
+	DestinationAccountNumber string     `json:"destination_account_number" validate:"required,min=10,max=10"`
+	Username                 string     `json:"username" validate:"required"`
+	TransactionPin           string     `json:"transaction_pin" validate:"required,min=4,max=4"`
+	Reference                string     `json:"payment_reference" validate:"required,min=1,max=250"`
+	Amount                   BigDecimal `json:"amount" validate:"required,isPositive"`
+	Currency                 string     `json:"currency,omitempty"`
+}
+
+type InternalTransferRequestDTO struct {
+	InternalTransferDataDTO
+}
+
+type InternalTransferResponseDTO struct {
+	SourceAccountNumber      string      `json:"source_account_number"`
+	DestinationAccountNumber string      `json:"destination_account_number"`
+	Amount                   *BigDecimal `json:"amount,omitempty"`
+	Currency                 string      `json:"currency,omitempty"`
+	DebitedAmount            *BigDecimal `json:"debited_amount,omitempty"`
+	DebitedCurrency          string      `json:"debited_currency,omitempty"`
+	CreditedAmount           *BigDecimal `json:"credited_amount,omitempty"`
+	CreditedCurrency         string      `json:"credited_currency,omitempty"`
+	PaymentReference         string      `json:"payment_reference"`
+	DebitReference           string      `json:"debit_reference"`
+	CreditReference          string      `json:"credit_reference"`
+}
+
+type ReversalRequestDTO struct {
+	// Amount is optional, leaving it out reverses everything that has not been reversed yet
+	Amount *BigDecimal `json:"amount,omitempty" validate:"omitempty,isPositive"`
+	Reason string      `json:"reason" validate:"required,max=250"`
+}
+
+type ReversalResponseDTO struct {
+	PaymentReference  string            `json:"payment_reference"`
+	ReversalReference string            `json:"reversal_reference"`
+	Amount            *BigDecimal       `json:"amount,omitempty"`
+	ReversedAmount    *BigDecimal       `json:"reversed_amount,omitempty"`
+	Currency          string            `json:"currency,omitempty"`
+	Status            TransactionStatus `json:"status"`
+	OriginalStatus    TransactionStatus `json:"original_status"`
+}
+
+type ScheduledTransferRequestDTO struct {
+	TransactionDataDTO
+	ExecuteAt time.Time `json:"execute_at" validate:"required"`
+}
+
+type CancelScheduledTransferRequestDTO struct {
+	Username       string `json:"username" validate:"required"`
+	TransactionPin string `json:"transaction_pin" validate:"required,min=4,max=4"`
+}
+
+type ScheduledTransferDTO struct {
+	ScheduledTransferID uint                    `json:"scheduled_transfer_id"`
+	AccountNumber       string                  `json:"account_number"`
+	PaymentReference    string                  `json:"payment_reference"`
+	Amount              *BigDecimal             `json:"amount,omitempty"`
+	Currency            string                  `json:"currency,omitempty"`
+	Type                TransactionType         `json:"type"`
+	ExecuteAt           time.Time               `json:"execute_at"`
+	Status              ScheduledTransferStatus `json:"status"`
+	Attempts            int                     `json:"attempts"`
+	FailureReason       string                  `json:"failure_reason,omitempty"`
+	LastAttemptAt       *time.Time              `json:"last_attempt_at,omitempty"`
+}
+
+type StandingOrderRequestDTO struct {
+	TransactionDataDTO
+	Frequency            string                  `json:"frequency" validate:"required"`
+	Interval             int                     `json:"interval"`
+	CronExpression       string                  `json:"cron_expression"`
+	StartAt              time.Time               `json:"start_at" validate:"required"`
+	EndAt                *time.Time              `json:"end_at"`
+	MaxOccurrences       int                     `json:"max_occurrences"`
+	OnInsufficientFunds  InsufficientFundsPolicy `json:"on_insufficient_funds"`
+	MaxRetries           int                     `json:"max_retries"`
+	RetryIntervalMinutes int                     `json:"retry_interval_minutes"`
+}
+
+type CancelStandingOrderRequestDTO struct {
+	Username       string `json:"username" validate:"required"`
+	TransactionPin string `json:"transaction_pin" validate:"required,min=4,max=4"`
+}
+
+type StandingOrderDTO struct {
+	StandingOrderID      uint                    `json:"standing_order_id"`
+	AccountNumber        string                  `json:"account_number"`
+	Reference            string                  `json:"payment_reference"`
+	Amount               *BigDecimal             `json:"amount,omitempty"`
+	Currency             string                  `json:"currency,omitempty"`
+	Type                 TransactionType         `json:"type"`
+	Frequency            string                  `json:"frequency"`
+	Interval             int                     `json:"interval,omitempty"`
+	CronExpression       string                  `json:"cron_expression,omitempty"`
+	StartAt              time.Time               `json:"start_at"`
+	EndAt                *time.Time              `json:"end_at,omitempty"`
+	MaxOccurrences       int                     `json:"max_occurrences,omitempty"`
+	OnInsufficientFunds  InsufficientFundsPolicy `json:"on_insufficient_funds"`
+	MaxRetries           int                     `json:"max_retries"`
+	RetryIntervalMinutes int                     `json:"retry_interval_minutes"`
+	Occurrences          int                     `json:"occurrences"`
+	NextRunAt            *time.Time              `json:"next_run_at,omitempty"`
+	Status               StandingOrderStatus     `json:"status"`
+	FailureReason        string                  `json:"failure_reason,omitempty"`
+}
+
+type StandingOrderExecutionDTO struct {
+	Occurrence       int                  `json:"occurrence"`
+	Attempt          int                  `json:"attempt"`
+	ScheduledFor     time.Time            `json:"scheduled_for"`
+	PaymentReference string               `json:"payment_reference"`
+	Outcome          StandingOrderOutcome `json:"outcome"`
+	Reason           string               `json:"reason,omitempty"`
+	ExecutedAt       time.Time            `json:"executed_at"`
+}
+
+// BulkTransferRowRequestDTO is a transfer of a submitted batch. It names neither a user nor a PIN,
+// as every row is sent on behalf of the user who submitted the batch.
+type BulkTransferRowRequestDTO struct {
+	AccountNumber string          `json:"account_number" validate:"required,min=10,max=10"`
+	Reference     string          `json:"payment_reference" validate:"required,min=1,max=255"`
+	Amount        BigDecimal      `json:"amount" validate:"required,isPositive"`
+	Currency      string          `json:"currency,omitempty"`
+	Type          TransactionType `json:"type" validate:"required,oneof=credit debit"`
+}
+
+type BulkTransferBatchDTO struct {
+	BatchID       uint                    `json:"batch_id"`
+	Source        string                  `json:"source"`
+	Status        BulkTransferBatchStatus `json:"status"`
+	TotalRows     int                     `json:"total_rows"`
+	ProcessedRows int                     `json:"processed_rows"`
+	SucceededRows int                     `json:"succeeded_rows"`
+	FailedRows    int                     `json:"failed_rows"`
+	UnknownRows   int                     `json:"unknown_rows"`
+	SubmittedAt   time.Time               `json:"submitted_at"`
+	CompletedAt   *time.Time              `json:"completed_at,omitempty"`
+	Rows          []BulkTransferRowDTO    `json:"rows,omitempty"`
+}
+
+type BulkTransferRowDTO struct {
+	RowNumber        int                   `json:"row_number"`
+	AccountNumber    string                `json:"account_number"`
+	PaymentReference string                `json:"payment_reference"`
+	Amount           *BigDecimal           `json:"amount,omitempty"`
+	Currency         string                `json:"currency,omitempty"`
+	Type             TransactionType       `json:"type"`
+	Status           BulkTransferRowStatus `json:"status"`
+	Message          string                `json:"message,omitempty"`
+}
+
+type ExchangeRateDTO struct {
+	BaseCurrency  string      `json:"base_currency" validate:"required"`
+	QuoteCurrency string      `json:"quote_currency" validate:"required"`
+	Rate          *BigDecimal `json:"rate" validate:"required"`
+	Source        string      `json:"source,omitempty"`
+	UpdatedAt     *time.Time  `json:"updated_at,omitempty"`
+}
+
+type UpdateExchangeRatesRequestDTO struct {
+	Rates []ExchangeRateDTO `json:"rates" validate:"required,min=1"`
+}
+
+// TransactionLimitDTO holds the limits of a tier or an account. Limits that are omitted or zero are not enforced.
+type TransactionLimitDTO struct {
+	Tier          string      `json:"tier,omitempty"`
+	AccountNumber string      `json:"account_number,omitempty"`
+	MaxAmount     *BigDecimal `json:"max_amount,omitempty"`
+	DailyAmount   *BigDecimal `json:"daily_amount,omitempty"`
+	MonthlyAmount *BigDecimal `json:"monthly_amount,omitempty"`
+	DailyCount    int         `json:"daily_count,omitempty" validate:"min=0"`
+	MonthlyCount  int         `json:"monthly_count,omitempty" validate:"min=0"`
+}
+
+// LimitHeadroomDTO shows how much an account can still debit under its limits. Limits that are not set are omitted.
+type LimitHeadroomDTO struct {
+	AccountNumber string         `json:"account_number"`
+	Tier          string         `json:"tier"`
+	Currency      string         `json:"currency,omitempty"`
+	MaxAmount     *BigDecimal    `json:"max_amount,omitempty"`
+	Daily         LimitPeriodDTO `json:"daily"`
+	Monthly       LimitPeriodDTO `json:"monthly"`
+}
+
+type LimitPeriodDTO struct {
+	AmountLimit     *BigDecimal `json:"amount_limit,omitempty"`
+	AmountUsed      BigDecimal  `json:"amount_used"`
+	AmountRemaining *BigDecimal `json:"amount_remaining,omitempty"`
+	CountLimit      *int        `json:"count_limit,omitempty"`
+	CountUsed       int         `json:"count_used"`
+	CountRemaining  *int        `json:"count_remaining,omitempty"`
+}
+
+// FeeRuleDTO holds a rule of the fee schedule. Criteria that are omitted match any transfer, and a maximum amount
+// or maximum fee that is omitted or zero leaves the band or the fee uncapped.
+type FeeRuleDTO struct {
+	FeeRuleID       uint            `json:"fee_rule_id,omitempty"`
+	TransactionType TransactionType `json:"transaction_type,omitempty"`
+	Channel         Channel         `json:"channel,omitempty"`
+	Currency        string          `json:"currency,omitempty"`
+	MinAmount       *BigDecimal     `json:"min_amount,omitempty"`
+	MaxAmount       *BigDecimal     `json:"max_amount,omitempty"`
+	FlatFee         *BigDecimal     `json:"flat_fee,omitempty"`
+	Percentage      *BigDecimal     `json:"percentage,omitempty"`
+	MinFee          *BigDecimal     `json:"min_fee,omitempty"`
+	MaxFee          *BigDecimal     `json:"max_fee,omitempty"`
+}
+
+type UpdateFeeRulesRequestDTO struct {
+	Rules []FeeRuleDTO `json:"rules" validate:"required"`
+}
+
+// FeeBreakdownDTO shows how the fee of a transfer is made up, in the currency of the account. MinFeeTopUp and
+// MaxFeeReduction are what the minimum and maximum fee of the rule added to or took off the flat and percentage fees.
+// AccountAmount is what the account is debited including the fee, or credited net of it.
+type FeeBreakdownDTO struct {
+	FeeRuleID       uint        `json:"fee_rule_id"`
+	Currency        string      `json:"currency,omitempty"`
+	FlatFee         *BigDecimal `json:"flat_fee"`
+	Percentage      *BigDecimal `json:"percentage"`
+	PercentageFee   *BigDecimal `json:"percentage_fee"`
+	MinFeeTopUp     *BigDecimal `json:"min_fee_top_up,omitempty"`
+	MaxFeeReduction *BigDecimal `json:"max_fee_reduction,omitempty"`
+	Total           *BigDecimal `json:"total"`
+	AccountAmount   *BigDecimal `json:"account_amount"`
+}
+
+type HoldRequestDTO struct {
+	AccountNumber  string     `json:"account_number" validate:"required,min=10,max=10"`
+	Username       string     `json:"username" validate:"required"`
+	TransactionPin string     `json:"transaction_pin" validate:"required,min=4,max=4"`
+	Reference      string     `json:"payment_reference" validate:"required,min=1,max=255"`
+	Amount         BigDecimal `json:"amount" validate:"required,isPositive"`
+	// ExpiresAt is optional, leaving it out holds the funds for DefaultHoldExpiry
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+// TransferRequest builds the debit the hold is checked as when it is placed
+func (r HoldRequestDTO) TransferRequest() TransactionRequestDTO {
+	return TransactionRequestDTO{
+		TransactionDataDTO: TransactionDataDTO{
+			AccountNumber:  r.AccountNumber,
+			Username:       r.Username,
+			TransactionPin: r.TransactionPin,
+			Reference:      r.Reference,
+			Amount:         r.Amount,
+			Type:           DebitTransaction,
+		},
+	}
+}
+
+type CaptureHoldRequestDTO struct {
+	// Amount is optional, leaving it out captures the whole hold
+	Amount *BigDecimal `json:"amount,omitempty" validate:"omitempty,isPositive"`
+}
+
+type HoldDTO struct {
+	HoldID               uint              `json:"hold_id"`
+	AccountNumber        string            `json:"account_number"`
+	PaymentReference     string            `json:"payment_reference"`
+	Amount               *BigDecimal       `json:"amount,omitempty"`
+	CapturedAmount       *BigDecimal       `json:"captured_amount,omitempty"`
+	Currency             string            `json:"currency,omitempty"`
+	Status               HoldStatus        `json:"status"`
+	ExpiresAt            time.Time         `json:"expires_at"`
+	TransactionReference string            `json:"transaction_reference,omitempty"`
+	TransactionStatus    TransactionStatus `json:"transaction_status,omitempty"`
+	AvailableBalance     *BigDecimal       `json:"available_balance,omitempty"`
+}
+
+// OverdraftRequestDTO sets the overdraft facility of an account. A limit of zero removes the facility.
+type OverdraftRequestDTO struct {
+	Limit *BigDecimal `json:"limit" validate:"required"`
+	// InterestRate is the annual percentage charged on the overdrawn balance, leaving it out keeps the current rate
+	InterestRate *BigDecimal `json:"interest_rate,omitempty"`
+	Reason       string      `json:"reason" validate:"max=255"`
+}
+
+// OverdraftRemovalDTO records why the overdraft facility of an account was removed
+type OverdraftRemovalDTO struct {
+	Reason string `json:"reason" validate:"max=255"`
+}
+
+type OverdraftDTO struct {
+	AccountNumber    string               `json:"account_number"`
+	Currency         string               `json:"currency,omitempty"`
+	Limit            *BigDecimal          `json:"limit,omitempty"`
+	InterestRate     *BigDecimal          `json:"interest_rate,omitempty"`
+	OverdrawnAmount  *BigDecimal          `json:"overdrawn_amount,omitempty"`
+	AvailableBalance *BigDecimal          `json:"available_balance,omitempty"`
+	Changes          []OverdraftChangeDTO `json:"changes,omitempty"`
+}
+
+type OverdraftChangeDTO struct {
+	PreviousLimit BigDecimal `json:"previous_limit"`
+	Limit         BigDecimal `json:"limit"`
+	PreviousRate  BigDecimal `json:"previous_interest_rate"`
+	Rate          BigDecimal `json:"interest_rate"`
+	ChangedBy     string     `json:"changed_by"`
+	Reason        string     `json:"reason,omitempty"`
+	ChangedAt     time.Time  `json:"changed_at"`
+}
+
+// RegistrationRequestDTO registers a customer and opens their first account in the currency,
+// or in the default currency of the bank when it is left out
+type RegistrationRequestDTO struct {
+	Username       string `json:"username" validate:"required,min=3,max=50"`
+	Password       string `json:"password" validate:"required,min=8,max=72"`
+	TransactionPin string `json:"transaction_pin" validate:"required,len=4,numeric"`
+	FullName       string `json:"full_name" validate:"required,max=100"`
+	Email          string `json:"email" validate:"required,email,max=100"`
+	PhoneNumber    string `json:"phone_number" validate:"max=20"`
+	Currency       string `json:"currency,omitempty"`
+}
+
+// ProfileUpdateDTO changes the contact details of a user, leaving out a detail keeps it unchanged
+type ProfileUpdateDTO struct {
+	FullName    *string `json:"full_name,omitempty" validate:"omitempty,min=1,max=100"`
+	Email       *string `json:"email,omitempty" validate:"omitempty,email,max=100"`
+	PhoneNumber *string `json:"phone_number,omitempty" validate:"omitempty,max=20"`
+}
+
+// RoleAssignmentDTO gives a user another role, and with it the permissions of the role
+type RoleAssignmentDTO struct {
+	Role UserRole `json:"role" validate:"required"`
+}
+
+type ProfileDTO struct {
+	Username    string              `json:"username"`
+	FullName    string              `json:"full_name,omitempty"`
+	Email       string              `json:"email,omitempty"`
+	PhoneNumber string              `json:"phone_number,omitempty"`
+	Role        UserRole            `json:"role"`
+	Accounts    []ProfileAccountDTO `json:"accounts"`
+	CreatedAt   time.Time           `json:"created_at"`
+}
+
+type ProfileAccountDTO struct {
+	AccountNumber string        `json:"account_number"`
+	Currency      string        `json:"currency"`
+	Status        AccountStatus `json:"status"`
+}
+
+// LoginRequestDTO exchanges the username and password of a user for an access token
+type LoginRequestDTO struct {
+	Username string `json:"username" validate:"required"`
+	Password string `json:"password" validate:"required"`
+}
+
+type LoginResponseDTO struct {
+	AccessToken string    `json:"access_token"`
+	TokenType   string    `json:"token_type"`
+	ExpiresAt   time.Time `json:"expires_at"`
+}
+
+// PinChangeRequestDTO replaces the transaction PIN of the user, who proves they know the current one
+type PinChangeRequestDTO struct {
+	OldPin string `json:"old_pin" validate:"required,len=4,numeric"`
+	NewPin string `json:"new_pin" validate:"required,len=4,numeric"`
+}
+
+// PinResetRequestDTO sets a new transaction PIN with the one-time code sent to the user
+type PinResetRequestDTO struct {
+	Code   string `json:"reset_code" validate:"required,len=6,numeric"`
+	NewPin string `json:"new_pin" validate:"required,len=4,numeric"`
+}
+
+type PinResetCodeDTO struct {
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// AccountStatusRequestDTO moves an account to another status, giving the reason code for the change
+type AccountStatusRequestDTO struct {
+	Status     AccountStatus    `json:"status" validate:"required"`
+	ReasonCode StatusReasonCode `json:"reason_code" validate:"required"`
+	Reason     string           `json:"reason" validate:"max=255"`
+}
+
+type AccountStatusDTO struct {
+	AccountNumber string                   `json:"account_number"`
+	Status        AccountStatus            `json:"status"`
+	Changes       []AccountStatusChangeDTO `json:"changes,omitempty"`
+}
+
+type AccountStatusChangeDTO struct {
+	PreviousStatus AccountStatus    `json:"previous_status"`
+	Status         AccountStatus    `json:"status"`
+	ReasonCode     StatusReasonCode `json:"reason_code"`
+	ChangedBy      string           `json:"changed_by"`
+	Reason         string           `json:"reason,omitempty"`
+	ChangedAt      time.Time        `json:"changed_at"`
+}
+
+type AccountProductDTO struct {
+	Code       string             `json:"code,omitempty"`
+	Name       string             `json:"name" validate:"required,max=100"`
+	AnnualRate *BigDecimal        `json:"annual_rate" validate:"required"`
+	DayCount   DayCountConvention `json:"day_count" validate:"required"`
+	// WithholdingTaxRate is optional, leaving it out capitalises interest without withholding tax
+	WithholdingTaxRate *BigDecimal `json:"withholding_tax_rate,omitempty"`
+}
+
+type AssignProductRequestDTO struct {
+	Product string `json:"product" validate:"required,max=30"`
+}
+
+// InterestDTO shows the savings product of an account and the interest it accrued since its last capitalisation
+type InterestDTO struct {
+	AccountNumber      string             `json:"account_number"`
+	Currency           string             `json:"currency,omitempty"`
+	Product            string             `json:"product,omitempty"`
+	AnnualRate         *BigDecimal        `json:"annual_rate,omitempty"`
+	DayCount           DayCountConvention `json:"day_count,omitempty"`
+	WithholdingTaxRate *BigDecimal        `json:"withholding_tax_rate,omitempty"`
+	AccruedInterest    BigDecimal         `json:"accrued_interest"`
+	AccruedDays        int                `json:"accrued_days"`
+}
+
+type TransactionDTO struct {
+	Reference        string            `json:"reference"`
+	PaymentReference string            `json:"payment_reference"`
+	Type             TransactionType   `json:"type"`
+	Status           TransactionStatus `json:"status"`
+	Amount           *BigDecimal       `json:"amount,omitempty"`
+	Currency         string            `json:"currency,omitempty"`
+	TransferAmount   *BigDecimal       `json:"transfer_amount,omitempty"`
+	TransferCurrency string            `json:"transfer_currency,omitempty"`
+	Fee              *BigDecimal       `json:"fee,omitempty"`
+	ReversedAmount   *BigDecimal       `json:"reversed_amount,omitempty"`
+	TransactionTime  time.Time         `json:"transaction_time"`
+}
+
+type TransactionHistoryDTO struct {
+	AccountNumber string           `json:"account_number"`
+	Transactions  []TransactionDTO `json:"transactions"`
+	// NextCursor is passed as the cursor query parameter to read the next page, it is left out on the last page
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+type BalanceDTO struct {
+	AccountNumber    string      `json:"account_number"`
+	Currency         string      `json:"currency,omitempty"`
+	LedgerBalance    *BigDecimal `json:"ledger_balance"`
+	AvailableBalance *BigDecimal `json:"available_balance"`
+	HeldBalance      *BigDecimal `json:"held_balance,omitempty"`
+	PendingDebits    *BigDecimal `json:"pending_debits,omitempty"`
+	OverdraftLimit   *BigDecimal `json:"overdraft_limit,omitempty"`
+	AsOf             time.Time   `json:"as_of"`
+}
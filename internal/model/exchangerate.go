@@ -0,0 +1,12 @@
+package model
+
+// ExchangeRate is the price of one unit of the base currency in the quote currency.
+// There is one rate per currency pair; loading a new rate for the pair replaces it.
+type ExchangeRate struct {
+	ExchangeRateID uint       `gorm:"primaryKey"`
+	BaseCurrency   string     `gorm:"type:varchar(3);uniqueIndex:idx_exchange_rate_pair"`
+	QuoteCurrency  string     `gorm:"type:varchar(3);uniqueIndex:idx_exchange_rate_pair"`
+	Rate           BigDecimal `gorm:"type:decimal(20,10)"`
+	Source         string     `gorm:"type:varchar(20)"`
+	TimestampData
+}
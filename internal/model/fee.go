@@ -0,0 +1,37 @@
+package model
+
+// Channel is how a transfer was made, which decides the fee rules that apply to it
+type Channel string
+
+const (
+	APIChannel           Channel = "api"
+	ScheduledChannel     Channel = "scheduled"
+	StandingOrderChannel Channel = "standing_order"
+	BulkChannel          Channel = "bulk"
+)
+
+// IsValid reports whether the channel is one transfers are made through
+func (c Channel) IsValid() bool {
+	switch c {
+	case APIChannel, ScheduledChannel, StandingOrderChannel, BulkChannel:
+		return true
+	}
+	return false
+}
+
+// FeeRule charges a fee on transfers of a transaction type, made through a channel, from accounts in a currency,
+// for amounts above MinAmount up to and including MaxAmount. Type, channel and currency left empty match any
+// transfer, and a MaxAmount of zero leaves the band open. Amounts and fees are in the currency of the account.
+type FeeRule struct {
+	FeeRuleID       uint            `gorm:"primaryKey"`
+	TransactionType TransactionType `gorm:"type:varchar(10)"`
+	Channel         Channel         `gorm:"type:varchar(20)"`
+	Currency        string          `gorm:"type:varchar(3)"`
+	MinAmount       BigDecimal      `gorm:"type:decimal(20,4);default:0"`
+	MaxAmount       BigDecimal      `gorm:"type:decimal(20,4);default:0"`
+	FlatFee         BigDecimal      `gorm:"type:decimal(20,4);default:0"`
+	Percentage      BigDecimal      `gorm:"type:decimal(9,6);default:0"`
+	MinFee          BigDecimal      `gorm:"type:decimal(20,4);default:0"`
+	MaxFee          BigDecimal      `gorm:"type:decimal(20,4);default:0"`
+	TimestampData
+}
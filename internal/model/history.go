@@ -0,0 +1,59 @@
+package model
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrInvalidCursor is returned for transaction history cursors that were not issued by the history
+var ErrInvalidCursor = errors.New("transaction history cursor is invalid")
+
+// TransactionFilter selects the transactions listed in the transaction history of an account, newest first
+type TransactionFilter struct {
+	AccountID uint
+	// From and To bound the transaction time, From inclusively and To exclusively. Zero times leave the bound open.
+	From, To        time.Time
+	Type            TransactionType
+	Status          TransactionStatus
+	MinAmount       *BigDecimal
+	MaxAmount       *BigDecimal
+	ReferencePrefix string
+	// After continues the history past the transaction the cursor points at
+	After *TransactionCursor
+	Limit int
+}
+
+// TransactionCursor points at a transaction in the transaction history. Transactions are ordered by transaction
+// time and then by ID, so the cursor stays stable while new transactions are added.
+type TransactionCursor struct {
+	TransactionTime time.Time
+	TransactionID   uint
+}
+
+// CursorOf returns the cursor pointing at the transaction
+func CursorOf(transaction *Transaction) TransactionCursor {
+	return TransactionCursor{TransactionTime: transaction.TransactionTime, TransactionID: transaction.TransactionID}
+}
+
+// Encode returns the opaque form of the cursor handed to clients
+func (c TransactionCursor) Encode() string {
+	raw := fmt.Sprintf("%d:%d", c.TransactionTime.UnixNano(), c.TransactionID)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// ParseTransactionCursor reads a cursor returned by Encode
+func ParseTransactionCursor(encoded string) (TransactionCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return TransactionCursor{}, ErrInvalidCursor
+	}
+
+	var nanos int64
+	var id uint
+	if n, err := fmt.Sscanf(string(raw), "%d:%d", &nanos, &id); err != nil || n != 2 || id == 0 {
+		return TransactionCursor{}, ErrInvalidCursor
+	}
+	return TransactionCursor{TransactionTime: time.Unix(0, nanos).UTC(), TransactionID: id}, nil
+}
@@ -0,0 +1,73 @@
+package model
+
+import (
+	"bankingApp/internal/api/constants"
+	"errors"
+	"time"
+)
+
+type HoldStatus string
+
+const (
+	HoldActive   HoldStatus = "active"
+	HoldCaptured HoldStatus = "captured"
+	HoldReleased HoldStatus = "released"
+	HoldExpired  HoldStatus = "expired"
+)
+
+// DefaultHoldExpiry is how long a hold reserves funds when it is placed without an expiry
+const DefaultHoldExpiry = 7 * 24 * time.Hour
+
+var (
+	ErrHoldNotActive         = errors.New(constants.HoldNotActive)
+	ErrHoldExpired           = errors.New(constants.HoldExpired)
+	ErrCaptureAmountExceeded = errors.New(constants.CaptureAmountExceeded)
+)
+
+// Hold reserves funds of an account without moving them. The amount held counts against the available balance
+// of the account until the hold is captured into a debit, released, or expires.
+type Hold struct {
+	HoldID           uint       `gorm:"primaryKey"`
+	AccountID        uint       `gorm:"index"`
+	AccountNumber    string     `gorm:"type:varchar(10)"`
+	PaymentReference string     `gorm:"index:idx_hold_payment_reference;unique"`
+	Amount           BigDecimal `gorm:"type:decimal(20,4)"`
+	Currency         string     `gorm:"type:varchar(3)"`
+	CapturedAmount   BigDecimal `gorm:"type:decimal(20,4);default:0"`
+	Status           HoldStatus `gorm:"type:varchar(20);index:idx_hold_status_expiry"`
+	ExpiresAt        time.Time  `gorm:"index:idx_hold_status_expiry"`
+	// TransactionID is the debit the hold was captured into
+	TransactionID *uint
+	TimestampData
+}
+
+// IsExpired reports whether the hold has reached its expiry at now
+func (h *Hold) IsExpired(now time.Time) bool {
+	return !now.Before(h.ExpiresAt)
+}
+
+// Capture checks that the active hold has not expired at now and covers the amount, then marks it captured.
+// Whatever is not captured is released with it.
+func (h *Hold) Capture(amount BigDecimal, now time.Time) error {
+	if h.Status != HoldActive {
+		return ErrHoldNotActive
+	}
+	if h.IsExpired(now) {
+		return ErrHoldExpired
+	}
+	if amount.Decimal.Cmp(h.Amount.Decimal) > 0 {
+		return ErrCaptureAmountExceeded
+	}
+	h.CapturedAmount = amount
+	h.Status = HoldCaptured
+	return nil
+}
+
+// Close ends an active hold without capturing it, as released or expired
+func (h *Hold) Close(status HoldStatus) error {
+	if h.Status != HoldActive {
+		return ErrHoldNotActive
+	}
+	h.Status = status
+	return nil
+}
@@ -0,0 +1,52 @@
+package model
+
+import "time"
+
+type EntryDirection string
+
+const (
+	DebitEntry  EntryDirection = "debit"
+	CreditEntry EntryDirection = "credit"
+)
+
+type LedgerAccountType string
+
+const (
+	AssetAccount     LedgerAccountType = "asset"
+	LiabilityAccount LedgerAccountType = "liability"
+	EquityAccount    LedgerAccountType = "equity"
+	IncomeAccount    LedgerAccountType = "income"
+	ExpenseAccount   LedgerAccountType = "expense"
+)
+
+// LedgerAccount is a general ledger account in the bank's chart of accounts
+type LedgerAccount struct {
+	LedgerAccountID uint   `gorm:"primaryKey"`
+	Code            string `gorm:"index:idx_ledger_account_code;unique"`
+	Name            string
+	Type            LedgerAccountType
+	TimestampData
+}
+
+// JournalEntry groups the balanced postings recorded for a single money movement
+type JournalEntry struct {
+	JournalEntryID uint   `gorm:"primaryKey"`
+	Reference      string `gorm:"index:idx_journal_entry_reference"`
+	Description    string
+	Postings       []Posting
+	PostedAt       time.Time `gorm:"index"`
+	TimestampData
+}
+
+// Posting is one debit or credit line of a journal entry. Postings against customer accounts
+// carry the AccountID of the account in the customer deposits sub-ledger and are in the account currency.
+type Posting struct {
+	PostingID      uint   `gorm:"primaryKey"`
+	JournalEntryID uint   `gorm:"index"`
+	LedgerCode     string `gorm:"index:idx_posting_ledger_code"`
+	AccountID      uint   `gorm:"index"`
+	Direction      EntryDirection
+	Amount         BigDecimal `gorm:"type:decimal(20,4)"`
+	Currency       string     `gorm:"type:varchar(3)"`
+	TimestampData
+}
@@ -0,0 +1,105 @@
+package model
+
+import (
+	"bankingApp/internal/api/constants"
+	"errors"
+	"time"
+)
+
+// DefaultAccountTier is the tier of accounts that were not given one
+const DefaultAccountTier = "standard"
+
+var (
+	ErrTransactionLimitExceeded  = errors.New(constants.TransactionLimitExceeded)
+	ErrDailyLimitExceeded        = errors.New(constants.DailyLimitExceeded)
+	ErrMonthlyLimitExceeded      = errors.New(constants.MonthlyLimitExceeded)
+	ErrDailyCountLimitExceeded   = errors.New(constants.DailyCountLimitExceeded)
+	ErrMonthlyCountLimitExceeded = errors.New(constants.MonthlyCountLimitExceeded)
+)
+
+// TransactionLimit caps the debits of an account. A limit belongs either to a tier, applying to every account
+// in the tier, or to a single account, overriding the limits of its tier. Amounts are in the currency of the
+// account and a limit left at zero is not enforced.
+type TransactionLimit struct {
+	TransactionLimitID uint       `gorm:"primaryKey"`
+	Tier               string     `gorm:"type:varchar(20);uniqueIndex:idx_transaction_limit_owner"`
+	AccountID          uint       `gorm:"uniqueIndex:idx_transaction_limit_owner"`
+	MaxAmount          BigDecimal `gorm:"type:decimal(20,4);default:0"`
+	DailyAmount        BigDecimal `gorm:"type:decimal(20,4);default:0"`
+	MonthlyAmount      BigDecimal `gorm:"type:decimal(20,4);default:0"`
+	DailyCount         int
+	MonthlyCount       int
+	TimestampData
+}
+
+// LimitUsage is what an account has debited so far in the current day and month
+type LimitUsage struct {
+	DailyAmount   BigDecimal
+	DailyCount    int
+	MonthlyAmount BigDecimal
+	MonthlyCount  int
+}
+
+// TierOf returns the tier of the account, which is DefaultAccountTier unless it was given one
+func TierOf(account *Account) string {
+	if account.Tier == "" {
+		return DefaultAccountTier
+	}
+	return account.Tier
+}
+
+// Override returns the limits of the tier with every limit that is set on the account limit replacing its own
+func (l TransactionLimit) Override(account TransactionLimit) TransactionLimit {
+	if account.MaxAmount.Decimal.IsPos() {
+		l.MaxAmount = account.MaxAmount
+	}
+	if account.DailyAmount.Decimal.IsPos() {
+		l.DailyAmount = account.DailyAmount
+	}
+	if account.MonthlyAmount.Decimal.IsPos() {
+		l.MonthlyAmount = account.MonthlyAmount
+	}
+	if account.DailyCount > 0 {
+		l.DailyCount = account.DailyCount
+	}
+	if account.MonthlyCount > 0 {
+		l.MonthlyCount = account.MonthlyCount
+	}
+	return l
+}
+
+// Check returns the error of the first limit a debit of the amount would exceed, given what was debited so far
+func (l TransactionLimit) Check(usage LimitUsage, amount BigDecimal) error {
+	switch {
+	case exceedsAmount(l.MaxAmount, BigDecimal{}, amount):
+		return ErrTransactionLimitExceeded
+	case exceedsAmount(l.DailyAmount, usage.DailyAmount, amount):
+		return ErrDailyLimitExceeded
+	case exceedsAmount(l.MonthlyAmount, usage.MonthlyAmount, amount):
+		return ErrMonthlyLimitExceeded
+	case l.DailyCount > 0 && usage.DailyCount >= l.DailyCount:
+		return ErrDailyCountLimitExceeded
+	case l.MonthlyCount > 0 && usage.MonthlyCount >= l.MonthlyCount:
+		return ErrMonthlyCountLimitExceeded
+	}
+	return nil
+}
+
+// exceedsAmount reports whether adding the amount to what was used goes over a limit that is set
+func exceedsAmount(limit, used, amount BigDecimal) bool {
+	if !limit.Decimal.IsPos() {
+		return false
+	}
+	total, err := used.Decimal.Add(amount.Decimal)
+	if err != nil {
+		return true
+	}
+	return total.Cmp(limit.Decimal) > 0
+}
+
+// LimitPeriods returns the start of the day and of the month the given time falls in, in its location
+func LimitPeriods(now time.Time) (time.Time, time.Time) {
+	day := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	month := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+	return day, month
+}
@@ -1,6 +1,11 @@
 package model
 
 import (
+	"bankingApp/internal/api/constants"
+	"bankingApp/internal/credential"
+	"bankingApp/internal/currency"
+	"errors"
+	"fmt"
 	"sync"
 	"time"
 )
@@ -10,21 +15,86 @@ type TimestampData struct {
 	UpdatedAt time.Time
 }
 
+// UserRole separates customers from the bank staff that service their accounts, and grants the user
+// the permissions of the role
+type UserRole string
+
+const (
+	CustomerRole UserRole = "customer"
+	TellerRole   UserRole = "teller"
+	OpsRole      UserRole = "ops"
+	AdminRole    UserRole = "admin"
+	AuditorRole  UserRole = "auditor"
+)
+
 type User struct {
-	UserID         uint   `gorm:"primaryKey"`
-	Username       string `gorm:"index:idx_username;unique"`
-	Password       string
-	TransactionPin string
-	Accounts       []Account
+	UserID           uint   `gorm:"primaryKey"`
+	Username         string `gorm:"index:idx_username;unique"`
+	Password         string
+	TransactionPin   string
+	PinAttempts      int
+	PinLockedUntil   *time.Time
+	LoginAttempts    int
+	LoginLockedUntil *time.Time
+	FullName         string   `gorm:"type:varchar(100)"`
+	Email            string   `gorm:"type:varchar(100)"`
+	PhoneNumber      string   `gorm:"type:varchar(20)"`
+	Role             UserRole `gorm:"type:varchar(20);default:customer"`
+	Accounts         []Account
 	TimestampData
 }
 
+// Can reports whether the role of the user grants the permission
+func (u *User) Can(permission Permission) bool {
+	return u.Role.Can(permission)
+}
+
+// CheckTransactionPin reports whether the PIN matches the transaction PIN of the user
+func (u *User) CheckTransactionPin(pin string) bool {
+	return credential.Verify(u.TransactionPin, pin)
+}
+
+// PinLocked reports whether too many incorrect transaction PINs have locked PIN use of the user at the time
+func (u *User) PinLocked(now time.Time) bool {
+	return u.PinLockedUntil != nil && now.Before(*u.PinLockedUntil)
+}
+
+// LoginLocked reports whether too many incorrect passwords have locked signing in as the user at the time
+func (u *User) LoginLocked(now time.Time) bool {
+	return u.LoginLockedUntil != nil && now.Before(*u.LoginLockedUntil)
+}
+
+// Lockout is how many incorrect passwords or transaction PINs in a row lock their use, and for how long
+type Lockout struct {
+	MaxAttempts int
+	Cooldown    time.Duration
+}
+
+var (
+	ErrUsernameTaken      = errors.New(constants.UsernameTaken)
+	ErrAccountNumberTaken = errors.New("account number is already taken")
+	ErrIncorrectPin       = errors.New(constants.IncorrectTransactionPin)
+	ErrPinLocked          = errors.New(constants.TransactionPinLocked)
+)
+
 type Account struct {
-	AccountID     uint   `gorm:"primaryKey"`
-	UserID        uint   // Foreign key referencing the User table
-	AccountNumber string `gorm:"index:idx_account_number;unique"`
-	Balance       BigDecimal
-	mu            sync.Mutex `gorm:"-"`
+	AccountID     uint       `gorm:"primaryKey"`
+	UserID        uint       // Foreign key referencing the User table
+	AccountNumber string     `gorm:"index:idx_account_number;unique"`
+	Currency      string     `gorm:"type:varchar(3)"`                   // ISO 4217 code of the currency the account is held in
+	Tier          string     `gorm:"type:varchar(20);default:standard"` // Tier setting the default transaction limits
+	Product       string     `gorm:"type:varchar(30);index"`            // Code of the savings product the account accrues interest under
+	Balance       BigDecimal `gorm:"type:decimal(20,4)"`
+	HeldBalance   BigDecimal `gorm:"type:decimal(20,4);default:0"` // Funds reserved by active holds
+	// Status is the lifecycle status of the account, which decides whether it can be debited or credited
+	Status AccountStatus `gorm:"type:varchar(20);default:active"`
+	// OverdraftLimit is how far below zero debits may take the balance, at the annual percentage OverdraftRate
+	OverdraftLimit BigDecimal `gorm:"type:decimal(20,4);default:0"`
+	OverdraftRate  BigDecimal `gorm:"type:decimal(9,6);default:0"`
+	// InterestCarried is the accrued interest below the minor unit of the currency left over from the last
+	// capitalisation, carried into the next one
+	InterestCarried BigDecimal `gorm:"type:decimal(38,18);default:0"`
+	mu              sync.Mutex `gorm:"-"`
 	TimestampData
 }
 
@@ -36,12 +106,10 @@ func (acc *Account) GetBalance() BigDecimal {
 	return acc.Balance
 }
 
-const scale = 2
-
 func (acc *Account) Deposit(amount BigDecimal) error {
 	acc.mu.Lock()
 	defer acc.mu.Unlock()
-	newBalance, err := acc.GetBalance().Decimal.AddExact(amount.Decimal, scale)
+	newBalance, err := acc.GetBalance().Decimal.AddExact(amount.Decimal, currency.MinorUnits(acc.Currency))
 	if err != nil {
 		return err
 	}
@@ -52,7 +120,7 @@ func (acc *Account) Deposit(amount BigDecimal) error {
 func (acc *Account) Withdraw(amount BigDecimal) error {
 	acc.mu.Lock()
 	defer acc.mu.Unlock()
-	newBalance, err := acc.GetBalance().Decimal.SubExact(amount.Decimal, scale)
+	newBalance, err := acc.GetBalance().Decimal.SubExact(amount.Decimal, currency.MinorUnits(acc.Currency))
 	if err != nil {
 		return err
 	}
@@ -62,20 +130,268 @@ func (acc *Account) Withdraw(amount BigDecimal) error {
 
 const insufficientBalanceFlag = -1
 
+// ErrInsufficientFunds is returned when a debit would take an account beyond its overdraft limit
+var ErrInsufficientFunds = errors.New(constants.InsufficientFunds)
+
+// IsInsufficientBalance reports whether the available balance of the account cannot cover the amount
 func (acc *Account) IsInsufficientBalance(amount BigDecimal) bool {
 	acc.mu.Lock()
 	defer acc.mu.Unlock()
-	return acc.GetBalance().Decimal.Cmp(amount.Decimal) == insufficientBalanceFlag
+	return acc.availableBalance().Decimal.Cmp(amount.Decimal) == insufficientBalanceFlag
+}
+
+// AvailableBalance returns the balance of the account plus its overdraft limit, less the funds reserved by its active holds
+func (acc *Account) AvailableBalance() BigDecimal {
+	acc.mu.Lock()
+	defer acc.mu.Unlock()
+	return acc.availableBalance()
+}
+
+// PlaceHold reserves the amount out of the available balance
+func (acc *Account) PlaceHold(amount BigDecimal) error {
+	acc.mu.Lock()
+	defer acc.mu.Unlock()
+	if acc.availableBalance().Decimal.Cmp(amount.Decimal) == insufficientBalanceFlag {
+		return ErrInsufficientFunds
+	}
+	held, err := acc.HeldBalance.Decimal.AddExact(amount.Decimal, currency.MinorUnits(acc.Currency))
+	if err != nil {
+		return err
+	}
+	acc.HeldBalance = BigDecimal{Decimal: held}
+	return nil
+}
+
+// ReleaseHold gives an amount reserved by a hold back to the available balance
+func (acc *Account) ReleaseHold(amount BigDecimal) error {
+	acc.mu.Lock()
+	defer acc.mu.Unlock()
+	held, err := acc.HeldBalance.Decimal.SubExact(amount.Decimal, currency.MinorUnits(acc.Currency))
+	if err != nil {
+		return err
+	}
+	if held.IsNeg() {
+		return fmt.Errorf("account %s releases %s more than it holds", acc.AccountNumber, held.Neg())
+	}
+	acc.HeldBalance = BigDecimal{Decimal: held}
+	return nil
+}
+
+func (acc *Account) availableBalance() BigDecimal {
+	available, err := acc.Balance.Decimal.Sub(acc.HeldBalance.Decimal)
+	if err != nil {
+		return acc.Balance
+	}
+	if available, err = available.Add(acc.OverdraftLimit.Decimal); err != nil {
+		return acc.Balance
+	}
+	return BigDecimal{Decimal: available}
 }
 
 type Transaction struct {
 	TransactionID    uint   `gorm:"primaryKey"`
-	AccountID        uint   `gorm:"index"`
+	AccountID        uint   `gorm:"index:idx_transaction_account_time,priority:1"`
 	Reference        string `gorm:"index:idx_reference;unique"`
 	PaymentReference string `gorm:"column:payment_reference;index:idx_payment_reference;unique"`
-	Amount           BigDecimal
-	Type             TransactionType
-	Success          bool
-	TransactionTime  time.Time
+	// CorrelationReference links the legs of an internal transfer together
+	CorrelationReference string `gorm:"index:idx_correlation_reference"`
+	// Amount is in Currency, the currency of the account
+	Amount   BigDecimal `gorm:"type:decimal(20,4)"`
+	Currency string     `gorm:"type:varchar(3)"`
+	// TransferAmount is the amount in the currency the transfer was requested in,
+	// converted to Amount at ExchangeRate units of Currency per unit of TransferCurrency
+	TransferAmount   BigDecimal `gorm:"type:decimal(20,4);default:0"`
+	TransferCurrency string     `gorm:"type:varchar(3)"`
+	ExchangeRate     BigDecimal `gorm:"type:decimal(20,10);default:0"`
+	// Fee is charged to the account in Currency, on top of a debit or out of a credit
+	Fee     BigDecimal `gorm:"type:decimal(20,4);default:0"`
+	Type    TransactionType
+	Status  TransactionStatus `gorm:"type:varchar(20);index;default:succeeded"`
+	Success bool
+	// OriginalTransactionID links a reversal to the transaction it compensates
+	OriginalTransactionID *uint      `gorm:"index"`
+	ReversedAmount        BigDecimal `gorm:"type:decimal(20,4);default:0"`
+	TransactionTime       time.Time  `gorm:"index:idx_transaction_account_time,priority:2"`
+	TimestampData
+}
+
+var (
+	ErrTransactionNotReversible   = errors.New(constants.TransactionNotReversible)
+	ErrTransactionAlreadyReversed = errors.New(constants.TransactionAlreadyReversed)
+	ErrReversalAmountExceeded     = errors.New(constants.ReversalAmountExceeded)
+)
+
+// ReversibleAmount returns the part of the transaction amount that has not been reversed yet
+func (t *Transaction) ReversibleAmount() (BigDecimal, error) {
+	remaining, err := t.Amount.Decimal.SubExact(t.ReversedAmount.Decimal, currency.MinorUnits(t.Currency))
+	if err != nil {
+		return BigDecimal{}, err
+	}
+	return BigDecimal{Decimal: remaining}, nil
+}
+
+// CheckReversal returns an error when the amount cannot be reversed from the transaction.
+// Only succeeded transactions that are not reversals themselves can be reversed, up to their amount.
+func (t *Transaction) CheckReversal(amount BigDecimal) error {
+	if t.Status == ReversedStatus {
+		return ErrTransactionAlreadyReversed
+	}
+	if t.Status != SucceededStatus || t.OriginalTransactionID != nil {
+		return ErrTransactionNotReversible
+	}
+
+	remaining, err := t.ReversibleAmount()
+	if err != nil {
+		return err
+	}
+	if !remaining.Decimal.IsPos() {
+		return ErrTransactionAlreadyReversed
+	}
+	if amount.Decimal.Cmp(remaining.Decimal) > 0 {
+		return ErrReversalAmountExceeded
+	}
+	return nil
+}
+
+// AddReversal checks the amount can still be reversed and adds it to the reversed amount.
+// It reports whether the transaction is now fully reversed.
+func (t *Transaction) AddReversal(amount BigDecimal) (bool, error) {
+	if err := t.CheckReversal(amount); err != nil {
+		return false, err
+	}
+	reversed, err := t.ReversedAmount.Decimal.AddExact(amount.Decimal, currency.MinorUnits(t.Currency))
+	if err != nil {
+		return false, err
+	}
+	t.ReversedAmount = BigDecimal{Decimal: reversed}
+	return t.IsFullyReversed(), nil
+}
+
+// ReleaseReversal gives back an amount added by a reversal that did not go through
+func (t *Transaction) ReleaseReversal(amount BigDecimal) error {
+	reversed, err := t.ReversedAmount.Decimal.SubExact(amount.Decimal, currency.MinorUnits(t.Currency))
+	if err != nil {
+		return err
+	}
+	if reversed.IsNeg() {
+		return ErrReversalAmountExceeded
+	}
+	t.ReversedAmount = BigDecimal{Decimal: reversed}
+	return nil
+}
+
+// IsConverted reports whether the transfer was requested in a currency other than the account currency
+func (t *Transaction) IsConverted() bool {
+	return t.TransferCurrency != "" && t.TransferCurrency != t.Currency
+}
+
+// SettlementAmount returns the amount and currency the transaction is settled in with the third-party provider
+func (t *Transaction) SettlementAmount() (BigDecimal, string) {
+	if t.IsConverted() {
+		return t.TransferAmount, t.TransferCurrency
+	}
+	return t.Amount, t.Currency
+}
+
+// TransferAmountOf returns the part of the transfer amount that corresponds to the given part of the amount,
+// converted back at the rate of the transaction. The whole amount gives back the whole transfer amount.
+func (t *Transaction) TransferAmountOf(amount BigDecimal) (BigDecimal, error) {
+	if !t.IsConverted() {
+		return amount, nil
+	}
+	if amount.Decimal.Cmp(t.Amount.Decimal) == 0 {
+		return t.TransferAmount, nil
+	}
+
+	transferAmount, err := amount.Decimal.Quo(t.ExchangeRate.Decimal)
+	if err != nil {
+		return BigDecimal{}, err
+	}
+	return BigDecimal{Decimal: transferAmount.Round(currency.MinorUnits(t.TransferCurrency))}, nil
+}
+
+// IsFullyReversed reports whether the whole amount of the transaction has been reversed
+func (t *Transaction) IsFullyReversed() bool {
+	return t.ReversedAmount.Decimal.Cmp(t.Amount.Decimal) >= 0
+}
+
+type TransactionStatus string
+
+const (
+	PendingStatus   TransactionStatus = "pending"
+	SubmittedStatus TransactionStatus = "submitted"
+	SucceededStatus TransactionStatus = "succeeded"
+	FailedStatus    TransactionStatus = "failed"
+	UnknownStatus   TransactionStatus = "unknown"
+	ReversedStatus  TransactionStatus = "reversed"
+)
+
+// transactionTransitions lists the statuses a transaction may move to from each status
+var transactionTransitions = map[TransactionStatus][]TransactionStatus{
+	PendingStatus:   {SubmittedStatus, FailedStatus},
+	SubmittedStatus: {SucceededStatus, FailedStatus, UnknownStatus},
+	UnknownStatus:   {SucceededStatus, FailedStatus},
+	SucceededStatus: {ReversedStatus},
+}
+
+// ErrInvalidStatusTransition is returned when a transaction is moved to a status its current status does not allow
+var ErrInvalidStatusTransition = errors.New("invalid transaction status transition")
+
+// CanTransitionTo reports whether a transaction in this status may move to next
+func (s TransactionStatus) CanTransitionTo(next TransactionStatus) bool {
+	for _, allowed := range transactionTransitions[s] {
+		if allowed == next {
+			return true
+		}
+	}
+	return false
+}
+
+// IsValid reports whether the status is one of the lifecycle statuses of a transaction
+func (s TransactionStatus) IsValid() bool {
+	switch s {
+	case PendingStatus, SubmittedStatus, SucceededStatus, FailedStatus, UnknownStatus, ReversedStatus:
+		return true
+	}
+	return false
+}
+
+// IsInitial reports whether a transaction may be created in this status. Transfers through the
+// third-party provider start as pending; internal movements that complete immediately start as succeeded.
+func (s TransactionStatus) IsInitial() bool {
+	return s == PendingStatus || s == SucceededStatus
+}
+
+// TransitionTo moves the transaction to the next status if the state machine allows it
+func (t *Transaction) TransitionTo(next TransactionStatus) error {
+	if !t.Status.CanTransitionTo(next) {
+		return fmt.Errorf("%w: %s to %s", ErrInvalidStatusTransition, t.Status, next)
+	}
+	t.Status = next
+	t.Success = next == SucceededStatus
+	return nil
+}
+
+// TransactionStatusHistory records every status a transaction has been in
+type TransactionStatusHistory struct {
+	TransactionStatusHistoryID uint              `gorm:"primaryKey"`
+	TransactionID              uint              `gorm:"index"`
+	FromStatus                 TransactionStatus `gorm:"type:varchar(20)"`
+	ToStatus                   TransactionStatus `gorm:"type:varchar(20)"`
+	Reason                     string
+	ChangedAt                  time.Time
+}
+
+// IdempotencyRecord stores the outcome of a request made with an Idempotency-Key header so retries can be replayed
+type IdempotencyRecord struct {
+	IdempotencyRecordID uint   `gorm:"primaryKey"`
+	IdempotencyKey      string `gorm:"index:idx_idempotency_key;unique"`
+	RequestHash         string
+	StatusCode          int
+	ResponseBody        string `gorm:"type:text"`
+	Completed           bool
+	// ClaimedAt is when the request holding the key started. A claim left without a response for longer than
+	// the claim TTL is taken to be abandoned, and a retry of the request can take it over.
+	ClaimedAt time.Time
 	TimestampData
 }
@@ -0,0 +1,90 @@
+package model
+
+import (
+	"bankingApp/internal/currency"
+	"time"
+
+	"github.com/govalues/decimal"
+)
+
+// DaysPerYear is the day count the annual overdraft interest rate is divided by to accrue daily interest
+const DaysPerYear = 365
+
+// yearlyPercentDivisor turns an annual percentage rate into the rate of a single day
+var yearlyPercentDivisor = decimal.MustNew(100*DaysPerYear, 0)
+
+// OverdraftChange is the audit trail of the overdraft facility of an account. A change is recorded
+// every time bank staff set, change or remove the overdraft limit or interest rate of the account.
+type OverdraftChange struct {
+	OverdraftChangeID uint       `gorm:"primaryKey"`
+	AccountID         uint       `gorm:"index"`
+	PreviousLimit     BigDecimal `gorm:"type:decimal(20,4);default:0"`
+	Limit             BigDecimal `gorm:"type:decimal(20,4);default:0"`
+	PreviousRate      BigDecimal `gorm:"type:decimal(9,6);default:0"`
+	Rate              BigDecimal `gorm:"type:decimal(9,6);default:0"`
+	ChangedBy         string     `gorm:"type:varchar(100)"`
+	Reason            string
+	ChangedAt         time.Time
+	TimestampData
+}
+
+// OverdraftInterestAccrual is the interest charged to an overdrawn account for one day. An account accrues
+// interest at most once a day, which the unique index on the account and the day enforces.
+type OverdraftInterestAccrual struct {
+	OverdraftInterestAccrualID uint       `gorm:"primaryKey"`
+	AccountID                  uint       `gorm:"uniqueIndex:idx_overdraft_accrual_day"`
+	AccrualDate                time.Time  `gorm:"uniqueIndex:idx_overdraft_accrual_day"`
+	Reference                  string     `gorm:"type:varchar(60)"`
+	Balance                    BigDecimal `gorm:"type:decimal(20,4)"`
+	Rate                       BigDecimal `gorm:"type:decimal(9,6)"`
+	Amount                     BigDecimal `gorm:"type:decimal(20,4)"`
+	TimestampData
+}
+
+// SetOverdraft sets the overdraft limit and annual interest rate of the account and returns the change made,
+// which changedBy is recorded against. A limit of zero removes the overdraft facility.
+func (acc *Account) SetOverdraft(limit, rate BigDecimal, changedBy, reason string, now time.Time) *OverdraftChange {
+	acc.mu.Lock()
+	defer acc.mu.Unlock()
+	change := &OverdraftChange{
+		AccountID:     acc.AccountID,
+		PreviousLimit: acc.OverdraftLimit,
+		Limit:         limit,
+		PreviousRate:  acc.OverdraftRate,
+		Rate:          rate,
+		ChangedBy:     changedBy,
+		Reason:        reason,
+		ChangedAt:     now,
+		TimestampData: TimestampData{CreatedAt: now},
+	}
+	acc.OverdraftLimit, acc.OverdraftRate = limit, rate
+	return change
+}
+
+// OverdrawnAmount returns how far the balance of the account is below zero, or zero when it is not overdrawn
+func (acc *Account) OverdrawnAmount() BigDecimal {
+	acc.mu.Lock()
+	defer acc.mu.Unlock()
+	if !acc.Balance.Decimal.IsNeg() {
+		return BigDecimal{}
+	}
+	return BigDecimal{Decimal: acc.Balance.Decimal.Neg()}
+}
+
+// DailyOverdraftInterest returns the interest a day the account closed on the balance accrues at the annual
+// overdraft rate of the account, rounded to the minor units of its currency. Days closed in credit accrue nothing.
+func (acc *Account) DailyOverdraftInterest(closing BigDecimal) (BigDecimal, error) {
+	if !closing.Decimal.IsNeg() || !acc.OverdraftRate.Decimal.IsPos() {
+		return BigDecimal{}, nil
+	}
+
+	yearly, err := closing.Decimal.Neg().Mul(acc.OverdraftRate.Decimal)
+	if err != nil {
+		return BigDecimal{}, err
+	}
+	daily, err := yearly.Quo(yearlyPercentDivisor)
+	if err != nil {
+		return BigDecimal{}, err
+	}
+	return BigDecimal{Decimal: daily.Round(currency.MinorUnits(acc.Currency))}, nil
+}
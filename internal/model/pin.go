@@ -0,0 +1,72 @@
+package model
+
+import (
+	"bankingApp/internal/api/constants"
+	"errors"
+	"time"
+)
+
+// PinAction is what a user tried to do with their transaction PIN, as recorded in the PIN audit trail
+type PinAction string
+
+const (
+	PinChangeAction       PinAction = "change"
+	PinResetRequestAction PinAction = "reset_request"
+	PinResetAction        PinAction = "reset"
+)
+
+// MaxPinResetCodeAttempts is how many incorrect codes burn a PIN reset code, so that it has to be requested again
+const MaxPinResetCodeAttempts = 5
+
+var (
+	ErrTrivialPin          = errors.New(constants.TrivialPin)
+	ErrPinUnchanged        = errors.New(constants.PinUnchanged)
+	ErrInvalidPinResetCode = errors.New(constants.InvalidPinResetCode)
+)
+
+// PinAudit records an attempt to change or reset a transaction PIN, whether it succeeded or not
+type PinAudit struct {
+	PinAuditID uint      `gorm:"primaryKey"`
+	UserID     uint      `gorm:"index"`
+	Action     PinAction `gorm:"type:varchar(20)"`
+	Succeeded  bool
+	Reason     string `gorm:"type:varchar(255)"`
+	ClientIP   string `gorm:"type:varchar(45)"`
+	CreatedAt  time.Time
+}
+
+// PinResetCode is a one-time code sent to a user, which lets them set a new transaction PIN until it expires.
+// Only the hash of the code is stored.
+type PinResetCode struct {
+	PinResetCodeID uint   `gorm:"primaryKey"`
+	UserID         uint   `gorm:"index"`
+	CodeHash       string `gorm:"type:varchar(100)"`
+	Attempts       int
+	ExpiresAt      time.Time
+	UsedAt         *time.Time
+	TimestampData
+}
+
+// Usable reports whether the code can still be used at the time
+func (p *PinResetCode) Usable(now time.Time) bool {
+	return p.UsedAt == nil && p.Attempts < MaxPinResetCodeAttempts && now.Before(p.ExpiresAt)
+}
+
+// CheckPinStrength rejects transaction PINs that are easy to guess: a repeated digit such as 0000,
+// or a run of consecutive digits going up or down such as 1234 or 9876
+func CheckPinStrength(pin string) error {
+	if len(pin) < 2 {
+		return nil
+	}
+	repeated, ascending, descending := true, true, true
+	for i := 1; i < len(pin); i++ {
+		step := int(pin[i]) - int(pin[i-1])
+		repeated = repeated && step == 0
+		ascending = ascending && step == 1
+		descending = descending && step == -1
+	}
+	if repeated || ascending || descending {
+		return ErrTrivialPin
+	}
+	return nil
+}
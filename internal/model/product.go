@@ -0,0 +1,46 @@
+package model
+
+import "time"
+
+// DayCountConvention sets how the days an account accrues interest for are counted against a year
+type DayCountConvention string
+
+const (
+	// Actual365 counts the calendar days against a year of 365 days
+	Actual365 DayCountConvention = "ACT/365"
+	// Thirty360 counts every month as 30 days against a year of 360 days (30E/360)
+	Thirty360 DayCountConvention = "30/360"
+)
+
+func (d DayCountConvention) IsValid() bool {
+	return d == Actual365 || d == Thirty360
+}
+
+// AccountProduct is a savings product accounts are opened under. Accounts of the product accrue interest daily
+// on their balance at the annual percentage rate, and the accrued interest is capitalised monthly less
+// withholding tax at WithholdingTaxRate percent when it is set.
+type AccountProduct struct {
+	AccountProductID   uint               `gorm:"primaryKey"`
+	Code               string             `gorm:"type:varchar(30);index:idx_account_product_code;unique"`
+	Name               string             `gorm:"type:varchar(100)"`
+	AnnualRate         BigDecimal         `gorm:"type:decimal(9,6);default:0"`
+	DayCount           DayCountConvention `gorm:"type:varchar(10)"`
+	WithholdingTaxRate BigDecimal         `gorm:"type:decimal(9,6);default:0"`
+	TimestampData
+}
+
+// InterestAccrual is the interest an account accrued for one day, kept at full precision until it is capitalised.
+// An account accrues at most once a day, which the unique index on the account and the day enforces.
+type InterestAccrual struct {
+	InterestAccrualID uint               `gorm:"primaryKey"`
+	AccountID         uint               `gorm:"uniqueIndex:idx_interest_accrual_day"`
+	AccrualDate       time.Time          `gorm:"uniqueIndex:idx_interest_accrual_day"`
+	Balance           BigDecimal         `gorm:"type:decimal(20,4)"`
+	Rate              BigDecimal         `gorm:"type:decimal(9,6)"`
+	DayCount          DayCountConvention `gorm:"type:varchar(10)"`
+	Amount            BigDecimal         `gorm:"type:decimal(38,18)"`
+	// TransactionID is the credit the accrual was capitalised into, nil until it is capitalised
+	TransactionID *uint      `gorm:"index"`
+	CapitalisedAt *time.Time `gorm:"index"`
+	TimestampData
+}
@@ -0,0 +1,61 @@
+package model
+
+// Permission is an action on the bank that only some roles may take
+type Permission string
+
+const (
+	// ViewAccountsPermission lets staff read the accounts of any customer, with their status and overdraft
+	ViewAccountsPermission Permission = "accounts:view"
+	// FreezeAccountsPermission lets staff move accounts through their lifecycle, freezing and closing them
+	FreezeAccountsPermission Permission = "accounts:freeze"
+	// AdjustAccountsPermission lets staff adjust the overdraft, limits and product of an account
+	AdjustAccountsPermission Permission = "accounts:adjust"
+	// ReverseTransactionsPermission lets staff reverse transactions
+	ReverseTransactionsPermission Permission = "transactions:reverse"
+	// SettleHoldsPermission lets staff capture the holds placed on accounts, or release them
+	SettleHoldsPermission Permission = "holds:settle"
+	// ConfigureBankPermission lets staff set the exchange rates, fee rules, tier limits and account products
+	ConfigureBankPermission Permission = "bank:configure"
+	// ManageUsersPermission lets staff assign roles to users
+	ManageUsersPermission Permission = "users:manage"
+)
+
+// rolePermissions are the permissions each role grants. Customers act on their own accounts only,
+// which needs no permission.
+var rolePermissions = map[UserRole][]Permission{
+	CustomerRole: {},
+	TellerRole:   {ViewAccountsPermission},
+	AuditorRole:  {ViewAccountsPermission},
+	OpsRole: {
+		ViewAccountsPermission,
+		FreezeAccountsPermission,
+		AdjustAccountsPermission,
+		ReverseTransactionsPermission,
+		SettleHoldsPermission,
+	},
+	AdminRole: {
+		ViewAccountsPermission,
+		FreezeAccountsPermission,
+		AdjustAccountsPermission,
+		ReverseTransactionsPermission,
+		SettleHoldsPermission,
+		ConfigureBankPermission,
+		ManageUsersPermission,
+	},
+}
+
+// IsValid reports whether the role is one the bank knows
+func (r UserRole) IsValid() bool {
+	_, ok := rolePermissions[r]
+	return ok
+}
+
+// Can reports whether the role grants the permission
+func (r UserRole) Can(permission Permission) bool {
+	for _, granted := range rolePermissions[r] {
+		if granted == permission {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,45 @@
+package model
+
+import "time"
+
+type ScheduledTransferStatus string
+
+const (
+	ScheduleActive    ScheduledTransferStatus = "scheduled"
+	ScheduleExecuting ScheduledTransferStatus = "executing"
+	ScheduleExecuted  ScheduledTransferStatus = "executed"
+	ScheduleFailed    ScheduledTransferStatus = "failed"
+	ScheduleCancelled ScheduledTransferStatus = "cancelled"
+)
+
+// ScheduledTransfer is a transfer instruction the background worker executes once ExecuteAt is reached
+type ScheduledTransfer struct {
+	ScheduledTransferID uint   `gorm:"primaryKey"`
+	AccountID           uint   `gorm:"index"`
+	AccountNumber       string `gorm:"type:varchar(10)"`
+	Username            string
+	PaymentReference    string                  `gorm:"index:idx_scheduled_payment_reference;unique"`
+	Amount              BigDecimal              `gorm:"type:decimal(20,4)"`
+	Currency            string                  `gorm:"type:varchar(3)"`
+	Type                TransactionType         `gorm:"type:varchar(10)"`
+	ExecuteAt           time.Time               `gorm:"index"`
+	Status              ScheduledTransferStatus `gorm:"type:varchar(20);index"`
+	Attempts            int
+	FailureReason       string
+	LastAttemptAt       *time.Time
+	TimestampData
+}
+
+// TransferRequest builds the transfer request the scheduled transfer is executed with
+func (s *ScheduledTransfer) TransferRequest() TransactionRequestDTO {
+	return TransactionRequestDTO{
+		TransactionDataDTO: TransactionDataDTO{
+			AccountNumber: s.AccountNumber,
+			Username:      s.Username,
+			Reference:     s.PaymentReference,
+			Amount:        s.Amount,
+			Currency:      s.Currency,
+			Type:          s.Type,
+		},
+	}
+}
@@ -0,0 +1,109 @@
+package model
+
+import (
+	"fmt"
+	"time"
+)
+
+type StandingOrderStatus string
+
+const (
+	StandingOrderActive    StandingOrderStatus = "active"
+	StandingOrderExecuting StandingOrderStatus = "executing"
+	StandingOrderCompleted StandingOrderStatus = "completed"
+	StandingOrderCancelled StandingOrderStatus = "cancelled"
+	StandingOrderFailed    StandingOrderStatus = "failed"
+)
+
+// InsufficientFundsPolicy decides what happens to a run of a standing order the account cannot pay for
+type InsufficientFundsPolicy string
+
+const (
+	SkipOnInsufficientFunds  InsufficientFundsPolicy = "skip"
+	RetryOnInsufficientFunds InsufficientFundsPolicy = "retry"
+)
+
+type StandingOrderOutcome string
+
+const (
+	StandingOrderRunExecuted     StandingOrderOutcome = "executed"
+	StandingOrderRunFailed       StandingOrderOutcome = "failed"
+	StandingOrderRunSkipped      StandingOrderOutcome = "skipped"
+	StandingOrderRunRetryPlanned StandingOrderOutcome = "retry_scheduled"
+)
+
+const defaultStandingOrderRetryWait = time.Hour
+
+// StandingOrder is a recurring transfer instruction. Every occurrence is executed as a transfer with its own
+// payment reference, until EndAt is passed or MaxOccurrences have run.
+type StandingOrder struct {
+	StandingOrderID      uint   `gorm:"primaryKey"`
+	AccountID            uint   `gorm:"index"`
+	AccountNumber        string `gorm:"type:varchar(10)"`
+	Username             string
+	Reference            string          `gorm:"index:idx_standing_order_reference;unique"`
+	Amount               BigDecimal      `gorm:"type:decimal(20,4)"`
+	Currency             string          `gorm:"type:varchar(3)"`
+	Type                 TransactionType `gorm:"type:varchar(10)"`
+	Frequency            string          `gorm:"type:varchar(10)"`
+	Interval             int             `gorm:"column:repeat_interval"`
+	CronExpression       string
+	StartAt              time.Time
+	EndAt                *time.Time
+	MaxOccurrences       int
+	OnInsufficientFunds  InsufficientFundsPolicy `gorm:"type:varchar(10)"`
+	MaxRetries           int
+	RetryIntervalMinutes int
+	// Occurrences counts the runs that are finished, whatever their outcome
+	Occurrences int
+	// RetryCount counts the retries of the current occurrence
+	RetryCount int
+	// NextOccurrenceAt is when the current occurrence is due, NextRunAt is when it is next attempted.
+	// They differ while an occurrence is retried.
+	NextOccurrenceAt *time.Time
+	NextRunAt        *time.Time          `gorm:"index"`
+	Status           StandingOrderStatus `gorm:"type:varchar(20);index"`
+	// FailureReason tells why the order stopped running when it failed
+	FailureReason string
+	TimestampData
+}
+
+// StandingOrderExecution records one attempt to run an occurrence of a standing order
+type StandingOrderExecution struct {
+	ExecutionID      uint `gorm:"primaryKey"`
+	StandingOrderID  uint `gorm:"index"`
+	Occurrence       int
+	Attempt          int
+	ScheduledFor     time.Time
+	PaymentReference string
+	Outcome          StandingOrderOutcome `gorm:"type:varchar(20)"`
+	Reason           string
+	ExecutedAt       time.Time
+}
+
+// OccurrenceReference is the payment reference of the given occurrence of the standing order
+func (s *StandingOrder) OccurrenceReference(occurrence int) string {
+	return fmt.Sprintf("%s-%d", s.Reference, occurrence)
+}
+
+// RetryWait is the time between retries of an occurrence the account could not pay for
+func (s *StandingOrder) RetryWait() time.Duration {
+	if s.RetryIntervalMinutes <= 0 {
+		return defaultStandingOrderRetryWait
+	}
+	return time.Duration(s.RetryIntervalMinutes) * time.Minute
+}
+
+// TransferRequest builds the transfer request the given occurrence of the standing order is executed with
+func (s *StandingOrder) TransferRequest(occurrence int) TransactionRequestDTO {
+	return TransactionRequestDTO{
+		TransactionDataDTO: TransactionDataDTO{
+			AccountNumber: s.AccountNumber,
+			Username:      s.Username,
+			Reference:     s.OccurrenceReference(occurrence),
+			Amount:        s.Amount,
+			Currency:      s.Currency,
+			Type:          s.Type,
+		},
+	}
+}
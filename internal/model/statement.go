@@ -0,0 +1,19 @@
+package model
+
+import "time"
+
+// StatementEntry is one booking on an account statement: the net effect of a journal entry on the account,
+// together with the transaction it was posted for. Entries posted without a transaction, such as overdraft
+// interest or the refund of a failed debit, leave the transaction fields empty.
+type StatementEntry struct {
+	JournalEntryID uint
+	Reference      string
+	Description    string
+	PostedAt       time.Time
+	// Amount is positive for a net credit to the account and negative for a net debit
+	Amount               BigDecimal
+	TransactionReference string
+	PaymentReference     string
+	Type                 TransactionType
+	Status               TransactionStatus
+}
@@ -0,0 +1,60 @@
+package notify
+
+import (
+	"encoding/json"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+)
+
+// Notification is a message for a user, sent to them by whichever channel the notifier delivers through
+type Notification struct {
+	Username    string    `json:"username"`
+	Email       string    `json:"email,omitempty"`
+	PhoneNumber string    `json:"phone_number,omitempty"`
+	Subject     string    `json:"subject"`
+	Body        string    `json:"body"`
+	SentAt      time.Time `json:"sent_at"`
+}
+
+// LogNotifier writes notifications to the application log. It stands in for an SMS or email gateway
+// when running locally, and must not be used where the log is readable by anyone but the user.
+type LogNotifier struct{}
+
+func (l LogNotifier) Send(notification Notification) error {
+	slog.Info("notification", "username", notification.Username, "subject", notification.Subject,
+		"body", notification.Body)
+	return nil
+}
+
+// FileNotifier appends notifications to a file as JSON lines, standing in for an SMS or email gateway
+// when running locally or in tests that read what was sent
+type FileNotifier struct {
+	Path string
+	mu   sync.Mutex
+}
+
+// NewFileNotifier creates a notifier appending to the file at the path
+func NewFileNotifier(path string) *FileNotifier {
+	return &FileNotifier{Path: path}
+}
+
+func (f *FileNotifier) Send(notification Notification) error {
+	line, err := json.Marshal(notification)
+	if err != nil {
+		return err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	file, err := os.OpenFile(f.Path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(append(line, '\n')); err != nil {
+		_ = file.Close()
+		return err
+	}
+	return file.Close()
+}
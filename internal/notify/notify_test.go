@@ -0,0 +1,31 @@
+package notify
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_FileNotifierAppendsNotifications(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "notifications.jsonl")
+	notifier := NewFileNotifier(path)
+	require.NoError(t, notifier.Send(Notification{Username: "janedoe", Subject: "first", Body: "123456"}))
+	require.NoError(t, notifier.Send(Notification{Username: "janedoe", Subject: "second", Body: "654321"}))
+
+	file, err := os.Open(path)
+	require.NoError(t, err)
+	defer file.Close()
+	var subjects []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var notification Notification
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &notification))
+		subjects = append(subjects, notification.Subject)
+	}
+	assert.Equal(t, []string{"first", "second"}, subjects)
+}
@@ -0,0 +1,161 @@
+package recurrence
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// searchLimit bounds the search for the next run of a cron schedule that can never match, such as 30 February
+const searchLimit = 5 * 366 * 24 * time.Hour
+
+var ErrInvalidCronExpression = errors.New("cron expression is invalid")
+
+type cronField struct {
+	name     string
+	min, max int
+}
+
+var cronFields = []cronField{
+	{name: "minute", min: 0, max: 59},
+	{name: "hour", min: 0, max: 23},
+	{name: "day of month", min: 1, max: 31},
+	{name: "month", min: 1, max: 12},
+	{name: "day of week", min: 0, max: 7},
+}
+
+// CronSchedule is a parsed five-field cron expression: minute, hour, day of month, month and day of week.
+// Fields take *, single values, ranges, lists and steps such as */15 or 1-5. Day of week 0 and 7 are Sunday.
+type CronSchedule struct {
+	minutes, hours, days, months, weekdays uint64
+	// anyDay and anyWeekday record unrestricted day fields. As in cron, when both day fields are restricted
+	// a day matches if either of them does.
+	anyDay, anyWeekday bool
+}
+
+// ParseCron parses a five-field cron expression
+func ParseCron(expression string) (*CronSchedule, error) {
+	parts := strings.Fields(expression)
+	if len(parts) != len(cronFields) {
+		return nil, fmt.Errorf("%w: expected %d fields, got %d", ErrInvalidCronExpression, len(cronFields), len(parts))
+	}
+
+	sets := make([]uint64, len(cronFields))
+	for i, part := range parts {
+		set, err := parseCronField(part, cronFields[i])
+		if err != nil {
+			return nil, err
+		}
+		sets[i] = set
+	}
+
+	weekdays := sets[4]
+	if weekdays&(1<<7) != 0 {
+		weekdays |= 1
+	}
+	return &CronSchedule{
+		minutes:    sets[0],
+		hours:      sets[1],
+		days:       sets[2],
+		months:     sets[3],
+		weekdays:   weekdays,
+		anyDay:     parts[2] == "*",
+		anyWeekday: parts[4] == "*",
+	}, nil
+}
+
+// parseCronField turns one field of an expression into a bit set of the values it matches
+func parseCronField(part string, field cronField) (uint64, error) {
+	var set uint64
+	for _, item := range strings.Split(part, ",") {
+		rangePart, step := item, 1
+		if i := strings.Index(item, "/"); i >= 0 {
+			s, err := strconv.Atoi(item[i+1:])
+			if err != nil || s < 1 {
+				return 0, fmt.Errorf("%w: invalid step in %s field %q", ErrInvalidCronExpression, field.name, item)
+			}
+			rangePart, step = item[:i], s
+		}
+
+		low, high, err := parseCronRange(rangePart, field)
+		if err != nil {
+			return 0, err
+		}
+		for v := low; v <= high; v += step {
+			set |= 1 << uint(v)
+		}
+	}
+	return set, nil
+}
+
+func parseCronRange(rangePart string, field cronField) (int, int, error) {
+	if rangePart == "*" {
+		return field.min, field.max, nil
+	}
+
+	bounds := strings.SplitN(rangePart, "-", 2)
+	low, err := strconv.Atoi(bounds[0])
+	if err != nil {
+		return 0, 0, fmt.Errorf("%w: invalid %s %q", ErrInvalidCronExpression, field.name, rangePart)
+	}
+	high := low
+	if len(bounds) == 2 {
+		if high, err = strconv.Atoi(bounds[1]); err != nil {
+			return 0, 0, fmt.Errorf("%w: invalid %s %q", ErrInvalidCronExpression, field.name, rangePart)
+		}
+	}
+
+	if low < field.min || high > field.max || low > high {
+		return 0, 0, fmt.Errorf("%w: %s %q is out of range %d-%d",
+			ErrInvalidCronExpression, field.name, rangePart, field.min, field.max)
+	}
+	return low, high, nil
+}
+
+// Next returns the first minute strictly after the given time that the schedule matches,
+// in the location of the given time
+func (s *CronSchedule) Next(after time.Time) (time.Time, error) {
+	t := after.Truncate(time.Minute).Add(time.Minute)
+	limit := t.Add(searchLimit)
+
+	for t.Before(limit) {
+		if !matches(s.months, int(t.Month())) {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if !s.matchesDay(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if !matches(s.hours, t.Hour()) {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+			continue
+		}
+		if !matches(s.minutes, t.Minute()) {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("%w: no run time within %s", ErrInvalidCronExpression, searchLimit)
+}
+
+func (s *CronSchedule) matchesDay(t time.Time) bool {
+	day, weekday := matches(s.days, t.Day()), matches(s.weekdays, int(t.Weekday()))
+	switch {
+	case s.anyDay && s.anyWeekday:
+		return true
+	case s.anyDay:
+		return weekday
+	case s.anyWeekday:
+		return day
+	default:
+		return day || weekday
+	}
+}
+
+func matches(set uint64, value int) bool {
+	return set&(1<<uint(value)) != 0
+}
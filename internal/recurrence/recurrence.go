@@ -0,0 +1,123 @@
+package recurrence
+
+import (
+	"errors"
+	"time"
+)
+
+type Frequency string
+
+const (
+	Daily   Frequency = "daily"
+	Weekly  Frequency = "weekly"
+	Monthly Frequency = "monthly"
+	Cron    Frequency = "cron"
+)
+
+var (
+	ErrUnknownFrequency = errors.New("frequency must be one of daily, weekly, monthly or cron")
+	ErrInvalidInterval  = errors.New("interval must be at least 1")
+)
+
+// Rule describes when a recurring payment runs. Daily, weekly and monthly rules repeat every Interval
+// periods counted from Start; cron rules run at the times matched by Expression.
+type Rule struct {
+	Frequency  Frequency
+	Interval   int
+	Expression string
+	Start      time.Time
+}
+
+// Validate reports whether the rule can be used to calculate run times
+func (r Rule) Validate() error {
+	switch r.Frequency {
+	case Daily, Weekly, Monthly:
+		if r.Interval < 1 {
+			return ErrInvalidInterval
+		}
+		return nil
+	case Cron:
+		_, err := ParseCron(r.Expression)
+		return err
+	default:
+		return ErrUnknownFrequency
+	}
+}
+
+// First returns the first run time of the rule, which is at or after Start
+func (r Rule) First() (time.Time, error) {
+	return r.Next(r.Start.Add(-time.Nanosecond))
+}
+
+// Next returns the first run time of the rule strictly after the given time
+func (r Rule) Next(after time.Time) (time.Time, error) {
+	if err := r.Validate(); err != nil {
+		return time.Time{}, err
+	}
+
+	switch r.Frequency {
+	case Daily:
+		return r.nextByDays(after, r.Interval), nil
+	case Weekly:
+		return r.nextByDays(after, 7*r.Interval), nil
+	case Monthly:
+		return r.nextByMonths(after), nil
+	default:
+		schedule, err := ParseCron(r.Expression)
+		if err != nil {
+			return time.Time{}, err
+		}
+		return schedule.Next(after)
+	}
+}
+
+// nextByDays steps from Start in calendar days, so runs keep their time of day across daylight saving changes
+func (r Rule) nextByDays(after time.Time, days int) time.Time {
+	if after.Before(r.Start) {
+		return r.Start
+	}
+
+	step := int(after.Sub(r.Start).Hours()/24) / days
+	for {
+		next := r.Start.AddDate(0, 0, step*days)
+		if next.After(after) {
+			return next
+		}
+		step++
+	}
+}
+
+// nextByMonths steps from Start in months. Runs stay on the day of month of Start,
+// falling back to the last day of shorter months.
+func (r Rule) nextByMonths(after time.Time) time.Time {
+	if after.Before(r.Start) {
+		return r.Start
+	}
+
+	months := (after.Year()-r.Start.Year())*12 + int(after.Month()-r.Start.Month())
+	step := months / r.Interval
+	if step > 0 {
+		step--
+	}
+	for {
+		next := addMonths(r.Start, step*r.Interval)
+		if next.After(after) {
+			return next
+		}
+		step++
+	}
+}
+
+// addMonths adds months to t, clamping the day to the length of the resulting month
+func addMonths(t time.Time, months int) time.Time {
+	first := time.Date(t.Year(), t.Month()+time.Month(months), 1, t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location())
+	day := t.Day()
+	if last := daysIn(first.Year(), first.Month(), t.Location()); day > last {
+		day = last
+	}
+	return first.AddDate(0, 0, day-1)
+}
+
+func daysIn(year int, month time.Month, location *time.Location) int {
+	return time.Date(year, month+1, 0, 0, 0, 0, 0, location).Day()
+}
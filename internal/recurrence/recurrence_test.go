@@ -0,0 +1,133 @@
+package recurrence
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func date(year int, month time.Month, day, hour, minute int) time.Time {
+	return time.Date(year, month, day, hour, minute, 0, 0, time.UTC)
+}
+
+func Test_NextRunTimes(t *testing.T) {
+	testCases := []struct {
+		name     string
+		rule     Rule
+		after    time.Time
+		expected time.Time
+	}{
+		{
+			name:     "daily before start runs at start",
+			rule:     Rule{Frequency: Daily, Interval: 1, Start: date(2024, 3, 10, 9, 0)},
+			after:    date(2024, 3, 1, 0, 0),
+			expected: date(2024, 3, 10, 9, 0),
+		},
+		{
+			name:     "every second day",
+			rule:     Rule{Frequency: Daily, Interval: 2, Start: date(2024, 3, 10, 9, 0)},
+			after:    date(2024, 3, 10, 9, 0),
+			expected: date(2024, 3, 12, 9, 0),
+		},
+		{
+			name:     "weekly keeps the weekday",
+			rule:     Rule{Frequency: Weekly, Interval: 1, Start: date(2024, 3, 4, 8, 30)},
+			after:    date(2024, 3, 20, 12, 0),
+			expected: date(2024, 3, 25, 8, 30),
+		},
+		{
+			name:     "monthly on the 1st",
+			rule:     Rule{Frequency: Monthly, Interval: 1, Start: date(2024, 1, 1, 6, 0)},
+			after:    date(2024, 5, 1, 6, 0),
+			expected: date(2024, 6, 1, 6, 0),
+		},
+		{
+			name:     "monthly on the 31st falls back to the end of shorter months",
+			rule:     Rule{Frequency: Monthly, Interval: 1, Start: date(2024, 1, 31, 6, 0)},
+			after:    date(2024, 1, 31, 6, 0),
+			expected: date(2024, 2, 29, 6, 0),
+		},
+		{
+			name:     "monthly on the 31st returns to the 31st",
+			rule:     Rule{Frequency: Monthly, Interval: 1, Start: date(2024, 1, 31, 6, 0)},
+			after:    date(2024, 2, 29, 6, 0),
+			expected: date(2024, 3, 31, 6, 0),
+		},
+		{
+			name:     "quarterly across a year end",
+			rule:     Rule{Frequency: Monthly, Interval: 3, Start: date(2024, 2, 15, 0, 0)},
+			after:    date(2024, 11, 20, 0, 0),
+			expected: date(2025, 2, 15, 0, 0),
+		},
+		{
+			name:     "cron on weekdays at 9",
+			rule:     Rule{Frequency: Cron, Expression: "0 9 * * 1-5"},
+			after:    date(2024, 3, 8, 9, 0),
+			expected: date(2024, 3, 11, 9, 0),
+		},
+		{
+			name:     "cron every 15 minutes",
+			rule:     Rule{Frequency: Cron, Expression: "*/15 * * * *"},
+			after:    date(2024, 3, 8, 9, 7),
+			expected: date(2024, 3, 8, 9, 15),
+		},
+		{
+			name:     "cron on the 1st of the month",
+			rule:     Rule{Frequency: Cron, Expression: "30 6 1 * *"},
+			after:    date(2024, 12, 1, 6, 30),
+			expected: date(2025, 1, 1, 6, 30),
+		},
+		{
+			name:     "cron day of month or day of week",
+			rule:     Rule{Frequency: Cron, Expression: "0 0 15 * 0"},
+			after:    date(2024, 3, 11, 0, 0),
+			expected: date(2024, 3, 15, 0, 0),
+		},
+		{
+			name:     "cron Sunday written as 7",
+			rule:     Rule{Frequency: Cron, Expression: "0 12 * * 7"},
+			after:    date(2024, 3, 11, 0, 0),
+			expected: date(2024, 3, 17, 12, 0),
+		},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			next, err := tt.rule.Next(tt.after)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, next)
+		})
+	}
+}
+
+func Test_FirstRunIsAtOrAfterStart(t *testing.T) {
+	first, err := Rule{Frequency: Monthly, Interval: 1, Start: date(2024, 3, 1, 0, 0)}.First()
+	require.NoError(t, err)
+	assert.Equal(t, date(2024, 3, 1, 0, 0), first)
+
+	first, err = Rule{Frequency: Cron, Expression: "0 0 * * *", Start: date(2024, 3, 1, 0, 0)}.First()
+	require.NoError(t, err)
+	assert.Equal(t, date(2024, 3, 1, 0, 0), first)
+}
+
+func Test_InvalidRules(t *testing.T) {
+	testCases := []struct {
+		name     string
+		rule     Rule
+		expected error
+	}{
+		{name: "unknown frequency", rule: Rule{Frequency: "yearly", Interval: 1}, expected: ErrUnknownFrequency},
+		{name: "zero interval", rule: Rule{Frequency: Daily}, expected: ErrInvalidInterval},
+		{name: "too few cron fields", rule: Rule{Frequency: Cron, Expression: "0 9 * *"}, expected: ErrInvalidCronExpression},
+		{name: "cron value out of range", rule: Rule{Frequency: Cron, Expression: "0 24 * * *"}, expected: ErrInvalidCronExpression},
+		{name: "cron zero step", rule: Rule{Frequency: Cron, Expression: "*/0 * * * *"}, expected: ErrInvalidCronExpression},
+		{name: "cron that never runs", rule: Rule{Frequency: Cron, Expression: "0 0 30 2 *"}, expected: ErrInvalidCronExpression},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.rule.Next(date(2024, 1, 1, 0, 0))
+			assert.ErrorIs(t, err, tt.expected)
+		})
+	}
+}
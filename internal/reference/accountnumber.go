@@ -0,0 +1,57 @@
+package reference
+
+import (
+	"crypto/rand"
+	"io"
+	"math/big"
+	"strconv"
+)
+
+// AccountNumberLength is the number of digits of an account number, the last of which is a check digit
+const AccountNumberLength = 10
+
+// accountSerials is the number of serials an account number can carry: nine digits that do not start with zero
+var accountSerials = big.NewInt(900_000_000)
+
+// IAccountNumberGenerator produces the numbers of newly opened accounts
+type IAccountNumberGenerator interface {
+	NewAccountNumber() (string, error)
+}
+
+// AccountNumberGenerator draws a random nine-digit serial and appends its Luhn check digit, so that a mistyped
+// digit in an account number can be caught. Numbers are not guaranteed to be unique, the caller draws another
+// number when the one it drew is already taken.
+type AccountNumberGenerator struct {
+	random io.Reader
+}
+
+// NewAccountNumberGenerator creates a new instance of AccountNumberGenerator
+func NewAccountNumberGenerator() *AccountNumberGenerator {
+	return &AccountNumberGenerator{random: rand.Reader}
+}
+
+func (a *AccountNumberGenerator) NewAccountNumber() (string, error) {
+	serial, err := rand.Int(a.random, accountSerials)
+	if err != nil {
+		return "", err
+	}
+	payload := strconv.FormatInt(serial.Int64()+100_000_000, 10)
+	return payload + string(luhnCheckDigit(payload)), nil
+}
+
+// luhnCheckDigit returns the digit that makes the payload followed by it pass the Luhn check
+func luhnCheckDigit(payload string) byte {
+	sum := 0
+	for i := len(payload) - 1; i >= 0; i-- {
+		digit := int(payload[i] - '0')
+		// the digits doubled are every other one, starting from the one next to the check digit
+		if (len(payload)-i)%2 == 1 {
+			digit *= 2
+			if digit > 9 {
+				digit -= 9
+			}
+		}
+		sum += digit
+	}
+	return byte('0' + (10-sum%10)%10)
+}
@@ -0,0 +1,32 @@
+package reference
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_LuhnCheckDigit(t *testing.T) {
+	// 7992739871 is the worked example of the Luhn algorithm, with a check digit of 3
+	assert.Equal(t, byte('3'), luhnCheckDigit("7992739871"))
+	assert.Equal(t, byte('0'), luhnCheckDigit("000000000"))
+}
+
+func Test_AccountNumbersHaveTenDigitsEndingWithTheirCheckDigit(t *testing.T) {
+	generator := NewAccountNumberGenerator()
+	for i := 0; i < 100; i++ {
+		number, err := generator.NewAccountNumber()
+		require.NoError(t, err)
+		require.Len(t, number, AccountNumberLength)
+		assert.NotEqual(t, byte('0'), number[0])
+		assert.Equal(t, luhnCheckDigit(number[:AccountNumberLength-1]), number[AccountNumberLength-1])
+	}
+}
+
+func Test_AccountNumberGeneratorFailsWithoutEntropy(t *testing.T) {
+	generator := &AccountNumberGenerator{random: bytes.NewReader(nil)}
+	_, err := generator.NewAccountNumber()
+	assert.Error(t, err)
+}
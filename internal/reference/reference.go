@@ -0,0 +1,200 @@
+package reference
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	SnowflakeFormat = "snowflake"
+	ULIDFormat      = "ulid"
+)
+
+// IGenerator produces unique, time-ordered transaction references
+type IGenerator interface {
+	NewReference() (string, error)
+}
+
+// NewGenerator creates the generator for the configured format, prefixing references when a prefix is set
+func NewGenerator(format string, nodeID int64, prefix string) (IGenerator, error) {
+	var generator IGenerator
+	switch strings.ToLower(format) {
+	case SnowflakeFormat, "":
+		snowflake, err := NewSnowflakeGenerator(nodeID)
+		if err != nil {
+			return nil, err
+		}
+		generator = snowflake
+	case ULIDFormat:
+		generator = NewULIDGenerator()
+	default:
+		return nil, fmt.Errorf("unknown reference format %q", format)
+	}
+
+	if prefix != "" {
+		return &PrefixedGenerator{Prefix: prefix, Generator: generator}, nil
+	}
+	return generator, nil
+}
+
+// PrefixedGenerator prepends a fixed prefix, such as the bank code, to the references of another generator
+type PrefixedGenerator struct {
+	Prefix    string
+	Generator IGenerator
+}
+
+func (p *PrefixedGenerator) NewReference() (string, error) {
+	ref, err := p.Generator.NewReference()
+	if err != nil {
+		return "", err
+	}
+	return p.Prefix + ref, nil
+}
+
+const (
+	nodeBits     = 10
+	sequenceBits = 12
+	maxNodeID    = 1<<nodeBits - 1
+	maxSequence  = 1<<sequenceBits - 1
+)
+
+// snowflakeEpoch is the custom epoch of snowflake IDs, leaving room for ~69 years of milliseconds
+var snowflakeEpoch = time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+var ErrInvalidNodeID = fmt.Errorf("reference node ID must be between 0 and %d", maxNodeID)
+
+// SnowflakeGenerator generates 63-bit IDs made of a millisecond timestamp, a node ID and a per-millisecond
+// sequence. IDs are unique across nodes as long as every running instance is configured with its own node ID.
+type SnowflakeGenerator struct {
+	mu            sync.Mutex
+	nodeID        int64
+	lastTimestamp int64
+	sequence      int64
+	now           func() time.Time
+}
+
+// NewSnowflakeGenerator creates a new instance of SnowflakeGenerator for the given node
+func NewSnowflakeGenerator(nodeID int64) (*SnowflakeGenerator, error) {
+	if nodeID < 0 || nodeID > maxNodeID {
+		return nil, ErrInvalidNodeID
+	}
+	return &SnowflakeGenerator{nodeID: nodeID, now: time.Now}, nil
+}
+
+func (s *SnowflakeGenerator) NewReference() (string, error) {
+	id, err := s.NextID()
+	if err != nil {
+		return "", err
+	}
+	return strconv.FormatInt(id, 10), nil
+}
+
+// NextID returns the next snowflake ID, waiting for the next millisecond when the sequence is exhausted
+// or the clock moved backwards
+func (s *SnowflakeGenerator) NextID() (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	timestamp := s.now().Sub(snowflakeEpoch).Milliseconds()
+	if timestamp < 0 {
+		return 0, errors.New("system clock is before the reference epoch")
+	}
+
+	for timestamp < s.lastTimestamp {
+		time.Sleep(time.Duration(s.lastTimestamp-timestamp) * time.Millisecond)
+		timestamp = s.now().Sub(snowflakeEpoch).Milliseconds()
+	}
+
+	if timestamp == s.lastTimestamp {
+		s.sequence = (s.sequence + 1) & maxSequence
+		if s.sequence == 0 {
+			for timestamp <= s.lastTimestamp {
+				timestamp = s.now().Sub(snowflakeEpoch).Milliseconds()
+			}
+		}
+	} else {
+		s.sequence = 0
+	}
+
+	s.lastTimestamp = timestamp
+	return timestamp<<(nodeBits+sequenceBits) | s.nodeID<<sequenceBits | s.sequence, nil
+}
+
+const crockfordAlphabet = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// ULIDGenerator generates lexicographically sortable 26 character ULIDs. IDs created within the same
+// millisecond increment the random part, so they stay strictly ordered on a single instance.
+type ULIDGenerator struct {
+	mu            sync.Mutex
+	lastTimestamp uint64
+	entropy       [10]byte
+	random        io.Reader
+	now           func() time.Time
+}
+
+// NewULIDGenerator creates a new instance of ULIDGenerator
+func NewULIDGenerator() *ULIDGenerator {
+	return &ULIDGenerator{random: rand.Reader, now: time.Now}
+}
+
+func (u *ULIDGenerator) NewReference() (string, error) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	timestamp := uint64(u.now().UnixMilli())
+	if timestamp > u.lastTimestamp {
+		if _, err := io.ReadFull(u.random, u.entropy[:]); err != nil {
+			return "", err
+		}
+		u.lastTimestamp = timestamp
+	} else if !increment(u.entropy[:]) {
+		return "", errors.New("ULID entropy exhausted within one millisecond")
+	}
+
+	var id [16]byte
+	for i := 0; i < 6; i++ {
+		id[i] = byte(u.lastTimestamp >> (40 - 8*i))
+	}
+	copy(id[6:], u.entropy[:])
+	return encodeCrockford(id), nil
+}
+
+// increment adds one to the big-endian number, returning false on overflow
+func increment(b []byte) bool {
+	for i := len(b) - 1; i >= 0; i-- {
+		b[i]++
+		if b[i] != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// encodeCrockford encodes the 128-bit ID as 26 Crockford base32 characters
+func encodeCrockford(id [16]byte) string {
+	var out [26]byte
+	// 130 bits of output for 128 bits of input: the first character carries only the top 3 bits
+	var bits uint
+	var buffer uint32
+	index := 0
+	out[index] = crockfordAlphabet[id[0]>>5]
+	index++
+	buffer = uint32(id[0] & 0x1f)
+	bits = 5
+	for _, b := range id[1:] {
+		buffer = buffer<<8 | uint32(b)
+		bits += 8
+		for bits >= 5 {
+			bits -= 5
+			out[index] = crockfordAlphabet[(buffer>>bits)&0x1f]
+			index++
+		}
+	}
+	return string(out[:])
+}
@@ -0,0 +1,127 @@
+package reference
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func generateConcurrently(t *testing.T, generator IGenerator, workers, perWorker int) []string {
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	references := make([]string, 0, workers*perWorker)
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < perWorker; i++ {
+				ref, err := generator.NewReference()
+				assert.NoError(t, err)
+				mu.Lock()
+				references = append(references, ref)
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	return references
+}
+
+func assertUnique(t *testing.T, references []string) {
+	seen := make(map[string]bool, len(references))
+	for _, ref := range references {
+		require.False(t, seen[ref], "duplicate reference %s", ref)
+		seen[ref] = true
+	}
+}
+
+func Test_SnowflakeReferencesAreUniqueUnderConcurrency(t *testing.T) {
+	generator, err := NewSnowflakeGenerator(7)
+	require.NoError(t, err)
+	assertUnique(t, generateConcurrently(t, generator, 16, 2000))
+}
+
+func Test_SnowflakeIDsIncreaseAndCarryNodeID(t *testing.T) {
+	generator, _ := NewSnowflakeGenerator(42)
+	previous := int64(0)
+	for i := 0; i < 10_000; i++ {
+		id, err := generator.NextID()
+		require.NoError(t, err)
+		assert.Greater(t, id, previous)
+		assert.Equal(t, int64(42), (id>>sequenceBits)&maxNodeID)
+		previous = id
+	}
+}
+
+func Test_SnowflakeWaitsWhenClockMovesBackwards(t *testing.T) {
+	generator, _ := NewSnowflakeGenerator(1)
+	current := time.Now()
+	generator.now = func() time.Time { return current }
+	first, _ := generator.NextID()
+
+	calls := 0
+	generator.now = func() time.Time {
+		calls++
+		if calls == 1 {
+			return current.Add(-time.Millisecond)
+		}
+		return current.Add(time.Millisecond)
+	}
+	second, err := generator.NextID()
+	assert.NoError(t, err)
+	assert.Greater(t, second, first)
+}
+
+func Test_SnowflakeRejectsInvalidNodeID(t *testing.T) {
+	_, err := NewSnowflakeGenerator(maxNodeID + 1)
+	assert.Equal(t, ErrInvalidNodeID, err)
+	_, err = NewSnowflakeGenerator(-1)
+	assert.Equal(t, ErrInvalidNodeID, err)
+}
+
+func Test_ULIDReferencesAreUniqueAndOrdered(t *testing.T) {
+	generator := NewULIDGenerator()
+	var references []string
+	for i := 0; i < 10_000; i++ {
+		ref, err := generator.NewReference()
+		require.NoError(t, err)
+		assert.Len(t, ref, 26)
+		references = append(references, ref)
+	}
+	assert.True(t, sort.StringsAreSorted(references))
+	assertUnique(t, references)
+	assertUnique(t, generateConcurrently(t, generator, 16, 1000))
+}
+
+func Test_ULIDEncodesTimestamp(t *testing.T) {
+	generator := NewULIDGenerator()
+	generator.now = func() time.Time { return time.UnixMilli(0) }
+	ref, err := generator.NewReference()
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(ref, "0000000000"))
+}
+
+func Test_NewGenerator(t *testing.T) {
+	generator, err := NewGenerator("snowflake", 1, "")
+	require.NoError(t, err)
+	assert.IsType(t, &SnowflakeGenerator{}, generator)
+
+	generator, err = NewGenerator("ULID", 1, "")
+	require.NoError(t, err)
+	assert.IsType(t, &ULIDGenerator{}, generator)
+
+	generator, err = NewGenerator("ulid", 1, "058")
+	require.NoError(t, err)
+	ref, err := generator.NewReference()
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(ref, "058"))
+	assert.Len(t, ref, 29)
+
+	_, err = NewGenerator("uuid", 1, "")
+	assert.Error(t, err)
+}
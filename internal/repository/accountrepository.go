@@ -2,8 +2,6 @@ package repository
 
 import (
 	"bankingApp/internal/model"
-	"log/slog"
-	"time"
 
 	"gorm.io/gorm"
 )
@@ -19,34 +17,6 @@ func NewAccountRepository(db *gorm.DB) *AccountRepository {
 	}
 }
 
-// UpdateAccount updates an account in the database within a transaction
-func (a AccountRepository) UpdateAccount(account *model.Account) error {
-	tx := a.db.Begin()
-	defer func() {
-		if r := recover(); r != nil {
-			tx.Rollback()
-		}
-	}()
-
-	if err := tx.Model(&model.Account{}).Where(model.Account{AccountID: account.AccountID}).
-		UpdateColumns(map[string]interface{}{
-			"balance":    account.Balance,
-			"created_at": time.Now(),
-		}).Error; err != nil {
-		slog.Error(err.Error())
-		tx.Rollback()
-		return err
-	}
-
-	if err := tx.Commit().Error; err != nil {
-		slog.Error(err.Error())
-		tx.Rollback()
-		return err
-	}
-
-	return nil
-}
-
 // GetAccountByAccountNumber fetch user account details by account number
 func (a AccountRepository) GetAccountByAccountNumber(number string) (*model.Account, error) {
 	var account model.Account
@@ -56,3 +26,22 @@ func (a AccountRepository) GetAccountByAccountNumber(number string) (*model.Acco
 		Error
 	return &account, err
 }
+
+// AssignDefaultCurrency sets the currency of the accounts opened before accounts carried one
+func (a AccountRepository) AssignDefaultCurrency(code string) error {
+	return a.db.Model(&model.Account{}).
+		Where("currency IS NULL OR currency = ''").
+		UpdateColumn("currency", code).
+		Error
+}
+
+// FindAccountStatusChanges lists the changes made to the status of an account, latest first
+func (a AccountRepository) FindAccountStatusChanges(accountID uint) ([]model.AccountStatusChange, error) {
+	var changes []model.AccountStatusChange
+	err := a.db.
+		Where(&model.AccountStatusChange{AccountID: accountID}).
+		Order("changed_at DESC, account_status_change_id DESC").
+		Find(&changes).
+		Error
+	return changes, err
+}
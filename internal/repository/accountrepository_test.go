@@ -0,0 +1,69 @@
+package repository
+
+import (
+	"bankingApp/internal/model"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_AssignDefaultCurrencyOnlyFillsAccountsWithoutOne(t *testing.T) {
+	db := openTestDB(t)
+	legacy := createTestAccount(t, db, "1234567890", "10.00")
+	euro := &model.Account{UserID: 1, AccountNumber: "0987654321", Currency: "EUR"}
+	require.NoError(t, db.Create(euro).Error)
+
+	require.NoError(t, NewAccountRepository(db).AssignDefaultCurrency("NGN"))
+
+	var accounts []model.Account
+	require.NoError(t, db.Order("account_id").Find(&accounts).Error)
+	assert.Equal(t, legacy.AccountID, accounts[0].AccountID)
+	assert.Equal(t, "NGN", accounts[0].Currency)
+	assert.Equal(t, "EUR", accounts[1].Currency)
+}
+
+func Test_AccountStatusChangesAreStoredWithTheirAuditTrail(t *testing.T) {
+	db := openTestDB(t)
+	created := createTestAccount(t, db, "1234567890", "0")
+	repository := NewAccountRepository(db)
+	uow := NewUnitOfWork(db)
+	now := time.Now()
+
+	account, err := repository.GetAccountByAccountNumber("1234567890")
+	require.NoError(t, err)
+	assert.Equal(t, model.ActiveAccount, account.Status)
+
+	changeStatus := func(status model.AccountStatus, reasonCode model.StatusReasonCode, at time.Time) error {
+		return uow.Execute(func(tx ITx) error {
+			locked, err := tx.LockAccount(created.AccountID)
+			if err != nil {
+				return err
+			}
+			change, err := locked.ChangeStatus(status, reasonCode, "ops.user", "", at)
+			if err != nil {
+				return err
+			}
+			if err := tx.UpdateAccountStatus(locked); err != nil {
+				return err
+			}
+			return tx.SaveAccountStatusChange(change)
+		})
+	}
+	require.NoError(t, changeStatus(model.FrozenAccount, model.SuspectedFraudReason, now.Add(-time.Hour)))
+	require.NoError(t, changeStatus(model.ClosedAccount, model.CustomerRequestReason, now))
+	assert.ErrorIs(t, changeStatus(model.ActiveAccount, model.ResolvedReason, now), model.ErrAccountClosed)
+
+	account, err = repository.GetAccountByAccountNumber("1234567890")
+	require.NoError(t, err)
+	assert.Equal(t, model.ClosedAccount, account.Status)
+
+	changes, err := repository.FindAccountStatusChanges(created.AccountID)
+	require.NoError(t, err)
+	require.Len(t, changes, 2)
+	assert.Equal(t, model.FrozenAccount, changes[0].PreviousStatus)
+	assert.Equal(t, model.ClosedAccount, changes[0].Status)
+	assert.Equal(t, model.ActiveAccount, changes[1].PreviousStatus)
+	assert.Equal(t, model.SuspectedFraudReason, changes[1].ReasonCode)
+}
@@ -0,0 +1,162 @@
+package repository
+
+import (
+	"bankingApp/internal/model"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type BulkTransferRepository struct {
+	db *gorm.DB
+}
+
+// NewBulkTransferRepository creates a new instance of BulkTransferRepository
+func NewBulkTransferRepository(db *gorm.DB) *BulkTransferRepository {
+	return &BulkTransferRepository{db: db}
+}
+
+// CreateBulkTransferBatch inserts a batch together with its rows
+func (b *BulkTransferRepository) CreateBulkTransferBatch(batch *model.BulkTransferBatch, rows []*model.BulkTransferRow) error {
+	return b.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(batch).Error; err != nil {
+			return err
+		}
+		for _, row := range rows {
+			row.BatchID = batch.BatchID
+		}
+		if len(rows) == 0 {
+			return nil
+		}
+		return tx.CreateInBatches(rows, 100).Error
+	})
+}
+
+// FindBulkTransferBatch retrieves a batch by ID, returning an empty one when it does not exist
+func (b *BulkTransferRepository) FindBulkTransferBatch(id uint) (*model.BulkTransferBatch, error) {
+	var batch model.BulkTransferBatch
+	err := b.db.
+		Where(&model.BulkTransferBatch{BatchID: id}).
+		Find(&batch).
+		Error
+	return &batch, err
+}
+
+// FindBulkTransferRows lists the rows of a batch in file order
+func (b *BulkTransferRepository) FindBulkTransferRows(batchID uint) ([]model.BulkTransferRow, error) {
+	var rows []model.BulkTransferRow
+	err := b.db.
+		Where(&model.BulkTransferRow{BatchID: batchID}).
+		Order("line_number").
+		Find(&rows).
+		Error
+	return rows, err
+}
+
+// ClaimPendingBulkTransferBatches moves up to limit pending batches to processing and returns them, oldest first.
+// Each one is claimed with a conditional update, so running several workers never processes a batch twice.
+func (b *BulkTransferRepository) ClaimPendingBulkTransferBatches(limit int) ([]model.BulkTransferBatch, error) {
+	var pending []model.BulkTransferBatch
+	err := b.db.
+		Where(&model.BulkTransferBatch{Status: model.BatchPending}).
+		Order("batch_id").
+		Limit(limit).
+		Find(&pending).
+		Error
+	if err != nil {
+		return nil, err
+	}
+
+	claimed := make([]model.BulkTransferBatch, 0, len(pending))
+	for _, batch := range pending {
+		result := b.db.Model(&model.BulkTransferBatch{}).
+			Where("batch_id = ? AND status = ?", batch.BatchID, model.BatchPending).
+			UpdateColumns(map[string]interface{}{
+				"status":     model.BatchProcessing,
+				"updated_at": time.Now(),
+			})
+		if result.Error != nil {
+			return claimed, result.Error
+		}
+		if result.RowsAffected == 1 {
+			batch.Status = model.BatchProcessing
+			claimed = append(claimed, batch)
+		}
+	}
+	return claimed, nil
+}
+
+// ClaimStalledBulkTransferBatches takes over up to limit batches left processing by a worker that stopped, oldest
+// first. A batch is stalled when none of its rows has finished since stalledBefore. Claiming one moves its
+// updated_at on with a conditional update, so running several workers never resumes a batch twice.
+func (b *BulkTransferRepository) ClaimStalledBulkTransferBatches(stalledBefore time.Time, limit int) ([]model.BulkTransferBatch, error) {
+	var stalled []model.BulkTransferBatch
+	err := b.db.
+		Where("status = ? AND updated_at < ?", model.BatchProcessing, stalledBefore).
+		Order("batch_id").
+		Limit(limit).
+		Find(&stalled).
+		Error
+	if err != nil {
+		return nil, err
+	}
+
+	claimed := make([]model.BulkTransferBatch, 0, len(stalled))
+	for _, batch := range stalled {
+		result := b.db.Model(&model.BulkTransferBatch{}).
+			Where("batch_id = ? AND status = ? AND updated_at < ?", batch.BatchID, model.BatchProcessing, stalledBefore).
+			UpdateColumn("updated_at", time.Now())
+		if result.Error != nil {
+			return claimed, result.Error
+		}
+		if result.RowsAffected == 1 {
+			claimed = append(claimed, batch)
+		}
+	}
+	return claimed, nil
+}
+
+// RecordBulkTransferRow stores the result of a row and counts it in the progress of its batch in the same commit
+func (b *BulkTransferRepository) RecordBulkTransferRow(row *model.BulkTransferRow) error {
+	counter := map[model.BulkTransferRowStatus]string{
+		model.RowSucceeded: "succeeded_rows",
+		model.RowFailed:    "failed_rows",
+		model.RowUnknown:   "unknown_rows",
+	}[row.Status]
+
+	return b.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&model.BulkTransferRow{}).
+			Where(&model.BulkTransferRow{RowID: row.RowID}).
+			UpdateColumns(map[string]interface{}{
+				"status":       row.Status,
+				"message":      row.Message,
+				"processed_at": row.ProcessedAt,
+			}).Error
+		if err != nil {
+			return err
+		}
+
+		columns := map[string]interface{}{
+			"processed_rows": gorm.Expr("processed_rows + 1"),
+			"updated_at":     time.Now(),
+		}
+		if counter != "" {
+			columns[counter] = gorm.Expr(counter + " + 1")
+		}
+		return tx.Model(&model.BulkTransferBatch{}).
+			Where(&model.BulkTransferBatch{BatchID: row.BatchID}).
+			UpdateColumns(columns).Error
+	})
+}
+
+// CompleteBulkTransferBatch marks a batch as completed once all its rows have been processed
+func (b *BulkTransferRepository) CompleteBulkTransferBatch(batchID uint) error {
+	now := time.Now()
+	return b.db.Model(&model.BulkTransferBatch{}).
+		Where(&model.BulkTransferBatch{BatchID: batchID}).
+		UpdateColumns(map[string]interface{}{
+			"status":       model.BatchCompleted,
+			"completed_at": &now,
+			"updated_at":   now,
+		}).Error
+}
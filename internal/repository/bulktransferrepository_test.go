@@ -0,0 +1,104 @@
+package repository
+
+import (
+	"bankingApp/internal/model"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/govalues/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func createBulkTransferBatch(t *testing.T, repository *BulkTransferRepository, rowCount int) *model.BulkTransferBatch {
+	batch := &model.BulkTransferBatch{Source: "csv", Status: model.BatchPending, TotalRows: rowCount}
+	rows := make([]*model.BulkTransferRow, 0, rowCount)
+	for i := 1; i <= rowCount; i++ {
+		rows = append(rows, &model.BulkTransferRow{
+			RowNumber:        i,
+			AccountNumber:    "1234567890",
+			PaymentReference: fmt.Sprintf("payroll%d", i),
+			Amount:           model.BigDecimal{Decimal: decimal.MustParse("10.00")},
+			Type:             model.DebitTransaction,
+			Status:           model.RowPending,
+		})
+	}
+	require.NoError(t, repository.CreateBulkTransferBatch(batch, rows))
+	return batch
+}
+
+func Test_ClaimPendingBulkTransferBatchesClaimsEachBatchOnce(t *testing.T) {
+	repository := NewBulkTransferRepository(openTestDB(t))
+	first := createBulkTransferBatch(t, repository, 1)
+	second := createBulkTransferBatch(t, repository, 1)
+
+	claimed, err := repository.ClaimPendingBulkTransferBatches(10)
+	require.NoError(t, err)
+	require.Len(t, claimed, 2)
+	assert.Equal(t, first.BatchID, claimed[0].BatchID)
+	assert.Equal(t, second.BatchID, claimed[1].BatchID)
+	assert.Equal(t, model.BatchProcessing, claimed[0].Status)
+
+	claimed, err = repository.ClaimPendingBulkTransferBatches(10)
+	require.NoError(t, err)
+	assert.Empty(t, claimed)
+}
+
+func Test_RecordBulkTransferRowCountsTheProgressOfTheBatch(t *testing.T) {
+	repository := NewBulkTransferRepository(openTestDB(t))
+	batch := createBulkTransferBatch(t, repository, 3)
+
+	rows, err := repository.FindBulkTransferRows(batch.BatchID)
+	require.NoError(t, err)
+	require.Len(t, rows, 3)
+	assert.Equal(t, []int{1, 2, 3}, []int{rows[0].RowNumber, rows[1].RowNumber, rows[2].RowNumber})
+
+	rows[0].Status = model.RowSucceeded
+	rows[1].Status, rows[1].Message = model.RowFailed, "insufficient funds"
+	require.NoError(t, repository.RecordBulkTransferRow(&rows[0]))
+	require.NoError(t, repository.RecordBulkTransferRow(&rows[1]))
+
+	stored, err := repository.FindBulkTransferBatch(batch.BatchID)
+	require.NoError(t, err)
+	assert.Equal(t, 2, stored.ProcessedRows)
+	assert.Equal(t, 1, stored.SucceededRows)
+	assert.Equal(t, 1, stored.FailedRows)
+	assert.Equal(t, model.BatchPending, stored.Status)
+
+	require.NoError(t, repository.CompleteBulkTransferBatch(batch.BatchID))
+	stored, err = repository.FindBulkTransferBatch(batch.BatchID)
+	require.NoError(t, err)
+	assert.Equal(t, model.BatchCompleted, stored.Status)
+	assert.NotNil(t, stored.CompletedAt)
+
+	rows, err = repository.FindBulkTransferRows(batch.BatchID)
+	require.NoError(t, err)
+	assert.Equal(t, "insufficient funds", rows[1].Message)
+	assert.Equal(t, model.RowPending, rows[2].Status)
+}
+
+func Test_ClaimStalledBulkTransferBatchesResumesEachBatchOnce(t *testing.T) {
+	db := openTestDB(t)
+	repository := NewBulkTransferRepository(db)
+	stalled := createBulkTransferBatch(t, repository, 1)
+	running := createBulkTransferBatch(t, repository, 1)
+	createBulkTransferBatch(t, repository, 1)
+	_, err := repository.ClaimPendingBulkTransferBatches(2)
+	require.NoError(t, err)
+
+	stoppedAt := time.Now().Add(-time.Hour)
+	require.NoError(t, db.Model(&model.BulkTransferBatch{}).
+		Where("batch_id = ?", stalled.BatchID).
+		UpdateColumn("updated_at", stoppedAt).Error)
+
+	claimed, err := repository.ClaimStalledBulkTransferBatches(time.Now().Add(-time.Minute), 10)
+	require.NoError(t, err)
+	require.Len(t, claimed, 1)
+	assert.Equal(t, stalled.BatchID, claimed[0].BatchID)
+	assert.NotEqual(t, running.BatchID, claimed[0].BatchID)
+
+	claimed, err = repository.ClaimStalledBulkTransferBatches(time.Now().Add(-time.Minute), 10)
+	require.NoError(t, err)
+	assert.Empty(t, claimed)
+}
@@ -0,0 +1,50 @@
+package repository
+
+import (
+	"bankingApp/internal/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ExchangeRateRepository struct {
+	db *gorm.DB
+}
+
+// NewExchangeRateRepository creates a new instance of ExchangeRateRepository
+func NewExchangeRateRepository(db *gorm.DB) *ExchangeRateRepository {
+	return &ExchangeRateRepository{db: db}
+}
+
+// FindExchangeRate retrieves the rate of a currency pair, returning an empty one when the pair has no rate
+func (e *ExchangeRateRepository) FindExchangeRate(base, quote string) (*model.ExchangeRate, error) {
+	var rate model.ExchangeRate
+	err := e.db.
+		Where(&model.ExchangeRate{BaseCurrency: base, QuoteCurrency: quote}).
+		Find(&rate).
+		Error
+	return &rate, err
+}
+
+// FindExchangeRates lists the rates of every currency pair
+func (e *ExchangeRateRepository) FindExchangeRates() ([]model.ExchangeRate, error) {
+	var rates []model.ExchangeRate
+	err := e.db.
+		Order("base_currency, quote_currency").
+		Find(&rates).
+		Error
+	return rates, err
+}
+
+// SaveExchangeRates stores the rates in one commit, replacing the rate of every pair that already has one
+func (e *ExchangeRateRepository) SaveExchangeRates(rates []model.ExchangeRate) error {
+	if len(rates) == 0 {
+		return nil
+	}
+	return e.db.Transaction(func(tx *gorm.DB) error {
+		return tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "base_currency"}, {Name: "quote_currency"}},
+			DoUpdates: clause.AssignmentColumns([]string{"rate", "source", "updated_at"}),
+		}).Create(&rates).Error
+	})
+}
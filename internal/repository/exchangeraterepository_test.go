@@ -0,0 +1,46 @@
+package repository
+
+import (
+	"bankingApp/internal/model"
+	"testing"
+
+	"github.com/govalues/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func exchangeRate(base, quote, rate, source string) model.ExchangeRate {
+	return model.ExchangeRate{
+		BaseCurrency:  base,
+		QuoteCurrency: quote,
+		Rate:          model.BigDecimal{Decimal: decimal.MustParse(rate)},
+		Source:        source,
+	}
+}
+
+func Test_SaveExchangeRatesReplacesTheRateOfAPair(t *testing.T) {
+	repository := NewExchangeRateRepository(openTestDB(t))
+
+	require.NoError(t, repository.SaveExchangeRates([]model.ExchangeRate{
+		exchangeRate("USD", "NGN", "1550.25", "file"),
+		exchangeRate("EUR", "USD", "1.085", "file"),
+	}))
+	require.NoError(t, repository.SaveExchangeRates([]model.ExchangeRate{
+		exchangeRate("USD", "NGN", "1602.5", "admin"),
+	}))
+
+	rate, err := repository.FindExchangeRate("USD", "NGN")
+	require.NoError(t, err)
+	assert.Equal(t, 0, rate.Rate.Decimal.Cmp(decimal.MustParse("1602.5")))
+	assert.Equal(t, "admin", rate.Source)
+
+	rate, err = repository.FindExchangeRate("NGN", "USD")
+	require.NoError(t, err)
+	assert.Zero(t, rate.ExchangeRateID)
+
+	rates, err := repository.FindExchangeRates()
+	require.NoError(t, err)
+	require.Len(t, rates, 2)
+	assert.Equal(t, "EUR", rates[0].BaseCurrency)
+	assert.Equal(t, "USD", rates[1].BaseCurrency)
+}
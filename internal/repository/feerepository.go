@@ -0,0 +1,39 @@
+package repository
+
+import (
+	"bankingApp/internal/model"
+
+	"gorm.io/gorm"
+)
+
+type FeeRepository struct {
+	db *gorm.DB
+}
+
+// NewFeeRepository creates a new instance of FeeRepository
+func NewFeeRepository(db *gorm.DB) *FeeRepository {
+	return &FeeRepository{db: db}
+}
+
+// FindFeeRules lists the rules of the fee schedule
+func (f *FeeRepository) FindFeeRules() ([]model.FeeRule, error) {
+	var rules []model.FeeRule
+	err := f.db.
+		Order("transaction_type, channel, currency, min_amount").
+		Find(&rules).
+		Error
+	return rules, err
+}
+
+// ReplaceFeeRules replaces the whole fee schedule with the given rules in one commit
+func (f *FeeRepository) ReplaceFeeRules(rules []model.FeeRule) error {
+	return f.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("1 = 1").Delete(&model.FeeRule{}).Error; err != nil {
+			return err
+		}
+		if len(rules) == 0 {
+			return nil
+		}
+		return tx.Create(&rules).Error
+	})
+}
@@ -0,0 +1,38 @@
+package repository
+
+import (
+	"bankingApp/internal/model"
+	"testing"
+
+	"github.com/govalues/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_ReplaceFeeRulesReplacesTheSchedule(t *testing.T) {
+	repository := NewFeeRepository(openTestDB(t))
+
+	require.NoError(t, repository.ReplaceFeeRules([]model.FeeRule{
+		{TransactionType: model.DebitTransaction, FlatFee: model.BigDecimal{Decimal: decimal.MustParse("10")}},
+		{Channel: model.BulkChannel, FlatFee: model.BigDecimal{Decimal: decimal.MustParse("5")}},
+	}))
+	require.NoError(t, repository.ReplaceFeeRules([]model.FeeRule{
+		{
+			TransactionType: model.DebitTransaction,
+			MinAmount:       model.BigDecimal{Decimal: decimal.MustParse("5000")},
+			Percentage:      model.BigDecimal{Decimal: decimal.MustParse("0.5")},
+		},
+		{TransactionType: model.DebitTransaction, MaxAmount: model.BigDecimal{Decimal: decimal.MustParse("5000")}},
+	}))
+
+	rules, err := repository.FindFeeRules()
+	require.NoError(t, err)
+	require.Len(t, rules, 2)
+	assert.Zero(t, rules[0].MinAmount.Decimal.Sign())
+	assert.Equal(t, 0, rules[1].Percentage.Decimal.Cmp(decimal.MustParse("0.5")))
+
+	require.NoError(t, repository.ReplaceFeeRules(nil))
+	rules, err = repository.FindFeeRules()
+	require.NoError(t, err)
+	assert.Empty(t, rules)
+}
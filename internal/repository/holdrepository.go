@@ -0,0 +1,49 @@
+package repository
+
+import (
+	"bankingApp/internal/model"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type HoldRepository struct {
+	db *gorm.DB
+}
+
+// NewHoldRepository creates a new instance of HoldRepository
+func NewHoldRepository(db *gorm.DB) *HoldRepository {
+	return &HoldRepository{db: db}
+}
+
+// FindHold retrieves a hold by ID, returning an empty one when it does not exist
+func (h *HoldRepository) FindHold(id uint) (*model.Hold, error) {
+	var hold model.Hold
+	err := h.db.
+		Where(&model.Hold{HoldID: id}).
+		Find(&hold).
+		Error
+	return &hold, err
+}
+
+// FindHoldByReference retrieves the hold with the given payment reference
+func (h *HoldRepository) FindHoldByReference(reference string) (*model.Hold, error) {
+	var hold model.Hold
+	err := h.db.
+		Where(&model.Hold{PaymentReference: reference}).
+		Find(&hold).
+		Error
+	return &hold, err
+}
+
+// FindExpiredHolds lists up to limit active holds that have reached their expiry at now, oldest expiry first
+func (h *HoldRepository) FindExpiredHolds(now time.Time, limit int) ([]model.Hold, error) {
+	var holds []model.Hold
+	err := h.db.
+		Where("status = ? AND expires_at <= ?", model.HoldActive, now).
+		Order("expires_at, hold_id").
+		Limit(limit).
+		Find(&holds).
+		Error
+	return holds, err
+}
@@ -0,0 +1,88 @@
+package repository
+
+import (
+	"bankingApp/internal/model"
+	"testing"
+	"time"
+
+	"github.com/govalues/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func placeHold(uow *UnitOfWork, accountID uint, reference, amount string, expiresAt time.Time) error {
+	return uow.Execute(func(tx ITx) error {
+		account, err := tx.LockAccount(accountID)
+		if err != nil {
+			return err
+		}
+		value := model.BigDecimal{Decimal: decimal.MustParse(amount)}
+		if err := account.PlaceHold(value); err != nil {
+			return err
+		}
+		if err := tx.UpdateHeldBalance(account); err != nil {
+			return err
+		}
+		return tx.SaveHold(&model.Hold{
+			AccountID:        accountID,
+			PaymentReference: reference,
+			Amount:           value,
+			Status:           model.HoldActive,
+			ExpiresAt:        expiresAt,
+		})
+	})
+}
+
+func Test_HoldsReserveTheAvailableBalance(t *testing.T) {
+	db := openTestDB(t)
+	account := createTestAccount(t, db, "1234567890", "100.00")
+	uow := NewUnitOfWork(db)
+
+	require.NoError(t, placeHold(uow, account.AccountID, "hold1", "60.00", time.Now().Add(time.Hour)))
+	assert.ErrorIs(t, placeHold(uow, account.AccountID, "hold2", "50.00", time.Now().Add(time.Hour)),
+		model.ErrInsufficientFunds)
+	assert.ErrorIs(t, debit(NewJournalRepository(db), account.AccountID, 1, "50.00"), model.ErrInsufficientFunds)
+
+	reloaded, err := NewAccountRepository(db).GetAccountByAccountNumber("1234567890")
+	require.NoError(t, err)
+	assert.Equal(t, 0, reloaded.HeldBalance.Decimal.Cmp(decimal.MustParse("60.00")))
+	assert.Equal(t, 0, reloaded.AvailableBalance().Decimal.Cmp(decimal.MustParse("40.00")))
+
+	repository := NewHoldRepository(db)
+	hold, err := repository.FindHoldByReference("hold1")
+	require.NoError(t, err)
+	require.NotZero(t, hold.HoldID)
+
+	require.NoError(t, uow.Execute(func(tx ITx) error {
+		locked, err := tx.LockHold(hold.HoldID)
+		if err != nil {
+			return err
+		}
+		if err := locked.Close(model.HoldReleased); err != nil {
+			return err
+		}
+		return tx.UpdateHold(locked)
+	}))
+
+	released, err := repository.FindHold(hold.HoldID)
+	require.NoError(t, err)
+	assert.Equal(t, model.HoldReleased, released.Status)
+}
+
+func Test_FindExpiredHoldsListsActiveHoldsPastTheirExpiry(t *testing.T) {
+	db := openTestDB(t)
+	account := createTestAccount(t, db, "1234567890", "100.00")
+	uow := NewUnitOfWork(db)
+	now := time.Now()
+
+	require.NoError(t, placeHold(uow, account.AccountID, "late", "10.00", now.Add(-time.Minute)))
+	require.NoError(t, placeHold(uow, account.AccountID, "later", "10.00", now.Add(-time.Hour)))
+	require.NoError(t, placeHold(uow, account.AccountID, "current", "10.00", now.Add(time.Hour)))
+	require.NoError(t, db.Model(&model.Hold{}).Where(&model.Hold{PaymentReference: "late"}).
+		Update("status", model.HoldReleased).Error)
+
+	holds, err := NewHoldRepository(db).FindExpiredHolds(now, 10)
+	require.NoError(t, err)
+	require.Len(t, holds, 1)
+	assert.Equal(t, "later", holds[0].PaymentReference)
+}
@@ -0,0 +1,92 @@
+package repository
+
+import (
+	"bankingApp/internal/model"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// idempotencyClaimTTL is how long a claimed key waits for the response of its request. A process that stops while
+// handling the request never completes or releases the key, so a retry takes the claim over once it is this old.
+const idempotencyClaimTTL = 5 * time.Minute
+
+type IdempotencyRepository struct {
+	db *gorm.DB
+}
+
+// NewIdempotencyRepository creates a new instance of IdempotencyRepository
+func NewIdempotencyRepository(db *gorm.DB) *IdempotencyRepository {
+	return &IdempotencyRepository{db: db}
+}
+
+// Claim reserves the idempotency key for the request hash. It returns true when the key was claimed by this call,
+// otherwise it returns the record previously stored for the key. A claim of the same request that has gone without
+// a response for longer than idempotencyClaimTTL is taken over with a conditional update, so only one retry gets it.
+func (i *IdempotencyRepository) Claim(key, requestHash string) (*model.IdempotencyRecord, bool, error) {
+	now := time.Now()
+	record := &model.IdempotencyRecord{
+		IdempotencyKey: key,
+		RequestHash:    requestHash,
+		ClaimedAt:      now,
+		TimestampData:  model.TimestampData{CreatedAt: now, UpdatedAt: now},
+	}
+
+	result := i.db.Clauses(clause.OnConflict{DoNothing: true}).Create(record)
+	if result.Error != nil {
+		return nil, false, result.Error
+	}
+	if result.RowsAffected == 1 {
+		return record, true, nil
+	}
+
+	var existing model.IdempotencyRecord
+	err := i.db.
+		Where(&model.IdempotencyRecord{IdempotencyKey: key}).
+		First(&existing).
+		Error
+	if err != nil {
+		return nil, false, err
+	}
+
+	staleBefore := now.Add(-idempotencyClaimTTL)
+	if existing.Completed || existing.RequestHash != requestHash || !existing.ClaimedAt.Before(staleBefore) {
+		return &existing, false, nil
+	}
+
+	result = i.db.Model(&model.IdempotencyRecord{}).
+		Where("idempotency_key = ? AND completed = ? AND claimed_at < ?", key, false, staleBefore).
+		UpdateColumns(map[string]interface{}{
+			"claimed_at": now,
+			"updated_at": now,
+		})
+	if result.Error != nil {
+		return nil, false, result.Error
+	}
+	if result.RowsAffected == 1 {
+		existing.ClaimedAt = now
+		return &existing, true, nil
+	}
+	return &existing, false, nil
+}
+
+// Complete persists the response returned for the claimed idempotency key
+func (i *IdempotencyRepository) Complete(key string, statusCode int, responseBody string) error {
+	return i.db.Model(&model.IdempotencyRecord{}).
+		Where(&model.IdempotencyRecord{IdempotencyKey: key}).
+		UpdateColumns(map[string]interface{}{
+			"status_code":   statusCode,
+			"response_body": responseBody,
+			"completed":     true,
+			"updated_at":    time.Now(),
+		}).Error
+}
+
+// Release removes the claim on an idempotency key so the request can be retried
+func (i *IdempotencyRepository) Release(key string) error {
+	return i.db.
+		Where(&model.IdempotencyRecord{IdempotencyKey: key}).
+		Delete(&model.IdempotencyRecord{}).
+		Error
+}
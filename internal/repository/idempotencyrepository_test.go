@@ -0,0 +1,39 @@
+package repository
+
+import (
+	"bankingApp/internal/model"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_ClaimTakesOverAnAbandonedClaim(t *testing.T) {
+	db := openTestDB(t)
+	repository := NewIdempotencyRepository(db)
+
+	_, claimed, err := repository.Claim("1:key-1", "hash")
+	require.NoError(t, err)
+	require.True(t, claimed)
+
+	_, claimed, err = repository.Claim("1:key-1", "hash")
+	require.NoError(t, err)
+	assert.False(t, claimed, "a claim within its TTL is still in progress")
+
+	require.NoError(t, db.Model(&model.IdempotencyRecord{}).
+		Where("idempotency_key = ?", "1:key-1").
+		UpdateColumn("claimed_at", time.Now().Add(-idempotencyClaimTTL-time.Minute)).Error)
+
+	_, claimed, err = repository.Claim("1:key-1", "another hash")
+	require.NoError(t, err)
+	assert.False(t, claimed, "an abandoned claim is not taken over by another request")
+
+	_, claimed, err = repository.Claim("1:key-1", "hash")
+	require.NoError(t, err)
+	assert.True(t, claimed)
+
+	_, claimed, err = repository.Claim("1:key-1", "hash")
+	require.NoError(t, err)
+	assert.False(t, claimed)
+}
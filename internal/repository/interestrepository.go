@@ -0,0 +1,96 @@
+package repository
+
+import (
+	"bankingApp/internal/model"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type InterestRepository struct {
+	db *gorm.DB
+}
+
+// NewInterestRepository creates a new instance of InterestRepository
+func NewInterestRepository(db *gorm.DB) *InterestRepository {
+	return &InterestRepository{db: db}
+}
+
+// FindProducts retrieves every account product, ordered by code
+func (i *InterestRepository) FindProducts() ([]model.AccountProduct, error) {
+	var products []model.AccountProduct
+	err := i.db.Order("code").Find(&products).Error
+	return products, err
+}
+
+// FindProduct retrieves an account product by code, returning an empty one when it does not exist
+func (i *InterestRepository) FindProduct(code string) (*model.AccountProduct, error) {
+	var product model.AccountProduct
+	err := i.db.
+		Where(&model.AccountProduct{Code: code}).
+		Find(&product).
+		Error
+	return &product, err
+}
+
+// SaveProduct stores an account product, replacing the product with the same code
+func (i *InterestRepository) SaveProduct(product *model.AccountProduct) error {
+	return i.db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "code"}},
+		DoUpdates: clause.AssignmentColumns([]string{
+			"name", "annual_rate", "day_count", "withholding_tax_rate", "updated_at",
+		}),
+	}).Create(product).Error
+}
+
+// AssignProduct opens the account under the account product with the given code
+func (i *InterestRepository) AssignProduct(accountID uint, code string) error {
+	return i.db.Model(&model.Account{}).Where(model.Account{AccountID: accountID}).
+		UpdateColumns(map[string]interface{}{
+			"product":    code,
+			"updated_at": time.Now(),
+		}).Error
+}
+
+// FindAccountsToAccrueInterest lists up to limit accounts in credit that are opened under an account product
+// and have not accrued interest for the given day yet
+func (i *InterestRepository) FindAccountsToAccrueInterest(day time.Time, limit int) ([]model.Account, error) {
+	var accounts []model.Account
+	err := i.db.
+		Where("product <> '' AND balance > 0").
+		Where("account_id NOT IN (?)", i.db.Model(&model.InterestAccrual{}).
+			Select("account_id").
+			Where("accrual_date = ?", day)).
+		Order("account_id").
+		Limit(limit).
+		Find(&accounts).
+		Error
+	return accounts, err
+}
+
+// FindAccountsToCapitalise lists up to limit accounts with accruals dated before the given day
+// that have not been capitalised yet
+func (i *InterestRepository) FindAccountsToCapitalise(before time.Time, limit int) ([]model.Account, error) {
+	var accounts []model.Account
+	err := i.db.
+		Where("account_id IN (?)", i.db.Model(&model.InterestAccrual{}).
+			Select("account_id").
+			Where("capitalised_at IS NULL AND accrual_date < ?", before)).
+		Order("account_id").
+		Limit(limit).
+		Find(&accounts).
+		Error
+	return accounts, err
+}
+
+// FindUncapitalisedAccruals lists the accruals of the account that have not been capitalised yet, oldest first
+func (i *InterestRepository) FindUncapitalisedAccruals(accountID uint) ([]model.InterestAccrual, error) {
+	var accruals []model.InterestAccrual
+	err := i.db.
+		Where("account_id = ? AND capitalised_at IS NULL", accountID).
+		Order("accrual_date").
+		Find(&accruals).
+		Error
+	return accruals, err
+}
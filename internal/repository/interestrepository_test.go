@@ -0,0 +1,118 @@
+package repository
+
+import (
+	"bankingApp/internal/model"
+	"testing"
+	"time"
+
+	"github.com/govalues/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_SaveProductReplacesTheProductWithTheSameCode(t *testing.T) {
+	db := openTestDB(t)
+	repository := NewInterestRepository(db)
+
+	require.NoError(t, repository.SaveProduct(&model.AccountProduct{
+		Code: "SAVER", Name: "Easy saver", DayCount: model.Actual365,
+		AnnualRate: model.BigDecimal{Decimal: decimal.MustParse("3.5")},
+	}))
+	require.NoError(t, repository.SaveProduct(&model.AccountProduct{
+		Code: "SAVER", Name: "Easy saver", DayCount: model.Thirty360,
+		AnnualRate: model.BigDecimal{Decimal: decimal.MustParse("4.25")},
+	}))
+
+	products, err := repository.FindProducts()
+	require.NoError(t, err)
+	require.Len(t, products, 1)
+	assert.Equal(t, model.Thirty360, products[0].DayCount)
+	assert.Equal(t, 0, products[0].AnnualRate.Decimal.Cmp(decimal.MustParse("4.25")))
+
+	missing, err := repository.FindProduct("GOLD")
+	require.NoError(t, err)
+	assert.Zero(t, missing.AccountProductID)
+}
+
+func Test_FindAccountsToAccrueInterestSkipsAccountsThatAccruedForTheDay(t *testing.T) {
+	db := openTestDB(t)
+	saver := createTestAccount(t, db, "1234567890", "100.00")
+	accrued := createTestAccount(t, db, "1234567891", "100.00")
+	overdrawn := createTestAccount(t, db, "1234567892", "-100.00")
+	createTestAccount(t, db, "1234567893", "100.00")
+	repository := NewInterestRepository(db)
+	day := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	for _, accountID := range []uint{saver.AccountID, accrued.AccountID, overdrawn.AccountID} {
+		require.NoError(t, repository.AssignProduct(accountID, "SAVER"))
+	}
+	require.NoError(t, NewUnitOfWork(db).Execute(func(tx ITx) error {
+		return tx.SaveInterestAccrual(&model.InterestAccrual{AccountID: accrued.AccountID, AccrualDate: day})
+	}))
+
+	accounts, err := repository.FindAccountsToAccrueInterest(day, 10)
+	require.NoError(t, err)
+	require.Len(t, accounts, 1)
+	assert.Equal(t, saver.AccountID, accounts[0].AccountID)
+	assert.Equal(t, "SAVER", accounts[0].Product)
+}
+
+func Test_CapitalisedAccrualsAreNotCapitalisedAgain(t *testing.T) {
+	db := openTestDB(t)
+	account := createTestAccount(t, db, "1234567890", "100.00")
+	repository := NewInterestRepository(db)
+	uow := NewUnitOfWork(db)
+	february := time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC)
+	march := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	require.NoError(t, uow.Execute(func(tx ITx) error {
+		for _, day := range []time.Time{february, march} {
+			err := tx.SaveInterestAccrual(&model.InterestAccrual{
+				AccountID:   account.AccountID,
+				AccrualDate: day,
+				Amount:      model.BigDecimal{Decimal: decimal.MustParse("0.009589041095890411")},
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}))
+
+	accounts, err := repository.FindAccountsToCapitalise(march, 10)
+	require.NoError(t, err)
+	require.Len(t, accounts, 1)
+
+	require.NoError(t, uow.Execute(func(tx ITx) error {
+		accruals, err := tx.LockUncapitalisedAccruals(account.AccountID, march)
+		if err != nil {
+			return err
+		}
+		require.Len(t, accruals, 1)
+		assert.Equal(t, february, accruals[0].AccrualDate.UTC())
+		assert.Equal(t, 0, accruals[0].Amount.Decimal.Cmp(decimal.MustParse("0.009589041095890411")))
+
+		locked, err := tx.LockAccount(account.AccountID)
+		if err != nil {
+			return err
+		}
+		locked.InterestCarried = accruals[0].Amount
+		if err := tx.UpdateInterestCarried(locked); err != nil {
+			return err
+		}
+		return tx.MarkAccrualsCapitalised(accruals, nil, march)
+	}))
+
+	accounts, err = repository.FindAccountsToCapitalise(march, 10)
+	require.NoError(t, err)
+	assert.Empty(t, accounts)
+
+	remaining, err := repository.FindUncapitalisedAccruals(account.AccountID)
+	require.NoError(t, err)
+	require.Len(t, remaining, 1)
+	assert.Equal(t, march, remaining[0].AccrualDate.UTC())
+
+	var reloaded model.Account
+	require.NoError(t, db.First(&reloaded, account.AccountID).Error)
+	assert.Equal(t, 0, reloaded.InterestCarried.Decimal.Cmp(decimal.MustParse("0.009589041095890411")))
+}
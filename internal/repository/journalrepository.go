@@ -0,0 +1,126 @@
+package repository
+
+import (
+	"bankingApp/internal/ledger"
+	"bankingApp/internal/model"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type JournalRepository struct {
+	db *gorm.DB
+}
+
+// NewJournalRepository creates a new instance of JournalRepository
+func NewJournalRepository(db *gorm.DB) *JournalRepository {
+	return &JournalRepository{db: db}
+}
+
+// PostJournalEntry applies the postings of the entry to the customer account balances and stores the entry
+// and the given transactions within a single unit of work
+func (j *JournalRepository) PostJournalEntry(entry *model.JournalEntry, transactions ...*model.Transaction) error {
+	return NewUnitOfWork(j.db).Execute(func(tx ITx) error {
+		if err := tx.PostJournalEntry(entry); err != nil {
+			return err
+		}
+		for _, transaction := range transactions {
+			if err := tx.SaveTransaction(transaction); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// AccountPostingTotals returns the total debits and credits posted against a customer account
+func (j *JournalRepository) AccountPostingTotals(accountID uint) (model.BigDecimal, model.BigDecimal, error) {
+	var debits, credits model.BigDecimal
+	for direction, total := range map[model.EntryDirection]*model.BigDecimal{
+		model.DebitEntry:  &debits,
+		model.CreditEntry: &credits,
+	} {
+		err := j.db.Model(&model.Posting{}).
+			Select("COALESCE(SUM(amount), 0)").
+			Where(&model.Posting{AccountID: accountID, Direction: direction}).
+			Row().
+			Scan(&total.Decimal)
+		if err != nil {
+			return model.BigDecimal{}, model.BigDecimal{}, err
+		}
+	}
+	return debits, credits, nil
+}
+
+// FindAccounts lists up to limit customer accounts with an ID after afterID, in ID order
+func (j *JournalRepository) FindAccounts(afterID uint, limit int) ([]model.Account, error) {
+	var accounts []model.Account
+	err := j.db.
+		Where("account_id > ?", afterID).
+		Order("account_id").
+		Limit(limit).
+		Find(&accounts).
+		Error
+	return accounts, err
+}
+
+// FindAccount retrieves a customer account by ID
+func (j *JournalRepository) FindAccount(accountID uint) (*model.Account, error) {
+	var account model.Account
+	err := j.db.
+		Where(&model.Account{AccountID: accountID}).
+		First(&account).
+		Error
+	return &account, err
+}
+
+// EnsureChartOfAccounts creates the general ledger accounts that do not exist yet
+func (j *JournalRepository) EnsureChartOfAccounts(accounts []model.LedgerAccount) error {
+	for _, account := range accounts {
+		account := account
+		err := j.db.
+			Where(&model.LedgerAccount{Code: account.Code}).
+			FirstOrCreate(&account).
+			Error
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// PostOpeningBalances posts an opening balance entry for every account that has a balance but no postings yet,
+// so balances recorded before the ledger existed are backed by postings
+func (j *JournalRepository) PostOpeningBalances() error {
+	var accounts []model.Account
+	err := j.db.
+		Where("account_id NOT IN (?)", j.db.Model(&model.Posting{}).Distinct("account_id")).
+		Where("balance <> 0").
+		Find(&accounts).
+		Error
+	if err != nil {
+		return err
+	}
+
+	for i := range accounts {
+		entry, err := openingBalanceEntry(&accounts[i])
+		if err != nil {
+			return err
+		}
+		ledger.Stamp(entry, time.Now())
+		if err := j.db.Create(entry).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func openingBalanceEntry(account *model.Account) (*model.JournalEntry, error) {
+	balance := account.GetBalance()
+	entry := ledger.NewEntry("opening-"+account.AccountNumber, "opening balance").In(account.Currency)
+	if balance.Decimal.IsNeg() {
+		amount := model.BigDecimal{Decimal: balance.Decimal.Abs()}
+		return entry.DebitAccount(account.AccountID, amount).CreditGL(ledger.OpeningBalanceGL, amount).Build()
+	}
+	return entry.DebitGL(ledger.OpeningBalanceGL, balance).CreditAccount(account.AccountID, balance).Build()
+}
@@ -0,0 +1,67 @@
+package repository
+
+import (
+	"bankingApp/internal/model"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type LimitRepository struct {
+	db *gorm.DB
+}
+
+// NewLimitRepository creates a new instance of LimitRepository
+func NewLimitRepository(db *gorm.DB) *LimitRepository {
+	return &LimitRepository{db: db}
+}
+
+// FindTierLimit retrieves the limits of an account tier, returning empty limits when the tier has none
+func (l *LimitRepository) FindTierLimit(tier string) (*model.TransactionLimit, error) {
+	var limit model.TransactionLimit
+	err := l.db.
+		Where("tier = ? AND account_id = 0", tier).
+		Find(&limit).
+		Error
+	return &limit, err
+}
+
+// FindAccountLimit retrieves the limits set on a single account, returning empty limits when it has none
+func (l *LimitRepository) FindAccountLimit(accountID uint) (*model.TransactionLimit, error) {
+	var limit model.TransactionLimit
+	err := l.db.
+		Where("tier = '' AND account_id = ?", accountID).
+		Find(&limit).
+		Error
+	return &limit, err
+}
+
+// SaveLimit stores the limits of a tier or an account, replacing the limits it already has
+func (l *LimitRepository) SaveLimit(limit *model.TransactionLimit) error {
+	return l.db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "tier"}, {Name: "account_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{
+			"max_amount", "daily_amount", "monthly_amount", "daily_count", "monthly_count", "updated_at",
+		}),
+	}).Create(limit).Error
+}
+
+// FindLimitUsage returns the total and the number of debits made from the account since the start of the given day
+// and month. Failed transactions and reversals do not count towards the limits of the account.
+func (l *LimitRepository) FindLimitUsage(accountID uint, day, month time.Time) (model.LimitUsage, error) {
+	return findLimitUsage(l.db, accountID, day, month)
+}
+
+func findLimitUsage(db *gorm.DB, accountID uint, day, month time.Time) (model.LimitUsage, error) {
+	var usage model.LimitUsage
+	err := db.Model(&model.Transaction{}).
+		Select("COALESCE(SUM(CASE WHEN transaction_time >= ? THEN amount ELSE 0 END), 0) AS daily_amount, "+
+			"COALESCE(SUM(CASE WHEN transaction_time >= ? THEN 1 ELSE 0 END), 0) AS daily_count, "+
+			"COALESCE(SUM(amount), 0) AS monthly_amount, COUNT(*) AS monthly_count", day, day).
+		Where(&model.Transaction{AccountID: accountID, Type: model.DebitTransaction}).
+		Where("status <> ? AND original_transaction_id IS NULL AND transaction_time >= ?", model.FailedStatus, month).
+		Scan(&usage).
+		Error
+	return usage, err
+}
@@ -0,0 +1,83 @@
+package repository
+
+import (
+	"bankingApp/internal/model"
+	"testing"
+	"time"
+
+	"github.com/govalues/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_SaveLimitReplacesTheLimitsOfATierOrAccount(t *testing.T) {
+	repository := NewLimitRepository(openTestDB(t))
+
+	require.NoError(t, repository.SaveLimit(&model.TransactionLimit{
+		Tier:        model.DefaultAccountTier,
+		DailyAmount: model.BigDecimal{Decimal: decimal.MustParse("1000")},
+		DailyCount:  5,
+	}))
+	require.NoError(t, repository.SaveLimit(&model.TransactionLimit{
+		Tier:      model.DefaultAccountTier,
+		MaxAmount: model.BigDecimal{Decimal: decimal.MustParse("250")},
+	}))
+	require.NoError(t, repository.SaveLimit(&model.TransactionLimit{
+		AccountID:   1,
+		DailyAmount: model.BigDecimal{Decimal: decimal.MustParse("5000")},
+	}))
+
+	limit, err := repository.FindTierLimit(model.DefaultAccountTier)
+	require.NoError(t, err)
+	assert.Equal(t, 0, limit.MaxAmount.Decimal.Cmp(decimal.MustParse("250")))
+	assert.True(t, limit.DailyAmount.Decimal.IsZero())
+	assert.Zero(t, limit.DailyCount)
+
+	limit, err = repository.FindAccountLimit(1)
+	require.NoError(t, err)
+	assert.Equal(t, 0, limit.DailyAmount.Decimal.Cmp(decimal.MustParse("5000")))
+
+	limit, err = repository.FindAccountLimit(2)
+	require.NoError(t, err)
+	assert.Zero(t, limit.TransactionLimitID)
+}
+
+func Test_FindLimitUsageCountsTheDebitsOfTheDayAndMonth(t *testing.T) {
+	db := openTestDB(t)
+	repository := NewLimitRepository(db)
+	since := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	day := since.Add(90 * time.Minute)
+	originalID := uint(1)
+
+	for i, transaction := range []model.Transaction{
+		{Type: model.DebitTransaction, Status: model.SucceededStatus, Amount: decimalAmount("100"), TransactionTime: since.Add(time.Hour)},
+		{Type: model.DebitTransaction, Status: model.PendingStatus, Amount: decimalAmount("50.5"), TransactionTime: since.Add(2 * time.Hour)},
+		{Type: model.DebitTransaction, Status: model.FailedStatus, Amount: decimalAmount("70"), TransactionTime: since.Add(time.Hour)},
+		{Type: model.DebitTransaction, Status: model.SucceededStatus, Amount: decimalAmount("30"), TransactionTime: since.Add(-time.Hour)},
+		{Type: model.CreditTransaction, Status: model.SucceededStatus, Amount: decimalAmount("500"), TransactionTime: since.Add(time.Hour)},
+		{Type: model.DebitTransaction, Status: model.SucceededStatus, Amount: decimalAmount("20"), TransactionTime: since.Add(time.Hour),
+			OriginalTransactionID: &originalID},
+	} {
+		transaction := transaction
+		transaction.AccountID = 1
+		transaction.Reference = string(rune('a' + i))
+		transaction.PaymentReference = transaction.Reference
+		require.NoError(t, db.Create(&transaction).Error)
+	}
+
+	usage, err := repository.FindLimitUsage(1, day, since)
+	require.NoError(t, err)
+	assert.Equal(t, 0, usage.MonthlyAmount.Decimal.Cmp(decimal.MustParse("150.5")))
+	assert.Equal(t, 2, usage.MonthlyCount)
+	assert.Equal(t, 0, usage.DailyAmount.Decimal.Cmp(decimal.MustParse("50.5")))
+	assert.Equal(t, 1, usage.DailyCount)
+
+	usage, err = repository.FindLimitUsage(2, day, since)
+	require.NoError(t, err)
+	assert.True(t, usage.MonthlyAmount.Decimal.IsZero())
+	assert.Zero(t, usage.MonthlyCount)
+}
+
+func decimalAmount(value string) model.BigDecimal {
+	return model.BigDecimal{Decimal: decimal.MustParse(value)}
+}
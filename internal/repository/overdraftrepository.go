@@ -0,0 +1,54 @@
+package repository
+
+import (
+	"bankingApp/internal/model"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type OverdraftRepository struct {
+	db *gorm.DB
+}
+
+// NewOverdraftRepository creates a new instance of OverdraftRepository
+func NewOverdraftRepository(db *gorm.DB) *OverdraftRepository {
+	return &OverdraftRepository{db: db}
+}
+
+// FindOverdraftChanges lists the changes made to the overdraft facility of an account, latest first
+func (o *OverdraftRepository) FindOverdraftChanges(accountID uint) ([]model.OverdraftChange, error) {
+	var changes []model.OverdraftChange
+	err := o.db.
+		Where(&model.OverdraftChange{AccountID: accountID}).
+		Order("changed_at DESC, overdraft_change_id DESC").
+		Find(&changes).
+		Error
+	return changes, err
+}
+
+// FindAccountsToAccrue lists up to limit accounts with an overdraft interest rate that closed the given day
+// overdrawn and have not accrued overdraft interest for it yet. The balance the day closed on is that of the
+// postings made before end, the midnight the day ends at.
+func (o *OverdraftRepository) FindAccountsToAccrue(day, end time.Time, limit int) ([]model.Account, error) {
+	var accounts []model.Account
+	err := o.db.
+		Where("overdraft_rate > 0").
+		Where("account_id IN (?)", postingsBefore(o.db, end).
+			Select("tbl_posting.account_id").
+			Group("tbl_posting.account_id").
+			Having("SUM("+signedPostingAmount+") < 0", model.CreditEntry)).
+		Where("account_id NOT IN (?)", o.db.Model(&model.OverdraftInterestAccrual{}).
+			Select("account_id").
+			Where("accrual_date = ?", day)).
+		Order("account_id").
+		Limit(limit).
+		Find(&accounts).
+		Error
+	return accounts, err
+}
+
+// BalanceBefore returns the balance of the account from the postings made against it before the given time
+func (o *OverdraftRepository) BalanceBefore(accountID uint, before time.Time) (model.BigDecimal, error) {
+	return balanceBefore(o.db, accountID, before)
+}
@@ -0,0 +1,165 @@
+package repository
+
+import (
+	"bankingApp/internal/ledger"
+	"bankingApp/internal/model"
+	"testing"
+	"time"
+
+	"github.com/govalues/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func setOverdraft(uow *UnitOfWork, accountID uint, limit, rate string, now time.Time) error {
+	return uow.Execute(func(tx ITx) error {
+		account, err := tx.LockAccount(accountID)
+		if err != nil {
+			return err
+		}
+		change := account.SetOverdraft(
+			model.BigDecimal{Decimal: decimal.MustParse(limit)},
+			model.BigDecimal{Decimal: decimal.MustParse(rate)},
+			"ops.user", "agreed facility", now)
+		if err := tx.UpdateOverdraft(account); err != nil {
+			return err
+		}
+		return tx.SaveOverdraftChange(change)
+	})
+}
+
+func Test_OverdraftLetsDebitsTakeTheBalanceDownToTheLimit(t *testing.T) {
+	db := openTestDB(t)
+	account := createTestAccount(t, db, "1234567890", "100.00")
+	repository := NewJournalRepository(db)
+	uow := NewUnitOfWork(db)
+
+	assert.ErrorIs(t, debit(repository, account.AccountID, 1, "150.00"), model.ErrInsufficientFunds)
+
+	require.NoError(t, setOverdraft(uow, account.AccountID, "100.00", "18.25", time.Now()))
+	require.NoError(t, debit(repository, account.AccountID, 2, "150.00"))
+	assert.Equal(t, 0, reloadBalance(t, db, account.AccountID).Cmp(decimal.MustParse("-50.00")))
+
+	assert.ErrorIs(t, debit(repository, account.AccountID, 3, "50.01"), model.ErrInsufficientFunds)
+	assert.Equal(t, 0, reloadBalance(t, db, account.AccountID).Cmp(decimal.MustParse("-50.00")))
+}
+
+// postAt stores the journal entry as posted at the given time
+func postAt(t *testing.T, db *gorm.DB, entry *model.JournalEntry, err error, at time.Time) {
+	require.NoError(t, err)
+	ledger.Stamp(entry, at)
+	require.NoError(t, db.Create(entry).Error)
+}
+
+func Test_FindAccountsToAccrueSkipsAccountsThatAccruedForTheDay(t *testing.T) {
+	db := openTestDB(t)
+	overdrawn := createTestAccount(t, db, "1234567890", "-50.00")
+	withoutRate := createTestAccount(t, db, "1234567891", "-50.00")
+	inCredit := createTestAccount(t, db, "1234567892", "50.00")
+	uow := NewUnitOfWork(db)
+	repository := NewOverdraftRepository(db)
+	day := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	midnight := day.AddDate(0, 0, 1)
+	for _, account := range []*model.Account{overdrawn, withoutRate, inCredit} {
+		entry, err := openingBalanceEntry(account)
+		postAt(t, db, entry, err, day.Add(9*time.Hour))
+	}
+
+	require.NoError(t, setOverdraft(uow, overdrawn.AccountID, "100.00", "18.25", day))
+	require.NoError(t, setOverdraft(uow, inCredit.AccountID, "100.00", "18.25", day))
+	accounts, err := repository.FindAccountsToAccrue(day, midnight, 10)
+	require.NoError(t, err)
+	require.Len(t, accounts, 1)
+	assert.Equal(t, overdrawn.AccountID, accounts[0].AccountID)
+
+	interest := model.BigDecimal{Decimal: decimal.MustParse("0.03")}
+	err = uow.Execute(func(tx ITx) error {
+		entry, err := ledger.OverdraftInterestEntry("interest1", overdrawn.AccountID, "", interest)
+		if err != nil {
+			return err
+		}
+		if err := tx.PostCharge(entry); err != nil {
+			return err
+		}
+		return tx.SaveOverdraftAccrual(&model.OverdraftInterestAccrual{
+			AccountID:   overdrawn.AccountID,
+			AccrualDate: day,
+			Reference:   "interest1",
+			Amount:      interest,
+		})
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 0, reloadBalance(t, db, overdrawn.AccountID).Cmp(decimal.MustParse("-50.03")))
+
+	accounts, err = repository.FindAccountsToAccrue(day, midnight, 10)
+	require.NoError(t, err)
+	assert.Empty(t, accounts)
+
+	accounts, err = repository.FindAccountsToAccrue(midnight, midnight.AddDate(0, 0, 1), 10)
+	require.NoError(t, err)
+	assert.Len(t, accounts, 1)
+
+	duplicate := &model.OverdraftInterestAccrual{AccountID: overdrawn.AccountID, AccrualDate: day}
+	assert.Error(t, uow.Execute(func(tx ITx) error { return tx.SaveOverdraftAccrual(duplicate) }))
+}
+
+func Test_FindAccountsToAccrueTakesTheBalanceTheDayClosedOn(t *testing.T) {
+	db := openTestDB(t)
+	uow := NewUnitOfWork(db)
+	repository := NewOverdraftRepository(db)
+	day := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	midnight := day.AddDate(0, 0, 1)
+	amount := func(value string) model.BigDecimal { return model.BigDecimal{Decimal: decimal.MustParse(value)} }
+
+	closedOverdrawn := createTestAccount(t, db, "1234567890", "-50.00")
+	backInCredit := createTestAccount(t, db, "1234567891", "-50.00")
+	overdrawnAfterMidnight := createTestAccount(t, db, "1234567892", "50.00")
+	for _, account := range []*model.Account{closedOverdrawn, backInCredit, overdrawnAfterMidnight} {
+		require.NoError(t, setOverdraft(uow, account.AccountID, "100.00", "18.25", day))
+		entry, err := openingBalanceEntry(account)
+		postAt(t, db, entry, err, day.Add(9*time.Hour))
+	}
+
+	entry, err := ledger.NewEntry("credit1", "credit").
+		DebitGL(ledger.OpeningBalanceGL, amount("70.00")).
+		CreditAccount(backInCredit.AccountID, amount("70.00")).
+		Build()
+	postAt(t, db, entry, err, day.Add(15*time.Hour))
+	entry, err = ledger.NewEntry("debit1", "debit").
+		DebitAccount(overdrawnAfterMidnight.AccountID, amount("100.00")).
+		CreditGL(ledger.OpeningBalanceGL, amount("100.00")).
+		Build()
+	postAt(t, db, entry, err, midnight.Add(time.Minute))
+
+	accounts, err := repository.FindAccountsToAccrue(day, midnight, 10)
+	require.NoError(t, err)
+	require.Len(t, accounts, 1)
+	assert.Equal(t, closedOverdrawn.AccountID, accounts[0].AccountID)
+
+	closing, err := repository.BalanceBefore(backInCredit.AccountID, midnight)
+	require.NoError(t, err)
+	assert.Equal(t, 0, closing.Decimal.Cmp(decimal.MustParse("20.00")))
+	closing, err = repository.BalanceBefore(overdrawnAfterMidnight.AccountID, midnight)
+	require.NoError(t, err)
+	assert.Equal(t, 0, closing.Decimal.Cmp(decimal.MustParse("50.00")))
+}
+
+func Test_FindOverdraftChangesListsTheAuditTrailLatestFirst(t *testing.T) {
+	db := openTestDB(t)
+	account := createTestAccount(t, db, "1234567890", "100.00")
+	uow := NewUnitOfWork(db)
+	now := time.Now()
+
+	require.NoError(t, setOverdraft(uow, account.AccountID, "100.00", "18.25", now.Add(-time.Hour)))
+	require.NoError(t, setOverdraft(uow, account.AccountID, "0", "0", now))
+
+	changes, err := NewOverdraftRepository(db).FindOverdraftChanges(account.AccountID)
+	require.NoError(t, err)
+	require.Len(t, changes, 2)
+	assert.Equal(t, 0, changes[0].PreviousLimit.Decimal.Cmp(decimal.MustParse("100.00")))
+	assert.True(t, changes[0].Limit.Decimal.IsZero())
+	assert.True(t, changes[1].PreviousLimit.Decimal.IsZero())
+	assert.Equal(t, 0, changes[1].Limit.Decimal.Cmp(decimal.MustParse("100.00")))
+	assert.Equal(t, "ops.user", changes[1].ChangedBy)
+}
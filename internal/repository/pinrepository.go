@@ -0,0 +1,89 @@
+package repository
+
+import (
+	"bankingApp/internal/model"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type PinRepository struct {
+	db *gorm.DB
+}
+
+// NewPinRepository creates a new instance of PinRepository
+func NewPinRepository(db *gorm.DB) *PinRepository {
+	return &PinRepository{db: db}
+}
+
+// SavePinAudit records an attempt to change or reset a transaction PIN in the audit trail
+func (p *PinRepository) SavePinAudit(audit *model.PinAudit) error {
+	return p.db.Create(audit).Error
+}
+
+// SavePinResetCode stores a new PIN reset code for the user, expiring the codes sent to them before it
+func (p *PinRepository) SavePinResetCode(code *model.PinResetCode) error {
+	return p.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&model.PinResetCode{}).
+			Where("user_id = ? AND used_at IS NULL AND expires_at > ?", code.UserID, code.CreatedAt).
+			UpdateColumn("expires_at", code.CreatedAt).
+			Error
+		if err != nil {
+			return err
+		}
+		return tx.Create(code).Error
+	})
+}
+
+// FindPinResetCode returns the latest PIN reset code sent to the user, with a zero ID when none was sent
+func (p *PinRepository) FindPinResetCode(userID uint) (*model.PinResetCode, error) {
+	var code model.PinResetCode
+	err := p.db.
+		Where(&model.PinResetCode{UserID: userID}).
+		Order("pin_reset_code_id DESC").
+		Limit(1).
+		Find(&code).
+		Error
+	return &code, err
+}
+
+// RecordPinResetCodeAttempt counts an incorrect code against the PIN reset code
+func (p *PinRepository) RecordPinResetCodeAttempt(codeID uint) error {
+	return p.db.Model(&model.PinResetCode{}).
+		Where(&model.PinResetCode{PinResetCodeID: codeID}).
+		UpdateColumn("attempts", gorm.Expr("attempts + 1")).
+		Error
+}
+
+// UpdateTransactionPin stores the new transaction PIN hash of the user, clearing any lock on PIN use
+func (p *PinRepository) UpdateTransactionPin(userID uint, pinHash string) error {
+	return p.db.Model(&model.User{}).Where(&model.User{UserID: userID}).
+		UpdateColumns(transactionPinColumns(pinHash)).Error
+}
+
+// ResetTransactionPin uses up the PIN reset code and stores the new transaction PIN hash of the user in one commit.
+// It returns ErrInvalidPinResetCode when the code has been used in the meantime.
+func (p *PinRepository) ResetTransactionPin(codeID, userID uint, pinHash string, now time.Time) error {
+	return p.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&model.PinResetCode{}).
+			Where("pin_reset_code_id = ? AND used_at IS NULL", codeID).
+			UpdateColumn("used_at", now)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return model.ErrInvalidPinResetCode
+		}
+		return tx.Model(&model.User{}).Where(&model.User{UserID: userID}).
+			UpdateColumns(transactionPinColumns(pinHash)).Error
+	})
+}
+
+func transactionPinColumns(pinHash string) map[string]interface{} {
+	return map[string]interface{}{
+		"transaction_pin":  pinHash,
+		"pin_attempts":     0,
+		"pin_locked_until": nil,
+		"updated_at":       time.Now(),
+	}
+}
@@ -0,0 +1,81 @@
+package repository
+
+import (
+	"bankingApp/internal/api/constants"
+	"bankingApp/internal/model"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_SavePinResetCodeExpiresEarlierCodes(t *testing.T) {
+	db := openTestDB(t)
+	repository := NewPinRepository(db)
+	now := time.Now()
+
+	first := &model.PinResetCode{UserID: 1, CodeHash: "first", ExpiresAt: now.Add(15 * time.Minute),
+		TimestampData: model.TimestampData{CreatedAt: now}}
+	require.NoError(t, repository.SavePinResetCode(first))
+	second := &model.PinResetCode{UserID: 1, CodeHash: "second", ExpiresAt: now.Add(20 * time.Minute),
+		TimestampData: model.TimestampData{CreatedAt: now.Add(5 * time.Minute)}}
+	require.NoError(t, repository.SavePinResetCode(second))
+
+	latest, err := repository.FindPinResetCode(1)
+	require.NoError(t, err)
+	assert.Equal(t, "second", latest.CodeHash)
+	assert.True(t, latest.Usable(now.Add(10*time.Minute)))
+
+	var expired model.PinResetCode
+	require.NoError(t, db.First(&expired, first.PinResetCodeID).Error)
+	assert.False(t, expired.Usable(now.Add(10*time.Minute)))
+
+	none, err := repository.FindPinResetCode(2)
+	require.NoError(t, err)
+	assert.Zero(t, none.PinResetCodeID)
+}
+
+func Test_ResetTransactionPinUsesTheCodeOnce(t *testing.T) {
+	db := openTestDB(t)
+	repository := NewPinRepository(db)
+	users := NewUserRepository(db, model.Lockout{MaxAttempts: 1, Cooldown: time.Hour}, model.Lockout{})
+	user := &model.User{Username: "johndoe", TransactionPin: "old-hash"}
+	require.NoError(t, users.CreateUser(user, &model.Account{AccountNumber: "1234567897"}))
+	now := time.Now()
+	require.NoError(t, users.RecordFailedPinAttempt(user.UserID, now))
+
+	code := &model.PinResetCode{UserID: user.UserID, CodeHash: "hash", ExpiresAt: now.Add(15 * time.Minute),
+		TimestampData: model.TimestampData{CreatedAt: now}}
+	require.NoError(t, repository.SavePinResetCode(code))
+	require.NoError(t, repository.RecordPinResetCodeAttempt(code.PinResetCodeID))
+
+	require.NoError(t, repository.ResetTransactionPin(code.PinResetCodeID, user.UserID, "new-hash", now))
+	err := repository.ResetTransactionPin(code.PinResetCodeID, user.UserID, "another-hash", now)
+	assert.ErrorIs(t, err, model.ErrInvalidPinResetCode)
+
+	found, err := users.FindUserByUsername("johndoe")
+	require.NoError(t, err)
+	assert.Equal(t, "new-hash", found.TransactionPin)
+	assert.False(t, found.PinLocked(now))
+
+	used, err := repository.FindPinResetCode(user.UserID)
+	require.NoError(t, err)
+	assert.Equal(t, 1, used.Attempts)
+	assert.NotNil(t, used.UsedAt)
+}
+
+func Test_SavePinAudit(t *testing.T) {
+	db := openTestDB(t)
+	repository := NewPinRepository(db)
+	audit := &model.PinAudit{UserID: 1, Action: model.PinResetAction, Reason: constants.InvalidPinResetCode,
+		ClientIP: "10.0.0.1", CreatedAt: time.Now()}
+	require.NoError(t, repository.SavePinAudit(audit))
+
+	var saved model.PinAudit
+	require.NoError(t, db.First(&saved, audit.PinAuditID).Error)
+	assert.Equal(t, model.PinResetAction, saved.Action)
+	assert.False(t, saved.Succeeded)
+	assert.Equal(t, constants.InvalidPinResetCode, saved.Reason)
+	assert.Equal(t, "10.0.0.1", saved.ClientIP)
+}
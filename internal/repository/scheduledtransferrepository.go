@@ -0,0 +1,141 @@
+package repository
+
+import (
+	"bankingApp/internal/model"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type ScheduledTransferRepository struct {
+	db *gorm.DB
+}
+
+// NewScheduledTransferRepository creates a new instance of ScheduledTransferRepository
+func NewScheduledTransferRepository(db *gorm.DB) *ScheduledTransferRepository {
+	return &ScheduledTransferRepository{db: db}
+}
+
+// SaveScheduledTransfer inserts a new scheduled transfer
+func (s *ScheduledTransferRepository) SaveScheduledTransfer(scheduled *model.ScheduledTransfer) error {
+	return s.db.Create(scheduled).Error
+}
+
+// FindScheduledTransfer retrieves a scheduled transfer by ID, returning an empty one when it does not exist
+func (s *ScheduledTransferRepository) FindScheduledTransfer(id uint) (*model.ScheduledTransfer, error) {
+	var scheduled model.ScheduledTransfer
+	err := s.db.
+		Where(&model.ScheduledTransfer{ScheduledTransferID: id}).
+		Find(&scheduled).
+		Error
+	return &scheduled, err
+}
+
+// FindScheduledTransferByReference retrieves the scheduled transfer with the given payment reference
+func (s *ScheduledTransferRepository) FindScheduledTransferByReference(reference string) (*model.ScheduledTransfer, error) {
+	var scheduled model.ScheduledTransfer
+	err := s.db.
+		Where(&model.ScheduledTransfer{PaymentReference: reference}).
+		Find(&scheduled).
+		Error
+	return &scheduled, err
+}
+
+// FindScheduledTransfersByAccount lists the scheduled transfers of an account, soonest first
+func (s *ScheduledTransferRepository) FindScheduledTransfersByAccount(accountID uint) ([]model.ScheduledTransfer, error) {
+	var scheduled []model.ScheduledTransfer
+	err := s.db.
+		Where(&model.ScheduledTransfer{AccountID: accountID}).
+		Order("execute_at, scheduled_transfer_id").
+		Find(&scheduled).
+		Error
+	return scheduled, err
+}
+
+// ClaimDueScheduledTransfers moves up to limit due scheduled transfers to executing and returns them.
+// Each one is claimed with a conditional update, so running several workers never executes a transfer twice.
+func (s *ScheduledTransferRepository) ClaimDueScheduledTransfers(now time.Time, limit int) ([]model.ScheduledTransfer, error) {
+	var due []model.ScheduledTransfer
+	err := s.db.
+		Where("status = ? AND execute_at <= ?", model.ScheduleActive, now).
+		Order("execute_at, scheduled_transfer_id").
+		Limit(limit).
+		Find(&due).
+		Error
+	if err != nil {
+		return nil, err
+	}
+
+	claimed := make([]model.ScheduledTransfer, 0, len(due))
+	for _, scheduled := range due {
+		ok, err := s.changeStatus(scheduled.ScheduledTransferID, model.ScheduleActive, model.ScheduleExecuting)
+		if err != nil {
+			return claimed, err
+		}
+		if ok {
+			scheduled.Status = model.ScheduleExecuting
+			claimed = append(claimed, scheduled)
+		}
+	}
+	return claimed, nil
+}
+
+// ClaimStalledScheduledTransfers takes over up to limit scheduled transfers left executing by a worker that stopped,
+// oldest first. A transfer is stalled when it has been executing since before stalledBefore. Claiming one moves its
+// updated_at on with a conditional update, so running several workers never resumes a transfer twice.
+func (s *ScheduledTransferRepository) ClaimStalledScheduledTransfers(stalledBefore time.Time, limit int) ([]model.ScheduledTransfer, error) {
+	var stalled []model.ScheduledTransfer
+	err := s.db.
+		Where("status = ? AND updated_at < ?", model.ScheduleExecuting, stalledBefore).
+		Order("execute_at, scheduled_transfer_id").
+		Limit(limit).
+		Find(&stalled).
+		Error
+	if err != nil {
+		return nil, err
+	}
+
+	claimed := make([]model.ScheduledTransfer, 0, len(stalled))
+	for _, scheduled := range stalled {
+		result := s.db.Model(&model.ScheduledTransfer{}).
+			Where("scheduled_transfer_id = ? AND status = ? AND updated_at < ?",
+				scheduled.ScheduledTransferID, model.ScheduleExecuting, stalledBefore).
+			UpdateColumn("updated_at", time.Now())
+		if result.Error != nil {
+			return claimed, result.Error
+		}
+		if result.RowsAffected == 1 {
+			claimed = append(claimed, scheduled)
+		}
+	}
+	return claimed, nil
+}
+
+// UpdateScheduledTransfer stores the outcome of an execution attempt
+func (s *ScheduledTransferRepository) UpdateScheduledTransfer(scheduled *model.ScheduledTransfer) error {
+	return s.db.Model(&model.ScheduledTransfer{}).
+		Where(&model.ScheduledTransfer{ScheduledTransferID: scheduled.ScheduledTransferID}).
+		UpdateColumns(map[string]interface{}{
+			"status":          scheduled.Status,
+			"attempts":        scheduled.Attempts,
+			"failure_reason":  scheduled.FailureReason,
+			"last_attempt_at": scheduled.LastAttemptAt,
+			"updated_at":      time.Now(),
+		}).Error
+}
+
+// CancelScheduledTransfer cancels a scheduled transfer that has not been picked up yet,
+// reporting false when it is already running or finished
+func (s *ScheduledTransferRepository) CancelScheduledTransfer(id uint) (bool, error) {
+	return s.changeStatus(id, model.ScheduleActive, model.ScheduleCancelled)
+}
+
+func (s *ScheduledTransferRepository) changeStatus(id uint, from, to model.ScheduledTransferStatus) (bool, error) {
+	result := s.db.Model(&model.ScheduledTransfer{}).
+		Where("scheduled_transfer_id = ? AND status = ?", id, from).
+		UpdateColumns(map[string]interface{}{
+			"status":     to,
+			"updated_at": time.Now(),
+		})
+	return result.RowsAffected == 1, result.Error
+}
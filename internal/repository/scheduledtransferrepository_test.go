@@ -0,0 +1,113 @@
+package repository
+
+import (
+	"bankingApp/internal/model"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/govalues/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func createScheduledTransfer(t *testing.T, repository *ScheduledTransferRepository, i int, executeAt time.Time) *model.ScheduledTransfer {
+	scheduled := &model.ScheduledTransfer{
+		AccountID:        1,
+		AccountNumber:    "1234567890",
+		PaymentReference: fmt.Sprintf("scheduled%d", i),
+		Amount:           model.BigDecimal{Decimal: decimal.MustParse("10.00")},
+		Type:             model.DebitTransaction,
+		ExecuteAt:        executeAt,
+		Status:           model.ScheduleActive,
+	}
+	require.NoError(t, repository.SaveScheduledTransfer(scheduled))
+	return scheduled
+}
+
+func Test_ClaimDueScheduledTransfersClaimsEachTransferOnce(t *testing.T) {
+	repository := NewScheduledTransferRepository(openTestDB(t))
+	now := time.Now()
+	first := createScheduledTransfer(t, repository, 1, now.Add(-time.Hour))
+	second := createScheduledTransfer(t, repository, 2, now.Add(-time.Minute))
+	createScheduledTransfer(t, repository, 3, now.Add(time.Hour))
+
+	claimed, err := repository.ClaimDueScheduledTransfers(now, 10)
+	require.NoError(t, err)
+	require.Len(t, claimed, 2)
+	assert.Equal(t, first.ScheduledTransferID, claimed[0].ScheduledTransferID)
+	assert.Equal(t, second.ScheduledTransferID, claimed[1].ScheduledTransferID)
+	assert.Equal(t, model.ScheduleExecuting, claimed[0].Status)
+
+	claimed, err = repository.ClaimDueScheduledTransfers(now, 10)
+	require.NoError(t, err)
+	assert.Empty(t, claimed)
+}
+
+func Test_ClaimStalledScheduledTransfersResumesEachTransferOnce(t *testing.T) {
+	db := openTestDB(t)
+	repository := NewScheduledTransferRepository(db)
+	now := time.Now()
+	stalled := createScheduledTransfer(t, repository, 1, now.Add(-time.Hour))
+	running := createScheduledTransfer(t, repository, 2, now.Add(-time.Hour))
+	createScheduledTransfer(t, repository, 3, now.Add(-time.Hour))
+	_, err := repository.ClaimDueScheduledTransfers(now, 2)
+	require.NoError(t, err)
+
+	require.NoError(t, db.Model(&model.ScheduledTransfer{}).
+		Where("scheduled_transfer_id = ?", stalled.ScheduledTransferID).
+		UpdateColumn("updated_at", now.Add(-time.Hour)).Error)
+
+	claimed, err := repository.ClaimStalledScheduledTransfers(now.Add(-time.Minute), 10)
+	require.NoError(t, err)
+	require.Len(t, claimed, 1)
+	assert.Equal(t, stalled.ScheduledTransferID, claimed[0].ScheduledTransferID)
+	assert.NotEqual(t, running.ScheduledTransferID, claimed[0].ScheduledTransferID)
+
+	claimed, err = repository.ClaimStalledScheduledTransfers(now.Add(-time.Minute), 10)
+	require.NoError(t, err)
+	assert.Empty(t, claimed)
+}
+
+func Test_CancelScheduledTransferOnlyCancelsTransfersThatHaveNotRun(t *testing.T) {
+	repository := NewScheduledTransferRepository(openTestDB(t))
+	now := time.Now()
+	due := createScheduledTransfer(t, repository, 1, now.Add(-time.Minute))
+	later := createScheduledTransfer(t, repository, 2, now.Add(time.Hour))
+
+	_, err := repository.ClaimDueScheduledTransfers(now, 10)
+	require.NoError(t, err)
+
+	cancelled, err := repository.CancelScheduledTransfer(due.ScheduledTransferID)
+	require.NoError(t, err)
+	assert.False(t, cancelled)
+
+	cancelled, err = repository.CancelScheduledTransfer(later.ScheduledTransferID)
+	require.NoError(t, err)
+	assert.True(t, cancelled)
+
+	scheduled, err := repository.FindScheduledTransfersByAccount(1)
+	require.NoError(t, err)
+	require.Len(t, scheduled, 2)
+	assert.Equal(t, model.ScheduleExecuting, scheduled[0].Status)
+	assert.Equal(t, model.ScheduleCancelled, scheduled[1].Status)
+}
+
+func Test_UpdateScheduledTransferRecordsFailure(t *testing.T) {
+	repository := NewScheduledTransferRepository(openTestDB(t))
+	scheduled := createScheduledTransfer(t, repository, 1, time.Now())
+
+	attemptedAt := time.Now()
+	scheduled.Status = model.ScheduleFailed
+	scheduled.Attempts = 1
+	scheduled.FailureReason = "insufficient funds"
+	scheduled.LastAttemptAt = &attemptedAt
+	require.NoError(t, repository.UpdateScheduledTransfer(scheduled))
+
+	reloaded, err := repository.FindScheduledTransfer(scheduled.ScheduledTransferID)
+	require.NoError(t, err)
+	assert.Equal(t, model.ScheduleFailed, reloaded.Status)
+	assert.Equal(t, 1, reloaded.Attempts)
+	assert.Equal(t, "insufficient funds", reloaded.FailureReason)
+	assert.NotNil(t, reloaded.LastAttemptAt)
+}
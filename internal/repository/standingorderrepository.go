@@ -0,0 +1,167 @@
+package repository
+
+import (
+	"bankingApp/internal/model"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type StandingOrderRepository struct {
+	db *gorm.DB
+}
+
+// NewStandingOrderRepository creates a new instance of StandingOrderRepository
+func NewStandingOrderRepository(db *gorm.DB) *StandingOrderRepository {
+	return &StandingOrderRepository{db: db}
+}
+
+// SaveStandingOrder inserts a new standing order
+func (s *StandingOrderRepository) SaveStandingOrder(order *model.StandingOrder) error {
+	return s.db.Create(order).Error
+}
+
+// FindStandingOrder retrieves a standing order by ID, returning an empty one when it does not exist
+func (s *StandingOrderRepository) FindStandingOrder(id uint) (*model.StandingOrder, error) {
+	var order model.StandingOrder
+	err := s.db.
+		Where(&model.StandingOrder{StandingOrderID: id}).
+		Find(&order).
+		Error
+	return &order, err
+}
+
+// FindStandingOrderByReference retrieves the standing order with the given payment reference
+func (s *StandingOrderRepository) FindStandingOrderByReference(reference string) (*model.StandingOrder, error) {
+	var order model.StandingOrder
+	err := s.db.
+		Where(&model.StandingOrder{Reference: reference}).
+		Find(&order).
+		Error
+	return &order, err
+}
+
+// FindStandingOrdersByAccount lists the standing orders of an account in the order they were set up
+func (s *StandingOrderRepository) FindStandingOrdersByAccount(accountID uint) ([]model.StandingOrder, error) {
+	var orders []model.StandingOrder
+	err := s.db.
+		Where(&model.StandingOrder{AccountID: accountID}).
+		Order("standing_order_id").
+		Find(&orders).
+		Error
+	return orders, err
+}
+
+// FindStandingOrderExecutions lists the execution history of a standing order, oldest first
+func (s *StandingOrderRepository) FindStandingOrderExecutions(id uint) ([]model.StandingOrderExecution, error) {
+	var executions []model.StandingOrderExecution
+	err := s.db.
+		Where(&model.StandingOrderExecution{StandingOrderID: id}).
+		Order("execution_id").
+		Find(&executions).
+		Error
+	return executions, err
+}
+
+// ClaimDueStandingOrders moves up to limit active standing orders whose next run is due to executing and returns them.
+// Each one is claimed with a conditional update, so running several workers never runs an occurrence twice.
+func (s *StandingOrderRepository) ClaimDueStandingOrders(now time.Time, limit int) ([]model.StandingOrder, error) {
+	var due []model.StandingOrder
+	err := s.db.
+		Where("status = ? AND next_run_at <= ?", model.StandingOrderActive, now).
+		Order("next_run_at, standing_order_id").
+		Limit(limit).
+		Find(&due).
+		Error
+	if err != nil {
+		return nil, err
+	}
+
+	claimed := make([]model.StandingOrder, 0, len(due))
+	for _, order := range due {
+		ok, err := s.changeStatus(order.StandingOrderID, model.StandingOrderActive, model.StandingOrderExecuting)
+		if err != nil {
+			return claimed, err
+		}
+		if ok {
+			order.Status = model.StandingOrderExecuting
+			claimed = append(claimed, order)
+		}
+	}
+	return claimed, nil
+}
+
+// ClaimStalledStandingOrders takes over up to limit standing orders left executing by a worker that stopped,
+// oldest run first. An order is stalled when it has been executing since before stalledBefore. Claiming one moves
+// its updated_at on with a conditional update, so running several workers never resumes an occurrence twice.
+func (s *StandingOrderRepository) ClaimStalledStandingOrders(stalledBefore time.Time, limit int) ([]model.StandingOrder, error) {
+	var stalled []model.StandingOrder
+	err := s.db.
+		Where("status = ? AND updated_at < ?", model.StandingOrderExecuting, stalledBefore).
+		Order("next_run_at, standing_order_id").
+		Limit(limit).
+		Find(&stalled).
+		Error
+	if err != nil {
+		return nil, err
+	}
+
+	claimed := make([]model.StandingOrder, 0, len(stalled))
+	for _, order := range stalled {
+		result := s.db.Model(&model.StandingOrder{}).
+			Where("standing_order_id = ? AND status = ? AND updated_at < ?",
+				order.StandingOrderID, model.StandingOrderExecuting, stalledBefore).
+			UpdateColumn("updated_at", time.Now())
+		if result.Error != nil {
+			return claimed, result.Error
+		}
+		if result.RowsAffected == 1 {
+			claimed = append(claimed, order)
+		}
+	}
+	return claimed, nil
+}
+
+// RecordStandingOrderExecution stores an execution attempt together with the state the standing order moves on to
+func (s *StandingOrderRepository) RecordStandingOrderExecution(
+	order *model.StandingOrder,
+	execution *model.StandingOrderExecution) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(execution).Error; err != nil {
+			return err
+		}
+		return tx.Model(&model.StandingOrder{}).
+			Where(&model.StandingOrder{StandingOrderID: order.StandingOrderID}).
+			UpdateColumns(map[string]interface{}{
+				"status":             order.Status,
+				"occurrences":        order.Occurrences,
+				"retry_count":        order.RetryCount,
+				"next_occurrence_at": order.NextOccurrenceAt,
+				"next_run_at":        order.NextRunAt,
+				"failure_reason":     order.FailureReason,
+				"updated_at":         time.Now(),
+			}).Error
+	})
+}
+
+// CancelStandingOrder cancels an active standing order, reporting false when it is running or already finished
+func (s *StandingOrderRepository) CancelStandingOrder(id uint) (bool, error) {
+	result := s.db.Model(&model.StandingOrder{}).
+		Where("standing_order_id = ? AND status = ?", id, model.StandingOrderActive).
+		UpdateColumns(map[string]interface{}{
+			"status":      model.StandingOrderCancelled,
+			"next_run_at": nil,
+			"updated_at":  time.Now(),
+		})
+	return result.RowsAffected == 1, result.Error
+}
+
+func (s *StandingOrderRepository) changeStatus(id uint, from, to model.StandingOrderStatus) (bool, error) {
+	result := s.db.Model(&model.StandingOrder{}).
+		Where("standing_order_id = ? AND status = ?", id, from).
+		UpdateColumns(map[string]interface{}{
+			"status":     to,
+			"updated_at": time.Now(),
+		})
+	return result.RowsAffected == 1, result.Error
+}
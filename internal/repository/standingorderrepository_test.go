@@ -0,0 +1,133 @@
+package repository
+
+import (
+	"bankingApp/internal/model"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/govalues/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func createStandingOrder(t *testing.T, repository *StandingOrderRepository, i int, nextRunAt time.Time) *model.StandingOrder {
+	order := &model.StandingOrder{
+		AccountID:           1,
+		AccountNumber:       "1234567890",
+		Reference:           fmt.Sprintf("standing%d", i),
+		Amount:              model.BigDecimal{Decimal: decimal.MustParse("10.00")},
+		Type:                model.DebitTransaction,
+		Frequency:           "monthly",
+		Interval:            1,
+		StartAt:             nextRunAt,
+		OnInsufficientFunds: model.SkipOnInsufficientFunds,
+		NextOccurrenceAt:    &nextRunAt,
+		NextRunAt:           &nextRunAt,
+		Status:              model.StandingOrderActive,
+	}
+	require.NoError(t, repository.SaveStandingOrder(order))
+	return order
+}
+
+func Test_ClaimDueStandingOrdersClaimsEachOrderOnce(t *testing.T) {
+	repository := NewStandingOrderRepository(openTestDB(t))
+	now := time.Now()
+	first := createStandingOrder(t, repository, 1, now.Add(-time.Hour))
+	second := createStandingOrder(t, repository, 2, now.Add(-time.Minute))
+	createStandingOrder(t, repository, 3, now.Add(time.Hour))
+
+	claimed, err := repository.ClaimDueStandingOrders(now, 10)
+	require.NoError(t, err)
+	require.Len(t, claimed, 2)
+	assert.Equal(t, first.StandingOrderID, claimed[0].StandingOrderID)
+	assert.Equal(t, second.StandingOrderID, claimed[1].StandingOrderID)
+	assert.Equal(t, model.StandingOrderExecuting, claimed[0].Status)
+
+	claimed, err = repository.ClaimDueStandingOrders(now, 10)
+	require.NoError(t, err)
+	assert.Empty(t, claimed)
+}
+
+func Test_ClaimStalledStandingOrdersResumesEachOrderOnce(t *testing.T) {
+	db := openTestDB(t)
+	repository := NewStandingOrderRepository(db)
+	now := time.Now()
+	stalled := createStandingOrder(t, repository, 1, now.Add(-time.Hour))
+	running := createStandingOrder(t, repository, 2, now.Add(-time.Hour))
+	createStandingOrder(t, repository, 3, now.Add(-time.Hour))
+	_, err := repository.ClaimDueStandingOrders(now, 2)
+	require.NoError(t, err)
+
+	require.NoError(t, db.Model(&model.StandingOrder{}).
+		Where("standing_order_id = ?", stalled.StandingOrderID).
+		UpdateColumn("updated_at", now.Add(-time.Hour)).Error)
+
+	claimed, err := repository.ClaimStalledStandingOrders(now.Add(-time.Minute), 10)
+	require.NoError(t, err)
+	require.Len(t, claimed, 1)
+	assert.Equal(t, stalled.StandingOrderID, claimed[0].StandingOrderID)
+	assert.NotEqual(t, running.StandingOrderID, claimed[0].StandingOrderID)
+
+	claimed, err = repository.ClaimStalledStandingOrders(now.Add(-time.Minute), 10)
+	require.NoError(t, err)
+	assert.Empty(t, claimed)
+}
+
+func Test_RecordStandingOrderExecutionStoresHistoryAndNextRun(t *testing.T) {
+	repository := NewStandingOrderRepository(openTestDB(t))
+	now := time.Now()
+	order := createStandingOrder(t, repository, 1, now.Add(-time.Minute))
+	claimed, err := repository.ClaimDueStandingOrders(now, 10)
+	require.NoError(t, err)
+	require.Len(t, claimed, 1)
+
+	next := now.AddDate(0, 1, 0)
+	claimed[0].Status = model.StandingOrderActive
+	claimed[0].Occurrences = 1
+	claimed[0].NextOccurrenceAt, claimed[0].NextRunAt = &next, &next
+	require.NoError(t, repository.RecordStandingOrderExecution(&claimed[0], &model.StandingOrderExecution{
+		StandingOrderID:  order.StandingOrderID,
+		Occurrence:       1,
+		Attempt:          1,
+		ScheduledFor:     now.Add(-time.Minute),
+		PaymentReference: order.OccurrenceReference(1),
+		Outcome:          model.StandingOrderRunExecuted,
+		ExecutedAt:       now,
+	}))
+
+	stored, err := repository.FindStandingOrder(order.StandingOrderID)
+	require.NoError(t, err)
+	assert.Equal(t, model.StandingOrderActive, stored.Status)
+	assert.Equal(t, 1, stored.Occurrences)
+	assert.WithinDuration(t, next, *stored.NextRunAt, time.Second)
+
+	executions, err := repository.FindStandingOrderExecutions(order.StandingOrderID)
+	require.NoError(t, err)
+	require.Len(t, executions, 1)
+	assert.Equal(t, "standing1-1", executions[0].PaymentReference)
+	assert.Equal(t, model.StandingOrderRunExecuted, executions[0].Outcome)
+}
+
+func Test_CancelStandingOrderOnlyCancelsActiveOrders(t *testing.T) {
+	repository := NewStandingOrderRepository(openTestDB(t))
+	now := time.Now()
+	due := createStandingOrder(t, repository, 1, now.Add(-time.Minute))
+	later := createStandingOrder(t, repository, 2, now.Add(time.Hour))
+
+	_, err := repository.ClaimDueStandingOrders(now, 10)
+	require.NoError(t, err)
+
+	cancelled, err := repository.CancelStandingOrder(due.StandingOrderID)
+	require.NoError(t, err)
+	assert.False(t, cancelled)
+
+	cancelled, err = repository.CancelStandingOrder(later.StandingOrderID)
+	require.NoError(t, err)
+	assert.True(t, cancelled)
+
+	stored, err := repository.FindStandingOrder(later.StandingOrderID)
+	require.NoError(t, err)
+	assert.Equal(t, model.StandingOrderCancelled, stored.Status)
+	assert.Nil(t, stored.NextRunAt)
+}
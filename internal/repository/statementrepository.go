@@ -0,0 +1,110 @@
+package repository
+
+import (
+	"bankingApp/internal/model"
+	"database/sql"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// signedPostingAmount is the amount of a posting as it moves the balance of the account it is made against
+const signedPostingAmount = "CASE WHEN tbl_posting.direction = ? THEN tbl_posting.amount ELSE -tbl_posting.amount END"
+
+type StatementRepository struct {
+	db *gorm.DB
+}
+
+// NewStatementRepository creates a new instance of StatementRepository
+func NewStatementRepository(db *gorm.DB) *StatementRepository {
+	return &StatementRepository{db: db}
+}
+
+// BalanceBefore returns the balance of the account from the postings made against it before the given time
+func (s *StatementRepository) BalanceBefore(accountID uint, before time.Time) (model.BigDecimal, error) {
+	return balanceBefore(s.db, accountID, before)
+}
+
+func balanceBefore(db *gorm.DB, accountID uint, before time.Time) (model.BigDecimal, error) {
+	var balance model.BigDecimal
+	err := postingsBefore(db, before).
+		Select("COALESCE(SUM("+signedPostingAmount+"), 0)", model.CreditEntry).
+		Where("tbl_posting.account_id = ?", accountID).
+		Row().
+		Scan(&balance.Decimal)
+	return balance, err
+}
+
+// postingsBefore selects the postings of the journal entries posted before the given time
+func postingsBefore(db *gorm.DB, before time.Time) *gorm.DB {
+	return db.Table("tbl_posting").
+		Joins("JOIN tbl_journal_entry ON tbl_journal_entry.journal_entry_id = tbl_posting.journal_entry_id").
+		Where("tbl_journal_entry.posted_at < ?", before)
+}
+
+// EachStatementEntry reads the journal entries posted against the account from from up to before to, oldest
+// first, and calls fn with the net effect of each on the account. Postings are read one row at a time so
+// statements over long periods are never held in memory.
+func (s *StatementRepository) EachStatementEntry(
+	accountID uint,
+	from, to time.Time,
+	fn func(entry *model.StatementEntry) error) error {
+	rows, err := s.db.Table("tbl_posting").
+		Select("tbl_journal_entry.journal_entry_id, tbl_journal_entry.reference, tbl_journal_entry.description, "+
+			"tbl_journal_entry.posted_at, tbl_posting.direction, tbl_posting.amount, tbl_transaction.reference, "+
+			"tbl_transaction.payment_reference, tbl_transaction.type, tbl_transaction.status").
+		Joins("JOIN tbl_journal_entry ON tbl_journal_entry.journal_entry_id = tbl_posting.journal_entry_id").
+		// the legs of internal transfers and their reversals are posted under the correlation reference
+		Joins("LEFT JOIN tbl_transaction ON tbl_transaction.account_id = tbl_posting.account_id AND "+
+			"(tbl_transaction.reference = tbl_journal_entry.reference OR "+
+			"tbl_transaction.correlation_reference = tbl_journal_entry.reference)").
+		Where("tbl_posting.account_id = ? AND tbl_journal_entry.posted_at >= ? AND tbl_journal_entry.posted_at < ?",
+			accountID, from, to).
+		Order("tbl_journal_entry.posted_at, tbl_journal_entry.journal_entry_id, tbl_posting.posting_id").
+		Rows()
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	var current *model.StatementEntry
+	for rows.Next() {
+		var (
+			row                                       model.StatementEntry
+			direction                                 model.EntryDirection
+			reference, paymentReference, kind, status sql.NullString
+		)
+		err := rows.Scan(&row.JournalEntryID, &row.Reference, &row.Description, &row.PostedAt, &direction,
+			&row.Amount.Decimal, &reference, &paymentReference, &kind, &status)
+		if err != nil {
+			return err
+		}
+		if direction == model.DebitEntry {
+			row.Amount.Decimal = row.Amount.Decimal.Neg()
+		}
+
+		if current != nil && current.JournalEntryID == row.JournalEntryID {
+			if current.Amount.Decimal, err = current.Amount.Decimal.Add(row.Amount.Decimal); err != nil {
+				return err
+			}
+			continue
+		}
+		if current != nil {
+			if err := fn(current); err != nil {
+				return err
+			}
+		}
+		row.TransactionReference = reference.String
+		row.PaymentReference = paymentReference.String
+		row.Type = model.TransactionType(kind.String)
+		row.Status = model.TransactionStatus(status.String)
+		current = &row
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	if current != nil {
+		return fn(current)
+	}
+	return nil
+}
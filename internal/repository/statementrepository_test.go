@@ -0,0 +1,82 @@
+package repository
+
+import (
+	"bankingApp/internal/ledger"
+	"bankingApp/internal/model"
+	"testing"
+	"time"
+
+	"github.com/govalues/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func setPostedAt(t *testing.T, db *gorm.DB, reference string, postedAt time.Time) {
+	require.NoError(t, db.Model(&model.JournalEntry{}).
+		Where(&model.JournalEntry{Reference: reference}).
+		Update("posted_at", postedAt).Error)
+}
+
+func Test_StatementEntriesNetThePostingsOfEachJournalEntry(t *testing.T) {
+	db := openTestDB(t)
+	account := createTestAccount(t, db, "1234567890", "1000.00")
+	journal := NewJournalRepository(db)
+	repository := NewStatementRepository(db)
+
+	require.NoError(t, journal.PostOpeningBalances())
+	payment := &model.Transaction{
+		AccountID:        account.AccountID,
+		Reference:        "ref1",
+		PaymentReference: "payment1",
+		Amount:           model.BigDecimal{Decimal: decimal.MustParse("150.00")},
+		Fee:              model.BigDecimal{Decimal: decimal.MustParse("10.00")},
+		Type:             model.DebitTransaction,
+		Status:           model.SucceededStatus,
+	}
+	entry, err := ledger.TransactionEntry(payment)
+	require.NoError(t, err)
+	require.NoError(t, journal.PostJournalEntry(entry, payment))
+	require.NoError(t, NewUnitOfWork(db).Execute(func(tx ITx) error {
+		interest := model.BigDecimal{Decimal: decimal.MustParse("0.25")}
+		entry, err := ledger.OverdraftInterestEntry("interest1", account.AccountID, "", interest)
+		if err != nil {
+			return err
+		}
+		return tx.PostCharge(entry)
+	}))
+
+	setPostedAt(t, db, "opening-1234567890", time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC))
+	setPostedAt(t, db, "ref1", time.Date(2024, 3, 5, 12, 0, 0, 0, time.UTC))
+	setPostedAt(t, db, "interest1", time.Date(2024, 4, 2, 0, 5, 0, 0, time.UTC))
+
+	opening, err := repository.BalanceBefore(account.AccountID, time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	assert.True(t, opening.Decimal.IsZero())
+
+	var entries []model.StatementEntry
+	err = repository.EachStatementEntry(account.AccountID,
+		time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+		time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC),
+		func(entry *model.StatementEntry) error {
+			entries = append(entries, *entry)
+			return nil
+		})
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+
+	assert.Equal(t, "opening-1234567890", entries[0].Reference)
+	assert.Equal(t, 0, entries[0].Amount.Decimal.Cmp(decimal.MustParse("1000.00")))
+	assert.Empty(t, entries[0].TransactionReference)
+
+	assert.Equal(t, "ref1", entries[1].TransactionReference)
+	assert.Equal(t, "payment1", entries[1].PaymentReference)
+	assert.Equal(t, model.DebitTransaction, entries[1].Type)
+	assert.Equal(t, model.SucceededStatus, entries[1].Status)
+	assert.Equal(t, 0, entries[1].Amount.Decimal.Cmp(decimal.MustParse("-160.00")))
+
+	closing, err := repository.BalanceBefore(account.AccountID, time.Date(2024, 4, 3, 0, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	assert.Equal(t, 0, closing.Decimal.Cmp(reloadBalance(t, db, account.AccountID)))
+	assert.Equal(t, 0, closing.Decimal.Cmp(decimal.MustParse("839.75")))
+}
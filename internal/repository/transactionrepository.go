@@ -2,10 +2,15 @@ package repository
 
 import (
 	"bankingApp/internal/model"
+	"strings"
+	"time"
 
 	"gorm.io/gorm"
 )
 
+// likeEscaper escapes the wildcards of LIKE patterns with the escape character given to the database
+var likeEscaper = strings.NewReplacer("!", "!!", "%", "!%", "_", "!_")
+
 type TransactionRepository struct { // TransactionRepository definition
 	db *gorm.DB
 }
@@ -35,14 +40,81 @@ func (t *TransactionRepository) FindTransactionByReference(reference string) (*m
 	return &transaction, err
 }
 
-// GetLastInsertID returns the last inserted transaction ID from the database.
-func (t *TransactionRepository) GetLastInsertID() (uint, error) {
-	var transaction model.Transaction
-	err := t.db.Order("transaction_id DESC").Limit(1).Find(&transaction).Error
-	if err != nil {
-		return 0, err
+// FindStatusHistory returns the status changes of a transaction, oldest first
+func (t *TransactionRepository) FindStatusHistory(transactionID uint) ([]model.TransactionStatusHistory, error) {
+	var history []model.TransactionStatusHistory
+	err := t.db.
+		Where(&model.TransactionStatusHistory{TransactionID: transactionID}).
+		Order("transaction_status_history_id").
+		Find(&history).
+		Error
+	return history, err
+}
+
+// FindStaleTransactions lists up to limit transactions still waiting for their outcome that were last changed
+// before the given time, oldest first
+func (t *TransactionRepository) FindStaleTransactions(before time.Time, limit int) ([]model.Transaction, error) {
+	var transactions []model.Transaction
+	err := t.db.
+		Where("status IN ? AND updated_at < ?",
+			[]model.TransactionStatus{model.PendingStatus, model.SubmittedStatus, model.UnknownStatus}, before).
+		Order("updated_at, transaction_id").
+		Limit(limit).
+		Find(&transactions).
+		Error
+	return transactions, err
+}
+
+// FindAccountTransactions lists up to filter.Limit transactions of the account that match the filter,
+// newest first, starting after the cursor of the filter when it is set
+func (t *TransactionRepository) FindAccountTransactions(filter model.TransactionFilter) ([]model.Transaction, error) {
+	query := t.db.Where("account_id = ?", filter.AccountID)
+	if !filter.From.IsZero() {
+		query = query.Where("transaction_time >= ?", filter.From)
+	}
+	if !filter.To.IsZero() {
+		query = query.Where("transaction_time < ?", filter.To)
 	}
-	return transaction.TransactionID, nil
+	if filter.Type != "" {
+		query = query.Where("type = ?", filter.Type)
+	}
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	if filter.MinAmount != nil {
+		query = query.Where("amount >= ?", filter.MinAmount)
+	}
+	if filter.MaxAmount != nil {
+		query = query.Where("amount <= ?", filter.MaxAmount)
+	}
+	if filter.ReferencePrefix != "" {
+		query = query.Where("payment_reference LIKE ? ESCAPE '!'", likeEscaper.Replace(filter.ReferencePrefix)+"%")
+	}
+	if filter.After != nil {
+		query = query.Where("(transaction_time < ? OR (transaction_time = ? AND transaction_id < ?))",
+			filter.After.TransactionTime, filter.After.TransactionTime, filter.After.TransactionID)
+	}
+
+	var transactions []model.Transaction
+	err := query.
+		Order("transaction_time DESC, transaction_id DESC").
+		Limit(filter.Limit).
+		Find(&transactions).
+		Error
+	return transactions, err
+}
+
+// SumUnsettledDebits returns the amount and fees of the debits of the account that are posted
+// but still awaiting their outcome from the third-party provider
+func (t *TransactionRepository) SumUnsettledDebits(accountID uint) (model.BigDecimal, error) {
+	var total model.BigDecimal
+	err := t.db.Model(&model.Transaction{}).
+		Select("COALESCE(SUM(amount + fee), 0)").
+		Where("account_id = ? AND type = ? AND status IN ?", accountID, model.DebitTransaction,
+			[]model.TransactionStatus{model.PendingStatus, model.SubmittedStatus, model.UnknownStatus}).
+		Row().
+		Scan(&total.Decimal)
+	return total, err
 }
 
 // SaveTransaction saves the transaction details to the DB
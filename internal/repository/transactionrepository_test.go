@@ -0,0 +1,173 @@
+package repository
+
+import (
+	"bankingApp/internal/model"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/govalues/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func createTestTransaction(
+	t *testing.T,
+	db *gorm.DB,
+	accountID uint,
+	paymentReference string,
+	transactionType model.TransactionType,
+	amount string,
+	at time.Time) *model.Transaction {
+	transaction := &model.Transaction{
+		AccountID:        accountID,
+		Reference:        "ref-" + paymentReference,
+		PaymentReference: paymentReference,
+		Amount:           model.BigDecimal{Decimal: decimal.MustParse(amount)},
+		Type:             transactionType,
+		Status:           model.SucceededStatus,
+		TransactionTime:  at,
+	}
+	require.NoError(t, db.Create(transaction).Error)
+	return transaction
+}
+
+func paymentReferences(transactions []model.Transaction) []string {
+	references := make([]string, 0, len(transactions))
+	for _, transaction := range transactions {
+		references = append(references, transaction.PaymentReference)
+	}
+	return references
+}
+
+func Test_FindAccountTransactionsPagesNewestFirst(t *testing.T) {
+	db := openTestDB(t)
+	account := createTestAccount(t, db, "1234567890", "100.00")
+	other := createTestAccount(t, db, "1234567891", "100.00")
+	repository := NewTransactionRepository(db)
+	noon := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	// payment3 and payment4 share a transaction time, the cursor must still separate them
+	for i, at := range []time.Time{noon, noon.Add(time.Hour), noon.Add(2 * time.Hour), noon.Add(2 * time.Hour)} {
+		createTestTransaction(t, db, account.AccountID, fmt.Sprintf("payment%d", i+1), model.DebitTransaction, "10.00", at)
+	}
+	createTestTransaction(t, db, other.AccountID, "other", model.DebitTransaction, "10.00", noon)
+
+	filter := model.TransactionFilter{AccountID: account.AccountID, Limit: 3}
+	page, err := repository.FindAccountTransactions(filter)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"payment4", "payment3", "payment2"}, paymentReferences(page))
+
+	cursor, err := model.ParseTransactionCursor(model.CursorOf(&page[1]).Encode())
+	require.NoError(t, err)
+	filter.After = &cursor
+	page, err = repository.FindAccountTransactions(filter)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"payment2", "payment1"}, paymentReferences(page))
+}
+
+func Test_FindAccountTransactionsFilters(t *testing.T) {
+	db := openTestDB(t)
+	account := createTestAccount(t, db, "1234567890", "100.00")
+	repository := NewTransactionRepository(db)
+	march := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	createTestTransaction(t, db, account.AccountID, "INV-1", model.DebitTransaction, "10.00", march)
+	createTestTransaction(t, db, account.AccountID, "INV-2", model.CreditTransaction, "250.00", march.AddDate(0, 0, 1))
+	createTestTransaction(t, db, account.AccountID, "INV_3", model.DebitTransaction, "99.99", march.AddDate(0, 0, 2))
+	failed := createTestTransaction(t, db, account.AccountID, "rent", model.DebitTransaction, "500.00", march.AddDate(0, 1, 0))
+	require.NoError(t, db.Model(failed).Update("status", model.FailedStatus).Error)
+
+	amount := func(value string) *model.BigDecimal {
+		return &model.BigDecimal{Decimal: decimal.MustParse(value)}
+	}
+	testCases := []struct {
+		name     string
+		filter   model.TransactionFilter
+		expected []string
+	}{
+		{name: "type", filter: model.TransactionFilter{Type: model.CreditTransaction}, expected: []string{"INV-2"}},
+		{name: "status", filter: model.TransactionFilter{Status: model.FailedStatus}, expected: []string{"rent"}},
+		{
+			name:     "date range",
+			filter:   model.TransactionFilter{From: march.AddDate(0, 0, 1), To: march.AddDate(0, 0, 2)},
+			expected: []string{"INV-2"},
+		},
+		{
+			name:     "amount range",
+			filter:   model.TransactionFilter{MinAmount: amount("10"), MaxAmount: amount("99.99")},
+			expected: []string{"INV_3", "INV-1"},
+		},
+		{name: "reference prefix", filter: model.TransactionFilter{ReferencePrefix: "INV-"}, expected: []string{"INV-2", "INV-1"}},
+		{name: "wildcards are literal", filter: model.TransactionFilter{ReferencePrefix: "INV_"}, expected: []string{"INV_3"}},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.filter.AccountID = account.AccountID
+			tt.filter.Limit = 10
+			transactions, err := repository.FindAccountTransactions(tt.filter)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, paymentReferences(transactions))
+		})
+	}
+}
+
+func Test_SumUnsettledDebitsCountsDebitsAwaitingTheirOutcome(t *testing.T) {
+	db := openTestDB(t)
+	account := createTestAccount(t, db, "1234567890", "100.00")
+	repository := NewTransactionRepository(db)
+	now := time.Now()
+
+	statuses := map[string]model.TransactionStatus{
+		"pending":   model.PendingStatus,
+		"submitted": model.SubmittedStatus,
+		"unknown":   model.UnknownStatus,
+		"succeeded": model.SucceededStatus,
+		"failed":    model.FailedStatus,
+	}
+	for reference, status := range statuses {
+		transaction := createTestTransaction(t, db, account.AccountID, reference, model.DebitTransaction, "10.00", now)
+		require.NoError(t, db.Model(transaction).Updates(map[string]interface{}{"status": status, "fee": "0.50"}).Error)
+	}
+	createTestTransaction(t, db, account.AccountID, "credit", model.CreditTransaction, "99.00", now)
+	require.NoError(t, db.Model(&model.Transaction{}).Where("payment_reference = ?", "credit").
+		Update("status", model.PendingStatus).Error)
+
+	total, err := repository.SumUnsettledDebits(account.AccountID)
+	require.NoError(t, err)
+	assert.Equal(t, 0, total.Decimal.Cmp(decimal.MustParse("31.50")), total.Decimal.String())
+}
+
+func Test_FindStaleTransactionsListsTransactionsAwaitingTheirOutcome(t *testing.T) {
+	db := openTestDB(t)
+	account := createTestAccount(t, db, "1234567890", "100.00")
+	repository := NewTransactionRepository(db)
+	now := time.Now()
+
+	changes := []struct {
+		reference string
+		status    model.TransactionStatus
+		updatedAt time.Time
+	}{
+		{"unknown", model.UnknownStatus, now.Add(-2 * time.Hour)},
+		{"pending", model.PendingStatus, now.Add(-time.Hour)},
+		{"submitted", model.SubmittedStatus, now.Add(-time.Hour)},
+		{"recent", model.SubmittedStatus, now},
+		{"succeeded", model.SucceededStatus, now.Add(-time.Hour)},
+		{"failed", model.FailedStatus, now.Add(-time.Hour)},
+	}
+	for _, change := range changes {
+		transaction := createTestTransaction(t, db, account.AccountID, change.reference, model.DebitTransaction, "10.00", now)
+		require.NoError(t, db.Model(transaction).
+			UpdateColumns(map[string]interface{}{"status": change.status, "updated_at": change.updatedAt}).Error)
+	}
+
+	stale, err := repository.FindStaleTransactions(now.Add(-time.Minute), 10)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"unknown", "pending", "submitted"}, paymentReferences(stale))
+
+	stale, err = repository.FindStaleTransactions(now.Add(-time.Minute), 1)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"unknown"}, paymentReferences(stale))
+}
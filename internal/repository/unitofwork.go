@@ -0,0 +1,339 @@
+package repository
+
+import (
+	"bankingApp/internal/ledger"
+	"bankingApp/internal/model"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ITx exposes the repository operations that take part in a unit of work.
+// Everything done through it is committed together or rolled back together.
+type ITx interface {
+	LockAccount(accountID uint) (*model.Account, error)
+	PostJournalEntry(entry *model.JournalEntry) error
+	SaveTransaction(transaction *model.Transaction) error
+	UpdateTransactionStatus(transaction *model.Transaction, status model.TransactionStatus, reason string) error
+	LockTransaction(transactionID uint) (*model.Transaction, error)
+	UpdateReversedAmount(transaction *model.Transaction) error
+	UpdateHeldBalance(account *model.Account) error
+	SaveHold(hold *model.Hold) error
+	LockHold(holdID uint) (*model.Hold, error)
+	UpdateHold(hold *model.Hold) error
+	PostCharge(entry *model.JournalEntry) error
+	UpdateOverdraft(account *model.Account) error
+	SaveOverdraftChange(change *model.OverdraftChange) error
+	SaveOverdraftAccrual(accrual *model.OverdraftInterestAccrual) error
+	UpdateAccountStatus(account *model.Account) error
+	SaveAccountStatusChange(change *model.AccountStatusChange) error
+	SaveInterestAccrual(accrual *model.InterestAccrual) error
+	LockUncapitalisedAccruals(accountID uint, before time.Time) ([]model.InterestAccrual, error)
+	MarkAccrualsCapitalised(accruals []model.InterestAccrual, transactionID *uint, at time.Time) error
+	UpdateInterestCarried(account *model.Account) error
+	FindLimitUsage(accountID uint, day, month time.Time) (model.LimitUsage, error)
+}
+
+// ErrStaleTransactionStatus is returned when another process changed the status of a transaction first
+var ErrStaleTransactionStatus = errors.New("transaction status was changed concurrently")
+
+type UnitOfWork struct {
+	db *gorm.DB
+}
+
+// NewUnitOfWork creates a new instance of UnitOfWork
+func NewUnitOfWork(db *gorm.DB) *UnitOfWork {
+	return &UnitOfWork{db: db}
+}
+
+// Execute runs fn inside a single database transaction, committing when fn returns nil and rolling back otherwise
+func (u *UnitOfWork) Execute(fn func(tx ITx) error) error {
+	return u.db.Transaction(func(tx *gorm.DB) error {
+		return fn(&unitOfWorkTx{tx: tx, accounts: make(map[uint]*model.Account)})
+	})
+}
+
+type unitOfWorkTx struct {
+	tx       *gorm.DB
+	accounts map[uint]*model.Account
+}
+
+// LockAccount loads the account with SELECT ... FOR UPDATE so no other transaction can change
+// its balance until this unit of work commits. Repeated calls return the same locked account.
+func (u *unitOfWorkTx) LockAccount(accountID uint) (*model.Account, error) {
+	if account, ok := u.accounts[accountID]; ok {
+		return account, nil
+	}
+
+	var account model.Account
+	err := u.tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where(&model.Account{AccountID: accountID}).
+		First(&account).
+		Error
+	if err != nil {
+		return nil, err
+	}
+
+	u.accounts[accountID] = &account
+	return &account, nil
+}
+
+// PostJournalEntry locks the customer accounts touched by the entry, applies its postings to their balances
+// and stores the entry. Accounts are locked in ascending order so concurrent entries cannot deadlock.
+func (u *unitOfWorkTx) PostJournalEntry(entry *model.JournalEntry) error {
+	return u.post(entry, ledger.Apply)
+}
+
+// PostCharge posts an entry charging customer accounts like PostJournalEntry, except that the charge is
+// applied even when it takes an account beyond its overdraft limit
+func (u *unitOfWorkTx) PostCharge(entry *model.JournalEntry) error {
+	return u.post(entry, ledger.ApplyCharge)
+}
+
+func (u *unitOfWorkTx) post(entry *model.JournalEntry, apply func(*model.Account, model.Posting) error) error {
+	if err := ledger.Validate(entry); err != nil {
+		return err
+	}
+
+	for _, id := range postingAccountIDs(entry) {
+		if _, err := u.LockAccount(id); err != nil {
+			return err
+		}
+	}
+
+	for _, posting := range entry.Postings {
+		if posting.AccountID == 0 {
+			continue
+		}
+		if err := apply(u.accounts[posting.AccountID], posting); err != nil {
+			return err
+		}
+	}
+
+	for _, id := range postingAccountIDs(entry) {
+		if err := u.updateBalance(u.accounts[id]); err != nil {
+			return err
+		}
+	}
+
+	ledger.Stamp(entry, time.Now())
+	return u.tx.Create(entry).Error
+}
+
+// SaveTransaction inserts the transaction details and records its initial status in the status history
+func (u *unitOfWorkTx) SaveTransaction(transaction *model.Transaction) error {
+	if !transaction.Status.IsInitial() {
+		return fmt.Errorf("%w: cannot create a transaction as %q", model.ErrInvalidStatusTransition, transaction.Status)
+	}
+	transaction.Success = transaction.Status == model.SucceededStatus
+
+	if err := u.tx.Create(transaction).Error; err != nil {
+		return err
+	}
+	return u.saveStatusHistory(transaction.TransactionID, "", transaction.Status, "created")
+}
+
+// UpdateTransactionStatus moves the transaction to the given status and records the change in the status history.
+// The update only applies while the row still has the status the transaction was read with.
+func (u *unitOfWorkTx) UpdateTransactionStatus(
+	transaction *model.Transaction,
+	status model.TransactionStatus,
+	reason string) error {
+	from := transaction.Status
+	if err := transaction.TransitionTo(status); err != nil {
+		return err
+	}
+
+	result := u.tx.Model(&model.Transaction{}).
+		Where("transaction_id = ? AND status = ?", transaction.TransactionID, from).
+		UpdateColumns(map[string]interface{}{
+			"status":     transaction.Status,
+			"success":    transaction.Success,
+			"updated_at": time.Now(),
+		})
+	if result.Error != nil || result.RowsAffected == 0 {
+		transaction.Status, transaction.Success = from, from == model.SucceededStatus
+		if result.Error != nil {
+			return result.Error
+		}
+		return ErrStaleTransactionStatus
+	}
+	return u.saveStatusHistory(transaction.TransactionID, from, status, reason)
+}
+
+// LockTransaction loads the transaction with SELECT ... FOR UPDATE so concurrent reversals of it are serialised
+func (u *unitOfWorkTx) LockTransaction(transactionID uint) (*model.Transaction, error) {
+	var transaction model.Transaction
+	err := u.tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where(&model.Transaction{TransactionID: transactionID}).
+		First(&transaction).
+		Error
+	if err != nil {
+		return nil, err
+	}
+	return &transaction, nil
+}
+
+// UpdateReversedAmount stores the amount of the transaction that has been reversed so far
+func (u *unitOfWorkTx) UpdateReversedAmount(transaction *model.Transaction) error {
+	return u.tx.Model(&model.Transaction{}).Where(&model.Transaction{TransactionID: transaction.TransactionID}).
+		UpdateColumns(map[string]interface{}{
+			"reversed_amount": transaction.ReversedAmount,
+			"updated_at":      time.Now(),
+		}).Error
+}
+
+// UpdateHeldBalance stores the funds the active holds of the account reserve
+func (u *unitOfWorkTx) UpdateHeldBalance(account *model.Account) error {
+	return u.tx.Model(&model.Account{}).Where(model.Account{AccountID: account.AccountID}).
+		UpdateColumns(map[string]interface{}{
+			"held_balance": account.HeldBalance,
+			"updated_at":   time.Now(),
+		}).Error
+}
+
+// SaveHold inserts a new hold
+func (u *unitOfWorkTx) SaveHold(hold *model.Hold) error {
+	return u.tx.Create(hold).Error
+}
+
+// LockHold loads the hold with SELECT ... FOR UPDATE so it is captured, released or expired only once
+func (u *unitOfWorkTx) LockHold(holdID uint) (*model.Hold, error) {
+	var hold model.Hold
+	err := u.tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where(&model.Hold{HoldID: holdID}).
+		First(&hold).
+		Error
+	if err != nil {
+		return nil, err
+	}
+	return &hold, nil
+}
+
+// UpdateHold stores the status of the hold together with what was captured of it
+func (u *unitOfWorkTx) UpdateHold(hold *model.Hold) error {
+	return u.tx.Model(&model.Hold{}).Where(&model.Hold{HoldID: hold.HoldID}).
+		UpdateColumns(map[string]interface{}{
+			"status":          hold.Status,
+			"captured_amount": hold.CapturedAmount,
+			"transaction_id":  hold.TransactionID,
+			"updated_at":      time.Now(),
+		}).Error
+}
+
+// UpdateOverdraft stores the overdraft limit and interest rate of the account
+func (u *unitOfWorkTx) UpdateOverdraft(account *model.Account) error {
+	return u.tx.Model(&model.Account{}).Where(model.Account{AccountID: account.AccountID}).
+		UpdateColumns(map[string]interface{}{
+			"overdraft_limit": account.OverdraftLimit,
+			"overdraft_rate":  account.OverdraftRate,
+			"updated_at":      time.Now(),
+		}).Error
+}
+
+// SaveOverdraftChange records a change to the overdraft facility of an account in its audit trail
+func (u *unitOfWorkTx) SaveOverdraftChange(change *model.OverdraftChange) error {
+	return u.tx.Create(change).Error
+}
+
+// UpdateAccountStatus stores the lifecycle status of the account
+func (u *unitOfWorkTx) UpdateAccountStatus(account *model.Account) error {
+	return u.tx.Model(&model.Account{}).Where(model.Account{AccountID: account.AccountID}).
+		UpdateColumns(map[string]interface{}{
+			"status":     account.Status,
+			"updated_at": time.Now(),
+		}).Error
+}
+
+// SaveAccountStatusChange records a change to the status of an account in its audit trail
+func (u *unitOfWorkTx) SaveAccountStatusChange(change *model.AccountStatusChange) error {
+	return u.tx.Create(change).Error
+}
+
+// SaveOverdraftAccrual records the overdraft interest an account accrued for a day
+func (u *unitOfWorkTx) SaveOverdraftAccrual(accrual *model.OverdraftInterestAccrual) error {
+	return u.tx.Create(accrual).Error
+}
+
+// SaveInterestAccrual records the interest an account accrued for a day
+func (u *unitOfWorkTx) SaveInterestAccrual(accrual *model.InterestAccrual) error {
+	return u.tx.Create(accrual).Error
+}
+
+// LockUncapitalisedAccruals loads the accruals of the account dated before the given day that have not been
+// capitalised yet, with SELECT ... FOR UPDATE so they are capitalised only once
+func (u *unitOfWorkTx) LockUncapitalisedAccruals(accountID uint, before time.Time) ([]model.InterestAccrual, error) {
+	var accruals []model.InterestAccrual
+	err := u.tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("account_id = ? AND capitalised_at IS NULL AND accrual_date < ?", accountID, before).
+		Order("accrual_date").
+		Find(&accruals).
+		Error
+	return accruals, err
+}
+
+// MarkAccrualsCapitalised records that the accruals were capitalised at the given time into the transaction,
+// which is nil when the interest was too small to credit and is carried instead
+func (u *unitOfWorkTx) MarkAccrualsCapitalised(accruals []model.InterestAccrual, transactionID *uint, at time.Time) error {
+	ids := make([]uint, 0, len(accruals))
+	for _, accrual := range accruals {
+		ids = append(ids, accrual.InterestAccrualID)
+	}
+	return u.tx.Model(&model.InterestAccrual{}).Where("interest_accrual_id IN ?", ids).
+		UpdateColumns(map[string]interface{}{
+			"transaction_id": transactionID,
+			"capitalised_at": at,
+			"updated_at":     time.Now(),
+		}).Error
+}
+
+// UpdateInterestCarried stores the interest below the minor unit the account carries into its next capitalisation
+func (u *unitOfWorkTx) UpdateInterestCarried(account *model.Account) error {
+	return u.tx.Model(&model.Account{}).Where(model.Account{AccountID: account.AccountID}).
+		UpdateColumns(map[string]interface{}{
+			"interest_carried": account.InterestCarried,
+			"updated_at":       time.Now(),
+		}).Error
+}
+
+// FindLimitUsage returns the debits counted towards the limits of the account like LimitRepository.FindLimitUsage.
+// Read after the account is locked, it includes every debit committed before this unit of work got the lock.
+func (u *unitOfWorkTx) FindLimitUsage(accountID uint, day, month time.Time) (model.LimitUsage, error) {
+	return findLimitUsage(u.tx, accountID, day, month)
+}
+
+func (u *unitOfWorkTx) saveStatusHistory(transactionID uint, from, to model.TransactionStatus, reason string) error {
+	return u.tx.Create(&model.TransactionStatusHistory{
+		TransactionID: transactionID,
+		FromStatus:    from,
+		ToStatus:      to,
+		Reason:        reason,
+		ChangedAt:     time.Now(),
+	}).Error
+}
+
+func (u *unitOfWorkTx) updateBalance(account *model.Account) error {
+	return u.tx.Model(&model.Account{}).Where(model.Account{AccountID: account.AccountID}).
+		UpdateColumns(map[string]interface{}{
+			"balance":    account.Balance,
+			"updated_at": time.Now(),
+		}).Error
+}
+
+// postingAccountIDs returns the customer accounts of the entry in ascending order
+func postingAccountIDs(entry *model.JournalEntry) []uint {
+	seen := make(map[uint]bool)
+	var ids []uint
+	for _, posting := range entry.Postings {
+		if posting.AccountID != 0 && !seen[posting.AccountID] {
+			seen[posting.AccountID] = true
+			ids = append(ids, posting.AccountID)
+		}
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
@@ -0,0 +1,337 @@
+package repository
+
+import (
+	"bankingApp/internal/ledger"
+	"bankingApp/internal/model"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/glebarez/sqlite"
+	"github.com/govalues/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+	"gorm.io/gorm/schema"
+)
+
+// openTestDB opens an in-memory database with the application naming strategy. A single connection makes
+// SQLite run units of work one after another and SQLite has no row locks, so the concurrency tests check that
+// units of work do not lose updates but not that rows are locked. Test_LockQueriesSelectForUpdate checks the locks.
+func openTestDB(t *testing.T) *gorm.DB {
+	dsn := fmt.Sprintf("file:%s?mode=memory&cache=shared", t.Name())
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{
+		NamingStrategy: schema.NamingStrategy{SingularTable: true, TablePrefix: "tbl_"},
+		Logger:         logger.Default.LogMode(logger.Silent),
+	})
+	require.NoError(t, err)
+
+	sqlDB, err := db.DB()
+	require.NoError(t, err)
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { _ = sqlDB.Close() })
+
+	require.NoError(t, db.AutoMigrate(
+		&model.User{},
+		&model.Account{},
+		&model.Transaction{},
+		&model.TransactionStatusHistory{},
+		&model.LedgerAccount{},
+		&model.JournalEntry{},
+		&model.Posting{},
+		&model.ScheduledTransfer{},
+		&model.StandingOrder{},
+		&model.StandingOrderExecution{},
+		&model.BulkTransferBatch{},
+		&model.BulkTransferRow{},
+		&model.ExchangeRate{},
+		&model.TransactionLimit{},
+		&model.FeeRule{},
+		&model.Hold{},
+		&model.OverdraftChange{},
+		&model.OverdraftInterestAccrual{},
+		&model.AccountProduct{},
+		&model.InterestAccrual{},
+		&model.AccountStatusChange{},
+		&model.PinAudit{},
+		&model.PinResetCode{},
+		&model.IdempotencyRecord{},
+	))
+	return db
+}
+
+func createTestAccount(t *testing.T, db *gorm.DB, number, balance string) *model.Account {
+	account := &model.Account{
+		UserID:        1,
+		AccountNumber: number,
+		Balance:       model.BigDecimal{Decimal: decimal.MustParse(balance)},
+	}
+	require.NoError(t, db.Create(account).Error)
+	return account
+}
+
+func reloadBalance(t *testing.T, db *gorm.DB, accountID uint) decimal.Decimal {
+	var account model.Account
+	require.NoError(t, db.Where(&model.Account{AccountID: accountID}).First(&account).Error)
+	return account.GetBalance().Decimal
+}
+
+func debit(repository *JournalRepository, accountID uint, i int, amount string) error {
+	value := model.BigDecimal{Decimal: decimal.MustParse(amount)}
+	reference := fmt.Sprintf("ref%d", i)
+	transaction := &model.Transaction{
+		AccountID:        accountID,
+		Reference:        reference,
+		PaymentReference: fmt.Sprintf("payment%d", i),
+		Amount:           value,
+		Type:             model.DebitTransaction,
+		Status:           model.SucceededStatus,
+	}
+	entry, err := ledger.TransactionEntry(transaction)
+	if err != nil {
+		return err
+	}
+	return repository.PostJournalEntry(entry, transaction)
+}
+
+func Test_ConcurrentDebitsDoNotLoseUpdates(t *testing.T) {
+	db := openTestDB(t)
+	account := createTestAccount(t, db, "1234567890", "1000.00")
+	repository := NewJournalRepository(db)
+
+	const debits = 50
+	var wg sync.WaitGroup
+	errs := make(chan error, debits)
+	for i := 0; i < debits; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			errs <- debit(repository, account.AccountID, i, "10.00")
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		assert.NoError(t, err)
+	}
+
+	assert.Equal(t, 0, reloadBalance(t, db, account.AccountID).Cmp(decimal.MustParse("500.00")))
+
+	var count int64
+	db.Model(&model.Transaction{}).Where(&model.Transaction{AccountID: account.AccountID}).Count(&count)
+	assert.Equal(t, int64(debits), count)
+
+	debitTotal, _, err := repository.AccountPostingTotals(account.AccountID)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, debitTotal.Decimal.Cmp(decimal.MustParse("500.00")))
+}
+
+func Test_ConcurrentDebitsCannotOverdraw(t *testing.T) {
+	db := openTestDB(t)
+	account := createTestAccount(t, db, "1234567890", "100.00")
+	repository := NewJournalRepository(db)
+
+	const debits = 20
+	var wg sync.WaitGroup
+	errs := make(chan error, debits)
+	for i := 0; i < debits; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			errs <- debit(repository, account.AccountID, i, "10.00")
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+
+	succeeded, rejected := 0, 0
+	for err := range errs {
+		switch {
+		case err == nil:
+			succeeded++
+		case errors.Is(err, model.ErrInsufficientFunds):
+			rejected++
+		default:
+			t.Errorf("unexpected error: %v", err)
+		}
+	}
+
+	assert.Equal(t, 10, succeeded)
+	assert.Equal(t, 10, rejected)
+	assert.True(t, reloadBalance(t, db, account.AccountID).IsZero())
+}
+
+func Test_UnitOfWorkRollsBackBalanceWhenTransactionInsertFails(t *testing.T) {
+	db := openTestDB(t)
+	account := createTestAccount(t, db, "1234567890", "100.00")
+	repository := NewJournalRepository(db)
+
+	require.NoError(t, debit(repository, account.AccountID, 1, "10.00"))
+
+	// the same transaction reference violates the unique index, so the debit must not stick
+	assert.Error(t, debit(repository, account.AccountID, 1, "10.00"))
+
+	assert.Equal(t, 0, reloadBalance(t, db, account.AccountID).Cmp(decimal.MustParse("90.00")))
+
+	var entries int64
+	db.Model(&model.JournalEntry{}).Count(&entries)
+	assert.Equal(t, int64(1), entries)
+}
+
+// lockQueries runs fn in a unit of work on the MySQL dialect in dry run mode, which builds the SQL of the queries
+// without running them, and returns the SQL of every query it made
+func lockQueries(t *testing.T, fn func(tx ITx)) []string {
+	db, err := gorm.Open(mysql.New(mysql.Config{
+		DSN:                       "user:password@tcp(127.0.0.1:3306)/bank?parseTime=True",
+		SkipInitializeWithVersion: true,
+	}), &gorm.Config{
+		DryRun:               true,
+		DisableAutomaticPing: true,
+		NamingStrategy:       schema.NamingStrategy{SingularTable: true, TablePrefix: "tbl_"},
+		Logger:               logger.Default.LogMode(logger.Silent),
+	})
+	require.NoError(t, err)
+
+	var queries []string
+	err = db.Callback().Query().After("gorm:query").Register("test:record_sql", func(tx *gorm.DB) {
+		queries = append(queries, tx.Statement.SQL.String())
+	})
+	require.NoError(t, err)
+
+	fn(&unitOfWorkTx{tx: db, accounts: make(map[uint]*model.Account)})
+	return queries
+}
+
+func Test_LockQueriesSelectForUpdate(t *testing.T) {
+	testCases := []struct {
+		name  string
+		table string
+		lock  func(tx ITx)
+	}{
+		{name: "account", table: "tbl_account", lock: func(tx ITx) { _, _ = tx.LockAccount(1) }},
+		{name: "transaction", table: "tbl_transaction", lock: func(tx ITx) { _, _ = tx.LockTransaction(1) }},
+		{name: "hold", table: "tbl_hold", lock: func(tx ITx) { _, _ = tx.LockHold(1) }},
+		{name: "interest accruals", table: "tbl_interest_accrual", lock: func(tx ITx) {
+			_, _ = tx.LockUncapitalisedAccruals(1, time.Now())
+		}},
+		{name: "accounts of a journal entry", table: "tbl_account", lock: func(tx ITx) {
+			entry, err := ledger.InternalTransferEntry("ref1",
+				&model.Transaction{AccountID: 2, Amount: model.BigDecimal{Decimal: decimal.MustParse("10.00")}},
+				&model.Transaction{AccountID: 1, Amount: model.BigDecimal{Decimal: decimal.MustParse("10.00")}})
+			require.NoError(t, err)
+			_ = tx.PostJournalEntry(entry)
+		}},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			queries := lockQueries(t, tt.lock)
+			require.NotEmpty(t, queries)
+			assert.Contains(t, queries[0], "FROM `"+tt.table+"`")
+			assert.True(t, strings.HasSuffix(queries[0], "FOR UPDATE"), queries[0])
+		})
+	}
+}
+
+func Test_LockAccountReturnsSameAccountWithinUnitOfWork(t *testing.T) {
+	db := openTestDB(t)
+	account := createTestAccount(t, db, "1234567890", "100.00")
+
+	err := NewUnitOfWork(db).Execute(func(tx ITx) error {
+		first, err := tx.LockAccount(account.AccountID)
+		if err != nil {
+			return err
+		}
+		second, err := tx.LockAccount(account.AccountID)
+		if err != nil {
+			return err
+		}
+		assert.Same(t, first, second)
+		return nil
+	})
+	assert.NoError(t, err)
+}
+
+func Test_UpdateTransactionStatusRecordsHistory(t *testing.T) {
+	db := openTestDB(t)
+	account := createTestAccount(t, db, "1234567890", "100.00")
+	uow := NewUnitOfWork(db)
+
+	transaction := &model.Transaction{
+		AccountID:        account.AccountID,
+		Reference:        "ref1",
+		PaymentReference: "payment1",
+		Amount:           model.BigDecimal{Decimal: decimal.MustParse("10.00")},
+		Type:             model.DebitTransaction,
+		Status:           model.PendingStatus,
+	}
+	require.NoError(t, uow.Execute(func(tx ITx) error { return tx.SaveTransaction(transaction) }))
+
+	for _, status := range []model.TransactionStatus{model.SubmittedStatus, model.UnknownStatus, model.SucceededStatus} {
+		require.NoError(t, uow.Execute(func(tx ITx) error {
+			return tx.UpdateTransactionStatus(transaction, status, "test")
+		}))
+	}
+	assert.True(t, transaction.Success)
+
+	err := uow.Execute(func(tx ITx) error {
+		return tx.UpdateTransactionStatus(transaction, model.FailedStatus, "test")
+	})
+	assert.ErrorIs(t, err, model.ErrInvalidStatusTransition)
+	assert.Equal(t, model.SucceededStatus, transaction.Status)
+
+	stale := *transaction
+	stale.Status = model.SubmittedStatus
+	err = uow.Execute(func(tx ITx) error {
+		return tx.UpdateTransactionStatus(&stale, model.FailedStatus, "test")
+	})
+	assert.ErrorIs(t, err, ErrStaleTransactionStatus)
+
+	history, err := NewTransactionRepository(db).FindStatusHistory(transaction.TransactionID)
+	require.NoError(t, err)
+	var statuses []model.TransactionStatus
+	for _, h := range history {
+		statuses = append(statuses, h.ToStatus)
+	}
+	assert.Equal(t, []model.TransactionStatus{
+		model.PendingStatus, model.SubmittedStatus, model.UnknownStatus, model.SucceededStatus,
+	}, statuses)
+}
+
+func Test_UpdateReversedAmountIsStoredOnTheLockedTransaction(t *testing.T) {
+	db := openTestDB(t)
+	account := createTestAccount(t, db, "1234567890", "100.00")
+	repository := NewJournalRepository(db)
+	require.NoError(t, debit(repository, account.AccountID, 1, "10.00"))
+
+	var original model.Transaction
+	require.NoError(t, db.Where(&model.Transaction{Reference: "ref1"}).First(&original).Error)
+
+	uow := NewUnitOfWork(db)
+	reverse := func(amount string) error {
+		return uow.Execute(func(tx ITx) error {
+			locked, err := tx.LockTransaction(original.TransactionID)
+			if err != nil {
+				return err
+			}
+			if _, err := locked.AddReversal(model.BigDecimal{Decimal: decimal.MustParse(amount)}); err != nil {
+				return err
+			}
+			return tx.UpdateReversedAmount(locked)
+		})
+	}
+
+	require.NoError(t, reverse("4.00"))
+	require.NoError(t, reverse("6.00"))
+	assert.ErrorIs(t, reverse("0.01"), model.ErrTransactionAlreadyReversed)
+
+	reloaded, err := NewTransactionRepository(db).FindTransaction(original.TransactionID)
+	require.NoError(t, err)
+	assert.Equal(t, 0, reloaded.ReversedAmount.Decimal.Cmp(decimal.MustParse("10.00")))
+	assert.True(t, reloaded.IsFullyReversed())
+}
@@ -1,18 +1,24 @@
 package repository
 
 import (
+	"bankingApp/internal/credential"
 	"bankingApp/internal/model"
+	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type UserRepository struct { // define UserRepository
-	DB *gorm.DB
+	DB           *gorm.DB
+	PinLockout   model.Lockout
+	LoginLockout model.Lockout
 }
 
-// NewUserRepository creates a new instance of UserRepository
-func NewUserRepository(db *gorm.DB) *UserRepository {
-	return &UserRepository{DB: db}
+// NewUserRepository creates a new instance of UserRepository locking PIN use and signing in of users as the
+// lockouts say
+func NewUserRepository(db *gorm.DB, pinLockout, loginLockout model.Lockout) *UserRepository {
+	return &UserRepository{DB: db, PinLockout: pinLockout, LoginLockout: loginLockout}
 }
 
 // FindUserByUsername retrieves a user by username from the database
@@ -45,3 +51,125 @@ func (u *UserRepository) GetUserAndAccountByAccountNumber(accountNumber string)
 
 	return &user, &account, nil
 }
+
+// CreateUser stores a new user together with their first account in one commit. It returns ErrUsernameTaken
+// when another user has the username, and ErrAccountNumberTaken when the account number is in use.
+func (u *UserRepository) CreateUser(user *model.User, account *model.Account) error {
+	return u.DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).Omit(clause.Associations).Create(user)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return model.ErrUsernameTaken
+		}
+
+		account.UserID = user.UserID
+		result = tx.Clauses(clause.OnConflict{DoNothing: true}).Create(account)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return model.ErrAccountNumberTaken
+		}
+		return nil
+	})
+}
+
+// UpdateProfile stores the contact details of the user
+func (u *UserRepository) UpdateProfile(user *model.User) error {
+	return u.DB.Model(&model.User{}).Where(&model.User{UserID: user.UserID}).
+		UpdateColumns(map[string]interface{}{
+			"full_name":    user.FullName,
+			"email":        user.Email,
+			"phone_number": user.PhoneNumber,
+			"updated_at":   time.Now(),
+		}).Error
+}
+
+// UpdateRole assigns the role to the user
+func (u *UserRepository) UpdateRole(userID uint, role model.UserRole) error {
+	return u.DB.Model(&model.User{}).Where(&model.User{UserID: userID}).
+		UpdateColumns(map[string]interface{}{"role": role, "updated_at": time.Now()}).Error
+}
+
+// FindUserAccounts lists the accounts of the user in the order they were opened
+func (u *UserRepository) FindUserAccounts(userID uint) ([]model.Account, error) {
+	var accounts []model.Account
+	err := u.DB.
+		Where(&model.Account{UserID: userID}).
+		Order("account_id").
+		Find(&accounts).
+		Error
+	return accounts, err
+}
+
+// RecordFailedPinAttempt counts an incorrect transaction PIN against the user. Reaching the maximum attempts
+// locks PIN use until the cooldown has passed and starts the count again. The count is kept in the database
+// so that PINs guessed concurrently are all counted.
+func (u *UserRepository) RecordFailedPinAttempt(userID uint, now time.Time) error {
+	lockedUntil := now.Add(u.PinLockout.Cooldown)
+	return u.DB.Exec(`UPDATE tbl_user SET
+		pin_locked_until = CASE WHEN pin_attempts + 1 >= ? THEN ? ELSE pin_locked_until END,
+		pin_attempts = CASE WHEN pin_attempts + 1 >= ? THEN 0 ELSE pin_attempts + 1 END
+		WHERE user_id = ?`,
+		u.PinLockout.MaxAttempts, lockedUntil, u.PinLockout.MaxAttempts, userID).Error
+}
+
+// ResetPinAttempts clears the incorrect transaction PINs counted against the user and any lock they caused
+func (u *UserRepository) ResetPinAttempts(userID uint) error {
+	return u.DB.Model(&model.User{}).Where(&model.User{UserID: userID}).
+		UpdateColumns(map[string]interface{}{"pin_attempts": 0, "pin_locked_until": nil}).Error
+}
+
+// RecordFailedLogin counts an incorrect password against the user. Reaching the maximum attempts locks signing
+// in as the user until the cooldown has passed and starts the count again.
+func (u *UserRepository) RecordFailedLogin(userID uint, now time.Time) error {
+	lockedUntil := now.Add(u.LoginLockout.Cooldown)
+	return u.DB.Exec(`UPDATE tbl_user SET
+		login_locked_until = CASE WHEN login_attempts + 1 >= ? THEN ? ELSE login_locked_until END,
+		login_attempts = CASE WHEN login_attempts + 1 >= ? THEN 0 ELSE login_attempts + 1 END
+		WHERE user_id = ?`,
+		u.LoginLockout.MaxAttempts, lockedUntil, u.LoginLockout.MaxAttempts, userID).Error
+}
+
+// ResetLoginAttempts clears the incorrect passwords counted against the user and any lock they caused
+func (u *UserRepository) ResetLoginAttempts(userID uint) error {
+	return u.DB.Model(&model.User{}).Where(&model.User{UserID: userID}).
+		UpdateColumns(map[string]interface{}{"login_attempts": 0, "login_locked_until": nil}).Error
+}
+
+// HashLegacyCredentials hashes the passwords and transaction PINs stored in plain text before credentials
+// were hashed, so that every stored credential is verified the same way
+func (u *UserRepository) HashLegacyCredentials() error {
+	var users []model.User
+	err := u.DB.
+		Where("(password <> '' AND password NOT LIKE '$2%') OR (transaction_pin <> '' AND transaction_pin NOT LIKE '$2%')").
+		Find(&users).
+		Error
+	if err != nil {
+		return err
+	}
+
+	for _, user := range users {
+		columns := map[string]interface{}{}
+		for column, stored := range map[string]string{"password": user.Password, "transaction_pin": user.TransactionPin} {
+			if stored == "" || credential.IsHashed(stored) {
+				continue
+			}
+			hashed, err := credential.Hash(stored)
+			if err != nil {
+				return err
+			}
+			columns[column] = hashed
+		}
+		if len(columns) == 0 {
+			continue
+		}
+		err := u.DB.Model(&model.User{}).Where(&model.User{UserID: user.UserID}).UpdateColumns(columns).Error
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,180 @@
+package repository
+
+import (
+	"bankingApp/internal/credential"
+	"bankingApp/internal/model"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_CreateUserOpensTheirFirstAccount(t *testing.T) {
+	db := openTestDB(t)
+	repository := NewUserRepository(db, model.Lockout{}, model.Lockout{})
+
+	user := &model.User{Username: "johndoe", Password: "hashed-password", TransactionPin: "hashed-pin"}
+	account := &model.Account{AccountNumber: "1234567897", Currency: "NGN", Status: model.ActiveAccount}
+	require.NoError(t, repository.CreateUser(user, account))
+	assert.NotZero(t, user.UserID)
+	assert.Equal(t, user.UserID, account.UserID)
+
+	found, err := repository.FindUserByUsername("johndoe")
+	require.NoError(t, err)
+	assert.Equal(t, user.UserID, found.UserID)
+	assert.Equal(t, model.CustomerRole, found.Role)
+
+	accounts, err := repository.FindUserAccounts(user.UserID)
+	require.NoError(t, err)
+	require.Len(t, accounts, 1)
+	assert.Equal(t, "1234567897", accounts[0].AccountNumber)
+	assert.Equal(t, model.DefaultAccountTier, accounts[0].Tier)
+}
+
+func Test_CreateUserRejectsTakenUsernamesAndAccountNumbers(t *testing.T) {
+	db := openTestDB(t)
+	repository := NewUserRepository(db, model.Lockout{}, model.Lockout{})
+	require.NoError(t, repository.CreateUser(
+		&model.User{Username: "johndoe"},
+		&model.Account{AccountNumber: "1234567897"}))
+
+	err := repository.CreateUser(&model.User{Username: "johndoe"}, &model.Account{AccountNumber: "2345678901"})
+	assert.ErrorIs(t, err, model.ErrUsernameTaken)
+
+	err = repository.CreateUser(&model.User{Username: "janedoe"}, &model.Account{AccountNumber: "1234567897"})
+	assert.ErrorIs(t, err, model.ErrAccountNumberTaken)
+
+	// neither attempt leaves a user or an account behind
+	var users, accounts int64
+	require.NoError(t, db.Model(&model.User{}).Count(&users).Error)
+	require.NoError(t, db.Model(&model.Account{}).Count(&accounts).Error)
+	assert.Equal(t, int64(1), users)
+	assert.Equal(t, int64(1), accounts)
+}
+
+func Test_UpdateProfileOnlyChangesContactDetails(t *testing.T) {
+	db := openTestDB(t)
+	repository := NewUserRepository(db, model.Lockout{}, model.Lockout{})
+	user := &model.User{Username: "johndoe", Password: "hashed-password", FullName: "John Doe"}
+	require.NoError(t, repository.CreateUser(user, &model.Account{AccountNumber: "1234567897"}))
+
+	user.FullName, user.Email, user.PhoneNumber = "John A. Doe", "john@example.com", "+2348000000000"
+	user.Password = "changed"
+	require.NoError(t, repository.UpdateProfile(user))
+
+	found, err := repository.FindUserByUsername("johndoe")
+	require.NoError(t, err)
+	assert.Equal(t, "John A. Doe", found.FullName)
+	assert.Equal(t, "john@example.com", found.Email)
+	assert.Equal(t, "+2348000000000", found.PhoneNumber)
+	assert.Equal(t, "hashed-password", found.Password)
+}
+
+func Test_FailedPinAttemptsLockPinUse(t *testing.T) {
+	db := openTestDB(t)
+	repository := NewUserRepository(db, model.Lockout{MaxAttempts: 3, Cooldown: 15 * time.Minute}, model.Lockout{})
+	user := &model.User{Username: "johndoe"}
+	require.NoError(t, repository.CreateUser(user, &model.Account{AccountNumber: "1234567897"}))
+	now := time.Now()
+
+	for attempt := 1; attempt <= 2; attempt++ {
+		require.NoError(t, repository.RecordFailedPinAttempt(user.UserID, now))
+		found, err := repository.FindUserByUsername("johndoe")
+		require.NoError(t, err)
+		assert.Equal(t, attempt, found.PinAttempts)
+		assert.False(t, found.PinLocked(now))
+	}
+
+	require.NoError(t, repository.RecordFailedPinAttempt(user.UserID, now))
+	found, err := repository.FindUserByUsername("johndoe")
+	require.NoError(t, err)
+	assert.Zero(t, found.PinAttempts)
+	assert.True(t, found.PinLocked(now))
+	assert.True(t, found.PinLocked(now.Add(14*time.Minute)))
+	assert.False(t, found.PinLocked(now.Add(15*time.Minute)))
+
+	require.NoError(t, repository.ResetPinAttempts(user.UserID))
+	found, err = repository.FindUserByUsername("johndoe")
+	require.NoError(t, err)
+	assert.Zero(t, found.PinAttempts)
+	assert.Nil(t, found.PinLockedUntil)
+}
+
+func Test_FailedLoginsLockSigningIn(t *testing.T) {
+	db := openTestDB(t)
+	repository := NewUserRepository(db, model.Lockout{}, model.Lockout{MaxAttempts: 3, Cooldown: 15 * time.Minute})
+	user := &model.User{Username: "johndoe"}
+	require.NoError(t, repository.CreateUser(user, &model.Account{AccountNumber: "1234567897"}))
+	now := time.Now()
+
+	for attempt := 1; attempt <= 2; attempt++ {
+		require.NoError(t, repository.RecordFailedLogin(user.UserID, now))
+		found, err := repository.FindUserByUsername("johndoe")
+		require.NoError(t, err)
+		assert.Equal(t, attempt, found.LoginAttempts)
+		assert.False(t, found.LoginLocked(now))
+	}
+
+	require.NoError(t, repository.RecordFailedLogin(user.UserID, now))
+	found, err := repository.FindUserByUsername("johndoe")
+	require.NoError(t, err)
+	assert.Zero(t, found.LoginAttempts)
+	assert.True(t, found.LoginLocked(now))
+	assert.True(t, found.LoginLocked(now.Add(14*time.Minute)))
+	assert.False(t, found.LoginLocked(now.Add(15*time.Minute)))
+
+	require.NoError(t, repository.ResetLoginAttempts(user.UserID))
+	found, err = repository.FindUserByUsername("johndoe")
+	require.NoError(t, err)
+	assert.Zero(t, found.LoginAttempts)
+	assert.Nil(t, found.LoginLockedUntil)
+}
+
+func Test_HashLegacyCredentials(t *testing.T) {
+	db := openTestDB(t)
+	repository := NewUserRepository(db, model.Lockout{}, model.Lockout{})
+	hashedPin, err := credential.Hash("5678")
+	require.NoError(t, err)
+	require.NoError(t, repository.CreateUser(
+		&model.User{Username: "johndoe", Password: "correct horse", TransactionPin: "1234"},
+		&model.Account{AccountNumber: "1234567897"}))
+	require.NoError(t, repository.CreateUser(
+		&model.User{Username: "janedoe", TransactionPin: hashedPin},
+		&model.Account{AccountNumber: "2345678901"}))
+
+	require.NoError(t, repository.HashLegacyCredentials())
+
+	legacy, err := repository.FindUserByUsername("johndoe")
+	require.NoError(t, err)
+	assert.True(t, credential.IsHashed(legacy.Password))
+	assert.True(t, credential.Verify(legacy.Password, "correct horse"))
+	assert.True(t, legacy.CheckTransactionPin("1234"))
+
+	hashed, err := repository.FindUserByUsername("janedoe")
+	require.NoError(t, err)
+	assert.Empty(t, hashed.Password)
+	assert.Equal(t, hashedPin, hashed.TransactionPin)
+}
+
+func Test_UpdateRoleAssignsTheRole(t *testing.T) {
+	db := openTestDB(t)
+	repository := NewUserRepository(db, model.Lockout{}, model.Lockout{})
+	require.NoError(t, repository.CreateUser(
+		&model.User{Username: "johndoe", Role: model.CustomerRole},
+		&model.Account{AccountNumber: "1234567897"}))
+	require.NoError(t, repository.CreateUser(
+		&model.User{Username: "teller", Role: model.TellerRole},
+		&model.Account{AccountNumber: "2345678901"}))
+
+	staff, err := repository.FindUserByUsername("teller")
+	require.NoError(t, err)
+	require.NoError(t, repository.UpdateRole(staff.UserID, model.AdminRole))
+
+	staff, err = repository.FindUserByUsername("teller")
+	require.NoError(t, err)
+	assert.Equal(t, model.AdminRole, staff.Role)
+	customer, err := repository.FindUserByUsername("johndoe")
+	require.NoError(t, err)
+	assert.Equal(t, model.CustomerRole, customer.Role)
+}
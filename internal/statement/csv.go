@@ -0,0 +1,62 @@
+package statement
+
+import (
+	"encoding/csv"
+	"io"
+	"time"
+
+	"github.com/govalues/decimal"
+)
+
+var csvColumns = []string{
+	"posted_at", "reference", "transaction_reference", "payment_reference",
+	"description", "type", "status", "amount", "balance",
+}
+
+// csvWriter renders a statement as one CSV row per line, between an opening and a closing balance row
+type csvWriter struct {
+	writer   *csv.Writer
+	currency string
+	from, to time.Time
+}
+
+func newCSVWriter(w io.Writer) *csvWriter {
+	return &csvWriter{writer: csv.NewWriter(w)}
+}
+
+func (c *csvWriter) Begin(header Header) error {
+	c.currency = header.Currency
+	c.from, c.to = header.From, header.To
+	if err := c.writer.Write(csvColumns); err != nil {
+		return err
+	}
+	return c.balanceRow(c.from, "opening balance", header.OpeningBalance)
+}
+
+func (c *csvWriter) Line(line Line) error {
+	return c.writer.Write([]string{
+		line.PostedAt.UTC().Format(time.RFC3339),
+		line.Reference,
+		line.TransactionReference,
+		line.PaymentReference,
+		line.Description,
+		string(line.Type),
+		string(line.Status),
+		amount(line.Amount.Decimal, c.currency),
+		amount(line.Balance, c.currency),
+	})
+}
+
+func (c *csvWriter) End(closing decimal.Decimal) error {
+	if err := c.balanceRow(c.to, "closing balance", closing); err != nil {
+		return err
+	}
+	c.writer.Flush()
+	return c.writer.Error()
+}
+
+func (c *csvWriter) balanceRow(day time.Time, description string, balance decimal.Decimal) error {
+	return c.writer.Write([]string{
+		day.Format(time.DateOnly), "", "", "", description, "", "", "", amount(balance, c.currency),
+	})
+}
@@ -0,0 +1,102 @@
+package statement
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"time"
+
+	"github.com/govalues/decimal"
+)
+
+type jsonLine struct {
+	PostedAt             time.Time `json:"posted_at"`
+	Reference            string    `json:"reference"`
+	TransactionReference string    `json:"transaction_reference,omitempty"`
+	PaymentReference     string    `json:"payment_reference,omitempty"`
+	Description          string    `json:"description"`
+	Type                 string    `json:"type,omitempty"`
+	Status               string    `json:"status,omitempty"`
+	Amount               string    `json:"amount"`
+	Balance              string    `json:"balance"`
+}
+
+// jsonWriter renders a statement as a single JSON document, writing the lines of its transactions array
+// as they are read
+type jsonWriter struct {
+	writer   *bufio.Writer
+	currency string
+	lines    int
+}
+
+func newJSONWriter(w io.Writer) *jsonWriter {
+	return &jsonWriter{writer: bufio.NewWriter(w)}
+}
+
+func (j *jsonWriter) Begin(header Header) error {
+	j.currency = header.Currency
+	opening, err := json.Marshal(struct {
+		AccountNumber  string `json:"account_number"`
+		Currency       string `json:"currency"`
+		From           string `json:"from"`
+		To             string `json:"to"`
+		OpeningBalance string `json:"opening_balance"`
+	}{
+		AccountNumber:  header.AccountNumber,
+		Currency:       header.Currency,
+		From:           header.From.Format(time.DateOnly),
+		To:             header.To.Format(time.DateOnly),
+		OpeningBalance: amount(header.OpeningBalance, header.Currency),
+	})
+	if err != nil {
+		return err
+	}
+	// reopen the header object to append the transactions to it
+	if _, err := j.writer.Write(opening[:len(opening)-1]); err != nil {
+		return err
+	}
+	_, err = j.writer.WriteString(`,"transactions":[`)
+	return err
+}
+
+func (j *jsonWriter) Line(line Line) error {
+	data, err := json.Marshal(jsonLine{
+		PostedAt:             line.PostedAt.UTC(),
+		Reference:            line.Reference,
+		TransactionReference: line.TransactionReference,
+		PaymentReference:     line.PaymentReference,
+		Description:          line.Description,
+		Type:                 string(line.Type),
+		Status:               string(line.Status),
+		Amount:               amount(line.Amount.Decimal, j.currency),
+		Balance:              amount(line.Balance, j.currency),
+	})
+	if err != nil {
+		return err
+	}
+	if j.lines > 0 {
+		if err := j.writer.WriteByte(','); err != nil {
+			return err
+		}
+	}
+	j.lines++
+	_, err = j.writer.Write(data)
+	return err
+}
+
+func (j *jsonWriter) End(closing decimal.Decimal) error {
+	balance, err := json.Marshal(amount(closing, j.currency))
+	if err != nil {
+		return err
+	}
+	if _, err := j.writer.WriteString(`],"closing_balance":`); err != nil {
+		return err
+	}
+	if _, err := j.writer.Write(balance); err != nil {
+		return err
+	}
+	if _, err := j.writer.WriteString("}\n"); err != nil {
+		return err
+	}
+	return j.writer.Flush()
+}
@@ -0,0 +1,123 @@
+package statement
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/govalues/decimal"
+)
+
+const (
+	mt940ReferenceLength   = 16
+	mt940NarrativeLength   = 65
+	mt940NoReference       = "NONREF"
+	mt940DateLayout        = "060102"
+	mt940EntryDateLayout   = "0102"
+	mt940TransferType      = "NTRF"
+	mt940MiscellaneousType = "NMSC"
+)
+
+// mt940Writer renders a statement as the text block of a SWIFT MT940 customer statement message
+type mt940Writer struct {
+	writer   *bufio.Writer
+	currency string
+	to       time.Time
+}
+
+func newMT940Writer(w io.Writer) *mt940Writer {
+	return &mt940Writer{writer: bufio.NewWriter(w)}
+}
+
+func (m *mt940Writer) Begin(header Header) error {
+	m.currency = header.Currency
+	m.to = header.To
+	return m.write(
+		":20:STMT"+header.To.Format(mt940DateLayout),
+		":25:"+header.AccountNumber,
+		":28C:1/1",
+		":60F:"+m.balance(header.From, header.OpeningBalance),
+	)
+}
+
+// Line writes the statement line of the entry and its information to the account owner. Entries posted for a
+// transaction carry its payment reference as the reference for the account owner; the journal entry reference
+// is the reference of the bank.
+func (m *mt940Writer) Line(line Line) error {
+	mark, transactionType, ownerReference := "C", mt940MiscellaneousType, mt940NoReference
+	if line.Amount.Decimal.IsNeg() {
+		mark = "D"
+	}
+	if line.TransactionReference != "" {
+		transactionType = mt940TransferType
+	}
+	if reference := swiftText(line.PaymentReference, mt940ReferenceLength); reference != "" {
+		ownerReference = reference
+	}
+
+	statementLine := fmt.Sprintf(":61:%s%s%s%s%s%s//%s",
+		line.PostedAt.UTC().Format(mt940DateLayout),
+		line.PostedAt.UTC().Format(mt940EntryDateLayout),
+		mark,
+		m.amount(line.Amount.Decimal),
+		transactionType,
+		ownerReference,
+		swiftText(line.Reference, mt940ReferenceLength))
+	narrative := strings.TrimSpace(line.Description + " " + line.PaymentReference)
+	return m.write(statementLine, ":86:"+swiftText(narrative, mt940NarrativeLength))
+}
+
+func (m *mt940Writer) End(closing decimal.Decimal) error {
+	if err := m.write(":62F:"+m.balance(m.to, closing), "-"); err != nil {
+		return err
+	}
+	return m.writer.Flush()
+}
+
+// balance formats a balance field: the credit or debit mark, the date, the currency and the amount
+func (m *mt940Writer) balance(day time.Time, balance decimal.Decimal) string {
+	mark := "C"
+	if balance.IsNeg() {
+		mark = "D"
+	}
+	return mark + day.Format(mt940DateLayout) + m.currency + m.amount(balance)
+}
+
+// amount formats the absolute value of the amount with a decimal comma, as MT940 requires
+func (m *mt940Writer) amount(value decimal.Decimal) string {
+	formatted := amount(value.Abs(), m.currency)
+	if !strings.Contains(formatted, ".") {
+		return formatted + ","
+	}
+	return strings.Replace(formatted, ".", ",", 1)
+}
+
+func (m *mt940Writer) write(lines ...string) error {
+	for _, line := range lines {
+		if _, err := m.writer.WriteString(line + "\r\n"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// swiftText keeps the characters of the SWIFT X character set in the text, replacing the others with spaces,
+// and cuts it to the given length
+func swiftText(text string, length int) string {
+	cleaned := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			return r
+		case strings.ContainsRune("/-?:().,'+ ", r):
+			return r
+		default:
+			return ' '
+		}
+	}, text)
+	if len(cleaned) > length {
+		cleaned = cleaned[:length]
+	}
+	return strings.TrimSpace(cleaned)
+}
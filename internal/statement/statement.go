@@ -0,0 +1,121 @@
+package statement
+
+import (
+	"bankingApp/internal/currency"
+	"bankingApp/internal/model"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/govalues/decimal"
+)
+
+// ErrUnknownFormat is returned for statement formats that cannot be rendered
+var ErrUnknownFormat = errors.New("statement format is not supported")
+
+// Format is the file format a statement is rendered in
+type Format string
+
+const (
+	CSV   Format = "csv"
+	JSON  Format = "json"
+	MT940 Format = "mt940"
+)
+
+func (f Format) IsValid() bool {
+	return f == CSV || f == JSON || f == MT940
+}
+
+// ContentType returns the media type of statements in the format
+func (f Format) ContentType() string {
+	switch f {
+	case CSV:
+		return "text/csv; charset=utf-8"
+	case JSON:
+		return "application/json; charset=utf-8"
+	default:
+		return "text/plain; charset=utf-8"
+	}
+}
+
+// FileName returns the name statements of the account over the period are downloaded under
+func (f Format) FileName(header Header) string {
+	extension := string(f)
+	if f == MT940 {
+		extension = "sta"
+	}
+	return fmt.Sprintf("statement-%s-%s-%s.%s",
+		header.AccountNumber, header.From.Format(time.DateOnly), header.To.Format(time.DateOnly), extension)
+}
+
+// Header describes the statement of an account from the From date up to and including the To date
+type Header struct {
+	AccountNumber  string
+	Currency       string
+	From           time.Time
+	To             time.Time
+	OpeningBalance decimal.Decimal
+}
+
+// Line is an entry on the statement together with the balance of the account after it
+type Line struct {
+	model.StatementEntry
+	Balance decimal.Decimal
+}
+
+// Writer renders a statement as it is read: the header, then every line in order, then the closing balance
+type Writer interface {
+	Begin(header Header) error
+	Line(line Line) error
+	End(closing decimal.Decimal) error
+}
+
+// NewWriter returns the writer rendering statements in the format to w
+func NewWriter(format Format, w io.Writer) (Writer, error) {
+	switch format {
+	case CSV:
+		return newCSVWriter(w), nil
+	case JSON:
+		return newJSONWriter(w), nil
+	case MT940:
+		return newMT940Writer(w), nil
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnknownFormat, format)
+	}
+}
+
+// Render writes the statement with the writer, reading its entries with each, and returns the closing balance.
+// The balance is carried from the opening balance of the header through every entry, so entries are rendered
+// as they are read and never held in memory.
+func Render(
+	w Writer,
+	header Header,
+	each func(fn func(entry *model.StatementEntry) error) error) (decimal.Decimal, error) {
+	if err := w.Begin(header); err != nil {
+		return decimal.Decimal{}, err
+	}
+
+	balance := header.OpeningBalance
+	err := each(func(entry *model.StatementEntry) error {
+		next, err := balance.Add(entry.Amount.Decimal)
+		if err != nil {
+			return err
+		}
+		balance = next
+		return w.Line(Line{StatementEntry: *entry, Balance: balance})
+	})
+	if err != nil {
+		return decimal.Decimal{}, err
+	}
+	return balance, w.End(balance)
+}
+
+// amount formats the amount to the minor units of the currency
+func amount(value decimal.Decimal, code string) string {
+	rescaled, err := value.Rescale(currency.MinorUnits(code))
+	if err != nil {
+		return value.String()
+	}
+	return rescaled.String()
+}
@@ -0,0 +1,141 @@
+package statement
+
+import (
+	"bankingApp/internal/model"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/govalues/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testHeader() Header {
+	return Header{
+		AccountNumber:  "1234567890",
+		Currency:       "NGN",
+		From:           time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+		To:             time.Date(2024, 3, 31, 0, 0, 0, 0, time.UTC),
+		OpeningBalance: decimal.MustParse("1000"),
+	}
+}
+
+func testEntries(fn func(entry *model.StatementEntry) error) error {
+	entries := []model.StatementEntry{
+		{
+			JournalEntryID:       1,
+			Reference:            "TRF0001",
+			Description:          "outbound transfer",
+			PostedAt:             time.Date(2024, 3, 2, 9, 30, 0, 0, time.UTC),
+			Amount:               model.BigDecimal{Decimal: decimal.MustParse("-150.5")},
+			TransactionReference: "TRF0001",
+			PaymentReference:     "rent march",
+			Type:                 model.DebitTransaction,
+			Status:               model.SucceededStatus,
+		},
+		{
+			JournalEntryID: 2,
+			Reference:      "ODI-1-20240303",
+			Description:    "overdraft interest",
+			PostedAt:       time.Date(2024, 3, 3, 0, 5, 0, 0, time.UTC),
+			Amount:         model.BigDecimal{Decimal: decimal.MustParse("-0.25")},
+		},
+	}
+	for i := range entries {
+		if err := fn(&entries[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func render(t *testing.T, format Format) string {
+	var buffer bytes.Buffer
+	writer, err := NewWriter(format, &buffer)
+	require.NoError(t, err)
+	closing, err := Render(writer, testHeader(), testEntries)
+	require.NoError(t, err)
+	assert.Equal(t, 0, closing.Cmp(decimal.MustParse("849.25")))
+	return buffer.String()
+}
+
+func Test_RenderCSV(t *testing.T) {
+	expected := "posted_at,reference,transaction_reference,payment_reference,description,type,status,amount,balance\n" +
+		"2024-03-01,,,,opening balance,,,,1000.00\n" +
+		"2024-03-02T09:30:00Z,TRF0001,TRF0001,rent march,outbound transfer,debit,succeeded,-150.50,849.50\n" +
+		"2024-03-03T00:05:00Z,ODI-1-20240303,,,overdraft interest,,,-0.25,849.25\n" +
+		"2024-03-31,,,,closing balance,,,,849.25\n"
+	assert.Equal(t, expected, render(t, CSV))
+}
+
+func Test_RenderJSON(t *testing.T) {
+	var statement struct {
+		AccountNumber  string     `json:"account_number"`
+		From           string     `json:"from"`
+		To             string     `json:"to"`
+		OpeningBalance string     `json:"opening_balance"`
+		Transactions   []jsonLine `json:"transactions"`
+		ClosingBalance string     `json:"closing_balance"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(render(t, JSON)), &statement))
+
+	assert.Equal(t, "1234567890", statement.AccountNumber)
+	assert.Equal(t, "2024-03-01", statement.From)
+	assert.Equal(t, "2024-03-31", statement.To)
+	assert.Equal(t, "1000.00", statement.OpeningBalance)
+	require.Len(t, statement.Transactions, 2)
+	assert.Equal(t, "rent march", statement.Transactions[0].PaymentReference)
+	assert.Equal(t, "-150.50", statement.Transactions[0].Amount)
+	assert.Equal(t, "849.50", statement.Transactions[0].Balance)
+	assert.Empty(t, statement.Transactions[1].TransactionReference)
+	assert.Equal(t, "849.25", statement.Transactions[1].Balance)
+	assert.Equal(t, "849.25", statement.ClosingBalance)
+}
+
+func Test_RenderJSONWithoutTransactions(t *testing.T) {
+	var buffer bytes.Buffer
+	writer, err := NewWriter(JSON, &buffer)
+	require.NoError(t, err)
+	_, err = Render(writer, testHeader(), func(func(entry *model.StatementEntry) error) error { return nil })
+	require.NoError(t, err)
+
+	var statement map[string]interface{}
+	require.NoError(t, json.Unmarshal(buffer.Bytes(), &statement))
+	assert.Empty(t, statement["transactions"])
+	assert.Equal(t, "1000.00", statement["closing_balance"])
+}
+
+func Test_RenderMT940(t *testing.T) {
+	expected := ":20:STMT240331\r\n" +
+		":25:1234567890\r\n" +
+		":28C:1/1\r\n" +
+		":60F:C240301NGN1000,00\r\n" +
+		":61:2403020302D150,50NTRFrent march//TRF0001\r\n" +
+		":86:outbound transfer rent march\r\n" +
+		":61:2403030303D0,25NMSCNONREF//ODI-1-20240303\r\n" +
+		":86:overdraft interest\r\n" +
+		":62F:C240331NGN849,25\r\n" +
+		"-\r\n"
+	assert.Equal(t, expected, render(t, MT940))
+}
+
+func Test_RenderStopsAtTheFirstError(t *testing.T) {
+	failure := errors.New("connection lost")
+	writer, err := NewWriter(CSV, new(bytes.Buffer))
+	require.NoError(t, err)
+
+	_, err = Render(writer, testHeader(), func(fn func(entry *model.StatementEntry) error) error {
+		return failure
+	})
+	assert.ErrorIs(t, err, failure)
+}
+
+func Test_NewWriterRejectsUnknownFormats(t *testing.T) {
+	_, err := NewWriter("pdf", new(bytes.Buffer))
+	assert.ErrorIs(t, err, ErrUnknownFormat)
+	assert.False(t, Format("pdf").IsValid())
+	assert.Equal(t, "statement-1234567890-2024-03-01-2024-03-31.sta", MT940.FileName(testHeader()))
+}
@@ -0,0 +1,78 @@
+package token
+
+import (
+	"encoding/base64"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_IssuedTokenParses(t *testing.T) {
+	issuer, err := NewIssuer("secret", AccessTokenTTL)
+	require.NoError(t, err)
+	now := time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)
+	issuer.now = func() time.Time { return now }
+
+	token, expiresAt, err := issuer.Issue("janedoe", 7, "customer")
+	require.NoError(t, err)
+	assert.Equal(t, now.Add(AccessTokenTTL), expiresAt)
+
+	claims, err := issuer.Parse(token)
+	require.NoError(t, err)
+	assert.Equal(t, "janedoe", claims.Subject)
+	assert.Equal(t, uint(7), claims.UserID)
+	assert.Equal(t, "customer", claims.Role)
+	assert.Equal(t, expiresAt.Unix(), claims.ExpiresAt)
+}
+
+func Test_ParseRejectsInvalidTokens(t *testing.T) {
+	issuer, err := NewIssuer("secret", AccessTokenTTL)
+	require.NoError(t, err)
+	token, _, err := issuer.Issue("janedoe", 7, "customer")
+	require.NoError(t, err)
+	parts := strings.Split(token, ".")
+
+	other, err := NewIssuer("another secret", AccessTokenTTL)
+	require.NoError(t, err)
+	forged, _, err := other.Issue("janedoe", 7, "customer")
+	require.NoError(t, err)
+
+	escalated := base64.RawURLEncoding.EncodeToString([]byte(`{"sub":"janedoe","uid":7,"role":"admin","exp":9999999999}`))
+	unsigned := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"none","typ":"JWT"}`))
+
+	testCases := []struct {
+		name  string
+		token string
+	}{
+		{name: "empty", token: ""},
+		{name: "not a JWT", token: "janedoe"},
+		{name: "signed with another secret", token: forged},
+		{name: "claims changed", token: parts[0] + "." + escalated + "." + parts[2]},
+		{name: "unsigned", token: unsigned + "." + parts[1] + "."},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := issuer.Parse(tt.token)
+			assert.ErrorIs(t, err, ErrInvalidToken)
+		})
+	}
+}
+
+func Test_ParseRejectsExpiredTokens(t *testing.T) {
+	issuer, err := NewIssuer("secret", AccessTokenTTL)
+	require.NoError(t, err)
+	token, expiresAt, err := issuer.Issue("janedoe", 7, "customer")
+	require.NoError(t, err)
+
+	issuer.now = func() time.Time { return expiresAt }
+	_, err = issuer.Parse(token)
+	assert.ErrorIs(t, err, ErrExpiredToken)
+}
+
+func Test_NewIssuerNeedsSecret(t *testing.T) {
+	_, err := NewIssuer("", AccessTokenTTL)
+	assert.ErrorIs(t, err, ErrMissingSecret)
+}
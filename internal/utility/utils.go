@@ -18,6 +18,13 @@ type APIResponse struct {
 	Data    *model.ResponseDTO `json:"data,omitempty"`
 }
 
+// APIDataResponse is the response envelope for endpoints whose payload is not a model.ResponseDTO
+type APIDataResponse struct {
+	Message string      `json:"message"`
+	Success bool        `json:"success"`
+	Data    interface{} `json:"data,omitempty"`
+}
+
 func InternalServerError(context *gin.Context) {
 	context.JSON(http.StatusInternalServerError, FormulateErrorResponse("an application error occurred"))
 }
@@ -37,6 +44,14 @@ func FormulateSuccessResponse(data model.ResponseDTO) *APIResponse {
 	}
 }
 
+func FormulateDataResponse(message string, data interface{}) *APIDataResponse {
+	return &APIDataResponse{
+		Message: message,
+		Data:    data,
+		Success: true,
+	}
+}
+
 func HandleError(context *gin.Context, err error, statusCode int, message string) {
 	if err != nil {
 		slog.Error(err.Error())
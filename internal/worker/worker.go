@@ -0,0 +1,59 @@
+package worker
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+const defaultInterval = 30 * time.Second
+
+// Job is a unit of background work, run with the time the worker woke up at
+type Job struct {
+	Name string
+	Run  func(ctx context.Context, now time.Time) error
+}
+
+// Worker runs its jobs one after another every interval
+type Worker struct {
+	Interval time.Duration
+	Jobs     []Job
+	now      func() time.Time
+}
+
+// NewWorker creates a new instance of Worker, falling back to a 30 second interval when none is configured
+func NewWorker(interval time.Duration, jobs ...Job) *Worker {
+	if interval <= 0 {
+		interval = defaultInterval
+	}
+	return &Worker{Interval: interval, Jobs: jobs, now: time.Now}
+}
+
+// Start runs the jobs in the background straight away and then every interval until the context is cancelled
+func (w *Worker) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(w.Interval)
+		defer ticker.Stop()
+		for {
+			w.RunOnce(ctx)
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// RunOnce runs every job once. Failures are logged so one failing job does not hold up the others.
+func (w *Worker) RunOnce(ctx context.Context) {
+	now := w.now()
+	for _, job := range w.Jobs {
+		if ctx.Err() != nil {
+			return
+		}
+		if err := job.Run(ctx, now); err != nil {
+			slog.Error("background job failed", "job", job.Name, "error", err)
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_RunOnceRunsEveryJobEvenWhenOneFails(t *testing.T) {
+	now := time.Date(2024, time.March, 1, 9, 0, 0, 0, time.UTC)
+	var ran []string
+	w := NewWorker(time.Minute,
+		Job{Name: "failing", Run: func(ctx context.Context, at time.Time) error {
+			ran = append(ran, "failing")
+			return errors.New("boom")
+		}},
+		Job{Name: "next", Run: func(ctx context.Context, at time.Time) error {
+			assert.Equal(t, now, at)
+			ran = append(ran, "next")
+			return nil
+		}},
+	)
+	w.now = func() time.Time { return now }
+
+	w.RunOnce(context.Background())
+	assert.Equal(t, []string{"failing", "next"}, ran)
+}
+
+func Test_StartRunsJobsUntilCancelled(t *testing.T) {
+	var runs atomic.Int32
+	w := NewWorker(time.Millisecond, Job{Name: "count", Run: func(ctx context.Context, at time.Time) error {
+		runs.Add(1)
+		return nil
+	}})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	w.Start(ctx)
+	assert.Eventually(t, func() bool { return runs.Load() >= 3 }, time.Second, time.Millisecond)
+	cancel()
+
+	time.Sleep(10 * time.Millisecond)
+	stopped := runs.Load()
+	time.Sleep(10 * time.Millisecond)
+	assert.Equal(t, stopped, runs.Load())
+}
+
+func Test_NewWorkerDefaultsInterval(t *testing.T) {
+	assert.Equal(t, defaultInterval, NewWorker(0).Interval)
+}